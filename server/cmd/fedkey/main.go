@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/config"
+	"github.com/grtsinry43/grtblog-v2/server/internal/database"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence"
+)
+
+// fedkey 轮换联合签名密钥：
+//
+//	go run ./cmd/fedkey -alg ed25519 -grace 48h
+//	go run ./cmd/fedkey -list
+func main() {
+	alg := flag.String("alg", "rsa-sha256", "要轮换的签名算法（rsa-sha256 或 ed25519）")
+	grace := flag.Duration("grace", appfed.DefaultKeyGracePeriod, "旧密钥继续公布的时长，应大于远端公钥缓存时间")
+	list := flag.Bool("list", false, "仅列出当前有效的密钥")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, falling back to system env vars")
+	}
+	cfg := config.Load()
+
+	db, err := database.New(cfg.Database)
+	if err != nil {
+		log.Fatalf("failed to initialize database: %v", err)
+	}
+
+	cfgSvc := federationconfig.NewService(persistence.NewFederationConfigRepository(db))
+	keySvc := appfed.NewKeyService(cfgSvc, persistence.NewFederationSigningKeyRepository(db))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if !*list {
+		key, err := keySvc.Rotate(ctx, *alg, *grace)
+		if err != nil {
+			log.Fatalf("rotate failed: %v", err)
+		}
+		fmt.Printf("rotated %s key, key_ref=%s\n", key.Algorithm, key.KeyRef)
+	}
+
+	keys, err := keySvc.ListKeys(ctx)
+	if err != nil {
+		log.Fatalf("list keys failed: %v", err)
+	}
+	for _, key := range keys {
+		expires := "-"
+		if key.ExpiresAt != nil {
+			expires = key.ExpiresAt.Format(time.RFC3339)
+		}
+		fmt.Printf("%-12s %-40s created=%s expires=%s\n", key.Algorithm, "#"+key.KeyRef, key.CreatedAt.Format(time.RFC3339), expires)
+	}
+}
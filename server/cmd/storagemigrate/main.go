@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	mediaapp "github.com/grtsinry43/grtblog-v2/server/internal/app/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/config"
+	"github.com/grtsinry43/grtblog-v2/server/internal/database"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/storage"
+)
+
+// storagemigrate 在存储驱动之间迁移上传文件并改写 upload_file.path：
+//
+//	go run ./cmd/storagemigrate -from local -to s3 -dry-run
+//	go run ./cmd/storagemigrate -from local -to s3 -delete-source
+func main() {
+	from := flag.String("from", storage.DriverLocal, "源存储驱动（local 或 s3）")
+	to := flag.String("to", storage.DriverS3, "目标存储驱动（local 或 s3）")
+	dryRun := flag.Bool("dry-run", false, "仅统计需要迁移的文件")
+	deleteSource := flag.Bool("delete-source", false, "迁移成功后删除源文件")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, falling back to system env vars")
+	}
+	cfg := config.Load()
+
+	db, err := database.New(cfg.Database)
+	if err != nil {
+		log.Fatalf("failed to initialize database: %v", err)
+	}
+	source, err := storage.New(cfg.Storage, *from)
+	if err != nil {
+		log.Fatalf("source driver: %v", err)
+	}
+	target, err := storage.New(cfg.Storage, *to)
+	if err != nil {
+		log.Fatalf("target driver: %v", err)
+	}
+
+	report, err := mediaapp.Migrate(context.Background(), persistence.NewUploadFileRepository(db), source, target, mediaapp.MigrateOptions{
+		DryRun:       *dryRun,
+		DeleteSource: *deleteSource,
+	})
+	if err != nil {
+		log.Fatalf("migrate failed: %v", err)
+	}
+	action := "migrated"
+	if *dryRun {
+		action = "to migrate"
+	}
+	fmt.Printf("scanned=%d %s=%d skipped=%d failed=%d\n", report.Scanned, action, report.Migrated, report.Skipped, len(report.Failed))
+	for _, failure := range report.Failed {
+		fmt.Printf("  id=%d path=%s err=%v\n", failure.ID, failure.Path, failure.Err)
+	}
+	if len(report.Failed) > 0 {
+		os.Exit(1)
+	}
+	if !*dryRun && *to != cfg.Storage.Driver {
+		fmt.Printf("note: STORAGE_DRIVER is %q, set it to %q so new uploads go to the target driver\n", cfg.Storage.Driver, *to)
+	}
+}
@@ -0,0 +1,803 @@
+package activitypub
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/comment"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/content"
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+const (
+	ObjectTypeArticle = "article"
+	ObjectTypeMoment  = "moment"
+
+	InteractionLike     = "like"
+	InteractionAnnounce = "announce"
+
+	outboxPageSize  = 20
+	maxReplyRunes   = 5000
+	deliveryTimeout = 15 * time.Second
+)
+
+// Service 将站长账号以 ActivityPub Actor 的形式暴露给 Mastodon 等实例：
+// 提供 WebFinger/Actor/Outbox，处理收件箱活动，并在文章发布时向关注者推送。
+type Service struct {
+	cfgSvc          *federationconfig.Service
+	keySvc          *appfed.KeyService
+	contentRepo     content.Repository
+	userRepo        identity.Repository
+	commentRepo     comment.CommentRepository
+	followerRepo    domainfed.ActivityPubFollowerRepository
+	interactionRepo domainfed.ActivityPubInteractionRepository
+	actors          *fedinfra.ActorKeySource
+	policy          fedinfra.InstancePolicy
+	client          *http.Client
+}
+
+// NewService 创建 ActivityPub 服务；policy 复用联合实例的域名黑名单，在写入回复前再次校验，可为 nil。
+func NewService(
+	cfgSvc *federationconfig.Service,
+	keySvc *appfed.KeyService,
+	contentRepo content.Repository,
+	userRepo identity.Repository,
+	commentRepo comment.CommentRepository,
+	followerRepo domainfed.ActivityPubFollowerRepository,
+	interactionRepo domainfed.ActivityPubInteractionRepository,
+	actors *fedinfra.ActorKeySource,
+	policy fedinfra.InstancePolicy,
+) *Service {
+	return &Service{
+		cfgSvc:          cfgSvc,
+		keySvc:          keySvc,
+		contentRepo:     contentRepo,
+		userRepo:        userRepo,
+		commentRepo:     commentRepo,
+		followerRepo:    followerRepo,
+		interactionRepo: interactionRepo,
+		actors:          actors,
+		policy:          policy,
+		client:          fedinfra.NewGuardedHTTPClient(deliveryTimeout),
+	}
+}
+
+// localActor 汇总当前站点的 Actor 信息，所有 URL 均基于 federation.instanceURL。
+type localActor struct {
+	settings federationconfig.Settings
+	user     *identity.User
+	baseURL  string
+	host     string
+	actorURL string
+}
+
+func (a localActor) keyID() string          { return a.actorURL + "#main-key" }
+func (a localActor) inboxURL() string       { return a.actorURL + "/inbox" }
+func (a localActor) outboxURL() string      { return a.actorURL + "/outbox" }
+func (a localActor) followersURL() string   { return a.actorURL + "/followers" }
+func (a localActor) sharedInboxURL() string { return a.baseURL + "/ap/inbox" }
+
+func (a localActor) objectURL(objectType string, shortURL string) string {
+	return a.baseURL + "/ap/" + objectType + "s/" + shortURL
+}
+
+func (a localActor) pageURL(objectType string, shortURL string) string {
+	if objectType == ObjectTypeMoment {
+		return a.baseURL + "/moments/" + shortURL
+	}
+	return a.baseURL + "/posts/" + shortURL
+}
+
+// resolveActor 校验开关并加载站长账号；未启用或未配置 instanceURL 时返回 ErrActivityPubDisabled。
+func (s *Service) resolveActor(ctx context.Context) (localActor, error) {
+	settings, err := s.cfgSvc.Settings(ctx)
+	if err != nil {
+		return localActor{}, err
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(settings.InstanceURL), "/")
+	if !settings.Enabled || !settings.ActivityPub || baseURL == "" {
+		return localActor{}, domainfed.ErrActivityPubDisabled
+	}
+	parsed, err := url.Parse(baseURL)
+	if err != nil || parsed.Host == "" {
+		return localActor{}, domainfed.ErrActivityPubDisabled
+	}
+	user, err := s.userRepo.FindFirstAdmin(ctx)
+	if err != nil {
+		if errors.Is(err, identity.ErrUserNotFound) {
+			return localActor{}, domainfed.ErrActorNotFound
+		}
+		return localActor{}, err
+	}
+	return localActor{
+		settings: settings,
+		user:     user,
+		baseURL:  baseURL,
+		host:     parsed.Host,
+		actorURL: baseURL + "/ap/users/" + url.PathEscape(user.Username),
+	}, nil
+}
+
+// resolveNamedActor 额外校验路由中的用户名，只有站长账号对外可见。
+func (s *Service) resolveNamedActor(ctx context.Context, username string) (localActor, error) {
+	actor, err := s.resolveActor(ctx)
+	if err != nil {
+		return localActor{}, err
+	}
+	if !strings.EqualFold(actor.user.Username, strings.TrimSpace(username)) {
+		return localActor{}, domainfed.ErrActorNotFound
+	}
+	return actor, nil
+}
+
+// WebFinger 响应 acct:user@host 或 Actor URL 形式的查询。
+func (s *Service) WebFinger(ctx context.Context, resource string) (*fedinfra.WebFingerDoc, error) {
+	actor, err := s.resolveActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resource = strings.TrimSpace(resource)
+	subject := "acct:" + actor.user.Username + "@" + actor.host
+	if !strings.EqualFold(resource, subject) && resource != actor.actorURL {
+		return nil, domainfed.ErrActorNotFound
+	}
+	return &fedinfra.WebFingerDoc{
+		Subject: subject,
+		Aliases: []string{actor.actorURL},
+		Links: []fedinfra.WebFingerLink{
+			{Rel: "self", Type: fedinfra.ContentTypeActivityJSON, Href: actor.actorURL},
+			{Rel: "http://webfinger.net/rel/profile-page", Type: "text/html", Href: actor.baseURL},
+		},
+	}, nil
+}
+
+// Actor 返回站长的 Person 文档，publicKey 复用联合协议的 RSA 签名密钥。
+func (s *Service) Actor(ctx context.Context, username string) (*fedinfra.APActor, error) {
+	actor, err := s.resolveNamedActor(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.signingKey(ctx, actor.settings)
+	if err != nil {
+		return nil, err
+	}
+	doc := &fedinfra.APActor{
+		Context:           fedinfra.APContext,
+		ID:                actor.actorURL,
+		Type:              "Person",
+		PreferredUsername: actor.user.Username,
+		Name:              firstNonEmpty(actor.user.Nickname, actor.user.Username),
+		Summary:           actor.settings.InstanceName,
+		URL:               actor.baseURL,
+		Inbox:             actor.inboxURL(),
+		Outbox:            actor.outboxURL(),
+		Followers:         actor.followersURL(),
+		Endpoints:         &fedinfra.APEndpoints{SharedInbox: actor.sharedInboxURL()},
+		PublicKey: &fedinfra.APPublicKey{
+			ID:           actor.keyID(),
+			Owner:        actor.actorURL,
+			PublicKeyPem: key.PublicKey,
+		},
+		Discoverable: true,
+	}
+	if avatar := strings.TrimSpace(actor.user.Avatar); avatar != "" {
+		doc.Icon = &fedinfra.APImage{Type: "Image", URL: absoluteURL(actor.baseURL, avatar)}
+	}
+	return doc, nil
+}
+
+// Outbox 不带 page 时返回集合概要，带 page 时按发布时间合并文章与手记。
+func (s *Service) Outbox(ctx context.Context, username string, page int) (*fedinfra.APOrderedCollection, error) {
+	actor, err := s.resolveNamedActor(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	entries, total, err := s.contentRepo.ListPublicTimeline(ctx, max(page, 1), outboxPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if page <= 0 {
+		return &fedinfra.APOrderedCollection{
+			Context:    fedinfra.ActivityStreamsContext,
+			ID:         actor.outboxURL(),
+			Type:       "OrderedCollection",
+			TotalItems: total,
+			First:      actor.outboxURL() + "?page=1",
+		}, nil
+	}
+
+	items := make([]any, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Article != nil {
+			tags, err := s.contentRepo.GetTagsByArticleID(ctx, entry.Article.ID)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, createActivity(actor, s.articleObject(actor, entry.Article, tags)))
+			continue
+		}
+		items = append(items, createActivity(actor, s.momentObject(actor, entry.Moment)))
+	}
+	collection := &fedinfra.APOrderedCollection{
+		Context:      fedinfra.ActivityStreamsContext,
+		ID:           fmt.Sprintf("%s?page=%d", actor.outboxURL(), page),
+		Type:         "OrderedCollectionPage",
+		TotalItems:   total,
+		PartOf:       actor.outboxURL(),
+		OrderedItems: items,
+	}
+	if int64(page*outboxPageSize) < total {
+		collection.Next = fmt.Sprintf("%s?page=%d", actor.outboxURL(), page+1)
+	}
+	return collection, nil
+}
+
+// Followers 只公开关注者数量，不列出具体账号。
+func (s *Service) Followers(ctx context.Context, username string) (*fedinfra.APOrderedCollection, error) {
+	actor, err := s.resolveNamedActor(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.followerRepo.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &fedinfra.APOrderedCollection{
+		Context:    fedinfra.ActivityStreamsContext,
+		ID:         actor.followersURL(),
+		Type:       "OrderedCollection",
+		TotalItems: total,
+	}, nil
+}
+
+// Object 返回单篇文章（Article）或手记（Note）对象，未发布内容视为不存在。
+func (s *Service) Object(ctx context.Context, objectType string, shortURL string) (*fedinfra.APObject, error) {
+	actor, err := s.resolveActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch objectType {
+	case ObjectTypeArticle:
+		item, err := s.contentRepo.GetArticleByShortURL(ctx, shortURL)
+		if err != nil {
+			if errors.Is(err, content.ErrArticleNotFound) {
+				return nil, domainfed.ErrActivityObjectNotFound
+			}
+			return nil, err
+		}
+		if !item.IsPublished {
+			return nil, domainfed.ErrActivityObjectNotFound
+		}
+		tags, err := s.contentRepo.GetTagsByArticleID(ctx, item.ID)
+		if err != nil {
+			return nil, err
+		}
+		object := s.articleObject(actor, item, tags)
+		object.Context = fedinfra.ActivityStreamsContext
+		return &object, nil
+	case ObjectTypeMoment:
+		item, err := s.contentRepo.GetMomentByShortURL(ctx, shortURL)
+		if err != nil {
+			if errors.Is(err, content.ErrMomentNotFound) {
+				return nil, domainfed.ErrActivityObjectNotFound
+			}
+			return nil, err
+		}
+		if !item.IsPublished {
+			return nil, domainfed.ErrActivityObjectNotFound
+		}
+		object := s.momentObject(actor, item)
+		object.Context = fedinfra.ActivityStreamsContext
+		return &object, nil
+	default:
+		return nil, domainfed.ErrActivityObjectNotFound
+	}
+}
+
+func (s *Service) articleObject(actor localActor, item *content.Article, tags []*content.Tag) fedinfra.APObject {
+	object := fedinfra.APObject{
+		ID:           actor.objectURL(ObjectTypeArticle, item.ShortURL),
+		Type:         "Article",
+		AttributedTo: actor.actorURL,
+		Name:         item.Title,
+		Summary:      contentutil.BuildSummary(item.Summary, item.Content),
+		Content:      contentutil.RenderHTML(item.Content),
+		URL:          actor.pageURL(ObjectTypeArticle, item.ShortURL),
+		Published:    item.CreatedAt.UTC(),
+		To:           []string{fedinfra.ActivityStreamsPublic},
+		Cc:           []string{actor.followersURL()},
+	}
+	if item.UpdatedAt.After(item.CreatedAt) {
+		updated := item.UpdatedAt.UTC()
+		object.Updated = &updated
+	}
+	for _, tag := range tags {
+		if tag == nil || strings.TrimSpace(tag.Name) == "" {
+			continue
+		}
+		object.Tag = append(object.Tag, fedinfra.APTag{Type: "Hashtag", Name: "#" + strings.ReplaceAll(tag.Name, " ", "")})
+	}
+	if item.Cover != nil && strings.TrimSpace(*item.Cover) != "" {
+		object.Attachment = []fedinfra.APImage{{Type: "Image", URL: absoluteURL(actor.baseURL, *item.Cover)}}
+	}
+	return object
+}
+
+func (s *Service) momentObject(actor localActor, item *content.Moment) fedinfra.APObject {
+	object := fedinfra.APObject{
+		ID:           actor.objectURL(ObjectTypeMoment, item.ShortURL),
+		Type:         "Note",
+		AttributedTo: actor.actorURL,
+		Content:      contentutil.RenderHTML(item.Content),
+		URL:          actor.pageURL(ObjectTypeMoment, item.ShortURL),
+		Published:    item.CreatedAt.UTC(),
+		To:           []string{fedinfra.ActivityStreamsPublic},
+		Cc:           []string{actor.followersURL()},
+	}
+	if item.UpdatedAt.After(item.CreatedAt) {
+		updated := item.UpdatedAt.UTC()
+		object.Updated = &updated
+	}
+	if item.Image != nil && strings.TrimSpace(*item.Image) != "" {
+		object.Attachment = []fedinfra.APImage{{Type: "Image", URL: absoluteURL(actor.baseURL, *item.Image)}}
+	}
+	return object
+}
+
+func createActivity(actor localActor, object fedinfra.APObject) fedinfra.APActivity {
+	published := object.Published
+	return fedinfra.APActivity{
+		ID:        object.ID + "#create",
+		Type:      "Create",
+		Actor:     actor.actorURL,
+		Object:    object,
+		To:        object.To,
+		Cc:        object.Cc,
+		Published: &published,
+	}
+}
+
+// HandleInbox 处理已通过签名校验的收件箱活动，活动的 actor 必须与签名方同源。
+func (s *Service) HandleInbox(ctx context.Context, sig *fedinfra.VerifiedSignature, body []byte) error {
+	actor, err := s.resolveActor(ctx)
+	if err != nil {
+		return err
+	}
+	var activity fedinfra.APIncomingActivity
+	if err := json.Unmarshal(body, &activity); err != nil {
+		return fmt.Errorf("invalid activity: %w", err)
+	}
+	if activity.Actor == "" || !sameOrigin(activity.Actor, sig.BaseURL) {
+		return domainfed.ErrActivityActorMismatch
+	}
+
+	switch activity.Type {
+	case "Follow":
+		return s.handleFollow(ctx, actor, activity, body)
+	case "Undo":
+		return s.handleUndo(ctx, activity)
+	case "Like", "Announce":
+		return s.handleInteraction(ctx, actor, activity)
+	case "Create":
+		return s.handleCreate(ctx, actor, activity)
+	case "Delete":
+		if fedinfra.ObjectID(activity.Object) == activity.Actor {
+			return s.removeFollower(ctx, activity.Actor)
+		}
+		return nil
+	default:
+		log.Printf("[activitypub] 忽略未支持的活动 type=%s actor=%s", activity.Type, activity.Actor)
+		return nil
+	}
+}
+
+// handleFollow 记录关注者并异步回送 Accept。
+func (s *Service) handleFollow(ctx context.Context, actor localActor, activity fedinfra.APIncomingActivity, body []byte) error {
+	if fedinfra.ObjectID(activity.Object) != actor.actorURL {
+		return domainfed.ErrActorNotFound
+	}
+	remote, err := s.actors.FetchActor(ctx, activity.Actor)
+	if err != nil {
+		return fmt.Errorf("fetch follower actor: %w", err)
+	}
+	follower := &domainfed.ActivityPubFollower{
+		ActorID:          activity.Actor,
+		Inbox:            remote.Inbox,
+		Username:         optionalString(remote.PreferredUsername),
+		DisplayName:      optionalString(remote.Name),
+		FollowActivityID: optionalString(activity.ID),
+	}
+	if remote.Endpoints != nil {
+		follower.SharedInbox = optionalString(remote.Endpoints.SharedInbox)
+	}
+	if remote.Icon != nil {
+		follower.Avatar = optionalString(remote.Icon.URL)
+	}
+	if err := s.followerRepo.Upsert(ctx, follower); err != nil {
+		return err
+	}
+	log.Printf("[activitypub] 新关注者 actor=%s", activity.Actor)
+
+	accept := fedinfra.APActivity{
+		Context: fedinfra.ActivityStreamsContext,
+		ID:      actor.actorURL + "#accepts/follows/" + shortHash(activity.Actor+activity.ID),
+		Type:    "Accept",
+		Actor:   actor.actorURL,
+		Object:  json.RawMessage(body),
+	}
+	go func() {
+		if err := s.deliver(context.Background(), remote.Inbox, accept); err != nil {
+			log.Printf("[activitypub] 投递 Accept 失败 inbox=%s err=%v", remote.Inbox, err)
+		}
+	}()
+	return nil
+}
+
+// handleUndo 撤销关注或点赞/转发；被撤销的活动只能由原 actor 发起。
+func (s *Service) handleUndo(ctx context.Context, activity fedinfra.APIncomingActivity) error {
+	var inner fedinfra.APIncomingObject
+	if err := json.Unmarshal(activity.Object, &inner); err != nil {
+		// 部分实现只给出被撤销活动的 id，此时按 id 删除互动记录。
+		return s.interactionRepo.DeleteByActivityID(ctx, fedinfra.ObjectID(activity.Object), activity.Actor)
+	}
+	switch inner.Type {
+	case "Follow":
+		return s.removeFollower(ctx, activity.Actor)
+	default:
+		return s.interactionRepo.DeleteByActivityID(ctx, inner.ID, activity.Actor)
+	}
+}
+
+// removeFollower 删除关注者，本就不存在时视为成功（远端会重复发送 Undo/Delete）。
+func (s *Service) removeFollower(ctx context.Context, actorID string) error {
+	if err := s.followerRepo.DeleteByActorID(ctx, actorID); err != nil && !errors.Is(err, domainfed.ErrFollowerNotFound) {
+		return err
+	}
+	log.Printf("[activitypub] 关注者已移除 actor=%s", actorID)
+	return nil
+}
+
+func (s *Service) handleInteraction(ctx context.Context, actor localActor, activity fedinfra.APIncomingActivity) error {
+	objectType, objectID, err := s.lookupLocalObject(ctx, actor, fedinfra.ObjectID(activity.Object))
+	if err != nil {
+		if errors.Is(err, domainfed.ErrActivityObjectNotFound) {
+			return nil
+		}
+		return err
+	}
+	kind := InteractionLike
+	if activity.Type == "Announce" {
+		kind = InteractionAnnounce
+	}
+	return s.interactionRepo.Create(ctx, &domainfed.ActivityPubInteraction{
+		ActivityID: activity.ID,
+		Kind:       kind,
+		ActorID:    activity.Actor,
+		ObjectType: objectType,
+		ObjectID:   objectID,
+	})
+}
+
+// handleCreate 将回复本站内容的 Note 写入对应评论区，默认未读等待站长处理。
+func (s *Service) handleCreate(ctx context.Context, actor localActor, activity fedinfra.APIncomingActivity) error {
+	if !actor.settings.APReplies {
+		return nil
+	}
+	var note fedinfra.APIncomingObject
+	if err := json.Unmarshal(activity.Object, &note); err != nil || note.Type != "Note" || note.InReplyTo == "" {
+		return nil
+	}
+	if note.AttributedTo != "" && note.AttributedTo != activity.Actor {
+		return domainfed.ErrActivityActorMismatch
+	}
+	if s.policy != nil {
+		actorURL, err := url.Parse(activity.Actor)
+		if err != nil || actorURL.Host == "" {
+			return domainfed.ErrActivityActorMismatch
+		}
+		if err := s.policy.CheckInstance(ctx, actorURL.Scheme+"://"+actorURL.Host); err != nil {
+			return err
+		}
+	}
+	areaID, err := s.lookupCommentArea(ctx, actor, note.InReplyTo)
+	if err != nil || areaID == 0 {
+		if errors.Is(err, domainfed.ErrActivityObjectNotFound) {
+			return nil
+		}
+		return err
+	}
+	area, err := s.commentRepo.GetAreaByID(ctx, areaID)
+	if err != nil {
+		return err
+	}
+	if area.IsClosed {
+		return nil
+	}
+
+	text := strings.TrimSpace(contentutil.StripHTML(note.Content))
+	if text == "" {
+		return nil
+	}
+	if runes := []rune(text); len(runes) > maxReplyRunes {
+		text = string(runes[:maxReplyRunes])
+	}
+	nickname := activity.Actor
+	website := activity.Actor
+	if remote, err := s.actors.FetchActor(ctx, activity.Actor); err == nil {
+		if remote.PreferredUsername != "" {
+			nickname = "@" + remote.PreferredUsername + "@" + hostOf(activity.Actor)
+		}
+		if remote.URL != "" {
+			website = remote.URL
+		}
+	}
+	platform := "ActivityPub"
+	return s.commentRepo.Create(ctx, &comment.Comment{
+		AreaID:   areaID,
+		Content:  text,
+		NickName: &nickname,
+		Website:  &website,
+		Platform: &platform,
+		IsViewed: false,
+	})
+}
+
+// lookupLocalObject 将对象 URL（/ap/... 或页面地址）解析为本站文章/手记。
+func (s *Service) lookupLocalObject(ctx context.Context, actor localActor, objectURL string) (string, int64, error) {
+	objectType, shortURL := parseLocalObject(actor, objectURL)
+	switch objectType {
+	case ObjectTypeArticle:
+		item, err := s.contentRepo.GetArticleByShortURL(ctx, shortURL)
+		if err != nil {
+			if errors.Is(err, content.ErrArticleNotFound) {
+				return "", 0, domainfed.ErrActivityObjectNotFound
+			}
+			return "", 0, err
+		}
+		return ObjectTypeArticle, item.ID, nil
+	case ObjectTypeMoment:
+		item, err := s.contentRepo.GetMomentByShortURL(ctx, shortURL)
+		if err != nil {
+			if errors.Is(err, content.ErrMomentNotFound) {
+				return "", 0, domainfed.ErrActivityObjectNotFound
+			}
+			return "", 0, err
+		}
+		return ObjectTypeMoment, item.ID, nil
+	default:
+		return "", 0, domainfed.ErrActivityObjectNotFound
+	}
+}
+
+func (s *Service) lookupCommentArea(ctx context.Context, actor localActor, objectURL string) (int64, error) {
+	objectType, shortURL := parseLocalObject(actor, objectURL)
+	var areaID *int64
+	switch objectType {
+	case ObjectTypeArticle:
+		item, err := s.contentRepo.GetArticleByShortURL(ctx, shortURL)
+		if err != nil {
+			if errors.Is(err, content.ErrArticleNotFound) {
+				return 0, domainfed.ErrActivityObjectNotFound
+			}
+			return 0, err
+		}
+		areaID = item.CommentID
+	case ObjectTypeMoment:
+		item, err := s.contentRepo.GetMomentByShortURL(ctx, shortURL)
+		if err != nil {
+			if errors.Is(err, content.ErrMomentNotFound) {
+				return 0, domainfed.ErrActivityObjectNotFound
+			}
+			return 0, err
+		}
+		areaID = item.CommentID
+	default:
+		return 0, domainfed.ErrActivityObjectNotFound
+	}
+	if areaID == nil {
+		return 0, nil
+	}
+	return *areaID, nil
+}
+
+func parseLocalObject(actor localActor, objectURL string) (string, string) {
+	objectURL, _, _ = strings.Cut(objectURL, "#")
+	path, ok := strings.CutPrefix(objectURL, actor.baseURL)
+	if !ok {
+		return "", ""
+	}
+	prefixes := []struct {
+		prefix     string
+		objectType string
+	}{
+		{"/ap/articles/", ObjectTypeArticle},
+		{"/ap/moments/", ObjectTypeMoment},
+		{"/posts/", ObjectTypeArticle},
+		{"/moments/", ObjectTypeMoment},
+	}
+	for _, item := range prefixes {
+		if short, ok := strings.CutPrefix(path, item.prefix); ok {
+			short = strings.Trim(short, "/")
+			if short == "" || strings.Contains(short, "/") {
+				return "", ""
+			}
+			return item.objectType, short
+		}
+	}
+	return "", ""
+}
+
+// PublishArticle 向所有关注者投递 Create(Article)，同一实例的关注者共用 sharedInbox 只投递一次。
+func (s *Service) PublishArticle(ctx context.Context, articleID int64) error {
+	actor, err := s.resolveActor(ctx)
+	if err != nil {
+		return err
+	}
+	item, err := s.contentRepo.GetArticleByID(ctx, articleID)
+	if err != nil {
+		return err
+	}
+	if !item.IsPublished {
+		return nil
+	}
+	tags, err := s.contentRepo.GetTagsByArticleID(ctx, item.ID)
+	if err != nil {
+		return err
+	}
+	followers, err := s.followerRepo.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+	if len(followers) == 0 {
+		return nil
+	}
+
+	activity := createActivity(actor, s.articleObject(actor, item, tags))
+	activity.Context = fedinfra.ActivityStreamsContext
+	seen := make(map[string]struct{}, len(followers))
+	delivered, failed := 0, 0
+	for _, follower := range followers {
+		inbox := follower.Inbox
+		if follower.SharedInbox != nil && strings.TrimSpace(*follower.SharedInbox) != "" {
+			inbox = *follower.SharedInbox
+		}
+		if _, ok := seen[inbox]; ok {
+			continue
+		}
+		seen[inbox] = struct{}{}
+		if err := s.deliver(ctx, inbox, activity); err != nil {
+			failed++
+			log.Printf("[activitypub] 投递失败 inbox=%s err=%v", inbox, err)
+			continue
+		}
+		delivered++
+	}
+	log.Printf("[activitypub] 文章推送完成 article=%d delivered=%d failed=%d", item.ID, delivered, failed)
+	return nil
+}
+
+// SignFetch 为拉取远端 Actor 的 GET 请求签名，兼容开启 authorized fetch 的实例。
+func (s *Service) SignFetch(ctx context.Context, req *http.Request) error {
+	_, keyID, privKey, signer, err := s.signingContext(ctx)
+	if err != nil {
+		return err
+	}
+	return signer.SignRequest(req, nil, keyID, privKey)
+}
+
+func (s *Service) deliver(ctx context.Context, inbox string, activity fedinfra.APActivity) error {
+	_, keyID, privKey, signer, err := s.signingContext(ctx)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+	resp, err := fedinfra.NewClient(s.client, signer).DoSignedWithType(reqCtx, http.MethodPost, inbox, payload, fedinfra.ContentTypeActivityJSON, keyID, privKey)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *Service) signingContext(ctx context.Context) (localActor, string, crypto.PrivateKey, *fedinfra.Signer, error) {
+	actor, err := s.resolveActor(ctx)
+	if err != nil {
+		return localActor{}, "", nil, nil, err
+	}
+	key, err := s.signingKey(ctx, actor.settings)
+	if err != nil {
+		return localActor{}, "", nil, nil, err
+	}
+	privKey, err := fedinfra.ParsePrivateKey(key.PrivateKey)
+	if err != nil {
+		return localActor{}, "", nil, nil, err
+	}
+	signer, err := fedinfra.NewActivityPubSigner(fedinfra.AlgorithmRSASHA256)
+	if err != nil {
+		return localActor{}, "", nil, nil, err
+	}
+	return actor, actor.keyID(), privKey, signer, nil
+}
+
+// signingKey ActivityPub 生态普遍只支持 RSA，因此固定使用 rsa-sha256 密钥。
+func (s *Service) signingKey(ctx context.Context, settings federationconfig.Settings) (*domainfed.FederationSigningKey, error) {
+	if s.keySvc != nil {
+		return s.keySvc.SigningKey(ctx, fedinfra.AlgorithmRSASHA256)
+	}
+	if strings.TrimSpace(settings.PrivateKey) == "" {
+		return nil, errors.New("private key not configured")
+	}
+	return &domainfed.FederationSigningKey{
+		Algorithm:  fedinfra.AlgorithmRSASHA256,
+		PublicKey:  settings.PublicKey,
+		PrivateKey: settings.PrivateKey,
+	}, nil
+}
+
+func sameOrigin(actorURL string, baseURL string) bool {
+	actorHost := hostOf(actorURL)
+	return actorHost != "" && strings.EqualFold(actorHost, hostOf(baseURL))
+}
+
+func hostOf(raw string) string {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
+
+func absoluteURL(baseURL string, raw string) string {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://") {
+		return raw
+	}
+	return baseURL + "/" + strings.TrimLeft(raw, "/")
+}
+
+func shortHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func optionalString(val string) *string {
+	trimmed := strings.TrimSpace(val)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
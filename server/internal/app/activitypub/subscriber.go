@@ -0,0 +1,36 @@
+package activitypub
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/article"
+	appEvent "github.com/grtsinry43/grtblog-v2/server/internal/app/event"
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+)
+
+type handlerFunc func(ctx context.Context, event appEvent.Event) error
+
+func (h handlerFunc) Handle(ctx context.Context, event appEvent.Event) error {
+	return h(ctx, event)
+}
+
+// RegisterSubscribers 在文章发布后异步向关注者推送，投递耗时不阻塞发布请求。
+func RegisterSubscribers(bus appEvent.Bus, service *Service) {
+	if bus == nil || service == nil {
+		return
+	}
+	bus.Subscribe(article.ArticlePublished{}.Name(), handlerFunc(func(ctx context.Context, event appEvent.Event) error {
+		ev, ok := event.(article.ArticlePublished)
+		if !ok {
+			return nil
+		}
+		go func() {
+			if err := service.PublishArticle(context.Background(), ev.ID); err != nil && !errors.Is(err, domainfed.ErrActivityPubDisabled) {
+				log.Printf("[activitypub] 文章推送失败 article=%d err=%v", ev.ID, err)
+			}
+		}()
+		return nil
+	}))
+}
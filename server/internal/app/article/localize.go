@@ -0,0 +1,85 @@
+package article
+
+import (
+	"context"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
+)
+
+// WithImageLocalizer 设置外链图片转存器，保存时 LocalizeImages 为 true 才会转存。
+func (s *Service) WithImageLocalizer(localizer contentutil.ImageLocalizer) *Service {
+	s.localizer = localizer
+	return s
+}
+
+// LocalizeArticleImages 转存已有文章中的外链图片并保存，内容无变化时返回 false。
+func (s *Service) LocalizeArticleImages(ctx context.Context, id int64) (bool, error) {
+	existing, err := s.repo.GetArticleByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	body, leadIn, cover, err := s.localizeImages(ctx, existing.AuthorID, existing.Content, existing.LeadIn, existing.Cover)
+	if err != nil {
+		return false, err
+	}
+	if body == existing.Content && ptrEqual(leadIn, existing.LeadIn) && ptrEqual(cover, existing.Cover) {
+		return false, nil
+	}
+	tags, err := s.repo.GetTagsByArticleID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	tagIDs := make([]int64, len(tags))
+	for i, tag := range tags {
+		tagIDs[i] = tag.ID
+	}
+	_, err = s.UpdateArticle(ctx, UpdateArticleCmd{
+		ID:          existing.ID,
+		Title:       existing.Title,
+		Summary:     existing.Summary,
+		LeadIn:      leadIn,
+		Content:     body,
+		Cover:       cover,
+		CategoryID:  existing.CategoryID,
+		TagIDs:      tagIDs,
+		ShortURL:    existing.ShortURL,
+		IsPublished: existing.IsPublished,
+		IsTop:       existing.IsTop,
+		IsHot:       existing.IsHot,
+		IsOriginal:  existing.IsOriginal,
+	})
+	return err == nil, err
+}
+
+// localizeImages 转存正文、导语与封面中的外链图片，未设置转存器时原样返回。
+func (s *Service) localizeImages(ctx context.Context, authorID int64, body string, leadIn *string, cover *string) (string, *string, *string, error) {
+	if s.localizer == nil {
+		return body, leadIn, cover, nil
+	}
+	body, err := s.localizer.LocalizeMarkdown(ctx, authorID, body)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if leadIn != nil {
+		localized, err := s.localizer.LocalizeMarkdown(ctx, authorID, *leadIn)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		leadIn = &localized
+	}
+	if cover != nil {
+		localized, err := s.localizer.LocalizeURL(ctx, authorID, *cover)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		cover = &localized
+	}
+	return body, leadIn, cover, nil
+}
+
+func ptrEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
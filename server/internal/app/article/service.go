@@ -12,8 +12,9 @@ import (
 )
 
 type Service struct {
-	repo   content.Repository
-	events appEvent.Bus
+	repo      content.Repository
+	events    appEvent.Bus
+	localizer contentutil.ImageLocalizer
 }
 
 func NewService(repo content.Repository, events appEvent.Bus) *Service {
@@ -46,6 +47,13 @@ func (s *Service) CreateArticle(ctx context.Context, authorID int64, cmd CreateA
 		return nil, err
 	}
 
+	if cmd.LocalizeImages {
+		cmd.Content, cmd.LeadIn, cmd.Cover, err = s.localizeImages(ctx, authorID, cmd.Content, cmd.LeadIn, cmd.Cover)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// 设置创建时间
 	createdAt := time.Now()
 	if cmd.CreatedAt != nil {
@@ -126,6 +134,12 @@ func (s *Service) UpdateArticle(ctx context.Context, cmd UpdateArticleCmd) (*con
 	if err := s.ensureTagsExist(ctx, cmd.TagIDs); err != nil {
 		return nil, err
 	}
+	if cmd.LocalizeImages {
+		cmd.Content, cmd.LeadIn, cmd.Cover, err = s.localizeImages(ctx, existing.AuthorID, cmd.Content, cmd.LeadIn, cmd.Cover)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	toc := contentutil.GenerateTOC(cmd.Content)
 	summary := contentutil.BuildSummary(cmd.Summary, cmd.Content)
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+)
+
+// LoginChallenge 是密码校验通过、等待两步验证的登录挑战，存储在 Redis。
+type LoginChallenge struct {
+	UserID int64 `json:"user_id"`
+	// SetupRequired 表示策略要求开启两步验证但用户尚未开启，需先完成绑定。
+	SetupRequired bool `json:"setup_required,omitempty"`
+	Attempts      int  `json:"attempts"`
+	// ExpiresAt 用于重新保存（累加失败次数）时保留剩余有效期。
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChallengeStore 保存登录挑战；Load 在挑战不存在或过期时返回 identity.ErrChallengeNotFound。
+type ChallengeStore interface {
+	Save(ctx context.Context, token string, data LoginChallenge, ttl time.Duration) error
+	Load(ctx context.Context, token string) (*LoginChallenge, error)
+	Delete(ctx context.Context, token string) error
+}
+
+type redisChallengeStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisChallengeStore(client *redis.Client, prefix string) ChallengeStore {
+	return &redisChallengeStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *redisChallengeStore) Save(ctx context.Context, token string, data LoginChallenge, ttl time.Duration) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal challenge: %w", err)
+	}
+	return s.client.Set(ctx, s.key(token), b, ttl).Err()
+}
+
+func (s *redisChallengeStore) Load(ctx context.Context, token string) (*LoginChallenge, error) {
+	val, err := s.client.Get(ctx, s.key(token)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, identity.ErrChallengeNotFound
+		}
+		return nil, err
+	}
+	var data LoginChallenge
+	if err := json.Unmarshal(val, &data); err != nil {
+		return nil, fmt.Errorf("unmarshal challenge: %w", err)
+	}
+	return &data, nil
+}
+
+func (s *redisChallengeStore) Delete(ctx context.Context, token string) error {
+	return s.client.Del(ctx, s.key(token)).Err()
+}
+
+func (s *redisChallengeStore) key(token string) string {
+	return s.prefix + "login_challenge:" + token
+}
+
+// memoryChallengeStore 在未配置 Redis 时使用，仅适用于单实例部署。
+type memoryChallengeStore struct {
+	mu    sync.Mutex
+	items map[string]LoginChallenge
+}
+
+func NewMemoryChallengeStore() ChallengeStore {
+	return &memoryChallengeStore{items: make(map[string]LoginChallenge)}
+}
+
+func (s *memoryChallengeStore) Save(_ context.Context, token string, data LoginChallenge, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, item := range s.items {
+		if now.After(item.ExpiresAt) {
+			delete(s.items, key)
+		}
+	}
+	if data.ExpiresAt.IsZero() {
+		data.ExpiresAt = now.Add(ttl)
+	}
+	s.items[token] = data
+	return nil
+}
+
+func (s *memoryChallengeStore) Load(_ context.Context, token string) (*LoginChallenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.items[token]
+	if !ok || time.Now().After(data.ExpiresAt) {
+		return nil, identity.ErrChallengeNotFound
+	}
+	return &data, nil
+}
+
+func (s *memoryChallengeStore) Delete(_ context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, token)
+	return nil
+}
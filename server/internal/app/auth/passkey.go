@@ -0,0 +1,386 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/config"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	"github.com/grtsinry43/grtblog-v2/server/internal/security/webauthn"
+)
+
+const (
+	passkeyCeremonyRegister = "register"
+	passkeyCeremonyLogin    = "login"
+	maxPasskeysPerUser      = 20
+	maxPasskeyNameLength    = 64
+)
+
+// PasskeyError 是通行密钥校验失败的错误，携带可确定的用户与凭据，供调用方记录审计日志。
+type PasskeyError struct {
+	UserID    int64
+	PasskeyID int64
+	Err       error
+}
+
+func (e *PasskeyError) Error() string { return e.Err.Error() }
+
+func (e *PasskeyError) Unwrap() error { return e.Err }
+
+// PasskeyDescriptor 描述一个已注册凭据，用于 excludeCredentials / allowCredentials。
+type PasskeyDescriptor struct {
+	ID         []byte
+	Transports []string
+}
+
+// PasskeyRegistrationOptions 对应 PublicKeyCredentialCreationOptions。
+type PasskeyRegistrationOptions struct {
+	SessionID   string
+	Challenge   []byte
+	RPID        string
+	RPName      string
+	UserHandle  []byte
+	UserName    string
+	DisplayName string
+	Algorithms  []int64
+	Exclude     []PasskeyDescriptor
+	Timeout     time.Duration
+}
+
+// PasskeyLoginOptions 对应 PublicKeyCredentialRequestOptions；Allow 为空时由浏览器列出可发现凭据。
+type PasskeyLoginOptions struct {
+	SessionID string
+	Challenge []byte
+	RPID      string
+	Allow     []PasskeyDescriptor
+	Timeout   time.Duration
+}
+
+// FinishPasskeyRegistrationCmd 中的二进制字段均为浏览器返回的 base64url 编码。
+type FinishPasskeyRegistrationCmd struct {
+	UserID            int64
+	SessionID         string
+	Name              string
+	ClientDataJSON    string
+	AttestationObject string
+	Transports        []string
+}
+
+// FinishPasskeyLoginCmd 中的二进制字段均为浏览器返回的 base64url 编码。
+type FinishPasskeyLoginCmd struct {
+	SessionID         string
+	CredentialID      string
+	ClientDataJSON    string
+	AuthenticatorData string
+	Signature         string
+	UserHandle        string
+	Client            ClientMeta
+}
+
+// WithPasskeys 开启通行密钥；sessions 为空时只能管理已注册的凭据。
+func (s *Service) WithPasskeys(repo identity.PasskeyRepository, sessions PasskeySessionStore, cfg config.WebAuthnConfig) *Service {
+	s.passkeys = repo
+	s.passkeySessions = sessions
+	s.relyingParty = webauthn.RelyingParty{ID: cfg.RPID, Name: cfg.RPName, Origins: cfg.Origins}
+	s.passkeyTimeout = cfg.Timeout
+	if s.passkeyTimeout <= 0 {
+		s.passkeyTimeout = 5 * time.Minute
+	}
+	return s
+}
+
+// ListPasskeys 返回用户注册的通行密钥。
+func (s *Service) ListPasskeys(ctx context.Context, userID int64) ([]identity.Passkey, error) {
+	if s.passkeys == nil {
+		return nil, identity.ErrPasskeyNotConfigured
+	}
+	return s.passkeys.ListByUserID(ctx, userID)
+}
+
+// RenamePasskey 修改通行密钥的备注名。
+func (s *Service) RenamePasskey(ctx context.Context, userID, id int64, name string) error {
+	if s.passkeys == nil {
+		return identity.ErrPasskeyNotConfigured
+	}
+	return s.passkeys.Rename(ctx, userID, id, normalizePasskeyName(name))
+}
+
+// DeletePasskey 删除用户的通行密钥。
+func (s *Service) DeletePasskey(ctx context.Context, userID, id int64) error {
+	if s.passkeys == nil {
+		return identity.ErrPasskeyNotConfigured
+	}
+	return s.passkeys.Delete(ctx, userID, id)
+}
+
+// BeginPasskeyRegistration 为已登录用户生成注册选项，已注册的凭据放入 excludeCredentials 防止重复注册。
+func (s *Service) BeginPasskeyRegistration(ctx context.Context, userID int64) (*PasskeyRegistrationOptions, error) {
+	if s.passkeys == nil || s.passkeySessions == nil {
+		return nil, identity.ErrPasskeyNotConfigured
+	}
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.passkeys.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxPasskeysPerUser {
+		return nil, identity.ErrPasskeyLimitExceeded
+	}
+	sessionID, challenge, err := s.savePasskeySession(ctx, passkeyCeremonyRegister, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &PasskeyRegistrationOptions{
+		SessionID:   sessionID,
+		Challenge:   challenge,
+		RPID:        s.relyingParty.ID,
+		RPName:      s.relyingParty.Name,
+		UserHandle:  passkeyUserHandle(user.ID),
+		UserName:    user.Username,
+		DisplayName: firstNonEmpty(user.Nickname, user.Username),
+		Algorithms:  webauthn.SupportedAlgorithms,
+		Exclude:     passkeyDescriptors(existing),
+		Timeout:     s.passkeyTimeout,
+	}, nil
+}
+
+// FinishPasskeyRegistration 校验认证器的注册响应并保存凭据。
+func (s *Service) FinishPasskeyRegistration(ctx context.Context, cmd FinishPasskeyRegistrationCmd) (*identity.Passkey, error) {
+	session, err := s.takePasskeySession(ctx, cmd.SessionID, passkeyCeremonyRegister)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != cmd.UserID {
+		return nil, identity.ErrChallengeNotFound
+	}
+	clientDataJSON, err1 := webauthn.DecodeBase64URL(cmd.ClientDataJSON)
+	attestationObject, err2 := webauthn.DecodeBase64URL(cmd.AttestationObject)
+	if err := errors.Join(err1, err2); err != nil {
+		return nil, &PasskeyError{UserID: cmd.UserID, Err: fmt.Errorf("%w: %v", identity.ErrPasskeyVerification, err)}
+	}
+	// 通行密钥用于免密码登录，注册时即要求用户验证，避免登记无法登录的凭据。
+	credential, err := s.relyingParty.VerifyRegistration(session.Challenge, clientDataJSON, attestationObject, true)
+	if err != nil {
+		return nil, &PasskeyError{UserID: cmd.UserID, Err: fmt.Errorf("%w: %v", identity.ErrPasskeyVerification, err)}
+	}
+
+	if _, err := s.passkeys.FindByCredentialID(ctx, credential.ID); err == nil {
+		return nil, identity.ErrPasskeyExists
+	} else if !errors.Is(err, identity.ErrPasskeyNotFound) {
+		return nil, err
+	}
+	existing, err := s.passkeys.ListByUserID(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxPasskeysPerUser {
+		return nil, identity.ErrPasskeyLimitExceeded
+	}
+
+	name := normalizePasskeyName(cmd.Name)
+	if name == "" {
+		name = fmt.Sprintf("通行密钥 %d", len(existing)+1)
+	}
+	passkey := &identity.Passkey{
+		UserID:         cmd.UserID,
+		Name:           name,
+		CredentialID:   credential.ID,
+		PublicKey:      credential.PublicKey,
+		Algorithm:      credential.Algorithm,
+		SignCount:      credential.SignCount,
+		AAGUID:         credential.AAGUID,
+		Transports:     normalizeTransports(cmd.Transports),
+		BackupEligible: credential.BackupEligible,
+		BackupState:    credential.BackupState,
+	}
+	if err := s.passkeys.Create(ctx, passkey); err != nil {
+		return nil, err
+	}
+	return passkey, nil
+}
+
+// BeginPasskeyLogin 生成登录选项；credential 为用户名或邮箱时只允许该用户的凭据，为空时使用可发现凭据。
+// 用户不存在时不报错，按可发现凭据处理。
+func (s *Service) BeginPasskeyLogin(ctx context.Context, credential string) (*PasskeyLoginOptions, error) {
+	if s.passkeys == nil || s.passkeySessions == nil {
+		return nil, identity.ErrPasskeyNotConfigured
+	}
+	var (
+		userID int64
+		allow  []PasskeyDescriptor
+	)
+	if credential = strings.TrimSpace(credential); credential != "" {
+		user, err := s.users.FindByCredential(ctx, credential)
+		if err != nil && !errors.Is(err, identity.ErrInvalidCredentials) {
+			return nil, err
+		}
+		if user != nil {
+			passkeys, err := s.passkeys.ListByUserID(ctx, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			userID, allow = user.ID, passkeyDescriptors(passkeys)
+		}
+	}
+	sessionID, challenge, err := s.savePasskeySession(ctx, passkeyCeremonyLogin, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &PasskeyLoginOptions{
+		SessionID: sessionID,
+		Challenge: challenge,
+		RPID:      s.relyingParty.ID,
+		Allow:     allow,
+		Timeout:   s.passkeyTimeout,
+	}, nil
+}
+
+// FinishPasskeyLogin 校验断言与签名计数后签发 token。
+// 断言要求用户验证，本身已满足两个因素（持有设备 + PIN/生物识别），因此不再进入 TOTP 两步验证。
+func (s *Service) FinishPasskeyLogin(ctx context.Context, cmd FinishPasskeyLoginCmd) (*LoginResult, error) {
+	session, err := s.takePasskeySession(ctx, cmd.SessionID, passkeyCeremonyLogin)
+	if err != nil {
+		return nil, err
+	}
+	credentialID, err := webauthn.DecodeBase64URL(cmd.CredentialID)
+	if err != nil || len(credentialID) == 0 {
+		return nil, &PasskeyError{UserID: session.UserID, Err: identity.ErrPasskeyVerification}
+	}
+	passkey, err := s.passkeys.FindByCredentialID(ctx, credentialID)
+	if err != nil {
+		if errors.Is(err, identity.ErrPasskeyNotFound) {
+			return nil, &PasskeyError{UserID: session.UserID, Err: identity.ErrPasskeyVerification}
+		}
+		return nil, err
+	}
+	fail := func(err error) (*LoginResult, error) {
+		return nil, &PasskeyError{UserID: passkey.UserID, PasskeyID: passkey.ID, Err: err}
+	}
+	if session.UserID != 0 && session.UserID != passkey.UserID {
+		return fail(identity.ErrPasskeyVerification)
+	}
+	if cmd.UserHandle != "" {
+		handle, err := webauthn.DecodeBase64URL(cmd.UserHandle)
+		if err != nil || string(handle) != string(passkeyUserHandle(passkey.UserID)) {
+			return fail(identity.ErrPasskeyVerification)
+		}
+	}
+
+	clientDataJSON, err1 := webauthn.DecodeBase64URL(cmd.ClientDataJSON)
+	authenticatorData, err2 := webauthn.DecodeBase64URL(cmd.AuthenticatorData)
+	signature, err3 := webauthn.DecodeBase64URL(cmd.Signature)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return fail(fmt.Errorf("%w: %v", identity.ErrPasskeyVerification, err))
+	}
+	authData, err := s.relyingParty.VerifyAssertion(session.Challenge, clientDataJSON, authenticatorData, signature, passkey.PublicKey, true)
+	if err != nil {
+		return fail(fmt.Errorf("%w: %v", identity.ErrPasskeyVerification, err))
+	}
+	if !webauthn.SignCountValid(passkey.SignCount, authData.SignCount) {
+		return fail(identity.ErrPasskeySignCount)
+	}
+	// 条件更新防止两个并发断言以相同计数通过。
+	updated, err := s.passkeys.UpdateSignCount(ctx, passkey.ID, authData.SignCount, authData.BackupState(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return fail(identity.ErrPasskeySignCount)
+	}
+
+	user, err := s.users.FindByID(ctx, passkey.UserID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.issueLogin(ctx, user, cmd.Client)
+	if err != nil {
+		return nil, err
+	}
+	result.PasskeyID = passkey.ID
+	return result, nil
+}
+
+func (s *Service) savePasskeySession(ctx context.Context, ceremony string, userID int64) (string, []byte, error) {
+	sessionID, err := randomString(32)
+	if err != nil {
+		return "", nil, err
+	}
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	session := PasskeySession{
+		UserID:    userID,
+		Ceremony:  ceremony,
+		Challenge: challenge,
+		ExpiresAt: now.Add(s.passkeyTimeout),
+		CreatedAt: now,
+	}
+	if err := s.passkeySessions.Save(ctx, sessionID, session, s.passkeyTimeout); err != nil {
+		return "", nil, err
+	}
+	return sessionID, challenge, nil
+}
+
+// takePasskeySession 读取并立即删除仪式挑战，每个挑战只能提交一次。
+func (s *Service) takePasskeySession(ctx context.Context, sessionID, ceremony string) (*PasskeySession, error) {
+	if s.passkeys == nil || s.passkeySessions == nil {
+		return nil, identity.ErrPasskeyNotConfigured
+	}
+	if strings.TrimSpace(sessionID) == "" {
+		return nil, identity.ErrChallengeNotFound
+	}
+	session, err := s.passkeySessions.Load(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.passkeySessions.Delete(ctx, sessionID); err != nil {
+		return nil, err
+	}
+	if session.Ceremony != ceremony {
+		return nil, identity.ErrChallengeNotFound
+	}
+	return session, nil
+}
+
+// passkeyUserHandle 是注册时写入认证器的 user.id，可发现凭据登录时原样返回。
+func passkeyUserHandle(userID int64) []byte {
+	return []byte(strconv.FormatInt(userID, 10))
+}
+
+func passkeyDescriptors(passkeys []identity.Passkey) []PasskeyDescriptor {
+	descriptors := make([]PasskeyDescriptor, len(passkeys))
+	for i, passkey := range passkeys {
+		descriptors[i] = PasskeyDescriptor{ID: passkey.CredentialID, Transports: passkey.Transports}
+	}
+	return descriptors
+}
+
+func normalizePasskeyName(name string) string {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > maxPasskeyNameLength {
+		name = string([]rune(name)[:maxPasskeyNameLength])
+	}
+	return name
+}
+
+// normalizeTransports 只保留规范定义的传输方式。
+func normalizeTransports(transports []string) []string {
+	result := make([]string, 0, len(transports))
+	for _, transport := range transports {
+		switch transport {
+		case "usb", "nfc", "ble", "smart-card", "hybrid", "internal":
+			result = append(result, transport)
+		}
+	}
+	return result
+}
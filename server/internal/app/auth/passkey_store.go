@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+)
+
+// PasskeySession 保存一次 WebAuthn 注册或登录仪式的挑战，存储在 Redis。
+type PasskeySession struct {
+	// UserID 注册时为当前用户；登录时为指定的用户，0 表示由可发现凭据决定。
+	UserID    int64     `json:"user_id,omitempty"`
+	Ceremony  string    `json:"ceremony"`
+	Challenge []byte    `json:"challenge"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PasskeySessionStore 保存 WebAuthn 仪式挑战；Load 在不存在或过期时返回 identity.ErrChallengeNotFound。
+type PasskeySessionStore interface {
+	Save(ctx context.Context, id string, data PasskeySession, ttl time.Duration) error
+	Load(ctx context.Context, id string) (*PasskeySession, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type redisPasskeySessionStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisPasskeySessionStore(client *redis.Client, prefix string) PasskeySessionStore {
+	return &redisPasskeySessionStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *redisPasskeySessionStore) Save(ctx context.Context, id string, data PasskeySession, ttl time.Duration) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal passkey session: %w", err)
+	}
+	return s.client.Set(ctx, s.key(id), b, ttl).Err()
+}
+
+func (s *redisPasskeySessionStore) Load(ctx context.Context, id string) (*PasskeySession, error) {
+	val, err := s.client.Get(ctx, s.key(id)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, identity.ErrChallengeNotFound
+		}
+		return nil, err
+	}
+	var data PasskeySession
+	if err := json.Unmarshal(val, &data); err != nil {
+		return nil, fmt.Errorf("unmarshal passkey session: %w", err)
+	}
+	return &data, nil
+}
+
+func (s *redisPasskeySessionStore) Delete(ctx context.Context, id string) error {
+	return s.client.Del(ctx, s.key(id)).Err()
+}
+
+func (s *redisPasskeySessionStore) key(id string) string {
+	return s.prefix + "passkey_session:" + id
+}
+
+// memoryPasskeySessionStore 在未配置 Redis 时使用，仅适用于单实例部署。
+type memoryPasskeySessionStore struct {
+	mu    sync.Mutex
+	items map[string]PasskeySession
+}
+
+func NewMemoryPasskeySessionStore() PasskeySessionStore {
+	return &memoryPasskeySessionStore{items: make(map[string]PasskeySession)}
+}
+
+func (s *memoryPasskeySessionStore) Save(_ context.Context, id string, data PasskeySession, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, item := range s.items {
+		if now.After(item.ExpiresAt) {
+			delete(s.items, key)
+		}
+	}
+	if data.ExpiresAt.IsZero() {
+		data.ExpiresAt = now.Add(ttl)
+	}
+	s.items[id] = data
+	return nil
+}
+
+func (s *memoryPasskeySessionStore) Load(_ context.Context, id string) (*PasskeySession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.items[id]
+	if !ok || time.Now().After(data.ExpiresAt) {
+		return nil, identity.ErrChallengeNotFound
+	}
+	return &data, nil
+}
+
+func (s *memoryPasskeySessionStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, id)
+	return nil
+}
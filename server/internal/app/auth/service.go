@@ -11,9 +11,11 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/oauth2"
 
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/comment"
 	"github.com/grtsinry43/grtblog-v2/server/internal/config"
 	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
 	"github.com/grtsinry43/grtblog-v2/server/internal/security/jwt"
+	"github.com/grtsinry43/grtblog-v2/server/internal/security/webauthn"
 )
 
 var (
@@ -41,15 +43,39 @@ type Service struct {
 	stateStore StateStore
 	manager    *jwt.Manager
 	providers  map[string]ExternalProvider
+	twoFactor  identity.TwoFactorRepository
+	challenges ChallengeStore
+	policy     TwoFactorPolicy
+	issuer     string
+
+	passkeys        identity.PasskeyRepository
+	passkeySessions PasskeySessionStore
+	relyingParty    webauthn.RelyingParty
+	passkeyTimeout  time.Duration
+
+	sessions   identity.SessionRepository
+	clientInfo comment.ClientInfoResolver
+	geoIP      comment.GeoIPResolver
+	refreshTTL time.Duration
+	sessionMax time.Duration
 }
 
 func NewService(repo identity.Repository, oauthRepo identity.OAuthProviderRepository, manager *jwt.Manager, stateStore StateStore, authCfg config.AuthConfig) *Service {
+	if authCfg.RefreshTTL <= 0 {
+		authCfg.RefreshTTL = 30 * 24 * time.Hour
+	}
+	if authCfg.SessionMaxAge <= 0 {
+		authCfg.SessionMaxAge = 90 * 24 * time.Hour
+	}
+	authCfg.SessionMaxAge = max(authCfg.SessionMaxAge, authCfg.RefreshTTL)
 	return &Service{
 		users:      repo,
 		oauthRepo:  oauthRepo,
 		stateStore: stateStore,
 		manager:    manager,
 		providers:  make(map[string]ExternalProvider),
+		refreshTTL: authCfg.RefreshTTL,
+		sessionMax: authCfg.SessionMaxAge,
 	}
 }
 
@@ -70,12 +96,23 @@ type RegisterCmd struct {
 type LoginCmd struct {
 	Credential string
 	Password   string
+	Client     ClientMeta
 }
 
 type LoginResult struct {
 	Token  string
 	User   identity.User
 	Claims *jwt.Claims
+	// Challenge 非空时需要两步验证，Token 为空。
+	Challenge *LoginChallengeResult
+	// RecoveryCodes 为登录时强制绑定两步验证后生成的恢复码。
+	RecoveryCodes    []string
+	UsedRecoveryCode bool
+	// PasskeyID 为通行密钥登录时使用的凭据。
+	PasskeyID int64
+	// RefreshToken 在开启服务端会话时签发，用于 /auth/refresh 换取新的 access token。
+	RefreshToken     string
+	RefreshExpiresAt time.Time
 }
 
 type UpdateProfileCmd struct {
@@ -89,6 +126,8 @@ type ChangePasswordCmd struct {
 	UserID      int64
 	OldPassword string
 	NewPassword string
+	// SessionKey 为当前会话，改密后保留，其余会话全部吊销。
+	SessionKey string
 }
 
 type AccessInfo struct {
@@ -134,17 +173,7 @@ func (s *Service) Login(ctx context.Context, cmd LoginCmd) (*LoginResult, error)
 	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(cmd.Password)) != nil {
 		return nil, identity.ErrInvalidCredentials
 	}
-	token, claims, err := s.manager.Generate(user.ID, user.IsAdmin)
-	if err != nil {
-		return nil, err
-	}
-	claims.Subject = user.Username
-	user.Password = ""
-	return &LoginResult{
-		Token:  token,
-		User:   *user,
-		Claims: claims,
-	}, nil
+	return s.completeLogin(ctx, user, cmd.Client)
 }
 
 type OAuthLoginCmd struct {
@@ -152,6 +181,7 @@ type OAuthLoginCmd struct {
 	Code     string
 	State    string
 	Redirect string
+	Client   ClientMeta
 }
 
 type OAuthAuthorizeResult struct {
@@ -219,17 +249,7 @@ func (s *Service) LoginWithProvider(ctx context.Context, cmd OAuthLoginCmd) (*Lo
 		}
 	}
 
-	jwtToken, claims, err := s.manager.Generate(user.ID, user.IsAdmin)
-	if err != nil {
-		return nil, err
-	}
-	claims.Subject = user.Username
-	user.Password = ""
-	return &LoginResult{
-		Token:  jwtToken,
-		User:   *user,
-		Claims: claims,
-	}, nil
+	return s.completeLogin(ctx, user, cmd.Client)
 }
 
 // AccessInfo 返回最新的用户、角色与权限信息。
@@ -290,7 +310,15 @@ func (s *Service) ChangePassword(ctx context.Context, cmd ChangePasswordCmd) err
 	if err != nil {
 		return err
 	}
-	return s.users.UpdatePassword(ctx, cmd.UserID, string(hashed))
+	if err := s.users.UpdatePassword(ctx, cmd.UserID, string(hashed)); err != nil {
+		return err
+	}
+	if s.sessions != nil {
+		if _, err := s.sessions.RevokeAll(ctx, cmd.UserID, cmd.SessionKey, SessionRevokedPasswordChange); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *Service) ListOAuthBindings(ctx context.Context, userID int64) ([]identity.UserOAuthBinding, error) {
@@ -0,0 +1,279 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/comment"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	"github.com/grtsinry43/grtblog-v2/server/internal/security/jwt"
+)
+
+// 会话吊销原因，记录在 user_session.revoked_reason。
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedByUser         = "revoked"
+	SessionRevokedAll            = "revoke_all"
+	SessionRevokedPasswordChange = "password_change"
+	SessionRevokedRefreshReuse   = "refresh_reuse"
+)
+
+const maxUserAgentLength = 512
+
+// ClientMeta 是发起登录或刷新请求的客户端信息，用于会话列表展示。
+type ClientMeta struct {
+	IP        string
+	UserAgent string
+}
+
+type RefreshCmd struct {
+	RefreshToken string
+	Client       ClientMeta
+}
+
+// SessionError 是刷新令牌被重放导致会话吊销的错误，携带用户与会话供调用方记录审计日志。
+type SessionError struct {
+	UserID    int64
+	SessionID int64
+	Err       error
+}
+
+func (e *SessionError) Error() string { return e.Err.Error() }
+
+func (e *SessionError) Unwrap() error { return e.Err }
+
+// WithSessions 开启服务端会话：登录签发刷新令牌，access token 的 jti 绑定会话以便吊销。
+// clientInfo 与 geoIP 可为空，为空时会话只记录原始 UA 与 IP。
+func (s *Service) WithSessions(repo identity.SessionRepository, clientInfo comment.ClientInfoResolver, geoIP comment.GeoIPResolver) *Service {
+	s.sessions = repo
+	s.clientInfo = clientInfo
+	s.geoIP = geoIP
+	return s
+}
+
+// Refresh 使用刷新令牌换取新的 access token，并轮换刷新令牌。
+// 已轮换掉的旧令牌再次出现说明令牌可能泄露，此时吊销整个会话。
+func (s *Service) Refresh(ctx context.Context, cmd RefreshCmd) (*LoginResult, error) {
+	if s.sessions == nil {
+		return nil, identity.ErrInvalidRefreshToken
+	}
+	sessionKey, _, ok := strings.Cut(strings.TrimSpace(cmd.RefreshToken), ".")
+	if !ok || sessionKey == "" {
+		return nil, identity.ErrInvalidRefreshToken
+	}
+	session, err := s.sessions.FindByKey(ctx, sessionKey)
+	if err != nil {
+		if errors.Is(err, identity.ErrSessionNotFound) {
+			return nil, identity.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	now := time.Now()
+	deadline := session.CreatedAt.Add(s.sessionMax)
+	if !session.Active(now) || !now.Before(deadline) {
+		return nil, identity.ErrInvalidRefreshToken
+	}
+	hash := hashRefreshToken(cmd.RefreshToken)
+	if session.PreviousTokenHash != "" && hash == session.PreviousTokenHash {
+		if err := s.sessions.RevokeByKey(ctx, session.SessionKey, SessionRevokedRefreshReuse); err != nil && !errors.Is(err, identity.ErrSessionNotFound) {
+			return nil, err
+		}
+		return nil, &SessionError{UserID: session.UserID, SessionID: session.ID, Err: identity.ErrRefreshTokenReused}
+	}
+	if hash != session.RefreshTokenHash {
+		return nil, identity.ErrInvalidRefreshToken
+	}
+
+	user, err := s.users.FindByID(ctx, session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := newRefreshToken(session.SessionKey)
+	if err != nil {
+		return nil, err
+	}
+	touch := identity.SessionTouch{
+		LastSeenAt: now,
+		ExpiresAt:  s.refreshExpiry(now, deadline),
+	}
+	if ip := strings.TrimSpace(cmd.Client.IP); ip != "" {
+		touch.IP, touch.Location = ip, s.resolveLocation(ip)
+	}
+	rotated, err := s.sessions.Rotate(ctx, session.ID, session.RefreshTokenHash, hashRefreshToken(refreshToken), touch)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// 并发刷新时只有一个请求能轮换成功。
+		return nil, identity.ErrInvalidRefreshToken
+	}
+	return s.signLogin(user, session.SessionKey, refreshToken, touch.ExpiresAt)
+}
+
+// ListSessions 返回用户当前有效的会话。
+func (s *Service) ListSessions(ctx context.Context, userID int64) ([]identity.Session, error) {
+	if s.sessions == nil {
+		return nil, nil
+	}
+	return s.sessions.ListActive(ctx, userID, time.Now())
+}
+
+// RevokeSession 吊销用户的一个会话，该会话的 access token 随即失效。
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID int64) error {
+	if s.sessions == nil {
+		return identity.ErrSessionNotFound
+	}
+	return s.sessions.Revoke(ctx, userID, sessionID, SessionRevokedByUser)
+}
+
+// RevokeAllSessions 吊销用户的全部会话；exceptKey 非空时保留该会话（通常是当前会话）。
+func (s *Service) RevokeAllSessions(ctx context.Context, userID int64, exceptKey string) (int64, error) {
+	if s.sessions == nil {
+		return 0, nil
+	}
+	return s.sessions.RevokeAll(ctx, userID, exceptKey, SessionRevokedAll)
+}
+
+// Logout 吊销当前会话。
+func (s *Service) Logout(ctx context.Context, sessionKey string) error {
+	if s.sessions == nil || sessionKey == "" {
+		return nil
+	}
+	err := s.sessions.RevokeByKey(ctx, sessionKey, SessionRevokedLogout)
+	if errors.Is(err, identity.ErrSessionNotFound) {
+		return nil
+	}
+	return err
+}
+
+// issueLogin 为通过认证的用户创建会话并签发 access token 与刷新令牌；未开启会话时只签发 access token。
+func (s *Service) issueLogin(ctx context.Context, user *identity.User, client ClientMeta) (*LoginResult, error) {
+	if s.sessions == nil {
+		return s.signLogin(user, "", "", time.Time{})
+	}
+	sessionKey, err := randomString(24)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := newRefreshToken(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &identity.Session{
+		SessionKey:       sessionKey,
+		UserID:           user.ID,
+		RefreshTokenHash: hashRefreshToken(refreshToken),
+		UserAgent:        truncateRunes(strings.TrimSpace(client.UserAgent), maxUserAgentLength),
+		IP:               strings.TrimSpace(client.IP),
+		LastSeenAt:       now,
+		ExpiresAt:        s.refreshExpiry(now, now.Add(s.sessionMax)),
+	}
+	if s.clientInfo != nil && session.UserAgent != "" {
+		info := s.clientInfo.Resolve(session.UserAgent)
+		session.Browser = truncateRunes(info.Browser, 64)
+		session.Platform = truncateRunes(info.Platform, 64)
+	}
+	session.Location = s.resolveLocation(session.IP)
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return s.signLogin(user, sessionKey, refreshToken, session.ExpiresAt)
+}
+
+// refreshExpiry 返回刷新令牌的过期时间：从 now 起延长 refreshTTL，但不超过会话的最长存活时间 deadline。
+func (s *Service) refreshExpiry(now, deadline time.Time) time.Time {
+	if expiresAt := now.Add(s.refreshTTL); expiresAt.Before(deadline) {
+		return expiresAt
+	}
+	return deadline
+}
+
+func (s *Service) signLogin(user *identity.User, sessionKey, refreshToken string, refreshExpiresAt time.Time) (*LoginResult, error) {
+	token, claims, err := s.manager.Generate(user.ID, user.IsAdmin, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	claims.Subject = user.Username
+	user.Password = ""
+	return &LoginResult{
+		Token:            token,
+		User:             *user,
+		Claims:           claims,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+func (s *Service) resolveLocation(ip string) string {
+	if s.geoIP == nil || ip == "" {
+		return ""
+	}
+	return truncateRunes(strings.TrimSpace(s.geoIP.Resolve(ip)), 128)
+}
+
+// newRefreshToken 生成 "<sessionKey>.<随机串>" 形式的刷新令牌，前缀用于定位会话。
+func newRefreshToken(sessionKey string) (string, error) {
+	secret, err := randomString(32)
+	if err != nil {
+		return "", err
+	}
+	return sessionKey + "." + secret, nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit])
+}
+
+type sessionChecker struct {
+	repo identity.SessionRepository
+}
+
+// NewSessionChecker 返回供 jwt.Manager 使用的会话校验：jti 对应的会话须存在、属于该用户且未吊销、未过期。
+func NewSessionChecker(repo identity.SessionRepository) jwt.SessionChecker {
+	return &sessionChecker{repo: repo}
+}
+
+func (c *sessionChecker) CheckSession(ctx context.Context, claims *jwt.Claims) error {
+	session, err := c.repo.FindByKey(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, identity.ErrSessionNotFound) {
+			return jwt.ErrRevokedToken
+		}
+		return err
+	}
+	if session.UserID != claims.UserID || !session.Active(time.Now()) {
+		return jwt.ErrRevokedToken
+	}
+	return nil
+}
+
+// StartSessionCleanup 定期删除过期或吊销超过 retention 的会话记录。
+func StartSessionCleanup(repo identity.SessionRepository, interval, retention time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			removed, err := repo.DeleteExpired(context.Background(), time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("[auth] 清理过期会话失败 err=%v", err)
+			} else if removed > 0 {
+				log.Printf("[auth] 已清理过期会话 count=%d", removed)
+			}
+		}
+	}()
+}
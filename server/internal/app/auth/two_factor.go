@@ -0,0 +1,389 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	"github.com/grtsinry43/grtblog-v2/server/internal/security/totp"
+)
+
+const (
+	loginChallengeTTL    = 5 * time.Minute
+	maxChallengeAttempts = 5
+	recoveryCodeCount    = 10
+)
+
+var recoveryEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+// TwoFactorPolicy 提供两步验证的强制策略，通常由 sysconfig.Service 实现。
+type TwoFactorPolicy interface {
+	AdminTwoFactorRequired(ctx context.Context) bool
+}
+
+// TwoFactorError 是登录第二步验证失败的错误，携带用户与剩余尝试次数，供调用方记录审计日志。
+type TwoFactorError struct {
+	UserID    int64
+	Remaining int
+	Err       error
+}
+
+func (e *TwoFactorError) Error() string { return e.Err.Error() }
+
+func (e *TwoFactorError) Unwrap() error { return e.Err }
+
+// LoginChallengeResult 是需要两步验证时 Login 返回的挑战。
+type LoginChallengeResult struct {
+	Token         string
+	ExpiresAt     time.Time
+	SetupRequired bool
+}
+
+type TwoFactorStatus struct {
+	Enabled           bool
+	Required          bool
+	EnabledAt         *time.Time
+	RecoveryCodesLeft int64
+}
+
+// TwoFactorSetup 是待验证的 TOTP 密钥，URI 用于生成二维码。
+type TwoFactorSetup struct {
+	Secret string
+	URI    string
+}
+
+type VerifyLoginCmd struct {
+	ChallengeToken string
+	Code           string
+	RecoveryCode   string
+	Client         ClientMeta
+}
+
+type DisableTwoFactorCmd struct {
+	UserID   int64
+	Password string
+	Code     string
+}
+
+// WithTwoFactor 开启两步验证；challenges 为空时只能管理设置，无法完成两步登录。
+func (s *Service) WithTwoFactor(repo identity.TwoFactorRepository, challenges ChallengeStore, policy TwoFactorPolicy, issuer string) *Service {
+	s.twoFactor = repo
+	s.challenges = challenges
+	s.policy = policy
+	s.issuer = issuer
+	return s
+}
+
+// TwoFactorStatus 返回用户的两步验证状态。
+func (s *Service) TwoFactorStatus(ctx context.Context, userID int64) (*TwoFactorStatus, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &TwoFactorStatus{Required: s.twoFactorRequired(ctx, user)}
+	if s.twoFactor == nil {
+		return status, nil
+	}
+	tf, err := s.twoFactor.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, identity.ErrTwoFactorNotSetup) {
+			return status, nil
+		}
+		return nil, err
+	}
+	if !tf.Enabled {
+		return status, nil
+	}
+	status.Enabled = true
+	status.EnabledAt = tf.EnabledAt
+	status.RecoveryCodesLeft, err = s.twoFactor.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// SetupTwoFactor 生成新的 TOTP 密钥，需调用 EnableTwoFactor 验证后才生效；重复调用会替换未生效的密钥。
+func (s *Service) SetupTwoFactor(ctx context.Context, userID int64) (*TwoFactorSetup, error) {
+	if s.twoFactor == nil {
+		return nil, identity.ErrTwoFactorNotEnabled
+	}
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.twoFactor.FindByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, identity.ErrTwoFactorNotSetup) {
+		return nil, err
+	}
+	if existing != nil && existing.Enabled {
+		return nil, identity.ErrTwoFactorAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactor.Save(ctx, &identity.TwoFactor{UserID: userID, Secret: secret}); err != nil {
+		return nil, err
+	}
+	return &TwoFactorSetup{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(s.issuer, user.Username, secret),
+	}, nil
+}
+
+// EnableTwoFactor 校验验证码后启用两步验证，返回一次性恢复码明文（只展示这一次）。
+func (s *Service) EnableTwoFactor(ctx context.Context, userID int64, code string) ([]string, error) {
+	if s.twoFactor == nil {
+		return nil, identity.ErrTwoFactorNotEnabled
+	}
+	tf, err := s.twoFactor.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.Enabled {
+		return nil, identity.ErrTwoFactorAlreadyEnabled
+	}
+	step, ok := totp.Validate(tf.Secret, code, time.Now())
+	if !ok {
+		return nil, identity.ErrInvalidTwoFactorCode
+	}
+	now := time.Now()
+	tf.Enabled = true
+	tf.EnabledAt = &now
+	tf.LastUsedStep = step
+	if err := s.twoFactor.Save(ctx, tf); err != nil {
+		return nil, err
+	}
+	return s.resetRecoveryCodes(ctx, userID)
+}
+
+// DisableTwoFactor 关闭两步验证，需同时提供密码（未设置密码的第三方登录账号除外）与验证码或恢复码。
+// 策略要求管理员开启两步验证时，管理员无法关闭。
+func (s *Service) DisableTwoFactor(ctx context.Context, cmd DisableTwoFactorCmd) error {
+	if s.twoFactor == nil {
+		return identity.ErrTwoFactorNotEnabled
+	}
+	user, err := s.users.FindByID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Password != "" && bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(cmd.Password)) != nil {
+		return identity.ErrInvalidCredentials
+	}
+	if s.twoFactorRequired(ctx, user) {
+		return identity.ErrTwoFactorRequired
+	}
+	if err := s.verifySecondFactor(ctx, cmd.UserID, cmd.Code); err != nil {
+		return err
+	}
+	return s.twoFactor.Delete(ctx, cmd.UserID)
+}
+
+// RegenerateRecoveryCodes 校验验证码后作废旧恢复码并生成新的一组。
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	if s.twoFactor == nil {
+		return nil, identity.ErrTwoFactorNotEnabled
+	}
+	if err := s.verifyCode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	return s.resetRecoveryCodes(ctx, userID)
+}
+
+// BeginTwoFactorSetup 在强制开启两步验证的登录挑战中生成密钥，用户随后通过 VerifyLogin 提交验证码完成绑定与登录。
+func (s *Service) BeginTwoFactorSetup(ctx context.Context, challengeToken string) (*TwoFactorSetup, error) {
+	challenge, err := s.loadChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if !challenge.SetupRequired {
+		return nil, identity.ErrTwoFactorAlreadyEnabled
+	}
+	return s.SetupTwoFactor(ctx, challenge.UserID)
+}
+
+// VerifyLogin 完成登录第二步：校验验证码或恢复码后签发 token。
+// 挑战要求绑定时，验证码用于启用两步验证，结果中带回恢复码。
+func (s *Service) VerifyLogin(ctx context.Context, cmd VerifyLoginCmd) (*LoginResult, error) {
+	challenge, err := s.loadChallenge(ctx, cmd.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.users.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	switch {
+	case challenge.SetupRequired:
+		recoveryCodes, err = s.EnableTwoFactor(ctx, user.ID, cmd.Code)
+	case strings.TrimSpace(cmd.RecoveryCode) != "":
+		err = s.useRecoveryCode(ctx, user.ID, cmd.RecoveryCode)
+	default:
+		err = s.verifyCode(ctx, user.ID, cmd.Code)
+	}
+	if err != nil {
+		if !errors.Is(err, identity.ErrInvalidTwoFactorCode) {
+			return nil, err
+		}
+		challenge.Attempts++
+		remaining := maxChallengeAttempts - challenge.Attempts
+		if remaining <= 0 {
+			_ = s.challenges.Delete(ctx, cmd.ChallengeToken)
+		} else if saveErr := s.challenges.Save(ctx, cmd.ChallengeToken, *challenge, time.Until(challenge.ExpiresAt)); saveErr != nil {
+			return nil, saveErr
+		}
+		return nil, &TwoFactorError{UserID: user.ID, Remaining: max(remaining, 0), Err: err}
+	}
+	_ = s.challenges.Delete(ctx, cmd.ChallengeToken)
+
+	result, err := s.issueLogin(ctx, user, cmd.Client)
+	if err != nil {
+		return nil, err
+	}
+	result.RecoveryCodes = recoveryCodes
+	result.UsedRecoveryCode = !challenge.SetupRequired && strings.TrimSpace(cmd.RecoveryCode) != ""
+	return result, nil
+}
+
+// completeLogin 在第一步认证（密码或第三方登录）通过后调用：需要两步验证时返回挑战，否则直接签发 token。
+func (s *Service) completeLogin(ctx context.Context, user *identity.User, client ClientMeta) (*LoginResult, error) {
+	if s.twoFactor == nil || s.challenges == nil {
+		return s.issueLogin(ctx, user, client)
+	}
+	tf, err := s.twoFactor.FindByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, identity.ErrTwoFactorNotSetup) {
+		return nil, err
+	}
+	enabled := tf != nil && tf.Enabled
+	if !enabled && !s.twoFactorRequired(ctx, user) {
+		return s.issueLogin(ctx, user, client)
+	}
+
+	token, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	challenge := LoginChallenge{
+		UserID:        user.ID,
+		SetupRequired: !enabled,
+		ExpiresAt:     now.Add(loginChallengeTTL),
+		CreatedAt:     now,
+	}
+	if err := s.challenges.Save(ctx, token, challenge, loginChallengeTTL); err != nil {
+		return nil, err
+	}
+	user.Password = ""
+	return &LoginResult{
+		User: *user,
+		Challenge: &LoginChallengeResult{
+			Token:         token,
+			ExpiresAt:     challenge.ExpiresAt,
+			SetupRequired: challenge.SetupRequired,
+		},
+	}, nil
+}
+
+func (s *Service) loadChallenge(ctx context.Context, token string) (*LoginChallenge, error) {
+	if s.challenges == nil || s.twoFactor == nil {
+		return nil, identity.ErrTwoFactorNotEnabled
+	}
+	if strings.TrimSpace(token) == "" {
+		return nil, identity.ErrChallengeNotFound
+	}
+	return s.challenges.Load(ctx, token)
+}
+
+func (s *Service) twoFactorRequired(ctx context.Context, user *identity.User) bool {
+	return user.IsAdmin && s.policy != nil && s.policy.AdminTwoFactorRequired(ctx)
+}
+
+// verifySecondFactor 接受 6 位验证码或恢复码。
+func (s *Service) verifySecondFactor(ctx context.Context, userID int64, code string) error {
+	if len(normalizeRecoveryCode(code)) == totp.Digits {
+		return s.verifyCode(ctx, userID, code)
+	}
+	return s.useRecoveryCode(ctx, userID, code)
+}
+
+// verifyCode 校验 TOTP 验证码，同一时间步的验证码只能使用一次。
+func (s *Service) verifyCode(ctx context.Context, userID int64, code string) error {
+	tf, err := s.twoFactor.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, identity.ErrTwoFactorNotSetup) {
+			return identity.ErrTwoFactorNotEnabled
+		}
+		return err
+	}
+	if !tf.Enabled {
+		return identity.ErrTwoFactorNotEnabled
+	}
+	step, ok := totp.Validate(tf.Secret, code, time.Now())
+	if !ok {
+		return identity.ErrInvalidTwoFactorCode
+	}
+	fresh, err := s.twoFactor.MarkStepUsed(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return identity.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (s *Service) useRecoveryCode(ctx context.Context, userID int64, code string) error {
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return identity.ErrInvalidTwoFactorCode
+	}
+	used, err := s.twoFactor.UseRecoveryCode(ctx, userID, hashRecoveryCode(normalized))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return identity.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (s *Service) resetRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := recoveryEncoding.EncodeToString(b)[:10]
+		codes[i] = fmt.Sprintf("%s-%s", raw[:5], raw[5:])
+		hashes[i] = hashRecoveryCode(raw)
+	}
+	if err := s.twoFactor.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// hashRecoveryCode 恢复码为 50 位随机值，直接用 SHA-256 即可防止泄库后被还原，且便于按哈希查找。
+func hashRecoveryCode(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
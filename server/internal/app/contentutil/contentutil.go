@@ -3,9 +3,12 @@ package contentutil
 // 把公共逻辑抽取了下，文章手记页面都能用欸
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"html"
 	"regexp"
 	"strings"
 	"unicode"
@@ -25,12 +28,18 @@ type heading struct {
 var markdownParser = goldmark.New()
 var tocAnchorSanitizer = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
 var shortURLSanitizer = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+var htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</p>`)
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+var htmlImgSrcPattern = regexp.MustCompile(`(?i)<img\b[^>]*?\bsrc\s*=\s*["']([^"']+)["']`)
+var markdownImageDestPattern = regexp.MustCompile(`!\[[^\]]*\]\(\s*(<[^>\n]*>|[^\s)]+)`)
+var markdownLinkDefPattern = regexp.MustCompile(`(?m)^ {0,3}\[[^\]\n]+\]:[ \t]*(<[^>\n]*>|\S+)`)
 
 const (
 	CommentAreaTypeArticle  = "article"
 	CommentAreaTypeMoment   = "moment"
 	CommentAreaTypePage     = "page"
 	CommentAreaTypeThinking = "thinking"
+	CommentAreaTypePhoto    = "photo"
 )
 
 func BuildCommentAreaName(areaType, title string) string {
@@ -91,6 +100,158 @@ func BuildSummary(summary, content string) string {
 	return truncateRunes(content, 200)
 }
 
+// RenderHTML 将 Markdown 渲染为 HTML，原始 HTML 会被过滤（goldmark 默认不开启 unsafe）。
+func RenderHTML(markdown string) string {
+	var buf bytes.Buffer
+	if err := markdownParser.Convert([]byte(markdown), &buf); err != nil {
+		return "<p>" + html.EscapeString(markdown) + "</p>"
+	}
+	return buf.String()
+}
+
+// StripHTML 将外部 HTML（如联合实例的回复）转换为纯文本，段落与换行保留为换行。
+func StripHTML(input string) string {
+	text := htmlBreakPattern.ReplaceAllString(input, "\n")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			kept = append(kept, trimmed)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// ExtractLinks 按出现顺序返回 Markdown 中的链接地址（含自动链接，不含图片），已去重。
+func ExtractLinks(markdown string) []string {
+	source := []byte(markdown)
+	doc := markdownParser.Parser().Parse(text.NewReader(source))
+	seen := make(map[string]struct{})
+	var links []string
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var dest string
+		switch n := node.(type) {
+		case *ast.Link:
+			dest = string(n.Destination)
+		case *ast.AutoLink:
+			if n.AutoLinkType == ast.AutoLinkURL {
+				dest = string(n.URL(source))
+			}
+		default:
+			return ast.WalkContinue, nil
+		}
+		dest = strings.TrimSpace(dest)
+		if dest == "" {
+			return ast.WalkContinue, nil
+		}
+		if _, ok := seen[dest]; !ok {
+			seen[dest] = struct{}{}
+			links = append(links, dest)
+		}
+		return ast.WalkContinue, nil
+	})
+	return links
+}
+
+// ExtractImageURLs 按出现顺序返回 Markdown 中的图片地址（含引用式图片与内嵌的 <img> 标签），已去重。
+func ExtractImageURLs(markdown string) []string {
+	doc := markdownParser.Parser().Parse(text.NewReader([]byte(markdown)))
+	seen := make(map[string]struct{})
+	var urls []string
+	add := func(dest string) {
+		dest = strings.TrimSpace(dest)
+		if dest == "" {
+			return
+		}
+		if _, ok := seen[dest]; !ok {
+			seen[dest] = struct{}{}
+			urls = append(urls, dest)
+		}
+	}
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if img, ok := node.(*ast.Image); ok && entering {
+			add(string(img.Destination))
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, match := range htmlImgSrcPattern.FindAllStringSubmatch(markdown, -1) {
+		add(html.UnescapeString(match[1]))
+	}
+	return urls
+}
+
+// ReplaceImageURLs 按 replacements 改写 Markdown 中的图片地址，只替换内联图片、引用定义与 <img> 标签的 src 本身，
+// 正文、链接文字等位置出现的相同字符串保持不变。
+func ReplaceImageURLs(markdown string, replacements map[string]string) string {
+	if len(replacements) == 0 {
+		return markdown
+	}
+	markdown = replaceSubmatch(markdown, markdownImageDestPattern, func(dest string) (string, bool) {
+		return replaceMarkdownDest(dest, replacements)
+	})
+	markdown = replaceSubmatch(markdown, markdownLinkDefPattern, func(dest string) (string, bool) {
+		return replaceMarkdownDest(dest, replacements)
+	})
+	return replaceSubmatch(markdown, htmlImgSrcPattern, func(src string) (string, bool) {
+		local, ok := replacements[strings.TrimSpace(html.UnescapeString(src))]
+		if !ok {
+			return "", false
+		}
+		return html.EscapeString(local), true
+	})
+}
+
+// replaceMarkdownDest 替换 Markdown 链接目标，保留尖括号写法。
+func replaceMarkdownDest(dest string, replacements map[string]string) (string, bool) {
+	if strings.HasPrefix(dest, "<") && strings.HasSuffix(dest, ">") {
+		local, ok := replacements[strings.TrimSpace(dest[1:len(dest)-1])]
+		if !ok {
+			return "", false
+		}
+		return "<" + local + ">", true
+	}
+	local, ok := replacements[dest]
+	return local, ok
+}
+
+// replaceSubmatch 对 pattern 每个匹配的第一个分组调用 replace，仅改写返回 true 的分组。
+func replaceSubmatch(s string, pattern *regexp.Regexp, replace func(string) (string, bool)) string {
+	matches := pattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2], m[3]
+		next, ok := replace(s[start:end])
+		if !ok {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(next)
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// ImageLocalizer 把外链图片转存到本站。单张图片失败时保留原地址，只有无法继续（如 ctx 取消）时才返回 error。
+type ImageLocalizer interface {
+	// LocalizeMarkdown 转存 Markdown 中的外链图片并返回改写后的内容。
+	LocalizeMarkdown(ctx context.Context, userID int64, markdown string) (string, error)
+	// LocalizeURL 转存单个图片地址（如封面），非外链时原样返回。
+	LocalizeURL(ctx context.Context, userID int64, rawURL string) (string, error)
+}
+
 func GenerateShortURLFromTitle(title string) string {
 	args := pinyin.NewArgs()
 	args.Style = pinyin.Normal
@@ -0,0 +1,288 @@
+package federation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/comment"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/content"
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+)
+
+// 跨站评论审核状态。
+const (
+	RemoteCommentPending  = "pending"
+	RemoteCommentApproved = "approved"
+	RemoteCommentRejected = "rejected"
+)
+
+// maxRemoteCommentRunes 入站评论正文长度上限，超出部分截断。
+const maxRemoteCommentRunes = 2000
+
+// CommentService 处理跨站评论：入站评论默认待审核，审核通过后写入目标文章评论区；
+// 出站方向由后台以本站身份回复友站文章。
+type CommentService struct {
+	repo         domainfed.FederatedCommentRepository
+	commentRepo  comment.CommentRepository
+	contentRepo  content.Repository
+	linkRepo     social.FriendLinkRepository
+	instanceRepo domainfed.FederationInstanceRepository
+	postCache    domainfed.FederatedPostCacheRepository
+	outbound     *OutboundService
+}
+
+func NewCommentService(
+	repo domainfed.FederatedCommentRepository,
+	commentRepo comment.CommentRepository,
+	contentRepo content.Repository,
+	linkRepo social.FriendLinkRepository,
+	instanceRepo domainfed.FederationInstanceRepository,
+	postCache domainfed.FederatedPostCacheRepository,
+	outbound *OutboundService,
+) *CommentService {
+	return &CommentService{
+		repo:         repo,
+		commentRepo:  commentRepo,
+		contentRepo:  contentRepo,
+		linkRepo:     linkRepo,
+		instanceRepo: instanceRepo,
+		postCache:    postCache,
+		outbound:     outbound,
+	}
+}
+
+// RemoteComment 已通过签名校验的入站评论。
+type RemoteComment struct {
+	Instance     *domainfed.FederationInstance
+	RemoteID     string
+	Article      *content.Article
+	AuthorName   string
+	AuthorURL    string
+	AuthorAvatar string
+	Content      string
+	AutoApprove  bool
+}
+
+// ResolveTarget 按文章 ID 或短链解析评论目标，未发布或未开放评论的文章不接收跨站评论。
+func (s *CommentService) ResolveTarget(ctx context.Context, targetID string) (*content.Article, error) {
+	var article *content.Article
+	var err error
+	if numericID, parseErr := strconv.ParseInt(targetID, 10, 64); parseErr == nil {
+		article, err = s.contentRepo.GetArticleByID(ctx, numericID)
+	} else {
+		article, err = s.contentRepo.GetArticleByShortURL(ctx, targetID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !article.IsPublished {
+		return nil, content.ErrArticleNotFound
+	}
+	if _, err := s.openArea(ctx, article); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// Receive 记录入站评论；同一实例重复投递的评论直接返回已有记录。
+func (s *CommentService) Receive(ctx context.Context, in RemoteComment) (*domainfed.FederatedComment, error) {
+	if in.Instance == nil || in.Article == nil {
+		return nil, errors.New("remote comment target not resolved")
+	}
+	existing, err := s.repo.FindByRemoteID(ctx, in.Instance.ID, in.RemoteID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, domainfed.ErrFederatedCommentNotFound) {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(contentutil.StripHTML(in.Content))
+	if runes := []rune(text); len(runes) > maxRemoteCommentRunes {
+		text = string(runes[:maxRemoteCommentRunes])
+	}
+	item := &domainfed.FederatedComment{
+		SourceInstanceID: in.Instance.ID,
+		RemoteCommentID:  in.RemoteID,
+		TargetArticleID:  in.Article.ID,
+		AuthorName:       strings.TrimSpace(in.AuthorName),
+		AuthorURL:        toOptionalString(in.AuthorURL),
+		AuthorAvatar:     toOptionalString(in.AuthorAvatar),
+		Content:          text,
+		Status:           RemoteCommentPending,
+	}
+	if err := s.repo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+	if !in.AutoApprove {
+		return item, nil
+	}
+	if err := s.approve(ctx, item, false); err != nil {
+		log.Printf("[federation] 跨站评论自动通过失败 id=%d err=%v", item.ID, err)
+	}
+	return item, nil
+}
+
+func (s *CommentService) List(ctx context.Context, options domainfed.FederatedCommentListOptions) ([]domainfed.FederatedComment, int64, error) {
+	return s.repo.List(ctx, options)
+}
+
+// Approve 审核通过并写入目标文章评论区。
+func (s *CommentService) Approve(ctx context.Context, id int64) (*domainfed.FederatedComment, error) {
+	item, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status == RemoteCommentApproved {
+		return nil, domainfed.ErrFederatedCommentReviewed
+	}
+	if err := s.approve(ctx, item, true); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// Reject 拒绝评论；已通过的评论同时从评论区撤下。
+func (s *CommentService) Reject(ctx context.Context, id int64, reason string) (*domainfed.FederatedComment, error) {
+	item, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status == RemoteCommentRejected {
+		return nil, domainfed.ErrFederatedCommentReviewed
+	}
+	if item.CommentID != nil {
+		if err := s.commentRepo.Delete(ctx, *item.CommentID); err != nil && !errors.Is(err, comment.ErrCommentNotFound) {
+			return nil, err
+		}
+		item.CommentID = nil
+	}
+	now := time.Now().UTC()
+	item.Status = RemoteCommentRejected
+	item.RejectReason = toOptionalString(reason)
+	item.ReviewedAt = &now
+	if err := s.repo.Update(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *CommentService) approve(ctx context.Context, item *domainfed.FederatedComment, viewed bool) error {
+	article, err := s.contentRepo.GetArticleByID(ctx, item.TargetArticleID)
+	if err != nil {
+		return err
+	}
+	areaID, err := s.openArea(ctx, article)
+	if err != nil {
+		return err
+	}
+	instance, err := s.instanceRepo.GetByID(ctx, item.SourceInstanceID)
+	if err != nil {
+		return err
+	}
+
+	nickname := item.AuthorName
+	if host := hostOf(instance.BaseURL); host != "" {
+		nickname = item.AuthorName + "@" + host
+	}
+	website := instance.BaseURL
+	if item.AuthorURL != nil {
+		website = *item.AuthorURL
+	}
+	platform := "Federation"
+	local := &comment.Comment{
+		AreaID:   areaID,
+		Content:  item.Content,
+		NickName: &nickname,
+		Website:  &website,
+		Platform: &platform,
+		IsFriend: s.isFriendLink(ctx, instance.BaseURL),
+		IsViewed: viewed,
+	}
+	if err := s.commentRepo.Create(ctx, local); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	item.Status = RemoteCommentApproved
+	item.CommentID = &local.ID
+	item.RejectReason = nil
+	item.ReviewedAt = &now
+	return s.repo.Update(ctx, item)
+}
+
+// openArea 返回文章的评论区 ID，评论区不存在或已关闭时拒绝。
+func (s *CommentService) openArea(ctx context.Context, article *content.Article) (int64, error) {
+	if article.CommentID == nil {
+		return 0, domainfed.ErrRemoteCommentNotAllowed
+	}
+	area, err := s.commentRepo.GetAreaByID(ctx, *article.CommentID)
+	if err != nil {
+		return 0, err
+	}
+	if area.IsClosed {
+		return 0, domainfed.ErrRemoteCommentNotAllowed
+	}
+	return area.ID, nil
+}
+
+func (s *CommentService) isFriendLink(ctx context.Context, baseURL string) bool {
+	if s.linkRepo == nil {
+		return false
+	}
+	_, err := s.linkRepo.FindByURL(ctx, strings.TrimRight(baseURL, "/"))
+	return err == nil
+}
+
+// ReplyCommand 后台以本站身份回复友站文章。
+type ReplyCommand struct {
+	TargetInstance string
+	TargetPostID   string
+	Content        string
+	AuthorName     string
+	AuthorAvatar   string
+}
+
+// Reply 发送出站评论；对端时间线缓存中标记为不可评论的文章直接拒绝。
+func (s *CommentService) Reply(ctx context.Context, cmd ReplyCommand) (*http.Response, []byte, error) {
+	if s.outbound == nil {
+		return nil, nil, errors.New("outbound service not configured")
+	}
+	if s.instanceRepo != nil && s.postCache != nil {
+		if instance, err := s.instanceRepo.GetByBaseURL(ctx, s.outbound.resolveTargetBaseURL(ctx, cmd.TargetInstance)); err == nil {
+			post, err := s.postCache.GetByRemoteID(ctx, instance.ID, cmd.TargetPostID)
+			if err == nil && !post.AllowComment {
+				return nil, nil, domainfed.ErrRemoteCommentNotAllowed
+			}
+		}
+	}
+	remoteID, err := newRemoteCommentID()
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.outbound.SendComment(ctx, CommentOutgoing{
+		TargetInstance: cmd.TargetInstance,
+		TargetPostID:   cmd.TargetPostID,
+		CommentID:      remoteID,
+		AuthorName:     cmd.AuthorName,
+		AuthorAvatar:   cmd.AuthorAvatar,
+		Content:        cmd.Content,
+	})
+}
+
+func newRemoteCommentID() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,202 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+)
+
+// 出站友链申请状态。
+const (
+	OutboundFriendLinkPending  = "pending"
+	OutboundFriendLinkSent     = "sent"
+	OutboundFriendLinkFailed   = "failed"
+	OutboundFriendLinkApproved = "approved"
+	OutboundFriendLinkRejected = "rejected"
+)
+
+// maxStoredResponseBytes 记录远端响应体的长度上限。
+const maxStoredResponseBytes = 4000
+
+// FriendLinkRequestService 记录本站发出的友链申请，并在对方回执通过后建立本地联合友链。
+type FriendLinkRequestService struct {
+	repo      domainfed.OutboundFriendLinkRepository
+	linkRepo  social.FriendLinkRepository
+	instances *InstanceService
+	outbound  *OutboundService
+}
+
+func NewFriendLinkRequestService(
+	repo domainfed.OutboundFriendLinkRepository,
+	linkRepo social.FriendLinkRepository,
+	instances *InstanceService,
+	outbound *OutboundService,
+) *FriendLinkRequestService {
+	return &FriendLinkRequestService{
+		repo:      repo,
+		linkRepo:  linkRepo,
+		instances: instances,
+		outbound:  outbound,
+	}
+}
+
+// Request 发起友链申请并记录远端响应；对方自动通过时直接建立友链。
+// 发送失败时记录仍会保留为 failed，同时返回错误。
+func (s *FriendLinkRequestService) Request(ctx context.Context, target string, message string, rssURL string) (*domainfed.OutboundFriendLinkRequest, error) {
+	if s.outbound == nil {
+		return nil, errors.New("outbound service not configured")
+	}
+	baseURL := resolveBaseURL(ctx, s.instances.instanceRepo, target)
+	if baseURL == "" {
+		return nil, errors.New("target instance is empty")
+	}
+	if err := s.instances.CheckInstance(ctx, baseURL); err != nil {
+		return nil, err
+	}
+	item := &domainfed.OutboundFriendLinkRequest{
+		TargetURL: baseURL,
+		Message:   toOptionalString(message),
+		RSSURL:    toOptionalString(rssURL),
+		Status:    OutboundFriendLinkPending,
+	}
+	if known, err := s.instances.instanceRepo.GetByBaseURL(ctx, baseURL); err == nil {
+		item.InstanceID = &known.ID
+	}
+	if err := s.repo.Create(ctx, item); err != nil {
+		return nil, err
+	}
+
+	resp, raw, sendErr := s.outbound.SendFriendLinkRequest(ctx, baseURL, message, rssURL)
+	if sendErr != nil {
+		item.Status = OutboundFriendLinkFailed
+		item.ResponseBody = toOptionalString(sendErr.Error())
+		if err := s.repo.Update(ctx, item); err != nil {
+			log.Printf("[federation] 更新出站友链申请失败 id=%d err=%v", item.ID, err)
+		}
+		return item, sendErr
+	}
+
+	statusCode := resp.StatusCode
+	item.ResponseStatus = &statusCode
+	item.ResponseBody = toOptionalString(truncateBytes(raw, maxStoredResponseBytes))
+	if statusCode < 200 || statusCode >= 300 {
+		item.Status = OutboundFriendLinkFailed
+		return item, s.repo.Update(ctx, item)
+	}
+
+	item.Status = OutboundFriendLinkSent
+	var envelope struct {
+		Data contract.FederationFriendLinkResponseResp `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &envelope); err == nil {
+		if envelope.Data.ApplicationID > 0 {
+			applicationID := envelope.Data.ApplicationID
+			item.RemoteApplicationID = &applicationID
+		}
+		if envelope.Data.Status == OutboundFriendLinkApproved {
+			if err := s.approve(ctx, item, ""); err != nil {
+				log.Printf("[federation] 出站友链自动通过处理失败 target=%s err=%v", baseURL, err)
+			}
+		}
+	}
+	return item, s.repo.Update(ctx, item)
+}
+
+// FriendLinkDecision 对方实例签名回传的审核结果。
+type FriendLinkDecision struct {
+	InstanceURL string
+	Status      string
+	Reason      string
+	RSSURL      string
+}
+
+// HandleDecision 处理友链审核回执；只接受本站确实发出且尚未有结果的申请。
+func (s *FriendLinkRequestService) HandleDecision(ctx context.Context, decision FriendLinkDecision) (*domainfed.OutboundFriendLinkRequest, error) {
+	baseURL := strings.TrimRight(strings.TrimSpace(decision.InstanceURL), "/")
+	item, err := s.repo.FindOpenByTargetURL(ctx, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch decision.Status {
+	case OutboundFriendLinkApproved:
+		if err := s.approve(ctx, item, decision.RSSURL); err != nil {
+			return nil, err
+		}
+	case OutboundFriendLinkRejected:
+		now := time.Now().UTC()
+		item.Status = OutboundFriendLinkRejected
+		item.RejectReason = toOptionalString(decision.Reason)
+		item.DecidedAt = &now
+	default:
+		return nil, errors.New("invalid friend link decision status")
+	}
+	if err := s.repo.Update(ctx, item); err != nil {
+		return nil, err
+	}
+	log.Printf("[federation] 出站友链申请结果 target=%s id=%d status=%s", item.TargetURL, item.ID, item.Status)
+	return item, nil
+}
+
+func (s *FriendLinkRequestService) List(ctx context.Context, options domainfed.OutboundFriendLinkListOptions) ([]domainfed.OutboundFriendLinkRequest, int64, error) {
+	return s.repo.List(ctx, options)
+}
+
+// approve 建立指向对方实例的联合友链，并将实例标记为 active；已存在同地址友链时直接关联。
+func (s *FriendLinkRequestService) approve(ctx context.Context, item *domainfed.OutboundFriendLinkRequest, rssURL string) error {
+	instance, err := s.instances.EnsureInstance(ctx, item.TargetURL)
+	if err != nil {
+		return err
+	}
+	link, err := s.linkRepo.FindByURL(ctx, instance.BaseURL)
+	if err != nil {
+		if !errors.Is(err, social.ErrFriendLinkNotFound) {
+			return err
+		}
+		name := instance.BaseURL
+		if instance.Name != nil && strings.TrimSpace(*instance.Name) != "" {
+			name = *instance.Name
+		}
+		link = &social.FriendLink{
+			Name:        name,
+			URL:         instance.BaseURL,
+			Description: instance.Description,
+			RSSURL:      toOptionalString(rssURL),
+			Kind:        "federation",
+			SyncMode:    "federation",
+			InstanceID:  &instance.ID,
+			IsActive:    true,
+		}
+		if err := s.linkRepo.Create(ctx, link); err != nil {
+			return err
+		}
+	}
+	if instance.Status == InstanceStatusPending {
+		instance.Status = InstanceStatusActive
+		if err := s.instances.instanceRepo.Update(ctx, instance); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now().UTC()
+	item.Status = OutboundFriendLinkApproved
+	item.InstanceID = &instance.ID
+	item.FriendLinkID = &link.ID
+	item.RejectReason = nil
+	item.DecidedAt = &now
+	return nil
+}
+
+func truncateBytes(raw []byte, limit int) string {
+	if len(raw) <= limit {
+		return string(raw)
+	}
+	return strings.ToValidUTF8(string(raw[:limit]), "")
+}
@@ -0,0 +1,300 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+const (
+	InstanceStatusPending = "pending"
+	InstanceStatusActive  = "active"
+	InstanceStatusBlocked = "blocked"
+
+	DomainRuleBlock = "block"
+	DomainRuleAllow = "allow"
+)
+
+// InstanceService 管理已知的联合实例以及域名黑白名单。
+type InstanceService struct {
+	instanceRepo domainfed.FederationInstanceRepository
+	ruleRepo     domainfed.FederationDomainRuleRepository
+	resolver     *fedinfra.Resolver
+}
+
+func NewInstanceService(instanceRepo domainfed.FederationInstanceRepository, ruleRepo domainfed.FederationDomainRuleRepository, resolver *fedinfra.Resolver) *InstanceService {
+	return &InstanceService{
+		instanceRepo: instanceRepo,
+		ruleRepo:     ruleRepo,
+		resolver:     resolver,
+	}
+}
+
+func (s *InstanceService) ListInstances(ctx context.Context, options domainfed.InstanceListOptions) ([]domainfed.FederationInstance, int64, error) {
+	return s.instanceRepo.List(ctx, options)
+}
+
+func (s *InstanceService) GetInstance(ctx context.Context, id int64) (*domainfed.FederationInstance, error) {
+	return s.instanceRepo.GetByID(ctx, id)
+}
+
+// RefreshInstance 跳过缓存重新拉取远端 well-known 元数据并回写实例记录。
+func (s *InstanceService) RefreshInstance(ctx context.Context, id int64) (*domainfed.FederationInstance, error) {
+	instance, err := s.instanceRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.resolver == nil {
+		return nil, errors.New("resolver not configured")
+	}
+	if err := s.resolver.Invalidate(ctx, instance.BaseURL); err != nil {
+		return nil, err
+	}
+	manifest, err := s.resolver.FetchManifest(ctx, instance.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := s.resolver.FetchEndpoints(ctx, instance.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	keyDoc, err := s.resolver.FetchPublicKey(ctx, instance.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	instance.Name = toOptionalString(manifest.Instance.Name)
+	instance.Description = toOptionalString(manifest.Instance.Description)
+	instance.ProtocolVersion = toOptionalString(manifest.ProtocolVersion)
+	instance.PublicKey = toOptionalString(keyDoc.PublicKey)
+	instance.KeyID = toOptionalString(keyDoc.KeyID)
+	instance.Features = marshalOrEmpty(manifest.Features, "[]")
+	instance.Policies = marshalOrEmpty(manifest.Policies, "{}")
+	instance.Endpoints = marshalOrEmpty(endpoints, "{}")
+	instance.Manifest = marshalOrEmpty(manifest, "{}")
+	instance.LastSeenAt = &now
+	if err := s.instanceRepo.Update(ctx, instance); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// EnsureInstance 返回 baseURL 对应的实例，未知实例按 well-known 元数据登记为 pending；被屏蔽的实例直接拒绝。
+func (s *InstanceService) EnsureInstance(ctx context.Context, baseURL string) (*domainfed.FederationInstance, error) {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if err := s.CheckInstance(ctx, baseURL); err != nil {
+		return nil, err
+	}
+	instance, err := s.instanceRepo.GetByBaseURL(ctx, baseURL)
+	if err == nil {
+		return instance, nil
+	}
+	if !errors.Is(err, domainfed.ErrFederationInstanceNotFound) {
+		return nil, err
+	}
+	if s.resolver == nil {
+		return nil, errors.New("resolver not configured")
+	}
+	manifest, err := s.resolver.FetchManifest(ctx, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := s.resolver.FetchEndpoints(ctx, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	keyDoc, err := s.resolver.FetchPublicKey(ctx, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	instance = &domainfed.FederationInstance{
+		BaseURL:         baseURL,
+		Name:            toOptionalString(manifest.Instance.Name),
+		Description:     toOptionalString(manifest.Instance.Description),
+		ProtocolVersion: toOptionalString(manifest.ProtocolVersion),
+		PublicKey:       toOptionalString(keyDoc.PublicKey),
+		KeyID:           toOptionalString(keyDoc.KeyID),
+		Features:        marshalOrEmpty(manifest.Features, "[]"),
+		Policies:        marshalOrEmpty(manifest.Policies, "{}"),
+		Endpoints:       marshalOrEmpty(endpoints, "{}"),
+		Manifest:        marshalOrEmpty(manifest, "{}"),
+		Status:          InstanceStatusPending,
+		LastSeenAt:      &now,
+	}
+	if err := s.instanceRepo.Create(ctx, instance); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// BlockInstance 屏蔽单个实例，其后的签名请求会在校验阶段直接拒绝。
+func (s *InstanceService) BlockInstance(ctx context.Context, id int64, reason string) (*domainfed.FederationInstance, error) {
+	return s.setInstanceStatus(ctx, id, InstanceStatusBlocked, toOptionalString(reason))
+}
+
+// AllowInstance 解除屏蔽并将实例标记为可用。
+func (s *InstanceService) AllowInstance(ctx context.Context, id int64) (*domainfed.FederationInstance, error) {
+	return s.setInstanceStatus(ctx, id, InstanceStatusActive, nil)
+}
+
+func (s *InstanceService) setInstanceStatus(ctx context.Context, id int64, status string, reason *string) (*domainfed.FederationInstance, error) {
+	if err := s.instanceRepo.UpdateStatus(ctx, id, status, reason); err != nil {
+		return nil, err
+	}
+	return s.instanceRepo.GetByID(ctx, id)
+}
+
+func (s *InstanceService) ListDomainRules(ctx context.Context) ([]domainfed.FederationDomainRule, error) {
+	return s.ruleRepo.List(ctx)
+}
+
+// CreateDomainRule 新增域名规则，pattern 支持 example.com 或 *.example.com。
+func (s *InstanceService) CreateDomainRule(ctx context.Context, pattern string, action string, reason string) (*domainfed.FederationDomainRule, error) {
+	normalized, err := NormalizeDomainPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	action = strings.TrimSpace(action)
+	if action == "" {
+		action = DomainRuleBlock
+	}
+	if action != DomainRuleBlock && action != DomainRuleAllow {
+		return nil, domainfed.ErrInvalidDomainPattern
+	}
+
+	rules, err := s.ruleRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.Pattern == normalized {
+			return nil, domainfed.ErrDomainRuleExists
+		}
+	}
+
+	rule := &domainfed.FederationDomainRule{
+		Pattern: normalized,
+		Action:  action,
+		Reason:  toOptionalString(reason),
+	}
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *InstanceService) DeleteDomainRule(ctx context.Context, id int64) error {
+	return s.ruleRepo.Delete(ctx, id)
+}
+
+// CheckInstance 实现 fedinfra.InstancePolicy：
+// 实例被单独屏蔽时拒绝；否则 allow 规则优先于 block 规则，未命中任何规则则放行。
+func (s *InstanceService) CheckInstance(ctx context.Context, baseURL string) error {
+	host := hostOf(baseURL)
+	if host == "" {
+		return domainfed.ErrFederationInstanceBlocked
+	}
+
+	instance, err := s.instanceRepo.GetByBaseURL(ctx, strings.TrimRight(baseURL, "/"))
+	if err != nil && !errors.Is(err, domainfed.ErrFederationInstanceNotFound) {
+		return err
+	}
+	if instance != nil && instance.Status == InstanceStatusBlocked {
+		return domainfed.ErrFederationInstanceBlocked
+	}
+
+	rules, err := s.ruleRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	blocked := false
+	for _, rule := range rules {
+		if !MatchDomainPattern(rule.Pattern, host) {
+			continue
+		}
+		if rule.Action == DomainRuleAllow {
+			return nil
+		}
+		blocked = true
+	}
+	if blocked {
+		return domainfed.ErrFederationInstanceBlocked
+	}
+	return nil
+}
+
+// NormalizeDomainPattern 统一规则格式：小写、去掉协议与路径，通配符只允许出现在最左侧。
+func NormalizeDomainPattern(raw string) (string, error) {
+	pattern := strings.ToLower(strings.TrimSpace(raw))
+	if strings.Contains(pattern, "://") {
+		pattern = hostOf(pattern)
+	}
+	pattern = strings.TrimSuffix(strings.SplitN(pattern, "/", 2)[0], ".")
+	if pattern == "" {
+		return "", domainfed.ErrInvalidDomainPattern
+	}
+	suffix := stripPort(strings.TrimPrefix(pattern, "*."))
+	if strings.Contains(suffix, "*") {
+		return "", domainfed.ErrInvalidDomainPattern
+	}
+	if suffix != "localhost" && !strings.Contains(suffix, ".") {
+		return "", domainfed.ErrInvalidDomainPattern
+	}
+	return pattern, nil
+}
+
+// MatchDomainPattern 判断主机是否命中规则，*.example.com 只匹配子域名，不包含 example.com 本身。
+func MatchDomainPattern(pattern string, host string) bool {
+	host = strings.ToLower(host)
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(stripPort(host), "."+stripPort(suffix))
+	}
+	if strings.Contains(pattern, ":") {
+		return host == pattern
+	}
+	return stripPort(host) == pattern
+}
+
+func hostOf(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+	if !strings.Contains(trimmed, "://") {
+		trimmed = "https://" + trimmed
+	}
+	parsed, err := url.Parse(trimmed)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
+
+func stripPort(host string) string {
+	if idx := strings.LastIndex(host, ":"); idx != -1 && !strings.Contains(host[idx:], "]") {
+		return host[:idx]
+	}
+	return host
+}
+
+func marshalOrEmpty(value any, fallback string) json.RawMessage {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return json.RawMessage(fallback)
+	}
+	return payload
+}
+
+func toOptionalString(val string) *string {
+	trimmed := strings.TrimSpace(val)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,273 @@
+package federation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/sysconfig"
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+// DefaultKeyGracePeriod 轮换后旧密钥继续公布的时长，需大于远端公钥缓存时间。
+const DefaultKeyGracePeriod = 2 * fedinfra.DefaultPublicKeyTTL
+
+// keyCacheTTL 为密钥列表缓存时长，多实例部署时其他实例的轮换在此时间内生效。
+const keyCacheTTL = time.Minute
+
+// KeyService 管理本站签名密钥：新密钥用于签名，旧密钥在宽限期内继续公布供对端校验。
+// 配置中的密钥只在启动、配置更新与轮换时登记到密钥表，请求路径上只读缓存。
+type KeyService struct {
+	cfgSvc *federationconfig.Service
+	repo   domainfed.FederationSigningKeyRepository
+
+	// syncMu 串行化登记与轮换，避免并发写入重复密钥。
+	syncMu   sync.Mutex
+	cacheMu  sync.RWMutex
+	cached   []domainfed.FederationSigningKey
+	loadedAt time.Time
+}
+
+func NewKeyService(cfgSvc *federationconfig.Service, repo domainfed.FederationSigningKeyRepository) *KeyService {
+	return &KeyService{cfgSvc: cfgSvc, repo: repo}
+}
+
+// SyncConfigKeys 将配置中的当前密钥登记到密钥表并刷新缓存，需在启动和联合配置更新后调用。
+func (s *KeyService) SyncConfigKeys(ctx context.Context) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	if err := s.syncConfigKeys(ctx); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// ListKeys 返回当前仍在有效期内的密钥，最新的在前。
+func (s *KeyService) ListKeys(ctx context.Context) ([]domainfed.FederationSigningKey, error) {
+	keys, err := s.loadKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	valid := make([]domainfed.FederationSigningKey, 0, len(keys))
+	for _, key := range keys {
+		if key.ExpiresAt == nil || key.ExpiresAt.After(now) {
+			valid = append(valid, key)
+		}
+	}
+	return valid, nil
+}
+
+// loadKeys 返回缓存的全部密钥（含已过期），过期判断在读取时进行，缓存不会因宽限期结束而失效。
+func (s *KeyService) loadKeys(ctx context.Context) ([]domainfed.FederationSigningKey, error) {
+	s.cacheMu.RLock()
+	if s.cached != nil && time.Since(s.loadedAt) < keyCacheTTL {
+		keys := s.cached
+		s.cacheMu.RUnlock()
+		return keys, nil
+	}
+	s.cacheMu.RUnlock()
+
+	keys, err := s.repo.ListValid(ctx, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		keys = []domainfed.FederationSigningKey{}
+	}
+	s.cacheMu.Lock()
+	s.cached, s.loadedAt = keys, time.Now()
+	s.cacheMu.Unlock()
+	return keys, nil
+}
+
+func (s *KeyService) invalidate() {
+	s.cacheMu.Lock()
+	s.cached = nil
+	s.cacheMu.Unlock()
+}
+
+// SigningKey 返回指定算法下最新的有效密钥。
+func (s *KeyService) SigningKey(ctx context.Context, algorithm string) (*domainfed.FederationSigningKey, error) {
+	keys, err := s.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	algorithm = fedinfra.NormalizeAlgorithm(algorithm)
+	for i := range keys {
+		if keys[i].Algorithm == algorithm {
+			return &keys[i], nil
+		}
+	}
+	return nil, domainfed.ErrSigningKeyNotFound
+}
+
+// PublishedKeys 生成 public-key.json 中的 keys 列表，首选算法的密钥排在前面，同算法按新旧排序。
+func (s *KeyService) PublishedKeys(ctx context.Context, documentURL string, preferred string) ([]fedinfra.PublicKeyEntry, error) {
+	keys, err := s.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	preferred = fedinfra.NormalizeAlgorithm(preferred)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].Algorithm == preferred && keys[j].Algorithm != preferred
+	})
+	entries := make([]fedinfra.PublicKeyEntry, len(keys))
+	for i, key := range keys {
+		entries[i] = fedinfra.PublicKeyEntry{
+			KeyID:     fedinfra.KeyIDFor(documentURL, key.KeyRef),
+			Algorithm: key.Algorithm,
+			PublicKey: key.PublicKey,
+			CreatedAt: key.CreatedAt,
+			ExpiresAt: key.ExpiresAt,
+		}
+	}
+	return entries, nil
+}
+
+// Rotate 生成新密钥并立即用于签名，同算法的旧密钥在 grace 之后过期。
+func (s *KeyService) Rotate(ctx context.Context, algorithm string, grace time.Duration) (*domainfed.FederationSigningKey, error) {
+	algorithm = fedinfra.NormalizeAlgorithm(algorithm)
+	if algorithm != fedinfra.AlgorithmRSASHA256 && algorithm != fedinfra.AlgorithmEd25519 {
+		return nil, fedinfra.ErrUnsupportedSignatureAlgorithm
+	}
+	if grace <= 0 {
+		grace = DefaultKeyGracePeriod
+	}
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	defer s.invalidate()
+	if err := s.syncConfigKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	pub, priv, err := fedinfra.GenerateKeyPair(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	ref, err := newKeyRef(algorithm, now)
+	if err != nil {
+		return nil, err
+	}
+	key := &domainfed.FederationSigningKey{
+		KeyRef:     ref,
+		Algorithm:  algorithm,
+		PublicKey:  pub,
+		PrivateKey: priv,
+	}
+	// 旧密钥设置过期时间与新密钥入库在同一事务中完成，避免中途失败后没有可用的签名密钥。
+	if err := s.repo.Rotate(ctx, key, now.Add(grace)); err != nil {
+		return nil, err
+	}
+	if err := s.mirrorConfig(ctx, key); err != nil {
+		return nil, err
+	}
+	log.Printf("[federation] 密钥已轮换 algorithm=%s key_ref=%s grace=%s", algorithm, key.KeyRef, grace)
+	return key, nil
+}
+
+// syncConfigKeys 将配置中的当前密钥（首次生成或手动填写）登记到密钥表，已登记的跳过。
+func (s *KeyService) syncConfigKeys(ctx context.Context) error {
+	if s.cfgSvc == nil {
+		return nil
+	}
+	settings, err := s.cfgSvc.Settings(ctx)
+	if err != nil {
+		return err
+	}
+	pairs := []struct {
+		algorithm  string
+		publicKey  string
+		privateKey string
+	}{
+		{fedinfra.AlgorithmRSASHA256, settings.PublicKey, settings.PrivateKey},
+		{fedinfra.AlgorithmEd25519, settings.Ed25519Public, settings.Ed25519Private},
+	}
+	for _, pair := range pairs {
+		if strings.TrimSpace(pair.publicKey) == "" || strings.TrimSpace(pair.privateKey) == "" {
+			continue
+		}
+		if _, err := s.repo.FindByPublicKey(ctx, pair.publicKey); err == nil {
+			continue
+		} else if !errors.Is(err, domainfed.ErrSigningKeyNotFound) {
+			return err
+		}
+		ref, err := s.nextKeyRef(ctx, pair.algorithm)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Create(ctx, &domainfed.FederationSigningKey{
+			KeyRef:     ref,
+			Algorithm:  pair.algorithm,
+			PublicKey:  pair.publicKey,
+			PrivateKey: pair.privateKey,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextKeyRef 首个密钥沿用旧版 keyId（RSA 无片段，Ed25519 为 #ed25519），之后按时间生成。
+func (s *KeyService) nextKeyRef(ctx context.Context, algorithm string) (string, error) {
+	// 零值时间会列出包括已过期在内的全部密钥，避免复用已占用的 key_ref。
+	keys, err := s.repo.ListValid(ctx, time.Time{})
+	if err != nil {
+		return "", err
+	}
+	for _, key := range keys {
+		if key.Algorithm == algorithm {
+			return newKeyRef(algorithm, time.Now())
+		}
+	}
+	if algorithm == fedinfra.AlgorithmEd25519 {
+		return fedinfra.AlgorithmEd25519, nil
+	}
+	return "", nil
+}
+
+// newKeyRef 按时间生成 key_ref，并附带随机后缀，同一秒内多次轮换也不会重复。
+func newKeyRef(algorithm string, now time.Time) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s-%s", algorithm, now.UTC().Format("20060102150405"), hex.EncodeToString(suffix)), nil
+}
+
+// mirrorConfig 把最新密钥写回配置，保持后台展示与首次生成逻辑一致。
+func (s *KeyService) mirrorConfig(ctx context.Context, key *domainfed.FederationSigningKey) error {
+	if s.cfgSvc == nil {
+		return nil
+	}
+	publicKey, privateKey := "federation.publicKey", "federation.privateKey"
+	if key.Algorithm == fedinfra.AlgorithmEd25519 {
+		publicKey, privateKey = "federation.ed25519PublicKey", "federation.ed25519PrivateKey"
+	}
+	pubRaw, err := json.Marshal(key.PublicKey)
+	if err != nil {
+		return err
+	}
+	privRaw, err := json.Marshal(key.PrivateKey)
+	if err != nil {
+		return err
+	}
+	pubMsg, privMsg := json.RawMessage(pubRaw), json.RawMessage(privRaw)
+	_, err = s.cfgSvc.UpdateConfigs(ctx, []sysconfig.UpdateItem{
+		{Key: publicKey, Value: &pubMsg},
+		{Key: privateKey, Value: &privMsg},
+	})
+	return err
+}
@@ -3,10 +3,7 @@ package federation
 import (
 	"context"
 	"crypto"
-	"crypto/rsa"
-	"crypto/x509"
 	"encoding/json"
-	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
@@ -25,26 +22,36 @@ import (
 
 type OutboundService struct {
 	cfgSvc       *federationconfig.Service
+	keySvc       *KeyService
 	resolver     *fedinfra.Resolver
 	instanceRepo domainfed.FederationInstanceRepository
 	client       *http.Client
 }
 
-func NewOutboundService(cfgSvc *federationconfig.Service, resolver *fedinfra.Resolver, instanceRepo domainfed.FederationInstanceRepository) *OutboundService {
+func NewOutboundService(cfgSvc *federationconfig.Service, keySvc *KeyService, resolver *fedinfra.Resolver, instanceRepo domainfed.FederationInstanceRepository) *OutboundService {
 	return &OutboundService{
 		cfgSvc:       cfgSvc,
+		keySvc:       keySvc,
 		resolver:     resolver,
 		instanceRepo: instanceRepo,
-		client:       &http.Client{Timeout: 10 * time.Second},
+		client:       fedinfra.NewGuardedHTTPClient(10 * time.Second),
 	}
 }
 
+// WithHTTPClient 替换出站请求使用的 HTTP 客户端，默认客户端会拒绝连接内网地址。
+func (s *OutboundService) WithHTTPClient(client *http.Client) *OutboundService {
+	if client != nil {
+		s.client = client
+	}
+	return s
+}
+
 func (s *OutboundService) SendFriendLinkRequest(ctx context.Context, target string, message string, rssURL string) (*http.Response, []byte, error) {
 	endpoint, err := s.resolveEndpoint(ctx, target, "friendlink_request", "/api/federation/friendlinks/request")
 	if err != nil {
 		return nil, nil, err
 	}
-	settings, keyID, privKey, client, err := s.signedClient(ctx)
+	settings, keyID, privKey, client, err := s.signedClient(ctx, endpoint)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -69,12 +76,44 @@ func (s *OutboundService) SendFriendLinkRequest(ctx context.Context, target stri
 	return resp, raw, nil
 }
 
+// SendFriendLinkDecision 将友链审核结果签名回传给申请方实例。
+func (s *OutboundService) SendFriendLinkDecision(ctx context.Context, target string, status string, reason string, rssURL string) (*http.Response, []byte, error) {
+	endpoint, err := s.resolveEndpoint(ctx, target, "friendlink_callback", "/api/federation/friendlinks/callback")
+	if err != nil {
+		return nil, nil, err
+	}
+	settings, keyID, privKey, client, err := s.signedClient(ctx, endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payload := contract.FederationFriendLinkDecisionReq{
+		InstanceURL: settings.InstanceURL,
+		Status:      status,
+		Reason:      reason,
+		RSSURL:      rssURL,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := client.DoSigned(ctx, http.MethodPost, endpoint, body, keyID, privKey)
+	if err != nil {
+		log.Printf("[federation] 出站 友链回执 target=%s endpoint=%s err=%v", target, endpoint, err)
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	log.Printf("[federation] 出站 友链回执 target=%s endpoint=%s status=%d", target, endpoint, resp.StatusCode)
+	return resp, raw, nil
+}
+
 func (s *OutboundService) SendCitation(ctx context.Context, ev CitationDetected) (*http.Response, []byte, error) {
 	endpoint, err := s.resolveEndpoint(ctx, ev.TargetInstance, "citation_request", "/api/federation/citations/request")
 	if err != nil {
 		return nil, nil, err
 	}
-	settings, keyID, privKey, client, err := s.signedClient(ctx)
+	settings, keyID, privKey, client, err := s.signedClient(ctx, endpoint)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -111,7 +150,7 @@ func (s *OutboundService) SendMention(ctx context.Context, ev MentionDetected) (
 	if err != nil {
 		return nil, nil, err
 	}
-	settings, keyID, privKey, client, err := s.signedClient(ctx)
+	settings, keyID, privKey, client, err := s.signedClient(ctx, endpoint)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -142,15 +181,65 @@ func (s *OutboundService) SendMention(ctx context.Context, ev MentionDetected) (
 	return resp, raw, nil
 }
 
+// CommentOutgoing 以本站身份发往友站文章的评论。
+type CommentOutgoing struct {
+	TargetInstance string
+	TargetPostID   string
+	CommentID      string
+	AuthorName     string
+	AuthorAvatar   string
+	Content        string
+}
+
+func (s *OutboundService) SendComment(ctx context.Context, out CommentOutgoing) (*http.Response, []byte, error) {
+	endpoint, err := s.resolveEndpoint(ctx, out.TargetInstance, "remote_comment", "/api/federation/comments")
+	if err != nil {
+		return nil, nil, err
+	}
+	settings, keyID, privKey, client, err := s.signedClient(ctx, endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payload := contract.FederationCommentReq{
+		SourceInstanceURL: settings.InstanceURL,
+		CommentID:         out.CommentID,
+		TargetPostID:      out.TargetPostID,
+		Author: contract.FederationCommentAuthor{
+			Name:   out.AuthorName,
+			URL:    settings.InstanceURL,
+			Avatar: out.AuthorAvatar,
+		},
+		Content: out.Content,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := client.DoSigned(ctx, http.MethodPost, endpoint, body, keyID, privKey)
+	if err != nil {
+		log.Printf("[federation] 出站 跨站评论 target=%s endpoint=%s err=%v", out.TargetInstance, endpoint, err)
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	log.Printf("[federation] 出站 跨站评论 target=%s endpoint=%s status=%d", out.TargetInstance, endpoint, resp.StatusCode)
+	return resp, raw, nil
+}
+
 func (s *OutboundService) resolveEndpoint(ctx context.Context, target string, key string, fallbackPath string) (string, error) {
-	baseURL := s.resolveTargetBaseURL(ctx, target)
+	return discoverEndpoint(ctx, s.resolver, resolveBaseURL(ctx, s.instanceRepo, target), key, fallbackPath)
+}
+
+// discoverEndpoint 按 endpoints.json 解析目标实例的端点地址，未声明时使用 fallbackPath。
+func discoverEndpoint(ctx context.Context, resolver *fedinfra.Resolver, baseURL string, key string, fallbackPath string) (string, error) {
 	if baseURL == "" {
 		return "", errors.New("target instance is empty")
 	}
-	if s.resolver == nil {
+	if resolver == nil {
 		return "", errors.New("resolver not configured")
 	}
-	endpoints, err := s.resolver.FetchEndpoints(ctx, baseURL)
+	endpoints, err := resolver.FetchEndpoints(ctx, baseURL)
 	if err != nil {
 		return "", err
 	}
@@ -185,8 +274,10 @@ type signingSettings struct {
 	Enabled       bool
 }
 
-func (s *OutboundService) signedClient(ctx context.Context) (signingSettings, string, crypto.PrivateKey, *fedinfra.Client, error) {
-	settings, keyID, privKey, err := s.signingContext(ctx)
+// signedClient 按对端 public-key.json 协商签名算法：
+// 本站首选 ed25519 且对端声明支持时使用 Ed25519，否则回退到 RSA。
+func (s *OutboundService) signedClient(ctx context.Context, endpoint string) (signingSettings, string, crypto.PrivateKey, *fedinfra.Client, error) {
+	settings, keyID, privKey, err := s.signingContext(ctx, endpoint)
 	if err != nil {
 		return signingSettings{}, "", nil, nil, err
 	}
@@ -198,7 +289,7 @@ func (s *OutboundService) signedClient(ctx context.Context) (signingSettings, st
 	return settings, keyID, privKey, client, nil
 }
 
-func (s *OutboundService) signingContext(ctx context.Context) (signingSettings, string, crypto.PrivateKey, error) {
+func (s *OutboundService) signingContext(ctx context.Context, endpoint string) (signingSettings, string, crypto.PrivateKey, error) {
 	if s.cfgSvc == nil {
 		return signingSettings{}, "", nil, errors.New("config service not configured")
 	}
@@ -212,41 +303,72 @@ func (s *OutboundService) signingContext(ctx context.Context) (signingSettings,
 	if strings.TrimSpace(settings.InstanceURL) == "" {
 		return signingSettings{}, "", nil, errors.New("instanceURL not configured")
 	}
-	if strings.TrimSpace(settings.PrivateKey) == "" {
-		return signingSettings{}, "", nil, errors.New("private key not configured")
+
+	algorithm := fedinfra.AlgorithmRSASHA256
+	if settings.SignatureAlg == fedinfra.AlgorithmEd25519 && s.peerSupports(ctx, endpoint, fedinfra.AlgorithmEd25519) {
+		algorithm = fedinfra.AlgorithmEd25519
+	}
+	key, err := s.signingKey(ctx, settings, algorithm)
+	if err != nil && algorithm == fedinfra.AlgorithmEd25519 {
+		algorithm = fedinfra.AlgorithmRSASHA256
+		key, err = s.signingKey(ctx, settings, algorithm)
 	}
-	privKey, err := parsePrivateKey(settings.PrivateKey)
 	if err != nil {
 		return signingSettings{}, "", nil, err
 	}
-	keyID := strings.TrimRight(settings.InstanceURL, "/") + "/.well-known/blog-federation/public-key.json"
+	privKey, err := fedinfra.ParsePrivateKey(key.PrivateKey)
+	if err != nil {
+		return signingSettings{}, "", nil, err
+	}
+	documentURL := strings.TrimRight(settings.InstanceURL, "/") + "/.well-known/blog-federation/public-key.json"
 	return signingSettings{
 		InstanceURL:   strings.TrimRight(settings.InstanceURL, "/"),
-		SignatureAlg:  settings.SignatureAlg,
-		PrivateKey:    settings.PrivateKey,
+		SignatureAlg:  algorithm,
+		PrivateKey:    key.PrivateKey,
 		AllowOutbound: settings.AllowOutbound,
 		Enabled:       settings.Enabled,
-	}, keyID, privKey, nil
+	}, fedinfra.KeyIDFor(documentURL, key.KeyRef), privKey, nil
 }
 
-func parsePrivateKey(pemData string) (crypto.PrivateKey, error) {
-	block, _ := pem.Decode([]byte(pemData))
-	if block == nil {
-		return nil, errors.New("invalid private key")
+// signingKey 取该算法下最新的密钥；未配置密钥服务时退回配置中的单一密钥。
+func (s *OutboundService) signingKey(ctx context.Context, settings federationconfig.Settings, algorithm string) (*domainfed.FederationSigningKey, error) {
+	if s.keySvc != nil {
+		return s.keySvc.SigningKey(ctx, algorithm)
 	}
-	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
-		return key, nil
+	key := &domainfed.FederationSigningKey{Algorithm: algorithm, PrivateKey: settings.PrivateKey}
+	if algorithm == fedinfra.AlgorithmEd25519 {
+		key.KeyRef = fedinfra.AlgorithmEd25519
+		key.PrivateKey = settings.Ed25519Private
 	}
-	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
-		if rsaKey, ok := key.(*rsa.PrivateKey); ok {
-			return rsaKey, nil
-		}
-		return nil, fmt.Errorf("unsupported private key type")
+	if strings.TrimSpace(key.PrivateKey) == "" {
+		return nil, errors.New("private key not configured")
 	}
-	return nil, errors.New("unsupported private key format")
+	return key, nil
+}
+
+// peerSupports 读取对端公钥文档判断其能否校验指定算法，获取失败时视为不支持。
+func (s *OutboundService) peerSupports(ctx context.Context, endpoint string, algorithm string) bool {
+	if s.resolver == nil {
+		return false
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return false
+	}
+	doc, err := s.resolver.FetchPublicKey(ctx, parsed.Scheme+"://"+parsed.Host)
+	if err != nil || doc == nil {
+		log.Printf("[federation] 出站 获取对端公钥失败，回退 rsa-sha256 endpoint=%s err=%v", endpoint, err)
+		return false
+	}
+	return doc.Supports(algorithm)
 }
 
 func (s *OutboundService) resolveTargetBaseURL(ctx context.Context, raw string) string {
+	return resolveBaseURL(ctx, s.instanceRepo, raw)
+}
+
+// resolveBaseURL 将 URL 或 host[:port] 形式的实例标识统一为 base URL，优先匹配已知实例。
+func resolveBaseURL(ctx context.Context, instanceRepo domainfed.FederationInstanceRepository, raw string) string {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
 		return ""
@@ -255,8 +377,8 @@ func (s *OutboundService) resolveTargetBaseURL(ctx context.Context, raw string)
 		return strings.TrimRight(trimmed, "/")
 	}
 	host, port := parseHostPort(trimmed)
-	if host != "" && s.instanceRepo != nil {
-		if instances, err := s.instanceRepo.ListActive(ctx); err == nil {
+	if host != "" && instanceRepo != nil {
+		if instances, err := instanceRepo.ListActive(ctx); err == nil {
 			for _, instance := range instances {
 				base := strings.TrimRight(instance.BaseURL, "/")
 				parsed, err := url.Parse(base)
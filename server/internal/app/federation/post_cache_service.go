@@ -0,0 +1,227 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+const (
+	// postCacheTTL 缓存超过该时长后由后台以条件请求刷新。
+	postCacheTTL          = 6 * time.Hour
+	postCacheRefreshBatch = 50
+	postFetchTimeout      = 10 * time.Second
+	maxPostDetailBytes    = 1 << 20
+)
+
+// PostCacheService 解析文章中的跨站引用，抓取被引用文章并缓存标题、摘要、封面与作者，供前端渲染预览卡片。
+type PostCacheService struct {
+	repo      domainfed.FederatedPostCacheRepository
+	instances *InstanceService
+	client    *http.Client
+	mu        sync.Mutex
+	done      chan struct{}
+}
+
+// NewPostCacheService 创建服务；client 为空时使用带 SSRF 防护的客户端，refreshInterval 大于 0 时启动后台刷新。
+func NewPostCacheService(repo domainfed.FederatedPostCacheRepository, instances *InstanceService, client *http.Client, refreshInterval time.Duration) *PostCacheService {
+	if client == nil {
+		client = fedinfra.NewGuardedHTTPClient(postFetchTimeout)
+	}
+	svc := &PostCacheService{
+		repo:      repo,
+		instances: instances,
+		client:    client,
+		done:      make(chan struct{}),
+	}
+	if refreshInterval > 0 {
+		go svc.loop(refreshInterval)
+	}
+	return svc
+}
+
+func (s *PostCacheService) Close() {
+	close(s.done)
+}
+
+func (s *PostCacheService) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.RefreshStale(context.Background())
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Preview 只读缓存，不触发远端请求；未缓存时返回 ErrFederatedPostNotFound。
+func (s *PostCacheService) Preview(ctx context.Context, instance string, postID string) (*domainfed.FederatedPostCache, error) {
+	baseURL := resolveBaseURL(ctx, s.instances.instanceRepo, instance)
+	if baseURL == "" || strings.TrimSpace(postID) == "" {
+		return nil, domainfed.ErrFederatedPostNotFound
+	}
+	known, err := s.instances.instanceRepo.GetByBaseURL(ctx, baseURL)
+	if err != nil {
+		if errors.Is(err, domainfed.ErrFederationInstanceNotFound) {
+			return nil, domainfed.ErrFederatedPostNotFound
+		}
+		return nil, err
+	}
+	return s.repo.GetByRemoteID(ctx, known.ID, strings.TrimSpace(postID))
+}
+
+// Resolve 返回被引用文章的缓存，缺失或 force 时向远端 posts/:id 拉取。
+func (s *PostCacheService) Resolve(ctx context.Context, instance string, postID string, force bool) (*domainfed.FederatedPostCache, error) {
+	postID = strings.TrimSpace(postID)
+	baseURL := resolveBaseURL(ctx, s.instances.instanceRepo, instance)
+	if baseURL == "" || postID == "" {
+		return nil, errors.New("citation target is empty")
+	}
+	known, err := s.instances.EnsureInstance(ctx, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := s.repo.GetByRemoteID(ctx, known.ID, postID)
+	if err != nil && !errors.Is(err, domainfed.ErrFederatedPostNotFound) {
+		return nil, err
+	}
+	if cached != nil && !force && time.Since(cached.CachedAt) < postCacheTTL {
+		return cached, nil
+	}
+	return s.fetch(ctx, known, postID, cached)
+}
+
+// RefreshStale 刷新过期缓存，上一轮未结束时直接跳过。
+func (s *PostCacheService) RefreshStale(ctx context.Context) {
+	if !s.mu.TryLock() {
+		return
+	}
+	defer s.mu.Unlock()
+
+	stale, err := s.repo.ListStale(ctx, time.Now().Add(-postCacheTTL), postCacheRefreshBatch)
+	if err != nil {
+		log.Printf("[federation] 获取过期文章缓存失败: %v", err)
+		return
+	}
+	for i := range stale {
+		post := stale[i]
+		if post.RemotePostID == nil {
+			continue
+		}
+		known, err := s.instances.GetInstance(ctx, post.InstanceID)
+		if err != nil {
+			log.Printf("[federation] 文章缓存刷新失败 url=%s err=%v", post.URL, err)
+			continue
+		}
+		if known.Status == InstanceStatusBlocked {
+			continue
+		}
+		if _, err := s.fetch(ctx, known, *post.RemotePostID, &post); err != nil {
+			log.Printf("[federation] 文章缓存刷新失败 url=%s err=%v", post.URL, err)
+		}
+	}
+}
+
+// fetch 以条件请求拉取远端文章详情；304 时仅更新缓存时间。
+func (s *PostCacheService) fetch(ctx context.Context, instance *domainfed.FederationInstance, postID string, cached *domainfed.FederatedPostCache) (*domainfed.FederatedPostCache, error) {
+	endpoint, err := discoverEndpoint(ctx, s.instances.resolver, instance.BaseURL, "post_detail", "/api/federation/posts/{id}")
+	if err != nil {
+		return nil, err
+	}
+	endpoint = strings.Replace(endpoint, "{id}", url.PathEscape(postID), 1)
+
+	ctx, cancel := context.WithTimeout(ctx, postFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if cached != nil {
+		if cached.ETag != nil {
+			req.Header.Set("If-None-Match", *cached.ETag)
+		}
+		if cached.LastModified != nil {
+			req.Header.Set("If-Modified-Since", *cached.LastModified)
+		}
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	now := time.Now().UTC()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		// 按 url 冲突更新，主键留空以免插入时与已有记录冲突。
+		touched := *cached
+		touched.ID = 0
+		touched.CachedAt = now
+		if err := s.repo.UpsertBatch(ctx, []domainfed.FederatedPostCache{touched}); err != nil {
+			return nil, err
+		}
+		cached.CachedAt = now
+		return cached, nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return nil, domainfed.ErrFederatedPostNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("post detail request failed: %s", resp.Status)
+	}
+
+	var envelope struct {
+		Data contract.FederationPostDetailResp `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxPostDetailBytes)).Decode(&envelope); err != nil {
+		return nil, err
+	}
+	remote := envelope.Data.Post
+	if strings.TrimSpace(remote.URL) == "" || strings.TrimSpace(remote.Title) == "" {
+		return nil, errors.New("post detail is incomplete")
+	}
+	if hostOf(remote.URL) != hostOf(instance.BaseURL) {
+		return nil, fmt.Errorf("post url %s does not belong to %s", remote.URL, instance.BaseURL)
+	}
+
+	post := domainfed.FederatedPostCache{
+		InstanceID:     instance.ID,
+		RemotePostID:   &postID,
+		URL:            remote.URL,
+		Title:          remote.Title,
+		Summary:        remote.Summary,
+		ContentPreview: remote.ContentPreview,
+		Author:         marshalOrEmpty(remote.Author, "{}"),
+		Tags:           json.RawMessage("[]"),
+		Categories:     json.RawMessage("[]"),
+		PublishedAt:    remote.PublishedAt,
+		UpdatedAt:      remote.UpdatedAt,
+		CoverImage:     remote.CoverImage,
+		Language:       remote.Language,
+		AllowCitation:  remote.AllowCitation,
+		AllowComment:   remote.AllowComment,
+		ETag:           toOptionalString(resp.Header.Get("ETag")),
+		LastModified:   toOptionalString(resp.Header.Get("Last-Modified")),
+		CachedAt:       now,
+	}
+	if err := s.repo.UpsertBatch(ctx, []domainfed.FederatedPostCache{post}); err != nil {
+		return nil, err
+	}
+	log.Printf("[federation] 缓存远端文章 instance=%s post=%s url=%s", instance.BaseURL, postID, post.URL)
+	return &post, nil
+}
@@ -0,0 +1,204 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+const (
+	EndpointFriendLinkRequest  = "friendlink_request"
+	EndpointFriendLinkCallback = "friendlink_callback"
+	EndpointCitationRequest    = "citation_request"
+	EndpointMentionNotify      = "mention_notify"
+	EndpointRemoteComment      = "remote_comment"
+	EndpointTimelineSync       = "timeline_sync"
+	EndpointPostDetail         = "post_detail"
+	EndpointActivityPubInbox   = "activitypub_inbox"
+	EndpointWebmention         = "webmention"
+	EndpointWebSubHub          = "websub_hub"
+)
+
+// inboundEndpointPaths 将入站路由映射到 rateLimits.endpoints 中的键。
+var inboundEndpointPaths = map[string]string{
+	"/api/federation/friendlinks/request":  EndpointFriendLinkRequest,
+	"/api/federation/friendlinks/callback": EndpointFriendLinkCallback,
+	"/api/federation/citations/request":    EndpointCitationRequest,
+	"/api/federation/mentions/notify":      EndpointMentionNotify,
+	"/api/federation/comments":             EndpointRemoteComment,
+	"/ap/inbox":                            EndpointActivityPubInbox,
+}
+
+// inboundEndpoint 个人收件箱路径带用户名，与共享收件箱共用同一配额。
+func inboundEndpoint(path string) string {
+	path = strings.TrimRight(path, "/")
+	if endpoint, ok := inboundEndpointPaths[path]; ok {
+		return endpoint
+	}
+	if strings.HasPrefix(path, "/ap/users/") && strings.HasSuffix(path, "/inbox") {
+		return EndpointActivityPubInbox
+	}
+	return ""
+}
+
+// RateRule 令牌桶规则：每 WindowSeconds 秒补充 Limit 个令牌，Burst 为桶容量（默认等于 Limit）。
+type RateRule struct {
+	Limit         int `json:"limit"`
+	WindowSeconds int `json:"window_seconds"`
+	Burst         int `json:"burst,omitempty"`
+}
+
+func (r RateRule) enabled() bool {
+	return r.Limit > 0 && r.WindowSeconds > 0
+}
+
+func (r RateRule) capacity() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	return float64(r.Limit)
+}
+
+func (r RateRule) refillPerSecond() float64 {
+	return float64(r.Limit) / float64(r.WindowSeconds)
+}
+
+// RateLimitConfig 对应 federation.rateLimits，Instance 为单实例总配额，Endpoints 为各端点配额。
+type RateLimitConfig struct {
+	Instance  RateRule            `json:"instance"`
+	Endpoints map[string]RateRule `json:"endpoints"`
+}
+
+// DefaultRateLimitConfig 未配置时使用的默认配额。
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Instance: RateRule{Limit: 600, WindowSeconds: 3600, Burst: 60},
+		Endpoints: map[string]RateRule{
+			EndpointFriendLinkRequest:  {Limit: 5, WindowSeconds: 3600},
+			EndpointFriendLinkCallback: {Limit: 20, WindowSeconds: 3600},
+			EndpointCitationRequest:    {Limit: 30, WindowSeconds: 3600, Burst: 10},
+			EndpointMentionNotify:      {Limit: 60, WindowSeconds: 3600, Burst: 20},
+			EndpointRemoteComment:      {Limit: 30, WindowSeconds: 3600, Burst: 10},
+			EndpointTimelineSync:       {Limit: 120, WindowSeconds: 3600, Burst: 20},
+			EndpointPostDetail:         {Limit: 600, WindowSeconds: 3600, Burst: 60},
+			EndpointActivityPubInbox:   {Limit: 600, WindowSeconds: 3600, Burst: 60},
+			EndpointWebmention:         {Limit: 30, WindowSeconds: 3600, Burst: 10},
+			EndpointWebSubHub:          {Limit: 60, WindowSeconds: 3600, Burst: 20},
+		},
+	}
+}
+
+// ParseRateLimitConfig 解析配置并与默认值合并；limit 设为 0 表示关闭对应限制。
+func ParseRateLimitConfig(raw json.RawMessage) RateLimitConfig {
+	cfg := DefaultRateLimitConfig()
+	if len(raw) == 0 {
+		return cfg
+	}
+	var parsed struct {
+		Instance  *RateRule           `json:"instance"`
+		Endpoints map[string]RateRule `json:"endpoints"`
+	}
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		log.Printf("[federation] 限流配置解析失败，使用默认值: %v", err)
+		return cfg
+	}
+	if parsed.Instance != nil {
+		cfg.Instance = *parsed.Instance
+	}
+	for key, rule := range parsed.Endpoints {
+		cfg.Endpoints[key] = rule
+	}
+	return cfg
+}
+
+// PerHour 换算为每小时可用次数，用于在 manifest 中对外公布。
+func (c RateLimitConfig) PerHour(endpoint string) int64 {
+	rule, ok := c.Endpoints[endpoint]
+	if !ok || !rule.enabled() {
+		return 0
+	}
+	return int64(rule.refillPerSecond() * 3600)
+}
+
+// RateLimitService 按实例与端点执行入站限流，并把超限记录到实例上。
+type RateLimitService struct {
+	cfgSvc       *federationconfig.Service
+	store        fedinfra.RateLimitStore
+	instanceRepo domainfed.FederationInstanceRepository
+}
+
+func NewRateLimitService(cfgSvc *federationconfig.Service, store fedinfra.RateLimitStore, instanceRepo domainfed.FederationInstanceRepository) *RateLimitService {
+	return &RateLimitService{
+		cfgSvc:       cfgSvc,
+		store:        store,
+		instanceRepo: instanceRepo,
+	}
+}
+
+// AllowRequest 实现 fedinfra.RequestLimiter，对已通过签名校验的请求按实例计费。
+func (s *RateLimitService) AllowRequest(ctx context.Context, baseURL string, req *http.Request) error {
+	baseURL = strings.TrimRight(baseURL, "/")
+	endpoint := inboundEndpoint(req.URL.Path)
+	err := s.take(ctx, "inst:"+baseURL, endpoint)
+	var limitErr *fedinfra.RateLimitError
+	if errors.As(err, &limitErr) && s.instanceRepo != nil {
+		if recErr := s.instanceRepo.RecordRateLimitViolation(ctx, baseURL, time.Now()); recErr != nil {
+			log.Printf("[federation] 记录限流失败 base=%s err=%v", baseURL, recErr)
+		}
+		log.Printf("[federation] 入站 限流 base=%s endpoint=%s scope=%s retry_after=%s", baseURL, endpoint, limitErr.Scope, limitErr.RetryAfter)
+	}
+	return err
+}
+
+// AllowIP 用于无签名的公开端点，按来源 IP 计费。
+func (s *RateLimitService) AllowIP(ctx context.Context, ip string, endpoint string) error {
+	err := s.take(ctx, "ip:"+ip, endpoint)
+	var limitErr *fedinfra.RateLimitError
+	if errors.As(err, &limitErr) {
+		log.Printf("[federation] 入站 限流 ip=%s endpoint=%s scope=%s retry_after=%s", ip, endpoint, limitErr.Scope, limitErr.RetryAfter)
+	}
+	return err
+}
+
+// take 先扣端点桶再扣总桶，任一耗尽即返回 *fedinfra.RateLimitError；存储异常时放行。
+func (s *RateLimitService) take(ctx context.Context, identity string, endpoint string) error {
+	if s.store == nil {
+		return nil
+	}
+	cfg := DefaultRateLimitConfig()
+	if s.cfgSvc != nil {
+		if settings, err := s.cfgSvc.Settings(ctx); err == nil {
+			cfg = ParseRateLimitConfig(settings.RateLimits)
+		}
+	}
+
+	if rule, ok := cfg.Endpoints[endpoint]; ok && rule.enabled() {
+		if err := s.takeRule(ctx, identity+":"+endpoint, endpoint, rule); err != nil {
+			return err
+		}
+	}
+	if cfg.Instance.enabled() {
+		return s.takeRule(ctx, identity, "instance", cfg.Instance)
+	}
+	return nil
+}
+
+func (s *RateLimitService) takeRule(ctx context.Context, key string, scope string, rule RateRule) error {
+	allowed, wait, err := s.store.Take(ctx, key, rule.capacity(), rule.refillPerSecond())
+	if err != nil {
+		log.Printf("[federation] 限流存储异常，本次放行 key=%s err=%v", key, err)
+		return nil
+	}
+	if allowed {
+		return nil
+	}
+	return &fedinfra.RateLimitError{Scope: scope, RetryAfter: wait}
+}
@@ -2,6 +2,7 @@ package federation
 
 import (
 	"context"
+	"log"
 
 	appEvent "github.com/grtsinry43/grtblog-v2/server/internal/app/event"
 )
@@ -34,3 +35,22 @@ func RegisterSubscribers(bus appEvent.Bus, svc *OutboundService) {
 		return err
 	}))
 }
+
+// RegisterCitationCacheSubscriber 在文章保存后异步解析引用目标并写入文章缓存，拉取远端不阻塞保存请求，失败也不影响保存。
+func RegisterCitationCacheSubscriber(bus appEvent.Bus, svc *PostCacheService) {
+	if bus == nil || svc == nil {
+		return
+	}
+	bus.Subscribe(CitationDetected{}.Name(), handlerFunc(func(ctx context.Context, event appEvent.Event) error {
+		payload, ok := event.(CitationDetected)
+		if !ok {
+			return nil
+		}
+		go func() {
+			if _, err := svc.Resolve(context.Background(), payload.TargetInstance, payload.TargetPostID, false); err != nil {
+				log.Printf("[federation] 引用预览解析失败 target=%s post=%s err=%v", payload.TargetInstance, payload.TargetPostID, err)
+			}
+		}()
+		return nil
+	}))
+}
@@ -2,11 +2,7 @@ package federationconfig
 
 import (
 	"context"
-	"crypto/rand"
-	"crypto/rsa"
-	"crypto/x509"
 	"encoding/json"
-	"encoding/pem"
 	"errors"
 	"strconv"
 	"strings"
@@ -14,6 +10,7 @@ import (
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/sysconfig"
 	"github.com/grtsinry43/grtblog-v2/server/internal/config"
 	domainconfig "github.com/grtsinry43/grtblog-v2/server/internal/domain/config"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
 )
 
 type Service struct {
@@ -68,12 +65,19 @@ type Settings struct {
 	InstanceURL     string
 	PublicKey       string
 	PrivateKey      string
+	Ed25519Public   string
+	Ed25519Private  string
 	SignatureAlg    string
 	RequireHTTPS    bool
 	AllowInbound    bool
 	AllowOutbound   bool
 	DefaultPolicies json.RawMessage
 	RateLimits      json.RawMessage
+	ActivityPub     bool
+	APReplies       bool
+	Webmention      bool
+	WMComments      bool
+	WebSub          bool
 }
 
 func (s *Service) Settings(ctx context.Context) (Settings, error) {
@@ -83,12 +87,19 @@ func (s *Service) Settings(ctx context.Context) (Settings, error) {
 		"federation.instanceURL",
 		"federation.publicKey",
 		"federation.privateKey",
+		"federation.ed25519PublicKey",
+		"federation.ed25519PrivateKey",
 		"federation.signatureAlg",
 		"federation.requireHTTPS",
 		"federation.allowInbound",
 		"federation.allowOutbound",
 		"federation.defaultPolicies",
 		"federation.rateLimits",
+		"federation.activityPub",
+		"federation.activityPubReplies",
+		"federation.webmention",
+		"federation.webmentionComments",
+		"federation.websub",
 	}
 	items, err := s.repo.List(ctx, keys)
 	if err != nil {
@@ -105,12 +116,19 @@ func (s *Service) Settings(ctx context.Context) (Settings, error) {
 		InstanceURL:     parseString(lookup["federation.instanceURL"], ""),
 		PublicKey:       parseString(lookup["federation.publicKey"], ""),
 		PrivateKey:      parseString(lookup["federation.privateKey"], ""),
-		SignatureAlg:    parseString(lookup["federation.signatureAlg"], "rsa-sha256"),
+		Ed25519Public:   parseString(lookup["federation.ed25519PublicKey"], ""),
+		Ed25519Private:  parseString(lookup["federation.ed25519PrivateKey"], ""),
+		SignatureAlg:    fedinfra.NormalizeAlgorithm(parseString(lookup["federation.signatureAlg"], fedinfra.AlgorithmRSASHA256)),
 		RequireHTTPS:    parseBool(lookup["federation.requireHTTPS"], true),
 		AllowInbound:    parseBool(lookup["federation.allowInbound"], true),
 		AllowOutbound:   parseBool(lookup["federation.allowOutbound"], true),
 		DefaultPolicies: parseJSON(lookup["federation.defaultPolicies"], json.RawMessage("{}")),
 		RateLimits:      parseJSON(lookup["federation.rateLimits"], json.RawMessage("{}")),
+		ActivityPub:     parseBool(lookup["federation.activityPub"], false),
+		APReplies:       parseBool(lookup["federation.activityPubReplies"], false),
+		Webmention:      parseBool(lookup["federation.webmention"], false),
+		WMComments:      parseBool(lookup["federation.webmentionComments"], false),
+		WebSub:          parseBool(lookup["federation.websub"], false),
 	}, nil
 }
 
@@ -152,16 +170,36 @@ func valueOrDefault(cfg domainconfig.SysConfig) string {
 	return ""
 }
 
+// ensureKeyPairUpdates 始终保证 RSA 密钥存在（用于与仅支持 rsa-sha256 的实例通信），
+// 首选算法为 ed25519 时额外生成 Ed25519 密钥。
 func (s *Service) ensureKeyPairUpdates(settings Settings) ([]sysconfig.UpdateItem, error) {
-	if strings.TrimSpace(settings.PublicKey) != "" && strings.TrimSpace(settings.PrivateKey) != "" {
-		return nil, nil
-	}
-
-	if settings.SignatureAlg != "" && settings.SignatureAlg != "rsa-sha256" {
-		return nil, errors.New("仅支持 rsa-sha256")
-	}
+	switch settings.SignatureAlg {
+	case "", fedinfra.AlgorithmRSASHA256, fedinfra.AlgorithmEd25519:
+	default:
+		return nil, errors.New("仅支持 rsa-sha256 或 ed25519")
+	}
+
+	var updates []sysconfig.UpdateItem
+	if strings.TrimSpace(settings.PublicKey) == "" || strings.TrimSpace(settings.PrivateKey) == "" {
+		items, err := keyPairUpdates(fedinfra.AlgorithmRSASHA256, "federation.publicKey", "federation.privateKey")
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, items...)
+	}
+	if settings.SignatureAlg == fedinfra.AlgorithmEd25519 &&
+		(strings.TrimSpace(settings.Ed25519Public) == "" || strings.TrimSpace(settings.Ed25519Private) == "") {
+		items, err := keyPairUpdates(fedinfra.AlgorithmEd25519, "federation.ed25519PublicKey", "federation.ed25519PrivateKey")
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, items...)
+	}
+	return updates, nil
+}
 
-	pub, priv, err := generateRSAKeyPair()
+func keyPairUpdates(algorithm string, publicKey string, privateKey string) ([]sysconfig.UpdateItem, error) {
+	pub, priv, err := fedinfra.GenerateKeyPair(algorithm)
 	if err != nil {
 		return nil, err
 	}
@@ -177,33 +215,16 @@ func (s *Service) ensureKeyPairUpdates(settings Settings) ([]sysconfig.UpdateIte
 
 	return []sysconfig.UpdateItem{
 		{
-			Key:   "federation.publicKey",
+			Key:   publicKey,
 			Value: toRaw(pubRaw),
 		},
 		{
-			Key:   "federation.privateKey",
+			Key:   privateKey,
 			Value: toRaw(privRaw),
 		},
 	}, nil
 }
 
-func generateRSAKeyPair() (string, string, error) {
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
-	if err != nil {
-		return "", "", err
-	}
-	privateBytes := x509.MarshalPKCS1PrivateKey(privateKey)
-	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: privateBytes})
-
-	publicBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
-	if err != nil {
-		return "", "", err
-	}
-	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes})
-
-	return string(publicPEM), string(privatePEM), nil
-}
-
 func toRaw(raw []byte) *json.RawMessage {
 	msg := json.RawMessage(raw)
 	return &msg
@@ -0,0 +1,368 @@
+package friendlink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+)
+
+const (
+	ApplicationStatusPending  = "pending"
+	ApplicationStatusApproved = "approved"
+	ApplicationStatusRejected = "rejected"
+
+	LinkKindManual     = "manual"
+	LinkKindFederation = "federation"
+
+	SyncModeNone       = "none"
+	SyncModeRSS        = "rss"
+	SyncModeFederation = "federation"
+)
+
+// DecisionSender 将审核结果签名回传给联合实例，由 federation.OutboundService 实现。
+type DecisionSender interface {
+	SendFriendLinkDecision(ctx context.Context, target string, status string, reason string, rssURL string) (*http.Response, []byte, error)
+}
+
+// AdminService 负责友链申请审核与友链管理。
+type AdminService struct {
+	appRepo      social.FriendLinkApplicationRepository
+	linkRepo     social.FriendLinkRepository
+	instanceRepo federation.FederationInstanceRepository
+	sender       DecisionSender
+}
+
+func NewAdminService(
+	appRepo social.FriendLinkApplicationRepository,
+	linkRepo social.FriendLinkRepository,
+	instanceRepo federation.FederationInstanceRepository,
+	sender DecisionSender,
+) *AdminService {
+	return &AdminService{
+		appRepo:      appRepo,
+		linkRepo:     linkRepo,
+		instanceRepo: instanceRepo,
+		sender:       sender,
+	}
+}
+
+type ApproveCmd struct {
+	ID           int64
+	Name         *string
+	Logo         *string
+	Description  *string
+	RSSURL       *string
+	SyncMode     *string
+	SyncInterval *int
+	// NotifyRemote 为 true 且申请来自联合实例时，签名回传通过结果。
+	NotifyRemote bool
+}
+
+type RejectCmd struct {
+	ID           int64
+	Reason       string
+	NotifyRemote bool
+}
+
+type ReviewResult struct {
+	Application social.FriendLinkApplication
+	Link        *social.FriendLink
+	Notified    bool
+	NotifyError string
+}
+
+func (s *AdminService) ListApplications(ctx context.Context, options social.FriendLinkApplicationListOptions) ([]*social.FriendLinkApplication, int64, error) {
+	return s.appRepo.List(ctx, options)
+}
+
+// Approve 通过友链申请：创建或更新对应友链，并按需通知联合实例。
+func (s *AdminService) Approve(ctx context.Context, cmd ApproveCmd) (*ReviewResult, error) {
+	app, err := s.appRepo.FindByID(ctx, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+	if app.Status != ApplicationStatusPending {
+		return nil, social.ErrFriendLinkApplicationReviewed
+	}
+
+	link, err := s.upsertLinkFromApplication(ctx, app, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	app.Status = ApplicationStatusApproved
+	app.ReviewReason = nil
+	app.ReviewedAt = &now
+	if err := s.appRepo.Update(ctx, app); err != nil {
+		return nil, err
+	}
+
+	result := &ReviewResult{Application: *app, Link: link}
+	if cmd.NotifyRemote {
+		s.notify(ctx, app, "", result)
+	}
+	return result, nil
+}
+
+// Reject 拒绝友链申请并记录原因。
+func (s *AdminService) Reject(ctx context.Context, cmd RejectCmd) (*ReviewResult, error) {
+	app, err := s.appRepo.FindByID(ctx, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+	if app.Status != ApplicationStatusPending {
+		return nil, social.ErrFriendLinkApplicationReviewed
+	}
+
+	now := time.Now()
+	reason := strings.TrimSpace(cmd.Reason)
+	app.Status = ApplicationStatusRejected
+	app.ReviewReason = toOptionalString(reason)
+	app.ReviewedAt = &now
+	if err := s.appRepo.Update(ctx, app); err != nil {
+		return nil, err
+	}
+
+	result := &ReviewResult{Application: *app}
+	if cmd.NotifyRemote {
+		s.notify(ctx, app, reason, result)
+	}
+	return result, nil
+}
+
+func (s *AdminService) notify(ctx context.Context, app *social.FriendLinkApplication, reason string, result *ReviewResult) {
+	if app.ApplyChannel != "federation" || app.InstanceURL == nil {
+		return
+	}
+	if s.sender == nil {
+		result.NotifyError = "联合服务未初始化"
+		return
+	}
+	resp, _, err := s.sender.SendFriendLinkDecision(ctx, *app.InstanceURL, app.Status, reason, "")
+	if err != nil {
+		log.Printf("[friendlink] 审核回执发送失败 app_id=%d target=%s err=%v", app.ID, *app.InstanceURL, err)
+		result.NotifyError = err.Error()
+		return
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.NotifyError = fmt.Sprintf("对方返回状态码 %d", resp.StatusCode)
+		return
+	}
+	result.Notified = true
+}
+
+func (s *AdminService) upsertLinkFromApplication(ctx context.Context, app *social.FriendLinkApplication, cmd ApproveCmd) (*social.FriendLink, error) {
+	link, err := s.linkRepo.FindByURL(ctx, app.URL)
+	if err != nil && !errors.Is(err, social.ErrFriendLinkNotFound) {
+		return nil, err
+	}
+	created := link == nil
+	if created {
+		link = &social.FriendLink{URL: app.URL}
+	}
+
+	link.Name = firstNonEmpty(optionalValue(cmd.Name), optionalValue(app.Name), link.Name, app.URL)
+	link.Logo = pickOptional(cmd.Logo, app.Logo, link.Logo)
+	link.Description = pickOptional(cmd.Description, app.Description, link.Description)
+	link.RSSURL = pickOptional(cmd.RSSURL, app.RSSURL, link.RSSURL)
+	link.UserID = app.UserID
+	link.IsActive = true
+	if cmd.SyncInterval != nil {
+		link.SyncInterval = cmd.SyncInterval
+	}
+
+	if app.ApplyChannel == "federation" {
+		link.Kind = LinkKindFederation
+		link.SyncMode = SyncModeFederation
+		if instance := s.activateInstance(ctx, app.InstanceURL); instance != nil {
+			link.InstanceID = &instance.ID
+		}
+	} else {
+		link.Kind = LinkKindManual
+		link.SyncMode = requestedSyncMode(optionalValue(link.RSSURL))
+	}
+	if cmd.SyncMode != nil {
+		mode, err := normalizeSyncMode(*cmd.SyncMode)
+		if err != nil {
+			return nil, err
+		}
+		link.SyncMode = mode
+	}
+
+	if created {
+		if err := s.linkRepo.Create(ctx, link); err != nil {
+			return nil, err
+		}
+		return link, nil
+	}
+	if err := s.linkRepo.Update(ctx, link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (s *AdminService) activateInstance(ctx context.Context, instanceURL *string) *federation.FederationInstance {
+	if s.instanceRepo == nil || instanceURL == nil || strings.TrimSpace(*instanceURL) == "" {
+		return nil
+	}
+	instance, err := s.instanceRepo.GetByBaseURL(ctx, strings.TrimRight(strings.TrimSpace(*instanceURL), "/"))
+	if err != nil {
+		return nil
+	}
+	if instance.Status == "blocked" {
+		return nil
+	}
+	if instance.Status != "active" {
+		instance.Status = "active"
+		if err := s.instanceRepo.Update(ctx, instance); err != nil {
+			log.Printf("[friendlink] 更新联合实例状态失败 base=%s err=%v", instance.BaseURL, err)
+		}
+	}
+	return instance
+}
+
+type LinkCmd struct {
+	Name         string
+	URL          string
+	Logo         *string
+	Description  *string
+	RSSURL       *string
+	Kind         string
+	SyncMode     string
+	SyncInterval *int
+	IsActive     bool
+}
+
+func (s *AdminService) ListLinks(ctx context.Context, options social.FriendLinkListOptions) ([]*social.FriendLink, int64, error) {
+	return s.linkRepo.List(ctx, options)
+}
+
+func (s *AdminService) GetLink(ctx context.Context, id int64) (*social.FriendLink, error) {
+	return s.linkRepo.FindByID(ctx, id)
+}
+
+// ListPublicLinks 返回前台展示用的启用友链。
+func (s *AdminService) ListPublicLinks(ctx context.Context) ([]*social.FriendLink, error) {
+	return s.linkRepo.ListActive(ctx)
+}
+
+func (s *AdminService) CreateLink(ctx context.Context, cmd LinkCmd) (*social.FriendLink, error) {
+	url := strings.TrimSpace(cmd.URL)
+	if _, err := s.linkRepo.FindByURL(ctx, url); err == nil {
+		return nil, social.ErrFriendLinkURLExists
+	} else if !errors.Is(err, social.ErrFriendLinkNotFound) {
+		return nil, err
+	}
+
+	link := &social.FriendLink{URL: url}
+	if err := applyLinkCmd(link, cmd); err != nil {
+		return nil, err
+	}
+	if err := s.linkRepo.Create(ctx, link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (s *AdminService) UpdateLink(ctx context.Context, id int64, cmd LinkCmd) (*social.FriendLink, error) {
+	link, err := s.linkRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	url := strings.TrimSpace(cmd.URL)
+	if url != link.URL {
+		if existing, err := s.linkRepo.FindByURL(ctx, url); err == nil && existing.ID != link.ID {
+			return nil, social.ErrFriendLinkURLExists
+		} else if err != nil && !errors.Is(err, social.ErrFriendLinkNotFound) {
+			return nil, err
+		}
+	}
+
+	previousRSS := optionalValue(link.RSSURL)
+	link.URL = url
+	if err := applyLinkCmd(link, cmd); err != nil {
+		return nil, err
+	}
+	if optionalValue(link.RSSURL) != previousRSS {
+		// 订阅地址变化后条件请求头失效，下次同步重新全量抓取。
+		link.FeedETag = nil
+		link.FeedLastModified = nil
+		link.LastSyncAt = nil
+	}
+	if err := s.linkRepo.Update(ctx, link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (s *AdminService) DeleteLink(ctx context.Context, id int64) error {
+	return s.linkRepo.Delete(ctx, id)
+}
+
+func applyLinkCmd(link *social.FriendLink, cmd LinkCmd) error {
+	kind := strings.TrimSpace(cmd.Kind)
+	if kind == "" {
+		kind = firstNonEmpty(link.Kind, LinkKindManual)
+	}
+	if kind != LinkKindManual && kind != LinkKindFederation {
+		return social.ErrFriendLinkInvalidKind
+	}
+	mode := strings.TrimSpace(cmd.SyncMode)
+	if mode == "" {
+		mode = requestedSyncMode(optionalValue(cmd.RSSURL))
+	}
+	mode, err := normalizeSyncMode(mode)
+	if err != nil {
+		return err
+	}
+
+	link.Name = strings.TrimSpace(cmd.Name)
+	link.Logo = trimOptional(cmd.Logo)
+	link.Description = trimOptional(cmd.Description)
+	link.RSSURL = trimOptional(cmd.RSSURL)
+	link.Kind = kind
+	link.SyncMode = mode
+	link.SyncInterval = cmd.SyncInterval
+	link.IsActive = cmd.IsActive
+	return nil
+}
+
+func normalizeSyncMode(mode string) (string, error) {
+	switch strings.TrimSpace(mode) {
+	case SyncModeNone, SyncModeRSS, SyncModeFederation:
+		return strings.TrimSpace(mode), nil
+	default:
+		return "", social.ErrFriendLinkInvalidSyncMode
+	}
+}
+
+func pickOptional(values ...*string) *string {
+	for _, val := range values {
+		if trimmed := trimOptional(val); trimmed != nil {
+			return trimmed
+		}
+	}
+	return nil
+}
+
+func trimOptional(val *string) *string {
+	if val == nil {
+		return nil
+	}
+	return toOptionalString(*val)
+}
+
+func optionalValue(val *string) string {
+	if val == nil {
+		return ""
+	}
+	return strings.TrimSpace(*val)
+}
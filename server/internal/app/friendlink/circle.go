@@ -0,0 +1,50 @@
+package friendlink
+
+import (
+	"context"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+)
+
+// CircleService 朋友圈：聚合所有启用友链的最新文章。
+type CircleService struct {
+	linkRepo social.FriendLinkRepository
+	postRepo social.FriendLinkPostRepository
+}
+
+func NewCircleService(linkRepo social.FriendLinkRepository, postRepo social.FriendLinkPostRepository) *CircleService {
+	return &CircleService{linkRepo: linkRepo, postRepo: postRepo}
+}
+
+type CircleEntry struct {
+	Post social.FriendLinkPost
+	Link social.FriendLink
+}
+
+func (s *CircleService) List(ctx context.Context, page int, pageSize int) ([]CircleEntry, int64, error) {
+	posts, total, err := s.postRepo.ListRecentActive(ctx, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(posts) == 0 {
+		return []CircleEntry{}, total, nil
+	}
+	links, err := s.linkRepo.ListActive(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	linkByID := make(map[int64]*social.FriendLink, len(links))
+	for _, link := range links {
+		linkByID[link.ID] = link
+	}
+
+	entries := make([]CircleEntry, 0, len(posts))
+	for _, post := range posts {
+		link, ok := linkByID[post.FriendLinkID]
+		if !ok {
+			continue
+		}
+		entries = append(entries, CircleEntry{Post: *post, Link: *link})
+	}
+	return entries, total, nil
+}
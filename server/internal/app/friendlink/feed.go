@@ -0,0 +1,340 @@
+package friendlink
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxSummaryRunes = 200
+
+var ErrUnsupportedFeed = errors.New("无法识别的订阅源格式")
+
+// FeedItem 统一后的订阅条目，RSS 2.0 / Atom / JSON Feed 都会归一到这里。
+type FeedItem struct {
+	GUID        string
+	Title       string
+	URL         string
+	Summary     string
+	Author      string
+	PublishedAt time.Time
+}
+
+// ParseFeed 根据内容自动识别订阅格式并解析，baseURL 用于补全相对链接。
+func ParseFeed(body []byte, baseURL string) ([]FeedItem, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return nil, ErrUnsupportedFeed
+	}
+
+	var items []FeedItem
+	var err error
+	if trimmed[0] == '{' {
+		items, err = parseJSONFeed(trimmed)
+	} else {
+		items, err = parseXMLFeed(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]FeedItem, 0, len(items))
+	for _, item := range items {
+		item.URL = resolveFeedURL(baseURL, strings.TrimSpace(item.URL))
+		item.Title = strings.TrimSpace(html.UnescapeString(item.Title))
+		item.Summary = summarize(item.Summary)
+		item.Author = strings.TrimSpace(item.Author)
+		item.GUID = strings.TrimSpace(item.GUID)
+		if item.GUID == "" {
+			item.GUID = item.URL
+		}
+		if item.URL == "" || item.GUID == "" {
+			continue
+		}
+		if item.Title == "" {
+			item.Title = item.URL
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+type rssDocument struct {
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 (RDF) 的 item 与 channel 平级。
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Author      string `xml:"author"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type atomDocument struct {
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Author    struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type jsonFeedDocument struct {
+	Version string         `json:"version"`
+	Items   []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            json.RawMessage `json:"id"`
+	URL           string          `json:"url"`
+	Title         string          `json:"title"`
+	Summary       string          `json:"summary"`
+	ContentText   string          `json:"content_text"`
+	ContentHTML   string          `json:"content_html"`
+	DatePublished string          `json:"date_published"`
+	DateModified  string          `json:"date_modified"`
+	Author        *struct {
+		Name string `json:"name"`
+	} `json:"author"`
+	Authors []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+}
+
+func parseXMLFeed(body []byte) ([]FeedItem, error) {
+	root, err := xmlRootName(body)
+	if err != nil {
+		return nil, err
+	}
+	switch root {
+	case "rss", "RDF":
+		var doc rssDocument
+		if err := newXMLDecoder(body).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("parse rss: %w", err)
+		}
+		entries := doc.Channel.Items
+		if len(entries) == 0 {
+			entries = doc.Items
+		}
+		items := make([]FeedItem, 0, len(entries))
+		for _, entry := range entries {
+			items = append(items, FeedItem{
+				GUID:        entry.GUID,
+				Title:       entry.Title,
+				URL:         entry.Link,
+				Summary:     firstNonEmpty(entry.Description, entry.Encoded),
+				Author:      firstNonEmpty(entry.Creator, entry.Author),
+				PublishedAt: parseFeedTime(firstNonEmpty(entry.PubDate, entry.Date)),
+			})
+		}
+		return items, nil
+	case "feed":
+		var doc atomDocument
+		if err := newXMLDecoder(body).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("parse atom: %w", err)
+		}
+		items := make([]FeedItem, 0, len(doc.Entries))
+		for _, entry := range doc.Entries {
+			items = append(items, FeedItem{
+				GUID:        entry.ID,
+				Title:       entry.Title,
+				URL:         atomEntryLink(entry.Links),
+				Summary:     firstNonEmpty(entry.Summary, entry.Content),
+				Author:      entry.Author.Name,
+				PublishedAt: parseFeedTime(firstNonEmpty(entry.Published, entry.Updated)),
+			})
+		}
+		return items, nil
+	default:
+		return nil, ErrUnsupportedFeed
+	}
+}
+
+func parseJSONFeed(body []byte) ([]FeedItem, error) {
+	var doc jsonFeedDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("parse json feed: %w", err)
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, ErrUnsupportedFeed
+	}
+	items := make([]FeedItem, 0, len(doc.Items))
+	for _, entry := range doc.Items {
+		author := ""
+		if entry.Author != nil {
+			author = entry.Author.Name
+		}
+		if author == "" && len(entry.Authors) > 0 {
+			author = entry.Authors[0].Name
+		}
+		items = append(items, FeedItem{
+			GUID:        jsonFeedID(entry.ID),
+			Title:       entry.Title,
+			URL:         entry.URL,
+			Summary:     firstNonEmpty(entry.Summary, entry.ContentText, entry.ContentHTML),
+			Author:      author,
+			PublishedAt: parseFeedTime(firstNonEmpty(entry.DatePublished, entry.DateModified)),
+		})
+	}
+	return items, nil
+}
+
+func xmlRootName(body []byte) (string, error) {
+	decoder := newXMLDecoder(body)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return "", ErrUnsupportedFeed
+			}
+			return "", fmt.Errorf("parse xml: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func newXMLDecoder(body []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(strings.TrimSpace(charset)) {
+		case "", "utf-8", "utf8", "us-ascii", "ascii":
+			return input, nil
+		default:
+			return nil, fmt.Errorf("unsupported charset: %s", charset)
+		}
+	}
+	return decoder
+}
+
+func atomEntryLink(links []atomLink) string {
+	fallback := ""
+	for _, link := range links {
+		if link.Href == "" {
+			continue
+		}
+		if link.Rel == "" || link.Rel == "alternate" {
+			if link.Type == "" || strings.Contains(link.Type, "html") {
+				return link.Href
+			}
+		}
+		if fallback == "" && link.Rel != "self" && link.Rel != "enclosure" {
+			fallback = link.Href
+		}
+	}
+	return fallback
+}
+
+func jsonFeedID(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str
+	}
+	return strings.Trim(string(raw), `"`)
+}
+
+var feedTimeLayouts = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 02 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseFeedTime 尽量兼容各种常见时间格式，解析失败时返回零值。
+func parseFeedTime(val string) time.Time {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return time.Time{}
+	}
+	for _, layout := range feedTimeLayouts {
+		if parsed, err := time.Parse(layout, val); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+var htmlTagPattern = regexp.MustCompile(`(?s)<[^>]*>`)
+
+// summarize 去掉 HTML 标签并截断摘要。
+func summarize(raw string) string {
+	text := htmlTagPattern.ReplaceAllString(raw, " ")
+	text = html.UnescapeString(text)
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= maxSummaryRunes {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:maxSummaryRunes]) + "…"
+}
+
+func resolveFeedURL(baseURL string, raw string) string {
+	if raw == "" {
+		return ""
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if ref.IsAbs() {
+		return ref.String()
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return raw
+	}
+	return base.ResolveReference(ref).String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, val := range values {
+		if strings.TrimSpace(val) != "" {
+			return val
+		}
+	}
+	return ""
+}
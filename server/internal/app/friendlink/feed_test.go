@@ -0,0 +1,164 @@
+package friendlink
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFeedFixtures(t *testing.T) {
+	cases := []struct {
+		file    string
+		baseURL string
+		want    []FeedItem
+	}{
+		{
+			file:    "rss2.xml",
+			baseURL: "https://blog.example.com/feed/",
+			want: []FeedItem{
+				{
+					GUID:        "https://blog.example.com/?p=1024",
+					Title:       "用 Go 写一个 RSS 聚合器 – 上篇",
+					URL:         "https://blog.example.com/2024/10/go-rss-aggregator/",
+					Summary:     "最近想把友链的文章聚合到一个页面里，于是动手写了一个小工具。 本文介绍整体结构。",
+					Author:      "小林",
+					PublishedAt: time.Date(2024, 10, 12, 8, 30, 12, 0, time.UTC),
+				},
+				{
+					GUID:        "https://blog.example.com/?p=1001",
+					Title:       "秋天的第一杯咖啡",
+					URL:         "https://blog.example.com/2024/09/coffee/",
+					Summary:     "天气转凉， 又到了 喝热咖啡的季节。",
+					PublishedAt: time.Date(2024, 9, 23, 6, 5, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			file:    "rdf.xml",
+			baseURL: "https://diary.example.org/index.rdf",
+			want: []FeedItem{
+				{
+					GUID:        "https://diary.example.org/entry/2024-08-01",
+					Title:       "Summer notes",
+					URL:         "https://diary.example.org/entry/2024-08-01",
+					Summary:     "Notes from a hot & humid August.",
+					Author:      "Taro",
+					PublishedAt: time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+				},
+				{
+					GUID:        "https://diary.example.org/entry/2024-07-15",
+					Title:       "Rainy season is over",
+					URL:         "https://diary.example.org/entry/2024-07-15",
+					Summary:     "Finally some sunshine.",
+					Author:      "Taro",
+					PublishedAt: time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			file:    "atom.xml",
+			baseURL: "https://alice.example.net/atom.xml",
+			want: []FeedItem{
+				{
+					GUID:        "https://alice.example.net/posts/hello-atom/",
+					Title:       "Hello Atom",
+					URL:         "https://alice.example.net/posts/hello-atom/",
+					Summary:     "First post migrated to Hexo today.",
+					Author:      "Alice",
+					PublishedAt: time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC),
+				},
+				{
+					GUID:        "tag:alice.example.net,2024:draft",
+					Title:       "Untitled draft",
+					URL:         "https://alice.example.net/posts/draft/",
+					Summary:     "Only content, no summary.",
+					PublishedAt: time.Date(2024, 9, 20, 2, 30, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			file:    "jsonfeed.json",
+			baseURL: "https://bob.example.dev/feed.json",
+			want: []FeedItem{
+				{
+					GUID:        "https://bob.example.dev/2024/10/05/shipping/",
+					Title:       "Shipping it",
+					URL:         "https://bob.example.dev/2024/10/05/shipping/",
+					Summary:     "Finally shipped the new site.",
+					Author:      "Bob",
+					PublishedAt: time.Date(2024, 10, 6, 1, 20, 0, 0, time.UTC),
+				},
+				{
+					GUID:        "42",
+					Title:       "https://bob.example.dev/2024/09/30/quick-note/",
+					URL:         "https://bob.example.dev/2024/09/30/quick-note/",
+					Summary:     "A quick note without a title.",
+					PublishedAt: time.Date(2024, 9, 30, 7, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tc.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseFeed(body, tc.baseURL)
+			if err != nil {
+				t.Fatalf("ParseFeed: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d items, want %d: %+v", len(got), len(tc.want), got)
+			}
+			for i, want := range tc.want {
+				item := got[i]
+				if item.GUID != want.GUID || item.Title != want.Title || item.URL != want.URL ||
+					item.Summary != want.Summary || item.Author != want.Author {
+					t.Errorf("item %d = %+v, want %+v", i, item, want)
+				}
+				if !item.PublishedAt.Equal(want.PublishedAt) {
+					t.Errorf("item %d published at %s, want %s", i, item.PublishedAt, want.PublishedAt)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFeedRejectsUnknownFormats(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+	}{
+		{"empty", "  \n"},
+		{"html page", "<!DOCTYPE html><html><head><title>Blog</title></head><body></body></html>"},
+		{"plain json", `{"items":[{"id":"1","url":"https://example.com/1"}]}`},
+		{"opml", `<?xml version="1.0"?><opml version="2.0"><body/></opml>`},
+	}
+	for _, tc := range cases {
+		if _, err := ParseFeed([]byte(tc.body), "https://example.com/"); !errors.Is(err, ErrUnsupportedFeed) {
+			t.Errorf("%s: err = %v, want ErrUnsupportedFeed", tc.name, err)
+		}
+	}
+}
+
+func TestParseFeedTruncatesSummary(t *testing.T) {
+	body := `<rss version="2.0"><channel><item><link>https://example.com/long</link><description>` +
+		strings.Repeat("字", maxSummaryRunes+50) + `</description></item></channel></rss>`
+	items, err := ParseFeed([]byte("\xef\xbb\xbf"+body), "")
+	if err != nil {
+		t.Fatalf("ParseFeed: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+	if got := []rune(items[0].Summary); len(got) != maxSummaryRunes+1 || got[len(got)-1] != '…' {
+		t.Errorf("summary not truncated: %d runes", len(got))
+	}
+	if items[0].GUID != "https://example.com/long" || items[0].Title != "https://example.com/long" {
+		t.Errorf("guid/title should fall back to link: %+v", items[0])
+	}
+}
@@ -0,0 +1,382 @@
+package friendlink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/sysconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+const (
+	defaultHealthLoopInterval = 10 * time.Minute
+	maxHealthBodyBytes        = 2 << 20
+	maxHealthProbeTimeout     = 30 * time.Second
+	healthUserAgent           = "grtblog-friendlink-health/2.0"
+)
+
+// HealthChecker 定期探测友链可用性：状态码、延迟、证书有效期以及是否挂了本站反链。
+type HealthChecker struct {
+	linkRepo     social.FriendLinkRepository
+	healthRepo   social.FriendLinkHealthRepository
+	sysCfg       *sysconfig.Service
+	fedCfg       *federationconfig.Service
+	client       *http.Client
+	loopInterval time.Duration
+	mu           sync.Mutex
+	done         chan struct{}
+}
+
+// NewHealthChecker 创建健康检查器并启动后台循环，loopInterval 为检查到期情况的频率。
+func NewHealthChecker(
+	linkRepo social.FriendLinkRepository,
+	healthRepo social.FriendLinkHealthRepository,
+	sysCfg *sysconfig.Service,
+	fedCfg *federationconfig.Service,
+	loopInterval time.Duration,
+) *HealthChecker {
+	if loopInterval <= 0 {
+		loopInterval = defaultHealthLoopInterval
+	}
+	checker := &HealthChecker{
+		linkRepo:     linkRepo,
+		healthRepo:   healthRepo,
+		sysCfg:       sysCfg,
+		fedCfg:       fedCfg,
+		client:       fedinfra.NewGuardedHTTPClient(maxHealthProbeTimeout),
+		loopInterval: loopInterval,
+		done:         make(chan struct{}),
+	}
+	go checker.loop()
+	return checker
+}
+
+func (h *HealthChecker) Close() {
+	close(h.done)
+}
+
+func (h *HealthChecker) loop() {
+	ticker := time.NewTicker(h.loopInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.CheckDue(context.Background())
+		case <-h.done:
+			return
+		}
+	}
+}
+
+// CheckDue 检查所有到期的启用友链以及被自动停用的友链，上一轮未结束时直接跳过。
+func (h *HealthChecker) CheckDue(ctx context.Context) {
+	if !h.mu.TryLock() {
+		return
+	}
+	defer h.mu.Unlock()
+
+	settings := h.settings(ctx)
+	links, err := h.dueLinks(ctx)
+	if err != nil {
+		log.Printf("[friendlink] 获取待检查友链失败: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, link := range links {
+		previous, err := h.healthRepo.GetByLinkID(ctx, link.ID)
+		if err != nil && !errors.Is(err, social.ErrFriendLinkHealthNotFound) {
+			log.Printf("[friendlink] 读取健康记录失败 id=%d err=%v", link.ID, err)
+			continue
+		}
+		if previous != nil && now.Sub(previous.CheckedAt) < settings.Interval {
+			continue
+		}
+		if _, err := h.check(ctx, link, previous, settings); err != nil {
+			log.Printf("[friendlink] 健康检查失败 id=%d url=%s err=%v", link.ID, link.URL, err)
+		}
+	}
+}
+
+// dueLinks 返回启用中的友链，并补上被健康检查自动停用的友链，使其恢复后能重新上线。
+func (h *HealthChecker) dueLinks(ctx context.Context) ([]*social.FriendLink, error) {
+	links, err := h.linkRepo.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := h.healthRepo.ListAutoDeactivatedLinkIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return links, nil
+	}
+	deactivated, err := h.linkRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, link := range deactivated {
+		if !link.IsActive {
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+type HealthEntry struct {
+	Health social.FriendLinkHealth
+	Link   social.FriendLink
+}
+
+// Check 立即检查指定友链并返回最新结果。
+func (h *HealthChecker) Check(ctx context.Context, linkID int64) (*HealthEntry, error) {
+	link, err := h.linkRepo.FindByID(ctx, linkID)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := h.healthRepo.GetByLinkID(ctx, link.ID)
+	if err != nil && !errors.Is(err, social.ErrFriendLinkHealthNotFound) {
+		return nil, err
+	}
+	health, err := h.check(ctx, link, previous, h.settings(ctx))
+	if health == nil {
+		return nil, err
+	}
+	return &HealthEntry{Health: *health, Link: *link}, err
+}
+
+// List 返回健康检查结果（失效的排在前面），附带友链信息供后台展示。
+func (h *HealthChecker) List(ctx context.Context, options social.FriendLinkHealthListOptions) ([]HealthEntry, int64, error) {
+	items, total, err := h.healthRepo.List(ctx, options)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		ids[i] = item.FriendLinkID
+	}
+	links, err := h.linkRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	linkByID := make(map[int64]*social.FriendLink, len(links))
+	for _, link := range links {
+		linkByID[link.ID] = link
+	}
+
+	entries := make([]HealthEntry, 0, len(items))
+	for _, item := range items {
+		link, ok := linkByID[item.FriendLinkID]
+		if !ok {
+			continue
+		}
+		entries = append(entries, HealthEntry{Health: *item, Link: *link})
+	}
+	return entries, total, nil
+}
+
+func (h *HealthChecker) settings(ctx context.Context) sysconfig.FriendLinkHealthSettings {
+	settings, err := h.sysCfg.FriendLinkHealthSettings(ctx)
+	if err != nil {
+		log.Printf("[friendlink] 读取健康检查配置失败，使用默认值: %v", err)
+	}
+	if strings.TrimSpace(settings.BacklinkURL) == "" && h.fedCfg != nil {
+		if fedSettings, err := h.fedCfg.Settings(ctx); err == nil {
+			settings.BacklinkURL = fedSettings.InstanceURL
+		}
+	}
+	return settings
+}
+
+func (h *HealthChecker) check(ctx context.Context, link *social.FriendLink, previous *social.FriendLinkHealth, settings sysconfig.FriendLinkHealthSettings) (*social.FriendLinkHealth, error) {
+	now := time.Now()
+	health := &social.FriendLinkHealth{
+		FriendLinkID: link.ID,
+		CheckedAt:    now,
+	}
+	if previous != nil {
+		health.ConsecutiveFailures = previous.ConsecutiveFailures
+		health.LastSuccessAt = previous.LastSuccessAt
+		health.AutoDeactivated = previous.AutoDeactivated
+	}
+
+	probeErr := h.probeSite(ctx, link.URL, settings, health)
+	if rssURL := strings.TrimSpace(safeString(link.RSSURL)); rssURL != "" {
+		if status, err := h.probeStatus(ctx, rssURL, settings.Timeout); err == nil {
+			health.RSSStatusCode = &status
+		}
+	}
+
+	if probeErr != nil {
+		msg := probeErr.Error()
+		health.ErrorMessage = &msg
+		health.ConsecutiveFailures++
+	} else {
+		health.ConsecutiveFailures = 0
+		health.LastSuccessAt = &now
+	}
+	threshold := settings.FailureThreshold
+	if threshold <= 0 {
+		threshold = 3
+	}
+	health.IsHealthy = health.ConsecutiveFailures < threshold
+
+	switch {
+	case !health.IsHealthy && settings.AutoDeactivate && link.IsActive:
+		if err := h.linkRepo.SetActive(ctx, link.ID, false); err != nil {
+			return nil, err
+		}
+		link.IsActive = false
+		health.AutoDeactivated = true
+		log.Printf("[friendlink] 友链连续失败 %d 次，已自动停用 id=%d url=%s", health.ConsecutiveFailures, link.ID, link.URL)
+	case health.IsHealthy && health.AutoDeactivated && !link.IsActive:
+		if err := h.linkRepo.SetActive(ctx, link.ID, true); err != nil {
+			return nil, err
+		}
+		link.IsActive = true
+		health.AutoDeactivated = false
+		log.Printf("[friendlink] 友链已恢复，重新启用 id=%d url=%s", link.ID, link.URL)
+	case link.IsActive:
+		// 管理员手动重新启用后不再视为自动停用。
+		health.AutoDeactivated = false
+	}
+
+	if err := h.healthRepo.Upsert(ctx, health); err != nil {
+		return nil, err
+	}
+	return health, nil
+}
+
+// probeSite 请求友链首页，记录状态码、延迟、证书到期时间并检测反链。
+func (h *HealthChecker) probeSite(ctx context.Context, siteURL string, settings sysconfig.FriendLinkHealthSettings, health *social.FriendLinkHealth) error {
+	reqCtx, cancel := context.WithTimeout(ctx, healthTimeout(settings.Timeout))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, siteURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", healthUserAgent)
+
+	start := time.Now()
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	latency := int(time.Since(start).Milliseconds())
+	status := resp.StatusCode
+	health.StatusCode = &status
+	health.LatencyMs = &latency
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		expiresAt := resp.TLS.PeerCertificates[0].NotAfter
+		health.TLSExpiresAt = &expiresAt
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHealthBodyBytes))
+	if err == nil {
+		health.HasBacklink = containsBacklink(body, settings.BacklinkURL)
+	}
+	if status >= 400 {
+		return fmt.Errorf("unexpected status: %d", status)
+	}
+	return nil
+}
+
+func (h *HealthChecker) probeStatus(ctx context.Context, target string, timeout time.Duration) (int, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, healthTimeout(timeout))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", healthUserAgent)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxHealthBodyBytes))
+	return resp.StatusCode, nil
+}
+
+var (
+	anchorTagPattern  = regexp.MustCompile(`(?is)<a\s[^>]*>`)
+	anchorHrefPattern = regexp.MustCompile(`(?is)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// containsBacklink 解析页面中的 <a href>，只比较主机名（忽略协议和 www 前缀），
+// 避免 http/https 差异导致误判，也不会把正文里出现的域名文本或子域名当作反链。
+func containsBacklink(body []byte, siteURL string) bool {
+	host := backlinkHost(siteURL)
+	if host == "" {
+		return false
+	}
+	for _, tag := range anchorTagPattern.FindAll(body, -1) {
+		match := anchorHrefPattern.FindSubmatch(tag)
+		if match == nil {
+			continue
+		}
+		href := string(match[1])
+		if len(match[2]) > 0 {
+			href = string(match[2])
+		} else if len(match[3]) > 0 {
+			href = string(match[3])
+		}
+		if hrefHost(href) == host {
+			return true
+		}
+	}
+	return false
+}
+
+// hrefHost 只接受带主机名的绝对地址或协议相对地址，站内相对链接不可能指向本站。
+func hrefHost(href string) string {
+	href = strings.TrimSpace(html.UnescapeString(href))
+	if href == "" {
+		return ""
+	}
+	parsed, err := url.Parse(href)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	if parsed.Scheme != "" && parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+func backlinkHost(siteURL string) string {
+	trimmed := strings.TrimSpace(siteURL)
+	if trimmed == "" {
+		return ""
+	}
+	if !strings.Contains(trimmed, "://") {
+		trimmed = "https://" + trimmed
+	}
+	parsed, err := url.Parse(trimmed)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+func healthTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return 10 * time.Second
+	}
+	return timeout
+}
@@ -0,0 +1,31 @@
+package friendlink
+
+import "testing"
+
+func TestContainsBacklink(t *testing.T) {
+	const site = "https://www.grtsinry43.com"
+	cases := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{"absolute link", `<a href="https://grtsinry43.com/">grtsinry43</a>`, true},
+		{"http and www", `<A class="friend" HREF='http://www.grtsinry43.com/about'>x</A>`, true},
+		{"protocol relative", `<a target=_blank href=//grtsinry43.com>x</a>`, true},
+		{"escaped href", `<a href="https://grtsinry43.com/?a=1&amp;b=2">x</a>`, true},
+		{"plain text only", `<p>友链：grtsinry43.com</p>`, false},
+		{"other attribute", `<a data-url="https://grtsinry43.com" href="/links">x</a>`, false},
+		{"img src", `<img src="https://grtsinry43.com/logo.png">`, false},
+		{"lookalike host", `<a href="https://grtsinry43.com.evil.example/">x</a>`, false},
+		{"subdomain", `<a href="https://blog.grtsinry43.com/">x</a>`, false},
+		{"relative link", `<a href="/grtsinry43.com">x</a>`, false},
+	}
+	for _, tc := range cases {
+		if got := containsBacklink([]byte(tc.body), site); got != tc.want {
+			t.Errorf("%s: containsBacklink = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if containsBacklink([]byte(`<a href="https://grtsinry43.com">x</a>`), "") {
+		t.Error("empty site url should never match")
+	}
+}
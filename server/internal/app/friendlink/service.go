@@ -66,6 +66,8 @@ func (s *Service) Submit(ctx context.Context, cmd SubmitCmd) (*SubmitResult, err
 	existing.Message = toOptionalString(cmd.Message)
 	existing.UserID = cmd.UserID
 	existing.Status = "pending"
+	existing.ReviewReason = nil
+	existing.ReviewedAt = nil
 
 	if err := s.repo.Update(ctx, existing); err != nil {
 		return nil, err
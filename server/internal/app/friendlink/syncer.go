@@ -0,0 +1,279 @@
+package friendlink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/sysconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+const (
+	SyncStatusSuccess     = "success"
+	SyncStatusNotModified = "not_modified"
+	SyncStatusFailed      = "failed"
+
+	defaultCheckInterval = 5 * time.Minute
+	maxFeedBodyBytes     = 5 << 20
+	maxFeedFetchTimeout  = time.Minute
+	syncUserAgent        = "grtblog-friendlink-sync/2.0"
+)
+
+// Syncer 定时抓取友链的 RSS/Atom/JSON Feed 并缓存最近的文章。
+type Syncer struct {
+	linkRepo      social.FriendLinkRepository
+	postRepo      social.FriendLinkPostRepository
+	sysCfg        *sysconfig.Service
+	websubRepo    social.FriendLinkWebSubRepository
+	fedCfg        *federationconfig.Service
+	client        *http.Client
+	checkInterval time.Duration
+	mu            sync.Mutex
+	done          chan struct{}
+}
+
+// NewSyncer 创建同步器并启动后台循环，checkInterval 为检查哪些友链到期的频率。
+func NewSyncer(linkRepo social.FriendLinkRepository, postRepo social.FriendLinkPostRepository, sysCfg *sysconfig.Service, checkInterval time.Duration) *Syncer {
+	if checkInterval <= 0 {
+		checkInterval = defaultCheckInterval
+	}
+	syncer := &Syncer{
+		linkRepo:      linkRepo,
+		postRepo:      postRepo,
+		sysCfg:        sysCfg,
+		client:        fedinfra.NewGuardedHTTPClient(maxFeedFetchTimeout),
+		checkInterval: checkInterval,
+		done:          make(chan struct{}),
+	}
+	go syncer.loop()
+	return syncer
+}
+
+func (s *Syncer) Close() {
+	close(s.done)
+}
+
+func (s *Syncer) loop() {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.SyncDue(context.Background())
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// SyncDue 同步所有已到期的友链，上一轮未结束时直接跳过。
+func (s *Syncer) SyncDue(ctx context.Context) {
+	if !s.mu.TryLock() {
+		return
+	}
+	defer s.mu.Unlock()
+
+	settings, err := s.sysCfg.FriendLinkSyncSettings(ctx)
+	if err != nil {
+		log.Printf("[friendlink] 读取同步配置失败，使用默认值: %v", err)
+	}
+	links, err := s.linkRepo.ListSyncable(ctx)
+	if err != nil {
+		log.Printf("[friendlink] 获取待同步友链失败: %v", err)
+		return
+	}
+
+	now := time.Now()
+	var subs map[int64]*social.FriendLinkWebSub
+	if s.websubEnabled(settings) {
+		subs = s.loadWebSubs(ctx)
+	}
+	for _, link := range links {
+		if sub := subs[link.ID]; sub != nil {
+			s.renewWebSub(ctx, sub, now)
+			// 推送生效期间只做低频兜底轮询。
+			if pushing(sub, now) && link.LastSyncAt != nil && now.Sub(*link.LastSyncAt) < websubFallbackInterval {
+				continue
+			}
+		}
+		if !isSyncDue(link, settings.DefaultInterval, now) {
+			continue
+		}
+		if err := s.SyncLink(ctx, link, settings); err != nil {
+			log.Printf("[friendlink] 同步失败 id=%d url=%s err=%v", link.ID, safeString(link.RSSURL), err)
+		}
+	}
+}
+
+// SyncLink 抓取单个友链的订阅源，并回写同步状态。
+func (s *Syncer) SyncLink(ctx context.Context, link *social.FriendLink, settings sysconfig.FriendLinkSyncSettings) error {
+	state := social.FriendLinkSyncState{
+		LastSyncAt:       time.Now(),
+		TotalPostsCached: link.TotalPostsCached,
+		FeedETag:         link.FeedETag,
+		FeedLastModified: link.FeedLastModified,
+	}
+
+	syncErr := s.fetchAndStore(ctx, link, settings, &state)
+	if syncErr != nil {
+		msg := syncErr.Error()
+		state.LastSyncStatus = SyncStatusFailed
+		state.LastSyncError = &msg
+	}
+	if err := s.linkRepo.UpdateSyncState(ctx, link.ID, state); err != nil {
+		return errors.Join(syncErr, err)
+	}
+	return syncErr
+}
+
+func (s *Syncer) fetchAndStore(ctx context.Context, link *social.FriendLink, settings sysconfig.FriendLinkSyncSettings, state *social.FriendLinkSyncState) error {
+	feedURL := strings.TrimSpace(safeString(link.RSSURL))
+	if feedURL == "" {
+		return errors.New("未配置订阅地址")
+	}
+
+	timeout := settings.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", syncUserAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, text/xml;q=0.8, */*;q=0.5")
+	if etag := safeString(link.FeedETag); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := safeString(link.FeedLastModified); lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		state.LastSyncStatus = SyncStatusNotModified
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedBodyBytes))
+	if err != nil {
+		return err
+	}
+	items, err := ParseFeed(body, resp.Request.URL.String())
+	if err != nil {
+		return err
+	}
+	if err := s.storeItems(ctx, link, items, settings, state); err != nil {
+		return err
+	}
+	state.FeedETag = toOptionalString(resp.Header.Get("ETag"))
+	state.FeedLastModified = toOptionalString(resp.Header.Get("Last-Modified"))
+
+	if s.websubEnabled(settings) {
+		s.ensureWebSub(ctx, link, resp.Request.URL.String(), resp.Header.Values("Link"), body)
+	}
+	return nil
+}
+
+// storeItems 写入抓取或推送得到的条目，并裁剪到 maxItems 篇。
+func (s *Syncer) storeItems(ctx context.Context, link *social.FriendLink, items []FeedItem, settings sysconfig.FriendLinkSyncSettings, state *social.FriendLinkSyncState) error {
+	maxItems := settings.MaxItems
+	if maxItems <= 0 {
+		maxItems = 20
+	}
+	posts := buildFriendLinkPosts(link.ID, items, state.LastSyncAt, maxItems)
+	if err := s.postRepo.UpsertBatch(ctx, posts); err != nil {
+		return err
+	}
+	total, err := s.postRepo.TrimByLink(ctx, link.ID, maxItems)
+	if err != nil {
+		return err
+	}
+
+	state.LastSyncStatus = SyncStatusSuccess
+	state.TotalPostsCached = total
+	return nil
+}
+
+// buildFriendLinkPosts 将订阅条目转换为待保存的文章，按发布时间倒序截取前 maxItems 条。
+func buildFriendLinkPosts(linkID int64, items []FeedItem, fetchedAt time.Time, maxItems int) []social.FriendLinkPost {
+	seen := make(map[string]struct{}, len(items))
+	posts := make([]social.FriendLinkPost, 0, len(items))
+	for _, item := range items {
+		if _, ok := seen[item.GUID]; ok {
+			continue
+		}
+		seen[item.GUID] = struct{}{}
+		publishedAt := item.PublishedAt
+		if publishedAt.IsZero() || publishedAt.After(fetchedAt) {
+			publishedAt = fetchedAt
+		}
+		posts = append(posts, social.FriendLinkPost{
+			FriendLinkID: linkID,
+			GUID:         truncateRunes(item.GUID, 512),
+			Title:        truncateRunes(item.Title, 512),
+			URL:          truncateRunes(item.URL, 512),
+			Summary:      toOptionalString(item.Summary),
+			Author:       toOptionalString(truncateRunes(item.Author, 255)),
+			PublishedAt:  publishedAt,
+		})
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].PublishedAt.After(posts[j].PublishedAt)
+	})
+	if len(posts) > maxItems {
+		posts = posts[:maxItems]
+	}
+	return posts
+}
+
+func isSyncDue(link *social.FriendLink, defaultInterval time.Duration, now time.Time) bool {
+	if link.LastSyncAt == nil {
+		return true
+	}
+	interval := defaultInterval
+	if link.SyncInterval != nil && *link.SyncInterval > 0 {
+		interval = time.Duration(*link.SyncInterval) * time.Minute
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return now.Sub(*link.LastSyncAt) >= interval
+}
+
+func truncateRunes(val string, limit int) string {
+	runes := []rune(val)
+	if len(runes) <= limit {
+		return val
+	}
+	return string(runes[:limit])
+}
+
+func safeString(val *string) string {
+	if val == nil {
+		return ""
+	}
+	return *val
+}
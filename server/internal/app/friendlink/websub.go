@@ -0,0 +1,440 @@
+package friendlink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/sysconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+)
+
+const (
+	WebSubStatusPending = "pending"
+	WebSubStatusActive  = "active"
+	WebSubStatusDenied  = "denied"
+
+	WebSubCallbackPath = "/api/websub/callback/"
+
+	websubLeaseSeconds = 7 * 24 * 3600
+	// websubRenewBefore 租期剩余不足该时长时主动续订。
+	websubRenewBefore = 24 * time.Hour
+	// websubFallbackInterval 推送生效后仍按该间隔低频轮询兜底，防止 Hub 漏推。
+	websubFallbackInterval = 24 * time.Hour
+	// websubRetryAfter 订阅被拒或长时间未确认时，间隔该时长后再尝试。
+	websubRetryAfter = 24 * time.Hour
+	websubPendingTTL = time.Hour
+)
+
+var (
+	ErrWebSubSignature = errors.New("websub 推送签名无效")
+
+	linkHeaderPattern = regexp.MustCompile(`<([^>]*)>\s*((?:;\s*[^;,]+)*)`)
+	linkRelPattern    = regexp.MustCompile(`(?i);\s*rel\s*=\s*(?:"([^"]*)"|([^\s;,]+))`)
+)
+
+// WithWebSub 启用 WebSub 订阅：订阅源声明了 Hub 时向其订阅，激活后改为接收推送并降低轮询频率。
+// fedCfg 提供回调地址所需的本站实例地址。
+func (s *Syncer) WithWebSub(repo social.FriendLinkWebSubRepository, fedCfg *federationconfig.Service) *Syncer {
+	s.websubRepo = repo
+	s.fedCfg = fedCfg
+	return s
+}
+
+func (s *Syncer) websubEnabled(settings sysconfig.FriendLinkSyncSettings) bool {
+	return settings.WebSub && s.websubRepo != nil && s.fedCfg != nil
+}
+
+// pushing 订阅已确认且未过期，此时友链内容由 Hub 推送。
+func pushing(sub *social.FriendLinkWebSub, now time.Time) bool {
+	return sub != nil && sub.Status == WebSubStatusActive && sub.ExpiresAt != nil && sub.ExpiresAt.After(now)
+}
+
+func (s *Syncer) loadWebSubs(ctx context.Context) map[int64]*social.FriendLinkWebSub {
+	subs, err := s.websubRepo.ListAll(ctx)
+	if err != nil {
+		log.Printf("[friendlink] 获取 WebSub 订阅失败: %v", err)
+		return nil
+	}
+	byLink := make(map[int64]*social.FriendLinkWebSub, len(subs))
+	for _, sub := range subs {
+		byLink[sub.FriendLinkID] = sub
+	}
+	return byLink
+}
+
+// renewWebSub 对即将到期的订阅重新发起订阅请求（沿用原有回调与密钥）。
+func (s *Syncer) renewWebSub(ctx context.Context, sub *social.FriendLinkWebSub, now time.Time) {
+	if sub.Status != WebSubStatusActive || sub.ExpiresAt == nil || sub.ExpiresAt.Sub(now) > websubRenewBefore {
+		return
+	}
+	if err := s.subscribe(ctx, sub.FriendLinkID, sub.Hub, sub.Topic, sub); err != nil {
+		log.Printf("[friendlink] WebSub 续订失败 id=%d hub=%s err=%v", sub.FriendLinkID, sub.Hub, err)
+	}
+}
+
+// ensureWebSub 在成功抓取订阅源后检查 Hub 声明：没有 Hub 时删除旧订阅恢复轮询，
+// Hub 或 topic 变化、尚未订阅时发起订阅。
+func (s *Syncer) ensureWebSub(ctx context.Context, link *social.FriendLink, feedURL string, linkHeaders []string, body []byte) {
+	hub, self := discoverHub(feedURL, linkHeaders, body)
+	existing, err := s.websubRepo.GetByLinkID(ctx, link.ID)
+	if err != nil && !errors.Is(err, social.ErrFriendLinkWebSubNotFound) {
+		log.Printf("[friendlink] 读取 WebSub 订阅失败 id=%d err=%v", link.ID, err)
+		return
+	}
+	if hub == "" {
+		if existing != nil {
+			if err := s.websubRepo.Delete(ctx, link.ID); err != nil {
+				log.Printf("[friendlink] 删除 WebSub 订阅失败 id=%d err=%v", link.ID, err)
+			}
+		}
+		return
+	}
+	topic := firstNonEmpty(self, feedURL)
+
+	now := time.Now()
+	if existing != nil && existing.Hub == hub && existing.Topic == topic {
+		switch existing.Status {
+		case WebSubStatusActive:
+			if existing.ExpiresAt != nil && existing.ExpiresAt.Sub(now) > websubRenewBefore {
+				return
+			}
+		case WebSubStatusPending:
+			if now.Sub(existing.UpdatedAt) < websubPendingTTL {
+				return
+			}
+		case WebSubStatusDenied:
+			if now.Sub(existing.UpdatedAt) < websubRetryAfter {
+				return
+			}
+		}
+	} else {
+		existing = nil
+	}
+	if err := s.subscribe(ctx, link.ID, hub, topic, existing); err != nil {
+		log.Printf("[friendlink] WebSub 订阅失败 id=%d hub=%s err=%v", link.ID, hub, err)
+	}
+}
+
+// subscribe 向 Hub 提交订阅请求，Hub 随后会 GET 回调地址校验意图。
+// previous 不为空时复用其回调令牌与密钥，续订期间旧订阅保持有效。
+func (s *Syncer) subscribe(ctx context.Context, linkID int64, hub string, topic string, previous *social.FriendLinkWebSub) error {
+	fedSettings, err := s.fedCfg.Settings(ctx)
+	if err != nil {
+		return err
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(fedSettings.InstanceURL), "/")
+	if baseURL == "" {
+		// 没有公网地址时 Hub 无法回调，继续轮询。
+		return nil
+	}
+
+	sub := &social.FriendLinkWebSub{
+		FriendLinkID: linkID,
+		Hub:          hub,
+		Topic:        topic,
+		Status:       WebSubStatusPending,
+	}
+	if previous != nil {
+		sub.CallbackToken = previous.CallbackToken
+		sub.Secret = previous.Secret
+		sub.LeaseSeconds = previous.LeaseSeconds
+		sub.LastPushAt = previous.LastPushAt
+		if pushing(previous, time.Now()) {
+			sub.Status = WebSubStatusActive
+			sub.ExpiresAt = previous.ExpiresAt
+		}
+	}
+	if sub.CallbackToken == "" {
+		if sub.CallbackToken, err = randomHex(16); err != nil {
+			return err
+		}
+		if sub.Secret, err = randomHex(32); err != nil {
+			return err
+		}
+	}
+
+	form := url.Values{
+		"hub.mode":          {"subscribe"},
+		"hub.topic":         {topic},
+		"hub.callback":      {baseURL + WebSubCallbackPath + sub.CallbackToken},
+		"hub.secret":        {sub.Secret},
+		"hub.lease_seconds": {strconv.Itoa(websubLeaseSeconds)},
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, hub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", syncUserAgent)
+
+	// 先落库再请求，Hub 的校验回调可能在响应返回前到达。
+	if err := s.websubRepo.Upsert(ctx, sub); err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err == nil {
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			err = fmt.Errorf("hub returned %s", resp.Status)
+		}
+	}
+	if err != nil {
+		if sub.Status != WebSubStatusActive {
+			sub.Status = WebSubStatusDenied
+		}
+		msg := err.Error()
+		sub.Error = &msg
+		if upsertErr := s.websubRepo.Upsert(ctx, sub); upsertErr != nil {
+			return errors.Join(err, upsertErr)
+		}
+		return err
+	}
+	log.Printf("[friendlink] 已向 Hub 发起订阅 id=%d hub=%s topic=%s", linkID, hub, topic)
+	return nil
+}
+
+// VerifyWebSub 响应 Hub 的意图校验，返回需要回显的 challenge。
+// 只确认与记录一致的订阅请求；denied 表示 Hub 拒绝了订阅，恢复轮询。
+func (s *Syncer) VerifyWebSub(ctx context.Context, token string, mode string, topic string, challenge string, leaseSeconds string, reason string) (string, error) {
+	if s.websubRepo == nil {
+		return "", social.ErrFriendLinkWebSubNotFound
+	}
+	sub, err := s.websubRepo.GetByToken(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	if topic != sub.Topic {
+		return "", social.ErrFriendLinkWebSubNotFound
+	}
+
+	switch mode {
+	case "subscribe":
+		if challenge == "" {
+			return "", social.ErrFriendLinkWebSubNotFound
+		}
+		lease, err := strconv.Atoi(leaseSeconds)
+		if err != nil || lease <= 0 {
+			lease = websubLeaseSeconds
+		}
+		expiresAt := time.Now().Add(time.Duration(lease) * time.Second)
+		sub.Status = WebSubStatusActive
+		sub.LeaseSeconds = &lease
+		sub.ExpiresAt = &expiresAt
+		sub.Error = nil
+		if err := s.websubRepo.Upsert(ctx, sub); err != nil {
+			return "", err
+		}
+		log.Printf("[friendlink] WebSub 订阅已确认 id=%d hub=%s lease=%ds", sub.FriendLinkID, sub.Hub, lease)
+		return challenge, nil
+	case "denied":
+		sub.Status = WebSubStatusDenied
+		sub.ExpiresAt = nil
+		sub.Error = toOptionalString(firstNonEmpty(reason, "hub denied subscription"))
+		if err := s.websubRepo.Upsert(ctx, sub); err != nil {
+			return "", err
+		}
+		log.Printf("[friendlink] WebSub 订阅被拒绝 id=%d hub=%s reason=%s", sub.FriendLinkID, sub.Hub, reason)
+		return "", nil
+	default:
+		// 本站不会主动退订，其余模式一律不确认。
+		return "", social.ErrFriendLinkWebSubNotFound
+	}
+}
+
+// ReceiveWebSub 处理 Hub 推送的订阅源内容：校验 X-Hub-Signature 后按普通同步的方式写入文章。
+// 友链已删除、停用或不再使用订阅同步时返回 ErrFriendLinkNotFound，调用方应回复 410 让 Hub 停止推送。
+func (s *Syncer) ReceiveWebSub(ctx context.Context, token string, signature string, body []byte) error {
+	if s.websubRepo == nil {
+		return social.ErrFriendLinkWebSubNotFound
+	}
+	sub, err := s.websubRepo.GetByToken(ctx, token)
+	if err != nil {
+		return err
+	}
+	if !verifyHubSignature(sub.Secret, signature, body) {
+		return ErrWebSubSignature
+	}
+	link, err := s.linkRepo.FindByID(ctx, sub.FriendLinkID)
+	if err != nil {
+		return err
+	}
+	if !link.IsActive || link.SyncMode != SyncModeRSS || link.DeletedAt != nil {
+		return social.ErrFriendLinkNotFound
+	}
+
+	settings, err := s.sysCfg.FriendLinkSyncSettings(ctx)
+	if err != nil {
+		log.Printf("[friendlink] 读取同步配置失败，使用默认值: %v", err)
+	}
+	state := social.FriendLinkSyncState{
+		LastSyncAt:       time.Now(),
+		TotalPostsCached: link.TotalPostsCached,
+		FeedETag:         link.FeedETag,
+		FeedLastModified: link.FeedLastModified,
+	}
+	storeErr := func() error {
+		items, err := ParseFeed(body, sub.Topic)
+		if err != nil {
+			return err
+		}
+		return s.storeItems(ctx, link, items, settings, &state)
+	}()
+	if storeErr != nil {
+		msg := storeErr.Error()
+		state.LastSyncStatus = SyncStatusFailed
+		state.LastSyncError = &msg
+	}
+	if err := s.linkRepo.UpdateSyncState(ctx, link.ID, state); err != nil {
+		return errors.Join(storeErr, err)
+	}
+
+	now := state.LastSyncAt
+	sub.LastPushAt = &now
+	if err := s.websubRepo.Upsert(ctx, sub); err != nil {
+		log.Printf("[friendlink] 更新 WebSub 推送时间失败 id=%d err=%v", link.ID, err)
+	}
+	return storeErr
+}
+
+// verifyHubSignature 校验 "算法=十六进制摘要" 形式的签名，支持规范列出的 sha1/sha256/sha384/sha512。
+func verifyHubSignature(secret string, signature string, body []byte) bool {
+	method, digest, ok := strings.Cut(strings.TrimSpace(signature), "=")
+	if !ok {
+		return false
+	}
+	var newHash func() hash.Hash
+	switch strings.ToLower(method) {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha384":
+		newHash = sha512.New384
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return false
+	}
+	expected, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// discoverHub 依次从 HTTP Link 头、Feed 顶层的 link 元素（Atom 或 RSS 中的 atom:link）、
+// JSON Feed 的 hubs 字段中查找 Hub 与 self 地址。
+func discoverHub(feedURL string, linkHeaders []string, body []byte) (string, string) {
+	var hub, self string
+	for _, header := range linkHeaders {
+		for _, match := range linkHeaderPattern.FindAllStringSubmatch(header, -1) {
+			for _, rel := range linkRelPattern.FindAllStringSubmatch(match[2], -1) {
+				for _, token := range strings.Fields(strings.ToLower(rel[1] + rel[2])) {
+					switch {
+					case token == "hub" && hub == "":
+						hub = resolveFeedURL(feedURL, strings.TrimSpace(match[1]))
+					case token == "self" && self == "":
+						self = resolveFeedURL(feedURL, strings.TrimSpace(match[1]))
+					}
+				}
+			}
+		}
+	}
+	if hub != "" {
+		return hub, self
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")))
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var doc struct {
+			FeedURL string `json:"feed_url"`
+			Hubs    []struct {
+				Type string `json:"type"`
+				URL  string `json:"url"`
+			} `json:"hubs"`
+		}
+		if err := json.Unmarshal(trimmed, &doc); err != nil {
+			return "", ""
+		}
+		for _, item := range doc.Hubs {
+			if strings.EqualFold(item.Type, "websub") && strings.TrimSpace(item.URL) != "" {
+				return resolveFeedURL(feedURL, strings.TrimSpace(item.URL)), firstNonEmpty(self, resolveFeedURL(feedURL, strings.TrimSpace(doc.FeedURL)))
+			}
+		}
+		return "", ""
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(trimmed))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		// 只看 Feed 级别的 link，遇到第一个条目即停止。
+		if start.Name.Local == "entry" || start.Name.Local == "item" {
+			break
+		}
+		if start.Name.Local != "link" {
+			continue
+		}
+		var rel, href string
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "rel":
+				rel = strings.ToLower(attr.Value)
+			case "href":
+				href = strings.TrimSpace(attr.Value)
+			}
+		}
+		if href == "" {
+			continue
+		}
+		for _, token := range strings.Fields(rel) {
+			switch {
+			case token == "hub" && hub == "":
+				hub = resolveFeedURL(feedURL, href)
+			case token == "self" && self == "":
+				self = resolveFeedURL(feedURL, href)
+			}
+		}
+	}
+	return hub, self
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,41 @@
+package gallery
+
+import "time"
+
+// CreatePhotoCmd 创建照片；Location/Device/TakenAt 为空时由图片 EXIF 自动填充。
+type CreatePhotoCmd struct {
+	UploadID    *int64
+	URL         string
+	AlbumID     *int64
+	Description *string
+	Location    *string
+	Device      *string
+	TakenAt     *time.Time
+}
+
+type UpdatePhotoCmd struct {
+	ID          int64
+	AlbumID     *int64
+	Description *string
+	Location    *string
+	Device      *string
+	TakenAt     *time.Time
+}
+
+type CreateAlbumCmd struct {
+	Title       string
+	Description *string
+}
+
+type UpdateAlbumCmd struct {
+	ID           int64
+	Title        string
+	Description  *string
+	CoverPhotoID *int64
+}
+
+// Liker 点赞人：登录用户使用 UserID，访客使用客户端生成的 SessionID。
+type Liker struct {
+	UserID    *int64
+	SessionID string
+}
@@ -0,0 +1,362 @@
+package gallery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime/multipart"
+	"strings"
+
+	mediaapp "github.com/grtsinry43/grtblog-v2/server/internal/app/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/like"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/imageproc"
+)
+
+var (
+	ErrPhotoSourceRequired = errors.New("照片需指定上传文件或图片地址")
+	ErrUploadNotPicture    = errors.New("上传文件不是图片")
+	ErrLikerRequired       = errors.New("缺少点赞人标识")
+)
+
+// exifReadLimit 从已上传的文件读取 EXIF 时最多读取的字节数，EXIF 位于文件头部。
+const exifReadLimit = 256 << 10
+
+type Service struct {
+	photos  media.PhotoRepository
+	albums  media.AlbumRepository
+	likes   like.Repository
+	uploads *mediaapp.Service
+}
+
+func NewService(photos media.PhotoRepository, albums media.AlbumRepository, likes like.Repository, uploads *mediaapp.Service) *Service {
+	return &Service{
+		photos:  photos,
+		albums:  albums,
+		likes:   likes,
+		uploads: uploads,
+	}
+}
+
+// UploadPhoto 上传图片并创建照片；在图片被去除定位信息之前读取 EXIF，用于填充拍摄设备、位置与时间。
+func (s *Service) UploadPhoto(ctx context.Context, uploader mediaapp.Uploader, file *multipart.FileHeader, cmd CreatePhotoCmd) (*media.Photo, error) {
+	if file == nil {
+		return nil, ErrPhotoSourceRequired
+	}
+	exif := readExifFromFile(file)
+	result, err := s.uploads.Upload(ctx, uploader, file, "picture")
+	if err != nil {
+		return nil, err
+	}
+	return s.create(ctx, &result.File, exif, cmd)
+}
+
+// CreatePhoto 从已上传的图片或外部地址创建照片。已上传的图片通常已去除定位信息，只能补全设备与时间。
+func (s *Service) CreatePhoto(ctx context.Context, cmd CreatePhotoCmd) (*media.Photo, error) {
+	if cmd.UploadID == nil {
+		if strings.TrimSpace(cmd.URL) == "" {
+			return nil, ErrPhotoSourceRequired
+		}
+		return s.create(ctx, nil, nil, cmd)
+	}
+	upload, err := s.uploads.GetByID(ctx, *cmd.UploadID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Type != "picture" {
+		return nil, ErrUploadNotPicture
+	}
+	return s.create(ctx, upload, s.readExifFromUpload(ctx, upload), cmd)
+}
+
+func (s *Service) create(ctx context.Context, upload *media.UploadFile, exif *imageproc.Exif, cmd CreatePhotoCmd) (*media.Photo, error) {
+	photo := &media.Photo{
+		URL:         strings.TrimSpace(cmd.URL),
+		AlbumID:     cmd.AlbumID,
+		Description: trimOptional(cmd.Description),
+		Location:    trimOptional(cmd.Location),
+		Device:      trimOptional(cmd.Device),
+		TakenAt:     cmd.TakenAt,
+	}
+	if upload != nil {
+		photo.UploadID = &upload.ID
+		photo.URL = media.PublicURL(upload.Path)
+		photo.Width = upload.Width
+		photo.Height = upload.Height
+		photo.Blurhash = upload.Blurhash
+		if upload.DominantColor != "" {
+			photo.Shade = &upload.DominantColor
+		}
+	}
+	if exif != nil {
+		if photo.Device == nil {
+			photo.Device = trimOptional(ptr(exif.Device()))
+		}
+		if photo.Location == nil {
+			photo.Location = trimOptional(ptr(exif.Location()))
+		}
+		if photo.TakenAt == nil && !exif.TakenAt.IsZero() {
+			photo.TakenAt = &exif.TakenAt
+		}
+	}
+	if photo.AlbumID != nil {
+		if _, err := s.albums.FindByID(ctx, *photo.AlbumID); err != nil {
+			return nil, err
+		}
+		next, err := s.photos.NextSortOrder(ctx, *photo.AlbumID)
+		if err != nil {
+			return nil, err
+		}
+		photo.SortOrder = next
+	}
+	if err := s.photos.Create(ctx, photo); err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+func (s *Service) UpdatePhoto(ctx context.Context, cmd UpdatePhotoCmd) (*media.Photo, error) {
+	photo, err := s.photos.FindByID(ctx, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !sameAlbum(photo.AlbumID, cmd.AlbumID) {
+		photo.AlbumID = cmd.AlbumID
+		photo.SortOrder = 0
+		if cmd.AlbumID != nil {
+			if _, err := s.albums.FindByID(ctx, *cmd.AlbumID); err != nil {
+				return nil, err
+			}
+			if photo.SortOrder, err = s.photos.NextSortOrder(ctx, *cmd.AlbumID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	photo.Description = trimOptional(cmd.Description)
+	photo.Location = trimOptional(cmd.Location)
+	photo.Device = trimOptional(cmd.Device)
+	photo.TakenAt = cmd.TakenAt
+	if err := s.photos.Update(ctx, photo); err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+// DeletePhoto 删除照片及其评论与点赞，上传文件本身保留。
+func (s *Service) DeletePhoto(ctx context.Context, id int64) error {
+	return s.photos.Delete(ctx, id)
+}
+
+func (s *Service) GetPhoto(ctx context.Context, id int64) (*media.Photo, error) {
+	return s.photos.FindByID(ctx, id)
+}
+
+// ListPhotos 分页列出照片；albumID 不为 nil 时只列出该相册并按相册内排序。
+func (s *Service) ListPhotos(ctx context.Context, albumID *int64, offset int, limit int) ([]media.Photo, int64, error) {
+	if albumID != nil {
+		if _, err := s.albums.FindByID(ctx, *albumID); err != nil {
+			return nil, 0, err
+		}
+	}
+	return s.photos.List(ctx, albumID, offset, limit)
+}
+
+func (s *Service) ReorderPhotos(ctx context.Context, albumID int64, ids []int64) error {
+	if _, err := s.albums.FindByID(ctx, albumID); err != nil {
+		return err
+	}
+	return s.photos.UpdateOrder(ctx, albumID, ids)
+}
+
+func (s *Service) CreateAlbum(ctx context.Context, cmd CreateAlbumCmd) (*media.Album, error) {
+	title := strings.TrimSpace(cmd.Title)
+	if title == "" {
+		return nil, media.ErrAlbumTitleEmpty
+	}
+	album := &media.Album{
+		Title:       title,
+		Description: trimOptional(cmd.Description),
+	}
+	if err := s.albums.Create(ctx, album); err != nil {
+		return nil, err
+	}
+	return album, nil
+}
+
+// UpdateAlbum 更新相册；封面必须是该相册内的照片，传 nil 表示使用排序最靠前的照片。
+func (s *Service) UpdateAlbum(ctx context.Context, cmd UpdateAlbumCmd) (*media.Album, error) {
+	title := strings.TrimSpace(cmd.Title)
+	if title == "" {
+		return nil, media.ErrAlbumTitleEmpty
+	}
+	album, err := s.albums.FindByID(ctx, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.CoverPhotoID != nil {
+		cover, err := s.photos.FindByID(ctx, *cmd.CoverPhotoID)
+		if err != nil {
+			return nil, err
+		}
+		if cover.AlbumID == nil || *cover.AlbumID != album.ID {
+			return nil, media.ErrPhotoNotInAlbum
+		}
+	}
+	album.Title = title
+	album.Description = trimOptional(cmd.Description)
+	album.CoverPhotoID = cmd.CoverPhotoID
+	if err := s.albums.Update(ctx, album); err != nil {
+		return nil, err
+	}
+	return s.albums.FindByID(ctx, album.ID)
+}
+
+// DeleteAlbum 删除相册，相册内的照片移出相册但不删除。
+func (s *Service) DeleteAlbum(ctx context.Context, id int64) error {
+	return s.albums.Delete(ctx, id)
+}
+
+func (s *Service) GetAlbum(ctx context.Context, id int64) (*media.Album, error) {
+	return s.albums.FindByID(ctx, id)
+}
+
+func (s *Service) ListAlbums(ctx context.Context, offset int, limit int) ([]media.Album, int64, error) {
+	return s.albums.List(ctx, offset, limit)
+}
+
+func (s *Service) ReorderAlbums(ctx context.Context, ids []int64) error {
+	return s.albums.UpdateOrder(ctx, ids)
+}
+
+// LikePhoto 点赞照片，重复点赞不重复计数；返回点赞后的总数。
+func (s *Service) LikePhoto(ctx context.Context, photoID int64, liker Liker) (int, error) {
+	item, err := s.likeRecord(ctx, photoID, liker)
+	if err != nil {
+		return 0, err
+	}
+	created, err := s.likes.Create(ctx, item)
+	if err != nil {
+		return 0, err
+	}
+	if created {
+		if err := s.photos.IncLike(ctx, photoID); err != nil {
+			return 0, err
+		}
+	}
+	return s.likeCount(ctx, photoID)
+}
+
+// UnlikePhoto 取消点赞；未点赞过时不做改动。
+func (s *Service) UnlikePhoto(ctx context.Context, photoID int64, liker Liker) (int, error) {
+	item, err := s.likeRecord(ctx, photoID, liker)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := s.likes.Delete(ctx, item)
+	if err != nil {
+		return 0, err
+	}
+	if deleted {
+		if err := s.photos.DecLike(ctx, photoID); err != nil {
+			return 0, err
+		}
+	}
+	return s.likeCount(ctx, photoID)
+}
+
+// HasLiked 返回点赞人是否已点赞该照片；没有点赞人标识时返回 false。
+func (s *Service) HasLiked(ctx context.Context, photoID int64, liker Liker) (bool, error) {
+	if liker.UserID == nil && liker.SessionID == "" {
+		return false, nil
+	}
+	return s.likes.Exists(ctx, &like.ContentLike{
+		TargetType: like.TargetPhoto,
+		TargetID:   photoID,
+		UserID:     liker.UserID,
+		SessionID:  ptr(liker.SessionID),
+	})
+}
+
+func (s *Service) likeRecord(ctx context.Context, photoID int64, liker Liker) (*like.ContentLike, error) {
+	if liker.UserID == nil && liker.SessionID == "" {
+		return nil, ErrLikerRequired
+	}
+	if _, err := s.photos.FindByID(ctx, photoID); err != nil {
+		return nil, err
+	}
+	item := &like.ContentLike{
+		TargetType: like.TargetPhoto,
+		TargetID:   photoID,
+		UserID:     liker.UserID,
+	}
+	if liker.UserID == nil {
+		item.SessionID = &liker.SessionID
+	}
+	return item, nil
+}
+
+func (s *Service) likeCount(ctx context.Context, photoID int64) (int, error) {
+	photo, err := s.photos.FindByID(ctx, photoID)
+	if err != nil {
+		return 0, err
+	}
+	return photo.Likes, nil
+}
+
+func (s *Service) readExifFromUpload(ctx context.Context, upload *media.UploadFile) *imageproc.Exif {
+	body, _, err := s.uploads.Open(ctx, upload)
+	if err != nil {
+		log.Printf("[gallery] 读取上传文件失败 id=%d err=%v", upload.ID, err)
+		return nil
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, exifReadLimit))
+	if err != nil {
+		return nil
+	}
+	exif, err := imageproc.ReadExif(data)
+	if err != nil {
+		return nil
+	}
+	return exif
+}
+
+func readExifFromFile(file *multipart.FileHeader) *imageproc.Exif {
+	src, err := file.Open()
+	if err != nil {
+		return nil
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil
+	}
+	exif, err := imageproc.ReadExif(data)
+	if err != nil {
+		return nil
+	}
+	return exif
+}
+
+func sameAlbum(a *int64, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func trimOptional(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
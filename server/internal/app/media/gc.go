@@ -0,0 +1,209 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/storage"
+)
+
+// ErrUsageTrackingDisabled 未开启引用跟踪。
+var ErrUsageTrackingDisabled = errors.New("usage tracking is disabled")
+
+const orphanScanPageSize = 200
+
+// UsageTracker 返回引用跟踪器，未开启时为 nil。
+func (s *Service) UsageTracker() *UsageTracker {
+	return s.tracker
+}
+
+// Usages 列出引用该上传文件的内容。
+func (s *Service) Usages(ctx context.Context, owner Uploader, id int64) ([]media.Usage, error) {
+	if _, err := s.GetOwned(ctx, owner, id); err != nil {
+		return nil, err
+	}
+	if s.usages == nil {
+		return nil, ErrUsageTrackingDisabled
+	}
+	if err := s.syncReferences(ctx); err != nil {
+		return nil, err
+	}
+	return s.usages.ListByUpload(ctx, id)
+}
+
+// ListOrphans 分页列出超过宽限期且未被任何内容引用的上传文件。
+func (s *Service) ListOrphans(ctx context.Context, page int, size int) (*ListResult, error) {
+	if s.usages == nil {
+		return nil, ErrUsageTrackingDisabled
+	}
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 {
+		size = 10
+	}
+	if size > 100 {
+		size = 100
+	}
+	if err := s.syncReferences(ctx); err != nil {
+		return nil, err
+	}
+	items, total, err := s.usages.ListOrphans(ctx, s.orphanCutoff(), (page-1)*size, size)
+	if err != nil {
+		return nil, err
+	}
+	return &ListResult{Items: items, Total: total, Page: page, Size: size}, nil
+}
+
+// StrayObject 是存储上存在、但没有任何 upload_file 记录（含尺寸变体）对应的文件。
+type StrayObject struct {
+	Driver  string
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// GCFailure 记录回收过程中删除失败的条目。
+type GCFailure struct {
+	Path  string
+	Error string
+}
+
+// GCReport 是一次垃圾回收（或预演）的结果。
+type GCReport struct {
+	DryRun     bool
+	Orphans    []media.UploadFile
+	Strays     []StrayObject
+	FreedBytes int64
+	Failures   []GCFailure
+}
+
+// CollectGarbage 清理孤儿上传文件与存储上的游离文件；dryRun 为 true 时只统计不删除。
+// 执行前会全量重建引用关系，避免事件丢失导致误删。
+func (s *Service) CollectGarbage(ctx context.Context, dryRun bool) (*GCReport, error) {
+	if s.usages == nil {
+		return nil, ErrUsageTrackingDisabled
+	}
+	if s.tracker != nil {
+		if err := s.tracker.Rebuild(ctx); err != nil {
+			return nil, err
+		}
+	}
+	report := &GCReport{DryRun: dryRun}
+	cutoff := s.orphanCutoff()
+
+	orphans, err := s.collectOrphans(ctx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	strays, err := s.collectStrays(ctx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	report.Orphans = orphans
+	report.Strays = strays
+
+	for _, file := range orphans {
+		if dryRun {
+			report.FreedBytes += file.Size
+			continue
+		}
+		if err := s.removeFile(ctx, &file); err != nil {
+			report.Failures = append(report.Failures, GCFailure{Path: file.Path, Error: err.Error()})
+			continue
+		}
+		report.FreedBytes += file.Size
+	}
+	for _, stray := range strays {
+		if dryRun {
+			report.FreedBytes += stray.Size
+			continue
+		}
+		if err := s.drivers[stray.Driver].Delete(ctx, stray.Key); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			report.Failures = append(report.Failures, GCFailure{Path: media.StoredPath(stray.Driver, stray.Key), Error: err.Error()})
+			continue
+		}
+		report.FreedBytes += stray.Size
+	}
+	if !dryRun {
+		log.Printf("[media] 垃圾回收完成 orphans=%d strays=%d freed=%d failures=%d", len(orphans), len(strays), report.FreedBytes, len(report.Failures))
+	}
+	return report, nil
+}
+
+// syncReferences 同步头像、友链 logo 等不发事件的引用，避免它们被当作孤儿文件。
+func (s *Service) syncReferences(ctx context.Context) error {
+	if s.tracker == nil {
+		return nil
+	}
+	return s.tracker.SyncReferences(ctx)
+}
+
+func (s *Service) orphanCutoff() time.Time {
+	return time.Now().Add(-s.grace)
+}
+
+func (s *Service) collectOrphans(ctx context.Context, cutoff time.Time) ([]media.UploadFile, error) {
+	var orphans []media.UploadFile
+	for offset := 0; ; offset += orphanScanPageSize {
+		items, total, err := s.usages.ListOrphans(ctx, cutoff, offset, orphanScanPageSize)
+		if err != nil {
+			return nil, err
+		}
+		orphans = append(orphans, items...)
+		if int64(offset+orphanScanPageSize) >= total || len(items) == 0 {
+			return orphans, nil
+		}
+	}
+}
+
+// collectStrays 遍历支持列举的驱动，找出不属于任何上传记录的文件。
+func (s *Service) collectStrays(ctx context.Context, cutoff time.Time) ([]StrayObject, error) {
+	known, err := s.knownPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var strays []StrayObject
+	for name, driver := range s.drivers {
+		lister, ok := driver.(storage.Lister)
+		if !ok {
+			continue
+		}
+		err := lister.List(ctx, func(info storage.ObjectInfo) error {
+			if _, ok := known[media.StoredPath(name, info.Key)]; ok {
+				return nil
+			}
+			if !info.ModTime.IsZero() && info.ModTime.After(cutoff) {
+				return nil
+			}
+			strays = append(strays, StrayObject{Driver: name, Key: info.Key, Size: info.Size, ModTime: info.ModTime})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return strays, nil
+}
+
+func (s *Service) knownPaths(ctx context.Context) (map[string]struct{}, error) {
+	known := make(map[string]struct{})
+	for offset := 0; ; offset += orphanScanPageSize {
+		items, total, err := s.repo.List(ctx, nil, offset, orphanScanPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			known[item.Path] = struct{}{}
+			for _, variant := range item.Variants {
+				known[variant.Path] = struct{}{}
+			}
+		}
+		if int64(offset+orphanScanPageSize) >= total || len(items) == 0 {
+			return known, nil
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/imageproc"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/storage"
+)
+
+// ErrNotImage 文件无法按图片解码。
+var ErrNotImage = errors.New("file is not a supported image")
+
+// resizeStep 按需缩放的宽度向上取整到该步长，限制同一图片可能产生的缓存文件数量。
+const resizeStep = 32
+
+// metaSampleSide 计算主色与 blurhash 前先把图片缩到该尺寸以内。
+const metaSampleSide = 64
+
+// ImageOptions 控制图片处理流程。
+type ImageOptions struct {
+	VariantWidths  []int
+	VariantFormats []string
+	Quality        int
+	MaxPixels      int
+	MaxResizeWidth int
+	CacheDir       string
+}
+
+// ImagePipeline 处理上传的图片：去除定位信息、提取元数据、生成尺寸变体，并提供带磁盘缓存的按需缩放。
+type ImagePipeline struct {
+	opts    ImageOptions
+	formats []string
+	group   singleflight.Group
+}
+
+func NewImagePipeline(opts ImageOptions) *ImagePipeline {
+	if opts.Quality <= 0 || opts.Quality > 100 {
+		opts.Quality = imageproc.DefaultQuality
+	}
+	if opts.MaxResizeWidth <= 0 {
+		opts.MaxResizeWidth = 2560
+	}
+	if opts.CacheDir == "" {
+		opts.CacheDir = filepath.Join("storage", "cache", "images")
+	}
+	widths := make([]int, 0, len(opts.VariantWidths))
+	for _, w := range opts.VariantWidths {
+		if w > 0 && !slices.Contains(widths, w) {
+			widths = append(widths, w)
+		}
+	}
+	slices.Sort(widths)
+	opts.VariantWidths = widths
+
+	var formats []string
+	for _, raw := range opts.VariantFormats {
+		format := imageproc.NormalizeFormat(raw)
+		if format == "" || slices.Contains(formats, format) {
+			continue
+		}
+		if !imageproc.CanEncode(format) {
+			log.Printf("[media] 图片格式 %s 暂无纯 Go 编码器，不生成该格式的变体", format)
+			continue
+		}
+		formats = append(formats, format)
+	}
+	return &ImagePipeline{opts: opts, formats: formats}
+}
+
+// Prepare 去除图片中的定位信息，返回实际写入存储的数据。
+func (p *ImagePipeline) Prepare(data []byte) []byte {
+	stripped, changed := imageproc.StripGPS(data)
+	if changed {
+		log.Printf("[media] 已移除图片中的定位信息 size=%d", len(data))
+	}
+	return stripped
+}
+
+// Process 解码已写入存储的图片，提取元数据并把尺寸变体写入同一驱动。
+// 解码失败时返回零值元数据，不影响原文件的上传。
+func (p *ImagePipeline) Process(ctx context.Context, driver storage.Driver, key string, data []byte) media.ImageMeta {
+	img, err := imageproc.Decode(data, p.opts.MaxPixels)
+	if err != nil {
+		log.Printf("[media] 图片解码失败，跳过处理 key=%s err=%v", key, err)
+		return media.ImageMeta{}
+	}
+	meta := media.ImageMeta{
+		Width:  img.Width(),
+		Height: img.Height(),
+	}
+	sample := imageproc.Fit(img.Image, metaSampleSide)
+	meta.DominantColor = imageproc.DominantColor(sample)
+	if hash, err := imageproc.Blurhash(sample, imageproc.BlurhashXComponents, imageproc.BlurhashYComponents); err == nil {
+		meta.Blurhash = hash
+	}
+
+	base := strings.TrimSuffix(key, filepath.Ext(key))
+	for _, width := range p.opts.VariantWidths {
+		if width >= meta.Width {
+			break
+		}
+		resized := imageproc.Resize(img.Image, width)
+		for _, format := range p.formats {
+			var buf bytes.Buffer
+			if err := imageproc.Encode(&buf, resized, format, p.opts.Quality); err != nil {
+				log.Printf("[media] 生成图片变体失败 key=%s width=%d format=%s err=%v", key, width, format, err)
+				continue
+			}
+			variantKey := fmt.Sprintf("%s-w%d%s", base, width, imageproc.Extension(format))
+			size := int64(buf.Len())
+			if err := driver.Put(ctx, variantKey, &buf, size, mime.TypeByExtension(imageproc.Extension(format))); err != nil {
+				log.Printf("[media] 写入图片变体失败 key=%s err=%v", variantKey, err)
+				continue
+			}
+			meta.Variants = append(meta.Variants, media.ImageVariant{
+				Width:  width,
+				Height: resized.Bounds().Dy(),
+				Format: format,
+				Path:   media.StoredPath(driver.Name(), variantKey),
+				Size:   size,
+			})
+		}
+	}
+	return meta
+}
+
+// ResizeRequest 描述一次按需缩放，Width 为 0 表示保持原宽，Format 为空表示沿用原格式。
+type ResizeRequest struct {
+	Width  int
+	Format string
+}
+
+// Resize 返回缩放结果在缓存目录中的路径；缓存未命中时读取原图生成，同一结果并发请求只生成一次。
+func (p *ImagePipeline) Resize(ctx context.Context, driver storage.Driver, key string, req ResizeRequest) (string, error) {
+	format, err := p.outputFormat(key, req.Format)
+	if err != nil {
+		return "", err
+	}
+	width := req.Width
+	if width > p.opts.MaxResizeWidth {
+		width = p.opts.MaxResizeWidth
+	}
+	if width > 0 {
+		width = (width + resizeStep - 1) / resizeStep * resizeStep
+	}
+
+	name := "w" + strconv.Itoa(width) + imageproc.Extension(format)
+	if width == 0 {
+		name = "original" + imageproc.Extension(format)
+	}
+	target := filepath.Join(p.cacheDirFor(key), name)
+	if _, err := os.Stat(target); err == nil {
+		return target, nil
+	}
+
+	_, err, _ = p.group.Do(target, func() (any, error) {
+		if _, err := os.Stat(target); err == nil {
+			return nil, nil
+		}
+		// 结果由所有等待者共享，不随首个请求的取消而中断。
+		return nil, p.render(context.WithoutCancel(ctx), driver, key, target, width, format)
+	})
+	if err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// Purge 删除某个对象的全部按需缩放缓存。
+func (p *ImagePipeline) Purge(key string) {
+	if err := os.RemoveAll(p.cacheDirFor(key)); err != nil {
+		log.Printf("[media] 清理图片缓存失败 key=%s err=%v", key, err)
+	}
+}
+
+func (p *ImagePipeline) render(ctx context.Context, driver storage.Driver, key string, target string, width int, format string) error {
+	body, _, err := driver.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return err
+	}
+	img, err := imageproc.Decode(data, p.opts.MaxPixels)
+	if err != nil {
+		if errors.Is(err, imageproc.ErrTooManyPixels) {
+			return err
+		}
+		return ErrNotImage
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".resize-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := imageproc.Encode(tmp, imageproc.Resize(img.Image, width), format, p.opts.Quality); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// outputFormat 解析目标格式：AVIF 无法编码时退回 WebP，未指定时沿用原图格式（GIF 转为 PNG，缩放后不保留动画）。
+func (p *ImagePipeline) outputFormat(key string, requested string) (string, error) {
+	if strings.TrimSpace(requested) == "" {
+		switch format := imageproc.NormalizeFormat(strings.TrimPrefix(filepath.Ext(key), ".")); format {
+		case "", imageproc.FormatGIF:
+			return imageproc.FormatPNG, nil
+		default:
+			if imageproc.CanEncode(format) {
+				return format, nil
+			}
+			return imageproc.FormatWebP, nil
+		}
+	}
+	format := imageproc.NormalizeFormat(requested)
+	if format == "" {
+		return "", imageproc.ErrUnsupportedFormat
+	}
+	if !imageproc.CanEncode(format) {
+		return imageproc.FormatWebP, nil
+	}
+	return format, nil
+}
+
+func (p *ImagePipeline) cacheDirFor(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	digest := hex.EncodeToString(sum[:])
+	return filepath.Join(p.opts.CacheDir, digest[:2], digest)
+}
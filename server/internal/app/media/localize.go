@@ -0,0 +1,345 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/content"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+)
+
+// LocalizeOptions 控制外链图片转存。
+type LocalizeOptions struct {
+	// MaxSize 单张图片的大小上限（字节），超过则保留原链接。
+	MaxSize int64
+	// MaxImages 单次保存最多转存的图片数，避免一篇内容拖住整个请求。
+	MaxImages int
+	// UserAgent 下载时使用的 User-Agent，部分图床会拒绝空 UA。
+	UserAgent string
+}
+
+// ImageLocalizer 下载内容中的外链图片并保存为本站上传文件，实现 contentutil.ImageLocalizer。
+// 下载经过 SSRF 防护的 client，保存走与普通上传相同的类型校验与哈希去重，并记录原始地址以免重复下载。
+type ImageLocalizer struct {
+	uploads *Service
+	sources media.SourceRepository
+	client  *http.Client
+	opts    LocalizeOptions
+}
+
+var _ contentutil.ImageLocalizer = (*ImageLocalizer)(nil)
+
+// NewImageLocalizer 创建转存器；client 应当拒绝连接内网地址，如 federation.NewGuardedHTTPClient。
+func NewImageLocalizer(uploads *Service, sources media.SourceRepository, client *http.Client, opts LocalizeOptions) *ImageLocalizer {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 10 << 20
+	}
+	if opts.MaxImages <= 0 {
+		opts.MaxImages = 50
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = "grtblog-image-localizer/2"
+	}
+	return &ImageLocalizer{uploads: uploads, sources: sources, client: client, opts: opts}
+}
+
+// LocalizeMarkdown 转存 Markdown 中的外链图片；同一地址的全部出现位置（含引用定义与 <img> 标签）一并改写。
+func (l *ImageLocalizer) LocalizeMarkdown(ctx context.Context, userID int64, markdown string) (string, error) {
+	count := 0
+	replacements := make(map[string]string)
+	for _, raw := range contentutil.ExtractImageURLs(markdown) {
+		if !isRemoteImageURL(raw) {
+			continue
+		}
+		if count >= l.opts.MaxImages {
+			log.Printf("[media] 外链图片超过单次转存上限 %d，其余图片保留原地址", l.opts.MaxImages)
+			break
+		}
+		count++
+		local, err := l.localize(ctx, userID, raw)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return markdown, ctxErr
+			}
+			log.Printf("[media] 外链图片转存失败 url=%s: %v", raw, err)
+			continue
+		}
+		replacements[raw] = local
+	}
+	return contentutil.ReplaceImageURLs(markdown, replacements), nil
+}
+
+// LocalizeURL 转存单个外链图片地址，失败时返回原地址。
+func (l *ImageLocalizer) LocalizeURL(ctx context.Context, userID int64, rawURL string) (string, error) {
+	raw := strings.TrimSpace(rawURL)
+	if !isRemoteImageURL(raw) {
+		return rawURL, nil
+	}
+	local, err := l.localize(ctx, userID, raw)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return rawURL, ctxErr
+		}
+		log.Printf("[media] 外链图片转存失败 url=%s: %v", raw, err)
+		return rawURL, nil
+	}
+	return local, nil
+}
+
+// localize 返回外链图片对应的站内地址；该地址此前转存过且文件仍在时直接复用。
+func (l *ImageLocalizer) localize(ctx context.Context, userID int64, raw string) (string, error) {
+	if existing, err := l.sources.FindByURL(ctx, raw); err == nil {
+		if l.uploads.objectExists(ctx, existing.Path) {
+			return media.PublicURL(existing.Path), nil
+		}
+	} else if !errors.Is(err, media.ErrUploadFileNotFound) {
+		return "", err
+	}
+
+	data, contentType, err := l.download(ctx, raw)
+	if err != nil {
+		return "", err
+	}
+	// 转存由保存内容触发，作者已通过内容权限校验，不计入个人配额。
+	result, err := l.uploads.upload(ctx, Uploader{UserID: userID, Admin: true}, uploadSource{
+		name:        remoteFileName(raw),
+		size:        int64(len(data)),
+		contentType: contentType,
+		open: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		},
+	}, "picture")
+	if err != nil {
+		return "", err
+	}
+	if err := l.sources.Record(ctx, result.File.ID, raw); err != nil {
+		log.Printf("[media] 记录图片来源失败 upload=%d: %v", result.File.ID, err)
+	}
+	return media.PublicURL(result.File.Path), nil
+}
+
+func (l *ImageLocalizer) download(ctx context.Context, raw string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, raw, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", l.opts.UserAgent)
+	req.Header.Set("Accept", "image/*")
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("%w: status %d", media.ErrRemoteImageFetch, resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && !strings.HasPrefix(mediaType, "image/") &&
+		mediaType != "application/octet-stream" && mediaType != "binary/octet-stream" {
+		return nil, "", fmt.Errorf("%w: %s", media.ErrUnsupportedFileType, mediaType)
+	}
+	if resp.ContentLength > l.opts.MaxSize {
+		return nil, "", media.ErrUploadTooLarge
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, l.opts.MaxSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > l.opts.MaxSize {
+		return nil, "", media.ErrUploadTooLarge
+	}
+	if len(data) == 0 {
+		return nil, "", fmt.Errorf("%w: empty body", media.ErrRemoteImageFetch)
+	}
+	return data, contentType, nil
+}
+
+func isRemoteImageURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return u.Scheme == "http" || u.Scheme == "https"
+}
+
+// remoteFileName 取 URL 路径的最后一段作为文件名，扩展名由类型校验按实际内容修正。
+func remoteFileName(raw string) string {
+	name := "image"
+	if u, err := url.Parse(raw); err == nil {
+		if base := path.Base(u.Path); base != "." && base != "/" && base != "" {
+			if unescaped, err := url.PathUnescape(base); err == nil {
+				base = unescaped
+			}
+			name = base
+		}
+	}
+	if len(name) > 200 {
+		name = name[len(name)-200:]
+	}
+	return name
+}
+
+// LocalizeTarget 转存某类内容中单条记录的外链图片并保存，内容无变化时返回 false。
+type LocalizeTarget func(ctx context.Context, id int64) (bool, error)
+
+// LocalizeJobStatus 是批量转存任务的进度。
+type LocalizeJobStatus struct {
+	Running    bool
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	Scanned    int
+	Changed    int
+	Failures   []LocalizeFailure
+}
+
+// LocalizeFailure 记录批量转存时保存失败的内容。
+type LocalizeFailure struct {
+	OwnerType string
+	OwnerID   int64
+	Error     string
+}
+
+// ErrLocalizeJobRunning 已有批量转存任务在执行。
+var ErrLocalizeJobRunning = errors.New("localize job is already running")
+
+// localizeMaxFailures 状态中最多保留的失败条目数。
+const localizeMaxFailures = 100
+
+// LocalizeJob 在后台批量转存已有文章、手记与页面中的外链图片，同一时间只运行一个。
+type LocalizeJob struct {
+	contents content.Repository
+	targets  map[string]LocalizeTarget
+
+	mu     sync.Mutex
+	status LocalizeJobStatus
+}
+
+// NewLocalizeJob 创建批量任务；targets 以 media.UsageOwner* 为键，缺少的类型会被跳过。
+func NewLocalizeJob(contents content.Repository, targets map[string]LocalizeTarget) *LocalizeJob {
+	return &LocalizeJob{contents: contents, targets: targets}
+}
+
+// Start 在后台启动任务并立即返回；任务不跟随请求的 ctx，进度通过 Status 查询。
+func (j *LocalizeJob) Start() (LocalizeJobStatus, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status.Running {
+		return j.snapshot(), ErrLocalizeJobRunning
+	}
+	now := time.Now()
+	j.status = LocalizeJobStatus{Running: true, StartedAt: &now}
+	go j.run(context.Background())
+	return j.snapshot(), nil
+}
+
+// Status 返回当前或最近一次任务的进度。
+func (j *LocalizeJob) Status() LocalizeJobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.snapshot()
+}
+
+func (j *LocalizeJob) snapshot() LocalizeJobStatus {
+	status := j.status
+	status.Failures = slices.Clone(j.status.Failures)
+	return status
+}
+
+func (j *LocalizeJob) run(ctx context.Context) {
+	defer func() {
+		now := time.Now()
+		j.mu.Lock()
+		j.status.Running = false
+		j.status.FinishedAt = &now
+		j.mu.Unlock()
+	}()
+	for _, ownerType := range []string{media.UsageOwnerArticle, media.UsageOwnerMoment, media.UsageOwnerPage} {
+		target, ok := j.targets[ownerType]
+		if !ok {
+			continue
+		}
+		ids, err := j.listIDs(ctx, ownerType)
+		if err != nil {
+			log.Printf("[media] 转存任务读取 %s 列表失败: %v", ownerType, err)
+			j.record(ownerType, 0, false, err)
+			continue
+		}
+		for _, id := range ids {
+			changed, err := target(ctx, id)
+			j.record(ownerType, id, changed, err)
+		}
+	}
+	status := j.Status()
+	log.Printf("[media] 转存任务完成 scanned=%d changed=%d failed=%d", status.Scanned, status.Changed, len(status.Failures))
+}
+
+func (j *LocalizeJob) record(ownerType string, id int64, changed bool, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if id > 0 {
+		j.status.Scanned++
+	}
+	if changed {
+		j.status.Changed++
+	}
+	if err != nil && len(j.status.Failures) < localizeMaxFailures {
+		j.status.Failures = append(j.status.Failures, LocalizeFailure{OwnerType: ownerType, OwnerID: id, Error: err.Error()})
+	}
+}
+
+// listIDs 先取出全部 ID 再逐条处理，避免保存后排序变化导致分页漏项。
+func (j *LocalizeJob) listIDs(ctx context.Context, ownerType string) ([]int64, error) {
+	var ids []int64
+	for page := 1; ; page++ {
+		var total int64
+		var n int
+		switch ownerType {
+		case media.UsageOwnerArticle:
+			items, count, err := j.contents.ListArticles(ctx, content.ArticleListOptionsInternal{Page: page, PageSize: rebuildPageSize})
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+			total, n = count, len(items)
+		case media.UsageOwnerMoment:
+			items, count, err := j.contents.ListMoments(ctx, content.MomentListOptionsInternal{Page: page, PageSize: rebuildPageSize})
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+			total, n = count, len(items)
+		case media.UsageOwnerPage:
+			items, count, err := j.contents.ListPages(ctx, content.PageListOptionsInternal{Page: page, PageSize: rebuildPageSize})
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+			total, n = count, len(items)
+		default:
+			return nil, nil
+		}
+		if int64(page*rebuildPageSize) >= total || n == 0 {
+			return ids, nil
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/storage"
+)
+
+const migrateBatchSize = 100
+
+// MigrateOptions 控制存储迁移。
+type MigrateOptions struct {
+	// DryRun 只统计需要迁移的文件，不复制也不改写 Path。
+	DryRun bool
+	// DeleteSource 复制并改写 Path 后删除源对象。
+	DeleteSource bool
+}
+
+// MigrateReport 迁移结果。
+type MigrateReport struct {
+	Scanned  int
+	Migrated int
+	Skipped  int
+	Failed   []MigrateFailure
+}
+
+type MigrateFailure struct {
+	ID   int64
+	Path string
+	Err  error
+}
+
+// Migrate 将 from 驱动上的文件复制到 to 驱动并改写 UploadFile.Path；单个文件失败不影响其他文件，可重复执行。
+func Migrate(ctx context.Context, repo media.Repository, from storage.Driver, to storage.Driver, opts MigrateOptions) (*MigrateReport, error) {
+	if from == nil || to == nil {
+		return nil, media.ErrStorageDriverNotFound
+	}
+	if from.Name() == to.Name() {
+		return nil, fmt.Errorf("source and target driver are both %s", from.Name())
+	}
+	report := &MigrateReport{}
+	for offset := 0; ; offset += migrateBatchSize {
+		files, total, err := repo.List(ctx, nil, offset, migrateBatchSize)
+		if err != nil {
+			return report, err
+		}
+		for i := range files {
+			file := files[i]
+			report.Scanned++
+			driver, key := file.StorageKey()
+			if driver != from.Name() || key == "" {
+				report.Skipped++
+				continue
+			}
+			if opts.DryRun {
+				report.Migrated++
+				continue
+			}
+			if err := migrateFile(ctx, repo, from, to, file, key, opts.DeleteSource); err != nil {
+				log.Printf("[media] 迁移文件失败 id=%d path=%s err=%v", file.ID, file.Path, err)
+				report.Failed = append(report.Failed, MigrateFailure{ID: file.ID, Path: file.Path, Err: err})
+				continue
+			}
+			report.Migrated++
+		}
+		if len(files) == 0 || int64(offset+len(files)) >= total {
+			return report, nil
+		}
+	}
+}
+
+func migrateFile(ctx context.Context, repo media.Repository, from storage.Driver, to storage.Driver, file media.UploadFile, key string, deleteSource bool) error {
+	if err := copyObject(ctx, from, to, key); err != nil {
+		return err
+	}
+	// 图片变体随原图一起迁移，变体缺失不阻塞原图迁移。
+	var movedVariants []string
+	if len(file.Variants) > 0 {
+		meta := file.ImageMeta
+		meta.Variants = append([]media.ImageVariant(nil), file.Variants...)
+		for i, variant := range meta.Variants {
+			driver, variantKey := media.SplitStoredPath(variant.Path)
+			if driver != from.Name() {
+				continue
+			}
+			if err := copyObject(ctx, from, to, variantKey); err != nil {
+				log.Printf("[media] 迁移图片变体失败 path=%s err=%v", variant.Path, err)
+				continue
+			}
+			meta.Variants[i].Path = media.StoredPath(to.Name(), variantKey)
+			movedVariants = append(movedVariants, variantKey)
+		}
+		if err := repo.UpdateImageMeta(ctx, file.ID, meta); err != nil {
+			return err
+		}
+	}
+	if err := repo.UpdatePath(ctx, file.ID, media.StoredPath(to.Name(), key)); err != nil {
+		return err
+	}
+	if deleteSource {
+		for _, variantKey := range movedVariants {
+			if err := from.Delete(ctx, variantKey); err != nil {
+				log.Printf("[media] 删除源图片变体失败 key=%s err=%v", variantKey, err)
+			}
+		}
+		return from.Delete(ctx, key)
+	}
+	return nil
+}
+
+// copyObject 复制对象；目标已有同名对象（上次迁移中断于改写 Path 前）时跳过。
+func copyObject(ctx context.Context, from storage.Driver, to storage.Driver, key string) error {
+	if _, err := to.Stat(ctx, key); err == nil {
+		return nil
+	} else if !errors.Is(err, storage.ErrObjectNotFound) {
+		return err
+	}
+	body, info, err := from.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return to.Put(ctx, key, body, info.Size, info.ContentType)
+}
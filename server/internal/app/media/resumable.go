@@ -0,0 +1,451 @@
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+)
+
+// ErrInvalidChecksum 校验和不是合法的 SHA-256 十六进制串。
+var ErrInvalidChecksum = errors.New("checksum must be a sha256 hex digest")
+
+const (
+	chunkSuffix         = ".part"
+	assembledFileName   = "assembled"
+	sessionCleanupBatch = 100
+)
+
+// ResumableOptions 分片上传参数，零值字段使用默认值。
+type ResumableOptions struct {
+	MaxSize         int64
+	ChunkSize       int64
+	SessionTTL      time.Duration
+	TempDir         string
+	CleanupInterval time.Duration
+}
+
+// ResumableService 实现 init/chunk/complete 断点续传：分片暂存在本地临时目录，已收到的分片以文件为准，
+// 客户端断线后查询会话即可知道还缺哪些分片。
+type ResumableService struct {
+	uploads  *Service
+	sessions media.UploadSessionRepository
+	opts     ResumableOptions
+
+	mu         sync.Mutex
+	completing map[string]*sessionLock
+	done       chan struct{}
+}
+
+func NewResumableService(uploads *Service, sessions media.UploadSessionRepository, opts ResumableOptions) *ResumableService {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = 2 << 30
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = 5 << 20
+	}
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = 24 * time.Hour
+	}
+	if opts.TempDir == "" {
+		opts.TempDir = filepath.Join("storage", "tmp", "uploads")
+	}
+	svc := &ResumableService{
+		uploads:    uploads,
+		sessions:   sessions,
+		opts:       opts,
+		completing: make(map[string]*sessionLock),
+		done:       make(chan struct{}),
+	}
+	if opts.CleanupInterval > 0 {
+		go svc.loop(opts.CleanupInterval)
+	}
+	return svc
+}
+
+func (s *ResumableService) Close() {
+	close(s.done)
+}
+
+func (s *ResumableService) loop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if removed, err := s.CleanupExpired(context.Background()); err != nil {
+				log.Printf("[media] 清理过期上传会话失败 err=%v", err)
+			} else if removed > 0 {
+				log.Printf("[media] 已清理过期上传会话 count=%d", removed)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// InitUploadCmd 创建分片上传会话。
+type InitUploadCmd struct {
+	Owner Uploader
+	Name  string
+	Type  string
+	Size  int64
+	// Hash 整个文件的 SHA-256，可选；服务器已有相同内容时直接完成（秒传），合并后也会据此校验。
+	Hash string
+}
+
+// SessionState 是会话当前进度；File 在会话完成后非空。
+type SessionState struct {
+	Session  media.UploadSession
+	Received []int
+	File     *media.UploadFile
+}
+
+func (s *ResumableService) MaxSize() int64 {
+	return s.opts.MaxSize
+}
+
+func (s *ResumableService) Init(ctx context.Context, cmd InitUploadCmd) (*SessionState, error) {
+	name := filepath.Base(strings.TrimSpace(cmd.Name))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return nil, errors.New("name is required")
+	}
+	if _, err := dirForType(cmd.Type); err != nil {
+		return nil, err
+	}
+	if cmd.Size <= 0 {
+		return nil, errors.New("size must be positive")
+	}
+	if cmd.Size > s.opts.MaxSize {
+		return nil, media.ErrUploadTooLarge
+	}
+	if err := s.uploads.CheckQuota(ctx, cmd.Owner, cmd.Size); err != nil {
+		return nil, err
+	}
+	hash, err := normalizeChecksum(cmd.Hash, true)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &media.UploadSession{
+		ID:          uuid.NewString(),
+		UserID:      cmd.Owner.UserID,
+		Name:        name,
+		Type:        strings.ToLower(strings.TrimSpace(cmd.Type)),
+		Size:        cmd.Size,
+		ChunkSize:   s.opts.ChunkSize,
+		TotalChunks: int((cmd.Size + s.opts.ChunkSize - 1) / s.opts.ChunkSize),
+		Hash:        hash,
+		ExpiresAt:   time.Now().Add(s.opts.SessionTTL),
+	}
+
+	if hash != "" {
+		existing, err := s.uploads.FindReusable(ctx, hash)
+		if err != nil && !errors.Is(err, media.ErrUploadFileNotFound) {
+			return nil, err
+		}
+		// 只复用自己的文件，否则知道哈希即可拿到他人文件的地址。
+		if existing != nil && (cmd.Owner.Admin || (existing.UserID != nil && *existing.UserID == cmd.Owner.UserID)) {
+			session.UploadID = &existing.ID
+			if err := s.sessions.Create(ctx, session); err != nil {
+				return nil, err
+			}
+			return &SessionState{Session: *session, File: existing}, nil
+		}
+	}
+
+	if err := os.MkdirAll(s.sessionDir(session.ID), 0o755); err != nil {
+		return nil, err
+	}
+	if err := s.sessions.Create(ctx, session); err != nil {
+		os.RemoveAll(s.sessionDir(session.ID))
+		return nil, err
+	}
+	return &SessionState{Session: *session, Received: []int{}}, nil
+}
+
+// Status 返回会话进度，用于断点续传时确定缺失的分片。
+func (s *ResumableService) Status(ctx context.Context, owner Uploader, id string) (*SessionState, error) {
+	session, err := s.load(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.state(ctx, session)
+}
+
+// PutChunk 保存一个分片；checksum 为该分片的 SHA-256，不一致时拒绝写入。重复上传同一分片会覆盖。
+func (s *ResumableService) PutChunk(ctx context.Context, owner Uploader, id string, index int, data []byte, checksum string) error {
+	session, err := s.load(ctx, owner, id)
+	if err != nil {
+		return err
+	}
+	if session.Completed() {
+		return nil
+	}
+	if index < 0 || index >= session.TotalChunks {
+		return media.ErrChunkOutOfRange
+	}
+	if int64(len(data)) != session.ChunkLength(index) {
+		return media.ErrChunkSizeMismatch
+	}
+	expected, err := normalizeChecksum(checksum, false)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != expected {
+		return media.ErrChunkChecksumMismatch
+	}
+
+	dir := s.sessionDir(session.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".chunk-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.chunkPath(session.ID, index))
+}
+
+// Complete 合并全部分片并按普通上传保存（同样按哈希去重）；会话已完成时直接返回结果。
+func (s *ResumableService) Complete(ctx context.Context, owner Uploader, id string) (*SessionState, error) {
+	lock := s.lockFor(id)
+	lock.Lock()
+	defer func() {
+		lock.Unlock()
+		s.releaseLock(id)
+	}()
+
+	session, err := s.load(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.Completed() {
+		return s.state(ctx, session)
+	}
+	received, err := s.receivedChunks(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(received) != session.TotalChunks {
+		return nil, media.ErrUploadSessionIncomplete
+	}
+
+	assembled := filepath.Join(s.sessionDir(session.ID), assembledFileName)
+	hash, err := s.assemble(session, assembled)
+	if err != nil {
+		os.Remove(assembled)
+		return nil, err
+	}
+	if session.Hash != "" && session.Hash != hash {
+		os.Remove(assembled)
+		return nil, media.ErrUploadHashMismatch
+	}
+
+	result, err := s.uploads.UploadLocalFile(ctx, owner, assembled, session.Name, session.Type, hash)
+	if err != nil {
+		os.Remove(assembled)
+		return nil, err
+	}
+	if err := s.sessions.MarkCompleted(ctx, session.ID, result.File.ID); err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(s.sessionDir(session.ID)); err != nil {
+		log.Printf("[media] 删除分片临时目录失败 session=%s err=%v", session.ID, err)
+	}
+	session.UploadID = &result.File.ID
+	return &SessionState{Session: *session, Received: received, File: &result.File}, nil
+}
+
+// Abort 放弃会话并删除已上传的分片。
+func (s *ResumableService) Abort(ctx context.Context, owner Uploader, id string) error {
+	session, err := s.load(ctx, owner, id)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(s.sessionDir(session.ID)); err != nil {
+		return err
+	}
+	return s.sessions.Delete(ctx, session.ID)
+}
+
+// CleanupExpired 删除过期会话及其暂存分片，返回清理的会话数。
+func (s *ResumableService) CleanupExpired(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		expired, err := s.sessions.ListExpired(ctx, time.Now(), sessionCleanupBatch)
+		if err != nil {
+			return removed, err
+		}
+		for _, session := range expired {
+			if err := os.RemoveAll(s.sessionDir(session.ID)); err != nil {
+				return removed, err
+			}
+			if err := s.sessions.Delete(ctx, session.ID); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		if len(expired) < sessionCleanupBatch {
+			return removed, nil
+		}
+	}
+}
+
+func (s *ResumableService) load(ctx context.Context, owner Uploader, id string) (*media.UploadSession, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, media.ErrUploadSessionNotFound
+	}
+	session, err := s.sessions.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != owner.UserID || (!session.Completed() && time.Now().After(session.ExpiresAt)) {
+		return nil, media.ErrUploadSessionNotFound
+	}
+	return session, nil
+}
+
+func (s *ResumableService) state(ctx context.Context, session *media.UploadSession) (*SessionState, error) {
+	if session.Completed() {
+		file, err := s.uploads.GetByID(ctx, *session.UploadID)
+		if err != nil {
+			return nil, err
+		}
+		return &SessionState{Session: *session, File: file}, nil
+	}
+	received, err := s.receivedChunks(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &SessionState{Session: *session, Received: received}, nil
+}
+
+func (s *ResumableService) receivedChunks(id string) ([]int, error) {
+	entries, err := os.ReadDir(s.sessionDir(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return []int{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	received := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), chunkSuffix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if index, err := strconv.Atoi(name); err == nil {
+			received = append(received, index)
+		}
+	}
+	slices.Sort(received)
+	return received, nil
+}
+
+// assemble 按序拼接分片并返回整体 SHA-256。
+func (s *ResumableService) assemble(session *media.UploadSession, target string) (string, error) {
+	out, err := os.Create(target)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	hasher := sha256.New()
+	writer := io.MultiWriter(out, hasher)
+	var total int64
+	for index := 0; index < session.TotalChunks; index++ {
+		chunk, err := os.Open(s.chunkPath(session.ID, index))
+		if err != nil {
+			return "", err
+		}
+		n, err := io.Copy(writer, chunk)
+		chunk.Close()
+		if err != nil {
+			return "", err
+		}
+		total += n
+	}
+	if total != session.Size {
+		return "", media.ErrChunkSizeMismatch
+	}
+	if err := out.Sync(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func (s *ResumableService) sessionDir(id string) string {
+	return filepath.Join(s.opts.TempDir, id)
+}
+
+func (s *ResumableService) chunkPath(id string, index int) string {
+	return filepath.Join(s.sessionDir(id), strconv.Itoa(index)+chunkSuffix)
+}
+
+// sessionLock 防止同一会话被并发合并，refs 归零后从表中移除。
+type sessionLock struct {
+	sync.Mutex
+	refs int
+}
+
+func (s *ResumableService) lockFor(id string) *sessionLock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, ok := s.completing[id]
+	if !ok {
+		lock = &sessionLock{}
+		s.completing[id] = lock
+	}
+	lock.refs++
+	return lock
+}
+
+func (s *ResumableService) releaseLock(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lock, ok := s.completing[id]; ok {
+		lock.refs--
+		if lock.refs <= 0 {
+			delete(s.completing, id)
+		}
+	}
+}
+
+func normalizeChecksum(value string, optional bool) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" && optional {
+		return "", nil
+	}
+	if len(value) != sha256.Size*2 {
+		return "", ErrInvalidChecksum
+	}
+	if _, err := hex.DecodeString(value); err != nil {
+		return "", ErrInvalidChecksum
+	}
+	return value, nil
+}
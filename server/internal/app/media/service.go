@@ -1,12 +1,15 @@
 package media
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"io"
+	"log"
+	"mime"
 	"mime/multipart"
 	"os"
 	"path/filepath"
@@ -14,60 +17,182 @@ import (
 	"time"
 
 	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/storage"
 )
 
+// ErrNotLocalFile 文件不在本地驱动上，没有磁盘路径。
+var ErrNotLocalFile = errors.New("file is not stored on local disk")
+
+// ErrImageProcessingDisabled 未开启图片处理。
+var ErrImageProcessingDisabled = errors.New("image processing is disabled")
+
 type Service struct {
-	repo      media.Repository
-	uploadDir string
+	repo    media.Repository
+	primary storage.Driver
+	drivers map[string]storage.Driver
+	private bool
+	images  *ImagePipeline
+	usages  media.UsageRepository
+	tracker *UsageTracker
+	grace   time.Duration
+	limits  UploadLimits
 }
 
-func NewService(repo media.Repository, uploadDir string) *Service {
-	trimmed := strings.TrimSpace(uploadDir)
-	if trimmed == "" {
-		trimmed = filepath.Join("storage", "uploads")
+// UploadLimits 提供随系统配置变化的上传限制，通常由 sysconfig.Service 实现。
+type UploadLimits interface {
+	// UploadUserQuotaBytes 返回单个用户的存储配额，0 表示不限制。
+	UploadUserQuotaBytes(ctx context.Context) int64
+	UploadSVGPolicy(ctx context.Context) string
+}
+
+// Uploader 是发起操作的用户；管理员不受配额限制，并可管理所有人的文件。
+type Uploader struct {
+	UserID int64
+	Admin  bool
+}
+
+// NewService 创建服务；新文件写入 primary，others 用于读取与删除迁移前仍留在其他驱动上的文件。
+func NewService(repo media.Repository, primary storage.Driver, others ...storage.Driver) *Service {
+	if primary == nil {
+		primary = storage.NewLocalDriver("", storage.NewURLSigner("", "/uploads"))
+	}
+	drivers := map[string]storage.Driver{primary.Name(): primary}
+	for _, driver := range others {
+		if driver != nil {
+			if _, ok := drivers[driver.Name()]; !ok {
+				drivers[driver.Name()] = driver
+			}
+		}
 	}
 	return &Service{
-		repo:      repo,
-		uploadDir: trimmed,
+		repo:    repo,
+		primary: primary,
+		drivers: drivers,
 	}
 }
 
+// WithPrivate 开启后 /uploads 只接受签名地址访问。
+func (s *Service) WithPrivate(private bool) *Service {
+	s.private = private
+	return s
+}
+
+func (s *Service) Private() bool {
+	return s.private
+}
+
+// WithImagePipeline 开启图片处理；为 nil 时图片按原样保存，/uploads 也不支持缩放参数。
+func (s *Service) WithImagePipeline(images *ImagePipeline) *Service {
+	s.images = images
+	return s
+}
+
+// WithUsageTracking 开启引用跟踪：删除仍被引用的文件需要强制确认，并可列出孤儿文件与执行垃圾回收。
+func (s *Service) WithUsageTracking(usages media.UsageRepository, tracker *UsageTracker, grace time.Duration) *Service {
+	s.usages = usages
+	s.tracker = tracker
+	s.grace = grace
+	return s
+}
+
+// WithLimits 开启按用户的配额与 SVG 策略；为 nil 时不限制配额，SVG 默认清洗后保存。
+func (s *Service) WithLimits(limits UploadLimits) *Service {
+	s.limits = limits
+	return s
+}
+
 type UploadResult struct {
 	File    media.UploadFile
 	Created bool
 }
 
-func (s *Service) Upload(ctx context.Context, file *multipart.FileHeader, fileType string) (*UploadResult, error) {
+func (s *Service) Upload(ctx context.Context, owner Uploader, file *multipart.FileHeader, fileType string) (*UploadResult, error) {
 	if file == nil {
 		return nil, errors.New("file is required")
 	}
+	return s.upload(ctx, owner, multipartSource(file), fileType)
+}
 
+// UploadLocalFile 保存服务器上已有的文件（如分片合并结果），hash 已知时可传入以免重复计算。
+func (s *Service) UploadLocalFile(ctx context.Context, owner Uploader, path string, name string, fileType string, hash string) (*UploadResult, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.upload(ctx, owner, uploadSource{
+		name: name,
+		size: stat.Size(),
+		hash: hash,
+		open: func() (io.ReadCloser, error) { return os.Open(path) },
+	}, fileType)
+}
+
+// FindReusable 按内容哈希查找仍可访问的已上传文件，用于秒传。
+func (s *Service) FindReusable(ctx context.Context, hash string) (*media.UploadFile, error) {
+	existing, err := s.repo.FindByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !s.objectExists(ctx, existing.Path) {
+		return nil, media.ErrUploadFileNotFound
+	}
+	return existing, nil
+}
+
+// uploadSource 是待保存的文件内容：multipart 表单文件或服务器上的本地文件。
+type uploadSource struct {
+	name        string
+	size        int64
+	contentType string
+	hash        string
+	open        func() (io.ReadCloser, error)
+}
+
+func multipartSource(file *multipart.FileHeader) uploadSource {
+	return uploadSource{
+		name:        file.Filename,
+		size:        file.Size,
+		contentType: file.Header.Get("Content-Type"),
+		open: func() (io.ReadCloser, error) {
+			return file.Open()
+		},
+	}
+}
+
+func (s *Service) upload(ctx context.Context, owner Uploader, src uploadSource, fileType string) (*UploadResult, error) {
 	dir, err := dirForType(fileType)
 	if err != nil {
 		return nil, err
 	}
+	picture := dir == "pictures"
 
-	hash, err := hashFile(file)
+	src, ext, err := validateSource(src, picture, s.svgPolicy(ctx))
 	if err != nil {
 		return nil, err
 	}
 
+	hash := src.hash
+	if hash == "" {
+		hash, err = hashSource(src)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	existing, err := s.repo.FindByHash(ctx, hash)
 	if err != nil && !errors.Is(err, media.ErrUploadFileNotFound) {
 		return nil, err
 	}
 
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	filename := s.buildFilename(dir, ext)
-	storedPath := "/" + dir + "/" + filename
-	diskPath := s.diskPathFromStored(storedPath)
+	key := dir + "/" + s.buildFilename(ctx, dir, ext)
+	storedPath := media.StoredPath(s.primary.Name(), key)
 
 	if existing != nil {
-		existingDisk := s.diskPathFromStored(existing.Path)
-		if fileExists(existingDisk) {
+		if s.objectExists(ctx, existing.Path) {
 			return &UploadResult{File: *existing, Created: false}, nil
 		}
-		if err := s.saveFile(file, diskPath); err != nil {
+		_, meta, err := s.storeUpload(ctx, src, key, picture)
+		if err != nil {
 			return nil, err
 		}
 		if existing.Path != storedPath {
@@ -76,19 +201,33 @@ func (s *Service) Upload(ctx context.Context, file *multipart.FileHeader, fileTy
 			}
 			existing.Path = storedPath
 		}
+		if picture && s.images != nil {
+			if err := s.repo.UpdateImageMeta(ctx, existing.ID, meta); err != nil {
+				return nil, err
+			}
+			existing.ImageMeta = meta
+		}
 		return &UploadResult{File: *existing, Created: false}, nil
 	}
 
-	if err := s.saveFile(file, diskPath); err != nil {
+	if err := s.CheckQuota(ctx, owner, src.size); err != nil {
+		return nil, err
+	}
+	size, meta, err := s.storeUpload(ctx, src, key, picture)
+	if err != nil {
 		return nil, err
 	}
 
 	record := &media.UploadFile{
-		Name: file.Filename,
-		Path: storedPath,
-		Type: strings.ToLower(strings.TrimSpace(fileType)),
-		Size: file.Size,
-		Hash: hash,
+		Name:      src.name,
+		Path:      storedPath,
+		Type:      strings.ToLower(strings.TrimSpace(fileType)),
+		Size:      size,
+		Hash:      hash,
+		ImageMeta: meta,
+	}
+	if owner.UserID > 0 {
+		record.UserID = &owner.UserID
 	}
 	if err := s.repo.Create(ctx, record); err != nil {
 		return nil, err
@@ -103,7 +242,34 @@ type ListResult struct {
 	Size  int
 }
 
-func (s *Service) List(ctx context.Context, page int, size int) (*ListResult, error) {
+// CheckQuota 检查用户再上传 size 字节是否超出配额，管理员不受限制。
+func (s *Service) CheckQuota(ctx context.Context, owner Uploader, size int64) error {
+	if owner.Admin || owner.UserID <= 0 || s.limits == nil {
+		return nil
+	}
+	quota := s.limits.UploadUserQuotaBytes(ctx)
+	if quota <= 0 {
+		return nil
+	}
+	used, err := s.repo.SumSizeByUser(ctx, owner.UserID)
+	if err != nil {
+		return err
+	}
+	if used+size > quota {
+		return media.ErrUploadQuotaExceeded
+	}
+	return nil
+}
+
+func (s *Service) svgPolicy(ctx context.Context) string {
+	if s.limits == nil {
+		return SVGPolicySanitize
+	}
+	return s.limits.UploadSVGPolicy(ctx)
+}
+
+// List 分页列出文件，非管理员只能看到自己上传的文件。
+func (s *Service) List(ctx context.Context, owner Uploader, page int, size int) (*ListResult, error) {
 	if page <= 0 {
 		page = 1
 	}
@@ -114,7 +280,11 @@ func (s *Service) List(ctx context.Context, page int, size int) (*ListResult, er
 		size = 100
 	}
 	offset := (page - 1) * size
-	items, total, err := s.repo.List(ctx, offset, size)
+	var userID *int64
+	if !owner.Admin {
+		userID = &owner.UserID
+	}
+	items, total, err := s.repo.List(ctx, userID, offset, size)
 	if err != nil {
 		return nil, err
 	}
@@ -126,12 +296,12 @@ func (s *Service) List(ctx context.Context, page int, size int) (*ListResult, er
 	}, nil
 }
 
-func (s *Service) Rename(ctx context.Context, id int64, name string) (*media.UploadFile, error) {
+func (s *Service) Rename(ctx context.Context, owner Uploader, id int64, name string) (*media.UploadFile, error) {
 	trimmed := strings.TrimSpace(name)
 	if trimmed == "" {
 		return nil, errors.New("name is required")
 	}
-	file, err := s.repo.FindByID(ctx, id)
+	file, err := s.GetOwned(ctx, owner, id)
 	if err != nil {
 		return nil, err
 	}
@@ -145,71 +315,214 @@ func (s *Service) Rename(ctx context.Context, id int64, name string) (*media.Upl
 	return file, nil
 }
 
-func (s *Service) Delete(ctx context.Context, id int64) (*media.UploadFile, error) {
-	file, err := s.repo.FindByID(ctx, id)
+// Delete 删除上传文件；文件仍被内容引用且未指定 force 时返回 ErrUploadFileInUse 及引用列表。
+// 被相册照片使用的文件即使指定 force 也不会删除（返回 ErrUploadFileUsedByPhoto），需先删除照片。
+func (s *Service) Delete(ctx context.Context, owner Uploader, id int64, force bool) (*media.UploadFile, []media.Usage, error) {
+	file, err := s.GetOwned(ctx, owner, id)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	diskPath := s.diskPathFromStored(file.Path)
-	if err := removeFile(diskPath); err != nil {
-		return nil, err
+	var usages []media.Usage
+	if s.usages != nil {
+		if err := s.syncReferences(ctx); err != nil {
+			return nil, nil, err
+		}
+		usages, err = s.usages.ListByUpload(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, usage := range usages {
+			if usage.OwnerType == media.UsageOwnerPhoto {
+				return file, usages, media.ErrUploadFileUsedByPhoto
+			}
+		}
+		if len(usages) > 0 && !force {
+			return file, usages, media.ErrUploadFileInUse
+		}
 	}
-	if err := s.repo.DeleteByID(ctx, id); err != nil {
-		return nil, err
+	if err := s.removeFile(ctx, file); err != nil {
+		return nil, nil, err
 	}
-	return file, nil
+	if len(usages) > 0 {
+		log.Printf("[media] 强制删除仍被引用的上传文件 id=%d usages=%d", id, len(usages))
+	}
+	return file, usages, nil
+}
+
+func (s *Service) removeFile(ctx context.Context, file *media.UploadFile) error {
+	driver, key, err := s.driverFor(file.Path)
+	if err != nil {
+		return err
+	}
+	if err := driver.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+		return err
+	}
+	for _, variant := range file.Variants {
+		if variantDriver, variantKey, err := s.driverFor(variant.Path); err == nil {
+			if err := variantDriver.Delete(ctx, variantKey); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+				log.Printf("[media] 删除图片变体失败 path=%s err=%v", variant.Path, err)
+			}
+		}
+	}
+	if s.images != nil {
+		s.images.Purge(key)
+	}
+	return s.repo.DeleteByID(ctx, file.ID)
 }
 
 func (s *Service) GetByID(ctx context.Context, id int64) (*media.UploadFile, error) {
 	return s.repo.FindByID(ctx, id)
 }
 
+// GetOwned 获取文件并校验归属，非管理员访问他人（或无归属）的文件时按不存在处理。
+func (s *Service) GetOwned(ctx context.Context, owner Uploader, id int64) (*media.UploadFile, error) {
+	file, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !owner.Admin && (file.UserID == nil || *file.UserID != owner.UserID) {
+		return nil, media.ErrUploadFileNotFound
+	}
+	return file, nil
+}
+
+// ResolveDiskPath 返回本地驱动上文件的磁盘路径；文件位于其他驱动时返回 ErrNotLocalFile。
 func (s *Service) ResolveDiskPath(storedPath string) (string, error) {
-	diskPath := s.diskPathFromStored(storedPath)
-	if diskPath == "" {
-		return "", errors.New("empty stored path")
+	driver, key, err := s.driverFor(storedPath)
+	if err != nil {
+		return "", err
+	}
+	local, ok := driver.(*storage.LocalDriver)
+	if !ok {
+		return "", ErrNotLocalFile
 	}
-	return diskPath, nil
+	return local.DiskPath(key)
 }
 
-func (s *Service) saveFile(file *multipart.FileHeader, path string) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return err
+// Open 打开文件内容，调用方负责关闭。
+func (s *Service) Open(ctx context.Context, file *media.UploadFile) (io.ReadCloser, *storage.ObjectInfo, error) {
+	driver, key, err := s.driverFor(file.Path)
+	if err != nil {
+		return nil, nil, err
 	}
-	if fileExists(path) {
-		return nil
+	return driver.Open(ctx, key)
+}
+
+// SignedURL 生成限时访问地址，ttl 超出驱动上限时按上限截断。
+func (s *Service) SignedURL(ctx context.Context, file *media.UploadFile, ttl time.Duration) (string, error) {
+	driver, key, err := s.driverFor(file.Path)
+	if err != nil {
+		return "", err
 	}
-	src, err := file.Open()
+	return driver.SignedURL(ctx, key, ttl)
+}
+
+// ServedObject 是 /uploads 路由按对象键找到的文件。
+type ServedObject struct {
+	Driver storage.Driver
+	Key    string
+}
+
+// LocateObject 按对象键查找文件，先查主驱动，再查迁移中仍在使用的其他驱动。
+func (s *Service) LocateObject(ctx context.Context, key string) (*ServedObject, error) {
+	clean, err := storage.CleanKey(key)
 	if err != nil {
-		return err
+		return nil, storage.ErrObjectNotFound
 	}
-	defer src.Close()
+	if _, err := s.primary.Stat(ctx, clean); err == nil {
+		return &ServedObject{Driver: s.primary, Key: clean}, nil
+	} else if !errors.Is(err, storage.ErrObjectNotFound) {
+		return nil, err
+	}
+	for name, driver := range s.drivers {
+		if name == s.primary.Name() {
+			continue
+		}
+		if _, err := driver.Stat(ctx, clean); err == nil {
+			return &ServedObject{Driver: driver, Key: clean}, nil
+		}
+	}
+	return nil, storage.ErrObjectNotFound
+}
 
-	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+// ResizeImage 按需缩放图片并返回缓存文件路径；未开启图片处理时返回 ErrImageProcessingDisabled。
+func (s *Service) ResizeImage(ctx context.Context, object *ServedObject, req ResizeRequest) (string, error) {
+	if s.images == nil {
+		return "", ErrImageProcessingDisabled
+	}
+	return s.images.Resize(ctx, object.Driver, object.Key, req)
+}
+
+// VerifySignedURL 校验本地签名地址。
+func (s *Service) VerifySignedURL(key string, expires string, signature string) bool {
+	for _, driver := range s.drivers {
+		if local, ok := driver.(*storage.LocalDriver); ok && local.Signer() != nil {
+			return local.Signer().Verify(key, expires, signature)
+		}
+	}
+	return false
+}
+
+func (s *Service) driverFor(storedPath string) (storage.Driver, string, error) {
+	name, key := media.SplitStoredPath(storedPath)
+	if key == "" {
+		return nil, "", errors.New("empty stored path")
+	}
+	driver, ok := s.drivers[name]
+	if !ok {
+		return nil, "", media.ErrStorageDriverNotFound
+	}
+	return driver, key, nil
+}
+
+func (s *Service) objectExists(ctx context.Context, storedPath string) bool {
+	driver, key, err := s.driverFor(storedPath)
 	if err != nil {
-		return err
+		return false
 	}
-	defer dst.Close()
+	_, err = driver.Stat(ctx, key)
+	return err == nil
+}
 
-	_, err = io.Copy(dst, src)
-	return err
+// storeUpload 写入上传文件并返回实际大小；开启图片处理时图片会先去除定位信息，再提取元数据并生成尺寸变体。
+func (s *Service) storeUpload(ctx context.Context, src uploadSource, key string, picture bool) (int64, media.ImageMeta, error) {
+	if !picture || s.images == nil {
+		return src.size, media.ImageMeta{}, s.saveFile(ctx, src, key)
+	}
+	reader, err := src.open()
+	if err != nil {
+		return 0, media.ImageMeta{}, err
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return 0, media.ImageMeta{}, err
+	}
+	data = s.images.Prepare(data)
+	size := int64(len(data))
+	if err := s.primary.Put(ctx, key, bytes.NewReader(data), size, contentTypeFor(src, key)); err != nil {
+		return 0, media.ImageMeta{}, err
+	}
+	return size, s.images.Process(ctx, s.primary, key, data), nil
 }
 
-func (s *Service) diskPathFromStored(storedPath string) string {
-	trimmed := strings.TrimSpace(storedPath)
-	if trimmed == "" {
-		return ""
+func (s *Service) saveFile(ctx context.Context, src uploadSource, key string) error {
+	reader, err := src.open()
+	if err != nil {
+		return err
 	}
-	clean := filepath.Clean(trimmed)
-	clean = strings.TrimPrefix(clean, string(filepath.Separator))
-	uploadDir := filepath.Clean(s.uploadDir)
-	if strings.HasPrefix(clean, uploadDir+string(filepath.Separator)) || clean == uploadDir {
-		return clean
+	defer reader.Close()
+	return s.primary.Put(ctx, key, reader, src.size, contentTypeFor(src, key))
+}
+
+func contentTypeFor(src uploadSource, key string) string {
+	if byExt := mime.TypeByExtension(filepath.Ext(key)); byExt != "" {
+		return byExt
 	}
-	return filepath.Join(uploadDir, clean)
+	return src.contentType
 }
 
-func (s *Service) buildFilename(dir string, ext string) string {
+func (s *Service) buildFilename(ctx context.Context, dir string, ext string) string {
 	base := time.Now().Format("2006-01-02-15:04:05")
 	ext = strings.TrimSpace(ext)
 	if ext != "" && !strings.HasPrefix(ext, ".") {
@@ -218,7 +531,7 @@ func (s *Service) buildFilename(dir string, ext string) string {
 	for i := 0; i < 5; i++ {
 		suffix := randomHex(2)
 		filename := base + "-" + suffix + ext
-		if !fileExists(filepath.Join(s.uploadDir, dir, filename)) {
+		if _, err := s.primary.Stat(ctx, dir+"/"+filename); errors.Is(err, storage.ErrObjectNotFound) {
 			return filename
 		}
 	}
@@ -253,34 +566,16 @@ func dirForType(fileType string) (string, error) {
 	}
 }
 
-func hashFile(file *multipart.FileHeader) (string, error) {
-	src, err := file.Open()
+func hashSource(src uploadSource) (string, error) {
+	reader, err := src.open()
 	if err != nil {
 		return "", err
 	}
-	defer src.Close()
+	defer reader.Close()
 
 	hasher := sha256.New()
-	if _, err := io.Copy(hasher, src); err != nil {
+	if _, err := io.Copy(hasher, reader); err != nil {
 		return "", err
 	}
 	return hex.EncodeToString(hasher.Sum(nil)), nil
 }
-
-func fileExists(path string) bool {
-	if strings.TrimSpace(path) == "" {
-		return false
-	}
-	_, err := os.Stat(path)
-	return err == nil
-}
-
-func removeFile(path string) error {
-	if strings.TrimSpace(path) == "" {
-		return nil
-	}
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		return err
-	}
-	return nil
-}
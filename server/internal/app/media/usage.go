@@ -0,0 +1,349 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/article"
+	appEvent "github.com/grtsinry43/grtblog-v2/server/internal/app/event"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/moment"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/page"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/thinking"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/content"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	domainthinking "github.com/grtsinry43/grtblog-v2/server/internal/domain/thinking"
+)
+
+const rebuildPageSize = 100
+
+// uploadRefPattern 匹配正文、封面或配图里的 /uploads/<key> 链接，绝对地址与带查询参数的缩放地址同样适用。
+var uploadRefPattern = regexp.MustCompile(`/uploads/([^\s"'()<>\[\]?#,]+)`)
+
+// UsageTracker 维护内容对上传文件的引用关系，内容保存或删除后按 ID 重新同步。
+type UsageTracker struct {
+	files     media.Repository
+	usages    media.UsageRepository
+	contents  content.Repository
+	thinkings domainthinking.ThinkingRepository
+	drivers   []string
+}
+
+// NewUsageTracker 创建引用跟踪器；drivers 为已配置的存储驱动名，用于把链接中的对象键还原为存储路径。
+func NewUsageTracker(files media.Repository, usages media.UsageRepository, contents content.Repository, thinkings domainthinking.ThinkingRepository, drivers ...string) *UsageTracker {
+	return &UsageTracker{
+		files:     files,
+		usages:    usages,
+		contents:  contents,
+		thinkings: thinkings,
+		drivers:   drivers,
+	}
+}
+
+// Sync 重新扫描某个内容引用的上传文件；内容已不存在时清除其引用。
+func (t *UsageTracker) Sync(ctx context.Context, ownerType string, ownerID int64) error {
+	texts, err := t.load(ctx, ownerType, ownerID)
+	if err != nil {
+		return err
+	}
+	return t.replace(ctx, ownerType, ownerID, texts...)
+}
+
+// Rebuild 全量重建引用关系（含头像、友链 logo 与站点信息），用于首次启用或垃圾回收前兜底。
+func (t *UsageTracker) Rebuild(ctx context.Context) error {
+	if err := t.SyncReferences(ctx); err != nil {
+		return err
+	}
+	for page := 1; ; page++ {
+		items, total, err := t.contents.ListArticles(ctx, content.ArticleListOptionsInternal{Page: page, PageSize: rebuildPageSize})
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := t.replace(ctx, media.UsageOwnerArticle, item.ID, articleTexts(item)...); err != nil {
+				return err
+			}
+		}
+		if int64(page*rebuildPageSize) >= total || len(items) == 0 {
+			break
+		}
+	}
+	for page := 1; ; page++ {
+		items, total, err := t.contents.ListMoments(ctx, content.MomentListOptionsInternal{Page: page, PageSize: rebuildPageSize})
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := t.replace(ctx, media.UsageOwnerMoment, item.ID, momentTexts(item)...); err != nil {
+				return err
+			}
+		}
+		if int64(page*rebuildPageSize) >= total || len(items) == 0 {
+			break
+		}
+	}
+	for page := 1; ; page++ {
+		items, total, err := t.contents.ListPages(ctx, content.PageListOptionsInternal{Page: page, PageSize: rebuildPageSize})
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := t.replace(ctx, media.UsageOwnerPage, item.ID, item.Content); err != nil {
+				return err
+			}
+		}
+		if int64(page*rebuildPageSize) >= total || len(items) == 0 {
+			break
+		}
+	}
+	if t.thinkings == nil {
+		return nil
+	}
+	for offset := 0; ; offset += rebuildPageSize {
+		items, total, err := t.thinkings.List(ctx, rebuildPageSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := t.replace(ctx, media.UsageOwnerThinking, item.ID, item.Content); err != nil {
+				return err
+			}
+		}
+		if int64(offset+rebuildPageSize) >= total || len(items) == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+// referenceOwnerTypes 是由 SyncReferences 全量维护的引用类型。
+var referenceOwnerTypes = []string{media.UsageOwnerUser, media.UsageOwnerFriendLink, media.UsageOwnerWebsiteInfo}
+
+// SyncReferences 重新扫描用户头像、友链 logo 与站点信息中的上传链接。
+// 这些字段修改时不发事件，删除文件、列出孤儿文件和垃圾回收前都会先同步一次。
+func (t *UsageTracker) SyncReferences(ctx context.Context) error {
+	sources, err := t.usages.ListReferenceSources(ctx)
+	if err != nil {
+		return err
+	}
+	refs := make(map[string]map[int64][]int64, len(referenceOwnerTypes))
+	for _, ownerType := range referenceOwnerTypes {
+		refs[ownerType] = make(map[int64][]int64)
+	}
+	for _, source := range sources {
+		owner, ok := refs[source.OwnerType]
+		if !ok {
+			continue
+		}
+		ids, err := t.resolve(ctx, source.Text)
+		if err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			owner[source.OwnerID] = append(owner[source.OwnerID], ids...)
+		}
+	}
+	for _, ownerType := range referenceOwnerTypes {
+		if err := t.usages.ReplaceOwnerType(ctx, ownerType, refs[ownerType]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *UsageTracker) load(ctx context.Context, ownerType string, ownerID int64) ([]string, error) {
+	switch ownerType {
+	case media.UsageOwnerArticle:
+		item, err := t.contents.GetArticleByID(ctx, ownerID)
+		if errors.Is(err, content.ErrArticleNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return articleTexts(item), nil
+	case media.UsageOwnerMoment:
+		item, err := t.contents.GetMomentByID(ctx, ownerID)
+		if errors.Is(err, content.ErrMomentNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return momentTexts(item), nil
+	case media.UsageOwnerPage:
+		item, err := t.contents.GetPageByID(ctx, ownerID)
+		if errors.Is(err, content.ErrPageNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []string{item.Content}, nil
+	case media.UsageOwnerThinking:
+		if t.thinkings == nil {
+			return nil, nil
+		}
+		item, err := t.thinkings.FindByID(ctx, ownerID)
+		if errors.Is(err, domainthinking.ErrThinkingNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []string{item.Content}, nil
+	default:
+		return nil, errors.New("unknown usage owner type")
+	}
+}
+
+func (t *UsageTracker) replace(ctx context.Context, ownerType string, ownerID int64, texts ...string) error {
+	ids, err := t.resolve(ctx, texts...)
+	if err != nil {
+		return err
+	}
+	return t.usages.Replace(ctx, ownerType, ownerID, ids)
+}
+
+// resolve 把文本中的 /uploads/ 链接还原为上传文件 ID。
+func (t *UsageTracker) resolve(ctx context.Context, texts ...string) ([]int64, error) {
+	paths := t.storedPaths(ExtractUploadKeys(texts...))
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	files, err := t.files.FindByStoredPaths(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(files))
+	for _, file := range files {
+		ids = append(ids, file.ID)
+	}
+	return ids, nil
+}
+
+func (t *UsageTracker) storedPaths(keys []string) []string {
+	paths := make([]string, 0, len(keys)*len(t.drivers))
+	for _, key := range keys {
+		for _, driver := range t.drivers {
+			paths = append(paths, media.StoredPath(driver, key))
+		}
+	}
+	return paths
+}
+
+func articleTexts(item *content.Article) []string {
+	texts := []string{item.Content}
+	if item.Cover != nil {
+		texts = append(texts, *item.Cover)
+	}
+	if item.LeadIn != nil {
+		texts = append(texts, *item.LeadIn)
+	}
+	return texts
+}
+
+func momentTexts(item *content.Moment) []string {
+	texts := []string{item.Content}
+	if item.Image != nil {
+		texts = append(texts, *item.Image)
+	}
+	return texts
+}
+
+// ExtractUploadKeys 提取文本中 /uploads/ 链接指向的对象键，结果去重排序。
+func ExtractUploadKeys(texts ...string) []string {
+	var keys []string
+	for _, text := range texts {
+		for _, match := range uploadRefPattern.FindAllStringSubmatch(text, -1) {
+			key := match[1]
+			if unescaped, err := url.PathUnescape(key); err == nil {
+				key = unescaped
+			}
+			key = strings.TrimRight(key, ".;")
+			if key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+type handlerFunc func(ctx context.Context, event appEvent.Event) error
+
+func (h handlerFunc) Handle(ctx context.Context, event appEvent.Event) error {
+	return h(ctx, event)
+}
+
+// RegisterUsageSubscribers 在文章、手记、页面与回想保存或删除后同步引用关系，失败只记录日志。
+func RegisterUsageSubscribers(bus appEvent.Bus, tracker *UsageTracker) {
+	if bus == nil || tracker == nil {
+		return
+	}
+	subscribe := func(name string, ownerType string, idOf func(appEvent.Event) (int64, bool)) {
+		bus.Subscribe(name, handlerFunc(func(ctx context.Context, event appEvent.Event) error {
+			id, ok := idOf(event)
+			if !ok {
+				return nil
+			}
+			if err := tracker.Sync(ctx, ownerType, id); err != nil {
+				log.Printf("[media] 同步上传文件引用失败 owner=%s id=%d err=%v", ownerType, id, err)
+			}
+			return nil
+		}))
+	}
+
+	subscribe(article.ArticleCreated{}.Name(), media.UsageOwnerArticle, func(e appEvent.Event) (int64, bool) {
+		ev, ok := e.(article.ArticleCreated)
+		return ev.ID, ok
+	})
+	subscribe(article.ArticleUpdated{}.Name(), media.UsageOwnerArticle, func(e appEvent.Event) (int64, bool) {
+		ev, ok := e.(article.ArticleUpdated)
+		return ev.ID, ok
+	})
+	subscribe(article.ArticleDeleted{}.Name(), media.UsageOwnerArticle, func(e appEvent.Event) (int64, bool) {
+		ev, ok := e.(article.ArticleDeleted)
+		return ev.ID, ok
+	})
+	subscribe(moment.MomentCreated{}.Name(), media.UsageOwnerMoment, func(e appEvent.Event) (int64, bool) {
+		ev, ok := e.(moment.MomentCreated)
+		return ev.ID, ok
+	})
+	subscribe(moment.MomentUpdated{}.Name(), media.UsageOwnerMoment, func(e appEvent.Event) (int64, bool) {
+		ev, ok := e.(moment.MomentUpdated)
+		return ev.ID, ok
+	})
+	subscribe(moment.MomentDeleted{}.Name(), media.UsageOwnerMoment, func(e appEvent.Event) (int64, bool) {
+		ev, ok := e.(moment.MomentDeleted)
+		return ev.ID, ok
+	})
+	subscribe(page.PageCreated{}.Name(), media.UsageOwnerPage, func(e appEvent.Event) (int64, bool) {
+		ev, ok := e.(page.PageCreated)
+		return ev.ID, ok
+	})
+	subscribe(page.PageUpdated{}.Name(), media.UsageOwnerPage, func(e appEvent.Event) (int64, bool) {
+		ev, ok := e.(page.PageUpdated)
+		return ev.ID, ok
+	})
+	subscribe(page.PageDeleted{}.Name(), media.UsageOwnerPage, func(e appEvent.Event) (int64, bool) {
+		ev, ok := e.(page.PageDeleted)
+		return ev.ID, ok
+	})
+	subscribe(thinking.ThinkingCreated{}.Name(), media.UsageOwnerThinking, func(e appEvent.Event) (int64, bool) {
+		ev, ok := e.(thinking.ThinkingCreated)
+		return ev.ID, ok
+	})
+	subscribe(thinking.ThinkingUpdated{}.Name(), media.UsageOwnerThinking, func(e appEvent.Event) (int64, bool) {
+		ev, ok := e.(thinking.ThinkingUpdated)
+		return ev.ID, ok
+	})
+	subscribe(thinking.ThinkingDeleted{}.Name(), media.UsageOwnerThinking, func(e appEvent.Event) (int64, bool) {
+		ev, ok := e.(thinking.ThinkingDeleted)
+		return ev.ID, ok
+	})
+}
@@ -0,0 +1,134 @@
+package media
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/imageproc"
+)
+
+// SVG 处理策略，对应 sys_config upload.svgPolicy。
+const (
+	SVGPolicySanitize = "sanitize"
+	SVGPolicyReject   = "reject"
+)
+
+const sniffLen = 1024
+
+// allowedType 是允许上传的内容类型；exts 的第一个为扩展名不匹配时使用的默认扩展名。
+type allowedType struct {
+	exts    []string
+	picture bool
+}
+
+// allowedTypes 按文件头识别出的 MIME 类型白名单。picture 只接受图片，file 接受全部条目。
+// text/html、可执行文件等不在表中，一律拒绝。
+var allowedTypes = map[string]allowedType{
+	"image/jpeg":                   {exts: []string{".jpg", ".jpeg"}, picture: true},
+	"image/png":                    {exts: []string{".png"}, picture: true},
+	"image/gif":                    {exts: []string{".gif"}, picture: true},
+	"image/webp":                   {exts: []string{".webp"}, picture: true},
+	"image/avif":                   {exts: []string{".avif"}, picture: true},
+	"image/bmp":                    {exts: []string{".bmp"}, picture: true},
+	"image/svg+xml":                {exts: []string{".svg"}, picture: true},
+	"image/heic":                   {exts: []string{".heic", ".heif"}},
+	"application/pdf":              {exts: []string{".pdf"}},
+	"application/zip":              {exts: []string{".zip", ".docx", ".xlsx", ".pptx", ".odt", ".ods", ".odp", ".epub"}},
+	"application/x-gzip":           {exts: []string{".gz", ".tgz"}},
+	"application/x-rar-compressed": {exts: []string{".rar"}},
+	"application/x-7z-compressed":  {exts: []string{".7z"}},
+	"application/x-ole-storage":    {exts: []string{".doc", ".xls", ".ppt"}},
+	"audio/mpeg":                   {exts: []string{".mp3"}},
+	"audio/wave":                   {exts: []string{".wav"}},
+	"audio/flac":                   {exts: []string{".flac"}},
+	"application/ogg":              {exts: []string{".ogg", ".oga", ".opus"}},
+	"video/mp4":                    {exts: []string{".mp4", ".m4v", ".m4a"}},
+	"video/webm":                   {exts: []string{".webm"}},
+	"text/plain":                   {exts: []string{".txt", ".md", ".markdown", ".csv", ".json", ".log", ".yaml", ".yml", ".toml"}},
+}
+
+// sniffMIME 按文件头识别类型，补充 http.DetectContentType 不认识的格式，SVG 单独识别。
+func sniffMIME(head []byte) string {
+	if len(head) >= 12 && string(head[4:8]) == "ftyp" {
+		switch string(head[8:12]) {
+		case "avif", "avis":
+			return "image/avif"
+		case "heic", "heix", "mif1", "msf1":
+			return "image/heic"
+		}
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte("7z\xbc\xaf\x27\x1c")):
+		return "application/x-7z-compressed"
+	case bytes.HasPrefix(head, []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")):
+		return "application/x-ole-storage"
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return "audio/flac"
+	}
+	detected, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	if (detected == "text/xml" || detected == "text/plain") && imageproc.LooksLikeSVG(head) {
+		return "image/svg+xml"
+	}
+	return detected
+}
+
+// validateSource 校验文件头类型是否在白名单内，返回经过处理的来源与应使用的扩展名。
+// SVG 按策略清洗或拒绝；扩展名与实际类型不符时改用该类型的默认扩展名，避免以 .html 等扩展名被当作网页访问。
+func validateSource(src uploadSource, picture bool, svgPolicy string) (uploadSource, string, error) {
+	reader, err := src.open()
+	if err != nil {
+		return src, "", err
+	}
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(reader, head)
+	reader.Close()
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return src, "", err
+	}
+	head = head[:n]
+
+	mimeType := sniffMIME(head)
+	allowed, ok := allowedTypes[mimeType]
+	if !ok || (picture && !allowed.picture) {
+		return src, "", media.ErrUnsupportedFileType
+	}
+
+	if mimeType == "image/svg+xml" {
+		if svgPolicy == SVGPolicyReject {
+			return src, "", media.ErrUnsupportedFileType
+		}
+		reader, err := src.open()
+		if err != nil {
+			return src, "", err
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return src, "", err
+		}
+		clean, err := imageproc.SanitizeSVG(data)
+		if err != nil {
+			return src, "", media.ErrUnsupportedFileType
+		}
+		src = uploadSource{
+			name:        src.name,
+			size:        int64(len(clean)),
+			contentType: mimeType,
+			open: func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(clean)), nil
+			},
+		}
+	}
+
+	ext := strings.ToLower(filepath.Ext(src.name))
+	if !slices.Contains(allowed.exts, ext) {
+		ext = allowed.exts[0]
+	}
+	src.contentType = mimeType
+	return src, ext, nil
+}
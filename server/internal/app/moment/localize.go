@@ -0,0 +1,82 @@
+package moment
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
+)
+
+// WithImageLocalizer 设置外链图片转存器，保存时 LocalizeImages 为 true 才会转存。
+func (s *Service) WithImageLocalizer(localizer contentutil.ImageLocalizer) *Service {
+	s.localizer = localizer
+	return s
+}
+
+// LocalizeMomentImages 转存已有手记中的外链图片并保存，内容无变化时返回 false。
+func (s *Service) LocalizeMomentImages(ctx context.Context, id int64) (bool, error) {
+	existing, err := s.repo.GetMomentByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	body, image, err := s.localizeImages(ctx, existing.AuthorID, existing.Content, existing.Image)
+	if err != nil {
+		return false, err
+	}
+	if body == existing.Content && ptrEqual(image, existing.Image) {
+		return false, nil
+	}
+	topics, err := s.repo.GetTopicsByMomentID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	topicIDs := make([]int64, len(topics))
+	for i, topic := range topics {
+		topicIDs[i] = topic.ID
+	}
+	_, err = s.UpdateMoment(ctx, UpdateMomentCmd{
+		ID:          existing.ID,
+		Title:       existing.Title,
+		Summary:     existing.Summary,
+		Content:     body,
+		Image:       image,
+		ColumnID:    existing.ColumnID,
+		TopicIDs:    topicIDs,
+		ShortURL:    existing.ShortURL,
+		IsPublished: existing.IsPublished,
+		IsTop:       existing.IsTop,
+		IsHot:       existing.IsHot,
+		IsOriginal:  existing.IsOriginal,
+	})
+	return err == nil, err
+}
+
+// localizeImages 转存正文与配图中的外链图片，配图为逗号分隔的地址列表；未设置转存器时原样返回。
+func (s *Service) localizeImages(ctx context.Context, authorID int64, body string, image *string) (string, *string, error) {
+	if s.localizer == nil {
+		return body, image, nil
+	}
+	body, err := s.localizer.LocalizeMarkdown(ctx, authorID, body)
+	if err != nil {
+		return "", nil, err
+	}
+	if image != nil {
+		parts := strings.Split(*image, ",")
+		for i, part := range parts {
+			parts[i], err = s.localizer.LocalizeURL(ctx, authorID, strings.TrimSpace(part))
+			if err != nil {
+				return "", nil, err
+			}
+		}
+		joined := strings.Join(parts, ",")
+		image = &joined
+	}
+	return body, image, nil
+}
+
+func ptrEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -12,8 +12,9 @@ import (
 )
 
 type Service struct {
-	repo   content.Repository
-	events appEvent.Bus
+	repo      content.Repository
+	events    appEvent.Bus
+	localizer contentutil.ImageLocalizer
 }
 
 func NewService(repo content.Repository, events appEvent.Bus) *Service {
@@ -46,6 +47,13 @@ func (s *Service) CreateMoment(ctx context.Context, authorID int64, cmd CreateMo
 		return nil, err
 	}
 
+	if cmd.LocalizeImages {
+		cmd.Content, cmd.Image, err = s.localizeImages(ctx, authorID, cmd.Content, cmd.Image)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	createdAt := time.Now()
 	if cmd.CreatedAt != nil {
 		createdAt = *cmd.CreatedAt
@@ -120,6 +128,12 @@ func (s *Service) UpdateMoment(ctx context.Context, cmd UpdateMomentCmd) (*conte
 	if err := s.ensureTagsExist(ctx, cmd.TopicIDs); err != nil {
 		return nil, err
 	}
+	if cmd.LocalizeImages {
+		cmd.Content, cmd.Image, err = s.localizeImages(ctx, existing.AuthorID, cmd.Content, cmd.Image)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	toc := contentutil.GenerateTOC(cmd.Content)
 	summary := contentutil.BuildSummary(cmd.Summary, cmd.Content)
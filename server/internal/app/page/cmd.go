@@ -4,22 +4,24 @@ import "time"
 
 // CreatePageCmd 创建页面命令。
 type CreatePageCmd struct {
-	Title       string
-	Description *string
-	Content     string
-	ShortURL    *string
-	IsEnabled   bool
-	IsBuiltin   bool
-	CreatedAt   *time.Time
+	Title          string
+	Description    *string
+	Content        string
+	ShortURL       *string
+	IsEnabled      bool
+	IsBuiltin      bool
+	LocalizeImages bool // 保存前把外链图片转存到本站
+	CreatedAt      *time.Time
 }
 
 // UpdatePageCmd 更新页面命令。
 type UpdatePageCmd struct {
-	ID          int64
-	Title       string
-	Description *string
-	Content     string
-	ShortURL    string
-	IsEnabled   bool
-	IsBuiltin   bool
+	ID             int64
+	Title          string
+	Description    *string
+	Content        string
+	ShortURL       string
+	IsEnabled      bool
+	IsBuiltin      bool
+	LocalizeImages bool
 }
@@ -0,0 +1,46 @@
+package page
+
+import (
+	"context"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
+)
+
+// WithImageLocalizer 设置外链图片转存器，保存时 LocalizeImages 为 true 才会转存。
+func (s *Service) WithImageLocalizer(localizer contentutil.ImageLocalizer) *Service {
+	s.localizer = localizer
+	return s
+}
+
+// LocalizePageImages 转存已有页面中的外链图片并保存，内容无变化时返回 false。
+func (s *Service) LocalizePageImages(ctx context.Context, id int64) (bool, error) {
+	existing, err := s.repo.GetPageByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	body, err := s.localizeImages(ctx, existing.Content)
+	if err != nil {
+		return false, err
+	}
+	if body == existing.Content {
+		return false, nil
+	}
+	_, err = s.UpdatePage(ctx, UpdatePageCmd{
+		ID:          existing.ID,
+		Title:       existing.Title,
+		Description: existing.Description,
+		Content:     body,
+		ShortURL:    existing.ShortURL,
+		IsEnabled:   existing.IsEnabled,
+		IsBuiltin:   existing.IsBuiltin,
+	})
+	return err == nil, err
+}
+
+// localizeImages 转存正文中的外链图片；页面没有作者，转存的文件不归属任何用户。
+func (s *Service) localizeImages(ctx context.Context, body string) (string, error) {
+	if s.localizer == nil {
+		return body, nil
+	}
+	return s.localizer.LocalizeMarkdown(ctx, 0, body)
+}
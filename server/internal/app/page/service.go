@@ -12,8 +12,9 @@ import (
 )
 
 type Service struct {
-	repo   content.Repository
-	events appEvent.Bus
+	repo      content.Repository
+	events    appEvent.Bus
+	localizer contentutil.ImageLocalizer
 }
 
 func NewService(repo content.Repository, events appEvent.Bus) *Service {
@@ -41,6 +42,12 @@ func (s *Service) CreatePage(ctx context.Context, cmd CreatePageCmd) (*content.P
 	if cmd.CreatedAt != nil {
 		createdAt = *cmd.CreatedAt
 	}
+	if cmd.LocalizeImages {
+		cmd.Content, err = s.localizeImages(ctx, cmd.Content)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	description := trimPtr(cmd.Description)
 	toc := contentutil.GenerateTOC(cmd.Content)
@@ -81,6 +88,13 @@ func (s *Service) UpdatePage(ctx context.Context, cmd UpdatePageCmd) (*content.P
 		return nil, err
 	}
 
+	if cmd.LocalizeImages {
+		cmd.Content, err = s.localizeImages(ctx, cmd.Content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	description := trimPtr(cmd.Description)
 	toc := contentutil.GenerateTOC(cmd.Content)
 
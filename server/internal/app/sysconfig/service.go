@@ -128,6 +128,45 @@ func (s *Service) UploadMaxSizeBytes(ctx context.Context) int {
 	return sizeMB * 1024 * 1024
 }
 
+// UploadUserQuotaBytes 返回普通用户的存储配额（字节），读取 upload.userQuotaMB，默认 1024MB，0 表示不限制。
+func (s *Service) UploadUserQuotaBytes(ctx context.Context) int64 {
+	sizeMB := int64(1024)
+	cfg, err := s.repo.GetByKey(ctx, "upload.userQuotaMB")
+	if err == nil {
+		if parsed, parseErr := strconv.ParseInt(strings.TrimSpace(cfg.Value), 10, 64); parseErr == nil && parsed >= 0 {
+			sizeMB = parsed
+		}
+	}
+	return sizeMB * 1024 * 1024
+}
+
+// UploadRatePerMinute 返回每个用户每分钟允许的上传次数，读取 upload.ratePerMinute，默认 20。
+func (s *Service) UploadRatePerMinute(ctx context.Context) int {
+	rate := 20
+	cfg, err := s.repo.GetByKey(ctx, "upload.ratePerMinute")
+	if err == nil {
+		if parsed, parseErr := strconv.Atoi(strings.TrimSpace(cfg.Value)); parseErr == nil && parsed > 0 {
+			rate = parsed
+		}
+	}
+	return rate
+}
+
+// UploadSVGPolicy 返回 SVG 上传策略：sanitize（清洗脚本后保存，默认）或 reject（拒绝）。
+func (s *Service) UploadSVGPolicy(ctx context.Context) string {
+	cfg, err := s.repo.GetByKey(ctx, "upload.svgPolicy")
+	if err == nil && strings.TrimSpace(cfg.Value) == "reject" {
+		return "reject"
+	}
+	return "sanitize"
+}
+
+// AdminTwoFactorRequired 返回是否强制管理员开启两步验证（key：auth.adminTwoFactor，值为 required 时生效）。
+func (s *Service) AdminTwoFactorRequired(ctx context.Context) bool {
+	cfg, err := s.repo.GetByKey(ctx, "auth.adminTwoFactor")
+	return err == nil && strings.TrimSpace(cfg.Value) == "required"
+}
+
 type WebhookSettings struct {
 	Timeout   time.Duration
 	Workers   int
@@ -155,26 +194,84 @@ func (s *Service) WebhookSettings(ctx context.Context) (WebhookSettings, error)
 		QueueSize: defaultQ,
 	}
 
-	applyInt := func(key string, apply func(int) error) error {
-		cfg, err := s.repo.GetByKey(ctx, key)
-		if err != nil {
-			if err == domainconfig.ErrSysConfigNotFound {
-				return nil
-			}
-			return fmt.Errorf("load %s: %w", key, err)
+	if err := s.applyInt(ctx, timeoutKey, func(val int) error {
+		if val > 0 {
+			settings.Timeout = time.Duration(val) * time.Second
 		}
-		val := strings.TrimSpace(cfg.Value)
-		if val == "" {
-			return nil
+		return nil
+	}); err != nil {
+		return settings, err
+	}
+	if err := s.applyInt(ctx, workersKey, func(val int) error {
+		if val > 0 {
+			settings.Workers = val
 		}
-		parsed, err := strconv.Atoi(val)
-		if err != nil {
-			return fmt.Errorf("parse %s: %w", key, err)
+		return nil
+	}); err != nil {
+		return settings, err
+	}
+	if err := s.applyInt(ctx, queueKey, func(val int) error {
+		if val > 0 {
+			settings.QueueSize = val
 		}
-		return apply(parsed)
+		return nil
+	}); err != nil {
+		return settings, err
 	}
 
-	if err := applyInt(timeoutKey, func(val int) error {
+	return settings, nil
+}
+
+type FriendLinkSyncSettings struct {
+	DefaultInterval time.Duration
+	MaxItems        int
+	Timeout         time.Duration
+	WebSub          bool
+}
+
+// FriendLinkSyncSettings 返回友链订阅同步配置。
+// 约定 key：
+// - friendlink.syncIntervalMinutes: 未单独设置间隔的友链默认同步间隔（分钟）
+// - friendlink.syncMaxItems: 每个友链保留的最新文章数
+// - friendlink.syncTimeoutSeconds: 单次抓取超时秒数
+// - friendlink.syncWebSub: 订阅源声明了 WebSub Hub 时改为订阅推送
+func (s *Service) FriendLinkSyncSettings(ctx context.Context) (FriendLinkSyncSettings, error) {
+	const (
+		intervalKey     = "friendlink.syncIntervalMinutes"
+		maxItemsKey     = "friendlink.syncMaxItems"
+		timeoutKey      = "friendlink.syncTimeoutSeconds"
+		websubKey       = "friendlink.syncWebSub"
+		defaultInterval = 60
+		defaultMaxItems = 20
+		defaultTimeout  = 15
+		minInterval     = 5
+		maxItemsLimit   = 100
+	)
+
+	settings := FriendLinkSyncSettings{
+		DefaultInterval: time.Duration(defaultInterval) * time.Minute,
+		MaxItems:        defaultMaxItems,
+		Timeout:         time.Duration(defaultTimeout) * time.Second,
+		WebSub:          true,
+	}
+
+	if err := s.applyInt(ctx, intervalKey, func(val int) error {
+		if val >= minInterval {
+			settings.DefaultInterval = time.Duration(val) * time.Minute
+		}
+		return nil
+	}); err != nil {
+		return settings, err
+	}
+	if err := s.applyInt(ctx, maxItemsKey, func(val int) error {
+		if val > 0 && val <= maxItemsLimit {
+			settings.MaxItems = val
+		}
+		return nil
+	}); err != nil {
+		return settings, err
+	}
+	if err := s.applyInt(ctx, timeoutKey, func(val int) error {
 		if val > 0 {
 			settings.Timeout = time.Duration(val) * time.Second
 		}
@@ -182,26 +279,122 @@ func (s *Service) WebhookSettings(ctx context.Context) (WebhookSettings, error)
 	}); err != nil {
 		return settings, err
 	}
-	if err := applyInt(workersKey, func(val int) error {
+	if err := s.applyString(ctx, websubKey, func(val string) error {
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("parse bool: %w", err)
+		}
+		settings.WebSub = b
+		return nil
+	}); err != nil {
+		return settings, err
+	}
+
+	return settings, nil
+}
+
+type FriendLinkHealthSettings struct {
+	Interval         time.Duration
+	Timeout          time.Duration
+	FailureThreshold int
+	AutoDeactivate   bool
+	BacklinkURL      string
+}
+
+// FriendLinkHealthSettings 返回友链健康检查配置。
+// 约定 key：
+// - friendlink.healthIntervalHours: 检查间隔（小时）
+// - friendlink.healthTimeoutSeconds: 单次请求超时秒数
+// - friendlink.healthFailureThreshold: 连续失败多少次后标记为失效
+// - friendlink.healthAutoDeactivate: 失效后是否自动停用友链
+// - friendlink.backlinkURL: 反链检测使用的本站地址，为空时由调用方回退
+func (s *Service) FriendLinkHealthSettings(ctx context.Context) (FriendLinkHealthSettings, error) {
+	const (
+		intervalKey      = "friendlink.healthIntervalHours"
+		timeoutKey       = "friendlink.healthTimeoutSeconds"
+		thresholdKey     = "friendlink.healthFailureThreshold"
+		autoKey          = "friendlink.healthAutoDeactivate"
+		backlinkKey      = "friendlink.backlinkURL"
+		defaultInterval  = 24
+		defaultTimeout   = 10
+		defaultThreshold = 3
+	)
+
+	settings := FriendLinkHealthSettings{
+		Interval:         time.Duration(defaultInterval) * time.Hour,
+		Timeout:          time.Duration(defaultTimeout) * time.Second,
+		FailureThreshold: defaultThreshold,
+	}
+
+	if err := s.applyInt(ctx, intervalKey, func(val int) error {
 		if val > 0 {
-			settings.Workers = val
+			settings.Interval = time.Duration(val) * time.Hour
 		}
 		return nil
 	}); err != nil {
 		return settings, err
 	}
-	if err := applyInt(queueKey, func(val int) error {
+	if err := s.applyInt(ctx, timeoutKey, func(val int) error {
 		if val > 0 {
-			settings.QueueSize = val
+			settings.Timeout = time.Duration(val) * time.Second
 		}
 		return nil
 	}); err != nil {
 		return settings, err
 	}
+	if err := s.applyInt(ctx, thresholdKey, func(val int) error {
+		if val > 0 {
+			settings.FailureThreshold = val
+		}
+		return nil
+	}); err != nil {
+		return settings, err
+	}
+	if err := s.applyString(ctx, autoKey, func(val string) error {
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("parse bool: %w", err)
+		}
+		settings.AutoDeactivate = b
+		return nil
+	}); err != nil {
+		return settings, err
+	}
+	_ = s.applyString(ctx, backlinkKey, func(val string) error {
+		settings.BacklinkURL = val
+		return nil
+	})
 
 	return settings, nil
 }
 
+// applyString 读取字符串配置，未配置或为空时跳过。
+func (s *Service) applyString(ctx context.Context, key string, apply func(string) error) error {
+	cfg, err := s.repo.GetByKey(ctx, key)
+	if err != nil {
+		if err == domainconfig.ErrSysConfigNotFound {
+			return nil
+		}
+		return fmt.Errorf("load %s: %w", key, err)
+	}
+	val := strings.TrimSpace(cfg.Value)
+	if val == "" {
+		return nil
+	}
+	return apply(val)
+}
+
+// applyInt 读取整数配置，未配置或为空时跳过。
+func (s *Service) applyInt(ctx context.Context, key string, apply func(int) error) error {
+	return s.applyString(ctx, key, func(val string) error {
+		parsed, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", key, err)
+		}
+		return apply(parsed)
+	})
+}
+
 type UpdateItem struct {
 	Key          string
 	Value        *json.RawMessage
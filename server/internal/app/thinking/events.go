@@ -1 +1,36 @@
 package thinking
+
+import "time"
+
+type ThinkingCreated struct {
+	ID       int64
+	AuthorID int64
+	At       time.Time
+}
+
+func (e ThinkingCreated) Name() string { return "thinking.created" }
+func (e ThinkingCreated) OccurredAt() time.Time {
+	return e.At
+}
+
+type ThinkingUpdated struct {
+	ID       int64
+	AuthorID int64
+	At       time.Time
+}
+
+func (e ThinkingUpdated) Name() string { return "thinking.updated" }
+func (e ThinkingUpdated) OccurredAt() time.Time {
+	return e.At
+}
+
+type ThinkingDeleted struct {
+	ID       int64
+	AuthorID int64
+	At       time.Time
+}
+
+func (e ThinkingDeleted) Name() string { return "thinking.deleted" }
+func (e ThinkingDeleted) OccurredAt() time.Time {
+	return e.At
+}
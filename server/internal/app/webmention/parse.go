@@ -0,0 +1,175 @@
+package webmention
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
+)
+
+var (
+	htmlCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	linkTagPattern     = regexp.MustCompile(`(?is)<(?:link|a)\b[^>]*>`)
+	urlAttrTagPattern  = regexp.MustCompile(`(?is)<[a-z][a-z0-9]*\b[^>]*\b(?:href|src)\s*=[^>]*>`)
+	attrPattern        = regexp.MustCompile(`(?is)([a-z][a-z0-9_:-]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	titlePattern       = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	metaTagPattern     = regexp.MustCompile(`(?is)<meta\b[^>]*>`)
+	linkHeaderPattern  = regexp.MustCompile(`<([^>]*)>\s*((?:;\s*[^;,]+)*)`)
+	linkRelPattern     = regexp.MustCompile(`(?i);\s*rel\s*=\s*(?:"([^"]*)"|([^\s;,]+))`)
+)
+
+// pageMeta 从来源页面提取的展示信息。
+type pageMeta struct {
+	Title   string
+	Author  string
+	Snippet string
+}
+
+func parseAttrs(tag string) map[string]string {
+	attrs := make(map[string]string)
+	for _, match := range attrPattern.FindAllStringSubmatch(tag, -1) {
+		name := strings.ToLower(match[1])
+		if _, ok := attrs[name]; ok {
+			continue
+		}
+		attrs[name] = html.UnescapeString(match[2] + match[3] + match[4])
+	}
+	return attrs
+}
+
+func hasRel(rel string, want string) bool {
+	for _, token := range strings.Fields(strings.ToLower(rel)) {
+		if token == want {
+			return true
+		}
+	}
+	return false
+}
+
+// discoverEndpoint 按规范依次检查 HTTP Link 头、HTML 中首个 rel=webmention 的 <link>/<a>。
+// 空 href 表示端点就是页面本身；相对地址基于最终（跟随重定向后的）页面地址解析。
+func discoverEndpoint(pageURL *url.URL, linkHeaders []string, body string) string {
+	for _, header := range linkHeaders {
+		for _, match := range linkHeaderPattern.FindAllStringSubmatch(header, -1) {
+			for _, rel := range linkRelPattern.FindAllStringSubmatch(match[2], -1) {
+				if hasRel(rel[1]+rel[2], "webmention") {
+					return resolveURL(pageURL, match[1])
+				}
+			}
+		}
+	}
+	body = htmlCommentPattern.ReplaceAllString(body, "")
+	for _, tag := range linkTagPattern.FindAllString(body, -1) {
+		attrs := parseAttrs(tag)
+		href, ok := attrs["href"]
+		if !ok || !hasRel(attrs["rel"], "webmention") {
+			continue
+		}
+		return resolveURL(pageURL, href)
+	}
+	return ""
+}
+
+// linksTo 判断来源页面是否包含指向目标的链接（href 或 src），纯文本内容则直接查找目标地址。
+func linksTo(sourceURL *url.URL, body string, contentType string, target string) bool {
+	if !strings.Contains(strings.ToLower(contentType), "html") {
+		return strings.Contains(body, target)
+	}
+	want := normalizeURL(target)
+	body = htmlCommentPattern.ReplaceAllString(body, "")
+	for _, tag := range urlAttrTagPattern.FindAllString(body, -1) {
+		attrs := parseAttrs(tag)
+		for _, key := range []string{"href", "src"} {
+			if value, ok := attrs[key]; ok && normalizeURL(resolveURL(sourceURL, value)) == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// extractMeta 取页面标题、作者与链接附近的一段正文作为摘要。
+func extractMeta(body string, target string, window int) pageMeta {
+	var meta pageMeta
+	if match := titlePattern.FindStringSubmatch(body); match != nil {
+		meta.Title = strings.TrimSpace(html.UnescapeString(match[1]))
+	}
+	for _, tag := range metaTagPattern.FindAllString(body, -1) {
+		attrs := parseAttrs(tag)
+		key := strings.ToLower(attrs["name"] + attrs["property"])
+		value := strings.TrimSpace(attrs["content"])
+		if value == "" {
+			continue
+		}
+		switch key {
+		case "og:title":
+			meta.Title = value
+		case "author", "article:author":
+			if meta.Author == "" {
+				meta.Author = value
+			}
+		case "og:site_name":
+			if meta.Author == "" {
+				meta.Author = value
+			}
+		}
+	}
+
+	cleaned := htmlCommentPattern.ReplaceAllString(body, "")
+	idx := strings.Index(cleaned, target)
+	if idx < 0 {
+		return meta
+	}
+	start := max(idx-window*4, 0)
+	end := min(idx+len(target)+window*4, len(cleaned))
+	// 前后裁到标签边界，避免把半截标签当成正文。
+	if cut := strings.LastIndex(cleaned[:idx], "<p"); cut >= start {
+		start = cut
+	} else if cut := strings.Index(cleaned[start:idx], ">"); cut >= 0 {
+		start += cut + 1
+	}
+	if cut := strings.Index(cleaned[idx:end], "</p>"); cut >= 0 {
+		end = idx + cut
+	} else if cut := strings.LastIndex(cleaned[idx:end], "<"); cut > 0 {
+		end = idx + cut
+	}
+	text := strings.Join(strings.Fields(contentutil.StripHTML(cleaned[start:end])), " ")
+	if runes := []rune(text); len(runes) > window*2 {
+		text = string(runes[:window*2]) + "…"
+	}
+	meta.Snippet = text
+	return meta
+}
+
+func resolveURL(base *url.URL, raw string) string {
+	raw = strings.TrimSpace(raw)
+	if base == nil {
+		return raw
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// normalizeURL 忽略片段、大小写主机与末尾斜杠的差异。
+func normalizeURL(raw string) string {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return raw
+	}
+	parsed.Fragment = ""
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+	result := parsed.String()
+	if len(parsed.Path) > 1 {
+		result = strings.TrimSuffix(result, "/")
+	}
+	return result
+}
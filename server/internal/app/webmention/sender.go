@@ -0,0 +1,182 @@
+package webmention
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+)
+
+const (
+	SendStatusSent       = "sent"
+	SendStatusNoEndpoint = "no_endpoint"
+	SendStatusFailed     = "failed"
+)
+
+// SendForArticle 通知文章中每个外链的 Webmention 端点：
+// 内容未变且已发送成功的链接跳过；本次已从正文移除的链接也会再通知一次，便于对方撤下提及。
+func (s *Service) SendForArticle(ctx context.Context, articleID int64) error {
+	site, err := s.settings(ctx)
+	if err != nil {
+		return err
+	}
+	article, err := s.contentRepo.GetArticleByID(ctx, articleID)
+	if err != nil {
+		return err
+	}
+	if !article.IsPublished {
+		return nil
+	}
+	source := site.baseURL.String() + "/posts/" + article.ShortURL
+
+	previous, err := s.sendRepo.ListByArticle(ctx, article.ID)
+	if err != nil {
+		return err
+	}
+	previousByTarget := make(map[string]domainfed.WebmentionSend, len(previous))
+	for _, item := range previous {
+		previousByTarget[item.Target] = item
+	}
+
+	sent, skipped := 0, 0
+	current := make(map[string]struct{})
+	for _, target := range outboundLinks(article.Content, site.baseURL.Host) {
+		current[target] = struct{}{}
+		if prev, ok := previousByTarget[target]; ok && prev.Status == SendStatusSent &&
+			prev.ContentHash != nil && *prev.ContentHash == article.ContentHash {
+			skipped++
+			continue
+		}
+		record := s.send(ctx, source, target)
+		record.ArticleID = article.ID
+		hash := article.ContentHash
+		record.ContentHash = &hash
+		if err := s.sendRepo.Upsert(ctx, record); err != nil {
+			log.Printf("[webmention] 保存发送状态失败 article=%d target=%s err=%v", article.ID, target, err)
+		}
+		if record.Status == SendStatusSent {
+			sent++
+		}
+	}
+	for _, prev := range previous {
+		if _, ok := current[prev.Target]; ok {
+			continue
+		}
+		if prev.Status == SendStatusSent {
+			_ = s.send(ctx, source, prev.Target)
+		}
+		if err := s.sendRepo.Delete(ctx, prev.ID); err != nil {
+			log.Printf("[webmention] 清理发送记录失败 id=%d err=%v", prev.ID, err)
+		}
+	}
+	log.Printf("[webmention] 文章外链通知完成 article=%d links=%d sent=%d skipped=%d", article.ID, len(current), sent, skipped)
+	return nil
+}
+
+func (s *Service) ListSends(ctx context.Context, articleID int64) ([]domainfed.WebmentionSend, error) {
+	return s.sendRepo.ListByArticle(ctx, articleID)
+}
+
+// send 发现端点并提交 source/target，结果写入返回的记录。
+func (s *Service) send(ctx context.Context, source string, target string) *domainfed.WebmentionSend {
+	record := &domainfed.WebmentionSend{Source: source, Target: target}
+	endpoint, err := s.discover(ctx, target)
+	if err != nil {
+		record.Status = SendStatusFailed
+		record.Error = optionalString(err.Error())
+		return record
+	}
+	if endpoint == "" {
+		record.Status = SendStatusNoEndpoint
+		return record
+	}
+	record.Endpoint = &endpoint
+
+	form := url.Values{"source": {source}, "target": {target}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		record.Status = SendStatusFailed
+		record.Error = optionalString(err.Error())
+		return record
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", webmentionAgent)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		record.Status = SendStatusFailed
+		record.Error = optionalString(err.Error())
+		return record
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	code := resp.StatusCode
+	record.StatusCode = &code
+	if code >= http.StatusOK && code < http.StatusMultipleChoices {
+		now := time.Now()
+		record.Status = SendStatusSent
+		record.SentAt = &now
+		return record
+	}
+	record.Status = SendStatusFailed
+	record.Error = optionalString(fmt.Sprintf("endpoint returned %s", resp.Status))
+	return record
+}
+
+// discover 请求目标页面查找 Webmention 端点，未声明端点时返回空字符串。
+func (s *Service) discover(ctx context.Context, target string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", webmentionAgent)
+	req.Header.Set("Accept", "text/html, */*;q=0.1")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("target returned %s", resp.Status)
+	}
+	pageURL := req.URL
+	if resp.Request != nil && resp.Request.URL != nil {
+		pageURL = resp.Request.URL
+	}
+	var body string
+	if strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "html") {
+		raw, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceBytes))
+		if err != nil {
+			return "", err
+		}
+		body = string(raw)
+	}
+	endpoint := discoverEndpoint(pageURL, resp.Header.Values("Link"), body)
+	if endpoint == "" {
+		return "", nil
+	}
+	if _, err := parseHTTPURL(endpoint); err != nil {
+		return "", err
+	}
+	return endpoint, nil
+}
+
+// outboundLinks 过滤出指向站外的 http(s) 链接。
+func outboundLinks(markdown string, ownHost string) []string {
+	var links []string
+	for _, link := range contentutil.ExtractLinks(markdown) {
+		parsed, err := parseHTTPURL(link)
+		if err != nil || strings.EqualFold(parsed.Host, ownHost) {
+			continue
+		}
+		links = append(links, link)
+	}
+	return links
+}
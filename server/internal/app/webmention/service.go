@@ -0,0 +1,366 @@
+package webmention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/comment"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/content"
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusVerified = "verified"
+	StatusRejected = "rejected"
+	StatusDeleted  = "deleted"
+
+	TargetTypeArticle = "article"
+	TargetTypeMoment  = "moment"
+
+	maxSourceBytes   = 1 << 20
+	snippetRunes     = 200
+	fetchTimeout     = 10 * time.Second
+	webmentionAgent  = "grtblog-webmention/2.0"
+	defaultQueueSize = 100
+)
+
+// Service 实现 Webmention 的接收（异步校验来源）与发送（发现外链端点并通知）。
+type Service struct {
+	cfgSvc      *federationconfig.Service
+	contentRepo content.Repository
+	commentRepo comment.CommentRepository
+	mentionRepo domainfed.WebmentionRepository
+	sendRepo    domainfed.WebmentionSendRepository
+	policy      fedinfra.InstancePolicy
+	client      *http.Client
+	queue       chan int64
+	inflight    sync.Map
+}
+
+// NewService 创建服务并启动校验 worker；policy 复用联合实例的域名黑名单，可为 nil。
+func NewService(
+	cfgSvc *federationconfig.Service,
+	contentRepo content.Repository,
+	commentRepo comment.CommentRepository,
+	mentionRepo domainfed.WebmentionRepository,
+	sendRepo domainfed.WebmentionSendRepository,
+	policy fedinfra.InstancePolicy,
+	workers int,
+) *Service {
+	if workers <= 0 {
+		workers = 1
+	}
+	svc := &Service{
+		cfgSvc:      cfgSvc,
+		contentRepo: contentRepo,
+		commentRepo: commentRepo,
+		mentionRepo: mentionRepo,
+		sendRepo:    sendRepo,
+		policy:      policy,
+		client:      fedinfra.NewGuardedHTTPClient(fetchTimeout),
+		queue:       make(chan int64, defaultQueueSize),
+	}
+	for i := 0; i < workers; i++ {
+		go svc.worker()
+	}
+	return svc
+}
+
+func (s *Service) worker() {
+	for id := range s.queue {
+		if _, err := s.Verify(context.Background(), id); err != nil {
+			log.Printf("[webmention] 校验失败 id=%d err=%v", id, err)
+		}
+	}
+}
+
+type siteSettings struct {
+	baseURL  *url.URL
+	comments bool
+}
+
+func (s *Service) settings(ctx context.Context) (siteSettings, error) {
+	settings, err := s.cfgSvc.Settings(ctx)
+	if err != nil {
+		return siteSettings{}, err
+	}
+	if !settings.Webmention || strings.TrimSpace(settings.InstanceURL) == "" {
+		return siteSettings{}, domainfed.ErrWebmentionDisabled
+	}
+	base, err := url.Parse(strings.TrimRight(strings.TrimSpace(settings.InstanceURL), "/"))
+	if err != nil || base.Host == "" {
+		return siteSettings{}, domainfed.ErrWebmentionDisabled
+	}
+	return siteSettings{baseURL: base, comments: settings.WMComments}, nil
+}
+
+// Receive 校验参数后记录为 pending 并排队异步校验；同一 source/target 重复提交视为更新。
+func (s *Service) Receive(ctx context.Context, source string, target string) (*domainfed.Webmention, error) {
+	site, err := s.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	source = strings.TrimSpace(source)
+	target = strings.TrimSpace(target)
+	sourceURL, err := parseHTTPURL(source)
+	if err != nil {
+		return nil, domainfed.ErrInvalidWebmention
+	}
+	targetURL, err := parseHTTPURL(target)
+	if err != nil || normalizeURL(source) == normalizeURL(target) {
+		return nil, domainfed.ErrInvalidWebmention
+	}
+	if !strings.EqualFold(targetURL.Host, site.baseURL.Host) {
+		return nil, domainfed.ErrInvalidWebmention
+	}
+	if s.policy != nil {
+		if err := s.policy.CheckInstance(ctx, sourceURL.Scheme+"://"+sourceURL.Host); err != nil {
+			return nil, err
+		}
+	}
+	targetType, targetID, err := s.resolveTarget(ctx, targetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	mention := &domainfed.Webmention{
+		Source:     source,
+		Target:     target,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Status:     StatusPending,
+	}
+	if err := s.mentionRepo.Upsert(ctx, mention); err != nil {
+		return nil, err
+	}
+	s.Enqueue(mention.ID)
+	return mention, nil
+}
+
+// Enqueue 投递到校验队列，队列已满时保持 pending，可在后台手动重新校验。
+func (s *Service) Enqueue(id int64) {
+	select {
+	case s.queue <- id:
+	default:
+		log.Printf("[webmention] 校验队列已满，保持 pending id=%d", id)
+	}
+}
+
+// resolveTarget 把本站地址映射到文章/手记，只接受 /posts/{short} 与 /moments/{short}。
+func (s *Service) resolveTarget(ctx context.Context, target *url.URL) (string, int64, error) {
+	path := strings.Trim(target.Path, "/")
+	kind, short, ok := strings.Cut(path, "/")
+	if !ok || short == "" || strings.Contains(short, "/") {
+		return "", 0, domainfed.ErrInvalidWebmention
+	}
+	switch kind {
+	case "posts":
+		item, err := s.contentRepo.GetArticleByShortURL(ctx, short)
+		if err != nil {
+			if errors.Is(err, content.ErrArticleNotFound) {
+				return "", 0, domainfed.ErrInvalidWebmention
+			}
+			return "", 0, err
+		}
+		if !item.IsPublished {
+			return "", 0, domainfed.ErrInvalidWebmention
+		}
+		return TargetTypeArticle, item.ID, nil
+	case "moments":
+		item, err := s.contentRepo.GetMomentByShortURL(ctx, short)
+		if err != nil {
+			if errors.Is(err, content.ErrMomentNotFound) {
+				return "", 0, domainfed.ErrInvalidWebmention
+			}
+			return "", 0, err
+		}
+		if !item.IsPublished {
+			return "", 0, domainfed.ErrInvalidWebmention
+		}
+		return TargetTypeMoment, item.ID, nil
+	default:
+		return "", 0, domainfed.ErrInvalidWebmention
+	}
+}
+
+// Verify 拉取来源页面确认其确实链接了目标：
+// 410 视为删除，其余失败或链接缺失视为拒绝；之前已转为评论的提及在失效时删除评论。
+func (s *Service) Verify(ctx context.Context, id int64) (*domainfed.Webmention, error) {
+	mention, err := s.mentionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	site, err := s.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status, meta, verifyErr := s.fetchSource(ctx, mention.Source, mention.Target)
+	now := time.Now()
+	mention.Status = status
+	mention.Error = nil
+	if verifyErr != nil {
+		msg := verifyErr.Error()
+		mention.Error = &msg
+	}
+	if status == StatusVerified {
+		mention.VerifiedAt = &now
+		mention.Title = optionalString(meta.Title)
+		mention.AuthorName = optionalString(meta.Author)
+		mention.Content = optionalString(meta.Snippet)
+		if site.comments && mention.CommentID == nil {
+			if commentID, err := s.createComment(ctx, mention); err != nil {
+				log.Printf("[webmention] 写入评论失败 id=%d err=%v", mention.ID, err)
+			} else if commentID != 0 {
+				mention.CommentID = &commentID
+			}
+		}
+	} else if mention.CommentID != nil {
+		if err := s.commentRepo.Delete(ctx, *mention.CommentID); err != nil {
+			log.Printf("[webmention] 删除失效评论失败 id=%d comment=%d err=%v", mention.ID, *mention.CommentID, err)
+		} else {
+			mention.CommentID = nil
+		}
+	}
+	if err := s.mentionRepo.Update(ctx, mention); err != nil {
+		return nil, err
+	}
+	log.Printf("[webmention] 校验完成 id=%d source=%s status=%s", mention.ID, mention.Source, mention.Status)
+	return mention, nil
+}
+
+func (s *Service) fetchSource(ctx context.Context, source string, target string) (string, pageMeta, error) {
+	sourceURL, err := parseHTTPURL(source)
+	if err != nil {
+		return StatusRejected, pageMeta{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return StatusRejected, pageMeta{}, err
+	}
+	req.Header.Set("User-Agent", webmentionAgent)
+	req.Header.Set("Accept", "text/html, text/plain;q=0.9, */*;q=0.1")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return StatusRejected, pageMeta{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusGone {
+		return StatusDeleted, pageMeta{}, nil
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return StatusRejected, pageMeta{}, fmt.Errorf("source returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceBytes))
+	if err != nil {
+		return StatusRejected, pageMeta{}, err
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		sourceURL = resp.Request.URL
+	}
+	page := string(body)
+	if !linksTo(sourceURL, page, resp.Header.Get("Content-Type"), target) {
+		return StatusRejected, pageMeta{}, errors.New("source does not link to target")
+	}
+	return StatusVerified, extractMeta(page, target, snippetRunes), nil
+}
+
+// createComment 把提及写入目标内容的评论区，评论区不存在或已关闭时跳过。
+func (s *Service) createComment(ctx context.Context, mention *domainfed.Webmention) (int64, error) {
+	var areaID *int64
+	switch mention.TargetType {
+	case TargetTypeArticle:
+		item, err := s.contentRepo.GetArticleByID(ctx, mention.TargetID)
+		if err != nil {
+			return 0, err
+		}
+		areaID = item.CommentID
+	case TargetTypeMoment:
+		item, err := s.contentRepo.GetMomentByID(ctx, mention.TargetID)
+		if err != nil {
+			return 0, err
+		}
+		areaID = item.CommentID
+	}
+	if areaID == nil {
+		return 0, nil
+	}
+	area, err := s.commentRepo.GetAreaByID(ctx, *areaID)
+	if err != nil {
+		return 0, err
+	}
+	if area.IsClosed {
+		return 0, nil
+	}
+
+	nickname := hostOf(mention.Source)
+	if mention.AuthorName != nil {
+		nickname = *mention.AuthorName
+	}
+	text := mention.Source
+	if mention.Content != nil {
+		text = *mention.Content
+	} else if mention.Title != nil {
+		text = *mention.Title
+	}
+	website := mention.Source
+	platform := "Webmention"
+	item := &comment.Comment{
+		AreaID:   *areaID,
+		Content:  text,
+		NickName: &nickname,
+		Website:  &website,
+		Platform: &platform,
+		IsViewed: false,
+	}
+	if err := s.commentRepo.Create(ctx, item); err != nil {
+		return 0, err
+	}
+	return item.ID, nil
+}
+
+func (s *Service) List(ctx context.Context, options domainfed.WebmentionListOptions) ([]domainfed.Webmention, int64, error) {
+	return s.mentionRepo.List(ctx, options)
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	return s.mentionRepo.Delete(ctx, id)
+}
+
+func parseHTTPURL(raw string) (*url.URL, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("unsupported url: %s", raw)
+	}
+	return parsed, nil
+}
+
+func hostOf(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return parsed.Host
+}
+
+func optionalString(val string) *string {
+	trimmed := strings.TrimSpace(val)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,47 @@
+package webmention
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/article"
+	appEvent "github.com/grtsinry43/grtblog-v2/server/internal/app/event"
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+)
+
+type handlerFunc func(ctx context.Context, event appEvent.Event) error
+
+func (h handlerFunc) Handle(ctx context.Context, event appEvent.Event) error {
+	return h(ctx, event)
+}
+
+// RegisterSubscribers 在文章发布或更新（已发布状态）后异步发送 Webmention。
+func RegisterSubscribers(bus appEvent.Bus, service *Service) {
+	if bus == nil || service == nil {
+		return
+	}
+	bus.Subscribe(article.ArticlePublished{}.Name(), handlerFunc(func(ctx context.Context, event appEvent.Event) error {
+		if ev, ok := event.(article.ArticlePublished); ok {
+			go service.sendAsync(ev.ID)
+		}
+		return nil
+	}))
+	bus.Subscribe(article.ArticleUpdated{}.Name(), handlerFunc(func(ctx context.Context, event appEvent.Event) error {
+		if ev, ok := event.(article.ArticleUpdated); ok && ev.Published {
+			go service.sendAsync(ev.ID)
+		}
+		return nil
+	}))
+}
+
+// sendAsync 发布时 updated 与 published 事件会同时触发，同一文章正在发送时直接跳过。
+func (s *Service) sendAsync(articleID int64) {
+	if _, running := s.inflight.LoadOrStore(articleID, struct{}{}); running {
+		return
+	}
+	defer s.inflight.Delete(articleID)
+	if err := s.SendForArticle(context.Background(), articleID); err != nil && !errors.Is(err, domainfed.ErrWebmentionDisabled) {
+		log.Printf("[webmention] 文章外链通知失败 article=%d err=%v", articleID, err)
+	}
+}
@@ -0,0 +1,164 @@
+package websub
+
+import (
+	"context"
+	"encoding/xml"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/content"
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+)
+
+const (
+	FeedArticles = "articles"
+	FeedMoments  = "moments"
+
+	feedPageSize  = 20
+	feedGenerator = "grtblog"
+	atomNamespace = "http://www.w3.org/2005/Atom"
+)
+
+// FeedPath 返回 Feed（也就是 WebSub topic）在站点上的路径。
+func FeedPath(kind string) string {
+	return "/api/feeds/" + kind + ".xml"
+}
+
+func validFeed(kind string) bool {
+	return kind == FeedArticles || kind == FeedMoments
+}
+
+// FeedDocument 渲染好的 Atom Feed，HubURL 为空表示未启用 Hub。
+type FeedDocument struct {
+	Body    []byte
+	SelfURL string
+	HubURL  string
+}
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"feed"`
+	Namespace string      `xml:"xmlns,attr"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Generator string      `xml:"generator,omitempty"`
+	Author    atomAuthor  `xml:"author"`
+	Links     []atomLink  `xml:"link"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Summary   *atomText  `xml:"summary,omitempty"`
+	Content   *atomText  `xml:"content,omitempty"`
+}
+
+// Feed 渲染最新的公开文章或手记；fallbackBase 在未配置实例地址时使用（通常为请求的站点地址）。
+func (s *Service) Feed(ctx context.Context, kind string, fallbackBase string) (*FeedDocument, error) {
+	if !validFeed(kind) {
+		return nil, domainfed.ErrFeedNotFound
+	}
+	site, err := s.site(ctx, fallbackBase)
+	if err != nil {
+		return nil, err
+	}
+	return s.renderFeed(ctx, site, kind)
+}
+
+func (s *Service) renderFeed(ctx context.Context, site siteInfo, kind string) (*FeedDocument, error) {
+	selfURL := site.baseURL + FeedPath(kind)
+	feed := atomFeed{
+		Namespace: atomNamespace,
+		ID:        selfURL,
+		Generator: feedGenerator,
+		Author:    atomAuthor{Name: site.name},
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: selfURL},
+		},
+	}
+	doc := &FeedDocument{SelfURL: selfURL}
+	if site.hub {
+		doc.HubURL = site.baseURL + HubPath
+		feed.Links = append(feed.Links, atomLink{Rel: "hub", Href: doc.HubURL})
+	}
+
+	var updated time.Time
+	switch kind {
+	case FeedArticles:
+		feed.Title = site.name + " · 文章"
+		feed.Links = append(feed.Links, atomLink{Rel: "alternate", Type: "text/html", Href: site.baseURL + "/posts"})
+		items, _, err := s.contentRepo.ListPublicArticles(ctx, content.ArticleListOptions{Page: 1, PageSize: feedPageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			pageURL := site.baseURL + "/posts/" + item.ShortURL
+			feed.Entries = append(feed.Entries, buildEntry(pageURL, item.Title, item.Summary, item.Content, item.CreatedAt, item.UpdatedAt))
+			updated = latest(updated, item.UpdatedAt)
+		}
+	case FeedMoments:
+		feed.Title = site.name + " · 手记"
+		feed.Links = append(feed.Links, atomLink{Rel: "alternate", Type: "text/html", Href: site.baseURL + "/moments"})
+		items, _, err := s.contentRepo.ListPublicMoments(ctx, content.MomentListOptions{Page: 1, PageSize: feedPageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			pageURL := site.baseURL + "/moments/" + item.ShortURL
+			feed.Entries = append(feed.Entries, buildEntry(pageURL, item.Title, item.Summary, item.Content, item.CreatedAt, item.UpdatedAt))
+			updated = latest(updated, item.UpdatedAt)
+		}
+	}
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	doc.Body = append([]byte(xml.Header), body...)
+	return doc, nil
+}
+
+func buildEntry(pageURL string, title string, summary string, markdown string, createdAt time.Time, updatedAt time.Time) atomEntry {
+	entry := atomEntry{
+		ID:        pageURL,
+		Title:     title,
+		Links:     []atomLink{{Rel: "alternate", Type: "text/html", Href: pageURL}},
+		Published: createdAt.UTC().Format(time.RFC3339),
+		Updated:   latest(createdAt, updatedAt).UTC().Format(time.RFC3339),
+		Content:   &atomText{Type: "html", Body: contentutil.RenderHTML(markdown)},
+	}
+	if text := contentutil.BuildSummary(summary, markdown); text != "" {
+		entry.Summary = &atomText{Type: "text", Body: text}
+	}
+	return entry
+}
+
+func latest(a time.Time, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
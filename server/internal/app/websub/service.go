@@ -0,0 +1,400 @@
+package websub
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/content"
+	domainfed "github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+const (
+	HubPath = "/api/websub/hub"
+
+	ModeSubscribe   = "subscribe"
+	ModeUnsubscribe = "unsubscribe"
+	ModePublish     = "publish"
+
+	DefaultLeaseSeconds = 10 * 24 * 3600
+	minLeaseSeconds     = 3600
+	maxLeaseSeconds     = 30 * 24 * 3600
+	maxSecretBytes      = 200
+	requestTimeout      = 10 * time.Second
+	distributeDelay     = 2 * time.Second
+	expireInterval      = time.Hour
+	hubAgent            = "grtblog-websub/2.0"
+)
+
+// deliveryBackoff 分发失败后的重试间隔，首项为首次投递。
+var deliveryBackoff = []time.Duration{0, 30 * time.Second, 5 * time.Minute}
+
+// Service 提供站点 Atom Feed，并作为这些 Feed 的内置 WebSub Hub：
+// 校验订阅意图、维护租期，在内容发布时把最新 Feed 推送给订阅方。
+type Service struct {
+	cfgSvc      *federationconfig.Service
+	contentRepo content.Repository
+	subRepo     domainfed.WebSubSubscriptionRepository
+	client      *http.Client
+	mu          sync.Mutex
+	scheduled   map[string]struct{}
+	done        chan struct{}
+}
+
+// NewService 创建服务并启动过期订阅清理循环。
+func NewService(cfgSvc *federationconfig.Service, contentRepo content.Repository, subRepo domainfed.WebSubSubscriptionRepository) *Service {
+	svc := &Service{
+		cfgSvc:      cfgSvc,
+		contentRepo: contentRepo,
+		subRepo:     subRepo,
+		client:      fedinfra.NewGuardedHTTPClient(requestTimeout),
+		scheduled:   make(map[string]struct{}),
+		done:        make(chan struct{}),
+	}
+	go svc.expireLoop()
+	return svc
+}
+
+func (s *Service) Close() {
+	close(s.done)
+}
+
+func (s *Service) expireLoop() {
+	ticker := time.NewTicker(expireInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if removed, err := s.subRepo.DeleteExpired(context.Background(), time.Now()); err != nil {
+				log.Printf("[websub] 清理过期订阅失败: %v", err)
+			} else if removed > 0 {
+				log.Printf("[websub] 已清理过期订阅 count=%d", removed)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+type siteInfo struct {
+	baseURL string
+	name    string
+	hub     bool
+}
+
+// site 读取实例地址与名称；Hub 只在启用且配置了实例地址时声明，避免把临时的请求地址发给订阅方。
+func (s *Service) site(ctx context.Context, fallbackBase string) (siteInfo, error) {
+	settings, err := s.cfgSvc.Settings(ctx)
+	if err != nil {
+		return siteInfo{}, err
+	}
+	instanceURL := strings.TrimRight(strings.TrimSpace(settings.InstanceURL), "/")
+	info := siteInfo{
+		baseURL: instanceURL,
+		name:    strings.TrimSpace(settings.InstanceName),
+		hub:     settings.WebSub && instanceURL != "",
+	}
+	if info.baseURL == "" {
+		info.baseURL = strings.TrimRight(strings.TrimSpace(fallbackBase), "/")
+	}
+	if info.baseURL == "" {
+		return siteInfo{}, domainfed.ErrFeedNotFound
+	}
+	if info.name == "" {
+		info.name = hostOf(info.baseURL)
+	}
+	return info, nil
+}
+
+func (s *Service) hubSite(ctx context.Context) (siteInfo, error) {
+	site, err := s.site(ctx, "")
+	if err != nil {
+		if errors.Is(err, domainfed.ErrFeedNotFound) {
+			return siteInfo{}, domainfed.ErrWebSubDisabled
+		}
+		return siteInfo{}, err
+	}
+	if !site.hub {
+		return siteInfo{}, domainfed.ErrWebSubDisabled
+	}
+	return site, nil
+}
+
+// HubRequest 对应 Hub 端点收到的表单参数。
+type HubRequest struct {
+	Mode         string
+	Topic        string
+	Callback     string
+	Secret       string
+	LeaseSeconds int
+}
+
+// HandleHub 处理订阅/退订请求（异步校验意图后生效）以及发布方的 publish 通知。
+func (s *Service) HandleHub(ctx context.Context, req HubRequest) error {
+	site, err := s.hubSite(ctx)
+	if err != nil {
+		return err
+	}
+	kind, ok := topicKind(site, req.Topic)
+	if !ok {
+		return domainfed.ErrInvalidWebSubRequest
+	}
+
+	switch req.Mode {
+	case ModePublish:
+		s.schedule(kind)
+		return nil
+	case ModeSubscribe, ModeUnsubscribe:
+	default:
+		return domainfed.ErrInvalidWebSubRequest
+	}
+	callback, err := url.Parse(strings.TrimSpace(req.Callback))
+	if err != nil || (callback.Scheme != "http" && callback.Scheme != "https") || callback.Host == "" {
+		return domainfed.ErrInvalidWebSubRequest
+	}
+	if len(req.Secret) > maxSecretBytes {
+		return domainfed.ErrInvalidWebSubRequest
+	}
+	intent := HubRequest{
+		Mode:         req.Mode,
+		Topic:        site.baseURL + FeedPath(kind),
+		Callback:     callback.String(),
+		Secret:       req.Secret,
+		LeaseSeconds: clampLease(req.LeaseSeconds),
+	}
+	go s.verifyIntent(intent)
+	return nil
+}
+
+// verifyIntent 按规范向回调地址发起 GET 校验，回显 challenge 才视为订阅方确认；
+// 校验失败时不改变已有订阅。
+func (s *Service) verifyIntent(intent HubRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	challenge, err := randomToken(16)
+	if err != nil {
+		log.Printf("[websub] 生成 challenge 失败: %v", err)
+		return
+	}
+	verifyURL, err := url.Parse(intent.Callback)
+	if err != nil {
+		return
+	}
+	query := verifyURL.Query()
+	query.Set("hub.mode", intent.Mode)
+	query.Set("hub.topic", intent.Topic)
+	query.Set("hub.challenge", challenge)
+	if intent.Mode == ModeSubscribe {
+		query.Set("hub.lease_seconds", strconv.Itoa(intent.LeaseSeconds))
+	}
+	verifyURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, verifyURL.String(), nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("User-Agent", hubAgent)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		log.Printf("[websub] 校验订阅意图失败 callback=%s err=%v", intent.Callback, err)
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices || strings.TrimSpace(string(body)) != challenge {
+		log.Printf("[websub] 订阅方未确认 mode=%s callback=%s status=%d", intent.Mode, intent.Callback, resp.StatusCode)
+		return
+	}
+
+	if intent.Mode == ModeUnsubscribe {
+		if err := s.subRepo.DeleteByTopicCallback(ctx, intent.Topic, intent.Callback); err != nil {
+			log.Printf("[websub] 退订失败 callback=%s err=%v", intent.Callback, err)
+			return
+		}
+		log.Printf("[websub] 已退订 topic=%s callback=%s", intent.Topic, intent.Callback)
+		return
+	}
+	sub := &domainfed.WebSubSubscription{
+		Topic:        intent.Topic,
+		Callback:     intent.Callback,
+		LeaseSeconds: intent.LeaseSeconds,
+		ExpiresAt:    time.Now().Add(time.Duration(intent.LeaseSeconds) * time.Second),
+	}
+	if intent.Secret != "" {
+		secret := intent.Secret
+		sub.Secret = &secret
+	}
+	if err := s.subRepo.Upsert(ctx, sub); err != nil {
+		log.Printf("[websub] 保存订阅失败 callback=%s err=%v", intent.Callback, err)
+		return
+	}
+	log.Printf("[websub] 订阅已生效 topic=%s callback=%s lease=%ds", intent.Topic, intent.Callback, intent.LeaseSeconds)
+}
+
+// schedule 合并短时间内的多次触发（发布时 updated 与 published 事件会同时到达），
+// 延迟到期后再渲染最新 Feed 分发。
+func (s *Service) schedule(kind string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.scheduled[kind]; ok {
+		return
+	}
+	s.scheduled[kind] = struct{}{}
+	time.AfterFunc(distributeDelay, func() {
+		s.mu.Lock()
+		delete(s.scheduled, kind)
+		s.mu.Unlock()
+		if err := s.Distribute(context.Background(), kind); err != nil && !errors.Is(err, domainfed.ErrWebSubDisabled) {
+			log.Printf("[websub] 分发失败 feed=%s err=%v", kind, err)
+		}
+	})
+}
+
+// Distribute 把当前 Feed 推送给该 topic 下所有未过期的订阅，失败的按 deliveryBackoff 重试；
+// 订阅方返回 410 时视为主动退订。
+func (s *Service) Distribute(ctx context.Context, kind string) error {
+	site, err := s.hubSite(ctx)
+	if err != nil {
+		return err
+	}
+	doc, err := s.renderFeed(ctx, site, kind)
+	if err != nil {
+		return err
+	}
+	subs, err := s.subRepo.ListActiveByTopic(ctx, doc.SelfURL, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	pending := subs
+	delivered := 0
+	for attempt, wait := range deliveryBackoff {
+		if len(pending) == 0 {
+			break
+		}
+		if wait > 0 {
+			time.Sleep(wait)
+		}
+		last := attempt == len(deliveryBackoff)-1
+		var failed []domainfed.WebSubSubscription
+		for _, sub := range pending {
+			status, err := s.deliver(ctx, doc, sub)
+			now := time.Now()
+			switch {
+			case err == nil:
+				delivered++
+				if recErr := s.subRepo.RecordDelivery(ctx, sub.ID, now, nil); recErr != nil {
+					log.Printf("[websub] 记录投递结果失败 id=%d err=%v", sub.ID, recErr)
+				}
+			case status == http.StatusGone:
+				if delErr := s.subRepo.Delete(ctx, sub.ID); delErr != nil && !errors.Is(delErr, domainfed.ErrWebSubNotFound) {
+					log.Printf("[websub] 删除已退订订阅失败 id=%d err=%v", sub.ID, delErr)
+				}
+			case last:
+				msg := err.Error()
+				if recErr := s.subRepo.RecordDelivery(ctx, sub.ID, now, &msg); recErr != nil {
+					log.Printf("[websub] 记录投递结果失败 id=%d err=%v", sub.ID, recErr)
+				}
+			default:
+				failed = append(failed, sub)
+			}
+		}
+		pending = failed
+	}
+	log.Printf("[websub] 分发完成 feed=%s subscribers=%d delivered=%d", kind, len(subs), delivered)
+	return nil
+}
+
+func (s *Service) deliver(ctx context.Context, doc *FeedDocument, sub domainfed.WebSubSubscription) (int, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, sub.Callback, bytes.NewReader(doc.Body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/atom+xml; charset=utf-8")
+	req.Header.Set("User-Agent", hubAgent)
+	req.Header.Add("Link", fmt.Sprintf(`<%s>; rel="hub"`, doc.HubURL))
+	req.Header.Add("Link", fmt.Sprintf(`<%s>; rel="self"`, doc.SelfURL))
+	if sub.Secret != nil && *sub.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(*sub.Secret))
+		mac.Write(doc.Body)
+		req.Header.Set("X-Hub-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("callback returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *Service) List(ctx context.Context, options domainfed.WebSubSubscriptionListOptions) ([]domainfed.WebSubSubscription, int64, error) {
+	return s.subRepo.List(ctx, options)
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	return s.subRepo.Delete(ctx, id)
+}
+
+// topicKind 只接受本站 Feed 地址作为 topic，忽略协议与末尾斜杠差异。
+func topicKind(site siteInfo, topic string) (string, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(topic))
+	if err != nil || !strings.EqualFold(parsed.Host, hostOf(site.baseURL)) {
+		return "", false
+	}
+	for _, kind := range []string{FeedArticles, FeedMoments} {
+		if strings.TrimRight(parsed.Path, "/") == FeedPath(kind) {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+func clampLease(seconds int) int {
+	if seconds <= 0 {
+		return DefaultLeaseSeconds
+	}
+	return min(max(seconds, minLeaseSeconds), maxLeaseSeconds)
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hostOf(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return parsed.Host
+}
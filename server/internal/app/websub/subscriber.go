@@ -0,0 +1,57 @@
+package websub
+
+import (
+	"context"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/article"
+	appEvent "github.com/grtsinry43/grtblog-v2/server/internal/app/event"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/moment"
+)
+
+type handlerFunc func(ctx context.Context, event appEvent.Event) error
+
+func (h handlerFunc) Handle(ctx context.Context, event appEvent.Event) error {
+	return h(ctx, event)
+}
+
+// RegisterSubscribers 文章/手记的发布、更新、下线与删除都会改变 Feed，统一排队分发。
+func RegisterSubscribers(bus appEvent.Bus, service *Service) {
+	if bus == nil || service == nil {
+		return
+	}
+	feedOf := func(event appEvent.Event) string {
+		switch ev := event.(type) {
+		case article.ArticlePublished, article.ArticleUnpublished, article.ArticleDeleted:
+			return FeedArticles
+		case article.ArticleUpdated:
+			if ev.Published {
+				return FeedArticles
+			}
+		case moment.MomentPublished, moment.MomentUnpublished, moment.MomentDeleted:
+			return FeedMoments
+		case moment.MomentUpdated:
+			if ev.Published {
+				return FeedMoments
+			}
+		}
+		return ""
+	}
+	handler := handlerFunc(func(ctx context.Context, event appEvent.Event) error {
+		if kind := feedOf(event); kind != "" {
+			service.schedule(kind)
+		}
+		return nil
+	})
+	for _, name := range []string{
+		article.ArticlePublished{}.Name(),
+		article.ArticleUpdated{}.Name(),
+		article.ArticleUnpublished{}.Name(),
+		article.ArticleDeleted{}.Name(),
+		moment.MomentPublished{}.Name(),
+		moment.MomentUpdated{}.Name(),
+		moment.MomentUnpublished{}.Name(),
+		moment.MomentDeleted{}.Name(),
+	} {
+		bus.Subscribe(name, handler)
+	}
+}
@@ -13,8 +13,11 @@ type Config struct {
 	Database  DatabaseConfig
 	Auth      AuthConfig
 	Turnstile TurnstileConfig
+	WebAuthn  WebAuthnConfig
 	Redis     RedisConfig
 	GeoIP     GeoIPConfig
+	Storage   StorageConfig
+	Image     ImageConfig
 }
 
 // AppConfig contains Fiber specific settings.
@@ -31,14 +34,24 @@ type DatabaseConfig struct {
 	AutoMigrate bool
 }
 
-// AuthConfig 控制 JWT 签发与校验。
+// AuthConfig 控制 JWT 签发与校验。SessionMaxAge 是会话自登录起的最长存活时间，刷新令牌轮换不会延长到此之后。
 type AuthConfig struct {
 	Secret        string
 	Issuer        string
 	AccessTTL     time.Duration
+	RefreshTTL    time.Duration
+	SessionMaxAge time.Duration
 	OAuthStateTTL time.Duration
 }
 
+// WebAuthnConfig 描述通行密钥的依赖方：RPID 为站点域名（不含协议与端口），Origins 为允许发起登录的前端来源。
+type WebAuthnConfig struct {
+	RPID    string
+	RPName  string
+	Origins []string
+	Timeout time.Duration
+}
+
 // TurnstileConfig 控制 Cloudflare Turnstile 人机校验。
 type TurnstileConfig struct {
 	Enabled   bool
@@ -63,6 +76,49 @@ type GeoIPConfig struct {
 	ASNURL      string
 }
 
+// StorageConfig 描述上传文件的存储后端。
+type StorageConfig struct {
+	Driver       string
+	LocalDir     string
+	Private      bool
+	SignSecret   string
+	SignedURLTTL time.Duration
+	// OrphanGrace 未被引用的上传文件至少存在这么久才会被视为孤儿，避免误删刚上传、尚未保存到内容里的文件。
+	OrphanGrace time.Duration
+	S3          S3Config
+	Resumable   ResumableConfig
+}
+
+// ResumableConfig 控制分片断点续传，大小上限独立于普通上传的请求体限制。
+type ResumableConfig struct {
+	MaxSizeMB   int
+	ChunkSizeMB int
+	SessionTTL  time.Duration
+	TempDir     string
+}
+
+// S3Config 描述 S3 兼容对象存储（AWS S3、MinIO、R2 等）。
+type S3Config struct {
+	Endpoint      string
+	Region        string
+	Bucket        string
+	AccessKey     string
+	SecretKey     string
+	PathStyle     bool
+	PublicBaseURL string
+}
+
+// ImageConfig 控制上传图片的处理：尺寸变体、输出格式与按需缩放缓存。
+type ImageConfig struct {
+	Enabled        bool
+	VariantWidths  []int
+	VariantFormats []string
+	Quality        int
+	MaxPixels      int
+	MaxResizeWidth int
+	CacheDir       string
+}
+
 // Load builds a Config struct with sane defaults overridden by environment variables.
 func Load() Config {
 	return Config{
@@ -79,7 +135,9 @@ func Load() Config {
 		Auth: AuthConfig{
 			Secret:        getEnv("AUTH_SECRET", "change-me"),
 			Issuer:        getEnv("AUTH_ISSUER", "grtblog-api"),
-			AccessTTL:     getEnvAsDuration("AUTH_ACCESS_TTL", 7*24*time.Hour),
+			AccessTTL:     getEnvAsDuration("AUTH_ACCESS_TTL", 15*time.Minute),
+			RefreshTTL:    getEnvAsDuration("AUTH_REFRESH_TTL", 30*24*time.Hour),
+			SessionMaxAge: getEnvAsDuration("AUTH_SESSION_MAX_AGE", 90*24*time.Hour),
 			OAuthStateTTL: getEnvAsDuration("AUTH_STATE_TTL", time.Minute*10),
 		},
 		Turnstile: TurnstileConfig{
@@ -88,6 +146,12 @@ func Load() Config {
 			VerifyURL: getEnv("TURNSTILE_VERIFY_URL", "https://challenges.cloudflare.com/turnstile/v0/siteverify"),
 			Timeout:   getEnvAsDuration("TURNSTILE_TIMEOUT", 5*time.Second),
 		},
+		WebAuthn: WebAuthnConfig{
+			RPID:    getEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPName:  getEnv("WEBAUTHN_RP_NAME", "grtblog"),
+			Origins: getEnvAsSlice("WEBAUTHN_ORIGINS", []string{"http://localhost:3000", "http://localhost:5173"}),
+			Timeout: getEnvAsDuration("WEBAUTHN_TIMEOUT", 5*time.Minute),
+		},
 		Redis: RedisConfig{
 			Addr:     getEnv("REDIS_ADDR", "127.0.0.1:6379"),
 			Password: getEnv("REDIS_PASSWORD", ""),
@@ -100,6 +164,38 @@ func Load() Config {
 			ASNPath:     getEnv("GEOIP_ASN_DB_PATH", "storage/geoip/GeoLite2-ASN.mmdb"),
 			ASNURL:      getEnv("GEOIP_ASN_DB_URL", "https://github.com/P3TERX/GeoLite.mmdb/raw/download/GeoLite2-ASN.mmdb"),
 		},
+		Storage: StorageConfig{
+			Driver:       strings.ToLower(getEnv("STORAGE_DRIVER", "local")),
+			LocalDir:     getEnv("STORAGE_LOCAL_DIR", "storage/uploads"),
+			Private:      getEnvAsBool("STORAGE_PRIVATE", false),
+			SignSecret:   getEnv("STORAGE_SIGN_SECRET", ""),
+			SignedURLTTL: getEnvAsDuration("STORAGE_SIGNED_URL_TTL", 15*time.Minute),
+			OrphanGrace:  getEnvAsDuration("STORAGE_ORPHAN_GRACE", 24*time.Hour),
+			S3: S3Config{
+				Endpoint:      getEnv("S3_ENDPOINT", ""),
+				Region:        getEnv("S3_REGION", "us-east-1"),
+				Bucket:        getEnv("S3_BUCKET", ""),
+				AccessKey:     getEnv("S3_ACCESS_KEY", ""),
+				SecretKey:     getEnv("S3_SECRET_KEY", ""),
+				PathStyle:     getEnvAsBool("S3_PATH_STYLE", true),
+				PublicBaseURL: getEnv("S3_PUBLIC_BASE_URL", ""),
+			},
+			Resumable: ResumableConfig{
+				MaxSizeMB:   getEnvAsInt("UPLOAD_RESUMABLE_MAX_SIZE_MB", 2048),
+				ChunkSizeMB: getEnvAsInt("UPLOAD_RESUMABLE_CHUNK_SIZE_MB", 5),
+				SessionTTL:  getEnvAsDuration("UPLOAD_RESUMABLE_SESSION_TTL", 24*time.Hour),
+				TempDir:     getEnv("UPLOAD_RESUMABLE_TEMP_DIR", "storage/tmp/uploads"),
+			},
+		},
+		Image: ImageConfig{
+			Enabled:        getEnvAsBool("IMAGE_PROCESSING_ENABLED", true),
+			VariantWidths:  getEnvAsIntSlice("IMAGE_VARIANT_WIDTHS", []int{320, 640, 1280}),
+			VariantFormats: getEnvAsSlice("IMAGE_VARIANT_FORMATS", []string{"webp"}),
+			Quality:        getEnvAsInt("IMAGE_QUALITY", 80),
+			MaxPixels:      getEnvAsInt("IMAGE_MAX_PIXELS", 50_000_000),
+			MaxResizeWidth: getEnvAsInt("IMAGE_MAX_RESIZE_WIDTH", 2560),
+			CacheDir:       getEnv("IMAGE_CACHE_DIR", "storage/cache/images"),
+		},
 	}
 }
 
@@ -163,6 +259,19 @@ func getEnvAsSlice(key string, fallback []string) []string {
 	return result
 }
 
+func getEnvAsIntSlice(key string, fallback []int) []int {
+	var result []int
+	for _, part := range getEnvAsSlice(key, nil) {
+		if i, err := strconv.Atoi(part); err == nil && i > 0 {
+			result = append(result, i)
+		}
+	}
+	if len(result) == 0 {
+		return fallback
+	}
+	return result
+}
+
 func getEnvAsInt(key string, fallback int) int {
 	value := strings.TrimSpace(os.Getenv(key))
 	if value == "" {
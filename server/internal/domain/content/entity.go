@@ -99,6 +99,12 @@ type Moment struct {
 	DeletedAt   *time.Time
 }
 
+// TimelineEntry 时间线中的一项，Article 与 Moment 只有一个非空。
+type TimelineEntry struct {
+	Article *Article
+	Moment  *Moment
+}
+
 type MomentMetrics struct {
 	MomentID  int64
 	Views     int64
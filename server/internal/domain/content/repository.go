@@ -66,6 +66,8 @@ type Repository interface {
 	DeleteMoment(ctx context.Context, id int64) error
 	ListMoments(ctx context.Context, options MomentListOptionsInternal) ([]*Moment, int64, error)
 	ListPublicMoments(ctx context.Context, options MomentListOptions) ([]*Moment, int64, error)
+	// ListPublicTimeline 按发布时间倒序分页返回公开的文章和手记。
+	ListPublicTimeline(ctx context.Context, page int, pageSize int) ([]TimelineEntry, int64, error)
 
 	// Page 相关操作
 	CreatePage(ctx context.Context, page *Page) error
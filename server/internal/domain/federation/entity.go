@@ -35,8 +35,109 @@ type FederationInstance struct {
 	Features        json.RawMessage
 	Policies        json.RawMessage
 	Endpoints       json.RawMessage
+	Manifest        json.RawMessage
 	Status          string
-	LastSeenAt      *time.Time
+	BlockReason     *string
+	// RateLimitViolations counts inbound requests rejected by the rate limiter.
+	RateLimitViolations int
+	LastRateLimitedAt   *time.Time
+	LastSeenAt          *time.Time
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// FederationDomainRule blocks or allows instances by host, e.g. "example.com" or "*.example.com".
+type FederationDomainRule struct {
+	ID        int64
+	Pattern   string
+	Action    string
+	Reason    *string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// FederationSigningKey is one local signing key; keys stay published until ExpiresAt.
+type FederationSigningKey struct {
+	ID         int64
+	KeyRef     string
+	Algorithm  string
+	PublicKey  string
+	PrivateKey string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+}
+
+// ActivityPubFollower is a remote ActivityPub actor following the local blog actor.
+type ActivityPubFollower struct {
+	ID               int64
+	ActorID          string
+	Inbox            string
+	SharedInbox      *string
+	Username         *string
+	DisplayName      *string
+	Avatar           *string
+	FollowActivityID *string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// ActivityPubInteraction records a Like or Announce of a local article/moment.
+type ActivityPubInteraction struct {
+	ID         int64
+	ActivityID string
+	Kind       string
+	ActorID    string
+	ObjectType string
+	ObjectID   int64
+	CreatedAt  time.Time
+}
+
+// Webmention is a mention received from another site, verified asynchronously against its source.
+type Webmention struct {
+	ID         int64
+	Source     string
+	Target     string
+	TargetType string
+	TargetID   int64
+	Status     string
+	AuthorName *string
+	AuthorURL  *string
+	Title      *string
+	Content    *string
+	CommentID  *int64
+	Error      *string
+	VerifiedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// WebmentionSend tracks delivery of a Webmention for one outbound link of an article.
+type WebmentionSend struct {
+	ID          int64
+	ArticleID   int64
+	Source      string
+	Target      string
+	Endpoint    *string
+	Status      string
+	StatusCode  *int
+	Error       *string
+	ContentHash *string
+	SentAt      *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// WebSubSubscription is a verified subscriber of one of our feed topics on the built-in hub.
+type WebSubSubscription struct {
+	ID              int64
+	Topic           string
+	Callback        string
+	Secret          *string
+	LeaseSeconds    int
+	ExpiresAt       time.Time
+	LastDeliveredAt *time.Time
+	LastError       *string
+	FailureCount    int
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
@@ -93,3 +194,41 @@ type FederatedMention struct {
 	CreatedAt        time.Time
 	ReadAt           *time.Time
 }
+
+// FederatedComment is a comment posted on a local article from a remote instance.
+// It stays pending until moderated; approval materializes it as a local comment.
+type FederatedComment struct {
+	ID               int64
+	SourceInstanceID int64
+	RemoteCommentID  string
+	TargetArticleID  int64
+	AuthorName       string
+	AuthorURL        *string
+	AuthorAvatar     *string
+	Content          string
+	Status           string
+	CommentID        *int64
+	RejectReason     *string
+	ReviewedAt       *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// OutboundFriendLinkRequest tracks a friend-link application sent to a remote instance
+// until the remote side reports its decision through the signed callback.
+type OutboundFriendLinkRequest struct {
+	ID                  int64
+	TargetURL           string
+	InstanceID          *int64
+	Message             *string
+	RSSURL              *string
+	Status              string
+	ResponseStatus      *int
+	ResponseBody        *string
+	RemoteApplicationID *int64
+	RejectReason        *string
+	FriendLinkID        *int64
+	DecidedAt           *time.Time
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
@@ -5,4 +5,26 @@ import "errors"
 var (
 	ErrFederationConfigNotFound   = errors.New("federation config not found")
 	ErrFederationInstanceNotFound = errors.New("federation instance not found")
+	ErrFederationInstanceBlocked  = errors.New("federation instance blocked")
+	ErrDomainRuleNotFound         = errors.New("federation domain rule not found")
+	ErrDomainRuleExists           = errors.New("federation domain rule already exists")
+	ErrInvalidDomainPattern       = errors.New("invalid federation domain pattern")
+	ErrSigningKeyNotFound         = errors.New("federation signing key not found")
+	ErrFollowerNotFound           = errors.New("activitypub follower not found")
+	ErrActivityPubDisabled        = errors.New("activitypub disabled")
+	ErrActorNotFound              = errors.New("activitypub actor not found")
+	ErrActivityObjectNotFound     = errors.New("activitypub object not found")
+	ErrActivityActorMismatch      = errors.New("activity actor does not match signature")
+	ErrWebmentionNotFound         = errors.New("webmention not found")
+	ErrWebmentionDisabled         = errors.New("webmention disabled")
+	ErrInvalidWebmention          = errors.New("invalid webmention source or target")
+	ErrWebSubDisabled             = errors.New("websub hub disabled")
+	ErrWebSubNotFound             = errors.New("websub subscription not found")
+	ErrInvalidWebSubRequest       = errors.New("invalid websub request")
+	ErrFeedNotFound               = errors.New("feed not found")
+	ErrFederatedPostNotFound      = errors.New("federated post not found")
+	ErrFederatedCommentNotFound   = errors.New("federated comment not found")
+	ErrFederatedCommentReviewed   = errors.New("federated comment already reviewed")
+	ErrRemoteCommentNotAllowed    = errors.New("remote post does not accept comments")
+	ErrOutboundFriendLinkNotFound = errors.New("outbound friend link request not found")
 )
@@ -0,0 +1,40 @@
+package federation
+
+// InstanceListOptions filters the admin instance list.
+type InstanceListOptions struct {
+	Page     int
+	PageSize int
+	Status   *string
+	Search   *string
+}
+
+// WebmentionListOptions filters the admin Webmention list.
+type WebmentionListOptions struct {
+	Page       int
+	PageSize   int
+	Status     *string
+	TargetType *string
+	TargetID   *int64
+}
+
+// WebSubSubscriptionListOptions filters the admin hub subscription list.
+type WebSubSubscriptionListOptions struct {
+	Page     int
+	PageSize int
+	Topic    *string
+}
+
+// FederatedCommentListOptions filters the admin remote comment list.
+type FederatedCommentListOptions struct {
+	Page            int
+	PageSize        int
+	Status          *string
+	TargetArticleID *int64
+}
+
+// OutboundFriendLinkListOptions filters the admin outbound friend-link request list.
+type OutboundFriendLinkListOptions struct {
+	Page     int
+	PageSize int
+	Status   *string
+}
@@ -7,17 +7,89 @@ import (
 
 // FederationInstanceRepository manages remote instance records.
 type FederationInstanceRepository interface {
+	GetByID(ctx context.Context, id int64) (*FederationInstance, error)
 	GetByBaseURL(ctx context.Context, baseURL string) (*FederationInstance, error)
 	Create(ctx context.Context, instance *FederationInstance) error
 	Update(ctx context.Context, instance *FederationInstance) error
+	UpdateStatus(ctx context.Context, id int64, status string, blockReason *string) error
+	RecordRateLimitViolation(ctx context.Context, baseURL string, at time.Time) error
+	List(ctx context.Context, options InstanceListOptions) ([]FederationInstance, int64, error)
 	ListActive(ctx context.Context) ([]FederationInstance, error)
 }
 
+// FederationDomainRuleRepository stores domain-level block/allow rules.
+type FederationDomainRuleRepository interface {
+	List(ctx context.Context) ([]FederationDomainRule, error)
+	Create(ctx context.Context, rule *FederationDomainRule) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// FederationSigningKeyRepository stores local signing keys across rotations.
+type FederationSigningKeyRepository interface {
+	// ListValid returns keys not expired at the given time, newest first; the zero time lists every key.
+	ListValid(ctx context.Context, at time.Time) ([]FederationSigningKey, error)
+	FindByPublicKey(ctx context.Context, publicKey string) (*FederationSigningKey, error)
+	Create(ctx context.Context, key *FederationSigningKey) error
+	// Rotate caps the validity of every key of the same algorithm at expiresAt and stores
+	// the new key, in a single transaction.
+	Rotate(ctx context.Context, key *FederationSigningKey, expiresAt time.Time) error
+}
+
+// ActivityPubFollowerRepository stores remote followers of the local actor.
+type ActivityPubFollowerRepository interface {
+	Upsert(ctx context.Context, follower *ActivityPubFollower) error
+	DeleteByActorID(ctx context.Context, actorID string) error
+	ListAll(ctx context.Context) ([]ActivityPubFollower, error)
+	Count(ctx context.Context) (int64, error)
+}
+
+// ActivityPubInteractionRepository stores likes and boosts of local content.
+type ActivityPubInteractionRepository interface {
+	// Create ignores activities that were already recorded.
+	Create(ctx context.Context, interaction *ActivityPubInteraction) error
+	DeleteByActivityID(ctx context.Context, activityID string, actorID string) error
+	CountByObject(ctx context.Context, objectType string, objectID int64) (map[string]int64, error)
+}
+
+// WebmentionRepository stores received Webmentions.
+type WebmentionRepository interface {
+	// Upsert creates the (source, target) pair or resets an existing one to pending.
+	Upsert(ctx context.Context, mention *Webmention) error
+	GetByID(ctx context.Context, id int64) (*Webmention, error)
+	Update(ctx context.Context, mention *Webmention) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context, options WebmentionListOptions) ([]Webmention, int64, error)
+}
+
+// WebmentionSendRepository stores per-link outbound Webmention status.
+type WebmentionSendRepository interface {
+	Upsert(ctx context.Context, send *WebmentionSend) error
+	ListByArticle(ctx context.Context, articleID int64) ([]WebmentionSend, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+// WebSubSubscriptionRepository stores hub subscriptions.
+type WebSubSubscriptionRepository interface {
+	// Upsert creates the (topic, callback) pair or renews its lease and secret.
+	Upsert(ctx context.Context, sub *WebSubSubscription) error
+	DeleteByTopicCallback(ctx context.Context, topic string, callback string) error
+	Delete(ctx context.Context, id int64) error
+	// ListActiveByTopic returns subscriptions of the topic whose lease has not expired at now.
+	ListActiveByTopic(ctx context.Context, topic string, now time.Time) ([]WebSubSubscription, error)
+	List(ctx context.Context, options WebSubSubscriptionListOptions) ([]WebSubSubscription, int64, error)
+	// RecordDelivery resets the failure count on success (deliveryErr == nil) and increments it otherwise.
+	RecordDelivery(ctx context.Context, id int64, at time.Time, deliveryErr *string) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
 // FederatedPostCacheRepository stores cached timeline posts.
 type FederatedPostCacheRepository interface {
 	UpsertBatch(ctx context.Context, posts []FederatedPostCache) error
 	ListByInstance(ctx context.Context, instanceID int64, since *time.Time, limit int) ([]FederatedPostCache, error)
 	ListRecent(ctx context.Context, limit int) ([]FederatedPostCache, error)
+	GetByRemoteID(ctx context.Context, instanceID int64, remotePostID string) (*FederatedPostCache, error)
+	// ListStale returns entries with a remote post id cached before the given time, oldest first.
+	ListStale(ctx context.Context, before time.Time, limit int) ([]FederatedPostCache, error)
 }
 
 // FederatedCitationRepository stores citation workflows.
@@ -27,6 +99,25 @@ type FederatedCitationRepository interface {
 	ListByTarget(ctx context.Context, articleID int64, status string) ([]FederatedCitation, error)
 }
 
+// FederatedCommentRepository stores comments delivered by remote instances.
+type FederatedCommentRepository interface {
+	Create(ctx context.Context, item *FederatedComment) error
+	GetByID(ctx context.Context, id int64) (*FederatedComment, error)
+	FindByRemoteID(ctx context.Context, instanceID int64, remoteID string) (*FederatedComment, error)
+	Update(ctx context.Context, item *FederatedComment) error
+	List(ctx context.Context, options FederatedCommentListOptions) ([]FederatedComment, int64, error)
+}
+
+// OutboundFriendLinkRepository stores friend-link applications sent to remote instances.
+type OutboundFriendLinkRepository interface {
+	Create(ctx context.Context, item *OutboundFriendLinkRequest) error
+	GetByID(ctx context.Context, id int64) (*OutboundFriendLinkRequest, error)
+	// FindOpenByTargetURL returns the latest request to targetURL still awaiting a decision.
+	FindOpenByTargetURL(ctx context.Context, targetURL string) (*OutboundFriendLinkRequest, error)
+	Update(ctx context.Context, item *OutboundFriendLinkRequest) error
+	List(ctx context.Context, options OutboundFriendLinkListOptions) ([]OutboundFriendLinkRequest, int64, error)
+}
+
 // FederatedMentionRepository stores mentions delivered to local users.
 type FederatedMentionRepository interface {
 	Create(ctx context.Context, mention *FederatedMention) error
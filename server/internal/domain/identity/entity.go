@@ -92,3 +92,60 @@ type UserOAuthBinding struct {
 	ExpiresAt     *time.Time
 	ProviderScope string
 }
+
+// TwoFactor 是用户的 TOTP 两步验证设置；Enabled 为 false 时表示已生成密钥但尚未验证启用。
+type TwoFactor struct {
+	UserID int64
+	Secret string
+	// LastUsedStep 为最近一次验证通过的时间步，不大于它的验证码会被拒绝，防止重放。
+	LastUsedStep int64
+	Enabled      bool
+	EnabledAt    *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Passkey 是用户注册的 WebAuthn 凭据。
+type Passkey struct {
+	ID           int64
+	UserID       int64
+	Name         string
+	CredentialID []byte
+	// PublicKey 为 COSE 编码的公钥。
+	PublicKey []byte
+	Algorithm int64
+	// SignCount 为认证器最近一次上报的签名计数，用于发现被复制的凭据。
+	SignCount      uint32
+	AAGUID         []byte
+	Transports     []string
+	BackupEligible bool
+	BackupState    bool
+	LastUsedAt     *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Session 是一次登录产生的会话，access token 的 jti 即 SessionKey；刷新令牌只保存哈希。
+type Session struct {
+	ID         int64
+	SessionKey string
+	UserID     int64
+	// RefreshTokenHash 为当前有效的刷新令牌哈希；PreviousTokenHash 为上一次轮换前的哈希，用于发现令牌被重放。
+	RefreshTokenHash  string
+	PreviousTokenHash string
+	UserAgent         string
+	Browser           string
+	Platform          string
+	IP                string
+	Location          string
+	CreatedAt         time.Time
+	LastSeenAt        time.Time
+	ExpiresAt         time.Time
+	RevokedAt         *time.Time
+	RevokedReason     string
+}
+
+// Active 判断会话在 now 时刻是否仍可使用。
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
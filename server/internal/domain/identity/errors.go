@@ -6,4 +6,22 @@ var (
 	ErrUserExists         = errors.New("用户已存在")
 	ErrUserNotFound       = errors.New("用户不存在")
 	ErrInvalidCredentials = errors.New("用户名或密码不正确")
+
+	ErrTwoFactorNotEnabled     = errors.New("未开启两步验证")
+	ErrTwoFactorAlreadyEnabled = errors.New("已开启两步验证")
+	ErrTwoFactorNotSetup       = errors.New("请先生成两步验证密钥")
+	ErrInvalidTwoFactorCode    = errors.New("验证码不正确")
+	ErrTwoFactorRequired       = errors.New("管理员必须开启两步验证")
+	ErrChallengeNotFound       = errors.New("登录验证已过期，请重新登录")
+
+	ErrPasskeyNotFound      = errors.New("通行密钥不存在")
+	ErrPasskeyExists        = errors.New("该通行密钥已注册")
+	ErrPasskeyVerification  = errors.New("通行密钥校验失败")
+	ErrPasskeySignCount     = errors.New("通行密钥签名计数异常，凭据可能已被复制")
+	ErrPasskeyNotConfigured = errors.New("未启用通行密钥")
+	ErrPasskeyLimitExceeded = errors.New("通行密钥数量已达上限")
+
+	ErrSessionNotFound     = errors.New("会话不存在")
+	ErrInvalidRefreshToken = errors.New("刷新令牌无效或已过期，请重新登录")
+	ErrRefreshTokenReused  = errors.New("刷新令牌已被使用，会话已注销，请重新登录")
 )
@@ -1,6 +1,9 @@
 package identity
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository 定义用户及其权限相关的持久化操作。
 type Repository interface {
@@ -15,6 +18,8 @@ type Repository interface {
 	FindByOAuth(ctx context.Context, providerKey, oauthID string) (*User, error)
 	BindOAuth(ctx context.Context, link UserOAuth) error
 	CountUsers(ctx context.Context) (int64, error)
+	// FindFirstAdmin 返回最早创建的管理员，用作站点对外的身份（如 ActivityPub actor）。
+	FindFirstAdmin(ctx context.Context) (*User, error)
 }
 
 // OAuthProviderRepository 提供 OAuth/OIDC 提供方配置。
@@ -26,3 +31,55 @@ type OAuthProviderRepository interface {
 	Update(ctx context.Context, provider *OAuthProvider) error
 	Delete(ctx context.Context, key string) error
 }
+
+// TwoFactorRepository 定义两步验证设置与恢复码的持久化操作，恢复码只保存哈希。
+type TwoFactorRepository interface {
+	FindByUserID(ctx context.Context, userID int64) (*TwoFactor, error)
+	// Save 按 UserID 新建或覆盖设置。
+	Save(ctx context.Context, tf *TwoFactor) error
+	// Delete 删除设置及全部恢复码。
+	Delete(ctx context.Context, userID int64) error
+	// MarkStepUsed 仅当 step 大于已记录的时间步时更新并返回 true。
+	MarkStepUsed(ctx context.Context, userID int64, step int64) (bool, error)
+	// ReplaceRecoveryCodes 作废旧恢复码并写入新的哈希。
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error
+	// UseRecoveryCode 将未使用的恢复码标记为已使用，不存在或已使用时返回 false。
+	UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID int64) (int64, error)
+}
+
+// PasskeyRepository 定义 WebAuthn 凭据的持久化操作。
+type PasskeyRepository interface {
+	ListByUserID(ctx context.Context, userID int64) ([]Passkey, error)
+	FindByCredentialID(ctx context.Context, credentialID []byte) (*Passkey, error)
+	Create(ctx context.Context, passkey *Passkey) error
+	// UpdateSignCount 仅当新计数大于已保存的计数（或双方均为 0）时更新并返回 true，防止并发断言回退计数。
+	UpdateSignCount(ctx context.Context, id int64, signCount uint32, backupState bool, usedAt time.Time) (bool, error)
+	Rename(ctx context.Context, userID, id int64, name string) error
+	Delete(ctx context.Context, userID, id int64) error
+}
+
+// SessionRepository 定义登录会话的持久化操作。
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session) error
+	FindByKey(ctx context.Context, sessionKey string) (*Session, error)
+	// ListActive 返回用户未吊销且未过期的会话，按最近活跃时间倒序。
+	ListActive(ctx context.Context, userID int64, now time.Time) ([]Session, error)
+	// Rotate 仅当当前刷新令牌哈希仍为 oldHash 且会话未吊销时写入新哈希，返回是否更新成功。
+	Rotate(ctx context.Context, id int64, oldHash, newHash string, update SessionTouch) (bool, error)
+	// Revoke 吊销用户的一个会话，会话不存在或已吊销时返回 ErrSessionNotFound。
+	Revoke(ctx context.Context, userID, id int64, reason string) error
+	RevokeByKey(ctx context.Context, sessionKey, reason string) error
+	// RevokeAll 吊销用户全部会话，exceptKey 非空时保留该会话，返回吊销数量。
+	RevokeAll(ctx context.Context, userID int64, exceptKey, reason string) (int64, error)
+	// DeleteExpired 清理在 before 之前过期或吊销的会话。
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SessionTouch 是刷新时更新的会话信息。
+type SessionTouch struct {
+	IP         string
+	Location   string
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+}
@@ -8,6 +8,7 @@ const (
 	TargetArticle TargetType = "article"
 	TargetMoment  TargetType = "moment"
 	TargetPage    TargetType = "page"
+	TargetPhoto   TargetType = "photo"
 )
 
 type ContentLike struct {
@@ -0,0 +1,12 @@
+package like
+
+import "context"
+
+// Repository 定义点赞记录的持久化操作；登录用户按 UserID 去重，访客按 SessionID 去重。
+type Repository interface {
+	// Create 记录点赞，已点赞过时返回 false。
+	Create(ctx context.Context, like *ContentLike) (bool, error)
+	// Delete 取消点赞，没有对应记录时返回 false。
+	Delete(ctx context.Context, like *ContentLike) (bool, error)
+	Exists(ctx context.Context, like *ContentLike) (bool, error)
+}
@@ -1,23 +1,165 @@
 package media
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type UploadFile struct {
-	ID        int64
-	Name      string
-	Path      string
-	Type      string
-	Size      int64
-	Hash      string
+	ID   int64
+	Name string
+	Path string
+	Type string
+	Size int64
+	Hash string
+	// UserID 为上传者，早期上传的文件为空，仅管理员可见。
+	UserID *int64
+	ImageMeta
 	CreatedAt time.Time
 }
 
+// ImageMeta 是图片上传后提取的元数据，非图片文件为零值。
+type ImageMeta struct {
+	Width         int
+	Height        int
+	DominantColor string
+	Blurhash      string
+	Variants      []ImageVariant
+}
+
+// ImageVariant 是上传时预生成的缩放版本，Path 与 UploadFile.Path 格式相同。
+type ImageVariant struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Format string `json:"format"`
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+}
+
+// LocalDriver 本地磁盘存储；历史记录的 Path 不带驱动前缀，均视为本地文件。
+const LocalDriver = "local"
+
+// StorageKey 拆分 Path 为存储驱动与对象键，例如 "s3://pictures/a.jpg" -> ("s3", "pictures/a.jpg")。
+func (f UploadFile) StorageKey() (string, string) {
+	return SplitStoredPath(f.Path)
+}
+
+// PublicURL 返回存储路径对应的站内访问地址（/uploads/<key>），路径为空时返回空串。
+func PublicURL(storedPath string) string {
+	if _, key := SplitStoredPath(storedPath); key != "" {
+		return "/uploads/" + key
+	}
+	return ""
+}
+
+func SplitStoredPath(path string) (string, string) {
+	trimmed := strings.TrimSpace(path)
+	if driver, key, ok := strings.Cut(trimmed, "://"); ok && driver != "" {
+		return driver, strings.TrimLeft(key, "/")
+	}
+	return LocalDriver, strings.TrimLeft(trimmed, "/")
+}
+
+// StoredPath 组装写入 UploadFile.Path 的值；本地驱动保持 "/pictures/a.jpg" 的历史格式。
+func StoredPath(driver string, key string) string {
+	key = strings.TrimLeft(key, "/")
+	if driver == "" || driver == LocalDriver {
+		return "/" + key
+	}
+	return driver + "://" + key
+}
+
+// Photo 相册中的照片；URL 为图片的公开地址，Shade 为主色，Location/Device 可由 EXIF 自动填充。
 type Photo struct {
 	ID          int64
+	UploadID    *int64
+	AlbumID     *int64
+	CommentID   int64
 	URL         string
 	Location    *string
 	Device      *string
 	Shade       *string
 	Description *string
+	Width       int
+	Height      int
+	Blurhash    string
+	TakenAt     *time.Time
+	SortOrder   int
+	Likes       int
 	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Album 相册；未设置封面时使用排序最靠前的照片，PhotoCount 与 Cover 只在查询时填充。
+type Album struct {
+	ID           int64
+	Title        string
+	Description  *string
+	CoverPhotoID *int64
+	SortOrder    int
+	PhotoCount   int64
+	Cover        *Photo
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// 引用上传文件的内容类型。
+const (
+	UsageOwnerArticle  = "article"
+	UsageOwnerMoment   = "moment"
+	UsageOwnerPage     = "page"
+	UsageOwnerThinking = "thinking"
+	UsageOwnerPhoto    = "photo"
+	// 头像、友链 logo 与站点信息的修改不发事件，引用在检查前按需全量同步。
+	UsageOwnerUser        = "user"
+	UsageOwnerFriendLink  = "friend_link"
+	UsageOwnerWebsiteInfo = "website_info"
+)
+
+// UsageSource 是可能包含上传链接的非内容字段，例如用户头像。
+type UsageSource struct {
+	OwnerType string
+	OwnerID   int64
+	Text      string
+}
+
+// Usage 表示某个内容引用了上传文件（封面、配图或正文中的 /uploads 链接）。
+type Usage struct {
+	UploadID  int64
+	OwnerType string
+	OwnerID   int64
+	CreatedAt time.Time
+}
+
+// UploadSession 是一次分片上传会话，分片暂存在服务器本地，全部到齐后合并保存为上传文件。
+type UploadSession struct {
+	ID          string
+	UserID      int64
+	Name        string
+	Type        string
+	Size        int64
+	ChunkSize   int64
+	TotalChunks int
+	// Hash 为客户端声明的整体 SHA-256，可为空；非空时合并后会校验。
+	Hash      string
+	UploadID  *int64
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Completed 会话是否已合并完成。
+func (s UploadSession) Completed() bool {
+	return s.UploadID != nil
+}
+
+// ChunkLength 返回第 index 个分片应有的字节数。
+func (s UploadSession) ChunkLength(index int) int64 {
+	if index < 0 || index >= s.TotalChunks {
+		return 0
+	}
+	if index == s.TotalChunks-1 {
+		return s.Size - int64(index)*s.ChunkSize
+	}
+	return s.ChunkSize
 }
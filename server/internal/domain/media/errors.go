@@ -4,3 +4,20 @@ import "errors"
 
 var ErrUploadFileNotFound = errors.New("上传文件不存在")
 var ErrInvalidUploadType = errors.New("无效的上传类型")
+var ErrStorageDriverNotFound = errors.New("存储驱动未配置")
+var ErrPhotoNotFound = errors.New("照片不存在")
+var ErrAlbumNotFound = errors.New("相册不存在")
+var ErrPhotoNotInAlbum = errors.New("照片不属于该相册")
+var ErrAlbumTitleEmpty = errors.New("相册标题不能为空")
+var ErrUploadFileInUse = errors.New("上传文件仍被内容引用")
+var ErrUploadFileUsedByPhoto = errors.New("上传文件被相册照片使用")
+var ErrUploadSessionNotFound = errors.New("上传会话不存在或已过期")
+var ErrUploadSessionIncomplete = errors.New("分片尚未全部上传")
+var ErrUploadTooLarge = errors.New("文件超过允许的大小")
+var ErrChunkOutOfRange = errors.New("分片序号超出范围")
+var ErrChunkSizeMismatch = errors.New("分片大小不正确")
+var ErrChunkChecksumMismatch = errors.New("分片校验和不匹配")
+var ErrUploadHashMismatch = errors.New("文件校验和不匹配")
+var ErrUnsupportedFileType = errors.New("不支持的文件类型")
+var ErrUploadQuotaExceeded = errors.New("存储空间配额不足")
+var ErrRemoteImageFetch = errors.New("外链图片下载失败")
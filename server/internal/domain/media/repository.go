@@ -1,6 +1,9 @@
 package media
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository 定义上传文件的持久化操作。
 type Repository interface {
@@ -9,6 +12,67 @@ type Repository interface {
 	Create(ctx context.Context, file *UploadFile) error
 	UpdatePath(ctx context.Context, id int64, path string) error
 	UpdateName(ctx context.Context, id int64, name string) error
-	List(ctx context.Context, offset int, limit int) ([]UploadFile, int64, error)
+	UpdateImageMeta(ctx context.Context, id int64, meta ImageMeta) error
+	// List 分页列出上传文件，userID 非空时只列出该用户上传的文件。
+	List(ctx context.Context, userID *int64, offset int, limit int) ([]UploadFile, int64, error)
+	// SumSizeByUser 统计用户已上传文件的总大小，用于配额检查。
+	SumSizeByUser(ctx context.Context, userID int64) (int64, error)
+	// FindByStoredPaths 按存储路径查找上传文件，路径命中原图或任一尺寸变体都算匹配。
+	FindByStoredPaths(ctx context.Context, paths []string) ([]UploadFile, error)
 	DeleteByID(ctx context.Context, id int64) error
 }
+
+// PhotoRepository 定义照片的持久化操作；创建照片时同时创建评论区。
+type PhotoRepository interface {
+	FindByID(ctx context.Context, id int64) (*Photo, error)
+	// List 按相册分页；albumID 为 nil 时列出全部照片（按创建时间倒序），否则按相册内排序。
+	List(ctx context.Context, albumID *int64, offset int, limit int) ([]Photo, int64, error)
+	Create(ctx context.Context, photo *Photo) error
+	Update(ctx context.Context, photo *Photo) error
+	Delete(ctx context.Context, id int64) error
+	// UpdateOrder 按 ids 顺序重写相册内照片的排序，ids 须全部属于该相册。
+	UpdateOrder(ctx context.Context, albumID int64, ids []int64) error
+	NextSortOrder(ctx context.Context, albumID int64) (int, error)
+	IncLike(ctx context.Context, id int64) error
+	DecLike(ctx context.Context, id int64) error
+}
+
+// AlbumRepository 定义相册的持久化操作。
+type AlbumRepository interface {
+	FindByID(ctx context.Context, id int64) (*Album, error)
+	List(ctx context.Context, offset int, limit int) ([]Album, int64, error)
+	Create(ctx context.Context, album *Album) error
+	Update(ctx context.Context, album *Album) error
+	Delete(ctx context.Context, id int64) error
+	UpdateOrder(ctx context.Context, ids []int64) error
+}
+
+// UsageRepository 定义内容对上传文件引用关系的持久化操作。照片通过 photo.upload_id 直接关联，查询时一并计入。
+type UsageRepository interface {
+	// Replace 用 uploadIDs 覆盖某个内容的全部引用，uploadIDs 为空表示清除。
+	Replace(ctx context.Context, ownerType string, ownerID int64, uploadIDs []int64) error
+	// ReplaceOwnerType 用 refs（owner_id -> uploadIDs）覆盖某类引用的全部记录。
+	ReplaceOwnerType(ctx context.Context, ownerType string, refs map[int64][]int64) error
+	// ListReferenceSources 返回用户头像、友链 logo 与站点信息中包含 /uploads/ 链接的字段。
+	ListReferenceSources(ctx context.Context) ([]UsageSource, error)
+	ListByUpload(ctx context.Context, uploadID int64) ([]Usage, error)
+	// ListOrphans 分页列出创建早于 before 且未被任何内容引用的上传文件。
+	ListOrphans(ctx context.Context, before time.Time, offset int, limit int) ([]UploadFile, int64, error)
+}
+
+// SourceRepository 记录由外链转存而来的上传文件与其原始地址，同一地址只对应一个文件。
+type SourceRepository interface {
+	FindByURL(ctx context.Context, sourceURL string) (*UploadFile, error)
+	// Record 保存原始地址，地址已存在时改为指向 uploadID。
+	Record(ctx context.Context, uploadID int64, sourceURL string) error
+}
+
+// UploadSessionRepository 定义分片上传会话的持久化操作。
+type UploadSessionRepository interface {
+	Create(ctx context.Context, session *UploadSession) error
+	FindByID(ctx context.Context, id string) (*UploadSession, error)
+	MarkCompleted(ctx context.Context, id string, uploadID int64) error
+	Delete(ctx context.Context, id string) error
+	// ListExpired 列出 before 之前过期的会话，用于清理暂存分片。
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]UploadSession, error)
+}
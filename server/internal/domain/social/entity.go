@@ -17,8 +17,11 @@ type FriendLink struct {
 	InstanceID       *int64
 	LastSyncAt       *time.Time
 	LastSyncStatus   *string
+	LastSyncError    *string
 	SyncInterval     *int
 	TotalPostsCached int
+	FeedETag         *string
+	FeedLastModified *string
 	UserID           *int64
 	IsActive         bool
 	CreatedAt        time.Time
@@ -26,6 +29,30 @@ type FriendLink struct {
 	DeletedAt        *time.Time
 }
 
+// FriendLinkSyncState 一次订阅同步后需要回写到友链上的状态。
+type FriendLinkSyncState struct {
+	LastSyncAt       time.Time
+	LastSyncStatus   string
+	LastSyncError    *string
+	TotalPostsCached int
+	FeedETag         *string
+	FeedLastModified *string
+}
+
+// FriendLinkPost 友链订阅源中抓取到的文章（朋友圈条目）。
+type FriendLinkPost struct {
+	ID           int64
+	FriendLinkID int64
+	GUID         string
+	Title        string
+	URL          string
+	Summary      *string
+	Author       *string
+	PublishedAt  time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
 type FriendLinkApplication struct {
 	ID                int64
 	Name              *string
@@ -42,10 +69,45 @@ type FriendLinkApplication struct {
 	UserID            *int64
 	Message           *string
 	Status            string
+	ReviewReason      *string
+	ReviewedAt        *time.Time
 	CreatedAt         time.Time
 	UpdatedAt         time.Time
 }
 
+// FriendLinkHealth 友链最近一次健康检查结果。
+type FriendLinkHealth struct {
+	FriendLinkID        int64
+	StatusCode          *int
+	LatencyMs           *int
+	TLSExpiresAt        *time.Time
+	HasBacklink         bool
+	RSSStatusCode       *int
+	ErrorMessage        *string
+	IsHealthy           bool
+	ConsecutiveFailures int
+	LastSuccessAt       *time.Time
+	// AutoDeactivated 标记友链是被健康检查自动停用的，恢复后会被自动重新启用。
+	AutoDeactivated bool
+	CheckedAt       time.Time
+}
+
+// FriendLinkWebSub 友链订阅源在远端 WebSub Hub 上的订阅，激活后该友链改为接收推送。
+type FriendLinkWebSub struct {
+	FriendLinkID  int64
+	Hub           string
+	Topic         string
+	CallbackToken string
+	Secret        string
+	Status        string
+	LeaseSeconds  *int
+	ExpiresAt     *time.Time
+	LastPushAt    *time.Time
+	Error         *string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
 type GlobalNotification struct {
 	ID         int64
 	Content    string
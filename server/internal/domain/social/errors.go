@@ -4,3 +4,9 @@ import "errors"
 
 var ErrFriendLinkApplicationNotFound = errors.New("友链申请不存在")
 var ErrFriendLinkNotFound = errors.New("友链不存在")
+var ErrFriendLinkApplicationReviewed = errors.New("友链申请已处理")
+var ErrFriendLinkURLExists = errors.New("友链 URL 已存在")
+var ErrFriendLinkInvalidSyncMode = errors.New("无效的友链同步模式")
+var ErrFriendLinkInvalidKind = errors.New("无效的友链类型")
+var ErrFriendLinkHealthNotFound = errors.New("友链暂无健康检查记录")
+var ErrFriendLinkWebSubNotFound = errors.New("友链 WebSub 订阅不存在")
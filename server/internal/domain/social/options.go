@@ -0,0 +1,25 @@
+package social
+
+// FriendLinkApplicationListOptions 友链申请列表查询选项。
+type FriendLinkApplicationListOptions struct {
+	Page     int
+	PageSize int
+	Status   *string
+	Channel  *string
+}
+
+// FriendLinkListOptions 后台友链列表查询选项。
+type FriendLinkListOptions struct {
+	Page     int
+	PageSize int
+	Kind     *string
+	IsActive *bool
+	Search   *string
+}
+
+// FriendLinkHealthListOptions 友链健康检查结果查询选项。
+type FriendLinkHealthListOptions struct {
+	Page      int
+	PageSize  int
+	IsHealthy *bool
+}
@@ -3,12 +3,53 @@ package social
 import "context"
 
 type FriendLinkApplicationRepository interface {
+	FindByID(ctx context.Context, id int64) (*FriendLinkApplication, error)
 	FindByURL(ctx context.Context, url string) (*FriendLinkApplication, error)
+	List(ctx context.Context, options FriendLinkApplicationListOptions) ([]*FriendLinkApplication, int64, error)
 	Create(ctx context.Context, app *FriendLinkApplication) error
 	Update(ctx context.Context, app *FriendLinkApplication) error
 }
 
 type FriendLinkRepository interface {
+	FindByID(ctx context.Context, id int64) (*FriendLink, error)
 	FindByURL(ctx context.Context, url string) (*FriendLink, error)
+	FindByIDs(ctx context.Context, ids []int64) ([]*FriendLink, error)
+	List(ctx context.Context, options FriendLinkListOptions) ([]*FriendLink, int64, error)
 	Create(ctx context.Context, link *FriendLink) error
+	Update(ctx context.Context, link *FriendLink) error
+	Delete(ctx context.Context, id int64) error
+	// ListActive 返回所有启用中的友链。
+	ListActive(ctx context.Context) ([]*FriendLink, error)
+	// ListSyncable 返回启用中且配置了 RSS 同步的友链。
+	ListSyncable(ctx context.Context) ([]*FriendLink, error)
+	UpdateSyncState(ctx context.Context, id int64, state FriendLinkSyncState) error
+	// SetActive 只更新启用状态，不影响同步等其它字段。
+	SetActive(ctx context.Context, id int64, active bool) error
+}
+
+type FriendLinkPostRepository interface {
+	// UpsertBatch 按 (friend_link_id, guid) 写入或更新文章。
+	UpsertBatch(ctx context.Context, posts []FriendLinkPost) error
+	// TrimByLink 仅保留某个友链最新的 keep 篇文章，返回保留后的数量。
+	TrimByLink(ctx context.Context, friendLinkID int64, keep int) (int, error)
+	// ListRecentActive 按发布时间倒序列出启用友链的文章。
+	ListRecentActive(ctx context.Context, page int, pageSize int) ([]*FriendLinkPost, int64, error)
+}
+
+type FriendLinkHealthRepository interface {
+	GetByLinkID(ctx context.Context, friendLinkID int64) (*FriendLinkHealth, error)
+	Upsert(ctx context.Context, health *FriendLinkHealth) error
+	List(ctx context.Context, options FriendLinkHealthListOptions) ([]*FriendLinkHealth, int64, error)
+	// ListAutoDeactivatedLinkIDs 返回被健康检查自动停用的友链 ID。
+	ListAutoDeactivatedLinkIDs(ctx context.Context) ([]int64, error)
+}
+
+type FriendLinkWebSubRepository interface {
+	GetByLinkID(ctx context.Context, friendLinkID int64) (*FriendLinkWebSub, error)
+	GetByToken(ctx context.Context, token string) (*FriendLinkWebSub, error)
+	// Upsert 按 friend_link_id 写入或覆盖订阅。
+	Upsert(ctx context.Context, sub *FriendLinkWebSub) error
+	Delete(ctx context.Context, friendLinkID int64) error
+	// ListAll 返回全部订阅，用于同步时判断哪些友链无需轮询。
+	ListAll(ctx context.Context) ([]*FriendLinkWebSub, error)
 }
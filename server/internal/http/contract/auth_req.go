@@ -28,3 +28,31 @@ type ChangePasswordReq struct {
 	OldPassword string `json:"oldPassword"`
 	NewPassword string `json:"newPassword"`
 }
+
+// VerifyTwoFactorLoginReq 登录第二步请求，code 与 recoveryCode 二选一。
+type VerifyTwoFactorLoginReq struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recoveryCode"`
+}
+
+// TwoFactorLoginSetupReq 登录时强制绑定两步验证的请求。
+type TwoFactorLoginSetupReq struct {
+	ChallengeToken string `json:"challengeToken"`
+}
+
+// TwoFactorCodeReq 携带验证码的请求。
+type TwoFactorCodeReq struct {
+	Code string `json:"code"`
+}
+
+// DisableTwoFactorReq 关闭两步验证请求，code 可为验证码或恢复码。
+type DisableTwoFactorReq struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// RefreshTokenReq 刷新 token 请求。
+type RefreshTokenReq struct {
+	RefreshToken string `json:"refreshToken"`
+}
@@ -48,8 +48,41 @@ func ToUserResp(u identity.User) UserResp {
 
 // LoginResp 供登录接口返回数据使用。
 type LoginResp struct {
-	Token string   `json:"token"`
-	User  UserResp `json:"user"`
+	Token     string     `json:"token"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// RefreshToken 用于 /auth/refresh 换取新的 token，每次使用后轮换。
+	RefreshToken     string     `json:"refreshToken,omitempty"`
+	RefreshExpiresAt *time.Time `json:"refreshExpiresAt,omitempty"`
+	User             UserResp   `json:"user"`
+	// TwoFactor 非空时需调用 /auth/login/2fa 完成第二步，token 为空。
+	TwoFactor     *TwoFactorChallengeResp `json:"twoFactor,omitempty"`
+	RecoveryCodes []string                `json:"recoveryCodes,omitempty"`
+}
+
+// TwoFactorChallengeResp 登录第二步所需的挑战，setupRequired 为 true 时需先绑定验证器。
+type TwoFactorChallengeResp struct {
+	ChallengeToken string    `json:"challengeToken"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	SetupRequired  bool      `json:"setupRequired"`
+}
+
+// TwoFactorStatusResp 两步验证状态。
+type TwoFactorStatusResp struct {
+	Enabled           bool       `json:"enabled"`
+	Required          bool       `json:"required"`
+	EnabledAt         *time.Time `json:"enabledAt,omitempty"`
+	RecoveryCodesLeft int64      `json:"recoveryCodesLeft"`
+}
+
+// TwoFactorSetupResp 待验证的 TOTP 密钥，otpauthUri 用于生成二维码。
+type TwoFactorSetupResp struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+// RecoveryCodesResp 恢复码明文，只在生成时返回一次。
+type RecoveryCodesResp struct {
+	Codes []string `json:"codes"`
 }
 
 // AccessInfoResp 返回当前登录用户的权限信息。
@@ -27,7 +27,37 @@ type FederationAdminMentionReq struct {
 	MentionType       string  `json:"mention_type,omitempty"`
 }
 
+// FederationAdminCommentReq 管理后台回复友站文章。
+type FederationAdminCommentReq struct {
+	TargetInstanceURL string `json:"target_instance_url"`
+	TargetPostID      string `json:"target_post_id"`
+	Content           string `json:"content"`
+}
+
+// FederationAdminCommentRejectReq 拒绝或撤回跨站评论。
+type FederationAdminCommentRejectReq struct {
+	Reason string `json:"reason,omitempty"`
+}
+
 // FederationAdminRemoteCheckReq 远端联通性检查请求。
 type FederationAdminRemoteCheckReq struct {
 	TargetURL string `json:"target_url"`
 }
+
+// FederationAdminBlockInstanceReq 屏蔽实例。
+type FederationAdminBlockInstanceReq struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// FederationAdminDomainRuleReq 新增域名规则，pattern 支持 example.com 或 *.example.com。
+type FederationAdminDomainRuleReq struct {
+	Pattern string `json:"pattern"`
+	Action  string `json:"action"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// FederationAdminRotateKeyReq 轮换签名密钥，grace_hours 为旧密钥继续公布的小时数，默认 48。
+type FederationAdminRotateKeyReq struct {
+	Algorithm  string `json:"algorithm"`
+	GraceHours int    `json:"grace_hours,omitempty"`
+}
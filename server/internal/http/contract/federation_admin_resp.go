@@ -1,9 +1,18 @@
 package contract
 
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+)
+
 // FederationAdminProxyResp 返回远端响应。
 type FederationAdminProxyResp struct {
 	StatusCode int    `json:"status_code"`
 	Body       string `json:"body"`
+	// RequestID 出站友链申请的本地记录 ID。
+	RequestID int64 `json:"request_id,omitempty"`
 }
 
 // FederationAdminRemoteCheckResp 返回远端 well-known 信息（仅用于文档与测试展示）。
@@ -12,3 +21,316 @@ type FederationAdminRemoteCheckResp struct {
 	PublicKey any `json:"public_key,omitempty" swaggertype:"object"`
 	Endpoints any `json:"endpoints,omitempty" swaggertype:"object"`
 }
+
+// FederationInstanceResp 已知联合实例信息。
+type FederationInstanceResp struct {
+	ID              int64           `json:"id"`
+	BaseURL         string          `json:"base_url"`
+	Name            *string         `json:"name,omitempty"`
+	Description     *string         `json:"description,omitempty"`
+	ProtocolVersion *string         `json:"protocol_version,omitempty"`
+	KeyID           *string         `json:"key_id,omitempty"`
+	Status          string          `json:"status"`
+	BlockReason     *string         `json:"block_reason,omitempty"`
+	Features        json.RawMessage `json:"features" swaggertype:"array,string"`
+	// RateLimitViolations 入站请求被限流的累计次数。
+	RateLimitViolations int        `json:"rate_limit_violations"`
+	LastRateLimitedAt   *time.Time `json:"last_rate_limited_at,omitempty"`
+	LastSeenAt          *time.Time `json:"last_seen_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// FederationInstanceDetailResp 实例详情，附带最近一次拉取的 manifest/endpoints/公钥。
+type FederationInstanceDetailResp struct {
+	FederationInstanceResp
+	PublicKey *string         `json:"public_key,omitempty"`
+	Policies  json.RawMessage `json:"policies" swaggertype:"object"`
+	Endpoints json.RawMessage `json:"endpoints" swaggertype:"object"`
+	Manifest  json.RawMessage `json:"manifest" swaggertype:"object"`
+}
+
+// FederationInstanceListResp 实例分页列表。
+type FederationInstanceListResp struct {
+	Items []FederationInstanceResp `json:"items"`
+	Total int64                    `json:"total"`
+	Page  int                      `json:"page"`
+	Size  int                      `json:"size"`
+}
+
+// FederationDomainRuleResp 域名黑白名单规则。
+type FederationDomainRuleResp struct {
+	ID        int64     `json:"id"`
+	Pattern   string    `json:"pattern"`
+	Action    string    `json:"action"`
+	Reason    *string   `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FederationSigningKeyResp 本站签名密钥（不含私钥）。
+type FederationSigningKeyResp struct {
+	ID        int64      `json:"id"`
+	KeyRef    string     `json:"key_ref"`
+	Algorithm string     `json:"algorithm"`
+	PublicKey string     `json:"public_key"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// WebmentionResp 收到的 Webmention 及其校验结果。
+type WebmentionResp struct {
+	ID         int64      `json:"id"`
+	Source     string     `json:"source"`
+	Target     string     `json:"target"`
+	TargetType string     `json:"target_type"`
+	TargetID   int64      `json:"target_id"`
+	Status     string     `json:"status"`
+	AuthorName *string    `json:"author_name,omitempty"`
+	Title      *string    `json:"title,omitempty"`
+	Content    *string    `json:"content,omitempty"`
+	CommentID  *int64     `json:"comment_id,omitempty"`
+	Error      *string    `json:"error,omitempty"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// WebmentionListResp Webmention 分页列表。
+type WebmentionListResp struct {
+	Items []WebmentionResp `json:"items"`
+	Total int64            `json:"total"`
+	Page  int              `json:"page"`
+	Size  int              `json:"size"`
+}
+
+// WebmentionSendResp 文章单个外链的 Webmention 发送状态。
+type WebmentionSendResp struct {
+	ID         int64      `json:"id"`
+	ArticleID  int64      `json:"article_id"`
+	Source     string     `json:"source"`
+	Target     string     `json:"target"`
+	Endpoint   *string    `json:"endpoint,omitempty"`
+	Status     string     `json:"status"`
+	StatusCode *int       `json:"status_code,omitempty"`
+	Error      *string    `json:"error,omitempty"`
+	SentAt     *time.Time `json:"sent_at,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// WebSubSubscriptionResp 内置 Hub 上的一条订阅。
+type WebSubSubscriptionResp struct {
+	ID              int64      `json:"id"`
+	Topic           string     `json:"topic"`
+	Callback        string     `json:"callback"`
+	HasSecret       bool       `json:"has_secret"`
+	LeaseSeconds    int        `json:"lease_seconds"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	LastDeliveredAt *time.Time `json:"last_delivered_at,omitempty"`
+	LastError       *string    `json:"last_error,omitempty"`
+	FailureCount    int        `json:"failure_count"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// WebSubSubscriptionListResp Hub 订阅分页列表。
+type WebSubSubscriptionListResp struct {
+	Items []WebSubSubscriptionResp `json:"items"`
+	Total int64                    `json:"total"`
+	Page  int                      `json:"page"`
+	Size  int                      `json:"size"`
+}
+
+// FederatedCommentResp 远端实例提交的评论及其审核状态。
+type FederatedCommentResp struct {
+	ID               int64      `json:"id"`
+	SourceInstanceID int64      `json:"source_instance_id"`
+	RemoteCommentID  string     `json:"remote_comment_id"`
+	TargetArticleID  int64      `json:"target_article_id"`
+	AuthorName       string     `json:"author_name"`
+	AuthorURL        *string    `json:"author_url,omitempty"`
+	AuthorAvatar     *string    `json:"author_avatar,omitempty"`
+	Content          string     `json:"content"`
+	Status           string     `json:"status"`
+	CommentID        *int64     `json:"comment_id,omitempty"`
+	RejectReason     *string    `json:"reject_reason,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// FederatedCommentListResp 跨站评论分页列表。
+type FederatedCommentListResp struct {
+	Items []FederatedCommentResp `json:"items"`
+	Total int64                  `json:"total"`
+	Page  int                    `json:"page"`
+	Size  int                    `json:"size"`
+}
+
+// FederationOutboundFriendLinkResp 本站发出的友链申请及其状态。
+type FederationOutboundFriendLinkResp struct {
+	ID                  int64      `json:"id"`
+	TargetURL           string     `json:"target_url"`
+	InstanceID          *int64     `json:"instance_id,omitempty"`
+	Message             *string    `json:"message,omitempty"`
+	RSSURL              *string    `json:"rss_url,omitempty"`
+	Status              string     `json:"status"`
+	ResponseStatus      *int       `json:"response_status,omitempty"`
+	ResponseBody        *string    `json:"response_body,omitempty"`
+	RemoteApplicationID *int64     `json:"remote_application_id,omitempty"`
+	RejectReason        *string    `json:"reject_reason,omitempty"`
+	FriendLinkID        *int64     `json:"friend_link_id,omitempty"`
+	DecidedAt           *time.Time `json:"decided_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// FederationOutboundFriendLinkListResp 出站友链申请分页列表。
+type FederationOutboundFriendLinkListResp struct {
+	Items []FederationOutboundFriendLinkResp `json:"items"`
+	Total int64                              `json:"total"`
+	Page  int                                `json:"page"`
+	Size  int                                `json:"size"`
+}
+
+func ToWebmentionResp(mention federation.Webmention) WebmentionResp {
+	return WebmentionResp{
+		ID:         mention.ID,
+		Source:     mention.Source,
+		Target:     mention.Target,
+		TargetType: mention.TargetType,
+		TargetID:   mention.TargetID,
+		Status:     mention.Status,
+		AuthorName: mention.AuthorName,
+		Title:      mention.Title,
+		Content:    mention.Content,
+		CommentID:  mention.CommentID,
+		Error:      mention.Error,
+		VerifiedAt: mention.VerifiedAt,
+		CreatedAt:  mention.CreatedAt,
+		UpdatedAt:  mention.UpdatedAt,
+	}
+}
+
+func ToWebmentionSendResp(send federation.WebmentionSend) WebmentionSendResp {
+	return WebmentionSendResp{
+		ID:         send.ID,
+		ArticleID:  send.ArticleID,
+		Source:     send.Source,
+		Target:     send.Target,
+		Endpoint:   send.Endpoint,
+		Status:     send.Status,
+		StatusCode: send.StatusCode,
+		Error:      send.Error,
+		SentAt:     send.SentAt,
+		UpdatedAt:  send.UpdatedAt,
+	}
+}
+
+func ToWebSubSubscriptionResp(sub federation.WebSubSubscription) WebSubSubscriptionResp {
+	return WebSubSubscriptionResp{
+		ID:              sub.ID,
+		Topic:           sub.Topic,
+		Callback:        sub.Callback,
+		HasSecret:       sub.Secret != nil && *sub.Secret != "",
+		LeaseSeconds:    sub.LeaseSeconds,
+		ExpiresAt:       sub.ExpiresAt,
+		LastDeliveredAt: sub.LastDeliveredAt,
+		LastError:       sub.LastError,
+		FailureCount:    sub.FailureCount,
+		CreatedAt:       sub.CreatedAt,
+		UpdatedAt:       sub.UpdatedAt,
+	}
+}
+
+func ToFederationInstanceResp(instance federation.FederationInstance) FederationInstanceResp {
+	return FederationInstanceResp{
+		ID:                  instance.ID,
+		BaseURL:             instance.BaseURL,
+		Name:                instance.Name,
+		Description:         instance.Description,
+		ProtocolVersion:     instance.ProtocolVersion,
+		KeyID:               instance.KeyID,
+		Status:              instance.Status,
+		BlockReason:         instance.BlockReason,
+		Features:            rawOrDefault(instance.Features, "[]"),
+		RateLimitViolations: instance.RateLimitViolations,
+		LastRateLimitedAt:   instance.LastRateLimitedAt,
+		LastSeenAt:          instance.LastSeenAt,
+		CreatedAt:           instance.CreatedAt,
+		UpdatedAt:           instance.UpdatedAt,
+	}
+}
+
+func ToFederationInstanceDetailResp(instance federation.FederationInstance) FederationInstanceDetailResp {
+	return FederationInstanceDetailResp{
+		FederationInstanceResp: ToFederationInstanceResp(instance),
+		PublicKey:              instance.PublicKey,
+		Policies:               rawOrDefault(instance.Policies, "{}"),
+		Endpoints:              rawOrDefault(instance.Endpoints, "{}"),
+		Manifest:               rawOrDefault(instance.Manifest, "{}"),
+	}
+}
+
+func ToFederationDomainRuleResp(rule federation.FederationDomainRule) FederationDomainRuleResp {
+	return FederationDomainRuleResp{
+		ID:        rule.ID,
+		Pattern:   rule.Pattern,
+		Action:    rule.Action,
+		Reason:    rule.Reason,
+		CreatedAt: rule.CreatedAt,
+	}
+}
+
+func ToFederationSigningKeyResp(key federation.FederationSigningKey) FederationSigningKeyResp {
+	return FederationSigningKeyResp{
+		ID:        key.ID,
+		KeyRef:    key.KeyRef,
+		Algorithm: key.Algorithm,
+		PublicKey: key.PublicKey,
+		CreatedAt: key.CreatedAt,
+		ExpiresAt: key.ExpiresAt,
+	}
+}
+
+func ToFederatedCommentResp(item federation.FederatedComment) FederatedCommentResp {
+	return FederatedCommentResp{
+		ID:               item.ID,
+		SourceInstanceID: item.SourceInstanceID,
+		RemoteCommentID:  item.RemoteCommentID,
+		TargetArticleID:  item.TargetArticleID,
+		AuthorName:       item.AuthorName,
+		AuthorURL:        item.AuthorURL,
+		AuthorAvatar:     item.AuthorAvatar,
+		Content:          item.Content,
+		Status:           item.Status,
+		CommentID:        item.CommentID,
+		RejectReason:     item.RejectReason,
+		ReviewedAt:       item.ReviewedAt,
+		CreatedAt:        item.CreatedAt,
+	}
+}
+
+func ToFederationOutboundFriendLinkResp(item federation.OutboundFriendLinkRequest) FederationOutboundFriendLinkResp {
+	return FederationOutboundFriendLinkResp{
+		ID:                  item.ID,
+		TargetURL:           item.TargetURL,
+		InstanceID:          item.InstanceID,
+		Message:             item.Message,
+		RSSURL:              item.RSSURL,
+		Status:              item.Status,
+		ResponseStatus:      item.ResponseStatus,
+		ResponseBody:        item.ResponseBody,
+		RemoteApplicationID: item.RemoteApplicationID,
+		RejectReason:        item.RejectReason,
+		FriendLinkID:        item.FriendLinkID,
+		DecidedAt:           item.DecidedAt,
+		CreatedAt:           item.CreatedAt,
+		UpdatedAt:           item.UpdatedAt,
+	}
+}
+
+func rawOrDefault(raw json.RawMessage, fallback string) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage(fallback)
+	}
+	return raw
+}
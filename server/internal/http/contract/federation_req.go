@@ -7,6 +7,14 @@ type FederationFriendLinkRequestReq struct {
 	RSSURL       string `json:"rss_url,omitempty"`
 }
 
+// FederationFriendLinkDecisionReq 联合友链审核结果回执（由被申请方签名发回申请方）。
+type FederationFriendLinkDecisionReq struct {
+	InstanceURL string `json:"instance_url"`
+	Status      string `json:"status"`
+	Reason      string `json:"reason,omitempty"`
+	RSSURL      string `json:"rss_url,omitempty"`
+}
+
 // FederationCitationSourcePost 引用来源文章信息。
 type FederationCitationSourcePost struct {
 	ID    string `json:"id,omitempty"`
@@ -44,3 +52,19 @@ type FederationMentionNotifyReq struct {
 	MentionContext    string                      `json:"mention_context"`
 	MentionType       string                      `json:"mention_type,omitempty"`
 }
+
+// FederationCommentAuthor 远端评论者身份。
+type FederationCommentAuthor struct {
+	Name   string `json:"name"`
+	URL    string `json:"url,omitempty"`
+	Avatar string `json:"avatar,omitempty"`
+}
+
+// FederationCommentReq 跨站评论，comment_id 为来源实例上的评论标识，用于去重。
+type FederationCommentReq struct {
+	SourceInstanceURL string                  `json:"source_instance_url"`
+	CommentID         string                  `json:"comment_id"`
+	TargetPostID      string                  `json:"target_post_id"`
+	Author            FederationCommentAuthor `json:"author"`
+	Content           string                  `json:"content"`
+}
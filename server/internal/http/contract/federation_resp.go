@@ -27,6 +27,18 @@ type FederationMentionNotifyResp struct {
 	Delivered bool  `json:"delivered"`
 }
 
+// FederationFriendLinkCallbackResp 友链审核回执响应。
+type FederationFriendLinkCallbackResp struct {
+	RequestID int64  `json:"request_id"`
+	Status    string `json:"status"`
+}
+
+// FederationCommentResp 跨站评论响应。
+type FederationCommentResp struct {
+	CommentID int64  `json:"comment_id"`
+	Status    string `json:"status"`
+}
+
 // FederationPostAuthorResp 联合时间线作者信息。
 type FederationPostAuthorResp struct {
 	Name   string  `json:"name"`
@@ -58,6 +70,12 @@ type FederationTimelineResp struct {
 	Size  int                  `json:"size"`
 }
 
+// FederationCitationPreviewResp 跨站引用预览卡片，数据来自本地缓存。
+type FederationCitationPreviewResp struct {
+	Post     FederationPostResp `json:"post"`
+	CachedAt time.Time          `json:"cached_at"`
+}
+
 // FederationPostDetailResp 文章详情响应。
 type FederationPostDetailResp struct {
 	Post         FederationPostResp   `json:"post"`
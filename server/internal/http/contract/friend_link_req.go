@@ -9,3 +9,33 @@ type FriendLinkApplicationReq struct {
 	Message     string `json:"message"`
 	RSSURL      string `json:"rssUrl"`
 }
+
+// ApproveFriendLinkApplicationReq 通过友链申请，可覆盖申请中的展示信息。
+type ApproveFriendLinkApplicationReq struct {
+	Name         *string `json:"name"`
+	Logo         *string `json:"logo"`
+	Description  *string `json:"description"`
+	RSSURL       *string `json:"rssUrl"`
+	SyncMode     *string `json:"syncMode"`
+	SyncInterval *int    `json:"syncInterval"`
+	NotifyRemote bool    `json:"notifyRemote"`
+}
+
+// RejectFriendLinkApplicationReq 拒绝友链申请。
+type RejectFriendLinkApplicationReq struct {
+	Reason       string `json:"reason"`
+	NotifyRemote bool   `json:"notifyRemote"`
+}
+
+// FriendLinkReq 创建/更新友链。
+type FriendLinkReq struct {
+	Name         string  `json:"name"`
+	URL          string  `json:"url"`
+	Logo         *string `json:"logo"`
+	Description  *string `json:"description"`
+	RSSURL       *string `json:"rssUrl"`
+	Kind         string  `json:"kind"`
+	SyncMode     string  `json:"syncMode"`
+	SyncInterval *int    `json:"syncInterval"`
+	IsActive     bool    `json:"isActive"`
+}
@@ -1,6 +1,8 @@
 package contract
 
 import (
+	"time"
+
 	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
 )
@@ -19,6 +21,8 @@ type FriendLinkApplicationResp struct {
 	UserID            *int64  `json:"userId,omitempty"`
 	Message           *string `json:"message,omitempty"`
 	Status            string  `json:"status"`
+	ReviewReason      *string `json:"reviewReason,omitempty"`
+	ReviewedAt        *string `json:"reviewedAt,omitempty"`
 	CreatedAt         string  `json:"createdAt"`
 	UpdatedAt         string  `json:"updatedAt"`
 }
@@ -38,6 +42,8 @@ func ToFriendLinkApplicationResp(app social.FriendLinkApplication) FriendLinkApp
 		UserID:            app.UserID,
 		Message:           app.Message,
 		Status:            app.Status,
+		ReviewReason:      app.ReviewReason,
+		ReviewedAt:        formatOptionalTime(app.ReviewedAt),
 		CreatedAt:         app.CreatedAt.Format(response.TimeLayout),
 		UpdatedAt:         app.UpdatedAt.Format(response.TimeLayout),
 	}
@@ -51,3 +57,192 @@ type FriendLinkApplicationRespEnvelope struct {
 	Data   FriendLinkApplicationResp `json:"data"`
 	Meta   response.Meta             `json:"meta"`
 }
+
+// FriendLinkApplicationListResp 友链申请列表。
+type FriendLinkApplicationListResp struct {
+	Items []FriendLinkApplicationResp `json:"items"`
+	Total int64                       `json:"total"`
+	Page  int                         `json:"page"`
+	Size  int                         `json:"size"`
+}
+
+// FriendLinkResp 后台友链详情。
+type FriendLinkResp struct {
+	ID               int64   `json:"id"`
+	Name             string  `json:"name"`
+	URL              string  `json:"url"`
+	Logo             *string `json:"logo,omitempty"`
+	Description      *string `json:"description,omitempty"`
+	RSSURL           *string `json:"rssUrl,omitempty"`
+	Kind             string  `json:"kind"`
+	SyncMode         string  `json:"syncMode"`
+	InstanceID       *int64  `json:"instanceId,omitempty"`
+	LastSyncAt       *string `json:"lastSyncAt,omitempty"`
+	LastSyncStatus   *string `json:"lastSyncStatus,omitempty"`
+	LastSyncError    *string `json:"lastSyncError,omitempty"`
+	SyncInterval     *int    `json:"syncInterval,omitempty"`
+	TotalPostsCached int     `json:"totalPostsCached"`
+	UserID           *int64  `json:"userId,omitempty"`
+	IsActive         bool    `json:"isActive"`
+	CreatedAt        string  `json:"createdAt"`
+	UpdatedAt        string  `json:"updatedAt"`
+}
+
+func ToFriendLinkResp(link social.FriendLink) FriendLinkResp {
+	return FriendLinkResp{
+		ID:               link.ID,
+		Name:             link.Name,
+		URL:              link.URL,
+		Logo:             link.Logo,
+		Description:      link.Description,
+		RSSURL:           link.RSSURL,
+		Kind:             link.Kind,
+		SyncMode:         link.SyncMode,
+		InstanceID:       link.InstanceID,
+		LastSyncAt:       formatOptionalTime(link.LastSyncAt),
+		LastSyncStatus:   link.LastSyncStatus,
+		LastSyncError:    link.LastSyncError,
+		SyncInterval:     link.SyncInterval,
+		TotalPostsCached: link.TotalPostsCached,
+		UserID:           link.UserID,
+		IsActive:         link.IsActive,
+		CreatedAt:        link.CreatedAt.Format(response.TimeLayout),
+		UpdatedAt:        link.UpdatedAt.Format(response.TimeLayout),
+	}
+}
+
+// FriendLinkListResp 后台友链列表。
+type FriendLinkListResp struct {
+	Items []FriendLinkResp `json:"items"`
+	Total int64            `json:"total"`
+	Page  int              `json:"page"`
+	Size  int              `json:"size"`
+}
+
+// FriendLinkPublicResp 前台展示的友链。
+type FriendLinkPublicResp struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	URL         string  `json:"url"`
+	Logo        *string `json:"logo,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Kind        string  `json:"kind"`
+}
+
+func ToFriendLinkPublicResp(link social.FriendLink) FriendLinkPublicResp {
+	return FriendLinkPublicResp{
+		ID:          link.ID,
+		Name:        link.Name,
+		URL:         link.URL,
+		Logo:        link.Logo,
+		Description: link.Description,
+		Kind:        link.Kind,
+	}
+}
+
+// FriendLinkReviewResp 友链申请审核结果。
+type FriendLinkReviewResp struct {
+	Application FriendLinkApplicationResp `json:"application"`
+	Link        *FriendLinkResp           `json:"link,omitempty"`
+	Notified    bool                      `json:"notified"`
+	NotifyError string                    `json:"notifyError,omitempty"`
+}
+
+// FriendCirclePostResp 朋友圈文章。
+type FriendCirclePostResp struct {
+	ID          int64   `json:"id"`
+	Title       string  `json:"title"`
+	URL         string  `json:"url"`
+	Summary     *string `json:"summary,omitempty"`
+	Author      *string `json:"author,omitempty"`
+	PublishedAt string  `json:"publishedAt"`
+	LinkID      int64   `json:"linkId"`
+	LinkName    string  `json:"linkName"`
+	LinkURL     string  `json:"linkUrl"`
+	LinkLogo    *string `json:"linkLogo,omitempty"`
+}
+
+// FriendCircleListResp 朋友圈文章列表。
+type FriendCircleListResp struct {
+	Items []FriendCirclePostResp `json:"items"`
+	Total int64                  `json:"total"`
+	Page  int                    `json:"page"`
+	Size  int                    `json:"size"`
+}
+
+func ToFriendCirclePostResp(post social.FriendLinkPost, link social.FriendLink) FriendCirclePostResp {
+	return FriendCirclePostResp{
+		ID:          post.ID,
+		Title:       post.Title,
+		URL:         post.URL,
+		Summary:     post.Summary,
+		Author:      post.Author,
+		PublishedAt: post.PublishedAt.Format(response.TimeLayout),
+		LinkID:      link.ID,
+		LinkName:    link.Name,
+		LinkURL:     link.URL,
+		LinkLogo:    link.Logo,
+	}
+}
+
+// FriendCircleListRespEnvelope 用于 swagger 展示朋友圈列表。
+type FriendCircleListRespEnvelope struct {
+	Code   int                  `json:"code"`
+	BizErr string               `json:"bizErr"`
+	Msg    string               `json:"msg"`
+	Data   FriendCircleListResp `json:"data"`
+	Meta   response.Meta        `json:"meta"`
+}
+
+// FriendLinkHealthResp 友链健康检查结果。
+type FriendLinkHealthResp struct {
+	LinkID              int64   `json:"linkId"`
+	LinkName            string  `json:"linkName"`
+	LinkURL             string  `json:"linkUrl"`
+	IsActive            bool    `json:"isActive"`
+	StatusCode          *int    `json:"statusCode,omitempty"`
+	LatencyMs           *int    `json:"latencyMs,omitempty"`
+	TLSExpiresAt        *string `json:"tlsExpiresAt,omitempty"`
+	HasBacklink         bool    `json:"hasBacklink"`
+	RSSStatusCode       *int    `json:"rssStatusCode,omitempty"`
+	ErrorMessage        *string `json:"errorMessage,omitempty"`
+	IsHealthy           bool    `json:"isHealthy"`
+	ConsecutiveFailures int     `json:"consecutiveFailures"`
+	LastSuccessAt       *string `json:"lastSuccessAt,omitempty"`
+	CheckedAt           string  `json:"checkedAt"`
+}
+
+func ToFriendLinkHealthResp(health social.FriendLinkHealth, link social.FriendLink) FriendLinkHealthResp {
+	return FriendLinkHealthResp{
+		LinkID:              link.ID,
+		LinkName:            link.Name,
+		LinkURL:             link.URL,
+		IsActive:            link.IsActive,
+		StatusCode:          health.StatusCode,
+		LatencyMs:           health.LatencyMs,
+		TLSExpiresAt:        formatOptionalTime(health.TLSExpiresAt),
+		HasBacklink:         health.HasBacklink,
+		RSSStatusCode:       health.RSSStatusCode,
+		ErrorMessage:        health.ErrorMessage,
+		IsHealthy:           health.IsHealthy,
+		ConsecutiveFailures: health.ConsecutiveFailures,
+		LastSuccessAt:       formatOptionalTime(health.LastSuccessAt),
+		CheckedAt:           health.CheckedAt.Format(response.TimeLayout),
+	}
+}
+
+// FriendLinkHealthListResp 友链健康检查结果列表。
+type FriendLinkHealthListResp struct {
+	Items []FriendLinkHealthResp `json:"items"`
+	Total int64                  `json:"total"`
+	Page  int                    `json:"page"`
+	Size  int                    `json:"size"`
+}
+
+func formatOptionalTime(val *time.Time) *string {
+	if val == nil {
+		return nil
+	}
+	formatted := val.Format(response.TimeLayout)
+	return &formatted
+}
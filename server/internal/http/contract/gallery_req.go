@@ -0,0 +1,38 @@
+package contract
+
+import "time"
+
+// CreatePhotoReq 从已上传的图片（uploadId）或外部地址（url）创建照片，位置与设备留空时从 EXIF 填充。
+type CreatePhotoReq struct {
+	UploadID    *int64     `json:"uploadId"`
+	URL         string     `json:"url"`
+	AlbumID     *int64     `json:"albumId"`
+	Description *string    `json:"description"`
+	Location    *string    `json:"location"`
+	Device      *string    `json:"device"`
+	TakenAt     *time.Time `json:"takenAt"`
+}
+
+type UpdatePhotoReq struct {
+	AlbumID     *int64     `json:"albumId"`
+	Description *string    `json:"description"`
+	Location    *string    `json:"location"`
+	Device      *string    `json:"device"`
+	TakenAt     *time.Time `json:"takenAt"`
+}
+
+type CreateAlbumReq struct {
+	Title       string  `json:"title" validate:"required"`
+	Description *string `json:"description"`
+}
+
+type UpdateAlbumReq struct {
+	Title        string  `json:"title" validate:"required"`
+	Description  *string `json:"description"`
+	CoverPhotoID *int64  `json:"coverPhotoId"`
+}
+
+// ReorderGalleryReq 按 ids 顺序重排相册或相册内的照片。
+type ReorderGalleryReq struct {
+	IDs []int64 `json:"ids"`
+}
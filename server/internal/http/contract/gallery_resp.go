@@ -0,0 +1,97 @@
+package contract
+
+import (
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+)
+
+type PhotoResp struct {
+	ID          int64      `json:"id"`
+	AlbumID     *int64     `json:"albumId,omitempty"`
+	CommentID   int64      `json:"commentId"`
+	URL         string     `json:"url"`
+	Width       int        `json:"width,omitempty"`
+	Height      int        `json:"height,omitempty"`
+	Blurhash    string     `json:"blurhash,omitempty"`
+	Shade       *string    `json:"shade,omitempty"`
+	Location    *string    `json:"location,omitempty"`
+	Device      *string    `json:"device,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	TakenAt     *time.Time `json:"takenAt,omitempty"`
+	SortOrder   int        `json:"sortOrder"`
+	Likes       int        `json:"likes"`
+	Liked       bool       `json:"liked"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+type PhotoListResp struct {
+	Items []PhotoResp `json:"items"`
+	Total int64       `json:"total"`
+	Page  int         `json:"page"`
+	Size  int         `json:"size"`
+}
+
+type AlbumResp struct {
+	ID           int64      `json:"id"`
+	Title        string     `json:"title"`
+	Description  *string    `json:"description,omitempty"`
+	CoverPhotoID *int64     `json:"coverPhotoId,omitempty"`
+	Cover        *PhotoResp `json:"cover,omitempty"`
+	PhotoCount   int64      `json:"photoCount"`
+	SortOrder    int        `json:"sortOrder"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+type AlbumListResp struct {
+	Items []AlbumResp `json:"items"`
+	Total int64       `json:"total"`
+	Page  int         `json:"page"`
+	Size  int         `json:"size"`
+}
+
+type PhotoLikeResp struct {
+	Liked bool `json:"liked"`
+	Likes int  `json:"likes"`
+}
+
+func ToPhotoResp(photo media.Photo) PhotoResp {
+	return PhotoResp{
+		ID:          photo.ID,
+		AlbumID:     photo.AlbumID,
+		CommentID:   photo.CommentID,
+		URL:         photo.URL,
+		Width:       photo.Width,
+		Height:      photo.Height,
+		Blurhash:    photo.Blurhash,
+		Shade:       photo.Shade,
+		Location:    photo.Location,
+		Device:      photo.Device,
+		Description: photo.Description,
+		TakenAt:     photo.TakenAt,
+		SortOrder:   photo.SortOrder,
+		Likes:       photo.Likes,
+		CreatedAt:   photo.CreatedAt,
+		UpdatedAt:   photo.UpdatedAt,
+	}
+}
+
+func ToAlbumResp(album media.Album) AlbumResp {
+	resp := AlbumResp{
+		ID:           album.ID,
+		Title:        album.Title,
+		Description:  album.Description,
+		CoverPhotoID: album.CoverPhotoID,
+		PhotoCount:   album.PhotoCount,
+		SortOrder:    album.SortOrder,
+		CreatedAt:    album.CreatedAt,
+		UpdatedAt:    album.UpdatedAt,
+	}
+	if album.Cover != nil {
+		cover := ToPhotoResp(*album.Cover)
+		resp.Cover = &cover
+	}
+	return resp
+}
@@ -8,33 +8,35 @@ import (
 
 // CreateMomentReq 创建手记请求。
 type CreateMomentReq struct {
-	Title       string     `json:"title" validate:"required,max=255"`
-	Summary     string     `json:"summary"`
-	Content     string     `json:"content" validate:"required"`
-	Image       []string   `json:"image,omitempty"`
-	ColumnID    *int64     `json:"columnId,omitempty"`
-	TopicIDs    []int64    `json:"topicIds,omitempty"`
-	ShortURL    *string    `json:"shortUrl"`
-	IsPublished bool       `json:"isPublished" validate:"required"`
-	IsTop       bool       `json:"isTop"`
-	IsHot       bool       `json:"isHot"`
-	IsOriginal  bool       `json:"isOriginal"`
-	CreatedAt   *time.Time `json:"createdAt,omitempty"` // 可以自定义发布时间
+	Title          string     `json:"title" validate:"required,max=255"`
+	Summary        string     `json:"summary"`
+	Content        string     `json:"content" validate:"required"`
+	Image          []string   `json:"image,omitempty"`
+	ColumnID       *int64     `json:"columnId,omitempty"`
+	TopicIDs       []int64    `json:"topicIds,omitempty"`
+	ShortURL       *string    `json:"shortUrl"`
+	IsPublished    bool       `json:"isPublished" validate:"required"`
+	IsTop          bool       `json:"isTop"`
+	IsHot          bool       `json:"isHot"`
+	IsOriginal     bool       `json:"isOriginal"`
+	LocalizeImages bool       `json:"localizeImages"`      // 保存前把外链图片转存到本站
+	CreatedAt      *time.Time `json:"createdAt,omitempty"` // 可以自定义发布时间
 }
 
 type createMomentReqJSON struct {
-	Title       string   `json:"title"`
-	Summary     string   `json:"summary"`
-	Content     string   `json:"content"`
-	Image       []string `json:"image"`
-	ColumnID    *int64   `json:"columnId"`
-	TopicIDs    []int64  `json:"topicIds"`
-	ShortURL    *string  `json:"shortUrl"`
-	IsPublished bool     `json:"isPublished"`
-	IsTop       bool     `json:"isTop"`
-	IsHot       bool     `json:"isHot"`
-	IsOriginal  bool     `json:"isOriginal"`
-	CreatedAt   *string  `json:"createdAt"`
+	Title          string   `json:"title"`
+	Summary        string   `json:"summary"`
+	Content        string   `json:"content"`
+	Image          []string `json:"image"`
+	ColumnID       *int64   `json:"columnId"`
+	TopicIDs       []int64  `json:"topicIds"`
+	ShortURL       *string  `json:"shortUrl"`
+	IsPublished    bool     `json:"isPublished"`
+	IsTop          bool     `json:"isTop"`
+	IsHot          bool     `json:"isHot"`
+	IsOriginal     bool     `json:"isOriginal"`
+	LocalizeImages bool     `json:"localizeImages"`
+	CreatedAt      *string  `json:"createdAt"`
 }
 
 func (r *CreateMomentReq) UnmarshalJSON(data []byte) error {
@@ -53,6 +55,7 @@ func (r *CreateMomentReq) UnmarshalJSON(data []byte) error {
 	r.IsTop = aux.IsTop
 	r.IsHot = aux.IsHot
 	r.IsOriginal = aux.IsOriginal
+	r.LocalizeImages = aux.LocalizeImages
 
 	if aux.CreatedAt == nil {
 		r.CreatedAt = nil
@@ -73,17 +76,18 @@ func (r *CreateMomentReq) UnmarshalJSON(data []byte) error {
 
 // UpdateMomentReq 更新手记请求。
 type UpdateMomentReq struct {
-	Title       string   `json:"title" validate:"required,max=255"`
-	Summary     string   `json:"summary"`
-	Content     string   `json:"content" validate:"required"`
-	Image       []string `json:"image,omitempty"`
-	ColumnID    *int64   `json:"columnId,omitempty"`
-	TopicIDs    []int64  `json:"topicIds,omitempty"`
-	ShortURL    string   `json:"shortUrl" validate:"required"`
-	IsPublished bool     `json:"isPublished"`
-	IsTop       bool     `json:"isTop"`
-	IsHot       bool     `json:"isHot"`
-	IsOriginal  bool     `json:"isOriginal"`
+	Title          string   `json:"title" validate:"required,max=255"`
+	Summary        string   `json:"summary"`
+	Content        string   `json:"content" validate:"required"`
+	Image          []string `json:"image,omitempty"`
+	ColumnID       *int64   `json:"columnId,omitempty"`
+	TopicIDs       []int64  `json:"topicIds,omitempty"`
+	ShortURL       string   `json:"shortUrl" validate:"required"`
+	IsPublished    bool     `json:"isPublished"`
+	IsTop          bool     `json:"isTop"`
+	IsHot          bool     `json:"isHot"`
+	IsOriginal     bool     `json:"isOriginal"`
+	LocalizeImages bool     `json:"localizeImages"`
 }
 
 // ListMomentsReq 手记列表查询请求。
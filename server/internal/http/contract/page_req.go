@@ -8,23 +8,25 @@ import (
 
 // CreatePageReq 创建页面请求。
 type CreatePageReq struct {
-	Title       string     `json:"title" validate:"required,max=255"`
-	Description *string    `json:"description,omitempty"`
-	Content     string     `json:"content" validate:"required"`
-	ShortURL    *string    `json:"shortUrl"`
-	IsEnabled   bool       `json:"isEnabled"`
-	IsBuiltin   bool       `json:"isBuiltin"`
-	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+	Title          string     `json:"title" validate:"required,max=255"`
+	Description    *string    `json:"description,omitempty"`
+	Content        string     `json:"content" validate:"required"`
+	ShortURL       *string    `json:"shortUrl"`
+	IsEnabled      bool       `json:"isEnabled"`
+	IsBuiltin      bool       `json:"isBuiltin"`
+	LocalizeImages bool       `json:"localizeImages"` // 保存前把外链图片转存到本站
+	CreatedAt      *time.Time `json:"createdAt,omitempty"`
 }
 
 type createPageReqJSON struct {
-	Title       string  `json:"title"`
-	Description *string `json:"description"`
-	Content     string  `json:"content"`
-	ShortURL    *string `json:"shortUrl"`
-	IsEnabled   bool    `json:"isEnabled"`
-	IsBuiltin   bool    `json:"isBuiltin"`
-	CreatedAt   *string `json:"createdAt"`
+	Title          string  `json:"title"`
+	Description    *string `json:"description"`
+	Content        string  `json:"content"`
+	ShortURL       *string `json:"shortUrl"`
+	IsEnabled      bool    `json:"isEnabled"`
+	IsBuiltin      bool    `json:"isBuiltin"`
+	LocalizeImages bool    `json:"localizeImages"`
+	CreatedAt      *string `json:"createdAt"`
 }
 
 func (r *CreatePageReq) UnmarshalJSON(data []byte) error {
@@ -38,6 +40,7 @@ func (r *CreatePageReq) UnmarshalJSON(data []byte) error {
 	r.ShortURL = aux.ShortURL
 	r.IsEnabled = aux.IsEnabled
 	r.IsBuiltin = aux.IsBuiltin
+	r.LocalizeImages = aux.LocalizeImages
 
 	if aux.CreatedAt == nil {
 		r.CreatedAt = nil
@@ -58,12 +61,13 @@ func (r *CreatePageReq) UnmarshalJSON(data []byte) error {
 
 // UpdatePageReq 更新页面请求。
 type UpdatePageReq struct {
-	Title       string  `json:"title" validate:"required,max=255"`
-	Description *string `json:"description,omitempty"`
-	Content     string  `json:"content" validate:"required"`
-	ShortURL    string  `json:"shortUrl" validate:"required"`
-	IsEnabled   bool    `json:"isEnabled"`
-	IsBuiltin   bool    `json:"isBuiltin"`
+	Title          string  `json:"title" validate:"required,max=255"`
+	Description    *string `json:"description,omitempty"`
+	Content        string  `json:"content" validate:"required"`
+	ShortURL       string  `json:"shortUrl" validate:"required"`
+	IsEnabled      bool    `json:"isEnabled"`
+	IsBuiltin      bool    `json:"isBuiltin"`
+	LocalizeImages bool    `json:"localizeImages"`
 }
 
 // ListPagesReq 页面列表查询请求。
@@ -0,0 +1,42 @@
+package contract
+
+// PasskeyLoginOptionsReq 获取通行密钥登录选项；credential 为空时使用可发现凭据。
+type PasskeyLoginOptionsReq struct {
+	Credential string `json:"credential"` // username or email
+}
+
+// PasskeyCredentialReq 是浏览器 PublicKeyCredential.toJSON() 的结果，二进制字段为 base64url。
+type PasskeyCredentialReq struct {
+	ID       string                    `json:"id"`
+	RawID    string                    `json:"rawId"`
+	Type     string                    `json:"type"`
+	Response PasskeyAuthenticatorReply `json:"response"`
+}
+
+// PasskeyAuthenticatorReply 合并了注册（attestation）与登录（assertion）两种响应的字段。
+type PasskeyAuthenticatorReply struct {
+	ClientDataJSON    string   `json:"clientDataJSON"`
+	AttestationObject string   `json:"attestationObject,omitempty"`
+	Transports        []string `json:"transports,omitempty"`
+	AuthenticatorData string   `json:"authenticatorData,omitempty"`
+	Signature         string   `json:"signature,omitempty"`
+	UserHandle        string   `json:"userHandle,omitempty"`
+}
+
+// FinishPasskeyRegistrationReq 提交通行密钥注册结果。
+type FinishPasskeyRegistrationReq struct {
+	SessionID  string               `json:"sessionId"`
+	Name       string               `json:"name"`
+	Credential PasskeyCredentialReq `json:"credential"`
+}
+
+// FinishPasskeyLoginReq 提交通行密钥登录断言。
+type FinishPasskeyLoginReq struct {
+	SessionID  string               `json:"sessionId"`
+	Credential PasskeyCredentialReq `json:"credential"`
+}
+
+// RenamePasskeyReq 修改通行密钥备注名。
+type RenamePasskeyReq struct {
+	Name string `json:"name"`
+}
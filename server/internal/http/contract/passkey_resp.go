@@ -0,0 +1,74 @@
+package contract
+
+import "time"
+
+// PasskeyResp 已注册的通行密钥。
+type PasskeyResp struct {
+	ID             int64      `json:"id"`
+	Name           string     `json:"name"`
+	Transports     []string   `json:"transports"`
+	BackupEligible bool       `json:"backupEligible"`
+	BackupState    bool       `json:"backupState"`
+	LastUsedAt     *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// PasskeyRegistrationOptionsResp 通行密钥注册选项，publicKey 可直接传给 PublicKeyCredential.parseCreationOptionsFromJSON。
+type PasskeyRegistrationOptionsResp struct {
+	SessionID string                 `json:"sessionId"`
+	PublicKey PasskeyCreationOptions `json:"publicKey"`
+}
+
+// PasskeyLoginOptionsResp 通行密钥登录选项，publicKey 可直接传给 PublicKeyCredential.parseRequestOptionsFromJSON。
+type PasskeyLoginOptionsResp struct {
+	SessionID string                `json:"sessionId"`
+	PublicKey PasskeyRequestOptions `json:"publicKey"`
+}
+
+// PasskeyCreationOptions 对应 PublicKeyCredentialCreationOptionsJSON。
+type PasskeyCreationOptions struct {
+	Challenge              string                        `json:"challenge"`
+	RP                     PasskeyRPEntity               `json:"rp"`
+	User                   PasskeyUserEntity             `json:"user"`
+	PubKeyCredParams       []PasskeyCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                         `json:"timeout"`
+	ExcludeCredentials     []PasskeyCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection PasskeyAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                        `json:"attestation"`
+}
+
+// PasskeyRequestOptions 对应 PublicKeyCredentialRequestOptionsJSON。
+type PasskeyRequestOptions struct {
+	Challenge        string                        `json:"challenge"`
+	RPID             string                        `json:"rpId"`
+	Timeout          int64                         `json:"timeout"`
+	AllowCredentials []PasskeyCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                        `json:"userVerification"`
+}
+
+type PasskeyRPEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type PasskeyUserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type PasskeyCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type PasskeyCredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type PasskeyAuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
@@ -0,0 +1,22 @@
+package contract
+
+import "time"
+
+// SessionResp 当前用户的登录会话（设备）。
+type SessionResp struct {
+	ID         int64     `json:"id"`
+	Browser    string    `json:"browser"`
+	Platform   string    `json:"platform"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	Location   string    `json:"location"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// RevokeSessionsResp 批量吊销会话的结果。
+type RevokeSessionsResp struct {
+	Revoked int64 `json:"revoked"`
+}
@@ -8,14 +8,29 @@ import (
 )
 
 type UploadFileResp struct {
-	ID         int64     `json:"id"`
-	Name       string    `json:"name"`
-	Path       string    `json:"path"`
-	PublicURL  string    `json:"publicUrl"`
-	Type       string    `json:"type"`
-	Size       int64     `json:"size"`
-	CreatedAt  time.Time `json:"createdAt"`
-	Duplicated bool      `json:"duplicated"`
+	ID            int64               `json:"id"`
+	Name          string              `json:"name"`
+	Path          string              `json:"path"`
+	PublicURL     string              `json:"publicUrl"`
+	Type          string              `json:"type"`
+	Size          int64               `json:"size"`
+	UserID        *int64              `json:"userId,omitempty"`
+	Width         int                 `json:"width,omitempty"`
+	Height        int                 `json:"height,omitempty"`
+	DominantColor string              `json:"dominantColor,omitempty"`
+	Blurhash      string              `json:"blurhash,omitempty"`
+	Variants      []UploadVariantResp `json:"variants,omitempty"`
+	CreatedAt     time.Time           `json:"createdAt"`
+	Duplicated    bool                `json:"duplicated"`
+}
+
+// UploadVariantResp 图片上传时预生成的尺寸变体，可直接用于 srcset。
+type UploadVariantResp struct {
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Format    string `json:"format"`
+	PublicURL string `json:"publicUrl"`
+	Size      int64  `json:"size"`
 }
 
 type UploadFileListResp struct {
@@ -43,23 +58,154 @@ type UploadFileListRespEnvelope struct {
 	Meta   response.Meta      `json:"meta"`
 }
 
+// UploadSignedURLResp 上传文件的限时访问地址。
+type UploadSignedURLResp struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
 type UploadRenameReq struct {
 	Name string `json:"name"`
 }
 
 func ToUploadFileResp(file media.UploadFile, duplicated bool) UploadFileResp {
-	publicURL := ""
-	if file.Path != "" {
-		publicURL = "/uploads" + file.Path
+	var variants []UploadVariantResp
+	for _, variant := range file.Variants {
+		variants = append(variants, UploadVariantResp{
+			Width:     variant.Width,
+			Height:    variant.Height,
+			Format:    variant.Format,
+			PublicURL: uploadPublicURL(variant.Path),
+			Size:      variant.Size,
+		})
 	}
 	return UploadFileResp{
-		ID:         file.ID,
-		Name:       file.Name,
-		Path:       file.Path,
-		PublicURL:  publicURL,
-		Type:       file.Type,
-		Size:       file.Size,
-		CreatedAt:  file.CreatedAt,
-		Duplicated: duplicated,
+		ID:            file.ID,
+		Name:          file.Name,
+		Path:          file.Path,
+		PublicURL:     uploadPublicURL(file.Path),
+		Type:          file.Type,
+		Size:          file.Size,
+		UserID:        file.UserID,
+		Width:         file.Width,
+		Height:        file.Height,
+		DominantColor: file.DominantColor,
+		Blurhash:      file.Blurhash,
+		Variants:      variants,
+		CreatedAt:     file.CreatedAt,
+		Duplicated:    duplicated,
 	}
 }
+
+func uploadPublicURL(storedPath string) string {
+	return media.PublicURL(storedPath)
+}
+
+// UploadUsageResp 引用上传文件的内容。
+type UploadUsageResp struct {
+	OwnerType string    `json:"ownerType"`
+	OwnerID   int64     `json:"ownerId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// UploadGCReq 垃圾回收请求，confirm 为 false 时只预演不删除。
+type UploadGCReq struct {
+	Confirm bool `json:"confirm"`
+}
+
+// UploadStrayResp 存储上没有对应上传记录的游离文件。
+type UploadStrayResp struct {
+	Driver  string    `json:"driver"`
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// UploadGCFailureResp 回收时删除失败的条目。
+type UploadGCFailureResp struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// UploadGCResp 垃圾回收结果。
+type UploadGCResp struct {
+	DryRun     bool                  `json:"dryRun"`
+	Orphans    []UploadFileResp      `json:"orphans"`
+	Strays     []UploadStrayResp     `json:"strays"`
+	FreedBytes int64                 `json:"freedBytes"`
+	Failures   []UploadGCFailureResp `json:"failures,omitempty"`
+}
+
+func ToUploadUsageResps(usages []media.Usage) []UploadUsageResp {
+	items := make([]UploadUsageResp, len(usages))
+	for i, usage := range usages {
+		items[i] = UploadUsageResp{
+			OwnerType: usage.OwnerType,
+			OwnerID:   usage.OwnerID,
+			CreatedAt: usage.CreatedAt,
+		}
+	}
+	return items
+}
+
+// UploadSessionInitReq 创建分片上传会话。
+type UploadSessionInitReq struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+	// Hash 整个文件的 SHA-256（十六进制），可选；服务器已有相同文件时直接完成。
+	Hash string `json:"hash,omitempty"`
+}
+
+// UploadSessionResp 分片上传会话进度；completed 为 true 时 file 为最终文件。
+type UploadSessionResp struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Type           string          `json:"type"`
+	Size           int64           `json:"size"`
+	ChunkSize      int64           `json:"chunkSize"`
+	TotalChunks    int             `json:"totalChunks"`
+	ReceivedChunks []int           `json:"receivedChunks"`
+	ExpiresAt      time.Time       `json:"expiresAt"`
+	Completed      bool            `json:"completed"`
+	File           *UploadFileResp `json:"file,omitempty"`
+}
+
+func ToUploadSessionResp(session media.UploadSession, received []int, file *media.UploadFile) UploadSessionResp {
+	if received == nil {
+		received = []int{}
+	}
+	resp := UploadSessionResp{
+		ID:             session.ID,
+		Name:           session.Name,
+		Type:           session.Type,
+		Size:           session.Size,
+		ChunkSize:      session.ChunkSize,
+		TotalChunks:    session.TotalChunks,
+		ReceivedChunks: received,
+		ExpiresAt:      session.ExpiresAt,
+		Completed:      session.Completed(),
+	}
+	if file != nil {
+		fileResp := ToUploadFileResp(*file, false)
+		resp.File = &fileResp
+	}
+	return resp
+}
+
+// UploadLocalizeJobResp 外链图片批量转存任务的进度。
+type UploadLocalizeJobResp struct {
+	Running    bool                        `json:"running"`
+	StartedAt  *time.Time                  `json:"startedAt,omitempty"`
+	FinishedAt *time.Time                  `json:"finishedAt,omitempty"`
+	Scanned    int                         `json:"scanned"`
+	Changed    int                         `json:"changed"`
+	Failures   []UploadLocalizeFailureResp `json:"failures,omitempty"`
+}
+
+// UploadLocalizeFailureResp 转存失败的内容。
+type UploadLocalizeFailureResp struct {
+	OwnerType string `json:"ownerType"`
+	OwnerID   int64  `json:"ownerId"`
+	Error     string `json:"error"`
+}
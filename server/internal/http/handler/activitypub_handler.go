@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/activitypub"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+// ActivityPubHandler 对外提供 WebFinger 与 ActivityPub 端点，响应体为原始 JSON-LD 而非统一响应包装。
+type ActivityPubHandler struct {
+	svc      *activitypub.Service
+	verifier *fedinfra.Verifier
+}
+
+func NewActivityPubHandler(svc *activitypub.Service, verifier *fedinfra.Verifier) *ActivityPubHandler {
+	return &ActivityPubHandler{svc: svc, verifier: verifier}
+}
+
+// WebFinger resolves acct: resources to the local actor.
+// @Summary WebFinger 查询
+// @Tags ActivityPub
+// @Produce json
+// @Param resource query string true "acct:user@host"
+// @Success 200 {object} fedinfra.WebFingerDoc
+// @Router /.well-known/webfinger [get]
+func (h *ActivityPubHandler) WebFinger(c *fiber.Ctx) error {
+	doc, err := h.svc.WebFinger(c.Context(), c.Query("resource"))
+	if err != nil {
+		return activityPubError(c, err)
+	}
+	return c.JSON(doc, fedinfra.ContentTypeJRD)
+}
+
+// Actor returns the local actor document.
+// @Summary ActivityPub Actor
+// @Tags ActivityPub
+// @Produce json
+// @Param username path string true "用户名"
+// @Success 200 {object} fedinfra.APActor
+// @Router /ap/users/{username} [get]
+func (h *ActivityPubHandler) Actor(c *fiber.Ctx) error {
+	doc, err := h.svc.Actor(c.Context(), c.Params("username"))
+	if err != nil {
+		return activityPubError(c, err)
+	}
+	return c.JSON(doc, fedinfra.ContentTypeActivityJSON)
+}
+
+// Outbox lists published articles and moments.
+// @Summary ActivityPub 发件箱
+// @Tags ActivityPub
+// @Produce json
+// @Param username path string true "用户名"
+// @Param page query int false "页码，不传时返回集合概要"
+// @Success 200 {object} fedinfra.APOrderedCollection
+// @Router /ap/users/{username}/outbox [get]
+func (h *ActivityPubHandler) Outbox(c *fiber.Ctx) error {
+	doc, err := h.svc.Outbox(c.Context(), c.Params("username"), c.QueryInt("page", 0))
+	if err != nil {
+		return activityPubError(c, err)
+	}
+	return c.JSON(doc, fedinfra.ContentTypeActivityJSON)
+}
+
+// Followers returns the follower count.
+// @Summary ActivityPub 关注者集合
+// @Tags ActivityPub
+// @Produce json
+// @Param username path string true "用户名"
+// @Success 200 {object} fedinfra.APOrderedCollection
+// @Router /ap/users/{username}/followers [get]
+func (h *ActivityPubHandler) Followers(c *fiber.Ctx) error {
+	doc, err := h.svc.Followers(c.Context(), c.Params("username"))
+	if err != nil {
+		return activityPubError(c, err)
+	}
+	return c.JSON(doc, fedinfra.ContentTypeActivityJSON)
+}
+
+// Article returns a published article as an ActivityPub Article.
+// @Summary ActivityPub 文章对象
+// @Tags ActivityPub
+// @Produce json
+// @Param shortURL path string true "文章短链接"
+// @Success 200 {object} fedinfra.APObject
+// @Router /ap/articles/{shortURL} [get]
+func (h *ActivityPubHandler) Article(c *fiber.Ctx) error {
+	return h.object(c, activitypub.ObjectTypeArticle)
+}
+
+// Moment returns a published moment as an ActivityPub Note.
+// @Summary ActivityPub 手记对象
+// @Tags ActivityPub
+// @Produce json
+// @Param shortURL path string true "手记短链接"
+// @Success 200 {object} fedinfra.APObject
+// @Router /ap/moments/{shortURL} [get]
+func (h *ActivityPubHandler) Moment(c *fiber.Ctx) error {
+	return h.object(c, activitypub.ObjectTypeMoment)
+}
+
+func (h *ActivityPubHandler) object(c *fiber.Ctx, objectType string) error {
+	doc, err := h.svc.Object(c.Context(), objectType, c.Params("shortURL"))
+	if err != nil {
+		return activityPubError(c, err)
+	}
+	return c.JSON(doc, fedinfra.ContentTypeActivityJSON)
+}
+
+// Inbox accepts signed activities for the local actor (personal and shared inbox).
+// @Summary ActivityPub 收件箱（入站）
+// @Tags ActivityPub
+// @Accept json
+// @Param username path string false "用户名（共享收件箱无此参数）"
+// @Success 202
+// @Router /ap/users/{username}/inbox [post]
+// @Router /ap/inbox [post]
+func (h *ActivityPubHandler) Inbox(c *fiber.Ctx) error {
+	body := c.Body()
+	req, err := parseFederationRequest(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	signature, err := h.verifier.VerifyRequest(c.Context(), req, body)
+	if err != nil {
+		log.Printf("[activitypub] 入站 收件箱 校验失败 ip=%s err=%v", c.IP(), err)
+		if limited := federationRateLimited(c, err); limited != nil {
+			return limited
+		}
+		if errors.Is(err, fedinfra.ErrReplayedRequest) {
+			return c.SendStatus(fiber.StatusAccepted)
+		}
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	if err := h.svc.HandleInbox(c.Context(), signature, body); err != nil {
+		log.Printf("[activitypub] 入站 收件箱 处理失败 key=%s err=%v", signature.KeyID, err)
+		return activityPubError(c, err)
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+func activityPubError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, federation.ErrActivityPubDisabled),
+		errors.Is(err, federation.ErrActorNotFound),
+		errors.Is(err, federation.ErrActivityObjectNotFound):
+		return c.SendStatus(fiber.StatusNotFound)
+	case errors.Is(err, federation.ErrActivityActorMismatch),
+		errors.Is(err, federation.ErrFederationInstanceBlocked):
+		return c.SendStatus(fiber.StatusForbidden)
+	default:
+		log.Printf("[activitypub] 请求处理失败 path=%s err=%v", c.Path(), err)
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+}
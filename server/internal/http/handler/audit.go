@@ -67,6 +67,44 @@ func buildAuditMessage(action, role string, fields map[string]any) string {
 		return "删除 OAuth 提供方" + suffixByKey(fields)
 	case "friend-link.submit":
 		return "提交友链申请"
+	case "friend-link.approve":
+		return "通过友链申请" + suffixByURL(fields)
+	case "friend-link.reject":
+		return "拒绝友链申请" + suffixByURL(fields)
+	case "friend-link.create":
+		return "创建友链" + suffixByURL(fields)
+	case "friend-link.update":
+		return "更新友链" + suffixByURL(fields)
+	case "friend-link.delete":
+		return "删除友链"
+	case "friend-link.health-check":
+		return "检查友链健康状态" + suffixByURL(fields)
+	case "federation.instance.refresh":
+		return "刷新联合实例信息" + suffixByURL(fields)
+	case "federation.instance.block":
+		return "屏蔽联合实例" + suffixByURL(fields)
+	case "federation.instance.allow":
+		return "解除屏蔽联合实例" + suffixByURL(fields)
+	case "federation.domain-rule.create":
+		return "新增联合域名规则" + suffixByPattern(fields)
+	case "federation.domain-rule.delete":
+		return "删除联合域名规则"
+	case "federation.key.rotate":
+		return "轮换联合签名密钥"
+	case "federation.comment.approve":
+		return "通过跨站评论"
+	case "federation.comment.reject":
+		return "拒绝跨站评论"
+	case "federation.comment.reply":
+		return "回复友站文章" + suffixByURL(fields)
+	case "webmention.verify":
+		return "重新校验 Webmention"
+	case "webmention.delete":
+		return "删除 Webmention"
+	case "webmention.send":
+		return "重新发送文章 Webmention"
+	case "websub.subscription.delete":
+		return "移除 WebSub 订阅"
 	default:
 		return action
 	}
@@ -91,3 +129,23 @@ func suffixByKey(fields map[string]any) string {
 	}
 	return ""
 }
+
+func suffixByURL(fields map[string]any) string {
+	if fields == nil {
+		return ""
+	}
+	if url, ok := fields["url"].(string); ok && strings.TrimSpace(url) != "" {
+		return "：" + url
+	}
+	return ""
+}
+
+func suffixByPattern(fields map[string]any) string {
+	if fields == nil {
+		return ""
+	}
+	if pattern, ok := fields["pattern"].(string); ok && strings.TrimSpace(pattern) != "" {
+		return "：" + pattern
+	}
+	return ""
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/jinzhu/copier"
@@ -87,6 +88,7 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	if err := copier.Copy(&cmd, req); err != nil {
 		return response.NewBizErrorWithMsg(response.ParamsError, "请求体映射失败")
 	}
+	cmd.Client = clientMeta(c)
 	result, err := h.svc.Login(c.Context(), cmd)
 	if err != nil {
 		if errors.Is(err, identity.ErrInvalidCredentials) {
@@ -94,15 +96,45 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		}
 		return err
 	}
-	Audit(c, "auth.login", map[string]any{
+	auditLogin(c, result)
+	return response.Success(c, toLoginResp(result))
+}
+
+// auditLogin 记录登录结果；需要两步验证时只记录挑战，第二步通过后再记录登录。
+func auditLogin(c *fiber.Ctx, result *auth.LoginResult) {
+	action := "auth.login"
+	if result.Challenge != nil {
+		action = "auth.login_challenge"
+	}
+	Audit(c, action, map[string]any{
 		"userId":   result.User.ID,
 		"username": result.User.Username,
 		"isAdmin":  result.User.IsAdmin,
 	})
-	return response.Success(c, contract.LoginResp{
-		Token: result.Token,
-		User:  contract.ToUserResp(result.User),
-	})
+}
+
+func toLoginResp(result *auth.LoginResult) contract.LoginResp {
+	resp := contract.LoginResp{
+		Token:         result.Token,
+		RefreshToken:  result.RefreshToken,
+		User:          contract.ToUserResp(result.User),
+		RecoveryCodes: result.RecoveryCodes,
+	}
+	if result.Claims != nil && result.Token != "" {
+		expiresAt := time.Unix(result.Claims.ExpiresAt, 0)
+		resp.ExpiresAt = &expiresAt
+	}
+	if !result.RefreshExpiresAt.IsZero() {
+		resp.RefreshExpiresAt = &result.RefreshExpiresAt
+	}
+	if result.Challenge != nil {
+		resp.TwoFactor = &contract.TwoFactorChallengeResp{
+			ChallengeToken: result.Challenge.Token,
+			ExpiresAt:      result.Challenge.ExpiresAt,
+			SetupRequired:  result.Challenge.SetupRequired,
+		}
+	}
+	return resp
 }
 
 func (h *AuthHandler) verifyTurnstile(c *fiber.Ctx, token string) error {
@@ -172,6 +204,7 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 		return response.NewBizErrorWithMsg(response.ParamsError, "请求体映射失败")
 	}
 	cmd.UserID = claims.UserID
+	cmd.SessionKey = claims.ID
 	if err := h.svc.ChangePassword(c.Context(), cmd); err != nil {
 		if errors.Is(err, identity.ErrInvalidCredentials) {
 			return response.NewBizError(response.InvalidCredential)
@@ -179,7 +212,7 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 		return err
 	}
 	Audit(c, "auth.change_password", map[string]any{"userId": claims.UserID})
-	return response.SuccessWithMessage[any](c, nil, "密码已更新")
+	return response.SuccessWithMessage[any](c, nil, "密码已更新，其他设备已退出登录")
 }
 
 // ListOAuthBindings 返回当前用户绑定的 OAuth 账号。
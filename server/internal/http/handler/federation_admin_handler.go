@@ -10,6 +10,7 @@ import (
 	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
 	"github.com/grtsinry43/grtblog-v2/server/internal/domain/content"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
 	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
@@ -19,14 +20,16 @@ type FederationAdminHandler struct {
 	cfgSvc      *federationconfig.Service
 	contentRepo content.Repository
 	outbound    *appfed.OutboundService
+	requests    *appfed.FriendLinkRequestService
 	resolver    *fedinfra.Resolver
 }
 
-func NewFederationAdminHandler(cfgSvc *federationconfig.Service, contentRepo content.Repository, outbound *appfed.OutboundService, resolver *fedinfra.Resolver) *FederationAdminHandler {
+func NewFederationAdminHandler(cfgSvc *federationconfig.Service, contentRepo content.Repository, outbound *appfed.OutboundService, requests *appfed.FriendLinkRequestService, resolver *fedinfra.Resolver) *FederationAdminHandler {
 	return &FederationAdminHandler{
 		cfgSvc:      cfgSvc,
 		contentRepo: contentRepo,
 		outbound:    outbound,
+		requests:    requests,
 		resolver:    resolver,
 	}
 }
@@ -50,16 +53,62 @@ func (h *FederationAdminHandler) RequestFriendLink(c *fiber.Ctx) error {
 	if target == "" {
 		return response.NewBizErrorWithMsg(response.ParamsError, "target_url 不能为空")
 	}
-	if h.outbound == nil {
+	if h.requests == nil {
 		return response.NewBizErrorWithMsg(response.ServerError, "联邦服务未初始化")
 	}
-	resp, raw, err := h.outbound.SendFriendLinkRequest(c.Context(), target, req.Message, req.RSSURL)
+	item, err := h.requests.Request(c.Context(), target, req.Message, req.RSSURL)
 	if err != nil {
+		if errors.Is(err, federation.ErrFederationInstanceBlocked) {
+			return response.NewBizErrorWithMsg(response.ParamsError, "实例已被屏蔽")
+		}
 		return response.NewBizErrorWithCause(response.ServerError, "请求失败", err)
 	}
-	return response.Success(c, contract.FederationAdminProxyResp{
-		StatusCode: resp.StatusCode,
-		Body:       string(raw),
+	resp := contract.FederationAdminProxyResp{RequestID: item.ID}
+	if item.ResponseStatus != nil {
+		resp.StatusCode = *item.ResponseStatus
+	}
+	if item.ResponseBody != nil {
+		resp.Body = *item.ResponseBody
+	}
+	return response.Success(c, resp)
+}
+
+// ListOutboundFriendLinks 获取本站发出的友链申请。
+// @Summary 获取出站友链申请列表
+// @Tags FederationAdmin
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param status query string false "状态 pending/sent/failed/approved/rejected"
+// @Success 200 {object} contract.FederationOutboundFriendLinkListResp
+// @Security BearerAuth
+// @Router /admin/federation/friendlinks/outbound [get]
+// @Security JWTAuth
+func (h *FederationAdminHandler) ListOutboundFriendLinks(c *fiber.Ctx) error {
+	if h.requests == nil {
+		return response.NewBizErrorWithMsg(response.ServerError, "联邦服务未初始化")
+	}
+	page, pageSize := parsePageQuery(c)
+	options := federation.OutboundFriendLinkListOptions{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		options.Status = &status
+	}
+	requests, total, err := h.requests.List(c.Context(), options)
+	if err != nil {
+		return err
+	}
+	items := make([]contract.FederationOutboundFriendLinkResp, len(requests))
+	for i, item := range requests {
+		items[i] = contract.ToFederationOutboundFriendLinkResp(item)
+	}
+	return response.Success(c, contract.FederationOutboundFriendLinkListResp{
+		Items: items,
+		Total: total,
+		Page:  page,
+		Size:  pageSize,
 	})
 }
 
@@ -249,6 +298,7 @@ func mapManifestResp(manifest *fedinfra.Manifest) map[string]any {
 		"policies": map[string]any{
 			"allow_citation":                   manifest.Policies.AllowCitation,
 			"allow_mention":                    manifest.Policies.AllowMention,
+			"allow_comment":                    manifest.Policies.AllowComment,
 			"auto_approve_friendlink_citation": manifest.Policies.AutoApproveFriendlinkCitation,
 			"require_https":                    manifest.Policies.RequireHTTPS,
 			"max_cache_age":                    manifest.Policies.MaxCacheAge,
@@ -263,9 +313,11 @@ func mapPublicKeyResp(doc *fedinfra.PublicKeyDoc) map[string]any {
 		return nil
 	}
 	return map[string]any{
-		"key_id":     doc.KeyID,
-		"algorithm":  doc.Algorithm,
-		"public_key": doc.PublicKey,
+		"key_id":               doc.KeyID,
+		"algorithm":            doc.Algorithm,
+		"public_key":           doc.PublicKey,
+		"supported_algorithms": doc.SupportedAlgorithms,
+		"keys":                 doc.Keys,
 	}
 }
 
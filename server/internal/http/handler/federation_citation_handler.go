@@ -68,7 +68,7 @@ func (h *FederationCitationHandler) RequestCitation(c *fiber.Ctx) error {
 	signature, err := h.verifier.VerifyRequest(c.Context(), req, body)
 	if err != nil {
 		log.Printf("[federation] 入站 引用申请 校验失败 ip=%s err=%v", c.IP(), err)
-		return response.NewBizErrorWithMsg(response.Unauthorized, "签名校验失败")
+		return federationVerifyError(c, err)
 	}
 
 	var payload contract.FederationCitationRequestReq
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+type FederationCitationPreviewHandler struct {
+	svc *appfed.PostCacheService
+}
+
+func NewFederationCitationPreviewHandler(svc *appfed.PostCacheService) *FederationCitationPreviewHandler {
+	return &FederationCitationPreviewHandler{svc: svc}
+}
+
+// GetPreview godoc
+// @Summary 获取跨站引用预览
+// @Description 仅读取本地缓存，引用在文章保存时解析
+// @Tags Federation
+// @Produce json
+// @Param instance query string true "被引用实例（URL 或域名）"
+// @Param post_id query string true "被引用文章 ID"
+// @Success 200 {object} contract.FederationCitationPreviewResp
+// @Router /federation/citations/preview [get]
+func (h *FederationCitationPreviewHandler) GetPreview(c *fiber.Ctx) error {
+	instance, postID, err := parseCitationTarget(c)
+	if err != nil {
+		return err
+	}
+	post, err := h.svc.Preview(c.Context(), instance, postID)
+	if err != nil {
+		if errors.Is(err, federation.ErrFederatedPostNotFound) {
+			return response.NewBizErrorWithMsg(response.NotFound, "引用文章尚未缓存")
+		}
+		return err
+	}
+	return response.Success(c, toCitationPreviewResp(*post))
+}
+
+// ResolvePreview godoc
+// @Summary 解析跨站引用预览
+// @Description 缓存缺失或 refresh=true 时向对方实例拉取文章详情
+// @Tags FederationAdmin
+// @Produce json
+// @Param instance query string true "被引用实例（URL 或域名）"
+// @Param post_id query string true "被引用文章 ID"
+// @Param refresh query bool false "强制刷新"
+// @Success 200 {object} contract.FederationCitationPreviewResp
+// @Security BearerAuth
+// @Router /admin/federation/citations/preview [get]
+// @Security JWTAuth
+func (h *FederationCitationPreviewHandler) ResolvePreview(c *fiber.Ctx) error {
+	instance, postID, err := parseCitationTarget(c)
+	if err != nil {
+		return err
+	}
+	post, err := h.svc.Resolve(c.Context(), instance, postID, c.QueryBool("refresh", false))
+	if err != nil {
+		switch {
+		case errors.Is(err, federation.ErrFederatedPostNotFound):
+			return response.NewBizErrorWithMsg(response.NotFound, "对方文章不存在")
+		case errors.Is(err, federation.ErrFederationInstanceBlocked):
+			return response.NewBizErrorWithMsg(response.ParamsError, "实例已被屏蔽")
+		default:
+			return response.NewBizErrorWithCause(response.ServerError, "引用解析失败", err)
+		}
+	}
+	return response.Success(c, toCitationPreviewResp(*post))
+}
+
+func parseCitationTarget(c *fiber.Ctx) (string, string, error) {
+	// Query 返回的字符串复用请求缓冲区，缓存记录会持有 post_id，这里复制一份。
+	instance := strings.Clone(strings.TrimSpace(c.Query("instance")))
+	postID := strings.Clone(strings.TrimSpace(c.Query("post_id")))
+	if instance == "" || postID == "" {
+		return "", "", response.NewBizErrorWithMsg(response.ParamsError, "instance 与 post_id 不能为空")
+	}
+	return instance, postID, nil
+}
+
+func toCitationPreviewResp(post federation.FederatedPostCache) contract.FederationCitationPreviewResp {
+	return contract.FederationCitationPreviewResp{
+		Post:     mapRemotePostToResp(post),
+		CachedAt: post.CachedAt,
+	}
+}
@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/comment"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/content"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+type FederationCommentHandler struct {
+	cfgSvc       *federationconfig.Service
+	svc          *appfed.CommentService
+	instanceRepo federation.FederationInstanceRepository
+	userRepo     identity.Repository
+	resolver     *fedinfra.Resolver
+	verifier     *fedinfra.Verifier
+}
+
+func NewFederationCommentHandler(
+	cfgSvc *federationconfig.Service,
+	svc *appfed.CommentService,
+	instanceRepo federation.FederationInstanceRepository,
+	userRepo identity.Repository,
+	resolver *fedinfra.Resolver,
+	verifier *fedinfra.Verifier,
+) *FederationCommentHandler {
+	return &FederationCommentHandler{
+		cfgSvc:       cfgSvc,
+		svc:          svc,
+		instanceRepo: instanceRepo,
+		userRepo:     userRepo,
+		resolver:     resolver,
+		verifier:     verifier,
+	}
+}
+
+// ReceiveComment handles signed comments posted by remote instances.
+// @Summary 跨站评论（入站）
+// @Tags Federation
+// @Accept json
+// @Produce json
+// @Param request body contract.FederationCommentReq true "评论参数"
+// @Success 200 {object} contract.FederationCommentResp
+// @Router /api/federation/comments [post]
+func (h *FederationCommentHandler) ReceiveComment(c *fiber.Ctx) error {
+	body := c.Body()
+	req, err := parseFederationRequest(c)
+	if err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求解析失败", err)
+	}
+
+	signature, err := h.verifier.VerifyRequest(c.Context(), req, body)
+	if err != nil {
+		log.Printf("[federation] 入站 跨站评论 校验失败 ip=%s err=%v", c.IP(), err)
+		return federationVerifyError(c, err)
+	}
+
+	var payload contract.FederationCommentReq
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	if strings.TrimSpace(payload.SourceInstanceURL) == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "source_instance_url 不能为空")
+	}
+	if strings.TrimSpace(payload.CommentID) == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "comment_id 不能为空")
+	}
+	if strings.TrimSpace(payload.TargetPostID) == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "target_post_id 不能为空")
+	}
+	if strings.TrimSpace(payload.Author.Name) == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "author.name 不能为空")
+	}
+	if strings.TrimSpace(payload.Content) == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "content 不能为空")
+	}
+	if signature != nil && signature.BaseURL != "" && !sameBaseURL(signature.BaseURL, payload.SourceInstanceURL) {
+		return response.NewBizErrorWithMsg(response.Unauthorized, "签名来源与请求不一致")
+	}
+
+	settings, err := h.cfgSvc.Settings(c.Context())
+	if err != nil || !settings.Enabled {
+		return response.NewBizErrorWithMsg(response.Unauthorized, "联合未启用")
+	}
+	policy := parseFederationPolicy(settings)
+	if !policyBool(policy.AllowComment, true) {
+		return response.NewBizErrorWithMsg(response.Unauthorized, "未允许跨站评论")
+	}
+	if !settings.AllowInbound {
+		return response.NewBizErrorWithMsg(response.Unauthorized, "已关闭入站请求")
+	}
+
+	article, err := h.svc.ResolveTarget(c.Context(), payload.TargetPostID)
+	if err != nil {
+		return mapFederatedCommentError(err)
+	}
+
+	instance, err := ensureFederationInstance(c.Context(), payload.SourceInstanceURL, h.resolver, h.instanceRepo)
+	if err != nil {
+		return err
+	}
+
+	item, err := h.svc.Receive(c.Context(), appfed.RemoteComment{
+		Instance:     instance,
+		RemoteID:     strings.TrimSpace(payload.CommentID),
+		Article:      article,
+		AuthorName:   payload.Author.Name,
+		AuthorURL:    payload.Author.URL,
+		AuthorAvatar: payload.Author.Avatar,
+		Content:      payload.Content,
+		AutoApprove:  policyBool(policy.AutoApproveRemoteComment, false),
+	})
+	if err != nil {
+		return response.NewBizErrorWithCause(response.ServerError, "创建跨站评论失败", err)
+	}
+
+	log.Printf("[federation] 入站 跨站评论 source=%s target_post=%s id=%d status=%s key_id=%s", payload.SourceInstanceURL, payload.TargetPostID, item.ID, item.Status, signature.KeyID)
+	return response.Success(c, contract.FederationCommentResp{
+		CommentID: item.ID,
+		Status:    item.Status,
+	})
+}
+
+// ListComments godoc
+// @Summary 获取跨站评论列表
+// @Tags FederationAdmin
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param status query string false "状态 pending/approved/rejected"
+// @Param target_article_id query int false "目标文章ID"
+// @Success 200 {object} contract.FederatedCommentListResp
+// @Security BearerAuth
+// @Router /admin/federation/comments [get]
+// @Security JWTAuth
+func (h *FederationCommentHandler) ListComments(c *fiber.Ctx) error {
+	page, pageSize := parsePageQuery(c)
+	options := federation.FederatedCommentListOptions{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		options.Status = &status
+	}
+	if raw := strings.TrimSpace(c.Query("target_article_id")); raw != "" {
+		articleID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return response.NewBizErrorWithMsg(response.ParamsError, "无效的文章ID")
+		}
+		options.TargetArticleID = &articleID
+	}
+
+	comments, total, err := h.svc.List(c.Context(), options)
+	if err != nil {
+		return err
+	}
+	items := make([]contract.FederatedCommentResp, len(comments))
+	for i, item := range comments {
+		items[i] = contract.ToFederatedCommentResp(item)
+	}
+	return response.Success(c, contract.FederatedCommentListResp{
+		Items: items,
+		Total: total,
+		Page:  page,
+		Size:  pageSize,
+	})
+}
+
+// ApproveComment godoc
+// @Summary 通过跨站评论
+// @Tags FederationAdmin
+// @Produce json
+// @Param id path int true "跨站评论ID"
+// @Success 200 {object} contract.FederatedCommentResp
+// @Security BearerAuth
+// @Router /admin/federation/comments/{id}/approve [post]
+// @Security JWTAuth
+func (h *FederationCommentHandler) ApproveComment(c *fiber.Ctx) error {
+	id, err := parseFederatedCommentID(c)
+	if err != nil {
+		return err
+	}
+	item, err := h.svc.Approve(c.Context(), id)
+	if err != nil {
+		return mapFederatedCommentError(err)
+	}
+	Audit(c, "federation.comment.approve", map[string]any{"id": id, "article_id": item.TargetArticleID})
+	return response.SuccessWithMessage(c, contract.ToFederatedCommentResp(*item), "评论已通过")
+}
+
+// RejectComment godoc
+// @Summary 拒绝跨站评论
+// @Tags FederationAdmin
+// @Accept json
+// @Produce json
+// @Param id path int true "跨站评论ID"
+// @Param request body contract.FederationAdminCommentRejectReq false "拒绝原因"
+// @Success 200 {object} contract.FederatedCommentResp
+// @Security BearerAuth
+// @Router /admin/federation/comments/{id}/reject [post]
+// @Security JWTAuth
+func (h *FederationCommentHandler) RejectComment(c *fiber.Ctx) error {
+	id, err := parseFederatedCommentID(c)
+	if err != nil {
+		return err
+	}
+	var req contract.FederationAdminCommentRejectReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+		}
+	}
+	item, err := h.svc.Reject(c.Context(), id, req.Reason)
+	if err != nil {
+		return mapFederatedCommentError(err)
+	}
+	Audit(c, "federation.comment.reject", map[string]any{"id": id, "article_id": item.TargetArticleID, "reason": req.Reason})
+	return response.SuccessWithMessage(c, contract.ToFederatedCommentResp(*item), "评论已拒绝")
+}
+
+// ReplyComment 由后台以本站身份评论友站文章。
+// @Summary 后台回复友站文章
+// @Tags FederationAdmin
+// @Accept json
+// @Produce json
+// @Param request body contract.FederationAdminCommentReq true "评论参数"
+// @Success 200 {object} contract.FederationAdminProxyResp
+// @Security BearerAuth
+// @Router /admin/federation/comments/reply [post]
+// @Security JWTAuth
+func (h *FederationCommentHandler) ReplyComment(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	var req contract.FederationAdminCommentReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	target := strings.TrimSpace(req.TargetInstanceURL)
+	if target == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "target_instance_url 不能为空")
+	}
+	if strings.TrimSpace(req.TargetPostID) == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "target_post_id 不能为空")
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "content 不能为空")
+	}
+
+	user, err := h.userRepo.FindByID(c.Context(), claims.UserID)
+	if err != nil {
+		return response.NewBizErrorWithCause(response.ServerError, "获取用户信息失败", err)
+	}
+	authorName := strings.TrimSpace(user.Nickname)
+	if authorName == "" {
+		authorName = user.Username
+	}
+
+	resp, raw, err := h.svc.Reply(c.Context(), appfed.ReplyCommand{
+		TargetInstance: target,
+		TargetPostID:   strings.TrimSpace(req.TargetPostID),
+		Content:        req.Content,
+		AuthorName:     authorName,
+		AuthorAvatar:   user.Avatar,
+	})
+	if err != nil {
+		if errors.Is(err, federation.ErrRemoteCommentNotAllowed) {
+			return response.NewBizErrorWithMsg(response.ParamsError, "对方文章未开放评论")
+		}
+		return response.NewBizErrorWithCause(response.ServerError, "请求失败", err)
+	}
+	Audit(c, "federation.comment.reply", map[string]any{"url": target, "target_post_id": req.TargetPostID, "status": resp.StatusCode})
+	return response.Success(c, contract.FederationAdminProxyResp{
+		StatusCode: resp.StatusCode,
+		Body:       string(raw),
+	})
+}
+
+func parseFederatedCommentID(c *fiber.Ctx) (int64, error) {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, response.NewBizErrorWithMsg(response.ParamsError, "无效的评论ID")
+	}
+	return id, nil
+}
+
+func mapFederatedCommentError(err error) error {
+	switch {
+	case errors.Is(err, content.ErrArticleNotFound):
+		return response.NewBizError(response.NotFound)
+	case errors.Is(err, federation.ErrFederatedCommentNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "跨站评论不存在")
+	case errors.Is(err, federation.ErrFederatedCommentReviewed):
+		return response.NewBizErrorWithMsg(response.ParamsError, "评论已审核")
+	case errors.Is(err, federation.ErrRemoteCommentNotAllowed),
+		errors.Is(err, comment.ErrCommentAreaNotFound):
+		return response.NewBizErrorWithMsg(response.Unauthorized, "目标文章未开放评论")
+	default:
+		return response.NewBizErrorWithCause(response.ServerError, "处理跨站评论失败", err)
+	}
+}
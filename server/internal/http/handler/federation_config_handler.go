@@ -7,6 +7,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/sysconfig"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
@@ -15,11 +16,13 @@ import (
 
 // FederationConfigHandler provides settings-center style APIs for federation_config.
 type FederationConfigHandler struct {
-	svc *federationconfig.Service
+	svc  *federationconfig.Service
+	keys *appfed.KeyService
 }
 
-func NewFederationConfigHandler(svc *federationconfig.Service) *FederationConfigHandler {
-	return &FederationConfigHandler{svc: svc}
+// NewFederationConfigHandler 创建处理器；keys 非空时，配置更新后将新生成或填写的密钥登记到密钥表。
+func NewFederationConfigHandler(svc *federationconfig.Service, keys *appfed.KeyService) *FederationConfigHandler {
+	return &FederationConfigHandler{svc: svc, keys: keys}
 }
 
 // ListFederationConfig lists federation config items.
@@ -121,6 +124,11 @@ func (h *FederationConfigHandler) UpdateFederationConfig(c *fiber.Ctx) error {
 		}
 		return err
 	}
+	if h.keys != nil {
+		if err := h.keys.SyncConfigKeys(c.Context()); err != nil {
+			return err
+		}
+	}
 	tree, err := buildSysConfigTree(updated)
 	if err != nil {
 		return response.NewBizErrorWithCause(response.ServerError, "配置解析失败", err)
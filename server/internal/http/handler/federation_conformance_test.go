@@ -0,0 +1,515 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/config"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/comment"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/content"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/federation/fedtest"
+)
+
+// localInstance runs this server's federation routes on a real listener so that
+// fake peers can fetch its well-known documents and deliver signed requests.
+type localInstance struct {
+	URL          string
+	outbound     *appfed.OutboundService
+	instances    *memInstanceRepo
+	links        *memFriendLinkRepo
+	applications *memApplicationRepo
+	citations    *memCitationRepo
+	mentions     *memMentionRepo
+	comments     *memFederatedCommentRepo
+	requests     *memOutboundFriendLinkRepo
+	friendLinks  *appfed.FriendLinkRequestService
+}
+
+func newLocalInstance(t *testing.T, policies string) *localInstance {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	baseURL := "http://" + ln.Addr().String()
+	pub, priv, err := fedinfra.GenerateKeyPair(fedinfra.AlgorithmRSASHA256)
+	if err != nil {
+		t.Fatalf("generate key pair: %v", err)
+	}
+	cfgSvc := federationconfig.NewService(newMemSysConfigRepo(map[string]string{
+		"federation.enabled":         "true",
+		"federation.instanceName":    "local",
+		"federation.instanceURL":     baseURL,
+		"federation.publicKey":       pub,
+		"federation.privateKey":      priv,
+		"federation.signatureAlg":    fedinfra.AlgorithmRSASHA256,
+		"federation.allowInbound":    "true",
+		"federation.allowOutbound":   "true",
+		"federation.defaultPolicies": policies,
+	}))
+
+	local := &localInstance{
+		URL:          baseURL,
+		instances:    &memInstanceRepo{},
+		links:        &memFriendLinkRepo{},
+		applications: &memApplicationRepo{},
+		citations:    &memCitationRepo{},
+		mentions:     &memMentionRepo{},
+		comments:     &memFederatedCommentRepo{},
+		requests:     &memOutboundFriendLinkRepo{},
+	}
+	commentArea := int64(1)
+	contentRepo := &memContentRepo{articles: []*content.Article{{
+		ID:          1,
+		Title:       "Hello federation",
+		Summary:     "first post",
+		AuthorID:    1,
+		ShortURL:    "hello",
+		CommentID:   &commentArea,
+		IsPublished: true,
+		CreatedAt:   time.Now().Add(-time.Hour),
+		UpdatedAt:   time.Now().Add(-time.Hour),
+	}}}
+	userRepo := &memUserRepo{users: []*identity.User{{ID: 1, Username: "admin", Nickname: "Admin"}}}
+
+	resolver := fedinfra.NewResolver(&http.Client{Timeout: 5 * time.Second}, nil)
+	verifier := fedinfra.NewVerifier(resolver, 5*time.Minute).WithReplayCache(fedinfra.NewMemoryReplayCache())
+	keySvc := appfed.NewKeyService(cfgSvc, &memSigningKeyRepo{})
+	if err := keySvc.SyncConfigKeys(context.Background()); err != nil {
+		t.Fatalf("sync signing keys: %v", err)
+	}
+	local.outbound = appfed.NewOutboundService(cfgSvc, keySvc, resolver, local.instances).
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second})
+	instanceSvc := appfed.NewInstanceService(local.instances, &memDomainRuleRepo{}, resolver)
+	local.friendLinks = appfed.NewFriendLinkRequestService(local.requests, local.links, instanceSvc, local.outbound)
+
+	app := newTestApp()
+	wellKnown := handler.NewFederationWellKnownHandler(cfgSvc, keySvc, config.AppConfig{Name: "grtblog"})
+	app.Get("/.well-known/blog-federation/manifest.json", wellKnown.Manifest)
+	app.Get("/.well-known/blog-federation/public-key.json", wellKnown.PublicKey)
+	app.Get("/.well-known/blog-federation/endpoints.json", wellKnown.Endpoints)
+	group := app.Group("/api/federation")
+	friendLinkHandler := handler.NewFederationFriendLinkHandler(cfgSvc, local.instances, local.links, local.applications, local.friendLinks, resolver, verifier)
+	group.Post("/friendlinks/request", friendLinkHandler.RequestFriendLink)
+	group.Post("/friendlinks/callback", friendLinkHandler.ReceiveDecision)
+	group.Get("/timeline/posts", handler.NewFederationTimelineHandler(contentRepo, userRepo, cfgSvc).ListTimelinePosts)
+	group.Post("/citations/request", handler.NewFederationCitationHandler(cfgSvc, contentRepo, local.instances, local.citations, local.links, resolver, verifier).RequestCitation)
+	group.Post("/mentions/notify", handler.NewFederationMentionHandler(cfgSvc, local.instances, local.mentions, userRepo, resolver, verifier).NotifyMention)
+	commentSvc := appfed.NewCommentService(local.comments, &memCommentRepo{areas: []*comment.CommentArea{{ID: commentArea}}}, contentRepo, local.links, local.instances, nil, nil)
+	group.Post("/comments", handler.NewFederationCommentHandler(cfgSvc, commentSvc, local.instances, userRepo, resolver, verifier).ReceiveComment)
+
+	go func() { _ = app.Listener(ln) }()
+	t.Cleanup(func() { _ = ln.Close() })
+	return local
+}
+
+func newTestApp() *fiber.App {
+	return fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			var appErr *response.AppError
+			if errors.As(err, &appErr) {
+				return response.ErrorWithMsg[any](c, appErr.Biz, appErr.Message)
+			}
+			return response.ErrorFromBiz[any](c, response.ServerError)
+		},
+	})
+}
+
+// deliver signs payload as peer and posts it to the endpoint our endpoints.json advertises for key.
+func deliver(t *testing.T, peer *fedtest.Instance, local *localInstance, key string, payload any, opts ...fedtest.SignOption) (int, response.Envelope[json.RawMessage]) {
+	t.Helper()
+	ctx := context.Background()
+	endpoint, err := peer.EndpointURL(ctx, local.URL, key)
+	if err != nil {
+		t.Fatalf("discover %s: %v", key, err)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	status, raw, err := peer.Post(ctx, endpoint, body, opts...)
+	if err != nil {
+		t.Fatalf("post %s: %v", endpoint, err)
+	}
+	var envelope response.Envelope[json.RawMessage]
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		t.Fatalf("decode response %q: %v", raw, err)
+	}
+	return status, envelope
+}
+
+func friendLinkPayload(peer *fedtest.Instance) contract.FederationFriendLinkRequestReq {
+	return contract.FederationFriendLinkRequestReq{
+		RequesterURL: peer.URL,
+		Message:      "hello from fedtest",
+		RSSURL:       peer.URL + "/feed.xml",
+	}
+}
+
+func TestFederationInboundFriendLinkRequest(t *testing.T) {
+	local := newLocalInstance(t, `{}`)
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmRSASHA256)
+
+	status, envelope := deliver(t, peer, local, fedtest.EndpointFriendLinkRequest, friendLinkPayload(peer))
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, msg = %s", status, envelope.Msg)
+	}
+	var resp contract.FederationFriendLinkResponseResp
+	if err := json.Unmarshal(envelope.Data, &resp); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if resp.Status != "pending" {
+		t.Errorf("status = %q, want pending", resp.Status)
+	}
+	if len(local.applications.apps) != 1 {
+		t.Fatalf("applications = %d, want 1", len(local.applications.apps))
+	}
+	app := local.applications.apps[0]
+	if !app.SignatureVerified || app.SignatureKeyID == nil || *app.SignatureKeyID != peer.KeyID() {
+		t.Errorf("application signature not recorded: %+v", app)
+	}
+	if len(local.links.links) != 0 {
+		t.Errorf("friend link created without approval")
+	}
+}
+
+func TestFederationInboundFriendLinkAutoApprove(t *testing.T) {
+	local := newLocalInstance(t, `{"auto_approve_friendlink":true}`)
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmEd25519)
+
+	status, envelope := deliver(t, peer, local, fedtest.EndpointFriendLinkRequest, friendLinkPayload(peer))
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, msg = %s", status, envelope.Msg)
+	}
+	if len(local.links.links) != 1 {
+		t.Fatalf("friend links = %d, want 1", len(local.links.links))
+	}
+	link := local.links.links[0]
+	if link.Kind != "federation" || link.InstanceID == nil || link.URL != peer.URL {
+		t.Errorf("unexpected friend link: %+v", link)
+	}
+}
+
+func TestFederationOutboundFriendLinkApprovedByCallback(t *testing.T) {
+	local := newLocalInstance(t, `{}`)
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmEd25519)
+	ctx := context.Background()
+
+	// 未发出申请时的回执不应建立友链。
+	decision := contract.FederationFriendLinkDecisionReq{InstanceURL: peer.URL, Status: "approved"}
+	if status, _ := deliver(t, peer, local, fedtest.EndpointFriendLinkCallback, decision); status != http.StatusNotFound {
+		t.Fatalf("unsolicited callback status = %d, want 404", status)
+	}
+
+	item, err := local.friendLinks.Request(ctx, peer.URL, "hello", local.URL+"/feed.xml")
+	if err != nil {
+		t.Fatalf("request friend link: %v", err)
+	}
+	if item.Status != "sent" || item.ResponseStatus == nil || *item.ResponseStatus != http.StatusOK {
+		t.Fatalf("unexpected request record: %+v", item)
+	}
+	if got := len(peer.Received(fedtest.EndpointFriendLinkRequest)); got != 1 {
+		t.Fatalf("peer received %d requests, want 1", got)
+	}
+
+	decision.RSSURL = peer.URL + "/feed.xml"
+	status, envelope := deliver(t, peer, local, fedtest.EndpointFriendLinkCallback, decision, fedtest.WithDate(time.Now().Add(time.Second)))
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, msg = %s", status, envelope.Msg)
+	}
+	var resp contract.FederationFriendLinkCallbackResp
+	if err := json.Unmarshal(envelope.Data, &resp); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if resp.RequestID != item.ID || resp.Status != "approved" {
+		t.Errorf("callback response = %+v", resp)
+	}
+	if len(local.links.links) != 1 {
+		t.Fatalf("friend links = %d, want 1", len(local.links.links))
+	}
+	link := local.links.links[0]
+	if link.Kind != "federation" || link.InstanceID == nil || link.URL != peer.URL {
+		t.Errorf("unexpected friend link: %+v", link)
+	}
+	stored := local.requests.items[0]
+	if stored.FriendLinkID == nil || *stored.FriendLinkID != link.ID || stored.DecidedAt == nil {
+		t.Errorf("request not resolved: %+v", stored)
+	}
+}
+
+func TestFederationInboundCitation(t *testing.T) {
+	local := newLocalInstance(t, `{}`)
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmRSASHA256)
+
+	status, envelope := deliver(t, peer, local, fedtest.EndpointCitationRequest, contract.FederationCitationRequestReq{
+		SourceInstanceURL: peer.URL,
+		SourcePost: contract.FederationCitationSourcePost{
+			ID:    "remote-1",
+			URL:   peer.URL + "/posts/remote-1",
+			Title: "Remote post",
+		},
+		TargetPostID:    "hello",
+		CitationContext: "as discussed in Hello federation",
+		CitationType:    "reference",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, msg = %s", status, envelope.Msg)
+	}
+	if len(local.citations.citations) != 1 {
+		t.Fatalf("citations = %d, want 1", len(local.citations.citations))
+	}
+	citation := local.citations.citations[0]
+	if citation.TargetArticleID != 1 || citation.Status != "pending" {
+		t.Errorf("unexpected citation: %+v", citation)
+	}
+
+	status, _ = deliver(t, peer, local, fedtest.EndpointCitationRequest, contract.FederationCitationRequestReq{
+		SourceInstanceURL: peer.URL,
+		SourcePost:        contract.FederationCitationSourcePost{URL: peer.URL + "/posts/remote-2"},
+		TargetPostID:      "missing",
+	})
+	if status != http.StatusNotFound {
+		t.Errorf("unknown target status = %d, want 404", status)
+	}
+}
+
+func TestFederationInboundMention(t *testing.T) {
+	local := newLocalInstance(t, `{}`)
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmEd25519)
+
+	status, envelope := deliver(t, peer, local, fedtest.EndpointMentionNotify, contract.FederationMentionNotifyReq{
+		SourceInstanceURL: peer.URL,
+		SourcePost: contract.FederationMentionSourcePost{
+			URL:   peer.URL + "/posts/remote-1",
+			Title: "Remote post",
+		},
+		MentionedUser:  "admin",
+		MentionContext: "thanks @admin",
+		MentionType:    "discussion",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, msg = %s", status, envelope.Msg)
+	}
+	var resp contract.FederationMentionNotifyResp
+	if err := json.Unmarshal(envelope.Data, &resp); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if !resp.Delivered || len(local.mentions.mentions) != 1 || local.mentions.mentions[0].MentionedUserID != 1 {
+		t.Errorf("mention not stored: resp=%+v mentions=%d", resp, len(local.mentions.mentions))
+	}
+}
+
+func TestFederationInboundRemoteCommentHeld(t *testing.T) {
+	local := newLocalInstance(t, `{}`)
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmEd25519)
+
+	payload := contract.FederationCommentReq{
+		SourceInstanceURL: peer.URL,
+		CommentID:         "c-1",
+		TargetPostID:      "hello",
+		Author:            contract.FederationCommentAuthor{Name: "alice", URL: peer.URL + "/about"},
+		Content:           "<p>nice post</p>",
+	}
+	// A redelivery is a fresh signed request carrying the same remote comment id.
+	for i := 0; i < 2; i++ {
+		date := fedtest.WithDate(time.Now().Add(-time.Duration(i) * time.Second))
+		status, envelope := deliver(t, peer, local, fedtest.EndpointRemoteComment, payload, date)
+		if status != http.StatusOK {
+			t.Fatalf("delivery %d status = %d, msg = %s", i, status, envelope.Msg)
+		}
+		var resp contract.FederationCommentResp
+		if err := json.Unmarshal(envelope.Data, &resp); err != nil {
+			t.Fatalf("decode data: %v", err)
+		}
+		if resp.Status != appfed.RemoteCommentPending {
+			t.Errorf("delivery %d status = %q, want %q", i, resp.Status, appfed.RemoteCommentPending)
+		}
+	}
+	if len(local.comments.comments) != 1 {
+		t.Fatalf("comments = %d, want 1", len(local.comments.comments))
+	}
+	if held := local.comments.comments[0]; held.Content != "nice post" || held.CommentID != nil {
+		t.Errorf("unexpected held comment: %+v", held)
+	}
+}
+
+func TestFederationTimelineFetch(t *testing.T) {
+	local := newLocalInstance(t, `{}`)
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmRSASHA256)
+
+	posts, total, err := peer.FetchTimeline(context.Background(), local.URL)
+	if err != nil {
+		t.Fatalf("fetch timeline: %v", err)
+	}
+	if total != 1 || len(posts) != 1 {
+		t.Fatalf("posts = %d total = %d, want 1", len(posts), total)
+	}
+	if posts[0].ID != "hello" || posts[0].URL != local.URL+"/posts/hello" || posts[0].PublishedAt.IsZero() {
+		t.Errorf("unexpected timeline post: %+v", posts[0])
+	}
+}
+
+func TestFederationInboundRejectsInvalidSignatures(t *testing.T) {
+	_, forgedKey, err := fedinfra.GenerateKeyPair(fedinfra.AlgorithmRSASHA256)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	forged, err := fedinfra.ParsePrivateKey(forgedKey)
+	if err != nil {
+		t.Fatalf("parse key: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		prepare func(peer *fedtest.Instance)
+		opts    []fedtest.SignOption
+	}{
+		{name: "signature failure", opts: []fedtest.SignOption{fedtest.WithPrivateKey(forged)}},
+		{name: "expired date", opts: []fedtest.SignOption{fedtest.WithDate(time.Now().Add(-time.Hour))}},
+		{name: "digest mismatch", opts: []fedtest.SignOption{fedtest.WithSentBody([]byte(`{"requester_url":"https://evil.example"}`))}},
+		{name: "unsupported algorithm", prepare: func(peer *fedtest.Instance) { peer.DeclareAlgorithm("rsa-sha512") }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			local := newLocalInstance(t, `{}`)
+			peer := fedtest.NewInstance(t, fedinfra.AlgorithmRSASHA256)
+			if tc.prepare != nil {
+				tc.prepare(peer)
+			}
+			status, envelope := deliver(t, peer, local, fedtest.EndpointFriendLinkRequest, friendLinkPayload(peer), tc.opts...)
+			if status != http.StatusForbidden || envelope.BizErr != response.Unauthorized.BizErr {
+				t.Fatalf("status = %d biz = %s, want 403 %s", status, envelope.BizErr, response.Unauthorized.BizErr)
+			}
+			if len(local.applications.apps) != 0 || len(local.instances.instances) != 0 {
+				t.Errorf("rejected request reached storage")
+			}
+		})
+	}
+}
+
+func TestFederationOutboundDelivery(t *testing.T) {
+	local := newLocalInstance(t, `{}`)
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmRSASHA256)
+	ctx := context.Background()
+
+	sends := []struct {
+		endpoint string
+		send     func() (*http.Response, []byte, error)
+	}{
+		{fedtest.EndpointFriendLinkRequest, func() (*http.Response, []byte, error) {
+			return local.outbound.SendFriendLinkRequest(ctx, peer.URL, "hello", local.URL+"/feed.xml")
+		}},
+		{fedtest.EndpointCitationRequest, func() (*http.Response, []byte, error) {
+			return local.outbound.SendCitation(ctx, appfed.CitationDetected{
+				Title:          "Hello federation",
+				ShortURL:       "hello",
+				TargetInstance: peer.URL,
+				TargetPostID:   "remote-1",
+				Context:        "see remote-1",
+			})
+		}},
+		{fedtest.EndpointMentionNotify, func() (*http.Response, []byte, error) {
+			return local.outbound.SendMention(ctx, appfed.MentionDetected{
+				Title:          "Hello federation",
+				ShortURL:       "hello",
+				TargetUser:     "someone",
+				TargetInstance: peer.URL,
+				Context:        "thanks @someone",
+			})
+		}},
+		{fedtest.EndpointRemoteComment, func() (*http.Response, []byte, error) {
+			return local.outbound.SendComment(ctx, appfed.CommentOutgoing{
+				TargetInstance: peer.URL,
+				TargetPostID:   "remote-1",
+				CommentID:      "reply-1",
+				AuthorName:     "Admin",
+				Content:        "great read",
+			})
+		}},
+	}
+	for _, tc := range sends {
+		t.Run(tc.endpoint, func(t *testing.T) {
+			resp, raw, err := tc.send()
+			if err != nil {
+				t.Fatalf("send: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d body = %s rejected = %v", resp.StatusCode, raw, peer.Rejected(tc.endpoint))
+			}
+			received := peer.Received(tc.endpoint)
+			if len(received) != 1 {
+				t.Fatalf("received = %d, want 1", len(received))
+			}
+			if received[0].Signature.BaseURL != local.URL {
+				t.Errorf("signed by %q, want %q", received[0].Signature.BaseURL, local.URL)
+			}
+		})
+	}
+}
+
+func TestFederationCitationPreviewCachesRemotePost(t *testing.T) {
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmRSASHA256)
+	peer.SetTimeline([]fedtest.TimelinePost{{
+		ID:          "remote-1",
+		URL:         peer.URL + "/posts/remote-1",
+		Title:       "Remote post",
+		Summary:     "cited from afar",
+		PublishedAt: time.Now().Add(-time.Hour).UTC().Truncate(time.Second),
+	}})
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	instances := appfed.NewInstanceService(&memInstanceRepo{}, &memDomainRuleRepo{}, fedinfra.NewResolver(client, nil))
+	svc := appfed.NewPostCacheService(&memPostCacheRepo{}, instances, client, 0)
+	previewHandler := handler.NewFederationCitationPreviewHandler(svc)
+	app := newTestApp()
+	app.Get("/preview", previewHandler.GetPreview)
+	app.Get("/resolve", previewHandler.ResolvePreview)
+
+	get := func(path string) (int, contract.FederationCitationPreviewResp) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, path+"instance="+peer.URL+"&post_id=remote-1", nil)
+		resp, err := app.Test(req, 10000)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var envelope response.Envelope[contract.FederationCitationPreviewResp]
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+		return resp.StatusCode, envelope.Data
+	}
+
+	if status, _ := get("/preview?"); status != http.StatusNotFound {
+		t.Fatalf("preview before resolve status = %d, want 404", status)
+	}
+	if status, preview := get("/resolve?"); status != http.StatusOK || preview.Post.Title != "Remote post" {
+		t.Fatalf("resolve status = %d, preview = %+v", status, preview)
+	}
+	if status, preview := get("/preview?"); status != http.StatusOK || preview.Post.Summary != "cited from afar" {
+		t.Fatalf("cached preview status = %d, preview = %+v", status, preview)
+	}
+	if status, _ := get("/resolve?refresh=true&"); status != http.StatusOK {
+		t.Fatalf("refresh status = %d", status)
+	}
+
+	served := peer.PostRequests("remote-1")
+	if len(served) != 2 || served[0] != http.StatusOK || served[1] != http.StatusNotModified {
+		t.Errorf("post_detail served %v, want [200 304]", served)
+	}
+}
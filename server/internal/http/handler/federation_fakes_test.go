@@ -0,0 +1,399 @@
+package handler_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/comment"
+	domainconfig "github.com/grtsinry43/grtblog-v2/server/internal/domain/config"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/content"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+)
+
+// The fakes embed the domain interfaces and only implement what the federation handlers call;
+// any other method panics, which flags handlers reaching for unexpected storage.
+
+type memSysConfigRepo struct {
+	domainconfig.SysConfigRepository
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newMemSysConfigRepo(values map[string]string) *memSysConfigRepo {
+	return &memSysConfigRepo{values: values}
+}
+
+func (r *memSysConfigRepo) GetByKey(_ context.Context, key string) (*domainconfig.SysConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &domainconfig.SysConfig{Key: key, Value: r.values[key]}, nil
+}
+
+func (r *memSysConfigRepo) List(_ context.Context, keys []string) ([]domainconfig.SysConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	items := make([]domainconfig.SysConfig, 0, len(keys))
+	for _, key := range keys {
+		if value, ok := r.values[key]; ok {
+			items = append(items, domainconfig.SysConfig{Key: key, Value: value})
+		}
+	}
+	return items, nil
+}
+
+func (r *memSysConfigRepo) Upsert(_ context.Context, configs []domainconfig.SysConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cfg := range configs {
+		r.values[cfg.Key] = cfg.Value
+	}
+	return nil
+}
+
+type memSigningKeyRepo struct {
+	federation.FederationSigningKeyRepository
+	mu   sync.Mutex
+	keys []federation.FederationSigningKey
+}
+
+func (r *memSigningKeyRepo) ListValid(_ context.Context, at time.Time) ([]federation.FederationSigningKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []federation.FederationSigningKey
+	for _, key := range r.keys {
+		if at.IsZero() || key.ExpiresAt == nil || key.ExpiresAt.After(at) {
+			out = append(out, key)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	return out, nil
+}
+
+func (r *memSigningKeyRepo) FindByPublicKey(_ context.Context, publicKey string) (*federation.FederationSigningKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.keys {
+		if r.keys[i].PublicKey == publicKey {
+			key := r.keys[i]
+			return &key, nil
+		}
+	}
+	return nil, federation.ErrSigningKeyNotFound
+}
+
+func (r *memSigningKeyRepo) Create(_ context.Context, key *federation.FederationSigningKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key.ID = int64(len(r.keys) + 1)
+	key.CreatedAt = time.Now()
+	r.keys = append(r.keys, *key)
+	return nil
+}
+
+type memInstanceRepo struct {
+	federation.FederationInstanceRepository
+	mu        sync.Mutex
+	instances []*federation.FederationInstance
+}
+
+func (r *memInstanceRepo) GetByBaseURL(_ context.Context, baseURL string) (*federation.FederationInstance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, instance := range r.instances {
+		if instance.BaseURL == baseURL {
+			return instance, nil
+		}
+	}
+	return nil, federation.ErrFederationInstanceNotFound
+}
+
+func (r *memInstanceRepo) Create(_ context.Context, instance *federation.FederationInstance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	instance.ID = int64(len(r.instances) + 1)
+	r.instances = append(r.instances, instance)
+	return nil
+}
+
+func (r *memInstanceRepo) Update(context.Context, *federation.FederationInstance) error {
+	return nil
+}
+
+func (r *memInstanceRepo) ListActive(context.Context) ([]federation.FederationInstance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []federation.FederationInstance
+	for _, instance := range r.instances {
+		if instance.Status == "active" {
+			out = append(out, *instance)
+		}
+	}
+	return out, nil
+}
+
+type memFriendLinkRepo struct {
+	social.FriendLinkRepository
+	mu    sync.Mutex
+	links []*social.FriendLink
+}
+
+func (r *memFriendLinkRepo) FindByURL(_ context.Context, url string) (*social.FriendLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, link := range r.links {
+		if link.URL == url {
+			return link, nil
+		}
+	}
+	return nil, social.ErrFriendLinkNotFound
+}
+
+func (r *memFriendLinkRepo) Create(_ context.Context, link *social.FriendLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	link.ID = int64(len(r.links) + 1)
+	r.links = append(r.links, link)
+	return nil
+}
+
+type memApplicationRepo struct {
+	social.FriendLinkApplicationRepository
+	mu   sync.Mutex
+	apps []*social.FriendLinkApplication
+}
+
+func (r *memApplicationRepo) FindByURL(_ context.Context, url string) (*social.FriendLinkApplication, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, app := range r.apps {
+		if app.URL == url {
+			return app, nil
+		}
+	}
+	return nil, social.ErrFriendLinkApplicationNotFound
+}
+
+func (r *memApplicationRepo) Create(_ context.Context, app *social.FriendLinkApplication) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	app.ID = int64(len(r.apps) + 1)
+	r.apps = append(r.apps, app)
+	return nil
+}
+
+func (r *memApplicationRepo) Update(context.Context, *social.FriendLinkApplication) error {
+	return nil
+}
+
+type memCitationRepo struct {
+	federation.FederatedCitationRepository
+	mu        sync.Mutex
+	citations []*federation.FederatedCitation
+}
+
+func (r *memCitationRepo) Create(_ context.Context, citation *federation.FederatedCitation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	citation.ID = int64(len(r.citations) + 1)
+	r.citations = append(r.citations, citation)
+	return nil
+}
+
+type memMentionRepo struct {
+	federation.FederatedMentionRepository
+	mu       sync.Mutex
+	mentions []*federation.FederatedMention
+}
+
+func (r *memMentionRepo) Create(_ context.Context, mention *federation.FederatedMention) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	mention.ID = int64(len(r.mentions) + 1)
+	r.mentions = append(r.mentions, mention)
+	return nil
+}
+
+type memFederatedCommentRepo struct {
+	federation.FederatedCommentRepository
+	mu       sync.Mutex
+	comments []*federation.FederatedComment
+}
+
+func (r *memFederatedCommentRepo) FindByRemoteID(_ context.Context, instanceID int64, remoteID string) (*federation.FederatedComment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, item := range r.comments {
+		if item.SourceInstanceID == instanceID && item.RemoteCommentID == remoteID {
+			return item, nil
+		}
+	}
+	return nil, federation.ErrFederatedCommentNotFound
+}
+
+func (r *memFederatedCommentRepo) Create(_ context.Context, item *federation.FederatedComment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item.ID = int64(len(r.comments) + 1)
+	r.comments = append(r.comments, item)
+	return nil
+}
+
+type memOutboundFriendLinkRepo struct {
+	federation.OutboundFriendLinkRepository
+	mu    sync.Mutex
+	items []*federation.OutboundFriendLinkRequest
+}
+
+func (r *memOutboundFriendLinkRepo) Create(_ context.Context, item *federation.OutboundFriendLinkRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item.ID = int64(len(r.items) + 1)
+	stored := *item
+	r.items = append(r.items, &stored)
+	return nil
+}
+
+func (r *memOutboundFriendLinkRepo) Update(_ context.Context, item *federation.OutboundFriendLinkRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.items {
+		if existing.ID == item.ID {
+			stored := *item
+			r.items[i] = &stored
+			return nil
+		}
+	}
+	return federation.ErrOutboundFriendLinkNotFound
+}
+
+func (r *memOutboundFriendLinkRepo) FindOpenByTargetURL(_ context.Context, targetURL string) (*federation.OutboundFriendLinkRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.items) - 1; i >= 0; i-- {
+		item := r.items[i]
+		if item.TargetURL == targetURL && (item.Status == "pending" || item.Status == "sent") {
+			found := *item
+			return &found, nil
+		}
+	}
+	return nil, federation.ErrOutboundFriendLinkNotFound
+}
+
+type memCommentRepo struct {
+	comment.CommentRepository
+	areas []*comment.CommentArea
+}
+
+func (r *memCommentRepo) GetAreaByID(_ context.Context, id int64) (*comment.CommentArea, error) {
+	for _, area := range r.areas {
+		if area.ID == id {
+			return area, nil
+		}
+	}
+	return nil, comment.ErrCommentAreaNotFound
+}
+
+type memDomainRuleRepo struct {
+	federation.FederationDomainRuleRepository
+}
+
+func (r *memDomainRuleRepo) List(context.Context) ([]federation.FederationDomainRule, error) {
+	return nil, nil
+}
+
+type memPostCacheRepo struct {
+	federation.FederatedPostCacheRepository
+	mu    sync.Mutex
+	posts []federation.FederatedPostCache
+}
+
+func (r *memPostCacheRepo) UpsertBatch(_ context.Context, posts []federation.FederatedPostCache) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, post := range posts {
+		replaced := false
+		for i := range r.posts {
+			if r.posts[i].URL == post.URL {
+				post.ID = r.posts[i].ID
+				r.posts[i] = post
+				replaced = true
+			}
+		}
+		if !replaced {
+			post.ID = int64(len(r.posts) + 1)
+			r.posts = append(r.posts, post)
+		}
+	}
+	return nil
+}
+
+func (r *memPostCacheRepo) GetByRemoteID(_ context.Context, instanceID int64, remotePostID string) (*federation.FederatedPostCache, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, post := range r.posts {
+		if post.InstanceID == instanceID && post.RemotePostID != nil && *post.RemotePostID == remotePostID {
+			return &post, nil
+		}
+	}
+	return nil, federation.ErrFederatedPostNotFound
+}
+
+type memContentRepo struct {
+	content.Repository
+	articles []*content.Article
+}
+
+func (r *memContentRepo) GetArticleByID(_ context.Context, id int64) (*content.Article, error) {
+	for _, article := range r.articles {
+		if article.ID == id {
+			return article, nil
+		}
+	}
+	return nil, content.ErrArticleNotFound
+}
+
+func (r *memContentRepo) GetArticleByShortURL(_ context.Context, shortURL string) (*content.Article, error) {
+	for _, article := range r.articles {
+		if article.ShortURL == shortURL {
+			return article, nil
+		}
+	}
+	return nil, content.ErrArticleNotFound
+}
+
+func (r *memContentRepo) ListPublicArticlesForFederation(_ context.Context, _ *time.Time, _ *time.Time, _ int, _ int) ([]*content.Article, int64, error) {
+	var out []*content.Article
+	for _, article := range r.articles {
+		if article.IsPublished {
+			out = append(out, article)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+type memUserRepo struct {
+	identity.Repository
+	users []*identity.User
+}
+
+func (r *memUserRepo) FindByID(_ context.Context, id int64) (*identity.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, identity.ErrUserNotFound
+}
+
+func (r *memUserRepo) FindByUsername(_ context.Context, username string) (*identity.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, identity.ErrUserNotFound
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
 	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
 	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
@@ -22,6 +23,7 @@ type FederationFriendLinkHandler struct {
 	instanceRepo    federation.FederationInstanceRepository
 	linkRepo        social.FriendLinkRepository
 	applicationRepo social.FriendLinkApplicationRepository
+	requests        *appfed.FriendLinkRequestService
 	resolver        *fedinfra.Resolver
 	verifier        *fedinfra.Verifier
 }
@@ -31,6 +33,7 @@ func NewFederationFriendLinkHandler(
 	instanceRepo federation.FederationInstanceRepository,
 	linkRepo social.FriendLinkRepository,
 	applicationRepo social.FriendLinkApplicationRepository,
+	requests *appfed.FriendLinkRequestService,
 	resolver *fedinfra.Resolver,
 	verifier *fedinfra.Verifier,
 ) *FederationFriendLinkHandler {
@@ -39,6 +42,7 @@ func NewFederationFriendLinkHandler(
 		instanceRepo:    instanceRepo,
 		linkRepo:        linkRepo,
 		applicationRepo: applicationRepo,
+		requests:        requests,
 		resolver:        resolver,
 		verifier:        verifier,
 	}
@@ -62,7 +66,7 @@ func (h *FederationFriendLinkHandler) RequestFriendLink(c *fiber.Ctx) error {
 	signature, err := h.verifier.VerifyRequest(c.Context(), req, body)
 	if err != nil {
 		log.Printf("[federation] 入站 友链申请 校验失败 ip=%s err=%v", c.IP(), err)
-		return response.NewBizErrorWithMsg(response.Unauthorized, "签名校验失败")
+		return federationVerifyError(c, err)
 	}
 
 	var payload contract.FederationFriendLinkRequestReq
@@ -126,6 +130,75 @@ func (h *FederationFriendLinkHandler) RequestFriendLink(c *fiber.Ctx) error {
 	return response.Success(c, resp)
 }
 
+// ReceiveDecision handles signed friendlink decisions for requests sent by this instance.
+// @Summary 联合友链审核回执（入站）
+// @Tags Federation
+// @Accept json
+// @Produce json
+// @Param request body contract.FederationFriendLinkDecisionReq true "审核结果"
+// @Success 200 {object} contract.FederationFriendLinkCallbackResp
+// @Router /api/federation/friendlinks/callback [post]
+func (h *FederationFriendLinkHandler) ReceiveDecision(c *fiber.Ctx) error {
+	body := c.Body()
+	req, err := parseFederationRequest(c)
+	if err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求解析失败", err)
+	}
+
+	signature, err := h.verifier.VerifyRequest(c.Context(), req, body)
+	if err != nil {
+		log.Printf("[federation] 入站 友链回执 校验失败 ip=%s err=%v", c.IP(), err)
+		return federationVerifyError(c, err)
+	}
+
+	var payload contract.FederationFriendLinkDecisionReq
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	instanceURL := strings.TrimSpace(payload.InstanceURL)
+	if instanceURL == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "instance_url 不能为空")
+	}
+	if payload.Status != appfed.OutboundFriendLinkApproved && payload.Status != appfed.OutboundFriendLinkRejected {
+		return response.NewBizErrorWithMsg(response.ParamsError, "status 仅支持 approved/rejected")
+	}
+	if signature != nil && signature.BaseURL != "" && !sameBaseURL(signature.BaseURL, instanceURL) {
+		return response.NewBizErrorWithMsg(response.Unauthorized, "签名来源与请求不一致")
+	}
+
+	// 回执是对本站出站申请的答复，不受 AllowInbound 限制。
+	settings, err := h.cfgSvc.Settings(c.Context())
+	if err != nil || !settings.Enabled {
+		return response.NewBizErrorWithMsg(response.Unauthorized, "联合未启用")
+	}
+	if h.requests == nil {
+		return response.NewBizErrorWithMsg(response.ServerError, "联邦服务未初始化")
+	}
+
+	item, err := h.requests.HandleDecision(c.Context(), appfed.FriendLinkDecision{
+		InstanceURL: instanceURL,
+		Status:      payload.Status,
+		Reason:      payload.Reason,
+		RSSURL:      payload.RSSURL,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, federation.ErrOutboundFriendLinkNotFound):
+			return response.NewBizErrorWithMsg(response.NotFound, "未找到待回执的友链申请")
+		case errors.Is(err, federation.ErrFederationInstanceBlocked):
+			return response.NewBizErrorWithMsg(response.Unauthorized, "实例已被屏蔽")
+		default:
+			return response.NewBizErrorWithCause(response.ServerError, "处理友链回执失败", err)
+		}
+	}
+
+	log.Printf("[federation] 入站 友链回执 base=%s request_id=%d status=%s key_id=%s", instanceURL, item.ID, item.Status, signature.KeyID)
+	return response.Success(c, contract.FederationFriendLinkCallbackResp{
+		RequestID: item.ID,
+		Status:    item.Status,
+	})
+}
+
 func (h *FederationFriendLinkHandler) ensureFriendLink(ctx context.Context, instance *federation.FederationInstance, rssURL string) error {
 	if instance == nil {
 		return nil
@@ -189,6 +262,8 @@ func (h *FederationFriendLinkHandler) upsertFriendLinkApplication(ctx context.Co
 	app.SignatureKeyID = toOptionalString(keyID)
 	app.SignatureVerified = true
 	app.Status = "pending"
+	app.ReviewReason = nil
+	app.ReviewedAt = nil
 	if err := h.applicationRepo.Update(ctx, app); err != nil {
 		return nil, false, err
 	}
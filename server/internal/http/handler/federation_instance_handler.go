@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+type FederationInstanceHandler struct {
+	svc *appfed.InstanceService
+}
+
+func NewFederationInstanceHandler(svc *appfed.InstanceService) *FederationInstanceHandler {
+	return &FederationInstanceHandler{svc: svc}
+}
+
+// ListInstances godoc
+// @Summary 获取联合实例列表
+// @Tags FederationAdmin
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param status query string false "状态 pending/active/blocked"
+// @Param search query string false "按地址或名称搜索"
+// @Success 200 {object} contract.FederationInstanceListResp
+// @Security BearerAuth
+// @Router /admin/federation/instances [get]
+// @Security JWTAuth
+func (h *FederationInstanceHandler) ListInstances(c *fiber.Ctx) error {
+	page, pageSize := parsePageQuery(c)
+	options := federation.InstanceListOptions{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		options.Status = &status
+	}
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		options.Search = &search
+	}
+
+	instances, total, err := h.svc.ListInstances(c.Context(), options)
+	if err != nil {
+		return err
+	}
+	items := make([]contract.FederationInstanceResp, len(instances))
+	for i, instance := range instances {
+		items[i] = contract.ToFederationInstanceResp(instance)
+	}
+	return response.Success(c, contract.FederationInstanceListResp{
+		Items: items,
+		Total: total,
+		Page:  page,
+		Size:  pageSize,
+	})
+}
+
+// GetInstance godoc
+// @Summary 获取联合实例详情
+// @Tags FederationAdmin
+// @Produce json
+// @Param id path int true "实例ID"
+// @Success 200 {object} contract.FederationInstanceDetailResp
+// @Security BearerAuth
+// @Router /admin/federation/instances/{id} [get]
+// @Security JWTAuth
+func (h *FederationInstanceHandler) GetInstance(c *fiber.Ctx) error {
+	id, err := parseInstanceID(c)
+	if err != nil {
+		return err
+	}
+	instance, err := h.svc.GetInstance(c.Context(), id)
+	if err != nil {
+		return mapFederationInstanceError(err)
+	}
+	return response.Success(c, contract.ToFederationInstanceDetailResp(*instance))
+}
+
+// RefreshInstance godoc
+// @Summary 重新拉取联合实例元数据
+// @Tags FederationAdmin
+// @Produce json
+// @Param id path int true "实例ID"
+// @Success 200 {object} contract.FederationInstanceDetailResp
+// @Security BearerAuth
+// @Router /admin/federation/instances/{id}/refresh [post]
+// @Security JWTAuth
+func (h *FederationInstanceHandler) RefreshInstance(c *fiber.Ctx) error {
+	id, err := parseInstanceID(c)
+	if err != nil {
+		return err
+	}
+	instance, err := h.svc.RefreshInstance(c.Context(), id)
+	if err != nil {
+		if errors.Is(err, federation.ErrFederationInstanceNotFound) {
+			return mapFederationInstanceError(err)
+		}
+		return response.NewBizErrorWithCause(response.ServerError, "拉取远端元数据失败", err)
+	}
+	Audit(c, "federation.instance.refresh", map[string]any{"id": id, "url": instance.BaseURL})
+	return response.Success(c, contract.ToFederationInstanceDetailResp(*instance))
+}
+
+// BlockInstance godoc
+// @Summary 屏蔽联合实例
+// @Tags FederationAdmin
+// @Accept json
+// @Produce json
+// @Param id path int true "实例ID"
+// @Param request body contract.FederationAdminBlockInstanceReq false "屏蔽原因"
+// @Success 200 {object} contract.FederationInstanceResp
+// @Security BearerAuth
+// @Router /admin/federation/instances/{id}/block [post]
+// @Security JWTAuth
+func (h *FederationInstanceHandler) BlockInstance(c *fiber.Ctx) error {
+	id, err := parseInstanceID(c)
+	if err != nil {
+		return err
+	}
+	var req contract.FederationAdminBlockInstanceReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+		}
+	}
+	instance, err := h.svc.BlockInstance(c.Context(), id, req.Reason)
+	if err != nil {
+		return mapFederationInstanceError(err)
+	}
+	Audit(c, "federation.instance.block", map[string]any{"id": id, "url": instance.BaseURL, "reason": req.Reason})
+	return response.SuccessWithMessage(c, contract.ToFederationInstanceResp(*instance), "实例已屏蔽")
+}
+
+// AllowInstance godoc
+// @Summary 解除屏蔽联合实例
+// @Tags FederationAdmin
+// @Produce json
+// @Param id path int true "实例ID"
+// @Success 200 {object} contract.FederationInstanceResp
+// @Security BearerAuth
+// @Router /admin/federation/instances/{id}/allow [post]
+// @Security JWTAuth
+func (h *FederationInstanceHandler) AllowInstance(c *fiber.Ctx) error {
+	id, err := parseInstanceID(c)
+	if err != nil {
+		return err
+	}
+	instance, err := h.svc.AllowInstance(c.Context(), id)
+	if err != nil {
+		return mapFederationInstanceError(err)
+	}
+	Audit(c, "federation.instance.allow", map[string]any{"id": id, "url": instance.BaseURL})
+	return response.SuccessWithMessage(c, contract.ToFederationInstanceResp(*instance), "实例已解除屏蔽")
+}
+
+// ListDomainRules godoc
+// @Summary 获取联合域名黑白名单
+// @Tags FederationAdmin
+// @Produce json
+// @Success 200 {array} contract.FederationDomainRuleResp
+// @Security BearerAuth
+// @Router /admin/federation/domain-rules [get]
+// @Security JWTAuth
+func (h *FederationInstanceHandler) ListDomainRules(c *fiber.Ctx) error {
+	rules, err := h.svc.ListDomainRules(c.Context())
+	if err != nil {
+		return err
+	}
+	items := make([]contract.FederationDomainRuleResp, len(rules))
+	for i, rule := range rules {
+		items[i] = contract.ToFederationDomainRuleResp(rule)
+	}
+	return response.Success(c, items)
+}
+
+// CreateDomainRule godoc
+// @Summary 新增联合域名规则
+// @Tags FederationAdmin
+// @Accept json
+// @Produce json
+// @Param request body contract.FederationAdminDomainRuleReq true "规则参数"
+// @Success 200 {object} contract.FederationDomainRuleResp
+// @Security BearerAuth
+// @Router /admin/federation/domain-rules [post]
+// @Security JWTAuth
+func (h *FederationInstanceHandler) CreateDomainRule(c *fiber.Ctx) error {
+	var req contract.FederationAdminDomainRuleReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	rule, err := h.svc.CreateDomainRule(c.Context(), req.Pattern, req.Action, req.Reason)
+	if err != nil {
+		return mapFederationInstanceError(err)
+	}
+	Audit(c, "federation.domain-rule.create", map[string]any{"id": rule.ID, "pattern": rule.Pattern, "action": rule.Action})
+	return response.SuccessWithMessage(c, contract.ToFederationDomainRuleResp(*rule), "规则已添加")
+}
+
+// DeleteDomainRule godoc
+// @Summary 删除联合域名规则
+// @Tags FederationAdmin
+// @Produce json
+// @Param id path int true "规则ID"
+// @Success 200 {object} any
+// @Security BearerAuth
+// @Router /admin/federation/domain-rules/{id} [delete]
+// @Security JWTAuth
+func (h *FederationInstanceHandler) DeleteDomainRule(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的规则ID")
+	}
+	if err := h.svc.DeleteDomainRule(c.Context(), id); err != nil {
+		return mapFederationInstanceError(err)
+	}
+	Audit(c, "federation.domain-rule.delete", map[string]any{"id": id})
+	return response.SuccessWithMessage[any](c, nil, "规则已删除")
+}
+
+func parseInstanceID(c *fiber.Ctx) (int64, error) {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, response.NewBizErrorWithMsg(response.ParamsError, "无效的实例ID")
+	}
+	return id, nil
+}
+
+func mapFederationInstanceError(err error) error {
+	switch {
+	case errors.Is(err, federation.ErrFederationInstanceNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "联合实例不存在")
+	case errors.Is(err, federation.ErrDomainRuleNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "域名规则不存在")
+	case errors.Is(err, federation.ErrDomainRuleExists):
+		return response.NewBizErrorWithMsg(response.ParamsError, "域名规则已存在")
+	case errors.Is(err, federation.ErrInvalidDomainPattern):
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的域名规则")
+	default:
+		return err
+	}
+}
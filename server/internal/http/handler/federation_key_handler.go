@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+type FederationKeyHandler struct {
+	svc *appfed.KeyService
+}
+
+func NewFederationKeyHandler(svc *appfed.KeyService) *FederationKeyHandler {
+	return &FederationKeyHandler{svc: svc}
+}
+
+// ListKeys godoc
+// @Summary 获取本站联合签名密钥
+// @Tags FederationAdmin
+// @Produce json
+// @Success 200 {array} contract.FederationSigningKeyResp
+// @Security BearerAuth
+// @Router /admin/federation/keys [get]
+// @Security JWTAuth
+func (h *FederationKeyHandler) ListKeys(c *fiber.Ctx) error {
+	keys, err := h.svc.ListKeys(c.Context())
+	if err != nil {
+		return err
+	}
+	items := make([]contract.FederationSigningKeyResp, len(keys))
+	for i, key := range keys {
+		items[i] = contract.ToFederationSigningKeyResp(key)
+	}
+	return response.Success(c, items)
+}
+
+// RotateKey godoc
+// @Summary 轮换联合签名密钥
+// @Tags FederationAdmin
+// @Accept json
+// @Produce json
+// @Param request body contract.FederationAdminRotateKeyReq true "轮换参数"
+// @Success 200 {object} contract.FederationSigningKeyResp
+// @Security BearerAuth
+// @Router /admin/federation/keys/rotate [post]
+// @Security JWTAuth
+func (h *FederationKeyHandler) RotateKey(c *fiber.Ctx) error {
+	var req contract.FederationAdminRotateKeyReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	if req.GraceHours < 0 {
+		return response.NewBizErrorWithMsg(response.ParamsError, "宽限期不能为负数")
+	}
+	key, err := h.svc.Rotate(c.Context(), req.Algorithm, time.Duration(req.GraceHours)*time.Hour)
+	if err != nil {
+		if errors.Is(err, fedinfra.ErrUnsupportedSignatureAlgorithm) {
+			return response.NewBizErrorWithMsg(response.ParamsError, "仅支持 rsa-sha256 或 ed25519")
+		}
+		return err
+	}
+	Audit(c, "federation.key.rotate", map[string]any{"id": key.ID, "algorithm": key.Algorithm, "key_ref": key.KeyRef})
+	return response.SuccessWithMessage(c, contract.ToFederationSigningKeyResp(*key), "密钥已轮换")
+}
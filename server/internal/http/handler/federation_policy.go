@@ -11,6 +11,8 @@ type federationPolicy struct {
 	AllowMention                  *bool `json:"allow_mention"`
 	AutoApproveFriendlink         *bool `json:"auto_approve_friendlink"`
 	AutoApproveFriendlinkCitation *bool `json:"auto_approve_friendlink_citation"`
+	AllowComment                  *bool `json:"allow_comment"`
+	AutoApproveRemoteComment      *bool `json:"auto_approve_remote_comment"`
 }
 
 func parseFederationPolicy(settings federationconfig.Settings) federationPolicy {
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -65,6 +68,14 @@ func (h *FederationPostHandler) GetPostDetail(c *fiber.Ctx) error {
 		return response.NewBizError(response.NotFound)
 	}
 
+	// 供对端以条件请求刷新缓存；相关文章的变化不计入校验值。
+	etag := fmt.Sprintf(`W/"%d-%d"`, article.ID, article.UpdatedAt.Unix())
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, article.UpdatedAt.UTC().Format(http.TimeFormat))
+	if postNotModified(c, etag, article.UpdatedAt) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	baseURL := resolveFederationBaseURL(c, h.cfgSvc)
 	post := h.buildPostResp(c.Context(), baseURL, article)
 
@@ -77,6 +88,23 @@ func (h *FederationPostHandler) GetPostDetail(c *fiber.Ctx) error {
 	return response.Success(c, resp)
 }
 
+func postNotModified(c *fiber.Ctx, etag string, modified time.Time) bool {
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			if strings.TrimSpace(candidate) == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if since := c.Get(fiber.HeaderIfModifiedSince); since != "" {
+		if parsed, err := http.ParseTime(since); err == nil {
+			return !modified.Truncate(time.Second).After(parsed)
+		}
+	}
+	return false
+}
+
 func (h *FederationPostHandler) resolveArticle(c *fiber.Ctx, rawID string) (*content.Article, error) {
 	if numericID, err := strconv.ParseInt(rawID, 10, 64); err == nil {
 		return h.contentRepo.GetArticleByID(c.Context(), numericID)
@@ -111,7 +139,7 @@ func (h *FederationPostHandler) buildPostResp(ctx context.Context, baseURL strin
 		CoverImage:    article.Cover,
 		Language:      nil,
 		AllowCitation: true,
-		AllowComment:  true,
+		AllowComment:  article.CommentID != nil,
 	}
 }
 
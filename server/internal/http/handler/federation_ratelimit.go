@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+// FederationRateLimit 为无签名的联合端点按来源 IP 限流；签名端点在校验通过后按实例限流。
+func FederationRateLimit(limiter *appfed.RateLimitService, endpoint string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if limiter == nil {
+			return c.Next()
+		}
+		if err := limiter.AllowIP(c.Context(), c.IP(), endpoint); err != nil {
+			if limited := federationRateLimited(c, err); limited != nil {
+				return limited
+			}
+			return err
+		}
+		return c.Next()
+	}
+}
+
+// federationRateLimited 命中限流时写入 Retry-After 并返回 429，否则返回 nil。
+func federationRateLimited(c *fiber.Ctx, err error) error {
+	var limitErr *fedinfra.RateLimitError
+	if !errors.As(err, &limitErr) {
+		return nil
+	}
+	seconds := int(math.Ceil(limitErr.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return response.NewBizErrorWithMsg(response.TooManyRequests, "")
+}
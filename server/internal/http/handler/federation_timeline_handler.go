@@ -95,7 +95,7 @@ func (h *FederationTimelineHandler) ListTimelinePosts(c *fiber.Ctx) error {
 			CoverImage:    article.Cover,
 			Language:      nil,
 			AllowCitation: true,
-			AllowComment:  true,
+			AllowComment:  article.CommentID != nil,
 		}
 	}
 
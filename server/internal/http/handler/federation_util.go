@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gofiber/fiber/v2"
+
 	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
 	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
@@ -55,6 +57,7 @@ func ensureInstanceFromDocs(
 	features := toJSON(manifest.Features)
 	policies := toJSON(manifest.Policies)
 	endpointsPayload := toJSON(endpoints)
+	manifestPayload := toJSON(manifest)
 
 	instance, err := instanceRepo.GetByBaseURL(ctx, baseURL)
 	if err != nil {
@@ -71,6 +74,7 @@ func ensureInstanceFromDocs(
 			Features:        features,
 			Policies:        policies,
 			Endpoints:       endpointsPayload,
+			Manifest:        manifestPayload,
 			Status:          "pending",
 			LastSeenAt:      timePtr(time.Now().UTC()),
 		}
@@ -88,6 +92,7 @@ func ensureInstanceFromDocs(
 	instance.Features = features
 	instance.Policies = policies
 	instance.Endpoints = endpointsPayload
+	instance.Manifest = manifestPayload
 	instance.LastSeenAt = timePtr(time.Now().UTC())
 	if err := instanceRepo.Update(ctx, instance); err != nil {
 		return nil, err
@@ -95,6 +100,20 @@ func ensureInstanceFromDocs(
 	return instance, nil
 }
 
+// federationVerifyError 将签名校验失败转换为对外错误，被屏蔽、重放与被限流的请求单独提示。
+func federationVerifyError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, federation.ErrFederationInstanceBlocked) {
+		return response.NewBizErrorWithMsg(response.Unauthorized, "实例已被屏蔽")
+	}
+	if errors.Is(err, fedinfra.ErrReplayedRequest) {
+		return response.NewBizError(response.ReplayedRequest)
+	}
+	if limited := federationRateLimited(c, err); limited != nil {
+		return limited
+	}
+	return response.NewBizErrorWithMsg(response.Unauthorized, "签名校验失败")
+}
+
 func toOptionalString(val string) *string {
 	trimmed := strings.TrimSpace(val)
 	if trimmed == "" {
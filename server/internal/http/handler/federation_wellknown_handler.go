@@ -9,6 +9,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
 	"github.com/grtsinry43/grtblog-v2/server/internal/config"
 	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
@@ -16,11 +17,12 @@ import (
 
 type FederationWellKnownHandler struct {
 	cfgSvc *federationconfig.Service
+	keySvc *appfed.KeyService
 	appCfg config.AppConfig
 }
 
-func NewFederationWellKnownHandler(cfgSvc *federationconfig.Service, appCfg config.AppConfig) *FederationWellKnownHandler {
-	return &FederationWellKnownHandler{cfgSvc: cfgSvc, appCfg: appCfg}
+func NewFederationWellKnownHandler(cfgSvc *federationconfig.Service, keySvc *appfed.KeyService, appCfg config.AppConfig) *FederationWellKnownHandler {
+	return &FederationWellKnownHandler{cfgSvc: cfgSvc, keySvc: keySvc, appCfg: appCfg}
 }
 
 func (h *FederationWellKnownHandler) Manifest(c *fiber.Ctx) error {
@@ -37,6 +39,9 @@ func (h *FederationWellKnownHandler) Manifest(c *fiber.Ctx) error {
 	if policyBool(policy.AllowMention, true) {
 		features = append(features, "cross-mention")
 	}
+	if policyBool(policy.AllowComment, true) {
+		features = append(features, "cross-comment")
+	}
 	manifest := fedinfra.Manifest{
 		ProtocolVersion: "1.0.0",
 		Instance: fedinfra.ManifestNode{
@@ -52,11 +57,12 @@ func (h *FederationWellKnownHandler) Manifest(c *fiber.Ctx) error {
 		Policies: fedinfra.ManifestPolicy{
 			AllowCitation:                 policyBool(policy.AllowCitation, true),
 			AllowMention:                  policyBool(policy.AllowMention, true),
+			AllowComment:                  policyBool(policy.AllowComment, true),
 			AutoApproveFriendlinkCitation: policyBool(policy.AutoApproveFriendlinkCitation, false),
 			RequireHTTPS:                  settings.RequireHTTPS,
 			MaxCacheAge:                   86400,
 		},
-		RateLimits: fedinfra.ManifestRate{},
+		RateLimits: manifestRateLimits(settings),
 		CreatedAt:  time.Now().UTC(),
 		UpdatedAt:  time.Now().UTC(),
 	}
@@ -69,12 +75,29 @@ func (h *FederationWellKnownHandler) PublicKey(c *fiber.Ctx) error {
 	if err != nil || !settings.Enabled || strings.TrimSpace(settings.PublicKey) == "" {
 		return c.SendStatus(fiber.StatusNotFound)
 	}
-	keyID := h.publicKeyID(c, settings)
+	documentURL := h.publicKeyID(c, settings)
+	keys, err := h.keySvc.PublishedKeys(c.Context(), documentURL, settings.SignatureAlg)
+	if err != nil {
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	// 顶层字段固定为最新的 RSA 密钥，保证只认单密钥文档的旧实例仍可校验；
+	// keys 列出宽限期内的全部密钥，首选算法在前。
 	doc := fedinfra.PublicKeyDoc{
-		KeyID:     keyID,
-		Algorithm: settings.SignatureAlg,
-		PublicKey: settings.PublicKey,
-		CreatedAt: time.Now().UTC(),
+		KeyID:               documentURL,
+		Algorithm:           fedinfra.AlgorithmRSASHA256,
+		PublicKey:           settings.PublicKey,
+		CreatedAt:           time.Now().UTC(),
+		SupportedAlgorithms: fedinfra.SupportedAlgorithms,
+		Keys:                keys,
+	}
+	for _, key := range keys {
+		if key.Algorithm == fedinfra.AlgorithmRSASHA256 {
+			doc.KeyID = key.KeyID
+			doc.PublicKey = key.PublicKey
+			doc.CreatedAt = key.CreatedAt
+			doc.ExpiresAt = key.ExpiresAt
+			break
+		}
 	}
 	return c.JSON(doc)
 }
@@ -88,11 +111,13 @@ func (h *FederationWellKnownHandler) Endpoints(c *fiber.Ctx) error {
 	doc := fedinfra.EndpointsDoc{
 		BaseURL: baseURL,
 		Endpoints: map[string]string{
-			"friendlink_request": "/friendlinks/request",
-			"timeline":           "/timeline/posts",
-			"post_detail":        "/posts/{id}",
-			"citation_request":   "/citations/request",
-			"mention_notify":     "/mentions/notify",
+			"friendlink_request":  "/friendlinks/request",
+			"friendlink_callback": "/friendlinks/callback",
+			"timeline":            "/timeline/posts",
+			"post_detail":         "/posts/{id}",
+			"citation_request":    "/citations/request",
+			"mention_notify":      "/mentions/notify",
+			"remote_comment":      "/comments",
 		},
 	}
 	return c.JSON(doc)
@@ -149,3 +174,13 @@ func buildVersion() string {
 	}
 	return "dev"
 }
+
+// manifestRateLimits 对外公布各端点每小时的入站配额。
+func manifestRateLimits(settings federationconfig.Settings) fedinfra.ManifestRate {
+	limits := appfed.ParseRateLimitConfig(settings.RateLimits)
+	return fedinfra.ManifestRate{
+		TimelineSync:    limits.PerHour(appfed.EndpointTimelineSync),
+		CitationRequest: limits.PerHour(appfed.EndpointCitationRequest),
+		MentionNotify:   limits.PerHour(appfed.EndpointMentionNotify),
+	}
+}
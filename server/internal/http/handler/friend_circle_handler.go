@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/friendlink"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+type FriendCircleHandler struct {
+	svc *friendlink.CircleService
+}
+
+func NewFriendCircleHandler(svc *friendlink.CircleService) *FriendCircleHandler {
+	return &FriendCircleHandler{svc: svc}
+}
+
+// List godoc
+// @Summary 获取朋友圈（友链最新文章）
+// @Tags FriendLink
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(20)
+// @Success 200 {object} contract.FriendCircleListRespEnvelope
+// @Router /public/friend-circle [get]
+func (h *FriendCircleHandler) List(c *fiber.Ctx) error {
+	page := 1
+	pageSize := 20
+	if parsed, err := strconv.Atoi(c.Query("page", "1")); err == nil && parsed > 0 {
+		page = parsed
+	}
+	if parsed, err := strconv.Atoi(c.Query("pageSize", "20")); err == nil && parsed > 0 && parsed <= 100 {
+		pageSize = parsed
+	}
+
+	entries, total, err := h.svc.List(c.Context(), page, pageSize)
+	if err != nil {
+		return err
+	}
+	items := make([]contract.FriendCirclePostResp, len(entries))
+	for i, entry := range entries {
+		items[i] = contract.ToFriendCirclePostResp(entry.Post, entry.Link)
+	}
+	return response.Success(c, contract.FriendCircleListResp{
+		Items: items,
+		Total: total,
+		Page:  page,
+		Size:  pageSize,
+	})
+}
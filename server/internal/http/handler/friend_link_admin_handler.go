@@ -0,0 +1,336 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/friendlink"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+type FriendLinkAdminHandler struct {
+	svc *friendlink.AdminService
+}
+
+func NewFriendLinkAdminHandler(svc *friendlink.AdminService) *FriendLinkAdminHandler {
+	return &FriendLinkAdminHandler{svc: svc}
+}
+
+// ListApplications godoc
+// @Summary 获取友链申请列表
+// @Tags FriendLinkAdmin
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param status query string false "状态 pending/approved/rejected"
+// @Param channel query string false "申请渠道 user/federation"
+// @Success 200 {object} contract.FriendLinkApplicationListResp
+// @Security BearerAuth
+// @Router /admin/friend-links/applications [get]
+// @Security JWTAuth
+func (h *FriendLinkAdminHandler) ListApplications(c *fiber.Ctx) error {
+	page, pageSize := parsePageQuery(c)
+	options := social.FriendLinkApplicationListOptions{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		options.Status = &status
+	}
+	if channel := strings.TrimSpace(c.Query("channel")); channel != "" {
+		options.Channel = &channel
+	}
+
+	items, total, err := h.svc.ListApplications(c.Context(), options)
+	if err != nil {
+		return err
+	}
+	resp := make([]contract.FriendLinkApplicationResp, len(items))
+	for i, item := range items {
+		resp[i] = contract.ToFriendLinkApplicationResp(*item)
+	}
+	return response.Success(c, contract.FriendLinkApplicationListResp{
+		Items: resp,
+		Total: total,
+		Page:  page,
+		Size:  pageSize,
+	})
+}
+
+// ApproveApplication godoc
+// @Summary 通过友链申请
+// @Tags FriendLinkAdmin
+// @Accept json
+// @Produce json
+// @Param id path int true "申请ID"
+// @Param request body contract.ApproveFriendLinkApplicationReq false "审核参数"
+// @Success 200 {object} contract.FriendLinkReviewResp
+// @Security BearerAuth
+// @Router /admin/friend-links/applications/{id}/approve [post]
+// @Security JWTAuth
+func (h *FriendLinkAdminHandler) ApproveApplication(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的申请ID")
+	}
+	var req contract.ApproveFriendLinkApplicationReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+		}
+	}
+
+	result, err := h.svc.Approve(c.Context(), friendlink.ApproveCmd{
+		ID:           id,
+		Name:         req.Name,
+		Logo:         req.Logo,
+		Description:  req.Description,
+		RSSURL:       req.RSSURL,
+		SyncMode:     req.SyncMode,
+		SyncInterval: req.SyncInterval,
+		NotifyRemote: req.NotifyRemote,
+	})
+	if err != nil {
+		return mapFriendLinkError(err)
+	}
+	Audit(c, "friend-link.approve", map[string]any{"id": id, "url": result.Application.URL, "notified": result.Notified})
+	return response.SuccessWithMessage(c, toFriendLinkReviewResp(result), "友链申请已通过")
+}
+
+// RejectApplication godoc
+// @Summary 拒绝友链申请
+// @Tags FriendLinkAdmin
+// @Accept json
+// @Produce json
+// @Param id path int true "申请ID"
+// @Param request body contract.RejectFriendLinkApplicationReq true "拒绝原因"
+// @Success 200 {object} contract.FriendLinkReviewResp
+// @Security BearerAuth
+// @Router /admin/friend-links/applications/{id}/reject [post]
+// @Security JWTAuth
+func (h *FriendLinkAdminHandler) RejectApplication(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的申请ID")
+	}
+	var req contract.RejectFriendLinkApplicationReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+
+	result, err := h.svc.Reject(c.Context(), friendlink.RejectCmd{
+		ID:           id,
+		Reason:       req.Reason,
+		NotifyRemote: req.NotifyRemote,
+	})
+	if err != nil {
+		return mapFriendLinkError(err)
+	}
+	Audit(c, "friend-link.reject", map[string]any{"id": id, "url": result.Application.URL, "reason": req.Reason})
+	return response.SuccessWithMessage(c, toFriendLinkReviewResp(result), "友链申请已拒绝")
+}
+
+// ListLinks godoc
+// @Summary 获取友链列表（后台）
+// @Tags FriendLinkAdmin
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param kind query string false "类型 manual/federation"
+// @Param isActive query bool false "是否启用"
+// @Param search query string false "搜索名称或 URL"
+// @Success 200 {object} contract.FriendLinkListResp
+// @Security BearerAuth
+// @Router /admin/friend-links [get]
+// @Security JWTAuth
+func (h *FriendLinkAdminHandler) ListLinks(c *fiber.Ctx) error {
+	page, pageSize := parsePageQuery(c)
+	options := social.FriendLinkListOptions{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if kind := strings.TrimSpace(c.Query("kind")); kind != "" {
+		options.Kind = &kind
+	}
+	if activeStr := c.Query("isActive"); activeStr != "" {
+		if active, err := strconv.ParseBool(activeStr); err == nil {
+			options.IsActive = &active
+		}
+	}
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		options.Search = &search
+	}
+
+	items, total, err := h.svc.ListLinks(c.Context(), options)
+	if err != nil {
+		return err
+	}
+	resp := make([]contract.FriendLinkResp, len(items))
+	for i, item := range items {
+		resp[i] = contract.ToFriendLinkResp(*item)
+	}
+	return response.Success(c, contract.FriendLinkListResp{
+		Items: resp,
+		Total: total,
+		Page:  page,
+		Size:  pageSize,
+	})
+}
+
+// CreateLink godoc
+// @Summary 创建友链
+// @Tags FriendLinkAdmin
+// @Accept json
+// @Produce json
+// @Param request body contract.FriendLinkReq true "友链信息"
+// @Success 200 {object} contract.FriendLinkResp
+// @Security BearerAuth
+// @Router /admin/friend-links [post]
+// @Security JWTAuth
+func (h *FriendLinkAdminHandler) CreateLink(c *fiber.Ctx) error {
+	var req contract.FriendLinkReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	if strings.TrimSpace(req.Name) == "" || strings.TrimSpace(req.URL) == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "友链名称和 URL 不能为空")
+	}
+
+	link, err := h.svc.CreateLink(c.Context(), toLinkCmd(req))
+	if err != nil {
+		return mapFriendLinkError(err)
+	}
+	Audit(c, "friend-link.create", map[string]any{"id": link.ID, "name": link.Name, "url": link.URL})
+	return response.SuccessWithMessage(c, contract.ToFriendLinkResp(*link), "友链创建成功")
+}
+
+// UpdateLink godoc
+// @Summary 更新友链
+// @Tags FriendLinkAdmin
+// @Accept json
+// @Produce json
+// @Param id path int true "友链ID"
+// @Param request body contract.FriendLinkReq true "友链信息"
+// @Success 200 {object} contract.FriendLinkResp
+// @Security BearerAuth
+// @Router /admin/friend-links/{id} [put]
+// @Security JWTAuth
+func (h *FriendLinkAdminHandler) UpdateLink(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的友链ID")
+	}
+	var req contract.FriendLinkReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	if strings.TrimSpace(req.Name) == "" || strings.TrimSpace(req.URL) == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "友链名称和 URL 不能为空")
+	}
+
+	link, err := h.svc.UpdateLink(c.Context(), id, toLinkCmd(req))
+	if err != nil {
+		return mapFriendLinkError(err)
+	}
+	Audit(c, "friend-link.update", map[string]any{"id": link.ID, "name": link.Name, "url": link.URL})
+	return response.SuccessWithMessage(c, contract.ToFriendLinkResp(*link), "友链更新成功")
+}
+
+// DeleteLink godoc
+// @Summary 删除友链
+// @Tags FriendLinkAdmin
+// @Produce json
+// @Param id path int true "友链ID"
+// @Success 200 {object} any
+// @Security BearerAuth
+// @Router /admin/friend-links/{id} [delete]
+// @Security JWTAuth
+func (h *FriendLinkAdminHandler) DeleteLink(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的友链ID")
+	}
+	if err := h.svc.DeleteLink(c.Context(), id); err != nil {
+		return mapFriendLinkError(err)
+	}
+	Audit(c, "friend-link.delete", map[string]any{"id": id})
+	return response.SuccessWithMessage[any](c, nil, "友链删除成功")
+}
+
+// ListPublic godoc
+// @Summary 获取公开友链列表
+// @Tags FriendLink
+// @Produce json
+// @Success 200 {object} []contract.FriendLinkPublicResp
+// @Router /public/friend-links [get]
+func (h *FriendLinkAdminHandler) ListPublic(c *fiber.Ctx) error {
+	items, err := h.svc.ListPublicLinks(c.Context())
+	if err != nil {
+		return err
+	}
+	resp := make([]contract.FriendLinkPublicResp, len(items))
+	for i, item := range items {
+		resp[i] = contract.ToFriendLinkPublicResp(*item)
+	}
+	return response.Success(c, resp)
+}
+
+func toLinkCmd(req contract.FriendLinkReq) friendlink.LinkCmd {
+	return friendlink.LinkCmd{
+		Name:         req.Name,
+		URL:          req.URL,
+		Logo:         req.Logo,
+		Description:  req.Description,
+		RSSURL:       req.RSSURL,
+		Kind:         req.Kind,
+		SyncMode:     req.SyncMode,
+		SyncInterval: req.SyncInterval,
+		IsActive:     req.IsActive,
+	}
+}
+
+func toFriendLinkReviewResp(result *friendlink.ReviewResult) contract.FriendLinkReviewResp {
+	resp := contract.FriendLinkReviewResp{
+		Application: contract.ToFriendLinkApplicationResp(result.Application),
+		Notified:    result.Notified,
+		NotifyError: result.NotifyError,
+	}
+	if result.Link != nil {
+		link := contract.ToFriendLinkResp(*result.Link)
+		resp.Link = &link
+	}
+	return resp
+}
+
+func mapFriendLinkError(err error) error {
+	switch {
+	case errors.Is(err, social.ErrFriendLinkApplicationNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "友链申请不存在")
+	case errors.Is(err, social.ErrFriendLinkNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "友链不存在")
+	case errors.Is(err, social.ErrFriendLinkApplicationReviewed),
+		errors.Is(err, social.ErrFriendLinkURLExists),
+		errors.Is(err, social.ErrFriendLinkInvalidSyncMode),
+		errors.Is(err, social.ErrFriendLinkInvalidKind):
+		return response.NewBizErrorWithMsg(response.ParamsError, err.Error())
+	default:
+		return err
+	}
+}
+
+func parsePageQuery(c *fiber.Ctx) (int, int) {
+	page := 1
+	pageSize := 10
+	if parsed, err := strconv.Atoi(c.Query("page", "1")); err == nil && parsed > 0 {
+		page = parsed
+	}
+	if parsed, err := strconv.Atoi(c.Query("pageSize", "10")); err == nil && parsed > 0 && parsed <= 100 {
+		pageSize = parsed
+	}
+	return page, pageSize
+}
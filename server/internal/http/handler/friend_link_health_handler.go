@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/friendlink"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+type FriendLinkHealthHandler struct {
+	checker *friendlink.HealthChecker
+}
+
+func NewFriendLinkHealthHandler(checker *friendlink.HealthChecker) *FriendLinkHealthHandler {
+	return &FriendLinkHealthHandler{checker: checker}
+}
+
+// ListHealth godoc
+// @Summary 获取友链健康检查结果
+// @Tags FriendLinkAdmin
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param isHealthy query bool false "是否健康"
+// @Success 200 {object} contract.FriendLinkHealthListResp
+// @Security BearerAuth
+// @Router /admin/friend-links/health [get]
+// @Security JWTAuth
+func (h *FriendLinkHealthHandler) ListHealth(c *fiber.Ctx) error {
+	page, pageSize := parsePageQuery(c)
+	options := social.FriendLinkHealthListOptions{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if raw := strings.TrimSpace(c.Query("isHealthy")); raw != "" {
+		healthy, err := strconv.ParseBool(raw)
+		if err != nil {
+			return response.NewBizErrorWithMsg(response.ParamsError, "isHealthy 参数无效")
+		}
+		options.IsHealthy = &healthy
+	}
+
+	entries, total, err := h.checker.List(c.Context(), options)
+	if err != nil {
+		return err
+	}
+	items := make([]contract.FriendLinkHealthResp, len(entries))
+	for i, entry := range entries {
+		items[i] = contract.ToFriendLinkHealthResp(entry.Health, entry.Link)
+	}
+	return response.Success(c, contract.FriendLinkHealthListResp{
+		Items: items,
+		Total: total,
+		Page:  page,
+		Size:  pageSize,
+	})
+}
+
+// CheckNow godoc
+// @Summary 立即检查友链健康状态
+// @Tags FriendLinkAdmin
+// @Produce json
+// @Param id path int true "友链ID"
+// @Success 200 {object} contract.FriendLinkHealthResp
+// @Security BearerAuth
+// @Router /admin/friend-links/{id}/health-check [post]
+// @Security JWTAuth
+func (h *FriendLinkHealthHandler) CheckNow(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的友链ID")
+	}
+	entry, err := h.checker.Check(c.Context(), id)
+	if err != nil {
+		return mapFriendLinkError(err)
+	}
+	Audit(c, "friend-link.health-check", map[string]any{"id": id, "url": entry.Link.URL, "healthy": entry.Health.IsHealthy})
+	return response.Success(c, contract.ToFriendLinkHealthResp(entry.Health, entry.Link))
+}
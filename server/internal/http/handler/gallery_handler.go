@@ -0,0 +1,497 @@
+package handler
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/gallery"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+// visitorIDHeader 访客点赞时由客户端生成并持久保存的标识。
+const visitorIDHeader = "X-Visitor-Id"
+
+var visitorIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+type GalleryHandler struct {
+	svc *gallery.Service
+}
+
+func NewGalleryHandler(svc *gallery.Service) *GalleryHandler {
+	return &GalleryHandler{svc: svc}
+}
+
+// ListAlbums godoc
+// @Summary 获取相册列表
+// @Tags Gallery
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(20)
+// @Success 200 {object} contract.AlbumListResp
+// @Router /gallery/albums [get]
+func (h *GalleryHandler) ListAlbums(c *fiber.Ctx) error {
+	page, size := galleryPage(c)
+	albums, total, err := h.svc.ListAlbums(c.Context(), (page-1)*size, size)
+	if err != nil {
+		return h.mapError(err)
+	}
+	items := make([]contract.AlbumResp, len(albums))
+	for i, album := range albums {
+		items[i] = contract.ToAlbumResp(album)
+	}
+	return response.Success(c, contract.AlbumListResp{Items: items, Total: total, Page: page, Size: size})
+}
+
+// GetAlbum godoc
+// @Summary 获取相册详情
+// @Tags Gallery
+// @Produce json
+// @Param id path int true "相册ID"
+// @Success 200 {object} contract.AlbumResp
+// @Router /gallery/albums/{id} [get]
+func (h *GalleryHandler) GetAlbum(c *fiber.Ctx) error {
+	id, err := parseInt64Param(c, "id")
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的相册ID")
+	}
+	album, err := h.svc.GetAlbum(c.Context(), id)
+	if err != nil {
+		return h.mapError(err)
+	}
+	return response.Success(c, contract.ToAlbumResp(*album))
+}
+
+// ListAlbumPhotos godoc
+// @Summary 获取相册内的照片
+// @Tags Gallery
+// @Produce json
+// @Param id path int true "相册ID"
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(20)
+// @Success 200 {object} contract.PhotoListResp
+// @Router /gallery/albums/{id}/photos [get]
+func (h *GalleryHandler) ListAlbumPhotos(c *fiber.Ctx) error {
+	id, err := parseInt64Param(c, "id")
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的相册ID")
+	}
+	return h.listPhotos(c, &id)
+}
+
+// ListPhotos godoc
+// @Summary 获取全部照片
+// @Tags Gallery
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(20)
+// @Success 200 {object} contract.PhotoListResp
+// @Router /gallery/photos [get]
+func (h *GalleryHandler) ListPhotos(c *fiber.Ctx) error {
+	return h.listPhotos(c, nil)
+}
+
+// GetPhoto godoc
+// @Summary 获取照片详情
+// @Description 返回的 commentId 可直接用于 /comments/areas/{areaId} 读取与发表评论。
+// @Tags Gallery
+// @Produce json
+// @Param id path int true "照片ID"
+// @Success 200 {object} contract.PhotoResp
+// @Router /gallery/photos/{id} [get]
+func (h *GalleryHandler) GetPhoto(c *fiber.Ctx) error {
+	id, err := parseInt64Param(c, "id")
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的照片ID")
+	}
+	photo, err := h.svc.GetPhoto(c.Context(), id)
+	if err != nil {
+		return h.mapError(err)
+	}
+	resp := contract.ToPhotoResp(*photo)
+	if liker, ok := galleryLiker(c); ok {
+		liked, err := h.svc.HasLiked(c.Context(), id, liker)
+		if err != nil {
+			return err
+		}
+		resp.Liked = liked
+	}
+	return response.Success(c, resp)
+}
+
+// LikePhoto godoc
+// @Summary 点赞照片
+// @Description 登录用户按账号去重；访客需在 X-Visitor-Id 头中携带客户端生成的标识。
+// @Tags Gallery
+// @Produce json
+// @Param id path int true "照片ID"
+// @Param X-Visitor-Id header string false "访客标识"
+// @Success 200 {object} contract.PhotoLikeResp
+// @Router /gallery/photos/{id}/like [post]
+func (h *GalleryHandler) LikePhoto(c *fiber.Ctx) error {
+	return h.toggleLike(c, true)
+}
+
+// UnlikePhoto godoc
+// @Summary 取消点赞照片
+// @Tags Gallery
+// @Produce json
+// @Param id path int true "照片ID"
+// @Param X-Visitor-Id header string false "访客标识"
+// @Success 200 {object} contract.PhotoLikeResp
+// @Router /gallery/photos/{id}/like [delete]
+func (h *GalleryHandler) UnlikePhoto(c *fiber.Ctx) error {
+	return h.toggleLike(c, false)
+}
+
+// UploadPhoto godoc
+// @Summary 上传照片
+// @Description 上传图片并创建照片；位置、设备与拍摄时间留空时在去除定位信息前从 EXIF 读取。
+// @Tags Gallery
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "图片"
+// @Param albumId formData int false "相册ID"
+// @Param description formData string false "描述"
+// @Param location formData string false "位置"
+// @Param device formData string false "设备"
+// @Success 200 {object} contract.PhotoResp
+// @Security JWTAuth
+// @Router /admin/gallery/photos/upload [post]
+func (h *GalleryHandler) UploadPhoto(c *fiber.Ctx) error {
+	file, err := c.FormFile("file")
+	if err != nil || file == nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "file 不能为空")
+	}
+	cmd := gallery.CreatePhotoCmd{
+		Description: formValue(c, "description"),
+		Location:    formValue(c, "location"),
+		Device:      formValue(c, "device"),
+	}
+	if raw := c.FormValue("albumId"); raw != "" {
+		albumID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return response.NewBizErrorWithMsg(response.ParamsError, "无效的相册ID")
+		}
+		cmd.AlbumID = &albumID
+	}
+	photo, err := h.svc.UploadPhoto(c.Context(), uploaderFromCtx(c), file, cmd)
+	if err != nil {
+		return h.mapError(err)
+	}
+	Audit(c, "gallery.photo.upload", map[string]any{
+		"photoId":  photo.ID,
+		"uploadId": photo.UploadID,
+	})
+	return response.SuccessWithMessage(c, contract.ToPhotoResp(*photo), "照片已上传")
+}
+
+// CreatePhoto godoc
+// @Summary 从已上传图片创建照片
+// @Tags Gallery
+// @Accept json
+// @Produce json
+// @Param request body contract.CreatePhotoReq true "创建参数"
+// @Success 200 {object} contract.PhotoResp
+// @Security JWTAuth
+// @Router /admin/gallery/photos [post]
+func (h *GalleryHandler) CreatePhoto(c *fiber.Ctx) error {
+	var req contract.CreatePhotoReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	photo, err := h.svc.CreatePhoto(c.Context(), gallery.CreatePhotoCmd{
+		UploadID:    req.UploadID,
+		URL:         req.URL,
+		AlbumID:     req.AlbumID,
+		Description: req.Description,
+		Location:    req.Location,
+		Device:      req.Device,
+		TakenAt:     req.TakenAt,
+	})
+	if err != nil {
+		return h.mapError(err)
+	}
+	Audit(c, "gallery.photo.create", map[string]any{
+		"photoId":  photo.ID,
+		"uploadId": photo.UploadID,
+	})
+	return response.SuccessWithMessage(c, contract.ToPhotoResp(*photo), "照片已创建")
+}
+
+// UpdatePhoto godoc
+// @Summary 更新照片
+// @Tags Gallery
+// @Accept json
+// @Produce json
+// @Param id path int true "照片ID"
+// @Param request body contract.UpdatePhotoReq true "更新参数"
+// @Success 200 {object} contract.PhotoResp
+// @Security JWTAuth
+// @Router /admin/gallery/photos/{id} [put]
+func (h *GalleryHandler) UpdatePhoto(c *fiber.Ctx) error {
+	id, err := parseInt64Param(c, "id")
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的照片ID")
+	}
+	var req contract.UpdatePhotoReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	photo, err := h.svc.UpdatePhoto(c.Context(), gallery.UpdatePhotoCmd{
+		ID:          id,
+		AlbumID:     req.AlbumID,
+		Description: req.Description,
+		Location:    req.Location,
+		Device:      req.Device,
+		TakenAt:     req.TakenAt,
+	})
+	if err != nil {
+		return h.mapError(err)
+	}
+	Audit(c, "gallery.photo.update", map[string]any{"photoId": id})
+	return response.SuccessWithMessage(c, contract.ToPhotoResp(*photo), "照片已更新")
+}
+
+// DeletePhoto godoc
+// @Summary 删除照片
+// @Description 同时删除照片的评论与点赞，上传文件保留。
+// @Tags Gallery
+// @Param id path int true "照片ID"
+// @Success 200
+// @Security JWTAuth
+// @Router /admin/gallery/photos/{id} [delete]
+func (h *GalleryHandler) DeletePhoto(c *fiber.Ctx) error {
+	id, err := parseInt64Param(c, "id")
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的照片ID")
+	}
+	if err := h.svc.DeletePhoto(c.Context(), id); err != nil {
+		return h.mapError(err)
+	}
+	Audit(c, "gallery.photo.delete", map[string]any{"photoId": id})
+	return response.SuccessWithMessage[any](c, nil, "照片已删除")
+}
+
+// CreateAlbum godoc
+// @Summary 创建相册
+// @Tags Gallery
+// @Accept json
+// @Produce json
+// @Param request body contract.CreateAlbumReq true "创建参数"
+// @Success 200 {object} contract.AlbumResp
+// @Security JWTAuth
+// @Router /admin/gallery/albums [post]
+func (h *GalleryHandler) CreateAlbum(c *fiber.Ctx) error {
+	var req contract.CreateAlbumReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	album, err := h.svc.CreateAlbum(c.Context(), gallery.CreateAlbumCmd{
+		Title:       req.Title,
+		Description: req.Description,
+	})
+	if err != nil {
+		return h.mapError(err)
+	}
+	Audit(c, "gallery.album.create", map[string]any{"albumId": album.ID})
+	return response.SuccessWithMessage(c, contract.ToAlbumResp(*album), "相册已创建")
+}
+
+// UpdateAlbum godoc
+// @Summary 更新相册
+// @Description coverPhotoId 必须是相册内的照片，留空时使用排序最靠前的照片作为封面。
+// @Tags Gallery
+// @Accept json
+// @Produce json
+// @Param id path int true "相册ID"
+// @Param request body contract.UpdateAlbumReq true "更新参数"
+// @Success 200 {object} contract.AlbumResp
+// @Security JWTAuth
+// @Router /admin/gallery/albums/{id} [put]
+func (h *GalleryHandler) UpdateAlbum(c *fiber.Ctx) error {
+	id, err := parseInt64Param(c, "id")
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的相册ID")
+	}
+	var req contract.UpdateAlbumReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	album, err := h.svc.UpdateAlbum(c.Context(), gallery.UpdateAlbumCmd{
+		ID:           id,
+		Title:        req.Title,
+		Description:  req.Description,
+		CoverPhotoID: req.CoverPhotoID,
+	})
+	if err != nil {
+		return h.mapError(err)
+	}
+	Audit(c, "gallery.album.update", map[string]any{"albumId": id})
+	return response.SuccessWithMessage(c, contract.ToAlbumResp(*album), "相册已更新")
+}
+
+// DeleteAlbum godoc
+// @Summary 删除相册
+// @Description 相册内的照片移出相册，不会被删除。
+// @Tags Gallery
+// @Param id path int true "相册ID"
+// @Success 200
+// @Security JWTAuth
+// @Router /admin/gallery/albums/{id} [delete]
+func (h *GalleryHandler) DeleteAlbum(c *fiber.Ctx) error {
+	id, err := parseInt64Param(c, "id")
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的相册ID")
+	}
+	if err := h.svc.DeleteAlbum(c.Context(), id); err != nil {
+		return h.mapError(err)
+	}
+	Audit(c, "gallery.album.delete", map[string]any{"albumId": id})
+	return response.SuccessWithMessage[any](c, nil, "相册已删除")
+}
+
+// ReorderAlbums godoc
+// @Summary 调整相册顺序
+// @Tags Gallery
+// @Accept json
+// @Param request body contract.ReorderGalleryReq true "相册ID顺序"
+// @Success 200
+// @Security JWTAuth
+// @Router /admin/gallery/albums/reorder [put]
+func (h *GalleryHandler) ReorderAlbums(c *fiber.Ctx) error {
+	var req contract.ReorderGalleryReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	if len(req.IDs) == 0 {
+		return response.NewBizErrorWithMsg(response.ParamsError, "排序数据不能为空")
+	}
+	if err := h.svc.ReorderAlbums(c.Context(), req.IDs); err != nil {
+		return h.mapError(err)
+	}
+	return response.SuccessWithMessage[any](c, nil, "相册排序已更新")
+}
+
+// ReorderAlbumPhotos godoc
+// @Summary 调整相册内照片顺序
+// @Tags Gallery
+// @Accept json
+// @Param id path int true "相册ID"
+// @Param request body contract.ReorderGalleryReq true "照片ID顺序"
+// @Success 200
+// @Security JWTAuth
+// @Router /admin/gallery/albums/{id}/photos/reorder [put]
+func (h *GalleryHandler) ReorderAlbumPhotos(c *fiber.Ctx) error {
+	id, err := parseInt64Param(c, "id")
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的相册ID")
+	}
+	var req contract.ReorderGalleryReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	if len(req.IDs) == 0 {
+		return response.NewBizErrorWithMsg(response.ParamsError, "排序数据不能为空")
+	}
+	if err := h.svc.ReorderPhotos(c.Context(), id, req.IDs); err != nil {
+		return h.mapError(err)
+	}
+	return response.SuccessWithMessage[any](c, nil, "照片排序已更新")
+}
+
+func (h *GalleryHandler) listPhotos(c *fiber.Ctx, albumID *int64) error {
+	page, size := galleryPage(c)
+	photos, total, err := h.svc.ListPhotos(c.Context(), albumID, (page-1)*size, size)
+	if err != nil {
+		return h.mapError(err)
+	}
+	items := make([]contract.PhotoResp, len(photos))
+	for i, photo := range photos {
+		items[i] = contract.ToPhotoResp(photo)
+	}
+	return response.Success(c, contract.PhotoListResp{Items: items, Total: total, Page: page, Size: size})
+}
+
+func (h *GalleryHandler) toggleLike(c *fiber.Ctx, liked bool) error {
+	id, err := parseInt64Param(c, "id")
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的照片ID")
+	}
+	liker, ok := galleryLiker(c)
+	if !ok {
+		return response.NewBizErrorWithMsg(response.ParamsError, "未登录时需要提供有效的 "+visitorIDHeader)
+	}
+	var likes int
+	if liked {
+		likes, err = h.svc.LikePhoto(c.Context(), id, liker)
+	} else {
+		likes, err = h.svc.UnlikePhoto(c.Context(), id, liker)
+	}
+	if err != nil {
+		return h.mapError(err)
+	}
+	return response.Success(c, contract.PhotoLikeResp{Liked: liked, Likes: likes})
+}
+
+func (h *GalleryHandler) mapError(err error) error {
+	switch {
+	case errors.Is(err, media.ErrPhotoNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "照片不存在")
+	case errors.Is(err, media.ErrAlbumNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "相册不存在")
+	case errors.Is(err, media.ErrUploadFileNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "上传文件不存在")
+	case errors.Is(err, media.ErrPhotoNotInAlbum),
+		errors.Is(err, media.ErrAlbumTitleEmpty),
+		errors.Is(err, gallery.ErrPhotoSourceRequired),
+		errors.Is(err, gallery.ErrUploadNotPicture),
+		errors.Is(err, gallery.ErrLikerRequired):
+		return response.NewBizErrorWithMsg(response.ParamsError, err.Error())
+	default:
+		return err
+	}
+}
+
+// galleryLiker 优先使用登录用户，其次使用访客标识头。
+func galleryLiker(c *fiber.Ctx) (gallery.Liker, bool) {
+	if claims, ok := middleware.GetClaims(c); ok {
+		userID := claims.UserID
+		return gallery.Liker{UserID: &userID}, true
+	}
+	visitorID := strings.TrimSpace(c.Get(visitorIDHeader))
+	if !visitorIDPattern.MatchString(visitorID) {
+		return gallery.Liker{}, false
+	}
+	return gallery.Liker{SessionID: strings.Clone(visitorID)}, true
+}
+
+func galleryPage(c *fiber.Ctx) (int, int) {
+	page := c.QueryInt("page", 1)
+	size := c.QueryInt("pageSize", 20)
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 20
+	}
+	if size > 100 {
+		size = 100
+	}
+	return page, size
+}
+
+func formValue(c *fiber.Ctx, key string) *string {
+	value := strings.TrimSpace(c.FormValue(key))
+	if value == "" {
+		return nil
+	}
+	value = strings.Clone(value)
+	return &value
+}
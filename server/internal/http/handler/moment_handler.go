@@ -54,18 +54,19 @@ func (h *MomentHandler) CreateMoment(c *fiber.Ctx) error {
 	}
 
 	cmd := moment.CreateMomentCmd{
-		Title:       req.Title,
-		Summary:     req.Summary,
-		Content:     req.Content,
-		Image:       joinImages(req.Image),
-		ColumnID:    req.ColumnID,
-		TopicIDs:    req.TopicIDs,
-		ShortURL:    req.ShortURL,
-		IsPublished: req.IsPublished,
-		IsTop:       req.IsTop,
-		IsHot:       req.IsHot,
-		IsOriginal:  req.IsOriginal,
-		CreatedAt:   req.CreatedAt,
+		Title:          req.Title,
+		Summary:        req.Summary,
+		Content:        req.Content,
+		Image:          joinImages(req.Image),
+		ColumnID:       req.ColumnID,
+		TopicIDs:       req.TopicIDs,
+		ShortURL:       req.ShortURL,
+		IsPublished:    req.IsPublished,
+		IsTop:          req.IsTop,
+		IsHot:          req.IsHot,
+		IsOriginal:     req.IsOriginal,
+		LocalizeImages: req.LocalizeImages,
+		CreatedAt:      req.CreatedAt,
 	}
 
 	createdMoment, err := h.svc.CreateMoment(c.Context(), claims.UserID, cmd)
@@ -124,17 +125,18 @@ func (h *MomentHandler) UpdateMoment(c *fiber.Ctx) error {
 	}
 
 	cmd := moment.UpdateMomentCmd{
-		Title:       req.Title,
-		Summary:     req.Summary,
-		Content:     req.Content,
-		Image:       joinImages(req.Image),
-		ColumnID:    req.ColumnID,
-		TopicIDs:    req.TopicIDs,
-		ShortURL:    req.ShortURL,
-		IsPublished: req.IsPublished,
-		IsTop:       req.IsTop,
-		IsHot:       req.IsHot,
-		IsOriginal:  req.IsOriginal,
+		Title:          req.Title,
+		Summary:        req.Summary,
+		Content:        req.Content,
+		Image:          joinImages(req.Image),
+		ColumnID:       req.ColumnID,
+		TopicIDs:       req.TopicIDs,
+		ShortURL:       req.ShortURL,
+		IsPublished:    req.IsPublished,
+		IsTop:          req.IsTop,
+		IsHot:          req.IsHot,
+		IsOriginal:     req.IsOriginal,
+		LocalizeImages: req.LocalizeImages,
 	}
 	cmd.ID = id
 
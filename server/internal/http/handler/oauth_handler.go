@@ -87,14 +87,13 @@ func (h *OAuthHandler) Callback(c *fiber.Ctx) error {
 		Provider: provider,
 		Code:     req.Code,
 		State:    req.State,
+		Client:   clientMeta(c),
 	})
 	if err != nil {
 		return err
 	}
-	return response.Success(c, contract.LoginResp{
-		Token: result.Token,
-		User:  contract.ToUserResp(result.User),
-	})
+	auditLogin(c, result)
+	return response.Success(c, toLoginResp(result))
 }
 
 func splitScopes(sc string) []string {
@@ -43,13 +43,14 @@ func (h *PageHandler) CreatePage(c *fiber.Ctx) error {
 	}
 
 	cmd := page.CreatePageCmd{
-		Title:       req.Title,
-		Description: req.Description,
-		Content:     req.Content,
-		ShortURL:    req.ShortURL,
-		IsEnabled:   req.IsEnabled,
-		IsBuiltin:   req.IsBuiltin,
-		CreatedAt:   req.CreatedAt,
+		Title:          req.Title,
+		Description:    req.Description,
+		Content:        req.Content,
+		ShortURL:       req.ShortURL,
+		IsEnabled:      req.IsEnabled,
+		IsBuiltin:      req.IsBuiltin,
+		LocalizeImages: req.LocalizeImages,
+		CreatedAt:      req.CreatedAt,
 	}
 
 	createdPage, err := h.svc.CreatePage(c.Context(), cmd)
@@ -102,13 +103,14 @@ func (h *PageHandler) UpdatePage(c *fiber.Ctx) error {
 	}
 
 	cmd := page.UpdatePageCmd{
-		ID:          id,
-		Title:       req.Title,
-		Description: req.Description,
-		Content:     req.Content,
-		ShortURL:    req.ShortURL,
-		IsEnabled:   req.IsEnabled,
-		IsBuiltin:   req.IsBuiltin,
+		ID:             id,
+		Title:          req.Title,
+		Description:    req.Description,
+		Content:        req.Content,
+		ShortURL:       req.ShortURL,
+		IsEnabled:      req.IsEnabled,
+		IsBuiltin:      req.IsBuiltin,
+		LocalizeImages: req.LocalizeImages,
 	}
 
 	updatedPage, err := h.svc.UpdatePage(c.Context(), cmd)
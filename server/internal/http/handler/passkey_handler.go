@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/auth"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+// BeginPasskeyLogin godoc
+// @Summary 获取通行密钥登录选项
+// @Description 返回 WebAuthn 断言选项，credential 为空时由浏览器列出可发现凭据。
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body contract.PasskeyLoginOptionsReq false "用户名或邮箱"
+// @Success 200 {object} contract.PasskeyLoginOptionsResp
+// @Router /auth/passkey/login/options [post]
+func (h *AuthHandler) BeginPasskeyLogin(c *fiber.Ctx) error {
+	var req contract.PasskeyLoginOptionsReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+		}
+	}
+	options, err := h.svc.BeginPasskeyLogin(c.Context(), req.Credential)
+	if err != nil {
+		return mapPasskeyError(err)
+	}
+	return response.Success(c, toPasskeyLoginOptionsResp(options))
+}
+
+// FinishPasskeyLogin godoc
+// @Summary 通行密钥登录
+// @Description 校验 WebAuthn 断言，成功后签发 token；通行密钥已包含用户验证，不再要求两步验证。
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body contract.FinishPasskeyLoginReq true "断言结果"
+// @Success 200 {object} contract.LoginRespEnvelope
+// @Router /auth/passkey/login [post]
+func (h *AuthHandler) FinishPasskeyLogin(c *fiber.Ctx) error {
+	var req contract.FinishPasskeyLoginReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	credentialID := req.Credential.RawID
+	if credentialID == "" {
+		credentialID = req.Credential.ID
+	}
+	result, err := h.svc.FinishPasskeyLogin(c.Context(), auth.FinishPasskeyLoginCmd{
+		SessionID:         req.SessionID,
+		CredentialID:      credentialID,
+		ClientDataJSON:    req.Credential.Response.ClientDataJSON,
+		AuthenticatorData: req.Credential.Response.AuthenticatorData,
+		Signature:         req.Credential.Response.Signature,
+		UserHandle:        req.Credential.Response.UserHandle,
+		Client:            clientMeta(c),
+	})
+	if err != nil {
+		auditPasskeyFailure(c, "login", err)
+		return mapPasskeyError(err)
+	}
+	Audit(c, "auth.passkey.used", map[string]any{"userId": result.User.ID, "passkeyId": result.PasskeyID})
+	auditLogin(c, result)
+	return response.Success(c, toLoginResp(result))
+}
+
+// ListPasskeys godoc
+// @Summary 列出通行密钥
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} []contract.PasskeyResp
+// @Security BearerAuth
+// @Router /auth/passkeys [get]
+func (h *AuthHandler) ListPasskeys(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	passkeys, err := h.svc.ListPasskeys(c.Context(), claims.UserID)
+	if err != nil {
+		return mapPasskeyError(err)
+	}
+	resp := make([]contract.PasskeyResp, len(passkeys))
+	for i, passkey := range passkeys {
+		resp[i] = toPasskeyResp(passkey)
+	}
+	return response.Success(c, resp)
+}
+
+// BeginPasskeyRegistration godoc
+// @Summary 获取通行密钥注册选项
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} contract.PasskeyRegistrationOptionsResp
+// @Security BearerAuth
+// @Router /auth/passkeys/options [post]
+func (h *AuthHandler) BeginPasskeyRegistration(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	options, err := h.svc.BeginPasskeyRegistration(c.Context(), claims.UserID)
+	if err != nil {
+		return mapPasskeyError(err)
+	}
+	return response.Success(c, toPasskeyRegistrationOptionsResp(options))
+}
+
+// FinishPasskeyRegistration godoc
+// @Summary 注册通行密钥
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body contract.FinishPasskeyRegistrationReq true "注册结果"
+// @Success 200 {object} contract.PasskeyResp
+// @Security BearerAuth
+// @Router /auth/passkeys [post]
+func (h *AuthHandler) FinishPasskeyRegistration(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	var req contract.FinishPasskeyRegistrationReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	passkey, err := h.svc.FinishPasskeyRegistration(c.Context(), auth.FinishPasskeyRegistrationCmd{
+		UserID:            claims.UserID,
+		SessionID:         req.SessionID,
+		Name:              req.Name,
+		ClientDataJSON:    req.Credential.Response.ClientDataJSON,
+		AttestationObject: req.Credential.Response.AttestationObject,
+		Transports:        req.Credential.Response.Transports,
+	})
+	if err != nil {
+		auditPasskeyFailure(c, "register", err)
+		return mapPasskeyError(err)
+	}
+	Audit(c, "auth.passkey.register", map[string]any{"userId": claims.UserID, "passkeyId": passkey.ID, "name": passkey.Name})
+	return response.SuccessWithMessage(c, toPasskeyResp(*passkey), "通行密钥已添加")
+}
+
+// RenamePasskey godoc
+// @Summary 重命名通行密钥
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param id path int true "通行密钥ID"
+// @Param request body contract.RenamePasskeyReq true "备注名"
+// @Success 200 {object} contract.GenericMessageEnvelope
+// @Security BearerAuth
+// @Router /auth/passkeys/{id} [put]
+func (h *AuthHandler) RenamePasskey(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的通行密钥 ID")
+	}
+	var req contract.RenamePasskeyReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	if err := h.svc.RenamePasskey(c.Context(), claims.UserID, id, req.Name); err != nil {
+		return mapPasskeyError(err)
+	}
+	return response.SuccessWithMessage[any](c, nil, "已更新")
+}
+
+// DeletePasskey godoc
+// @Summary 删除通行密钥
+// @Tags Auth
+// @Produce json
+// @Param id path int true "通行密钥ID"
+// @Success 200 {object} contract.GenericMessageEnvelope
+// @Security BearerAuth
+// @Router /auth/passkeys/{id} [delete]
+func (h *AuthHandler) DeletePasskey(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的通行密钥 ID")
+	}
+	if err := h.svc.DeletePasskey(c.Context(), claims.UserID, id); err != nil {
+		return mapPasskeyError(err)
+	}
+	Audit(c, "auth.passkey.delete", map[string]any{"userId": claims.UserID, "passkeyId": id})
+	return response.SuccessWithMessage[any](c, nil, "通行密钥已删除")
+}
+
+func auditPasskeyFailure(c *fiber.Ctx, stage string, err error) {
+	var pkErr *auth.PasskeyError
+	if !errors.As(err, &pkErr) {
+		return
+	}
+	Audit(c, "auth.passkey.failed", map[string]any{
+		"userId":    pkErr.UserID,
+		"passkeyId": pkErr.PasskeyID,
+		"stage":     stage,
+		"reason":    pkErr.Err.Error(),
+	})
+}
+
+func mapPasskeyError(err error) error {
+	switch {
+	case errors.Is(err, identity.ErrPasskeySignCount):
+		return response.NewBizErrorWithMsg(response.InvalidCredential, identity.ErrPasskeySignCount.Error())
+	case errors.Is(err, identity.ErrPasskeyVerification):
+		return response.NewBizErrorWithMsg(response.InvalidCredential, identity.ErrPasskeyVerification.Error())
+	case errors.Is(err, identity.ErrChallengeNotFound):
+		return response.NewBizErrorWithMsg(response.ParamsError, "通行密钥验证已过期，请重试")
+	case errors.Is(err, identity.ErrPasskeyNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, err.Error())
+	case errors.Is(err, identity.ErrPasskeyExists),
+		errors.Is(err, identity.ErrPasskeyLimitExceeded),
+		errors.Is(err, identity.ErrPasskeyNotConfigured):
+		return response.NewBizErrorWithMsg(response.ParamsError, err.Error())
+	case errors.Is(err, identity.ErrUserNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "用户不存在")
+	}
+	return err
+}
+
+func toPasskeyResp(passkey identity.Passkey) contract.PasskeyResp {
+	transports := passkey.Transports
+	if transports == nil {
+		transports = []string{}
+	}
+	return contract.PasskeyResp{
+		ID:             passkey.ID,
+		Name:           passkey.Name,
+		Transports:     transports,
+		BackupEligible: passkey.BackupEligible,
+		BackupState:    passkey.BackupState,
+		LastUsedAt:     passkey.LastUsedAt,
+		CreatedAt:      passkey.CreatedAt,
+	}
+}
+
+func toPasskeyRegistrationOptionsResp(options *auth.PasskeyRegistrationOptions) contract.PasskeyRegistrationOptionsResp {
+	params := make([]contract.PasskeyCredentialParameter, len(options.Algorithms))
+	for i, alg := range options.Algorithms {
+		params[i] = contract.PasskeyCredentialParameter{Type: "public-key", Alg: alg}
+	}
+	return contract.PasskeyRegistrationOptionsResp{
+		SessionID: options.SessionID,
+		PublicKey: contract.PasskeyCreationOptions{
+			Challenge: base64.RawURLEncoding.EncodeToString(options.Challenge),
+			RP:        contract.PasskeyRPEntity{ID: options.RPID, Name: options.RPName},
+			User: contract.PasskeyUserEntity{
+				ID:          base64.RawURLEncoding.EncodeToString(options.UserHandle),
+				Name:        options.UserName,
+				DisplayName: options.DisplayName,
+			},
+			PubKeyCredParams:   params,
+			Timeout:            options.Timeout.Milliseconds(),
+			ExcludeCredentials: toPasskeyDescriptors(options.Exclude),
+			AuthenticatorSelection: contract.PasskeyAuthenticatorSelection{
+				ResidentKey:      "preferred",
+				UserVerification: "required",
+			},
+			Attestation: "none",
+		},
+	}
+}
+
+func toPasskeyLoginOptionsResp(options *auth.PasskeyLoginOptions) contract.PasskeyLoginOptionsResp {
+	return contract.PasskeyLoginOptionsResp{
+		SessionID: options.SessionID,
+		PublicKey: contract.PasskeyRequestOptions{
+			Challenge:        base64.RawURLEncoding.EncodeToString(options.Challenge),
+			RPID:             options.RPID,
+			Timeout:          options.Timeout.Milliseconds(),
+			AllowCredentials: toPasskeyDescriptors(options.Allow),
+			UserVerification: "required",
+		},
+	}
+}
+
+func toPasskeyDescriptors(descriptors []auth.PasskeyDescriptor) []contract.PasskeyCredentialDescriptor {
+	result := make([]contract.PasskeyCredentialDescriptor, len(descriptors))
+	for i, descriptor := range descriptors {
+		result[i] = contract.PasskeyCredentialDescriptor{
+			Type:       "public-key",
+			ID:         base64.RawURLEncoding.EncodeToString(descriptor.ID),
+			Transports: descriptor.Transports,
+		}
+	}
+	return result
+}
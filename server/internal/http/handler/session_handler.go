@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/auth"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+// RefreshToken godoc
+// @Summary 刷新 token
+// @Description 使用刷新令牌换取新的 access token，刷新令牌同时轮换，旧令牌立即失效。
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body contract.RefreshTokenReq true "刷新令牌"
+// @Success 200 {object} contract.LoginRespEnvelope
+// @Router /auth/refresh [post]
+func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
+	var req contract.RefreshTokenReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	if req.RefreshToken == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "refreshToken 不能为空")
+	}
+	result, err := h.svc.Refresh(c.Context(), auth.RefreshCmd{
+		RefreshToken: req.RefreshToken,
+		Client:       clientMeta(c),
+	})
+	if err != nil {
+		var sessErr *auth.SessionError
+		if errors.As(err, &sessErr) {
+			Audit(c, "auth.refresh_reuse", map[string]any{
+				"userId":    sessErr.UserID,
+				"sessionId": sessErr.SessionID,
+			})
+		}
+		return mapSessionError(err)
+	}
+	return response.Success(c, toLoginResp(result))
+}
+
+// Logout godoc
+// @Summary 退出登录
+// @Description 吊销当前会话，对应的 token 与刷新令牌立即失效。
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} contract.GenericMessageEnvelope
+// @Security BearerAuth
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	if err := h.svc.Logout(c.Context(), claims.ID); err != nil {
+		return err
+	}
+	Audit(c, "auth.logout", map[string]any{"userId": claims.UserID})
+	return response.SuccessWithMessage[any](c, nil, "已退出登录")
+}
+
+// ListSessions godoc
+// @Summary 列出登录会话
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} []contract.SessionResp
+// @Security BearerAuth
+// @Router /auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	sessions, err := h.svc.ListSessions(c.Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+	resp := make([]contract.SessionResp, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, toSessionResp(session, claims.ID))
+	}
+	return response.Success(c, resp)
+}
+
+// RevokeSession godoc
+// @Summary 吊销登录会话
+// @Tags Auth
+// @Produce json
+// @Param id path int true "会话 ID"
+// @Success 200 {object} contract.GenericMessageEnvelope
+// @Security BearerAuth
+// @Router /auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的会话 ID")
+	}
+	if err := h.svc.RevokeSession(c.Context(), claims.UserID, id); err != nil {
+		return mapSessionError(err)
+	}
+	Audit(c, "auth.session.revoke", map[string]any{"userId": claims.UserID, "sessionId": id})
+	return response.SuccessWithMessage[any](c, nil, "会话已吊销")
+}
+
+// RevokeAllSessions godoc
+// @Summary 吊销全部登录会话
+// @Description 默认保留当前会话；includeCurrent=true 时当前会话一并退出。
+// @Tags Auth
+// @Produce json
+// @Param includeCurrent query bool false "是否同时吊销当前会话"
+// @Success 200 {object} contract.RevokeSessionsResp
+// @Security BearerAuth
+// @Router /auth/sessions [delete]
+func (h *AuthHandler) RevokeAllSessions(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	exceptKey := claims.ID
+	if c.QueryBool("includeCurrent") {
+		exceptKey = ""
+	}
+	revoked, err := h.svc.RevokeAllSessions(c.Context(), claims.UserID, exceptKey)
+	if err != nil {
+		return err
+	}
+	Audit(c, "auth.session.revoke_all", map[string]any{
+		"userId":         claims.UserID,
+		"revoked":        revoked,
+		"includeCurrent": exceptKey == "",
+	})
+	return response.SuccessWithMessage(c, contract.RevokeSessionsResp{Revoked: revoked}, "会话已吊销")
+}
+
+// clientMeta 提取会话列表展示所需的客户端信息。
+func clientMeta(c *fiber.Ctx) auth.ClientMeta {
+	return auth.ClientMeta{IP: c.IP(), UserAgent: c.Get(fiber.HeaderUserAgent)}
+}
+
+func mapSessionError(err error) error {
+	switch {
+	case errors.Is(err, identity.ErrRefreshTokenReused):
+		return response.NewBizErrorWithMsg(response.NotLogin, identity.ErrRefreshTokenReused.Error())
+	case errors.Is(err, identity.ErrInvalidRefreshToken):
+		return response.NewBizErrorWithMsg(response.NotLogin, identity.ErrInvalidRefreshToken.Error())
+	case errors.Is(err, identity.ErrSessionNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, err.Error())
+	case errors.Is(err, identity.ErrUserNotFound):
+		return response.NewBizErrorWithMsg(response.NotLogin, identity.ErrInvalidRefreshToken.Error())
+	}
+	return err
+}
+
+func toSessionResp(session identity.Session, currentKey string) contract.SessionResp {
+	return contract.SessionResp{
+		ID:         session.ID,
+		Browser:    session.Browser,
+		Platform:   session.Platform,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		Location:   session.Location,
+		Current:    currentKey != "" && session.SessionKey == currentKey,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+	}
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/auth"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+// VerifyTwoFactorLogin godoc
+// @Summary 两步验证登录
+// @Description 使用登录返回的 challengeToken 与验证码（或恢复码）完成登录；挑战要求绑定时，验证码用于启用两步验证并返回恢复码。
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body contract.VerifyTwoFactorLoginReq true "验证参数"
+// @Success 200 {object} contract.LoginRespEnvelope
+// @Router /auth/login/2fa [post]
+func (h *AuthHandler) VerifyTwoFactorLogin(c *fiber.Ctx) error {
+	var req contract.VerifyTwoFactorLoginReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	result, err := h.svc.VerifyLogin(c.Context(), auth.VerifyLoginCmd{
+		ChallengeToken: req.ChallengeToken,
+		Code:           req.Code,
+		RecoveryCode:   req.RecoveryCode,
+		Client:         clientMeta(c),
+	})
+	if err != nil {
+		var tfErr *auth.TwoFactorError
+		if errors.As(err, &tfErr) {
+			Audit(c, "auth.2fa.failed", map[string]any{
+				"userId":    tfErr.UserID,
+				"stage":     "login",
+				"remaining": tfErr.Remaining,
+			})
+		}
+		return mapTwoFactorError(err)
+	}
+	if len(result.RecoveryCodes) > 0 {
+		Audit(c, "auth.2fa.enable", map[string]any{"userId": result.User.ID, "stage": "login"})
+	}
+	if result.UsedRecoveryCode {
+		Audit(c, "auth.2fa.recovery_used", map[string]any{"userId": result.User.ID})
+	}
+	auditLogin(c, result)
+	return response.Success(c, toLoginResp(result))
+}
+
+// BeginTwoFactorLoginSetup godoc
+// @Summary 登录时绑定两步验证
+// @Description 策略要求管理员开启两步验证而当前未开启时，用登录挑战生成密钥，再调用 /auth/login/2fa 提交验证码。
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body contract.TwoFactorLoginSetupReq true "登录挑战"
+// @Success 200 {object} contract.TwoFactorSetupResp
+// @Router /auth/login/2fa/setup [post]
+func (h *AuthHandler) BeginTwoFactorLoginSetup(c *fiber.Ctx) error {
+	var req contract.TwoFactorLoginSetupReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	setup, err := h.svc.BeginTwoFactorSetup(c.Context(), req.ChallengeToken)
+	if err != nil {
+		return mapTwoFactorError(err)
+	}
+	return response.Success(c, contract.TwoFactorSetupResp{Secret: setup.Secret, OtpauthURI: setup.URI})
+}
+
+// TwoFactorStatus godoc
+// @Summary 获取两步验证状态
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} contract.TwoFactorStatusResp
+// @Security BearerAuth
+// @Router /auth/2fa [get]
+func (h *AuthHandler) TwoFactorStatus(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	status, err := h.svc.TwoFactorStatus(c.Context(), claims.UserID)
+	if err != nil {
+		return mapTwoFactorError(err)
+	}
+	return response.Success(c, contract.TwoFactorStatusResp{
+		Enabled:           status.Enabled,
+		Required:          status.Required,
+		EnabledAt:         status.EnabledAt,
+		RecoveryCodesLeft: status.RecoveryCodesLeft,
+	})
+}
+
+// SetupTwoFactor godoc
+// @Summary 生成两步验证密钥
+// @Description 返回 TOTP 密钥与 otpauth 地址，需调用 /auth/2fa/enable 提交验证码后才生效。
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} contract.TwoFactorSetupResp
+// @Security BearerAuth
+// @Router /auth/2fa/setup [post]
+func (h *AuthHandler) SetupTwoFactor(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	setup, err := h.svc.SetupTwoFactor(c.Context(), claims.UserID)
+	if err != nil {
+		return mapTwoFactorError(err)
+	}
+	return response.Success(c, contract.TwoFactorSetupResp{Secret: setup.Secret, OtpauthURI: setup.URI})
+}
+
+// EnableTwoFactor godoc
+// @Summary 启用两步验证
+// @Description 校验验证器生成的验证码后启用，返回的恢复码只展示这一次。
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body contract.TwoFactorCodeReq true "验证码"
+// @Success 200 {object} contract.RecoveryCodesResp
+// @Security BearerAuth
+// @Router /auth/2fa/enable [post]
+func (h *AuthHandler) EnableTwoFactor(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	var req contract.TwoFactorCodeReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	codes, err := h.svc.EnableTwoFactor(c.Context(), claims.UserID, req.Code)
+	if err != nil {
+		if errors.Is(err, identity.ErrInvalidTwoFactorCode) {
+			Audit(c, "auth.2fa.failed", map[string]any{"userId": claims.UserID, "stage": "enable"})
+		}
+		return mapTwoFactorError(err)
+	}
+	Audit(c, "auth.2fa.enable", map[string]any{"userId": claims.UserID})
+	return response.SuccessWithMessage(c, contract.RecoveryCodesResp{Codes: codes}, "两步验证已开启")
+}
+
+// DisableTwoFactor godoc
+// @Summary 关闭两步验证
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body contract.DisableTwoFactorReq true "密码与验证码"
+// @Success 200 {object} contract.GenericMessageEnvelope
+// @Security BearerAuth
+// @Router /auth/2fa/disable [post]
+func (h *AuthHandler) DisableTwoFactor(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	var req contract.DisableTwoFactorReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	err := h.svc.DisableTwoFactor(c.Context(), auth.DisableTwoFactorCmd{
+		UserID:   claims.UserID,
+		Password: req.Password,
+		Code:     req.Code,
+	})
+	if err != nil {
+		if errors.Is(err, identity.ErrInvalidTwoFactorCode) || errors.Is(err, identity.ErrInvalidCredentials) {
+			Audit(c, "auth.2fa.failed", map[string]any{"userId": claims.UserID, "stage": "disable"})
+		}
+		return mapTwoFactorError(err)
+	}
+	Audit(c, "auth.2fa.disable", map[string]any{"userId": claims.UserID})
+	return response.SuccessWithMessage[any](c, nil, "两步验证已关闭")
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary 重新生成恢复码
+// @Description 旧恢复码全部作废。
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body contract.TwoFactorCodeReq true "验证码"
+// @Success 200 {object} contract.RecoveryCodesResp
+// @Security BearerAuth
+// @Router /auth/2fa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return response.ErrorFromBiz[any](c, response.NotLogin)
+	}
+	var req contract.TwoFactorCodeReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithCause(response.ParamsError, "请求体解析失败", err)
+	}
+	codes, err := h.svc.RegenerateRecoveryCodes(c.Context(), claims.UserID, req.Code)
+	if err != nil {
+		if errors.Is(err, identity.ErrInvalidTwoFactorCode) {
+			Audit(c, "auth.2fa.failed", map[string]any{"userId": claims.UserID, "stage": "recovery_codes"})
+		}
+		return mapTwoFactorError(err)
+	}
+	Audit(c, "auth.2fa.recovery_regenerate", map[string]any{"userId": claims.UserID})
+	return response.Success(c, contract.RecoveryCodesResp{Codes: codes})
+}
+
+func mapTwoFactorError(err error) error {
+	switch {
+	case errors.Is(err, identity.ErrInvalidTwoFactorCode):
+		var tfErr *auth.TwoFactorError
+		if errors.As(err, &tfErr) && tfErr.Remaining == 0 {
+			return response.NewBizErrorWithMsg(response.InvalidCredential, "验证失败次数过多，请重新登录")
+		}
+		return response.NewBizErrorWithMsg(response.InvalidCredential, err.Error())
+	case errors.Is(err, identity.ErrInvalidCredentials):
+		return response.NewBizError(response.InvalidCredential)
+	case errors.Is(err, identity.ErrChallengeNotFound):
+		return response.NewBizErrorWithMsg(response.NotLogin, err.Error())
+	case errors.Is(err, identity.ErrTwoFactorNotEnabled),
+		errors.Is(err, identity.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, identity.ErrTwoFactorNotSetup),
+		errors.Is(err, identity.ErrTwoFactorRequired):
+		return response.NewBizErrorWithMsg(response.ParamsError, err.Error())
+	case errors.Is(err, identity.ErrUserNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "用户不存在")
+	}
+	return err
+}
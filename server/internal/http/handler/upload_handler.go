@@ -2,24 +2,33 @@ package handler
 
 import (
 	"errors"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 
 	mediaapp "github.com/grtsinry43/grtblog-v2/server/internal/app/media"
 	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/imageproc"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/storage"
 )
 
 type UploadHandler struct {
-	svc *mediaapp.Service
+	svc          *mediaapp.Service
+	signedURLTTL time.Duration
 }
 
-func NewUploadHandler(svc *mediaapp.Service) *UploadHandler {
-	return &UploadHandler{svc: svc}
+func NewUploadHandler(svc *mediaapp.Service, signedURLTTL time.Duration) *UploadHandler {
+	if signedURLTTL <= 0 {
+		signedURLTTL = 15 * time.Minute
+	}
+	return &UploadHandler{svc: svc, signedURLTTL: signedURLTTL}
 }
 
 // UploadFile godoc
@@ -42,11 +51,14 @@ func (h *UploadHandler) UploadFile(c *fiber.Ctx) error {
 		return response.NewBizErrorWithMsg(response.ParamsError, "type 不能为空")
 	}
 
-	result, err := h.svc.Upload(c.Context(), file, fileType)
+	result, err := h.svc.Upload(c.Context(), uploaderFromCtx(c), file, fileType)
 	if err != nil {
 		if errors.Is(err, media.ErrInvalidUploadType) {
 			return response.NewBizErrorWithMsg(response.ParamsError, "type 仅支持 picture 或 file")
 		}
+		if errors.Is(err, media.ErrUnsupportedFileType) || errors.Is(err, media.ErrUploadQuotaExceeded) {
+			return response.NewBizErrorWithMsg(response.ParamsError, err.Error())
+		}
 		return response.NewBizErrorWithCause(response.ServerError, "文件上传失败", err)
 	}
 
@@ -77,7 +89,7 @@ func (h *UploadHandler) ListUploads(c *fiber.Ctx) error {
 		pageSize = val
 	}
 
-	result, err := h.svc.List(c.Context(), page, pageSize)
+	result, err := h.svc.List(c.Context(), uploaderFromCtx(c), page, pageSize)
 	if err != nil {
 		return response.NewBizErrorWithCause(response.ServerError, "获取文件列表失败", err)
 	}
@@ -120,7 +132,7 @@ func (h *UploadHandler) RenameUpload(c *fiber.Ctx) error {
 		return response.NewBizErrorWithMsg(response.ParamsError, "文件名不能为空")
 	}
 
-	updated, err := h.svc.Rename(c.Context(), id, req.Name)
+	updated, err := h.svc.Rename(c.Context(), uploaderFromCtx(c), id, req.Name)
 	if err != nil {
 		if errors.Is(err, media.ErrUploadFileNotFound) {
 			return response.NewBizErrorWithMsg(response.NotFound, "文件不存在")
@@ -135,8 +147,11 @@ func (h *UploadHandler) RenameUpload(c *fiber.Ctx) error {
 // @Summary 删除上传文件
 // @Tags Upload
 // @Produce json
+// @Description 文件仍被文章、手记、页面、回想、头像、友链或站点信息引用时返回 409 及引用列表，确认后带 force=true 重试；被相册照片使用的文件需先删除照片。
 // @Param id path int true "文件ID"
+// @Param force query bool false "强制删除仍被引用的文件"
 // @Success 200 {object} any
+// @Failure 409 {object} []contract.UploadUsageResp
 // @Security BearerAuth
 // @Router /upload/{id} [delete]
 func (h *UploadHandler) DeleteUpload(c *fiber.Ctx) error {
@@ -144,18 +159,156 @@ func (h *UploadHandler) DeleteUpload(c *fiber.Ctx) error {
 	if err != nil {
 		return response.NewBizErrorWithMsg(response.ParamsError, "无效的文件ID")
 	}
+	force := c.QueryBool("force", false)
 
-	_, err = h.svc.Delete(c.Context(), id)
+	file, usages, err := h.svc.Delete(c.Context(), uploaderFromCtx(c), id, force)
 	if err != nil {
 		if errors.Is(err, media.ErrUploadFileNotFound) {
 			return response.NewBizErrorWithMsg(response.NotFound, "文件不存在")
 		}
+		if errors.Is(err, media.ErrUploadFileInUse) {
+			return response.ErrorWithData(c, response.ResourceInUse, "文件仍被内容引用，确认后可强制删除", contract.ToUploadUsageResps(usages))
+		}
+		if errors.Is(err, media.ErrUploadFileUsedByPhoto) {
+			return response.ErrorWithData(c, response.ResourceInUse, "文件被相册照片使用，请先删除照片", contract.ToUploadUsageResps(usages))
+		}
 		return response.NewBizErrorWithCause(response.ServerError, "删除文件失败", err)
 	}
+	if len(usages) > 0 {
+		Audit(c, "upload.force_delete", map[string]any{
+			"id":     id,
+			"path":   file.Path,
+			"usages": len(usages),
+		})
+	}
 
 	return response.SuccessWithMessage[any](c, nil, "文件已删除")
 }
 
+// ListUploadUsages godoc
+// @Summary 查看上传文件的引用
+// @Tags Upload
+// @Produce json
+// @Param id path int true "文件ID"
+// @Success 200 {object} []contract.UploadUsageResp
+// @Security BearerAuth
+// @Router /upload/{id}/usages [get]
+func (h *UploadHandler) ListUploadUsages(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的文件ID")
+	}
+	usages, err := h.svc.Usages(c.Context(), uploaderFromCtx(c), id)
+	if err != nil {
+		if errors.Is(err, media.ErrUploadFileNotFound) {
+			return response.NewBizErrorWithMsg(response.NotFound, "文件不存在")
+		}
+		if errors.Is(err, mediaapp.ErrUsageTrackingDisabled) {
+			return response.NewBizErrorWithMsg(response.ParamsError, "未开启引用跟踪")
+		}
+		return response.NewBizErrorWithCause(response.ServerError, "获取文件引用失败", err)
+	}
+	return response.Success(c, contract.ToUploadUsageResps(usages))
+}
+
+// ListOrphanUploads godoc
+// @Summary 列出未被引用的上传文件
+// @Description 只包含超过宽限期（STORAGE_ORPHAN_GRACE）的文件。
+// @Tags Upload
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Success 200 {object} contract.UploadFileListRespEnvelope
+// @Security BearerAuth
+// @Router /admin/uploads/orphans [get]
+func (h *UploadHandler) ListOrphanUploads(c *fiber.Ctx) error {
+	page := 1
+	pageSize := 10
+	if val, err := strconv.Atoi(c.Query("page", "1")); err == nil && val > 0 {
+		page = val
+	}
+	if val, err := strconv.Atoi(c.Query("pageSize", "10")); err == nil && val > 0 && val <= 100 {
+		pageSize = val
+	}
+
+	result, err := h.svc.ListOrphans(c.Context(), page, pageSize)
+	if err != nil {
+		if errors.Is(err, mediaapp.ErrUsageTrackingDisabled) {
+			return response.NewBizErrorWithMsg(response.ParamsError, "未开启引用跟踪")
+		}
+		return response.NewBizErrorWithCause(response.ServerError, "获取孤儿文件失败", err)
+	}
+
+	items := make([]contract.UploadFileResp, len(result.Items))
+	for i, file := range result.Items {
+		items[i] = contract.ToUploadFileResp(file, false)
+	}
+	return response.Success(c, contract.UploadFileListResp{
+		Items: items,
+		Total: result.Total,
+		Page:  result.Page,
+		Size:  result.Size,
+	})
+}
+
+// CollectUploadGarbage godoc
+// @Summary 回收未被引用的上传文件
+// @Description 清理孤儿上传记录及其文件，并删除存储上没有上传记录的游离文件。confirm 为 false 时只预演并返回将被清理的内容。
+// @Tags Upload
+// @Accept json
+// @Produce json
+// @Param request body contract.UploadGCReq false "回收参数"
+// @Success 200 {object} contract.UploadGCResp
+// @Security BearerAuth
+// @Router /admin/uploads/gc [post]
+func (h *UploadHandler) CollectUploadGarbage(c *fiber.Ctx) error {
+	var req contract.UploadGCReq
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.NewBizErrorWithMsg(response.ParamsError, "请求体解析失败")
+		}
+	}
+
+	report, err := h.svc.CollectGarbage(c.Context(), !req.Confirm)
+	if err != nil {
+		if errors.Is(err, mediaapp.ErrUsageTrackingDisabled) {
+			return response.NewBizErrorWithMsg(response.ParamsError, "未开启引用跟踪")
+		}
+		return response.NewBizErrorWithCause(response.ServerError, "回收上传文件失败", err)
+	}
+
+	resp := contract.UploadGCResp{
+		DryRun:     report.DryRun,
+		Orphans:    make([]contract.UploadFileResp, len(report.Orphans)),
+		Strays:     make([]contract.UploadStrayResp, len(report.Strays)),
+		FreedBytes: report.FreedBytes,
+	}
+	for i, file := range report.Orphans {
+		resp.Orphans[i] = contract.ToUploadFileResp(file, false)
+	}
+	for i, stray := range report.Strays {
+		resp.Strays[i] = contract.UploadStrayResp{
+			Driver:  stray.Driver,
+			Key:     stray.Key,
+			Size:    stray.Size,
+			ModTime: stray.ModTime,
+		}
+	}
+	for _, failure := range report.Failures {
+		resp.Failures = append(resp.Failures, contract.UploadGCFailureResp{Path: failure.Path, Error: failure.Error})
+	}
+	if !report.DryRun {
+		Audit(c, "upload.gc", map[string]any{
+			"orphans":    len(report.Orphans),
+			"strays":     len(report.Strays),
+			"freedBytes": report.FreedBytes,
+			"failures":   len(report.Failures),
+		})
+		return response.SuccessWithMessage(c, resp, "上传文件已回收")
+	}
+	return response.Success(c, resp)
+}
+
 // DownloadUpload godoc
 // @Summary 下载上传文件
 // @Tags Upload
@@ -170,7 +323,7 @@ func (h *UploadHandler) DownloadUpload(c *fiber.Ctx) error {
 		return response.NewBizErrorWithMsg(response.ParamsError, "无效的文件ID")
 	}
 
-	file, err := h.svc.GetByID(c.Context(), id)
+	file, err := h.svc.GetOwned(c.Context(), uploaderFromCtx(c), id)
 	if err != nil {
 		if errors.Is(err, media.ErrUploadFileNotFound) {
 			return response.NewBizErrorWithMsg(response.NotFound, "文件不存在")
@@ -178,16 +331,161 @@ func (h *UploadHandler) DownloadUpload(c *fiber.Ctx) error {
 		return response.NewBizErrorWithCause(response.ServerError, "获取文件失败", err)
 	}
 
-	diskPath, err := h.svc.ResolveDiskPath(file.Path)
-	if err != nil {
+	if diskPath, err := h.svc.ResolveDiskPath(file.Path); err == nil {
+		if _, err := os.Stat(diskPath); err != nil {
+			if os.IsNotExist(err) {
+				return response.NewBizErrorWithMsg(response.NotFound, "文件不存在")
+			}
+			return response.NewBizErrorWithCause(response.ServerError, "读取文件失败", err)
+		}
+		return c.Download(diskPath, file.Name)
+	} else if !errors.Is(err, mediaapp.ErrNotLocalFile) {
 		return response.NewBizErrorWithCause(response.ServerError, "文件路径解析失败", err)
 	}
-	if _, err := os.Stat(diskPath); err != nil {
-		if os.IsNotExist(err) {
+
+	body, info, err := h.svc.Open(c.Context(), file)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
 			return response.NewBizErrorWithMsg(response.NotFound, "文件不存在")
 		}
 		return response.NewBizErrorWithCause(response.ServerError, "读取文件失败", err)
 	}
+	c.Attachment(file.Name)
+	if info.ContentType != "" {
+		c.Set(fiber.HeaderContentType, info.ContentType)
+	}
+	return c.SendStream(body, int(info.Size))
+}
+
+// SignedUploadURL godoc
+// @Summary 获取上传文件的限时访问地址
+// @Tags Upload
+// @Produce json
+// @Param id path int true "文件ID"
+// @Param ttl query int false "有效期（秒），默认 900，最长 7 天" default(900)
+// @Success 200 {object} contract.UploadSignedURLResp
+// @Security BearerAuth
+// @Router /upload/{id}/signed-url [get]
+func (h *UploadHandler) SignedUploadURL(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的文件ID")
+	}
+	ttl := h.signedURLTTL
+	if raw := c.Query("ttl"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds <= 0 {
+			return response.NewBizErrorWithMsg(response.ParamsError, "无效的有效期")
+		}
+		ttl = time.Duration(seconds) * time.Second
+	}
+	if ttl > storage.MaxSignedURLTTL {
+		ttl = storage.MaxSignedURLTTL
+	}
 
-	return c.Download(diskPath, file.Name)
+	file, err := h.svc.GetOwned(c.Context(), uploaderFromCtx(c), id)
+	if err != nil {
+		if errors.Is(err, media.ErrUploadFileNotFound) {
+			return response.NewBizErrorWithMsg(response.NotFound, "文件不存在")
+		}
+		return response.NewBizErrorWithCause(response.ServerError, "获取文件失败", err)
+	}
+	signedURL, err := h.svc.SignedURL(c.Context(), file, ttl)
+	if err != nil {
+		return response.NewBizErrorWithCause(response.ServerError, "生成访问地址失败", err)
+	}
+	return response.Success(c, contract.UploadSignedURLResp{
+		URL:       signedURL,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+}
+
+// ServeObject 按对象键输出上传文件（/uploads/*）；私有存储下需携带签名参数。
+// 图片可附带 w（宽度）与 fmt（webp|jpeg|png，avif 暂按 webp 输出）参数按需缩放，结果缓存在磁盘。
+func (h *UploadHandler) ServeObject(c *fiber.Ctx) error {
+	// Params 未做 URL 解码且复用请求缓冲区，解码同时得到独立的副本。
+	key, err := url.PathUnescape(c.Params("*"))
+	if err != nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	expires, signature := c.Query("expires"), c.Query("signature")
+	if h.svc.Private() || signature != "" {
+		if !h.svc.VerifySignedURL(key, expires, signature) {
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+	}
+
+	object, err := h.svc.LocateObject(c.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+		return err
+	}
+	if c.Query("w") != "" || c.Query("fmt") != "" {
+		return h.serveResized(c, object)
+	}
+	if local, ok := object.Driver.(*storage.LocalDriver); ok {
+		diskPath, err := local.DiskPath(object.Key)
+		if err != nil {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+		return c.SendFile(diskPath)
+	}
+	if s3, ok := object.Driver.(*storage.S3Driver); ok && !h.svc.Private() {
+		if public := s3.PublicURL(object.Key); public != "" {
+			return c.Redirect(public, fiber.StatusFound)
+		}
+	}
+
+	body, info, err := object.Driver.Open(c.Context(), object.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+		return err
+	}
+	if info.ContentType != "" {
+		c.Set(fiber.HeaderContentType, info.ContentType)
+	}
+	if info.ETag != "" {
+		c.Set(fiber.HeaderETag, info.ETag)
+	}
+	return c.SendStream(body, int(info.Size))
+}
+
+func (h *UploadHandler) serveResized(c *fiber.Ctx, object *mediaapp.ServedObject) error {
+	req := mediaapp.ResizeRequest{Format: strings.Clone(c.Query("fmt"))}
+	if raw := c.Query("w"); raw != "" {
+		width, err := strconv.Atoi(raw)
+		if err != nil || width <= 0 {
+			return c.Status(fiber.StatusBadRequest).SendString("invalid width")
+		}
+		req.Width = width
+	}
+	cached, err := h.svc.ResizeImage(c.Context(), object, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, mediaapp.ErrImageProcessingDisabled):
+			return c.Status(fiber.StatusBadRequest).SendString("image processing is disabled")
+		case errors.Is(err, imageproc.ErrUnsupportedFormat), errors.Is(err, mediaapp.ErrNotImage):
+			return c.Status(fiber.StatusBadRequest).SendString("unsupported image or format")
+		case errors.Is(err, imageproc.ErrTooManyPixels):
+			return c.Status(fiber.StatusRequestEntityTooLarge).SendString("image too large")
+		case errors.Is(err, storage.ErrObjectNotFound):
+			return c.SendStatus(fiber.StatusNotFound)
+		default:
+			return err
+		}
+	}
+	return c.SendFile(cached)
+}
+
+// uploaderFromCtx 返回当前登录用户，上传接口均在 RequireAuth 之后，取不到时按无权限的普通用户处理。
+func uploaderFromCtx(c *fiber.Ctx) mediaapp.Uploader {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return mediaapp.Uploader{}
+	}
+	return mediaapp.Uploader{UserID: claims.UserID, Admin: claims.IsAdmin}
 }
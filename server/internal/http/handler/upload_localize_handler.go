@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	mediaapp "github.com/grtsinry43/grtblog-v2/server/internal/app/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+// UploadLocalizeHandler 管理外链图片批量转存任务。
+type UploadLocalizeHandler struct {
+	job *mediaapp.LocalizeJob
+}
+
+func NewUploadLocalizeHandler(job *mediaapp.LocalizeJob) *UploadLocalizeHandler {
+	return &UploadLocalizeHandler{job: job}
+}
+
+// StartLocalize godoc
+// @Summary 批量转存外链图片
+// @Description 在后台扫描全部文章、手记与页面，把外链图片下载到本站并改写内容；同一时间只能运行一个任务。
+// @Tags Upload
+// @Produce json
+// @Success 200 {object} contract.UploadLocalizeJobResp
+// @Security BearerAuth
+// @Router /admin/uploads/localize [post]
+func (h *UploadLocalizeHandler) StartLocalize(c *fiber.Ctx) error {
+	status, err := h.job.Start()
+	if err != nil {
+		if errors.Is(err, mediaapp.ErrLocalizeJobRunning) {
+			return response.NewBizErrorWithMsg(response.ResourceInUse, "转存任务正在执行")
+		}
+		return response.NewBizErrorWithCause(response.ServerError, "启动转存任务失败", err)
+	}
+	Audit(c, "upload.localize", nil)
+	return response.SuccessWithMessage(c, toUploadLocalizeJobResp(status), "转存任务已开始")
+}
+
+// GetLocalizeStatus godoc
+// @Summary 查询外链图片转存进度
+// @Tags Upload
+// @Produce json
+// @Success 200 {object} contract.UploadLocalizeJobResp
+// @Security BearerAuth
+// @Router /admin/uploads/localize [get]
+func (h *UploadLocalizeHandler) GetLocalizeStatus(c *fiber.Ctx) error {
+	return response.Success(c, toUploadLocalizeJobResp(h.job.Status()))
+}
+
+func toUploadLocalizeJobResp(status mediaapp.LocalizeJobStatus) contract.UploadLocalizeJobResp {
+	resp := contract.UploadLocalizeJobResp{
+		Running:    status.Running,
+		StartedAt:  status.StartedAt,
+		FinishedAt: status.FinishedAt,
+		Scanned:    status.Scanned,
+		Changed:    status.Changed,
+	}
+	for _, failure := range status.Failures {
+		resp.Failures = append(resp.Failures, contract.UploadLocalizeFailureResp{
+			OwnerType: failure.OwnerType,
+			OwnerID:   failure.OwnerID,
+			Error:     failure.Error,
+		})
+	}
+	return resp
+}
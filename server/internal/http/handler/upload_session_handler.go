@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	mediaapp "github.com/grtsinry43/grtblog-v2/server/internal/app/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+// UploadSessionHandler 处理分片断点续传：创建会话 → 逐片上传 → 合并。
+type UploadSessionHandler struct {
+	svc *mediaapp.ResumableService
+}
+
+func NewUploadSessionHandler(svc *mediaapp.ResumableService) *UploadSessionHandler {
+	return &UploadSessionHandler{svc: svc}
+}
+
+// InitSession godoc
+// @Summary 创建分片上传会话
+// @Description 返回分片大小与分片数；携带 hash 且服务器已有相同文件时会话直接完成。
+// @Tags Upload
+// @Accept json
+// @Produce json
+// @Param request body contract.UploadSessionInitReq true "文件信息"
+// @Success 200 {object} contract.UploadSessionResp
+// @Security BearerAuth
+// @Router /upload/sessions [post]
+func (h *UploadSessionHandler) InitSession(c *fiber.Ctx) error {
+	var req contract.UploadSessionInitReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "请求体解析失败")
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "name 不能为空")
+	}
+	if req.Size <= 0 {
+		return response.NewBizErrorWithMsg(response.ParamsError, "size 必须大于 0")
+	}
+
+	state, err := h.svc.Init(c.Context(), mediaapp.InitUploadCmd{
+		Owner: uploaderFromCtx(c),
+		Name:  req.Name,
+		Type:  req.Type,
+		Size:  req.Size,
+		Hash:  req.Hash,
+	})
+	if err != nil {
+		return h.mapError(err)
+	}
+	return response.Success(c, contract.ToUploadSessionResp(state.Session, state.Received, state.File))
+}
+
+// GetSession godoc
+// @Summary 查询分片上传进度
+// @Tags Upload
+// @Produce json
+// @Param id path string true "会话ID"
+// @Success 200 {object} contract.UploadSessionResp
+// @Security BearerAuth
+// @Router /upload/sessions/{id} [get]
+func (h *UploadSessionHandler) GetSession(c *fiber.Ctx) error {
+	state, err := h.svc.Status(c.Context(), uploaderFromCtx(c), c.Params("id"))
+	if err != nil {
+		return h.mapError(err)
+	}
+	return response.Success(c, contract.ToUploadSessionResp(state.Session, state.Received, state.File))
+}
+
+// PutChunk godoc
+// @Summary 上传分片
+// @Description 请求体为分片原始字节，X-Chunk-Checksum 为该分片的 SHA-256（十六进制）。除最后一片外，每片大小必须等于会话的 chunkSize。
+// @Tags Upload
+// @Accept application/octet-stream
+// @Produce json
+// @Param id path string true "会话ID"
+// @Param index path int true "分片序号，从 0 开始"
+// @Param X-Chunk-Checksum header string true "分片 SHA-256"
+// @Success 200 {object} any
+// @Security BearerAuth
+// @Router /upload/sessions/{id}/chunks/{index} [put]
+func (h *UploadSessionHandler) PutChunk(c *fiber.Ctx) error {
+	index, err := strconv.Atoi(c.Params("index"))
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的分片序号")
+	}
+	checksum := c.Get("X-Chunk-Checksum")
+	if strings.TrimSpace(checksum) == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "X-Chunk-Checksum 不能为空")
+	}
+
+	if err := h.svc.PutChunk(c.Context(), uploaderFromCtx(c), c.Params("id"), index, c.Body(), checksum); err != nil {
+		return h.mapError(err)
+	}
+	return response.SuccessWithMessage[any](c, nil, "分片已保存")
+}
+
+// CompleteSession godoc
+// @Summary 合并分片
+// @Tags Upload
+// @Produce json
+// @Param id path string true "会话ID"
+// @Success 200 {object} contract.UploadSessionResp
+// @Security BearerAuth
+// @Router /upload/sessions/{id}/complete [post]
+func (h *UploadSessionHandler) CompleteSession(c *fiber.Ctx) error {
+	state, err := h.svc.Complete(c.Context(), uploaderFromCtx(c), c.Params("id"))
+	if err != nil {
+		return h.mapError(err)
+	}
+	return response.SuccessWithMessage(c, contract.ToUploadSessionResp(state.Session, state.Received, state.File), "文件上传成功")
+}
+
+// AbortSession godoc
+// @Summary 取消分片上传
+// @Tags Upload
+// @Produce json
+// @Param id path string true "会话ID"
+// @Success 200 {object} any
+// @Security BearerAuth
+// @Router /upload/sessions/{id} [delete]
+func (h *UploadSessionHandler) AbortSession(c *fiber.Ctx) error {
+	if err := h.svc.Abort(c.Context(), uploaderFromCtx(c), c.Params("id")); err != nil {
+		return h.mapError(err)
+	}
+	return response.SuccessWithMessage[any](c, nil, "上传已取消")
+}
+
+func (h *UploadSessionHandler) mapError(err error) error {
+	switch {
+	case errors.Is(err, media.ErrUploadSessionNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, err.Error())
+	case errors.Is(err, media.ErrInvalidUploadType):
+		return response.NewBizErrorWithMsg(response.ParamsError, "type 仅支持 picture 或 file")
+	case errors.Is(err, media.ErrUploadTooLarge):
+		return response.NewBizErrorWithMsg(response.ParamsError, fmt.Sprintf("文件不能超过 %d MB", h.svc.MaxSize()>>20))
+	case errors.Is(err, media.ErrUploadQuotaExceeded), errors.Is(err, media.ErrUnsupportedFileType):
+		return response.NewBizErrorWithMsg(response.ParamsError, err.Error())
+	case errors.Is(err, mediaapp.ErrInvalidChecksum):
+		return response.NewBizErrorWithMsg(response.ParamsError, "校验和必须是 SHA-256 十六进制串")
+	case errors.Is(err, media.ErrUploadSessionIncomplete),
+		errors.Is(err, media.ErrChunkOutOfRange),
+		errors.Is(err, media.ErrChunkSizeMismatch),
+		errors.Is(err, media.ErrChunkChecksumMismatch),
+		errors.Is(err, media.ErrUploadHashMismatch):
+		return response.NewBizErrorWithMsg(response.ParamsError, err.Error())
+	default:
+		return response.NewBizErrorWithCause(response.ServerError, "分片上传失败", err)
+	}
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/webmention"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/content"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+type WebmentionHandler struct {
+	svc *webmention.Service
+}
+
+func NewWebmentionHandler(svc *webmention.Service) *WebmentionHandler {
+	return &WebmentionHandler{svc: svc}
+}
+
+// Receive accepts a Webmention and verifies the source asynchronously.
+// @Summary 接收 Webmention（入站）
+// @Tags Webmention
+// @Accept x-www-form-urlencoded
+// @Produce plain
+// @Param source formData string true "提及方页面地址"
+// @Param target formData string true "本站被提及的页面地址"
+// @Success 202 {string} string "Accepted"
+// @Router /api/webmention [post]
+func (h *WebmentionHandler) Receive(c *fiber.Ctx) error {
+	source := strings.TrimSpace(c.FormValue("source"))
+	target := strings.TrimSpace(c.FormValue("target"))
+	if source == "" || target == "" {
+		return response.NewBizErrorWithMsg(response.ParamsError, "source 与 target 不能为空")
+	}
+	if _, err := h.svc.Receive(c.Context(), source, target); err != nil {
+		return mapWebmentionError(err)
+	}
+	return c.Status(fiber.StatusAccepted).SendString("Accepted")
+}
+
+// ListWebmentions godoc
+// @Summary 获取收到的 Webmention 列表
+// @Tags WebmentionAdmin
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param status query string false "状态 pending/verified/rejected/deleted"
+// @Param target_type query string false "目标类型 article/moment"
+// @Param target_id query int false "目标ID"
+// @Success 200 {object} contract.WebmentionListResp
+// @Security BearerAuth
+// @Router /admin/webmentions [get]
+// @Security JWTAuth
+func (h *WebmentionHandler) ListWebmentions(c *fiber.Ctx) error {
+	page, pageSize := parsePageQuery(c)
+	options := federation.WebmentionListOptions{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		options.Status = &status
+	}
+	if targetType := strings.TrimSpace(c.Query("target_type")); targetType != "" {
+		options.TargetType = &targetType
+	}
+	if raw := strings.TrimSpace(c.Query("target_id")); raw != "" {
+		targetID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return response.NewBizErrorWithMsg(response.ParamsError, "无效的目标ID")
+		}
+		options.TargetID = &targetID
+	}
+
+	mentions, total, err := h.svc.List(c.Context(), options)
+	if err != nil {
+		return err
+	}
+	items := make([]contract.WebmentionResp, len(mentions))
+	for i, mention := range mentions {
+		items[i] = contract.ToWebmentionResp(mention)
+	}
+	return response.Success(c, contract.WebmentionListResp{
+		Items: items,
+		Total: total,
+		Page:  page,
+		Size:  pageSize,
+	})
+}
+
+// VerifyWebmention godoc
+// @Summary 重新校验 Webmention
+// @Tags WebmentionAdmin
+// @Produce json
+// @Param id path int true "Webmention ID"
+// @Success 200 {object} contract.WebmentionResp
+// @Security BearerAuth
+// @Router /admin/webmentions/{id}/verify [post]
+// @Security JWTAuth
+func (h *WebmentionHandler) VerifyWebmention(c *fiber.Ctx) error {
+	id, err := parseWebmentionID(c)
+	if err != nil {
+		return err
+	}
+	mention, err := h.svc.Verify(c.Context(), id)
+	if err != nil {
+		return mapWebmentionError(err)
+	}
+	Audit(c, "webmention.verify", map[string]any{"id": id, "source": mention.Source, "status": mention.Status})
+	return response.Success(c, contract.ToWebmentionResp(*mention))
+}
+
+// DeleteWebmention godoc
+// @Summary 删除 Webmention
+// @Tags WebmentionAdmin
+// @Produce json
+// @Param id path int true "Webmention ID"
+// @Success 200 {object} any
+// @Security BearerAuth
+// @Router /admin/webmentions/{id} [delete]
+// @Security JWTAuth
+func (h *WebmentionHandler) DeleteWebmention(c *fiber.Ctx) error {
+	id, err := parseWebmentionID(c)
+	if err != nil {
+		return err
+	}
+	if err := h.svc.Delete(c.Context(), id); err != nil {
+		return mapWebmentionError(err)
+	}
+	Audit(c, "webmention.delete", map[string]any{"id": id})
+	return response.SuccessWithMessage[any](c, nil, "删除成功")
+}
+
+// ListSends godoc
+// @Summary 获取文章外链的 Webmention 发送状态
+// @Tags WebmentionAdmin
+// @Produce json
+// @Param articleId path int true "文章ID"
+// @Success 200 {array} contract.WebmentionSendResp
+// @Security BearerAuth
+// @Router /admin/webmentions/sends/{articleId} [get]
+// @Security JWTAuth
+func (h *WebmentionHandler) ListSends(c *fiber.Ctx) error {
+	articleID, err := parseWebmentionArticleID(c)
+	if err != nil {
+		return err
+	}
+	sends, err := h.svc.ListSends(c.Context(), articleID)
+	if err != nil {
+		return err
+	}
+	items := make([]contract.WebmentionSendResp, len(sends))
+	for i, send := range sends {
+		items[i] = contract.ToWebmentionSendResp(send)
+	}
+	return response.Success(c, items)
+}
+
+// ResendArticle godoc
+// @Summary 重新发送文章的 Webmention
+// @Tags WebmentionAdmin
+// @Produce json
+// @Param articleId path int true "文章ID"
+// @Success 200 {array} contract.WebmentionSendResp
+// @Security BearerAuth
+// @Router /admin/webmentions/sends/{articleId} [post]
+// @Security JWTAuth
+func (h *WebmentionHandler) ResendArticle(c *fiber.Ctx) error {
+	articleID, err := parseWebmentionArticleID(c)
+	if err != nil {
+		return err
+	}
+	if err := h.svc.SendForArticle(c.Context(), articleID); err != nil {
+		return mapWebmentionError(err)
+	}
+	Audit(c, "webmention.send", map[string]any{"articleId": articleID})
+	return h.ListSends(c)
+}
+
+func parseWebmentionID(c *fiber.Ctx) (int64, error) {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, response.NewBizErrorWithMsg(response.ParamsError, "无效的 Webmention ID")
+	}
+	return id, nil
+}
+
+func parseWebmentionArticleID(c *fiber.Ctx) (int64, error) {
+	id, err := strconv.ParseInt(c.Params("articleId"), 10, 64)
+	if err != nil {
+		return 0, response.NewBizErrorWithMsg(response.ParamsError, "无效的文章ID")
+	}
+	return id, nil
+}
+
+func mapWebmentionError(err error) error {
+	switch {
+	case errors.Is(err, federation.ErrWebmentionDisabled):
+		return response.NewBizErrorWithMsg(response.NotFound, "Webmention 未启用")
+	case errors.Is(err, federation.ErrInvalidWebmention):
+		return response.NewBizErrorWithMsg(response.ParamsError, "source 或 target 无效")
+	case errors.Is(err, federation.ErrFederationInstanceBlocked):
+		return response.NewBizErrorWithMsg(response.Unauthorized, "来源站点已被屏蔽")
+	case errors.Is(err, federation.ErrWebmentionNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "Webmention 不存在")
+	case errors.Is(err, content.ErrArticleNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "文章不存在")
+	default:
+		return err
+	}
+}
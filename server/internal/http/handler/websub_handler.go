@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/friendlink"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/websub"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/contract"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+)
+
+type WebSubHandler struct {
+	svc    *websub.Service
+	syncer *friendlink.Syncer
+}
+
+func NewWebSubHandler(svc *websub.Service, syncer *friendlink.Syncer) *WebSubHandler {
+	return &WebSubHandler{svc: svc, syncer: syncer}
+}
+
+// ArticlesFeed godoc
+// @Summary 文章 Atom Feed
+// @Tags Feed
+// @Produce xml
+// @Success 200 {string} string "Atom Feed"
+// @Router /api/feeds/articles.xml [get]
+func (h *WebSubHandler) ArticlesFeed(c *fiber.Ctx) error {
+	return h.feed(c, websub.FeedArticles)
+}
+
+// MomentsFeed godoc
+// @Summary 手记 Atom Feed
+// @Tags Feed
+// @Produce xml
+// @Success 200 {string} string "Atom Feed"
+// @Router /api/feeds/moments.xml [get]
+func (h *WebSubHandler) MomentsFeed(c *fiber.Ctx) error {
+	return h.feed(c, websub.FeedMoments)
+}
+
+// feed 启用 Hub 时同时在 Link 头中声明 hub 与 self，便于订阅方不解析正文即可发现。
+func (h *WebSubHandler) feed(c *fiber.Ctx, kind string) error {
+	doc, err := h.svc.Feed(c.Context(), kind, c.BaseURL())
+	if err != nil {
+		return mapWebSubError(err)
+	}
+	if doc.HubURL != "" {
+		c.Append(fiber.HeaderLink, fmt.Sprintf(`<%s>; rel="hub"`, doc.HubURL), fmt.Sprintf(`<%s>; rel="self"`, doc.SelfURL))
+	}
+	c.Set(fiber.HeaderContentType, "application/atom+xml; charset=utf-8")
+	return c.Send(doc.Body)
+}
+
+// Hub handles WebSub subscribe/unsubscribe/publish requests.
+// @Summary WebSub Hub
+// @Tags WebSub
+// @Accept x-www-form-urlencoded
+// @Produce plain
+// @Param hub.mode formData string true "subscribe/unsubscribe/publish"
+// @Param hub.topic formData string false "订阅的 Feed 地址"
+// @Param hub.url formData string false "publish 时通知更新的 Feed 地址"
+// @Param hub.callback formData string false "订阅方回调地址"
+// @Param hub.secret formData string false "推送签名密钥"
+// @Param hub.lease_seconds formData int false "期望租期（秒）"
+// @Success 202 {string} string "Accepted"
+// @Router /api/websub/hub [post]
+func (h *WebSubHandler) Hub(c *fiber.Ctx) error {
+	req := websub.HubRequest{
+		Mode:     strings.TrimSpace(c.FormValue("hub.mode")),
+		Topic:    strings.TrimSpace(c.FormValue("hub.topic")),
+		Callback: strings.TrimSpace(c.FormValue("hub.callback")),
+		Secret:   c.FormValue("hub.secret"),
+	}
+	if req.Mode == websub.ModePublish && req.Topic == "" {
+		req.Topic = strings.TrimSpace(c.FormValue("hub.url"))
+	}
+	if raw := strings.TrimSpace(c.FormValue("hub.lease_seconds")); raw != "" {
+		lease, err := strconv.Atoi(raw)
+		if err != nil {
+			return response.NewBizErrorWithMsg(response.ParamsError, "无效的 hub.lease_seconds")
+		}
+		req.LeaseSeconds = lease
+	}
+	if err := h.svc.HandleHub(c.Context(), req); err != nil {
+		return mapWebSubError(err)
+	}
+	return c.Status(fiber.StatusAccepted).SendString("Accepted")
+}
+
+// VerifyCallback 响应远端 Hub 对友链订阅的意图校验。
+// @Summary WebSub 订阅回调校验（友链订阅）
+// @Tags WebSub
+// @Produce plain
+// @Param token path string true "回调令牌"
+// @Success 200 {string} string "hub.challenge"
+// @Router /api/websub/callback/{token} [get]
+func (h *WebSubHandler) VerifyCallback(c *fiber.Ctx) error {
+	challenge, err := h.syncer.VerifyWebSub(
+		c.Context(),
+		c.Params("token"),
+		c.Query("hub.mode"),
+		c.Query("hub.topic"),
+		c.Query("hub.challenge"),
+		c.Query("hub.lease_seconds"),
+		c.Query("hub.reason"),
+	)
+	if err != nil {
+		if errors.Is(err, social.ErrFriendLinkWebSubNotFound) {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+		return err
+	}
+	return c.Status(fiber.StatusOK).SendString(challenge)
+}
+
+// ReceiveCallback 接收远端 Hub 推送的友链订阅内容。
+// @Summary WebSub 内容推送（友链订阅）
+// @Tags WebSub
+// @Accept xml
+// @Produce plain
+// @Param token path string true "回调令牌"
+// @Success 202 {string} string "Accepted"
+// @Router /api/websub/callback/{token} [post]
+func (h *WebSubHandler) ReceiveCallback(c *fiber.Ctx) error {
+	token := c.Params("token")
+	err := h.syncer.ReceiveWebSub(c.Context(), token, c.Get("X-Hub-Signature"), c.Body())
+	switch {
+	case err == nil:
+	case errors.Is(err, social.ErrFriendLinkWebSubNotFound):
+		return c.SendStatus(fiber.StatusNotFound)
+	case errors.Is(err, social.ErrFriendLinkNotFound):
+		// 友链已移除或不再订阅，410 通知 Hub 停止推送。
+		return c.SendStatus(fiber.StatusGone)
+	default:
+		// 签名无效或内容无法解析时按规范仍返回 2xx，避免 Hub 无意义地重试。
+		log.Printf("[friendlink] 处理 WebSub 推送失败 token=%s err=%v", token, err)
+	}
+	return c.Status(fiber.StatusAccepted).SendString("Accepted")
+}
+
+// ListSubscriptions godoc
+// @Summary 获取内置 WebSub Hub 的订阅列表
+// @Tags WebSubAdmin
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param pageSize query int false "每页数量" default(10)
+// @Param topic query string false "Feed 地址"
+// @Success 200 {object} contract.WebSubSubscriptionListResp
+// @Security BearerAuth
+// @Router /admin/websub/subscriptions [get]
+// @Security JWTAuth
+func (h *WebSubHandler) ListSubscriptions(c *fiber.Ctx) error {
+	page, pageSize := parsePageQuery(c)
+	options := federation.WebSubSubscriptionListOptions{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if topic := strings.TrimSpace(c.Query("topic")); topic != "" {
+		options.Topic = &topic
+	}
+	subs, total, err := h.svc.List(c.Context(), options)
+	if err != nil {
+		return err
+	}
+	items := make([]contract.WebSubSubscriptionResp, len(subs))
+	for i, sub := range subs {
+		items[i] = contract.ToWebSubSubscriptionResp(sub)
+	}
+	return response.Success(c, contract.WebSubSubscriptionListResp{
+		Items: items,
+		Total: total,
+		Page:  page,
+		Size:  pageSize,
+	})
+}
+
+// DeleteSubscription godoc
+// @Summary 移除内置 WebSub Hub 的订阅
+// @Tags WebSubAdmin
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} any
+// @Security BearerAuth
+// @Router /admin/websub/subscriptions/{id} [delete]
+// @Security JWTAuth
+func (h *WebSubHandler) DeleteSubscription(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的订阅ID")
+	}
+	if err := h.svc.Delete(c.Context(), id); err != nil {
+		return mapWebSubError(err)
+	}
+	Audit(c, "websub.subscription.delete", map[string]any{"id": id})
+	return response.SuccessWithMessage[any](c, nil, "删除成功")
+}
+
+func mapWebSubError(err error) error {
+	switch {
+	case errors.Is(err, federation.ErrWebSubDisabled):
+		return response.NewBizErrorWithMsg(response.NotFound, "WebSub Hub 未启用")
+	case errors.Is(err, federation.ErrInvalidWebSubRequest):
+		return response.NewBizErrorWithMsg(response.ParamsError, "无效的 WebSub 请求")
+	case errors.Is(err, federation.ErrWebSubNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "订阅不存在")
+	case errors.Is(err, federation.ErrFeedNotFound):
+		return response.NewBizErrorWithMsg(response.NotFound, "Feed 不存在")
+	default:
+		return err
+	}
+}
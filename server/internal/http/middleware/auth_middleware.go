@@ -12,7 +12,7 @@ import (
 
 const authContextKey = "authUser"
 
-// RequireAuth 校验 Authorization header 并解析 JWT。
+// RequireAuth 校验 Authorization header 并解析 JWT，设置了会话校验时同时检查 jti 对应的会话未被吊销。
 func RequireAuth(manager *jwt.Manager) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		token := extractToken(c.Get("Authorization"))
@@ -27,12 +27,30 @@ func RequireAuth(manager *jwt.Manager) fiber.Handler {
 			}
 			return response.ErrorWithMsg[any](c, response.NotLogin, "token 无效")
 		}
+		if err := manager.CheckSession(c.Context(), claims); err != nil {
+			if errors.Is(err, jwt.ErrRevokedToken) {
+				return response.ErrorWithMsg[any](c, response.NotLogin, "登录已失效，请重新登录")
+			}
+			return err
+		}
 
 		c.Locals(authContextKey, claims)
 		return c.Next()
 	}
 }
 
+// OptionalAuth 携带有效 token 时解析 JWT，未携带或无效时按匿名访问继续处理。
+func OptionalAuth(manager *jwt.Manager) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token := extractToken(c.Get("Authorization")); token != "" {
+			if claims, err := manager.Parse(token); err == nil && manager.CheckSession(c.Context(), claims) == nil {
+				c.Locals(authContextKey, claims)
+			}
+		}
+		return c.Next()
+	}
+}
+
 // RequireAdmin 要求当前用户是管理员。
 func RequireAdmin() fiber.Handler {
 	return func(c *fiber.Ctx) error {
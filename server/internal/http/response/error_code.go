@@ -72,4 +72,18 @@ var (
 		BizErr:     "TOO_MANY_REQUESTS",
 		Msg:        "请求过于频繁，请稍后再试",
 	}
+
+	ReplayedRequest = BizError{
+		HTTPStatus: fiber.StatusConflict,
+		Code:       40901,
+		BizErr:     "REPLAYED_REQUEST",
+		Msg:        "请求已被处理，疑似重放",
+	}
+
+	ResourceInUse = BizError{
+		HTTPStatus: fiber.StatusConflict,
+		Code:       40902,
+		BizErr:     "RESOURCE_IN_USE",
+		Msg:        "资源仍在使用中",
+	}
 )
@@ -64,6 +64,14 @@ func ErrorWithMsg[T any](c *fiber.Ctx, be BizError, msg string) error {
 	return respond(c, be.HTTPStatus, be.Code, be.BizErr, msg, zero)
 }
 
+// ErrorWithData 错误响应同时携带数据，例如冲突时附带冲突详情。
+func ErrorWithData[T any](c *fiber.Ctx, be BizError, msg string, data T) error {
+	if msg == "" {
+		msg = be.Msg
+	}
+	return respond(c, be.HTTPStatus, be.Code, be.BizErr, msg, data)
+}
+
 // 低层封装：真正写出 JSON 的地方
 func respond[T any](c *fiber.Ctx, status int, code int, bizErr string, msg string, data T) error {
 	return c.Status(status).JSON(Envelope[T]{
@@ -1,7 +1,6 @@
 package router
 
 import (
-	"net/http"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,7 +14,7 @@ import (
 	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence"
 )
 
-func registerAdminRoutes(v2 fiber.Router, deps Dependencies, websiteInfoHandler *handler.WebsiteInfoHandler, navMenuHandler *handler.NavMenuHandler, sysCfgSvc *sysconfig.Service) {
+func registerAdminRoutes(v2 fiber.Router, deps Dependencies, websiteInfoHandler *handler.WebsiteInfoHandler, navMenuHandler *handler.NavMenuHandler, sysCfgSvc *sysconfig.Service, keySvc *appfed.KeyService) {
 	adminGroup := v2.Group("", middleware.RequireAuth(deps.JWTManager), middleware.RequireAdmin())
 
 	websiteInfo := adminGroup.Group("/website-info")
@@ -47,7 +46,7 @@ func registerAdminRoutes(v2 fiber.Router, deps Dependencies, websiteInfoHandler
 
 	fedCfgRepo := persistence.NewFederationConfigRepository(deps.DB)
 	fedCfgSvc := federationconfig.NewService(fedCfgRepo)
-	fedCfgHandler := handler.NewFederationConfigHandler(fedCfgSvc)
+	fedCfgHandler := handler.NewFederationConfigHandler(fedCfgSvc, keySvc)
 	admin.Get("/federation/config", fedCfgHandler.ListFederationConfig)
 	admin.Put("/federation/config", fedCfgHandler.UpdateFederationConfig)
 
@@ -57,14 +56,51 @@ func registerAdminRoutes(v2 fiber.Router, deps Dependencies, websiteInfoHandler
 	if deps.Redis != nil {
 		cache = fedinfra.NewRedisCache(deps.Redis, deps.Config.Redis.Prefix)
 	}
-	resolver := fedinfra.NewResolver(&http.Client{Timeout: 10 * time.Second}, cache)
-	outbound := appfed.NewOutboundService(fedCfgSvc, resolver, instanceRepo)
-	federationAdminHandler := handler.NewFederationAdminHandler(fedCfgSvc, contentRepo, outbound, resolver)
+	resolver := fedinfra.NewResolver(fedinfra.NewGuardedHTTPClient(10*time.Second), cache)
+	outbound := appfed.NewOutboundService(fedCfgSvc, keySvc, resolver, instanceRepo)
+	instanceSvc := appfed.NewInstanceService(instanceRepo, persistence.NewFederationDomainRuleRepository(deps.DB), resolver)
+	friendLinkRequestSvc := appfed.NewFriendLinkRequestService(
+		persistence.NewOutboundFriendLinkRepository(deps.DB),
+		persistence.NewFriendLinkRepository(deps.DB),
+		instanceSvc,
+		outbound,
+	)
+	federationAdminHandler := handler.NewFederationAdminHandler(fedCfgSvc, contentRepo, outbound, friendLinkRequestSvc, resolver)
 	admin.Post("/federation/friendlinks/request", federationAdminHandler.RequestFriendLink)
+	admin.Get("/federation/friendlinks/outbound", federationAdminHandler.ListOutboundFriendLinks)
 	admin.Post("/federation/citations/request", federationAdminHandler.SendCitation)
 	admin.Post("/federation/mentions/notify", federationAdminHandler.SendMention)
 	admin.Get("/federation/remote/check", federationAdminHandler.CheckRemote)
 
+	commentSvc := appfed.NewCommentService(
+		persistence.NewFederatedCommentRepository(deps.DB),
+		persistence.NewCommentRepository(deps.DB),
+		contentRepo,
+		persistence.NewFriendLinkRepository(deps.DB),
+		instanceRepo,
+		persistence.NewFederatedPostCacheRepository(deps.DB),
+		outbound,
+	)
+	commentHandler := handler.NewFederationCommentHandler(fedCfgSvc, commentSvc, instanceRepo, persistence.NewIdentityRepository(deps.DB), resolver, nil)
+	admin.Get("/federation/comments", commentHandler.ListComments)
+	admin.Post("/federation/comments/reply", commentHandler.ReplyComment)
+	admin.Post("/federation/comments/:id/approve", commentHandler.ApproveComment)
+	admin.Post("/federation/comments/:id/reject", commentHandler.RejectComment)
+
+	keyHandler := handler.NewFederationKeyHandler(keySvc)
+	admin.Get("/federation/keys", keyHandler.ListKeys)
+	admin.Post("/federation/keys/rotate", keyHandler.RotateKey)
+
+	instanceHandler := handler.NewFederationInstanceHandler(instanceSvc)
+	admin.Get("/federation/instances", instanceHandler.ListInstances)
+	admin.Get("/federation/instances/:id", instanceHandler.GetInstance)
+	admin.Post("/federation/instances/:id/refresh", instanceHandler.RefreshInstance)
+	admin.Post("/federation/instances/:id/block", instanceHandler.BlockInstance)
+	admin.Post("/federation/instances/:id/allow", instanceHandler.AllowInstance)
+	admin.Get("/federation/domain-rules", instanceHandler.ListDomainRules)
+	admin.Post("/federation/domain-rules", instanceHandler.CreateDomainRule)
+	admin.Delete("/federation/domain-rules/:id", instanceHandler.DeleteDomainRule)
+
 	logHandler := handler.NewAdminLogHandler("storage/logs/app.log", 200)
 	adminLogs := adminGroup.Group("/admin")
 	adminLogs.Get("/logs", logHandler.List)
@@ -4,13 +4,14 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/article"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
 	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence"
 )
 
 func registerArticlePublicRoutes(v2 fiber.Router, deps Dependencies) {
-	articleHandler := newArticleHandler(deps)
+	articleHandler := newArticleHandler(deps, nil)
 
 	publicGroup := v2.Group("/articles")
 	publicGroup.Get("/", articleHandler.ListArticles)                        // GET /api/v2/articles
@@ -19,8 +20,8 @@ func registerArticlePublicRoutes(v2 fiber.Router, deps Dependencies) {
 	publicGroup.Post("/:id/latest", articleHandler.CheckArticleLatest)       // POST /api/v2/articles/123/latest
 }
 
-func registerArticleAuthRoutes(v2 fiber.Router, deps Dependencies) {
-	articleHandler := newArticleHandler(deps)
+func registerArticleAuthRoutes(v2 fiber.Router, deps Dependencies, localizer contentutil.ImageLocalizer) {
+	articleHandler := newArticleHandler(deps, localizer)
 
 	authGroup := v2.Group("/articles", middleware.RequireAuth(deps.JWTManager))
 	authGroup.Post("/", articleHandler.CreateArticle)      // POST /api/v2/articles
@@ -28,9 +29,9 @@ func registerArticleAuthRoutes(v2 fiber.Router, deps Dependencies) {
 	authGroup.Delete("/:id", articleHandler.DeleteArticle) // DELETE /api/v2/articles/123
 }
 
-func newArticleHandler(deps Dependencies) *handler.ArticleHandler {
+func newArticleHandler(deps Dependencies, localizer contentutil.ImageLocalizer) *handler.ArticleHandler {
 	contentRepo := persistence.NewContentRepository(deps.DB)
 	identityRepo := persistence.NewIdentityRepository(deps.DB)
-	articleSvc := article.NewService(contentRepo, deps.EventBus)
+	articleSvc := article.NewService(contentRepo, deps.EventBus).WithImageLocalizer(localizer)
 	return handler.NewArticleHandler(articleSvc, contentRepo, identityRepo)
 }
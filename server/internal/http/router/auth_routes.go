@@ -7,9 +7,12 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/auth"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/comment"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/sysconfig"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/clientinfo"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/geoip"
 	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence"
 )
 
@@ -17,10 +20,26 @@ func registerAuthRoutes(v2 fiber.Router, deps Dependencies, sysCfgSvc *sysconfig
 	identityRepo := persistence.NewIdentityRepository(deps.DB)
 	oauthRepo := persistence.NewOAuthProviderRepository(deps.DB)
 	var stateStore auth.StateStore
+	var challengeStore auth.ChallengeStore = auth.NewMemoryChallengeStore()
+	var passkeySessions auth.PasskeySessionStore = auth.NewMemoryPasskeySessionStore()
 	if deps.Redis != nil {
 		stateStore = auth.NewRedisStateStore(deps.Redis, deps.Config.Redis.Prefix)
+		challengeStore = auth.NewRedisChallengeStore(deps.Redis, deps.Config.Redis.Prefix)
+		passkeySessions = auth.NewRedisPasskeySessionStore(deps.Redis, deps.Config.Redis.Prefix)
 	}
-	authSvc := auth.NewService(identityRepo, oauthRepo, deps.JWTManager, stateStore, deps.Config.Auth)
+	sessionRepo := persistence.NewSessionRepository(deps.DB)
+	deps.JWTManager.WithSessionChecker(auth.NewSessionChecker(sessionRepo))
+	auth.StartSessionCleanup(sessionRepo, time.Hour, 7*24*time.Hour)
+
+	var geoResolver comment.GeoIPResolver
+	if deps.Config.GeoIP.DBPath != "" {
+		// 数据库由评论模块负责下载，这里按需打开即可。
+		geoResolver = geoip.NewLazyResolver(deps.Config.GeoIP.DBPath, deps.Config.GeoIP.ASNPath)
+	}
+	authSvc := auth.NewService(identityRepo, oauthRepo, deps.JWTManager, stateStore, deps.Config.Auth).
+		WithTwoFactor(persistence.NewTwoFactorRepository(deps.DB), challengeStore, sysCfgSvc, deps.Config.App.Name).
+		WithPasskeys(persistence.NewPasskeyRepository(deps.DB), passkeySessions, deps.Config.WebAuthn).
+		WithSessions(sessionRepo, clientinfo.NewUAParser(), geoResolver)
 	authHandler := handler.NewAuthHandler(authSvc, sysCfgSvc, deps.Turnstile)
 	oauthHandler := handler.NewOAuthHandler(authSvc, deps.Config.Auth.OAuthStateTTL)
 
@@ -37,6 +56,11 @@ func registerAuthRoutes(v2 fiber.Router, deps Dependencies, sysCfgSvc *sysconfig
 	}))
 	authGroup.Post("/register", authHandler.Register)
 	authGroup.Post("/login", authHandler.Login)
+	authGroup.Post("/login/2fa", authHandler.VerifyTwoFactorLogin)
+	authGroup.Post("/login/2fa/setup", authHandler.BeginTwoFactorLoginSetup)
+	authGroup.Post("/passkey/login/options", authHandler.BeginPasskeyLogin)
+	authGroup.Post("/passkey/login", authHandler.FinishPasskeyLogin)
+	authGroup.Post("/refresh", authHandler.RefreshToken)
 	authGroup.Get("/init-state", authHandler.InitState)
 	authGroup.Get("/providers", oauthHandler.ListProviders)
 	authGroup.Get("/providers/:provider/authorize", oauthHandler.Authorize)
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
+)
+
+// registerCitationPreviewPublicRoutes 公开接口只读缓存，不会由访客触发对外请求。
+func registerCitationPreviewPublicRoutes(v2 fiber.Router, svc *appfed.PostCacheService) {
+	if svc == nil {
+		return
+	}
+	previewHandler := handler.NewFederationCitationPreviewHandler(svc)
+	v2.Get("/federation/citations/preview", previewHandler.GetPreview)
+}
+
+func registerCitationPreviewAdminRoutes(v2 fiber.Router, deps Dependencies, svc *appfed.PostCacheService) {
+	if svc == nil {
+		return
+	}
+	previewHandler := handler.NewFederationCitationPreviewHandler(svc)
+	adminGroup := v2.Group("", middleware.RequireAuth(deps.JWTManager), middleware.RequireAdmin())
+	admin := adminGroup.Group("/admin")
+	admin.Get("/federation/citations/preview", previewHandler.ResolvePreview)
+}
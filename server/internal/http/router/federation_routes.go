@@ -1,18 +1,19 @@
 package router
 
 import (
-	"net/http"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/activitypub"
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
 	"github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
 	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence"
 )
 
-func registerFederationRoutes(app *fiber.App, deps Dependencies) {
+func registerFederationRoutes(app *fiber.App, deps Dependencies, apSvc *activitypub.Service, apActors *federation.ActorKeySource, keySvc *appfed.KeyService, rateLimiter *appfed.RateLimitService) {
 	cfgRepo := persistence.NewFederationConfigRepository(deps.DB)
 	cfgSvc := federationconfig.NewService(cfgRepo)
 	instanceRepo := persistence.NewFederationInstanceRepository(deps.DB)
@@ -23,32 +24,64 @@ func registerFederationRoutes(app *fiber.App, deps Dependencies) {
 	citationRepo := persistence.NewFederatedCitationRepository(deps.DB)
 	mentionRepo := persistence.NewFederatedMentionRepository(deps.DB)
 	postCacheRepo := persistence.NewFederatedPostCacheRepository(deps.DB)
+	ruleRepo := persistence.NewFederationDomainRuleRepository(deps.DB)
 
 	var cache federation.Cache
 	if deps.Redis != nil {
 		cache = federation.NewRedisCache(deps.Redis, deps.Config.Redis.Prefix)
 	}
-	resolver := federation.NewResolver(&http.Client{Timeout: 10 * time.Second}, cache)
-	verifier := federation.NewVerifier(resolver, 5*time.Minute)
+	resolver := federation.NewResolver(federation.NewGuardedHTTPClient(10*time.Second), cache)
+	// 被屏蔽的实例在校验签名前即被拒绝，不会进入任何入站处理逻辑。
+	instanceSvc := appfed.NewInstanceService(instanceRepo, ruleRepo, resolver)
+	replayCache := federation.NewReplayCache(deps.Redis, deps.Config.Redis.Prefix)
+	verifier := federation.NewVerifier(resolver, 5*time.Minute).
+		WithInstancePolicy(instanceSvc).
+		WithReplayCache(replayCache).
+		WithRateLimiter(rateLimiter)
 
-	wellKnownHandler := handler.NewFederationWellKnownHandler(cfgSvc, deps.Config.App)
+	wellKnownHandler := handler.NewFederationWellKnownHandler(cfgSvc, keySvc, deps.Config.App)
 	app.Get("/.well-known/blog-federation/manifest.json", wellKnownHandler.Manifest)
 	app.Get("/.well-known/blog-federation/public-key.json", wellKnownHandler.PublicKey)
 	app.Get("/.well-known/blog-federation/endpoints.json", wellKnownHandler.Endpoints)
 
 	federationGroup := app.Group("/api/federation")
-	friendLinkHandler := handler.NewFederationFriendLinkHandler(cfgSvc, instanceRepo, linkRepo, appRepo, resolver, verifier)
+	// 回执只会更新本站已有的出站申请，不需要出站签名能力。
+	friendLinkRequestSvc := appfed.NewFriendLinkRequestService(persistence.NewOutboundFriendLinkRepository(deps.DB), linkRepo, instanceSvc, nil)
+	friendLinkHandler := handler.NewFederationFriendLinkHandler(cfgSvc, instanceRepo, linkRepo, appRepo, friendLinkRequestSvc, resolver, verifier)
 	federationGroup.Post("/friendlinks/request", friendLinkHandler.RequestFriendLink)
+	federationGroup.Post("/friendlinks/callback", friendLinkHandler.ReceiveDecision)
 
 	timelineHandler := handler.NewFederationTimelineHandler(contentRepo, userRepo, cfgSvc)
-	federationGroup.Get("/timeline/posts", timelineHandler.ListTimelinePosts)
+	federationGroup.Get("/timeline/posts", handler.FederationRateLimit(rateLimiter, appfed.EndpointTimelineSync), timelineHandler.ListTimelinePosts)
 
 	postHandler := handler.NewFederationPostHandler(contentRepo, userRepo, postCacheRepo, cfgSvc)
-	federationGroup.Get("/posts/:id", postHandler.GetPostDetail)
+	federationGroup.Get("/posts/:id", handler.FederationRateLimit(rateLimiter, appfed.EndpointPostDetail), postHandler.GetPostDetail)
 
 	citationHandler := handler.NewFederationCitationHandler(cfgSvc, contentRepo, instanceRepo, citationRepo, linkRepo, resolver, verifier)
 	federationGroup.Post("/citations/request", citationHandler.RequestCitation)
 
 	mentionHandler := handler.NewFederationMentionHandler(cfgSvc, instanceRepo, mentionRepo, userRepo, resolver, verifier)
 	federationGroup.Post("/mentions/notify", mentionHandler.NotifyMention)
+
+	commentRepo := persistence.NewFederatedCommentRepository(deps.DB)
+	commentSvc := appfed.NewCommentService(commentRepo, persistence.NewCommentRepository(deps.DB), contentRepo, linkRepo, instanceRepo, postCacheRepo, nil)
+	commentHandler := handler.NewFederationCommentHandler(cfgSvc, commentSvc, instanceRepo, userRepo, resolver, verifier)
+	federationGroup.Post("/comments", commentHandler.ReceiveComment)
+
+	// ActivityPub 的 keyId 指向 Actor 文档而非 well-known 公钥，使用单独的校验器。
+	apVerifier := federation.NewVerifier(nil, 5*time.Minute).
+		WithKeySource(apActors).
+		WithInstancePolicy(instanceSvc).
+		WithReplayCache(replayCache).
+		WithRateLimiter(rateLimiter)
+	apHandler := handler.NewActivityPubHandler(apSvc, apVerifier)
+	app.Get("/.well-known/webfinger", apHandler.WebFinger)
+	apGroup := app.Group("/ap")
+	apGroup.Get("/users/:username", apHandler.Actor)
+	apGroup.Get("/users/:username/outbox", apHandler.Outbox)
+	apGroup.Get("/users/:username/followers", apHandler.Followers)
+	apGroup.Post("/users/:username/inbox", apHandler.Inbox)
+	apGroup.Post("/inbox", apHandler.Inbox)
+	apGroup.Get("/articles/:shortURL", apHandler.Article)
+	apGroup.Get("/moments/:shortURL", apHandler.Moment)
 }
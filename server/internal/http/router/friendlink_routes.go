@@ -0,0 +1,52 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/friendlink"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence"
+)
+
+func registerFriendLinkPublicRoutes(v2 fiber.Router, deps Dependencies) {
+	linkRepo := persistence.NewFriendLinkRepository(deps.DB)
+	postRepo := persistence.NewFriendLinkPostRepository(deps.DB)
+	circleSvc := friendlink.NewCircleService(linkRepo, postRepo)
+	circleHandler := handler.NewFriendCircleHandler(circleSvc)
+	linkHandler := newFriendLinkAdminHandler(deps, nil)
+
+	public := v2.Group("/public")
+	public.Get("/friend-links", linkHandler.ListPublic) // GET /api/v2/public/friend-links
+	public.Get("/friend-circle", circleHandler.List)    // GET /api/v2/public/friend-circle
+}
+
+func registerFriendLinkAdminRoutes(v2 fiber.Router, deps Dependencies, outbound *appfed.OutboundService, checker *friendlink.HealthChecker) {
+	adminGroup := v2.Group("", middleware.RequireAuth(deps.JWTManager), middleware.RequireAdmin())
+	linkHandler := newFriendLinkAdminHandler(deps, outbound)
+	healthHandler := handler.NewFriendLinkHealthHandler(checker)
+
+	admin := adminGroup.Group("/admin/friend-links")
+	admin.Get("/applications", linkHandler.ListApplications)
+	admin.Post("/applications/:id/approve", linkHandler.ApproveApplication)
+	admin.Post("/applications/:id/reject", linkHandler.RejectApplication)
+	admin.Get("/health", healthHandler.ListHealth)
+	admin.Post("/:id/health-check", healthHandler.CheckNow)
+	admin.Get("", linkHandler.ListLinks)
+	admin.Post("", linkHandler.CreateLink)
+	admin.Put("/:id", linkHandler.UpdateLink)
+	admin.Delete("/:id", linkHandler.DeleteLink)
+}
+
+func newFriendLinkAdminHandler(deps Dependencies, outbound *appfed.OutboundService) *handler.FriendLinkAdminHandler {
+	appRepo := persistence.NewFriendLinkApplicationRepository(deps.DB)
+	linkRepo := persistence.NewFriendLinkRepository(deps.DB)
+	instanceRepo := persistence.NewFederationInstanceRepository(deps.DB)
+	var sender friendlink.DecisionSender
+	if outbound != nil {
+		sender = outbound
+	}
+	svc := friendlink.NewAdminService(appRepo, linkRepo, instanceRepo, sender)
+	return handler.NewFriendLinkAdminHandler(svc)
+}
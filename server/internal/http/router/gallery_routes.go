@@ -0,0 +1,49 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/gallery"
+	mediaapp "github.com/grtsinry43/grtblog-v2/server/internal/app/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence"
+)
+
+func registerGalleryPublicRoutes(v2 fiber.Router, deps Dependencies, uploadSvc *mediaapp.Service) {
+	galleryHandler := newGalleryHandler(deps, uploadSvc)
+
+	publicGroup := v2.Group("/gallery", middleware.OptionalAuth(deps.JWTManager))
+	publicGroup.Get("/albums", galleryHandler.ListAlbums)
+	publicGroup.Get("/albums/:id", galleryHandler.GetAlbum)
+	publicGroup.Get("/albums/:id/photos", galleryHandler.ListAlbumPhotos)
+	publicGroup.Get("/photos", galleryHandler.ListPhotos)
+	publicGroup.Get("/photos/:id", galleryHandler.GetPhoto)
+	publicGroup.Post("/photos/:id/like", galleryHandler.LikePhoto)
+	publicGroup.Delete("/photos/:id/like", galleryHandler.UnlikePhoto)
+}
+
+func registerGalleryAdminRoutes(v2 fiber.Router, deps Dependencies, uploadSvc *mediaapp.Service) {
+	galleryHandler := newGalleryHandler(deps, uploadSvc)
+
+	adminGroup := v2.Group("/admin/gallery", middleware.RequireAuth(deps.JWTManager), middleware.RequireAdmin())
+	adminGroup.Post("/photos/upload", galleryHandler.UploadPhoto)
+	adminGroup.Post("/photos", galleryHandler.CreatePhoto)
+	adminGroup.Put("/photos/:id", galleryHandler.UpdatePhoto)
+	adminGroup.Delete("/photos/:id", galleryHandler.DeletePhoto)
+	adminGroup.Post("/albums", galleryHandler.CreateAlbum)
+	adminGroup.Put("/albums/reorder", galleryHandler.ReorderAlbums)
+	adminGroup.Put("/albums/:id", galleryHandler.UpdateAlbum)
+	adminGroup.Delete("/albums/:id", galleryHandler.DeleteAlbum)
+	adminGroup.Put("/albums/:id/photos/reorder", galleryHandler.ReorderAlbumPhotos)
+}
+
+func newGalleryHandler(deps Dependencies, uploadSvc *mediaapp.Service) *handler.GalleryHandler {
+	svc := gallery.NewService(
+		persistence.NewPhotoRepository(deps.DB),
+		persistence.NewAlbumRepository(deps.DB),
+		persistence.NewLikeRepository(deps.DB),
+		uploadSvc,
+	)
+	return handler.NewGalleryHandler(svc)
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/moment"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
@@ -10,7 +11,7 @@ import (
 )
 
 func registerMomentPublicRoutes(v2 fiber.Router, deps Dependencies) {
-	momentHandler := newMomentHandler(deps)
+	momentHandler := newMomentHandler(deps, nil)
 
 	publicGroup := v2.Group("/moments")
 	publicGroup.Get("/", momentHandler.ListMoments)                        // GET /api/v2/moments
@@ -19,8 +20,8 @@ func registerMomentPublicRoutes(v2 fiber.Router, deps Dependencies) {
 	publicGroup.Post("/:id/latest", momentHandler.CheckMomentLatest)       // POST /api/v2/moments/123/latest
 }
 
-func registerMomentAuthRoutes(v2 fiber.Router, deps Dependencies) {
-	momentHandler := newMomentHandler(deps)
+func registerMomentAuthRoutes(v2 fiber.Router, deps Dependencies, localizer contentutil.ImageLocalizer) {
+	momentHandler := newMomentHandler(deps, localizer)
 
 	authGroup := v2.Group("/moments", middleware.RequireAuth(deps.JWTManager))
 	authGroup.Post("/", momentHandler.CreateMoment)      // POST /api/v2/moments
@@ -28,9 +29,9 @@ func registerMomentAuthRoutes(v2 fiber.Router, deps Dependencies) {
 	authGroup.Delete("/:id", momentHandler.DeleteMoment) // DELETE /api/v2/moments/123
 }
 
-func newMomentHandler(deps Dependencies) *handler.MomentHandler {
+func newMomentHandler(deps Dependencies, localizer contentutil.ImageLocalizer) *handler.MomentHandler {
 	contentRepo := persistence.NewContentRepository(deps.DB)
 	identityRepo := persistence.NewIdentityRepository(deps.DB)
-	momentSvc := moment.NewService(contentRepo, deps.EventBus)
+	momentSvc := moment.NewService(contentRepo, deps.EventBus).WithImageLocalizer(localizer)
 	return handler.NewMomentHandler(momentSvc, contentRepo, identityRepo)
 }
@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/gofiber/fiber/v2"
 
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/page"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
@@ -10,7 +11,7 @@ import (
 )
 
 func registerPagePublicRoutes(v2 fiber.Router, deps Dependencies) {
-	pageHandler := newPageHandler(deps)
+	pageHandler := newPageHandler(deps, nil)
 
 	publicGroup := v2.Group("/pages")
 	publicGroup.Get("/", pageHandler.ListPages)                        // GET /api/v2/pages
@@ -19,8 +20,8 @@ func registerPagePublicRoutes(v2 fiber.Router, deps Dependencies) {
 	publicGroup.Post("/:id/latest", pageHandler.CheckPageLatest)       // POST /api/v2/pages/123/latest
 }
 
-func registerPageAuthRoutes(v2 fiber.Router, deps Dependencies) {
-	pageHandler := newPageHandler(deps)
+func registerPageAuthRoutes(v2 fiber.Router, deps Dependencies, localizer contentutil.ImageLocalizer) {
+	pageHandler := newPageHandler(deps, localizer)
 
 	authGroup := v2.Group("/pages", middleware.RequireAuth(deps.JWTManager))
 	authGroup.Post("/", pageHandler.CreatePage)      // POST /api/v2/pages
@@ -28,8 +29,8 @@ func registerPageAuthRoutes(v2 fiber.Router, deps Dependencies) {
 	authGroup.Delete("/:id", pageHandler.DeletePage) // DELETE /api/v2/pages/123
 }
 
-func newPageHandler(deps Dependencies) *handler.PageHandler {
+func newPageHandler(deps Dependencies, localizer contentutil.ImageLocalizer) *handler.PageHandler {
 	contentRepo := persistence.NewContentRepository(deps.DB)
-	pageSvc := page.NewService(contentRepo, deps.EventBus)
+	pageSvc := page.NewService(contentRepo, deps.EventBus).WithImageLocalizer(localizer)
 	return handler.NewPageHandler(pageSvc)
 }
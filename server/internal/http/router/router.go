@@ -3,21 +3,24 @@ package router
 import (
 	"context"
 	"log"
-	"net/http"
-	"path/filepath"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/activitypub"
 	appEvent "github.com/grtsinry43/grtblog-v2/server/internal/app/event"
 	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/federationconfig"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/friendlink"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/htmlsnapshot"
+	mediaapp "github.com/grtsinry43/grtblog-v2/server/internal/app/media"
 	appnav "github.com/grtsinry43/grtblog-v2/server/internal/app/navigation"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/sysconfig"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/webhook"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/webmention"
 	"github.com/grtsinry43/grtblog-v2/server/internal/app/websiteinfo"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/websub"
 	"github.com/grtsinry43/grtblog-v2/server/internal/config"
 	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
 	infraevent "github.com/grtsinry43/grtblog-v2/server/internal/infra/event"
@@ -46,7 +49,8 @@ func Register(app *fiber.App, deps Dependencies) {
 
 	app.Get("/health/liveness", healthHandler.Liveness)
 	app.Get("/health/readiness", healthHandler.Readiness)
-	app.Static("/uploads", filepath.Join("storage", "uploads"))
+	uploadSvc := newUploadService(deps)
+	app.Get("/uploads/*", handler.NewUploadHandler(uploadSvc, deps.Config.Storage.SignedURLTTL).ServeObject)
 
 	api := app.Group("/api")
 	v2 := api.Group("/v2")
@@ -57,6 +61,8 @@ func Register(app *fiber.App, deps Dependencies) {
 		sysCfgSvc = sysconfig.NewService(sysCfgRepo, deps.Config.Turnstile)
 	}
 	deps.SysConfig = sysCfgSvc
+	uploadSvc.WithLimits(sysCfgSvc)
+	imageLocalizer := newImageLocalizer(deps, uploadSvc)
 	eventBus := deps.EventBus
 	if eventBus == nil {
 		eventBus = infraevent.NewInMemoryBus()
@@ -69,6 +75,7 @@ func Register(app *fiber.App, deps Dependencies) {
 	ws.RegisterArticleUpdateSubscriber(eventBus, wsManager)
 	ws.RegisterMomentUpdateSubscriber(eventBus, wsManager)
 	ws.RegisterPageUpdateSubscriber(eventBus, wsManager)
+	mediaapp.RegisterUsageSubscribers(eventBus, uploadSvc.UsageTracker())
 
 	webhookSettings, err := sysCfgSvc.WebhookSettings(context.Background())
 	if err != nil {
@@ -91,9 +98,57 @@ func Register(app *fiber.App, deps Dependencies) {
 	if deps.Redis != nil {
 		fedCache = fedinfra.NewRedisCache(deps.Redis, deps.Config.Redis.Prefix)
 	}
-	fedResolver := fedinfra.NewResolver(&http.Client{Timeout: 10 * time.Second}, fedCache)
-	fedOutbound := appfed.NewOutboundService(fedCfgSvc, fedResolver, fedInstanceRepo)
+	fedResolver := fedinfra.NewResolver(fedinfra.NewGuardedHTTPClient(10*time.Second), fedCache)
+	fedKeySvc := appfed.NewKeyService(fedCfgSvc, persistence.NewFederationSigningKeyRepository(deps.DB))
+	if err := fedKeySvc.SyncConfigKeys(context.Background()); err != nil {
+		log.Printf("federation signing keys sync error: %v", err)
+	}
+	fedOutbound := appfed.NewOutboundService(fedCfgSvc, fedKeySvc, fedResolver, fedInstanceRepo)
 	appfed.RegisterSubscribers(eventBus, fedOutbound)
+	fedInstanceSvc := appfed.NewInstanceService(fedInstanceRepo, persistence.NewFederationDomainRuleRepository(deps.DB), fedResolver)
+	// 入站限流器在联合、Webmention 等公开端点间共享，令牌桶存放在 Redis（不可用时退回内存）。
+	fedRateLimiter := appfed.NewRateLimitService(fedCfgSvc, fedinfra.NewRateLimitStore(deps.Redis, deps.Config.Redis.Prefix), fedInstanceRepo)
+	fedPostCache := appfed.NewPostCacheService(persistence.NewFederatedPostCacheRepository(deps.DB), fedInstanceSvc, nil, 30*time.Minute)
+	appfed.RegisterCitationCacheSubscriber(eventBus, fedPostCache)
+	apActors := fedinfra.NewActorKeySource(fedinfra.NewGuardedHTTPClient(10*time.Second), fedinfra.DefaultActorKeyTTL)
+	apSvc := activitypub.NewService(
+		fedCfgSvc,
+		fedKeySvc,
+		contentRepo,
+		persistence.NewIdentityRepository(deps.DB),
+		persistence.NewCommentRepository(deps.DB),
+		persistence.NewActivityPubFollowerRepository(deps.DB),
+		persistence.NewActivityPubInteractionRepository(deps.DB),
+		apActors,
+		fedInstanceSvc,
+	)
+	apActors.WithFetchSigner(apSvc.SignFetch)
+	activitypub.RegisterSubscribers(eventBus, apSvc)
+	webmentionSvc := webmention.NewService(
+		fedCfgSvc,
+		contentRepo,
+		persistence.NewCommentRepository(deps.DB),
+		persistence.NewWebmentionRepository(deps.DB),
+		persistence.NewWebmentionSendRepository(deps.DB),
+		fedInstanceSvc,
+		2,
+	)
+	webmention.RegisterSubscribers(eventBus, webmentionSvc)
+
+	friendLinkRepo := persistence.NewFriendLinkRepository(deps.DB)
+	friendLinkPostRepo := persistence.NewFriendLinkPostRepository(deps.DB)
+	friendLinkSyncer := friendlink.NewSyncer(friendLinkRepo, friendLinkPostRepo, sysCfgSvc, 5*time.Minute).
+		WithWebSub(persistence.NewFriendLinkWebSubRepository(deps.DB), fedCfgSvc)
+	websubSvc := websub.NewService(fedCfgSvc, contentRepo, persistence.NewWebSubSubscriptionRepository(deps.DB))
+	websub.RegisterSubscribers(eventBus, websubSvc)
+	friendLinkHealth := friendlink.NewHealthChecker(friendLinkRepo, persistence.NewFriendLinkHealthRepository(deps.DB), sysCfgSvc, fedCfgSvc, 10*time.Minute)
+	app.Hooks().OnShutdown(func() error {
+		friendLinkSyncer.Close()
+		friendLinkHealth.Close()
+		websubSvc.Close()
+		fedPostCache.Close()
+		return nil
+	})
 
 	websiteInfoRepo := persistence.NewWebsiteInfoRepository(deps.DB)
 	websiteInfoSvc := websiteinfo.NewService(websiteInfoRepo)
@@ -104,6 +159,8 @@ func Register(app *fiber.App, deps Dependencies) {
 	navMenuHandler := handler.NewNavMenuHandler(navMenuSvc)
 
 	registerPublicRoutes(v2, deps, websiteInfoHandler, htmlSnapshotSvc, navMenuHandler)
+	registerFriendLinkPublicRoutes(v2, deps)
+	registerCitationPreviewPublicRoutes(v2, fedPostCache)
 	registerAuthRoutes(v2, deps, sysCfgSvc)
 	deps.EventBus = eventBus
 	registerWSRoutes(v2, wsManager)
@@ -113,19 +170,34 @@ func Register(app *fiber.App, deps Dependencies) {
 	registerPagePublicRoutes(v2, deps)
 	registerTaxonomyPublicRoutes(v2, deps)
 	registerCommentPublicRoutes(v2, deps)
-	registerUserRoutes(v2, deps, websiteInfoHandler)
-	registerArticleAuthRoutes(v2, deps)
-	registerMomentAuthRoutes(v2, deps)
+	registerGalleryPublicRoutes(v2, deps, uploadSvc)
+	resumableSvc := newResumableUploadService(deps, uploadSvc)
+	app.Hooks().OnShutdown(func() error {
+		resumableSvc.Close()
+		return nil
+	})
+	// 普通上传与分片上传会话共用一个限流器，额度按用户合并计算。
+	uploadLimiter := newUploadRateLimiter(deps)
+	registerUploadSessionRoutes(v2, deps, resumableSvc, uploadLimiter)
+	registerUserRoutes(v2, deps, websiteInfoHandler, uploadSvc, uploadLimiter)
+	registerArticleAuthRoutes(v2, deps, imageLocalizer)
+	registerMomentAuthRoutes(v2, deps, imageLocalizer)
 	registerThinkingAuthRoutes(v2, deps)
-	registerPageAuthRoutes(v2, deps)
+	registerPageAuthRoutes(v2, deps, imageLocalizer)
 	registerCommentAuthRoutes(v2, deps)
-	registerAdminRoutes(v2, deps, websiteInfoHandler, navMenuHandler, sysCfgSvc)
+	registerGalleryAdminRoutes(v2, deps, uploadSvc)
+	registerUploadAdminRoutes(v2, deps, uploadSvc, imageLocalizer)
+	registerAdminRoutes(v2, deps, websiteInfoHandler, navMenuHandler, sysCfgSvc, fedKeySvc)
 	registerTaxonomyAdminRoutes(v2, deps)
 	registerWebhookAdminRoutes(v2, deps, webhookSvc)
+	registerFriendLinkAdminRoutes(v2, deps, fedOutbound, friendLinkHealth)
+	registerWebmentionRoutes(app, v2, deps, webmentionSvc, fedRateLimiter)
+	registerWebSubRoutes(app, v2, deps, websubSvc, friendLinkSyncer, fedRateLimiter)
+	registerCitationPreviewAdminRoutes(v2, deps, fedPostCache)
 
 	docsHandler := handler.NewDocsHandler("docs/swagger.json")
 	app.Get("/docs/openapi.json", docsHandler.OpenAPI)
 	app.Get("/docs", docsHandler.Scalar)
 
-	registerFederationRoutes(app, deps)
+	registerFederationRoutes(app, deps, apSvc, apActors, fedKeySvc, fedRateLimiter)
 }
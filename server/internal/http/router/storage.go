@@ -0,0 +1,153 @@
+package router
+
+import (
+	"context"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/article"
+	mediaapp "github.com/grtsinry43/grtblog-v2/server/internal/app/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/moment"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/page"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/response"
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/storage"
+)
+
+// newUploadService 按 STORAGE_DRIVER 创建上传服务；另一种驱动已配置时一并注册，迁移期间旧路径仍可读取。
+func newUploadService(deps Dependencies) *mediaapp.Service {
+	cfg := deps.Config.Storage
+	if cfg.SignSecret == "" {
+		// 多副本部署需共享签名密钥，未单独配置时沿用 JWT 密钥。
+		cfg.SignSecret = deps.Config.Auth.Secret
+	}
+	primary, err := storage.New(cfg, cfg.Driver)
+	if err != nil {
+		log.Fatalf("failed to initialize storage driver: %v", err)
+	}
+	var others []storage.Driver
+	if primary.Name() != storage.DriverLocal {
+		local, _ := storage.New(cfg, storage.DriverLocal)
+		others = append(others, local)
+	} else if cfg.S3.Bucket != "" {
+		if s3, err := storage.New(cfg, storage.DriverS3); err == nil {
+			others = append(others, s3)
+		} else {
+			log.Printf("[storage] s3 driver disabled: %v", err)
+		}
+	}
+	files := persistence.NewUploadFileRepository(deps.DB)
+	usages := persistence.NewUploadFileUsageRepository(deps.DB)
+	drivers := []string{primary.Name()}
+	for _, driver := range others {
+		drivers = append(drivers, driver.Name())
+	}
+	tracker := mediaapp.NewUsageTracker(files, usages, persistence.NewContentRepository(deps.DB), persistence.NewThinkingRepository(deps.DB), drivers...)
+	svc := mediaapp.NewService(files, primary, others...).
+		WithPrivate(cfg.Private).
+		WithUsageTracking(usages, tracker, cfg.OrphanGrace)
+	if img := deps.Config.Image; img.Enabled {
+		svc.WithImagePipeline(mediaapp.NewImagePipeline(mediaapp.ImageOptions{
+			VariantWidths:  img.VariantWidths,
+			VariantFormats: img.VariantFormats,
+			Quality:        img.Quality,
+			MaxPixels:      img.MaxPixels,
+			MaxResizeWidth: img.MaxResizeWidth,
+			CacheDir:       img.CacheDir,
+		}))
+	}
+	return svc
+}
+
+// newImageLocalizer 创建外链图片转存器；下载走 SSRF 防护的 client，单张大小沿用全局上传限制。
+func newImageLocalizer(deps Dependencies, uploadSvc *mediaapp.Service) *mediaapp.ImageLocalizer {
+	opts := mediaapp.LocalizeOptions{MaxImages: 50}
+	if deps.SysConfig != nil {
+		opts.MaxSize = int64(deps.SysConfig.UploadMaxSizeBytes(context.Background()))
+	}
+	client := fedinfra.NewGuardedHTTPClient(15 * time.Second)
+	return mediaapp.NewImageLocalizer(uploadSvc, persistence.NewUploadFileSourceRepository(deps.DB), client, opts)
+}
+
+func registerUploadAdminRoutes(v2 fiber.Router, deps Dependencies, uploadSvc *mediaapp.Service, localizer *mediaapp.ImageLocalizer) {
+	uploadHandler := handler.NewUploadHandler(uploadSvc, deps.Config.Storage.SignedURLTTL)
+	contentRepo := persistence.NewContentRepository(deps.DB)
+	localizeJob := mediaapp.NewLocalizeJob(contentRepo, map[string]mediaapp.LocalizeTarget{
+		media.UsageOwnerArticle: article.NewService(contentRepo, deps.EventBus).WithImageLocalizer(localizer).LocalizeArticleImages,
+		media.UsageOwnerMoment:  moment.NewService(contentRepo, deps.EventBus).WithImageLocalizer(localizer).LocalizeMomentImages,
+		media.UsageOwnerPage:    page.NewService(contentRepo, deps.EventBus).WithImageLocalizer(localizer).LocalizePageImages,
+	})
+	localizeHandler := handler.NewUploadLocalizeHandler(localizeJob)
+
+	adminGroup := v2.Group("/admin/uploads", middleware.RequireAuth(deps.JWTManager), middleware.RequireAdmin())
+	adminGroup.Get("/orphans", uploadHandler.ListOrphanUploads)
+	adminGroup.Post("/gc", uploadHandler.CollectUploadGarbage)
+	adminGroup.Get("/localize", localizeHandler.GetLocalizeStatus)
+	adminGroup.Post("/localize", localizeHandler.StartLocalize)
+}
+
+// newResumableUploadService 创建分片上传服务；分片大小不超过全局请求体限制，否则分片请求会被 Fiber 拒绝。
+func newResumableUploadService(deps Dependencies, uploadSvc *mediaapp.Service) *mediaapp.ResumableService {
+	cfg := deps.Config.Storage.Resumable
+	chunkSize := int64(cfg.ChunkSizeMB) << 20
+	if deps.SysConfig != nil {
+		if limit := int64(deps.SysConfig.UploadMaxSizeBytes(context.Background())); chunkSize > limit {
+			chunkSize = limit
+		}
+	}
+	return mediaapp.NewResumableService(uploadSvc, persistence.NewUploadSessionRepository(deps.DB), mediaapp.ResumableOptions{
+		MaxSize:         int64(cfg.MaxSizeMB) << 20,
+		ChunkSize:       chunkSize,
+		SessionTTL:      cfg.SessionTTL,
+		TempDir:         cfg.TempDir,
+		CleanupInterval: 30 * time.Minute,
+	})
+}
+
+func registerUploadSessionRoutes(v2 fiber.Router, deps Dependencies, resumableSvc *mediaapp.ResumableService, uploadLimiter fiber.Handler) {
+	sessionHandler := handler.NewUploadSessionHandler(resumableSvc)
+	sessions := v2.Group("/upload/sessions", middleware.RequireAuth(deps.JWTManager))
+	sessions.Post("", uploadLimiter, sessionHandler.InitSession)
+	sessions.Get("/:id", sessionHandler.GetSession)
+	sessions.Put("/:id/chunks/:index", sessionHandler.PutChunk)
+	sessions.Post("/:id/complete", sessionHandler.CompleteSession)
+	sessions.Delete("/:id", sessionHandler.AbortSession)
+}
+
+// newUploadRateLimiter 按用户限制上传频率，管理员不受限制。
+// upload.ratePerMinute 在每次请求时读取；令牌桶存放在 Redis（不可用时退回内存），多副本与各上传入口共享同一额度。
+func newUploadRateLimiter(deps Dependencies) fiber.Handler {
+	store := fedinfra.NewRateLimitStore(deps.Redis, deps.Config.Redis.Prefix)
+	return func(c *fiber.Ctx) error {
+		key := "upload:" + c.IP()
+		if claims, ok := middleware.GetClaims(c); ok {
+			if claims.IsAdmin {
+				return c.Next()
+			}
+			key = "upload:" + strconv.FormatInt(claims.UserID, 10)
+		}
+		rate := 20
+		if deps.SysConfig != nil {
+			rate = deps.SysConfig.UploadRatePerMinute(c.Context())
+		}
+		allowed, wait, err := store.Take(c.Context(), key, float64(rate), float64(rate)/60)
+		if err != nil {
+			log.Printf("[upload] 限流存储异常，本次放行 key=%s err=%v", key, err)
+			return c.Next()
+		}
+		if !allowed {
+			handler.Audit(c, "upload.rate_limited", map[string]any{"ip": c.IP()})
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(int(math.Ceil(wait.Seconds())), 1)))
+			return response.NewBizErrorWithMsg(response.TooManyRequests, "")
+		}
+		return c.Next()
+	}
+}
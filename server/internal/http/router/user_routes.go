@@ -11,18 +11,39 @@ import (
 	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence"
 )
 
-func registerUserRoutes(v2 fiber.Router, deps Dependencies, websiteInfoHandler *handler.WebsiteInfoHandler) {
+func registerUserRoutes(v2 fiber.Router, deps Dependencies, websiteInfoHandler *handler.WebsiteInfoHandler, uploadSvc *mediaapp.Service, uploadLimiter fiber.Handler) {
 	authenticated := v2.Group("", middleware.RequireAuth(deps.JWTManager))
 
 	identityRepo := persistence.NewIdentityRepository(deps.DB)
 	oauthRepo := persistence.NewOAuthProviderRepository(deps.DB)
-	authSvc := auth.NewService(identityRepo, oauthRepo, deps.JWTManager, nil, deps.Config.Auth)
+	var passkeySessions auth.PasskeySessionStore = auth.NewMemoryPasskeySessionStore()
+	if deps.Redis != nil {
+		passkeySessions = auth.NewRedisPasskeySessionStore(deps.Redis, deps.Config.Redis.Prefix)
+	}
+	authSvc := auth.NewService(identityRepo, oauthRepo, deps.JWTManager, nil, deps.Config.Auth).
+		WithTwoFactor(persistence.NewTwoFactorRepository(deps.DB), nil, deps.SysConfig, deps.Config.App.Name).
+		WithPasskeys(persistence.NewPasskeyRepository(deps.DB), passkeySessions, deps.Config.WebAuthn).
+		WithSessions(persistence.NewSessionRepository(deps.DB), nil, nil)
 	authHandler := handler.NewAuthHandler(authSvc, nil, nil)
 	authenticated.Get("/auth/access-info", authHandler.AccessInfo)
 	authenticated.Get("/auth/profile", authHandler.Profile)
 	authenticated.Put("/auth/profile", authHandler.UpdateProfile)
 	authenticated.Put("/auth/password", authHandler.ChangePassword)
 	authenticated.Get("/auth/oauth-bindings", authHandler.ListOAuthBindings)
+	authenticated.Get("/auth/2fa", authHandler.TwoFactorStatus)
+	authenticated.Post("/auth/2fa/setup", authHandler.SetupTwoFactor)
+	authenticated.Post("/auth/2fa/enable", authHandler.EnableTwoFactor)
+	authenticated.Post("/auth/2fa/disable", authHandler.DisableTwoFactor)
+	authenticated.Post("/auth/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+	authenticated.Get("/auth/passkeys", authHandler.ListPasskeys)
+	authenticated.Post("/auth/passkeys/options", authHandler.BeginPasskeyRegistration)
+	authenticated.Post("/auth/passkeys", authHandler.FinishPasskeyRegistration)
+	authenticated.Put("/auth/passkeys/:id", authHandler.RenamePasskey)
+	authenticated.Delete("/auth/passkeys/:id", authHandler.DeletePasskey)
+	authenticated.Post("/auth/logout", authHandler.Logout)
+	authenticated.Get("/auth/sessions", authHandler.ListSessions)
+	authenticated.Delete("/auth/sessions", authHandler.RevokeAllSessions)
+	authenticated.Delete("/auth/sessions/:id", authHandler.RevokeSession)
 
 	friendLinkRepo := persistence.NewFriendLinkApplicationRepository(deps.DB)
 	friendLinkSvc := friendlink.NewService(friendLinkRepo)
@@ -30,12 +51,12 @@ func registerUserRoutes(v2 fiber.Router, deps Dependencies, websiteInfoHandler *
 	friendLinks := authenticated.Group("/friend-links")
 	friendLinks.Post("/applications", friendLinkHandler.SubmitApplication)
 
-	uploadRepo := persistence.NewUploadFileRepository(deps.DB)
-	uploadSvc := mediaapp.NewService(uploadRepo, "")
-	uploadHandler := handler.NewUploadHandler(uploadSvc)
-	authenticated.Post("/upload", uploadHandler.UploadFile)
+	uploadHandler := handler.NewUploadHandler(uploadSvc, deps.Config.Storage.SignedURLTTL)
+	authenticated.Post("/upload", uploadLimiter, uploadHandler.UploadFile)
 	authenticated.Get("/uploads", uploadHandler.ListUploads)
 	authenticated.Put("/upload/:id", uploadHandler.RenameUpload)
 	authenticated.Delete("/upload/:id", uploadHandler.DeleteUpload)
 	authenticated.Get("/upload/:id/download", uploadHandler.DownloadUpload)
+	authenticated.Get("/upload/:id/usages", uploadHandler.ListUploadUsages)
+	authenticated.Get("/upload/:id/signed-url", uploadHandler.SignedUploadURL)
 }
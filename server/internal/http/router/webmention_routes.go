@@ -0,0 +1,28 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/webmention"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
+)
+
+func registerWebmentionRoutes(app *fiber.App, v2 fiber.Router, deps Dependencies, svc *webmention.Service, rateLimiter *appfed.RateLimitService) {
+	if svc == nil {
+		return
+	}
+	webmentionHandler := handler.NewWebmentionHandler(svc)
+
+	// 接收端点对外公开，按来源 IP 限流。
+	app.Post("/api/webmention", handler.FederationRateLimit(rateLimiter, appfed.EndpointWebmention), webmentionHandler.Receive)
+
+	adminGroup := v2.Group("", middleware.RequireAuth(deps.JWTManager), middleware.RequireAdmin())
+	admin := adminGroup.Group("/admin")
+	admin.Get("/webmentions", webmentionHandler.ListWebmentions)
+	admin.Post("/webmentions/:id/verify", webmentionHandler.VerifyWebmention)
+	admin.Delete("/webmentions/:id", webmentionHandler.DeleteWebmention)
+	admin.Get("/webmentions/sends/:articleId", webmentionHandler.ListSends)
+	admin.Post("/webmentions/sends/:articleId", webmentionHandler.ResendArticle)
+}
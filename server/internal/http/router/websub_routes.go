@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	appfed "github.com/grtsinry43/grtblog-v2/server/internal/app/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/friendlink"
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/websub"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/handler"
+	"github.com/grtsinry43/grtblog-v2/server/internal/http/middleware"
+)
+
+func registerWebSubRoutes(app *fiber.App, v2 fiber.Router, deps Dependencies, svc *websub.Service, syncer *friendlink.Syncer, rateLimiter *appfed.RateLimitService) {
+	if svc == nil {
+		return
+	}
+	websubHandler := handler.NewWebSubHandler(svc, syncer)
+
+	app.Get("/api/feeds/articles.xml", websubHandler.ArticlesFeed)
+	app.Get("/api/feeds/moments.xml", websubHandler.MomentsFeed)
+
+	// Hub 端点对外公开，按来源 IP 限流。
+	app.Post(websub.HubPath, handler.FederationRateLimit(rateLimiter, appfed.EndpointWebSubHub), websubHandler.Hub)
+
+	// 友链订阅的回调地址，令牌不可猜测，无需额外鉴权。
+	app.Get(friendlink.WebSubCallbackPath+":token", websubHandler.VerifyCallback)
+	app.Post(friendlink.WebSubCallbackPath+":token", websubHandler.ReceiveCallback)
+
+	adminGroup := v2.Group("", middleware.RequireAuth(deps.JWTManager), middleware.RequireAdmin())
+	admin := adminGroup.Group("/admin")
+	admin.Get("/websub/subscriptions", websubHandler.ListSubscriptions)
+	admin.Delete("/websub/subscriptions/:id", websubHandler.DeleteSubscription)
+}
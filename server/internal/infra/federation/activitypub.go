@@ -0,0 +1,278 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ActivityStreamsContext  = "https://www.w3.org/ns/activitystreams"
+	SecurityContext         = "https://w3id.org/security/v1"
+	ActivityStreamsPublic   = "https://www.w3.org/ns/activitystreams#Public"
+	ContentTypeActivityJSON = "application/activity+json"
+	ContentTypeJRD          = "application/jrd+json"
+
+	// DefaultActorKeyTTL mirrors DefaultPublicKeyTTL for ActivityPub actor keys.
+	DefaultActorKeyTTL = DefaultPublicKeyTTL
+
+	maxActivityPubDocumentBytes = 1 << 20
+)
+
+// APContext is the @context used for actors, which carry a publicKey.
+var APContext = []string{ActivityStreamsContext, SecurityContext}
+
+// WebFingerDoc mirrors a WebFinger JRD response.
+type WebFingerDoc struct {
+	Subject string          `json:"subject"`
+	Aliases []string        `json:"aliases,omitempty"`
+	Links   []WebFingerLink `json:"links"`
+}
+
+type WebFingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href,omitempty"`
+}
+
+// APActor is an ActivityPub Person/Service document.
+type APActor struct {
+	Context                   any          `json:"@context,omitempty"`
+	ID                        string       `json:"id"`
+	Type                      string       `json:"type"`
+	PreferredUsername         string       `json:"preferredUsername,omitempty"`
+	Name                      string       `json:"name,omitempty"`
+	Summary                   string       `json:"summary,omitempty"`
+	URL                       string       `json:"url,omitempty"`
+	Inbox                     string       `json:"inbox"`
+	Outbox                    string       `json:"outbox,omitempty"`
+	Followers                 string       `json:"followers,omitempty"`
+	Following                 string       `json:"following,omitempty"`
+	Icon                      *APImage     `json:"icon,omitempty"`
+	Endpoints                 *APEndpoints `json:"endpoints,omitempty"`
+	PublicKey                 *APPublicKey `json:"publicKey,omitempty"`
+	ManuallyApprovesFollowers bool         `json:"manuallyApprovesFollowers"`
+	Discoverable              bool         `json:"discoverable"`
+}
+
+// SharedInboxOrInbox prefers the shared inbox so one delivery covers every follower on a server.
+func (a *APActor) SharedInboxOrInbox() string {
+	if a.Endpoints != nil && strings.TrimSpace(a.Endpoints.SharedInbox) != "" {
+		return a.Endpoints.SharedInbox
+	}
+	return a.Inbox
+}
+
+type APEndpoints struct {
+	SharedInbox string `json:"sharedInbox,omitempty"`
+}
+
+type APPublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+type APImage struct {
+	Type      string `json:"type"`
+	MediaType string `json:"mediaType,omitempty"`
+	URL       string `json:"url"`
+}
+
+type APTag struct {
+	Type string `json:"type"`
+	Href string `json:"href,omitempty"`
+	Name string `json:"name"`
+}
+
+// APObject is a Note/Article published by the local actor.
+type APObject struct {
+	Context      any        `json:"@context,omitempty"`
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	AttributedTo string     `json:"attributedTo"`
+	Name         string     `json:"name,omitempty"`
+	Summary      string     `json:"summary,omitempty"`
+	Content      string     `json:"content"`
+	URL          string     `json:"url,omitempty"`
+	Published    time.Time  `json:"published"`
+	Updated      *time.Time `json:"updated,omitempty"`
+	To           []string   `json:"to"`
+	Cc           []string   `json:"cc,omitempty"`
+	Tag          []APTag    `json:"tag,omitempty"`
+	Attachment   []APImage  `json:"attachment,omitempty"`
+}
+
+// APActivity is an outgoing activity; Object is either an id string or an embedded document.
+type APActivity struct {
+	Context   any        `json:"@context,omitempty"`
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	Actor     string     `json:"actor"`
+	Object    any        `json:"object"`
+	To        []string   `json:"to,omitempty"`
+	Cc        []string   `json:"cc,omitempty"`
+	Published *time.Time `json:"published,omitempty"`
+}
+
+// APIncomingActivity is an activity received in an inbox; Object is decoded lazily.
+type APIncomingActivity struct {
+	ID     string          `json:"id"`
+	Type   string          `json:"type"`
+	Actor  string          `json:"actor"`
+	Object json.RawMessage `json:"object"`
+}
+
+// APIncomingObject holds the fields of an embedded object we act on.
+type APIncomingObject struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	Actor        string          `json:"actor"`
+	Object       json.RawMessage `json:"object"`
+	AttributedTo string          `json:"attributedTo"`
+	Content      string          `json:"content"`
+	URL          json.RawMessage `json:"url"`
+	InReplyTo    string          `json:"inReplyTo"`
+}
+
+// APOrderedCollection is an OrderedCollection or OrderedCollectionPage.
+type APOrderedCollection struct {
+	Context      any    `json:"@context,omitempty"`
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	TotalItems   int64  `json:"totalItems"`
+	First        string `json:"first,omitempty"`
+	Next         string `json:"next,omitempty"`
+	PartOf       string `json:"partOf,omitempty"`
+	OrderedItems []any  `json:"orderedItems,omitempty"`
+}
+
+// ObjectID returns the id of an object that may be inlined or referenced by URL.
+func ObjectID(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return id
+	}
+	var obj struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		return obj.ID
+	}
+	return ""
+}
+
+// ActorKeySource resolves ActivityPub keyIds (actor#main-key) by fetching the actor document.
+type ActorKeySource struct {
+	client    *http.Client
+	ttl       time.Duration
+	signFetch func(ctx context.Context, req *http.Request) error
+
+	mu     sync.Mutex
+	actors map[string]cachedActor
+}
+
+type cachedActor struct {
+	actor     *APActor
+	expiresAt time.Time
+}
+
+func NewActorKeySource(client *http.Client, ttl time.Duration) *ActorKeySource {
+	if client == nil {
+		client = NewGuardedHTTPClient(10 * time.Second)
+	}
+	if ttl <= 0 {
+		ttl = DefaultActorKeyTTL
+	}
+	return &ActorKeySource{client: client, ttl: ttl, actors: make(map[string]cachedActor)}
+}
+
+// WithFetchSigner signs actor fetches, required by servers running in authorized-fetch mode.
+func (s *ActorKeySource) WithFetchSigner(sign func(ctx context.Context, req *http.Request) error) *ActorKeySource {
+	s.signFetch = sign
+	return s
+}
+
+// FetchKey implements KeySource.
+func (s *ActorKeySource) FetchKey(ctx context.Context, baseURL string, keyID string, refresh bool) (*PublicKeyEntry, error) {
+	documentURL, _, _ := strings.Cut(keyID, "#")
+	if refresh {
+		s.forget(documentURL)
+	}
+	actor, err := s.FetchActor(ctx, documentURL)
+	if err != nil {
+		return nil, err
+	}
+	if actor.PublicKey == nil || actor.PublicKey.PublicKeyPem == "" {
+		return nil, fmt.Errorf("actor has no public key")
+	}
+	if actor.PublicKey.ID != keyID {
+		return nil, fmt.Errorf("actor key %s does not match keyId", actor.PublicKey.ID)
+	}
+	return &PublicKeyEntry{
+		KeyID:     actor.PublicKey.ID,
+		PublicKey: actor.PublicKey.PublicKeyPem,
+	}, nil
+}
+
+// FetchActor returns an actor document, served from cache until the TTL elapses.
+// Key documents (GoToSocial style main-key URLs) are accepted as long as they carry publicKey.
+func (s *ActorKeySource) FetchActor(ctx context.Context, actorURL string) (*APActor, error) {
+	s.mu.Lock()
+	if cached, ok := s.actors[actorURL]; ok && time.Now().Before(cached.expiresAt) {
+		s.mu.Unlock()
+		return cached.actor, nil
+	}
+	s.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, actorURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", ContentTypeActivityJSON)
+	if s.signFetch != nil {
+		if err := s.signFetch(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("actor request failed: %s", resp.Status)
+	}
+	var actor APActor
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxActivityPubDocumentBytes)).Decode(&actor); err != nil {
+		return nil, err
+	}
+	if actor.ID == "" {
+		return nil, fmt.Errorf("actor document has no id")
+	}
+
+	s.mu.Lock()
+	s.actors[actorURL] = cachedActor{actor: &actor, expiresAt: time.Now().Add(s.ttl)}
+	s.mu.Unlock()
+	return &actor, nil
+}
+
+func (s *ActorKeySource) forget(actorURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.actors, actorURL)
+	now := time.Now()
+	for key, cached := range s.actors {
+		if now.After(cached.expiresAt) {
+			delete(s.actors, key)
+		}
+	}
+}
@@ -24,6 +24,7 @@ type Cache interface {
 	SetPublicKey(ctx context.Context, baseURL string, doc PublicKeyDoc, ttl time.Duration) error
 	GetEndpoints(ctx context.Context, baseURL string) (*EndpointsDoc, error)
 	SetEndpoints(ctx context.Context, baseURL string, doc EndpointsDoc, ttl time.Duration) error
+	Invalidate(ctx context.Context, baseURL string) error
 }
 
 // RedisCache stores federation metadata in Redis.
@@ -105,6 +106,15 @@ func (c *RedisCache) SetEndpoints(ctx context.Context, baseURL string, doc Endpo
 	return c.client.Set(ctx, c.key("endpoints", baseURL), payload, ttl).Err()
 }
 
+// Invalidate drops all cached well-known documents of an instance.
+func (c *RedisCache) Invalidate(ctx context.Context, baseURL string) error {
+	return c.client.Del(ctx,
+		c.key("manifest", baseURL),
+		c.key("pubkey", baseURL),
+		c.key("endpoints", baseURL),
+	).Err()
+}
+
 func (c *RedisCache) key(kind string, baseURL string) string {
 	escaped := url.PathEscape(baseURL)
 	return fmt.Sprintf("%sbfp:%s:%s", c.prefix, kind, escaped)
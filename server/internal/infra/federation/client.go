@@ -17,13 +17,18 @@ type Client struct {
 
 func NewClient(httpClient *http.Client, signer *Signer) *Client {
 	if httpClient == nil {
-		httpClient = &http.Client{Timeout: 10 * time.Second}
+		httpClient = NewGuardedHTTPClient(10 * time.Second)
 	}
 	return &Client{httpClient: httpClient, signer: signer}
 }
 
 // DoSigned sends a signed HTTP request with optional JSON body.
 func (c *Client) DoSigned(ctx context.Context, method string, url string, body []byte, keyID string, privateKey crypto.PrivateKey) (*http.Response, error) {
+	return c.DoSignedWithType(ctx, method, url, body, "", keyID, privateKey)
+}
+
+// DoSignedWithType is DoSigned with an explicit Content-Type (and matching Accept), e.g. application/activity+json.
+func (c *Client) DoSignedWithType(ctx context.Context, method string, url string, body []byte, contentType string, keyID string, privateKey crypto.PrivateKey) (*http.Response, error) {
 	if c.signer == nil {
 		return nil, fmt.Errorf("signer not configured")
 	}
@@ -37,6 +42,12 @@ func (c *Client) DoSigned(ctx context.Context, method string, url string, body [
 	if err != nil {
 		return nil, err
 	}
+	if contentType != "" {
+		req.Header.Set("Accept", contentType)
+		if len(body) > 0 {
+			req.Header.Set("Content-Type", contentType)
+		}
+	}
 	if err := c.signer.SignRequest(req, body, keyID, privateKey); err != nil {
 		return nil, err
 	}
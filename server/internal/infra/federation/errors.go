@@ -1,10 +1,26 @@
 package federation
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	ErrUnsupportedSignatureAlgorithm = errors.New("unsupported signature algorithm")
 	ErrMissingSignatureHeader        = errors.New("missing signature header")
 	ErrInvalidDigest                 = errors.New("invalid digest header")
 	ErrSignatureExpired              = errors.New("signature timestamp expired")
+	ErrMissingSignedHeaders          = errors.New("signature does not cover required headers")
+	ErrReplayedRequest               = errors.New("signed request was already processed")
 )
+
+// RateLimitError reports an exhausted token bucket and when the next token is available.
+type RateLimitError struct {
+	Scope      string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded (%s), retry after %s", e.Scope, e.RetryAfter)
+}
@@ -0,0 +1,448 @@
+// Package fedtest provides an in-process blog-federation peer for protocol conformance tests.
+package fedtest
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+)
+
+// Endpoint keys advertised in endpoints.json, matching the ones a real instance publishes.
+const (
+	EndpointFriendLinkRequest  = "friendlink_request"
+	EndpointFriendLinkCallback = "friendlink_callback"
+	EndpointTimeline           = "timeline"
+	EndpointPostDetail         = "post_detail"
+	EndpointCitationRequest    = "citation_request"
+	EndpointMentionNotify      = "mention_notify"
+	EndpointRemoteComment      = "remote_comment"
+)
+
+var endpointPaths = map[string]string{
+	EndpointFriendLinkRequest:  "/friendlinks/request",
+	EndpointFriendLinkCallback: "/friendlinks/callback",
+	EndpointTimeline:           "/timeline/posts",
+	EndpointPostDetail:         "/posts/{id}",
+	EndpointCitationRequest:    "/citations/request",
+	EndpointMentionNotify:      "/mentions/notify",
+	EndpointRemoteComment:      "/comments",
+}
+
+// Received is a signed request accepted by the fake instance.
+type Received struct {
+	Endpoint  string
+	Body      []byte
+	Signature *fedinfra.VerifiedSignature
+}
+
+// Rejected is a signed request the fake instance refused, with the verifier's error.
+type Rejected struct {
+	Endpoint string
+	Err      error
+}
+
+// TimelinePost is the wire shape of one item in a peer's timeline response.
+type TimelinePost struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Summary     string    `json:"summary"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Instance is a fake remote instance served by httptest.
+// It publishes manifest, public key and endpoints documents and verifies
+// inbound signed requests against the signer's own well-known public key.
+type Instance struct {
+	Server    *httptest.Server
+	URL       string
+	Algorithm string
+
+	privateKey crypto.PrivateKey
+	publicKey  string
+	signer     *fedinfra.Signer
+	verifier   *fedinfra.Verifier
+	client     *http.Client
+
+	mu                sync.Mutex
+	declaredAlgorithm string
+	timeline          []TimelinePost
+	postRequests      map[string][]int
+	received          []Received
+	rejected          []Rejected
+}
+
+// NewInstance starts a fake instance signing with algorithm (rsa-sha256 or ed25519).
+// The server is closed when the test ends.
+func NewInstance(t testing.TB, algorithm string) *Instance {
+	t.Helper()
+	algorithm = fedinfra.NormalizeAlgorithm(algorithm)
+	pub, priv, err := fedinfra.GenerateKeyPair(algorithm)
+	if err != nil {
+		t.Fatalf("generate %s key pair: %v", algorithm, err)
+	}
+	privateKey, err := fedinfra.ParsePrivateKey(priv)
+	if err != nil {
+		t.Fatalf("parse private key: %v", err)
+	}
+	signer, err := fedinfra.NewSigner(algorithm)
+	if err != nil {
+		t.Fatalf("create signer: %v", err)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	inst := &Instance{
+		Algorithm:         algorithm,
+		privateKey:        privateKey,
+		publicKey:         pub,
+		signer:            signer,
+		verifier:          fedinfra.NewVerifier(fedinfra.NewResolver(client, nil), 5*time.Minute),
+		client:            client,
+		declaredAlgorithm: algorithm,
+	}
+	inst.Server = httptest.NewServer(inst.routes())
+	inst.URL = inst.Server.URL
+	t.Cleanup(inst.Server.Close)
+	return inst
+}
+
+// KeyID is the keyId the instance signs with.
+func (i *Instance) KeyID() string {
+	return i.URL + "/.well-known/blog-federation/public-key.json"
+}
+
+// PublicKey returns the PEM encoded public key published by the instance.
+func (i *Instance) PublicKey() string {
+	return i.publicKey
+}
+
+// DeclareAlgorithm changes the algorithm advertised for the key in public-key.json,
+// e.g. to publish a key under an algorithm the verifier does not accept.
+func (i *Instance) DeclareAlgorithm(algorithm string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.declaredAlgorithm = algorithm
+}
+
+// SetTimeline replaces the posts served from the timeline endpoint.
+func (i *Instance) SetTimeline(posts []TimelinePost) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.timeline = append([]TimelinePost(nil), posts...)
+}
+
+// Received returns the accepted requests for an endpoint key.
+func (i *Instance) Received(endpoint string) []Received {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	var out []Received
+	for _, item := range i.received {
+		if item.Endpoint == endpoint {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// Rejected returns the refused requests for an endpoint key.
+func (i *Instance) Rejected(endpoint string) []Rejected {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	var out []Rejected
+	for _, item := range i.rejected {
+		if item.Endpoint == endpoint {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// SignOption alters how a request is signed or what is sent after signing.
+type SignOption func(*signOptions)
+
+type signOptions struct {
+	date       time.Time
+	sendBody   []byte
+	replace    bool
+	privateKey crypto.PrivateKey
+}
+
+// WithDate signs the request with a fixed Date header.
+func WithDate(date time.Time) SignOption {
+	return func(o *signOptions) { o.date = date }
+}
+
+// WithSentBody sends body instead of the signed one, leaving the Digest header stale.
+func WithSentBody(body []byte) SignOption {
+	return func(o *signOptions) {
+		o.sendBody = body
+		o.replace = true
+	}
+}
+
+// WithPrivateKey signs with a key other than the published one.
+func WithPrivateKey(key crypto.PrivateKey) SignOption {
+	return func(o *signOptions) { o.privateKey = key }
+}
+
+// NewSignedRequest builds a request to target signed with the instance key.
+func (i *Instance) NewSignedRequest(ctx context.Context, method string, target string, body []byte, opts ...SignOption) (*http.Request, error) {
+	options := signOptions{privateKey: i.privateKey}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if !options.date.IsZero() {
+		req.Header.Set("Date", options.date.UTC().Format(http.TimeFormat))
+	}
+	if err := i.signer.SignRequest(req, body, i.KeyID(), options.privateKey); err != nil {
+		return nil, err
+	}
+	if options.replace {
+		req.Body = io.NopCloser(bytes.NewReader(options.sendBody))
+		req.ContentLength = int64(len(options.sendBody))
+	}
+	return req, nil
+}
+
+// Post signs body, sends it to target and returns the status and response body.
+func (i *Instance) Post(ctx context.Context, target string, body []byte, opts ...SignOption) (int, []byte, error) {
+	req, err := i.NewSignedRequest(ctx, http.MethodPost, target, body, opts...)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, raw, err
+}
+
+// EndpointURL resolves an endpoint key against a peer's endpoints.json.
+func (i *Instance) EndpointURL(ctx context.Context, baseURL string, key string) (string, error) {
+	doc, err := fedinfra.NewResolver(i.client, nil).FetchEndpoints(ctx, baseURL)
+	if err != nil {
+		return "", err
+	}
+	path := doc.Endpoints[key]
+	if path == "" {
+		return "", fmt.Errorf("endpoint %q not advertised", key)
+	}
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path, nil
+	}
+	return strings.TrimRight(doc.BaseURL, "/") + "/" + strings.TrimLeft(path, "/"), nil
+}
+
+// FetchTimeline discovers a peer's timeline endpoint and reads one page of posts.
+func (i *Instance) FetchTimeline(ctx context.Context, baseURL string) ([]TimelinePost, int64, error) {
+	endpoint, err := i.EndpointURL(ctx, baseURL, EndpointTimeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("timeline returned status %d", resp.StatusCode)
+	}
+	var envelope struct {
+		Code int `json:"code"`
+		Data struct {
+			Items []TimelinePost `json:"items"`
+			Total int64          `json:"total"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, 0, err
+	}
+	return envelope.Data.Items, envelope.Data.Total, nil
+}
+
+func (i *Instance) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/blog-federation/manifest.json", i.serveManifest)
+	mux.HandleFunc("GET /.well-known/blog-federation/public-key.json", i.servePublicKey)
+	mux.HandleFunc("GET /.well-known/blog-federation/endpoints.json", i.serveEndpoints)
+	mux.HandleFunc("GET /api/federation/timeline/posts", i.serveTimeline)
+	mux.HandleFunc("GET /api/federation/posts/{id}", i.servePost)
+	for _, key := range []string{EndpointFriendLinkRequest, EndpointFriendLinkCallback, EndpointCitationRequest, EndpointMentionNotify, EndpointRemoteComment} {
+		mux.HandleFunc("POST /api/federation"+endpointPaths[key], i.acceptSigned(key))
+	}
+	return mux
+}
+
+func (i *Instance) serveManifest(w http.ResponseWriter, _ *http.Request) {
+	now := time.Now().UTC()
+	writeJSON(w, http.StatusOK, fedinfra.Manifest{
+		ProtocolVersion: "1.0.0",
+		Instance: fedinfra.ManifestNode{
+			Name: "fedtest",
+			URL:  i.URL,
+		},
+		Software: fedinfra.ManifestSoftware{Name: "fedtest", Version: "dev"},
+		Features: []string{"friendlink-timeline", "cross-citation", "cross-mention"},
+		Policies: fedinfra.ManifestPolicy{
+			AllowCitation: true,
+			AllowMention:  true,
+			MaxCacheAge:   86400,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func (i *Instance) servePublicKey(w http.ResponseWriter, _ *http.Request) {
+	i.mu.Lock()
+	declared := i.declaredAlgorithm
+	i.mu.Unlock()
+	entry := fedinfra.PublicKeyEntry{
+		KeyID:     i.KeyID(),
+		Algorithm: declared,
+		PublicKey: i.publicKey,
+		CreatedAt: time.Now().UTC(),
+	}
+	writeJSON(w, http.StatusOK, fedinfra.PublicKeyDoc{
+		KeyID:               entry.KeyID,
+		Algorithm:           entry.Algorithm,
+		PublicKey:           entry.PublicKey,
+		CreatedAt:           entry.CreatedAt,
+		SupportedAlgorithms: fedinfra.SupportedAlgorithms,
+		Keys:                []fedinfra.PublicKeyEntry{entry},
+	})
+}
+
+func (i *Instance) serveEndpoints(w http.ResponseWriter, _ *http.Request) {
+	endpoints := make(map[string]string, len(endpointPaths))
+	for key, path := range endpointPaths {
+		endpoints[key] = path
+	}
+	writeJSON(w, http.StatusOK, fedinfra.EndpointsDoc{
+		BaseURL:   i.URL + "/api/federation",
+		Endpoints: endpoints,
+	})
+}
+
+func (i *Instance) serveTimeline(w http.ResponseWriter, _ *http.Request) {
+	i.mu.Lock()
+	items := append([]TimelinePost(nil), i.timeline...)
+	i.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"code": 0,
+		"msg":  "success",
+		"data": map[string]any{"items": items, "total": len(items), "page": 1, "size": len(items)},
+	})
+}
+
+// servePost answers post_detail from the timeline, honouring If-None-Match like a real instance.
+func (i *Instance) servePost(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	i.mu.Lock()
+	var post *TimelinePost
+	for idx := range i.timeline {
+		if i.timeline[idx].ID == id {
+			found := i.timeline[idx]
+			post = &found
+			break
+		}
+	}
+	status := http.StatusOK
+	etag := ""
+	switch {
+	case post == nil:
+		status = http.StatusNotFound
+	default:
+		etag = fmt.Sprintf(`"%s-%d"`, post.ID, post.PublishedAt.Unix())
+		if r.Header.Get("If-None-Match") == etag {
+			status = http.StatusNotModified
+		}
+	}
+	if i.postRequests == nil {
+		i.postRequests = make(map[string][]int)
+	}
+	i.postRequests[id] = append(i.postRequests[id], status)
+	i.mu.Unlock()
+
+	switch status {
+	case http.StatusNotFound:
+		writeJSON(w, status, map[string]any{"code": 404, "msg": "not found"})
+	case http.StatusNotModified:
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(status)
+	default:
+		w.Header().Set("ETag", etag)
+		writeJSON(w, status, map[string]any{
+			"code": 0,
+			"msg":  "success",
+			"data": map[string]any{"post": map[string]any{
+				"id":             post.ID,
+				"url":            post.URL,
+				"title":          post.Title,
+				"summary":        post.Summary,
+				"author":         map[string]any{"name": "peer"},
+				"published_at":   post.PublishedAt,
+				"allow_citation": true,
+				"allow_comment":  true,
+			}},
+		})
+	}
+}
+
+// PostRequests returns the statuses served for post_detail requests of id, in order.
+func (i *Instance) PostRequests(id string) []int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]int(nil), i.postRequests[id]...)
+}
+
+// acceptSigned verifies the request like a conforming peer would and records the outcome.
+func (i *Instance) acceptSigned(endpoint string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"code": 400, "msg": err.Error()})
+			return
+		}
+		signature, err := i.verifier.VerifyRequest(r.Context(), r, body)
+		i.mu.Lock()
+		if err != nil {
+			i.rejected = append(i.rejected, Rejected{Endpoint: endpoint, Err: err})
+		} else {
+			i.received = append(i.received, Received{Endpoint: endpoint, Body: body, Signature: signature})
+		}
+		i.mu.Unlock()
+		if err != nil {
+			writeJSON(w, http.StatusForbidden, map[string]any{"code": 403, "msg": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"code": 0, "msg": "success", "data": map[string]any{"status": "pending"}})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
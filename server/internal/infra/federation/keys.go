@@ -0,0 +1,138 @@
+package federation
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+const (
+	AlgorithmRSASHA256 = "rsa-sha256"
+	AlgorithmEd25519   = "ed25519"
+)
+
+// SupportedAlgorithms lists signature algorithms this instance can verify, preferred first.
+var SupportedAlgorithms = []string{AlgorithmEd25519, AlgorithmRSASHA256}
+
+// KeyIDFor derives a keyId from the public key document URL and the key's fragment.
+// An empty ref keeps the bare document URL so peers that predate multi-key documents keep resolving it.
+func KeyIDFor(documentURL string, keyRef string) string {
+	if keyRef == "" {
+		return documentURL
+	}
+	return documentURL + "#" + keyRef
+}
+
+// NormalizeAlgorithm maps common spellings to the canonical algorithm names.
+func NormalizeAlgorithm(algorithm string) string {
+	switch strings.ToLower(strings.TrimSpace(algorithm)) {
+	case "rsa-sha256", "rsa_sha256":
+		return AlgorithmRSASHA256
+	case "ed25519":
+		return AlgorithmEd25519
+	default:
+		return strings.ToLower(strings.TrimSpace(algorithm))
+	}
+}
+
+// GenerateKeyPair creates a PEM encoded key pair (PKIX public key) for the algorithm.
+func GenerateKeyPair(algorithm string) (string, string, error) {
+	switch NormalizeAlgorithm(algorithm) {
+	case AlgorithmRSASHA256:
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return "", "", err
+		}
+		privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+		publicPEM, err := encodePublicKey(&privateKey.PublicKey)
+		if err != nil {
+			return "", "", err
+		}
+		return publicPEM, string(privatePEM), nil
+	case AlgorithmEd25519:
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", "", err
+		}
+		privateBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return "", "", err
+		}
+		privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes})
+		publicPEM, err := encodePublicKey(publicKey)
+		if err != nil {
+			return "", "", err
+		}
+		return publicPEM, string(privatePEM), nil
+	default:
+		return "", "", ErrUnsupportedSignatureAlgorithm
+	}
+}
+
+func encodePublicKey(key crypto.PublicKey) (string, error) {
+	publicBytes, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes})), nil
+}
+
+// ParsePrivateKey accepts PKCS#1 RSA keys and PKCS#8 RSA/Ed25519 keys.
+func ParsePrivateKey(pemData string) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key PEM")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported private key format")
+	}
+	switch typed := key.(type) {
+	case *rsa.PrivateKey:
+		return typed, nil
+	case ed25519.PrivateKey:
+		return typed, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// ParsePublicKey accepts PKIX (RSA/Ed25519) and PKCS#1 RSA public keys.
+func ParsePublicKey(pemData string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, fmt.Errorf("invalid public key PEM")
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		switch key.(type) {
+		case *rsa.PublicKey, ed25519.PublicKey:
+			return key, nil
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported public key format")
+}
+
+// KeyAlgorithm infers the signature algorithm from a parsed public or private key.
+func KeyAlgorithm(key any) (string, error) {
+	switch key.(type) {
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return AlgorithmRSASHA256, nil
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return AlgorithmEd25519, nil
+	default:
+		return "", ErrUnsupportedSignatureAlgorithm
+	}
+}
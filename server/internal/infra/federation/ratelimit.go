@@ -0,0 +1,161 @@
+package federation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitStore consumes tokens from named token buckets.
+type RateLimitStore interface {
+	// Take removes one token from the bucket; when empty it reports how long to wait for the next token.
+	Take(ctx context.Context, key string, capacity float64, refillPerSecond float64) (bool, time.Duration, error)
+}
+
+// NewRateLimitStore returns a Redis-backed store that falls back to memory, or memory only when client is nil.
+func NewRateLimitStore(client *redis.Client, prefix string) RateLimitStore {
+	memory := NewMemoryRateLimitStore()
+	if client == nil {
+		return memory
+	}
+	return &fallbackRateLimitStore{
+		primary:  NewRedisRateLimitStore(client, prefix),
+		fallback: memory,
+	}
+}
+
+// tokenBucketScript keeps tokens and the last refill time (ms) in a hash so concurrent nodes share one bucket.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, wait}
+`)
+
+// RedisRateLimitStore shares token buckets across nodes through Redis.
+type RedisRateLimitStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisRateLimitStore(client *redis.Client, prefix string) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client, prefix: prefix}
+}
+
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, capacity float64, refillPerSecond float64) (bool, time.Duration, error) {
+	if capacity <= 0 || refillPerSecond <= 0 {
+		return true, 0, nil
+	}
+	perMilli := refillPerSecond / 1000
+	ttl := int64(math.Ceil(capacity/perMilli)) + 1000
+	res, err := tokenBucketScript.Run(ctx, s.client,
+		[]string{fmt.Sprintf("%sbfp:rl:%s", s.prefix, key)},
+		capacity, perMilli, time.Now().UnixMilli(), ttl,
+	).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected token bucket reply: %v", res)
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// MemoryRateLimitStore keeps token buckets in process; used when Redis is absent or failing.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	idleAfter time.Duration
+}
+
+const memoryBucketSweepInterval = time.Minute
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, capacity float64, refillPerSecond float64) (bool, time.Duration, error) {
+	if capacity <= 0 || refillPerSecond <= 0 {
+		return true, 0, nil
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updatedAt: now}
+		s.buckets[key] = bucket
+	}
+	bucket.idleAfter = time.Duration(capacity / refillPerSecond * float64(time.Second))
+	elapsed := now.Sub(bucket.updatedAt).Seconds()
+	bucket.tokens = math.Min(capacity, bucket.tokens+elapsed*refillPerSecond)
+	bucket.updatedAt = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0, nil
+	}
+	wait := time.Duration((1 - bucket.tokens) / refillPerSecond * float64(time.Second))
+	return false, wait, nil
+}
+
+// sweep drops buckets that have been idle long enough to be full again.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memoryBucketSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.updatedAt) > bucket.idleAfter {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+type fallbackRateLimitStore struct {
+	primary  RateLimitStore
+	fallback RateLimitStore
+}
+
+func (s *fallbackRateLimitStore) Take(ctx context.Context, key string, capacity float64, refillPerSecond float64) (bool, time.Duration, error) {
+	allowed, wait, err := s.primary.Take(ctx, key, capacity, refillPerSecond)
+	if err == nil {
+		return allowed, wait, nil
+	}
+	log.Printf("[federation] 限流 Redis 不可用，降级为内存令牌桶 key=%s err=%v", key, err)
+	return s.fallback.Take(ctx, key, capacity, refillPerSecond)
+}
@@ -0,0 +1,113 @@
+package federation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ReplayCache remembers signatures that were already accepted.
+type ReplayCache interface {
+	// Remember records key for ttl and reports whether it had been seen before.
+	Remember(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// NewReplayCache returns a Redis-backed cache that falls back to memory, or memory only when client is nil.
+func NewReplayCache(client *redis.Client, prefix string) ReplayCache {
+	memory := NewMemoryReplayCache()
+	if client == nil {
+		return memory
+	}
+	return &fallbackReplayCache{
+		primary:  NewRedisReplayCache(client, prefix),
+		fallback: memory,
+	}
+}
+
+// replayKey hashes keyId and signature so the stored key has a bounded size.
+func replayKey(keyID string, signature string) string {
+	sum := sha256.Sum256([]byte(keyID + "\n" + signature))
+	return hex.EncodeToString(sum[:])
+}
+
+// RedisReplayCache shares seen signatures across nodes through Redis.
+type RedisReplayCache struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisReplayCache(client *redis.Client, prefix string) *RedisReplayCache {
+	return &RedisReplayCache{client: client, prefix: prefix}
+}
+
+func (c *RedisReplayCache) Remember(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	stored, err := c.client.SetNX(ctx, fmt.Sprintf("%sbfp:replay:%s", c.prefix, key), 1, ttl).Result()
+	if err != nil {
+		return false, err
+	}
+	return !stored, nil
+}
+
+// MemoryReplayCache keeps seen signatures in process; used when Redis is absent or failing.
+type MemoryReplayCache struct {
+	mu        sync.Mutex
+	entries   map[string]time.Time
+	lastSweep time.Time
+}
+
+const memoryReplaySweepInterval = time.Minute
+
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{
+		entries:   make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *MemoryReplayCache) Remember(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sweep(now)
+
+	if expiresAt, ok := c.entries[key]; ok && now.Before(expiresAt) {
+		return true, nil
+	}
+	c.entries[key] = now.Add(ttl)
+	return false, nil
+}
+
+func (c *MemoryReplayCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < memoryReplaySweepInterval {
+		return
+	}
+	c.lastSweep = now
+	for key, expiresAt := range c.entries {
+		if !now.Before(expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// fallbackReplayCache writes to memory as well, so a Redis outage does not reopen the replay window
+// for signatures accepted on this node.
+type fallbackReplayCache struct {
+	primary  ReplayCache
+	fallback ReplayCache
+}
+
+func (c *fallbackReplayCache) Remember(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	seenLocal, _ := c.fallback.Remember(ctx, key, ttl)
+	seen, err := c.primary.Remember(ctx, key, ttl)
+	if err == nil {
+		return seen || seenLocal, nil
+	}
+	log.Printf("[federation] 防重放 Redis 不可用，降级为内存缓存 err=%v", err)
+	return seenLocal, nil
+}
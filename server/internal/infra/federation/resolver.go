@@ -19,7 +19,7 @@ type Resolver struct {
 
 func NewResolver(client *http.Client, cache Cache) *Resolver {
 	if client == nil {
-		client = &http.Client{Timeout: 10 * time.Second}
+		client = NewGuardedHTTPClient(10 * time.Second)
 	}
 	return &Resolver{client: client, cache: cache}
 }
@@ -81,6 +81,14 @@ func (r *Resolver) FetchEndpoints(ctx context.Context, baseURL string) (*Endpoin
 	return &doc, nil
 }
 
+// Invalidate drops cached metadata so the next fetch hits the remote instance.
+func (r *Resolver) Invalidate(ctx context.Context, baseURL string) error {
+	if r.cache == nil {
+		return nil
+	}
+	return r.cache.Invalidate(ctx, normalizeBaseURL(baseURL))
+}
+
 func (r *Resolver) fetchJSON(ctx context.Context, baseURL string, filename string, target any) error {
 	wellKnownURL, err := buildWellKnownURL(baseURL, filename)
 	if err != nil {
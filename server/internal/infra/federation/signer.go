@@ -18,17 +18,30 @@ type Signer struct {
 
 // NewSigner constructs a signer for the given algorithm string.
 func NewSigner(algorithm string) (*Signer, error) {
-	algo, err := resolveAlgorithm(algorithm)
-	if err != nil {
-		return nil, err
-	}
-
-	headers := []string{
+	return newSigner(algorithm, []string{
 		httpsig.RequestTarget,
 		"host",
 		"date",
 		"digest",
 		"content-type",
+	})
+}
+
+// NewActivityPubSigner signs the header set Mastodon-compatible servers expect;
+// content-type is left out so bodiless GETs (actor fetches) can be signed too.
+func NewActivityPubSigner(algorithm string) (*Signer, error) {
+	return newSigner(algorithm, []string{
+		httpsig.RequestTarget,
+		"host",
+		"date",
+		"digest",
+	})
+}
+
+func newSigner(algorithm string, headers []string) (*Signer, error) {
+	algo, err := resolveAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
 	}
 
 	// expiresIn=0 means no explicit expires parameter in the signature.
@@ -42,6 +55,10 @@ func NewSigner(algorithm string) (*Signer, error) {
 
 // SignRequest adds digest/date headers and signs the request.
 func (s *Signer) SignRequest(req *http.Request, body []byte, keyID string, privateKey crypto.PrivateKey) error {
+	// Digest is part of the signed header set, so bodiless requests carry the digest of an empty body.
+	if body == nil {
+		body = []byte{}
+	}
 	if req.Header.Get("Date") == "" {
 		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
 	}
@@ -60,11 +77,12 @@ func (s *Signer) SignRequest(req *http.Request, body []byte, keyID string, priva
 }
 
 func resolveAlgorithm(algorithm string) (httpsig.Algorithm, error) {
-	switch algorithm {
-	case "rsa-sha256", "rsa_sha256", "RSA-SHA256":
+	switch NormalizeAlgorithm(algorithm) {
+	case AlgorithmRSASHA256:
 		return httpsig.RSA_SHA256, nil
+	case AlgorithmEd25519:
+		return httpsig.ED25519, nil
 	default:
-		// TODO: add ed25519 support once a stable signer strategy is defined.
-		return httpsig.Algorithm(0), ErrUnsupportedSignatureAlgorithm
+		return "", ErrUnsupportedSignatureAlgorithm
 	}
 }
@@ -39,6 +39,7 @@ type ManifestAdmin struct {
 type ManifestPolicy struct {
 	AllowCitation                 bool  `json:"allow_citation"`
 	AllowMention                  bool  `json:"allow_mention"`
+	AllowComment                  bool  `json:"allow_comment"`
 	AutoApproveFriendlinkCitation bool  `json:"auto_approve_friendlink_citation"`
 	RequireHTTPS                  bool  `json:"require_https"`
 	MaxCacheAge                   int64 `json:"max_cache_age"`
@@ -57,7 +58,20 @@ type RSSFeed struct {
 }
 
 // PublicKeyDoc mirrors .well-known/blog-federation/public-key.json.
+// The top-level key stays RSA for peers that only understand a single key;
+// Keys lists every published key and SupportedAlgorithms what the instance can verify.
 type PublicKeyDoc struct {
+	KeyID               string           `json:"key_id"`
+	Algorithm           string           `json:"algorithm"`
+	PublicKey           string           `json:"public_key"`
+	CreatedAt           time.Time        `json:"created_at"`
+	ExpiresAt           *time.Time       `json:"expires_at,omitempty"`
+	SupportedAlgorithms []string         `json:"supported_algorithms,omitempty"`
+	Keys                []PublicKeyEntry `json:"keys,omitempty"`
+}
+
+// PublicKeyEntry is one published verification key.
+type PublicKeyEntry struct {
 	KeyID     string     `json:"key_id"`
 	Algorithm string     `json:"algorithm"`
 	PublicKey string     `json:"public_key"`
@@ -65,6 +79,41 @@ type PublicKeyDoc struct {
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
+// KeyFor returns the key matching keyID, falling back to the top-level key.
+func (d *PublicKeyDoc) KeyFor(keyID string) PublicKeyEntry {
+	for _, entry := range d.Keys {
+		if entry.KeyID == keyID {
+			return entry
+		}
+	}
+	return PublicKeyEntry{
+		KeyID:     d.KeyID,
+		Algorithm: d.Algorithm,
+		PublicKey: d.PublicKey,
+		CreatedAt: d.CreatedAt,
+		ExpiresAt: d.ExpiresAt,
+	}
+}
+
+// Supports reports whether the peer can verify signatures made with algorithm.
+// Peers without supported_algorithms are assumed to verify only their own key type.
+func (d *PublicKeyDoc) Supports(algorithm string) bool {
+	algorithm = NormalizeAlgorithm(algorithm)
+	if len(d.SupportedAlgorithms) == 0 {
+		own := NormalizeAlgorithm(d.Algorithm)
+		if own == "" {
+			own = AlgorithmRSASHA256
+		}
+		return own == algorithm
+	}
+	for _, supported := range d.SupportedAlgorithms {
+		if NormalizeAlgorithm(supported) == algorithm {
+			return true
+		}
+	}
+	return false
+}
+
 // EndpointsDoc mirrors .well-known/blog-federation/endpoints.json.
 type EndpointsDoc struct {
 	BaseURL   string            `json:"base_url"`
@@ -4,40 +4,120 @@ import (
 	"context"
 	"crypto"
 	"crypto/sha256"
-	"crypto/x509"
 	"encoding/base64"
-	"encoding/pem"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"code.superseriousbusiness.org/httpsig"
 )
 
+// InstancePolicy decides whether requests from a remote instance are accepted.
+type InstancePolicy interface {
+	CheckInstance(ctx context.Context, baseURL string) error
+}
+
+// RequestLimiter throttles verified requests per instance; it returns *RateLimitError when exhausted.
+type RequestLimiter interface {
+	AllowRequest(ctx context.Context, baseURL string, req *http.Request) error
+}
+
+// requiredSignedHeaders must all appear in the signature's headers parameter.
+var requiredSignedHeaders = []string{httpsig.RequestTarget, "host", "date", "digest"}
+
+// keyRefetchInterval bounds how often a failed signature may force a fresh key fetch per instance.
+const keyRefetchInterval = time.Minute
+
+// KeySource resolves the verification key named by a signature's keyId.
+// refresh asks the source to bypass any cached copy.
+type KeySource interface {
+	FetchKey(ctx context.Context, baseURL string, keyID string, refresh bool) (*PublicKeyEntry, error)
+}
+
+// wellKnownKeySource reads keys from the peer's blog-federation public-key.json.
+type wellKnownKeySource struct {
+	resolver *Resolver
+}
+
+func (s wellKnownKeySource) FetchKey(ctx context.Context, baseURL string, keyID string, refresh bool) (*PublicKeyEntry, error) {
+	if refresh {
+		if err := s.resolver.Invalidate(ctx, baseURL); err != nil {
+			return nil, err
+		}
+	}
+	pubDoc, err := s.resolver.FetchPublicKey(ctx, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if pubDoc == nil {
+		return nil, fmt.Errorf("public key not found")
+	}
+	entry := pubDoc.KeyFor(keyID)
+	return &entry, nil
+}
+
 // Verifier validates signed federation requests.
 type Verifier struct {
-	resolver    *Resolver
+	keys        KeySource
 	allowedSkew time.Duration
+	policy      InstancePolicy
+	limiter     RequestLimiter
+	replay      ReplayCache
+
+	refetchMu   sync.Mutex
+	refetchedAt map[string]time.Time
 }
 
 func NewVerifier(resolver *Resolver, allowedSkew time.Duration) *Verifier {
 	if allowedSkew <= 0 {
 		allowedSkew = 5 * time.Minute
 	}
-	return &Verifier{resolver: resolver, allowedSkew: allowedSkew}
+	verifier := &Verifier{allowedSkew: allowedSkew, refetchedAt: make(map[string]time.Time)}
+	if resolver != nil {
+		verifier.keys = wellKnownKeySource{resolver: resolver}
+	}
+	return verifier
 }
 
-// VerifyRequest validates digest, date window, and signature.
+// WithKeySource replaces the blog-federation key lookup, e.g. with ActivityPub actor documents.
+func (v *Verifier) WithKeySource(source KeySource) *Verifier {
+	v.keys = source
+	return v
+}
+
+// WithInstancePolicy rejects blocked instances before any remote key lookup.
+func (v *Verifier) WithInstancePolicy(policy InstancePolicy) *Verifier {
+	v.policy = policy
+	return v
+}
+
+// WithRateLimiter charges each successfully verified request to its instance's token buckets.
+func (v *Verifier) WithRateLimiter(limiter RequestLimiter) *Verifier {
+	v.limiter = limiter
+	return v
+}
+
+// WithReplayCache rejects signatures that were already accepted within the skew window.
+func (v *Verifier) WithReplayCache(cache ReplayCache) *Verifier {
+	v.replay = cache
+	return v
+}
+
+// VerifyRequest validates signed headers, digest, date window, signature and replays.
 func (v *Verifier) VerifyRequest(ctx context.Context, req *http.Request, body []byte) (*VerifiedSignature, error) {
-	if req.Header.Get("Signature") == "" {
+	rawSignature := req.Header.Get("Signature")
+	if rawSignature == "" {
 		return nil, ErrMissingSignatureHeader
 	}
-	if len(body) > 0 {
-		if err := verifyDigest(req.Header.Get("Digest"), body); err != nil {
-			return nil, err
-		}
+	params := parseSignatureParams(rawSignature)
+	if err := checkSignedHeaders(params["headers"]); err != nil {
+		return nil, err
+	}
+	if err := verifyDigest(req.Header.Get("Digest"), body); err != nil {
+		return nil, err
 	}
 	requestTime, err := parseRequestTime(req.Header.Get("Date"))
 	if err != nil {
@@ -60,32 +140,45 @@ func (v *Verifier) VerifyRequest(ctx context.Context, req *http.Request, body []
 	if err != nil {
 		return nil, err
 	}
-	if v.resolver == nil {
-		return nil, fmt.Errorf("resolver not configured")
+	if v.policy != nil {
+		if err := v.policy.CheckInstance(ctx, baseURL); err != nil {
+			return nil, err
+		}
 	}
-	pubDoc, err := v.resolver.FetchPublicKey(ctx, baseURL)
-	if err != nil {
-		return nil, err
+	if v.keys == nil {
+		return nil, fmt.Errorf("key source not configured")
 	}
-	if pubDoc == nil || pubDoc.PublicKey == "" {
-		return nil, fmt.Errorf("public key not found")
+	pubKey, algo, err := v.resolveKey(ctx, baseURL, keyID, false)
+	if err == nil {
+		err = verifier.Verify(pubKey, algo)
 	}
-
-	pubKey, err := parsePublicKey(pubDoc.PublicKey)
 	if err != nil {
-		return nil, err
+		// The peer may have rotated its key while we still hold the old document in cache.
+		if !v.allowRefetch(baseURL) {
+			return nil, err
+		}
+		pubKey, algo, err = v.resolveKey(ctx, baseURL, keyID, true)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifier.Verify(pubKey, algo); err != nil {
+			return nil, err
+		}
 	}
-
-	algo := httpsig.RSA_SHA256
-	if pubDoc.Algorithm != "" {
-		resolved, err := resolveAlgorithm(pubDoc.Algorithm)
+	if v.replay != nil {
+		// Entries outlive the accepted Date window on both sides, after which the date check rejects the request anyway.
+		seen, err := v.replay.Remember(ctx, replayKey(keyID, params["signature"]), 2*v.allowedSkew)
 		if err != nil {
 			return nil, err
 		}
-		algo = resolved
+		if seen {
+			return nil, ErrReplayedRequest
+		}
 	}
-	if err := verifier.Verify(pubKey, algo); err != nil {
-		return nil, err
+	if v.limiter != nil {
+		if err := v.limiter.AllowRequest(ctx, baseURL, req); err != nil {
+			return nil, err
+		}
 	}
 
 	return &VerifiedSignature{
@@ -95,6 +188,82 @@ func (v *Verifier) VerifyRequest(ctx context.Context, req *http.Request, body []
 	}, nil
 }
 
+// resolveKey looks up the key for keyID through the configured key source.
+func (v *Verifier) resolveKey(ctx context.Context, baseURL string, keyID string, refresh bool) (crypto.PublicKey, httpsig.Algorithm, error) {
+	entry, err := v.keys.FetchKey(ctx, baseURL, keyID, refresh)
+	if err != nil {
+		return nil, "", err
+	}
+	if entry == nil || entry.PublicKey == "" {
+		return nil, "", fmt.Errorf("public key not found")
+	}
+	if entry.ExpiresAt != nil && time.Now().After(*entry.ExpiresAt) {
+		return nil, "", fmt.Errorf("public key expired")
+	}
+
+	pubKey, err := ParsePublicKey(entry.PublicKey)
+	if err != nil {
+		return nil, "", err
+	}
+	// The key type decides the algorithm; httpsig advertises hs2019 in the header.
+	keyAlg, err := KeyAlgorithm(pubKey)
+	if err != nil {
+		return nil, "", err
+	}
+	if declared := NormalizeAlgorithm(entry.Algorithm); declared != "" && declared != "hs2019" && declared != keyAlg {
+		return nil, "", ErrUnsupportedSignatureAlgorithm
+	}
+	algo, err := resolveAlgorithm(keyAlg)
+	if err != nil {
+		return nil, "", err
+	}
+	return pubKey, algo, nil
+}
+
+// allowRefetch permits one forced key refresh per instance per keyRefetchInterval,
+// so forged signatures cannot turn the verifier into a fetch amplifier.
+func (v *Verifier) allowRefetch(baseURL string) bool {
+	v.refetchMu.Lock()
+	defer v.refetchMu.Unlock()
+	now := time.Now()
+	if last, ok := v.refetchedAt[baseURL]; ok && now.Sub(last) < keyRefetchInterval {
+		return false
+	}
+	for key, at := range v.refetchedAt {
+		if now.Sub(at) >= keyRefetchInterval {
+			delete(v.refetchedAt, key)
+		}
+	}
+	v.refetchedAt[baseURL] = now
+	return true
+}
+
+// parseSignatureParams splits a Signature header into its key="value" parameters.
+func parseSignatureParams(raw string) map[string]string {
+	params := make(map[string]string)
+	for _, part := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return params
+}
+
+func checkSignedHeaders(headers string) error {
+	signed := make(map[string]struct{})
+	for _, header := range strings.Fields(strings.ToLower(headers)) {
+		signed[header] = struct{}{}
+	}
+	for _, required := range requiredSignedHeaders {
+		if _, ok := signed[required]; !ok {
+			return ErrMissingSignedHeaders
+		}
+	}
+	return nil
+}
+
 func verifyDigest(digestHeader string, body []byte) error {
 	if digestHeader == "" {
 		return ErrInvalidDigest
@@ -133,19 +302,3 @@ func baseURLFromKeyID(keyID string) (string, error) {
 	}
 	return fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host), nil
 }
-
-func parsePublicKey(pemData string) (crypto.PublicKey, error) {
-	block, _ := pem.Decode([]byte(pemData))
-	if block == nil {
-		return nil, fmt.Errorf("invalid public key PEM")
-	}
-	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
-		return key, nil
-	}
-	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
-		return key, nil
-	}
-	return nil, fmt.Errorf("unsupported public key format")
-}
-
-// TODO: support Ed25519 verification once a stable key format is agreed.
@@ -0,0 +1,115 @@
+package federation_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	fedinfra "github.com/grtsinry43/grtblog-v2/server/internal/infra/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/federation/fedtest"
+)
+
+var testBody = []byte(`{"requester_url":"https://example.org"}`)
+
+func newTestVerifier() *fedinfra.Verifier {
+	resolver := fedinfra.NewResolver(&http.Client{Timeout: 5 * time.Second}, nil)
+	return fedinfra.NewVerifier(resolver, 5*time.Minute).WithReplayCache(fedinfra.NewMemoryReplayCache())
+}
+
+func verify(t *testing.T, verifier *fedinfra.Verifier, peer *fedtest.Instance, opts ...fedtest.SignOption) (*fedinfra.VerifiedSignature, error) {
+	t.Helper()
+	ctx := context.Background()
+	req, err := peer.NewSignedRequest(ctx, http.MethodPost, "https://blog.example.com/api/federation/friendlinks/request", testBody, opts...)
+	if err != nil {
+		t.Fatalf("sign request: %v", err)
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return verifier.VerifyRequest(ctx, req, body)
+}
+
+func TestVerifyRequestAcceptsPeerSignatures(t *testing.T) {
+	for _, algorithm := range []string{fedinfra.AlgorithmRSASHA256, fedinfra.AlgorithmEd25519} {
+		t.Run(algorithm, func(t *testing.T) {
+			peer := fedtest.NewInstance(t, algorithm)
+			signature, err := verify(t, newTestVerifier(), peer)
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if signature.KeyID != peer.KeyID() {
+				t.Errorf("key id = %q, want %q", signature.KeyID, peer.KeyID())
+			}
+			if signature.BaseURL != peer.URL {
+				t.Errorf("base url = %q, want %q", signature.BaseURL, peer.URL)
+			}
+		})
+	}
+}
+
+func TestVerifyRequestRejectsForgedSignature(t *testing.T) {
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmRSASHA256)
+	_, otherKey, err := fedinfra.GenerateKeyPair(fedinfra.AlgorithmRSASHA256)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	forged, err := fedinfra.ParsePrivateKey(otherKey)
+	if err != nil {
+		t.Fatalf("parse key: %v", err)
+	}
+	if _, err := verify(t, newTestVerifier(), peer, fedtest.WithPrivateKey(forged)); err == nil {
+		t.Fatal("expected forged signature to be rejected")
+	}
+}
+
+func TestVerifyRequestRejectsExpiredDate(t *testing.T) {
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmRSASHA256)
+	for name, date := range map[string]time.Time{
+		"past":   time.Now().Add(-10 * time.Minute),
+		"future": time.Now().Add(10 * time.Minute),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := verify(t, newTestVerifier(), peer, fedtest.WithDate(date))
+			if !errors.Is(err, fedinfra.ErrSignatureExpired) {
+				t.Fatalf("err = %v, want %v", err, fedinfra.ErrSignatureExpired)
+			}
+		})
+	}
+}
+
+func TestVerifyRequestRejectsDigestMismatch(t *testing.T) {
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmRSASHA256)
+	_, err := verify(t, newTestVerifier(), peer, fedtest.WithSentBody([]byte(`{"requester_url":"https://evil.example"}`)))
+	if !errors.Is(err, fedinfra.ErrInvalidDigest) {
+		t.Fatalf("err = %v, want %v", err, fedinfra.ErrInvalidDigest)
+	}
+}
+
+func TestVerifyRequestRejectsUnsupportedAlgorithm(t *testing.T) {
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmRSASHA256)
+	peer.DeclareAlgorithm("rsa-sha512")
+	_, err := verify(t, newTestVerifier(), peer)
+	if !errors.Is(err, fedinfra.ErrUnsupportedSignatureAlgorithm) {
+		t.Fatalf("err = %v, want %v", err, fedinfra.ErrUnsupportedSignatureAlgorithm)
+	}
+}
+
+func TestVerifyRequestRejectsReplay(t *testing.T) {
+	peer := fedtest.NewInstance(t, fedinfra.AlgorithmEd25519)
+	verifier := newTestVerifier()
+	ctx := context.Background()
+	req, err := peer.NewSignedRequest(ctx, http.MethodPost, "https://blog.example.com/api/federation/mentions/notify", testBody)
+	if err != nil {
+		t.Fatalf("sign request: %v", err)
+	}
+	if _, err := verifier.VerifyRequest(ctx, req, testBody); err != nil {
+		t.Fatalf("first delivery: %v", err)
+	}
+	if _, err := verifier.VerifyRequest(ctx, req, testBody); !errors.Is(err, fedinfra.ErrReplayedRequest) {
+		t.Fatalf("err = %v, want %v", err, fedinfra.ErrReplayedRequest)
+	}
+}
@@ -0,0 +1,44 @@
+package imageproc
+
+import (
+	"fmt"
+	"image"
+)
+
+// DominantColor 返回图片的主色（#rrggbb）：把像素按每通道 4 位量化分桶，取像素最多的桶的平均色。
+// 近乎透明的像素不参与统计；调用方应先用 Fit 缩小图片。
+func DominantColor(img image.Image) string {
+	src := toNRGBA(img)
+	b := src.Bounds()
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+	buckets := make(map[uint16]*bucket)
+	var best *bucket
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := src.NRGBAAt(x, y)
+			if c.A < 128 {
+				continue
+			}
+			key := uint16(c.R>>4)<<8 | uint16(c.G>>4)<<4 | uint16(c.B>>4)
+			bk := buckets[key]
+			if bk == nil {
+				bk = &bucket{}
+				buckets[key] = bk
+			}
+			bk.count++
+			bk.r += int(c.R)
+			bk.g += int(c.G)
+			bk.b += int(c.B)
+			if best == nil || bk.count > best.count {
+				best = bk
+			}
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
@@ -0,0 +1,514 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Exif 是从图片中读取的 EXIF 字段，缺失的字段保持零值。
+type Exif struct {
+	Orientation int
+	Make        string
+	Model       string
+	LensModel   string
+	TakenAt     time.Time
+	GPS         *GPS
+}
+
+// GPS 是十进制度数表示的拍摄位置，南纬、西经为负数。
+type GPS struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Device 返回拍摄设备描述，型号已包含厂商名时不再重复。
+func (e *Exif) Device() string {
+	model := strings.TrimSpace(e.Model)
+	maker := strings.TrimSpace(e.Make)
+	switch {
+	case model == "":
+		return maker
+	case maker == "" || strings.HasPrefix(strings.ToLower(model), strings.ToLower(maker)):
+		return model
+	default:
+		return maker + " " + model
+	}
+}
+
+// Location 返回 "纬度, 经度" 形式的位置，没有定位信息时返回空串。
+func (e *Exif) Location() string {
+	if e.GPS == nil {
+		return ""
+	}
+	return strconv.FormatFloat(e.GPS.Latitude, 'f', 6, 64) + ", " + strconv.FormatFloat(e.GPS.Longitude, 'f', 6, 64)
+}
+
+var errNoExif = errors.New("imageproc: no exif data")
+
+const (
+	tagMake             = 0x010f
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagLensModel        = 0xa434
+
+	tagGPSLatitudeRef  = 0x0001
+	tagGPSLatitude     = 0x0002
+	tagGPSLongitudeRef = 0x0003
+	tagGPSLongitude    = 0x0004
+)
+
+var (
+	exifHeader = []byte("Exif\x00\x00")
+	xmpHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	pngMagic   = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// ReadExif 读取 JPEG APP1 段、PNG eXIf 块或 WebP EXIF 块中的 EXIF 信息。
+// 应在 StripGPS 之前调用，否则读不到定位信息。
+func ReadExif(data []byte) (*Exif, error) {
+	payload, err := exifPayload(data)
+	if err != nil {
+		return nil, err
+	}
+	t, err := parseTIFF(payload)
+	if err != nil {
+		return nil, err
+	}
+	entries, _, err := t.ifd(t.firstIFD)
+	if err != nil {
+		return nil, err
+	}
+	exif := &Exif{Orientation: 1}
+	for _, entry := range entries {
+		switch entry.tag {
+		case tagOrientation:
+			exif.Orientation = int(t.uint16(entry.raw[:2]))
+		case tagMake:
+			exif.Make = t.ascii(entry)
+		case tagModel:
+			exif.Model = t.ascii(entry)
+		case tagExifIFD:
+			t.readExifIFD(int(t.uint32(entry.raw[:])), exif)
+		case tagGPSIFD:
+			exif.GPS = t.readGPS(int(t.uint32(entry.raw[:])))
+		}
+	}
+	return exif, nil
+}
+
+func exifPayload(data []byte) ([]byte, error) {
+	var payload []byte
+	switch {
+	case len(data) > 2 && data[0] == 0xff && data[1] == 0xd8:
+		err := walkJPEG(data, func(marker byte, segment []byte) bool {
+			if marker == 0xe1 && bytes.HasPrefix(segment, exifHeader) {
+				payload = segment[len(exifHeader):]
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	case bytes.HasPrefix(data, pngMagic):
+		for pos := len(pngMagic); pos+12 <= len(data); {
+			length := int(binary.BigEndian.Uint32(data[pos:]))
+			if length < 0 || pos+12+length > len(data) {
+				break
+			}
+			if string(data[pos+4:pos+8]) == "eXIf" {
+				payload = data[pos+8 : pos+8+length]
+				break
+			}
+			pos += 12 + length
+		}
+	case len(data) > 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		for pos := 12; pos+8 <= len(data); {
+			length := int(binary.LittleEndian.Uint32(data[pos+4:]))
+			if length < 0 || pos+8+length > len(data) {
+				break
+			}
+			if string(data[pos:pos+4]) == "EXIF" {
+				// 部分编码器会在 WebP 的 EXIF 块前保留 JPEG 的 "Exif\0\0" 前缀。
+				payload = bytes.TrimPrefix(data[pos+8:pos+8+length], exifHeader)
+				break
+			}
+			pos += 8 + length + length%2
+		}
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if payload == nil {
+		return nil, errNoExif
+	}
+	return payload, nil
+}
+
+// StripGPS 移除图片中的 GPS 定位信息，返回新数据与是否有改动。
+// JPEG/WebP 删除 EXIF 的 GPS IFD 与含定位的 XMP；PNG 删除 eXIf 块与含定位的 XMP。
+// 无法解析的 EXIF 整段删除，其他格式原样返回。
+func StripGPS(data []byte) ([]byte, bool) {
+	switch {
+	case len(data) > 2 && data[0] == 0xff && data[1] == 0xd8:
+		return stripJPEG(data)
+	case bytes.HasPrefix(data, pngMagic):
+		return stripPNG(data)
+	case len(data) > 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return stripWebP(data)
+	default:
+		return data, false
+	}
+}
+
+// walkJPEG 依次回调 SOS 之前的每个标记段（不含长度字段），fn 返回 false 时停止。
+func walkJPEG(data []byte, fn func(marker byte, segment []byte) bool) error {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return ErrUnsupportedFormat
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xff {
+			return errors.New("imageproc: malformed jpeg")
+		}
+		marker := data[pos+1]
+		if marker == 0xda || marker == 0xd9 {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return errors.New("imageproc: malformed jpeg")
+		}
+		if !fn(marker, data[pos+4:pos+2+length]) {
+			return nil
+		}
+		pos += 2 + length
+	}
+	return nil
+}
+
+func stripJPEG(data []byte) ([]byte, bool) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	changed := false
+	pos := 2
+	err := walkJPEG(data, func(marker byte, segment []byte) bool {
+		start := pos
+		pos += 4 + len(segment)
+		if marker == 0xe1 {
+			switch {
+			case bytes.HasPrefix(segment, exifHeader):
+				cleaned, ok := removeGPSIFD(segment[len(exifHeader):])
+				if !ok {
+					changed = true
+					return true
+				}
+				if cleaned != nil {
+					changed = true
+					out = append(out, data[start:start+4+len(exifHeader)]...)
+					out = append(out, cleaned...)
+					return true
+				}
+			case bytes.HasPrefix(segment, xmpHeader) && bytes.Contains(segment, []byte("GPS")):
+				changed = true
+				return true
+			}
+		}
+		out = append(out, data[start:pos]...)
+		return true
+	})
+	if err != nil || !changed {
+		return data, false
+	}
+	return append(out, data[pos:]...), true
+}
+
+func stripPNG(data []byte) ([]byte, bool) {
+	out := make([]byte, 0, len(data))
+	out = append(out, pngMagic...)
+	changed := false
+	pos := len(pngMagic)
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return data, false
+		}
+		kind := string(data[pos+4 : pos+8])
+		body := data[pos+8 : pos+8+length]
+		drop := kind == "eXIf" ||
+			(kind == "iTXt" && bytes.HasPrefix(body, []byte("XML:com.adobe.xmp\x00")) && bytes.Contains(body, []byte("GPS")))
+		if drop {
+			changed = true
+		} else {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	if !changed {
+		return data, false
+	}
+	return append(out, data[pos:]...), true
+}
+
+func stripWebP(data []byte) ([]byte, bool) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	changed := false
+	var clearFlags byte
+	vp8x := -1
+	pos := 12
+	for pos+8 <= len(data) {
+		length := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + length + length%2
+		if length < 0 || pos+8+length > len(data) {
+			return data, false
+		}
+		if end > len(data) {
+			end = len(data)
+		}
+		kind := string(data[pos : pos+4])
+		body := data[pos+8 : pos+8+length]
+		switch {
+		case kind == "EXIF":
+			changed = true
+			clearFlags |= 0x08
+		case kind == "XMP " && bytes.Contains(body, []byte("GPS")):
+			changed = true
+			clearFlags |= 0x04
+		default:
+			if kind == "VP8X" && length >= 1 {
+				vp8x = len(out) + 8
+			}
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	if !changed {
+		return data, false
+	}
+	if vp8x >= 0 {
+		out[vp8x] &^= clearFlags
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, true
+}
+
+// removeGPSIFD 在 TIFF 数据副本中删除 IFD0 的 GPS 指针并清零 GPS IFD 的内容，其余偏移保持不变。
+// 没有 GPS 时返回 (nil, true)；结构无法解析时返回 ok=false，调用方应丢弃整段 EXIF。
+func removeGPSIFD(payload []byte) ([]byte, bool) {
+	t, err := parseTIFF(append([]byte(nil), payload...))
+	if err != nil {
+		return nil, false
+	}
+	entries, next, err := t.ifd(t.firstIFD)
+	if err != nil {
+		return nil, false
+	}
+	index := -1
+	for i, entry := range entries {
+		if entry.tag == tagGPSIFD {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, true
+	}
+
+	gpsOffset := int(t.uint32(entries[index].raw[:]))
+	if gpsEntries, _, err := t.ifd(gpsOffset); err == nil {
+		for _, entry := range gpsEntries {
+			if size := entry.size(); size > 4 {
+				offset := int(t.uint32(entry.raw[:]))
+				if offset >= 0 && offset+size <= len(t.data) {
+					clear(t.data[offset : offset+size])
+				}
+			}
+		}
+		clear(t.data[gpsOffset : gpsOffset+2+12*len(gpsEntries)+4])
+	} else {
+		return nil, false
+	}
+
+	// 删掉 IFD0 中的 GPS 条目：后续条目与 next 指针前移 12 字节，空出的尾部清零。
+	base := t.firstIFD
+	count := len(entries)
+	entryStart := base + 2 + 12*index
+	ifdEnd := base + 2 + 12*count + 4
+	copy(t.data[entryStart:], t.data[entryStart+12:ifdEnd])
+	clear(t.data[ifdEnd-12 : ifdEnd])
+	t.putUint16(t.data[base:], uint16(count-1))
+	t.putUint32(t.data[base+2+12*(count-1):], uint32(next))
+	return t.data, true
+}
+
+// tiff 是 EXIF 使用的 TIFF 结构的最小解析器。
+type tiff struct {
+	data     []byte
+	order    binary.ByteOrder
+	firstIFD int
+}
+
+type tiffEntry struct {
+	tag   uint16
+	kind  uint16
+	count uint32
+	raw   [4]byte
+}
+
+var tiffTypeSize = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+func (e tiffEntry) size() int {
+	return tiffTypeSize[e.kind] * int(e.count)
+}
+
+func parseTIFF(data []byte) (*tiff, error) {
+	if len(data) < 8 {
+		return nil, errNoExif
+	}
+	t := &tiff{data: data}
+	switch string(data[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return nil, errors.New("imageproc: invalid tiff header")
+	}
+	if t.order.Uint16(data[2:]) != 42 {
+		return nil, errors.New("imageproc: invalid tiff header")
+	}
+	t.firstIFD = int(t.order.Uint32(data[4:]))
+	return t, nil
+}
+
+// ifd 解析 offset 处的 IFD，返回条目与下一个 IFD 的偏移。
+func (t *tiff) ifd(offset int) ([]tiffEntry, int, error) {
+	if offset < 8 || offset+2 > len(t.data) {
+		return nil, 0, errors.New("imageproc: invalid ifd offset")
+	}
+	count := int(t.order.Uint16(t.data[offset:]))
+	end := offset + 2 + 12*count + 4
+	if end > len(t.data) {
+		return nil, 0, errors.New("imageproc: truncated ifd")
+	}
+	entries := make([]tiffEntry, count)
+	for i := range entries {
+		p := offset + 2 + 12*i
+		entries[i] = tiffEntry{
+			tag:   t.order.Uint16(t.data[p:]),
+			kind:  t.order.Uint16(t.data[p+2:]),
+			count: t.order.Uint32(t.data[p+4:]),
+		}
+		copy(entries[i].raw[:], t.data[p+8:p+12])
+	}
+	return entries, int(t.order.Uint32(t.data[end-4:])), nil
+}
+
+// readExifIFD 读取 Exif 子 IFD 中的拍摄时间与镜头型号，解析失败时忽略。
+func (t *tiff) readExifIFD(offset int, exif *Exif) {
+	entries, _, err := t.ifd(offset)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		switch entry.tag {
+		case tagDateTimeOriginal:
+			// EXIF 时间不带时区，按 UTC 解析，仅用于展示与排序。
+			if taken, err := time.Parse("2006:01:02 15:04:05", t.ascii(entry)); err == nil {
+				exif.TakenAt = taken
+			}
+		case tagLensModel:
+			exif.LensModel = t.ascii(entry)
+		}
+	}
+}
+
+// readGPS 读取 GPS IFD 中的经纬度，缺少任一坐标时返回 nil。
+func (t *tiff) readGPS(offset int) *GPS {
+	entries, _, err := t.ifd(offset)
+	if err != nil {
+		return nil
+	}
+	var latRef, lonRef string
+	var lat, lon []float64
+	for _, entry := range entries {
+		switch entry.tag {
+		case tagGPSLatitudeRef:
+			latRef = t.ascii(entry)
+		case tagGPSLatitude:
+			lat = t.rationals(entry)
+		case tagGPSLongitudeRef:
+			lonRef = t.ascii(entry)
+		case tagGPSLongitude:
+			lon = t.rationals(entry)
+		}
+	}
+	if len(lat) != 3 || len(lon) != 3 {
+		return nil
+	}
+	gps := &GPS{
+		Latitude:  lat[0] + lat[1]/60 + lat[2]/3600,
+		Longitude: lon[0] + lon[1]/60 + lon[2]/3600,
+	}
+	if latRef == "S" {
+		gps.Latitude = -gps.Latitude
+	}
+	if lonRef == "W" {
+		gps.Longitude = -gps.Longitude
+	}
+	if math.Abs(gps.Latitude) > 90 || math.Abs(gps.Longitude) > 180 {
+		return nil
+	}
+	return gps
+}
+
+// value 返回条目的原始值：不超过 4 字节时内联在条目中，否则位于偏移处。
+func (t *tiff) value(entry tiffEntry) []byte {
+	size := entry.size()
+	if size <= 4 {
+		return entry.raw[:size]
+	}
+	offset := int(t.uint32(entry.raw[:]))
+	if offset < 0 || offset+size > len(t.data) {
+		return nil
+	}
+	return t.data[offset : offset+size]
+}
+
+func (t *tiff) ascii(entry tiffEntry) string {
+	if entry.kind != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(t.value(entry)), "\x00"))
+}
+
+func (t *tiff) rationals(entry tiffEntry) []float64 {
+	if entry.kind != 5 {
+		return nil
+	}
+	raw := t.value(entry)
+	values := make([]float64, 0, len(raw)/8)
+	for i := 0; i+8 <= len(raw); i += 8 {
+		den := t.uint32(raw[i+4:])
+		if den == 0 {
+			return nil
+		}
+		values = append(values, float64(t.uint32(raw[i:]))/float64(den))
+	}
+	return values
+}
+
+func (t *tiff) uint16(b []byte) uint16 { return t.order.Uint16(b) }
+func (t *tiff) uint32(b []byte) uint32 { return t.order.Uint32(b) }
+
+func (t *tiff) putUint16(b []byte, v uint16) { t.order.PutUint16(b, v) }
+func (t *tiff) putUint32(b []byte, v uint32) { t.order.PutUint32(b, v) }
@@ -0,0 +1,217 @@
+// Package imageproc 提供上传图片的解码、缩放、编码与元数据提取，全部为纯 Go 实现。
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+	FormatWebP = "webp"
+	FormatAVIF = "avif"
+)
+
+// DefaultQuality 是有损编码的默认质量。
+const DefaultQuality = 80
+
+var (
+	ErrUnsupportedFormat = errors.New("imageproc: unsupported format")
+	ErrTooManyPixels     = errors.New("imageproc: image has too many pixels")
+)
+
+// NormalizeFormat 统一格式名，例如 "jpg" -> "jpeg"；无法识别时返回空串。
+func NormalizeFormat(format string) string {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "jpg", "jpeg":
+		return FormatJPEG
+	case "png":
+		return FormatPNG
+	case "gif":
+		return FormatGIF
+	case "webp":
+		return FormatWebP
+	case "avif":
+		return FormatAVIF
+	default:
+		return ""
+	}
+}
+
+// CanEncode 返回是否能输出该格式。AVIF 目前没有可用的纯 Go 编码器，始终返回 false。
+func CanEncode(format string) bool {
+	switch NormalizeFormat(format) {
+	case FormatJPEG, FormatPNG, FormatWebP:
+		return true
+	default:
+		return false
+	}
+}
+
+// Extension 返回格式对应的文件扩展名。
+func Extension(format string) string {
+	switch NormalizeFormat(format) {
+	case FormatJPEG:
+		return ".jpg"
+	case "":
+		return ""
+	default:
+		return "." + NormalizeFormat(format)
+	}
+}
+
+// Image 是解码后的图片，已按 EXIF 方向校正。
+type Image struct {
+	Image  image.Image
+	Format string
+	Exif   *Exif
+}
+
+func (i *Image) Width() int  { return i.Image.Bounds().Dx() }
+func (i *Image) Height() int { return i.Image.Bounds().Dy() }
+
+// Decode 解码图片；maxPixels > 0 时先读取尺寸，像素数超限返回 ErrTooManyPixels，避免大图耗尽内存。
+func Decode(data []byte, maxPixels int) (*Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if maxPixels > 0 && cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooManyPixels
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	result := &Image{Image: img, Format: NormalizeFormat(format)}
+	if result.Format == FormatJPEG {
+		if exif, err := ReadExif(data); err == nil {
+			result.Exif = exif
+			result.Image = applyOrientation(img, exif.Orientation)
+		}
+	}
+	return result, nil
+}
+
+// Resize 按宽度等比缩放，不放大；width <= 0 或不小于原宽时原样返回。
+func Resize(img image.Image, width int) image.Image {
+	b := img.Bounds()
+	if width <= 0 || width >= b.Dx() {
+		return img
+	}
+	height := b.Dy() * width / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// Fit 把图片缩小到长边不超过 maxSide，用于计算主色与 blurhash。
+func Fit(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= maxSide && b.Dy() <= maxSide {
+		return img
+	}
+	width := maxSide
+	if b.Dy() > b.Dx() {
+		width = b.Dx() * maxSide / b.Dy()
+		if width < 1 {
+			width = 1
+		}
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, max(1, b.Dy()*width/b.Dx())))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// Encode 按格式编码图片，quality 只对有损格式生效。
+func Encode(w io.Writer, img image.Image, format string, quality int) error {
+	if quality <= 0 || quality > 100 {
+		quality = DefaultQuality
+	}
+	switch NormalizeFormat(format) {
+	case FormatJPEG:
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: quality})
+	case FormatPNG:
+		return png.Encode(w, img)
+	case FormatWebP:
+		return EncodeWebP(w, img, quality)
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// flatten 把透明像素合成到白色背景上，JPEG 不支持透明通道。
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, b, img, b.Min, draw.Over)
+	return dst
+}
+
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok {
+		return nrgba
+	}
+	b := img.Bounds()
+	dst := image.NewNRGBA(b)
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+	return dst
+}
+
+// applyOrientation 按 EXIF Orientation（1-8）旋转或翻转图片，使像素方向与观感一致。
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	src := toNRGBA(img)
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	swap := orientation >= 5
+	dw, dh := w, h
+	if swap {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.SetNRGBA(dx, dy, src.NRGBAAt(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
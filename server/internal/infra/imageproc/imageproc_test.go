@@ -0,0 +1,312 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/image/webp"
+)
+
+// gradient 生成带圆形高光的渐变图，覆盖平滑区域与边缘。
+func gradient(width, height int, withAlpha bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{R: uint8(x * 255 / width), G: uint8(y * 255 / height), B: 96, A: 255}
+			dx, dy := x-width/2, y-height/3
+			if dx*dx+dy*dy < (width/5)*(width/5) {
+				c = color.NRGBA{R: 240, G: 220, B: 40, A: 255}
+			}
+			if withAlpha && x < width/4 {
+				c.A = 0
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestEncodeWebPRoundTrip(t *testing.T) {
+	for _, size := range [][2]int{{1, 1}, {17, 9}, {160, 90}, {333, 217}} {
+		src := gradient(size[0], size[1], false)
+		var buf bytes.Buffer
+		if err := EncodeWebP(&buf, src, 90); err != nil {
+			t.Fatalf("encode %v: %v", size, err)
+		}
+		decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("decode %v: %v", size, err)
+		}
+		ycc, ok := decoded.(*image.YCbCr)
+		if !ok {
+			t.Fatalf("decoded type %T", decoded)
+		}
+		if ycc.Bounds().Dx() != size[0] || ycc.Bounds().Dy() != size[1] {
+			t.Fatalf("decoded size %v, want %v", ycc.Bounds(), size)
+		}
+		want, _ := toYUV420(src)
+		var sum float64
+		for y := 0; y < size[1]; y++ {
+			for x := 0; x < size[0]; x++ {
+				d := float64(ycc.Y[y*ycc.YStride+x]) - float64(want.y[y*want.yStride+x])
+				sum += d * d
+			}
+		}
+		mse := sum / float64(size[0]*size[1])
+		psnr := 10 * math.Log10(255*255/math.Max(mse, 1e-9))
+		if psnr < 32 {
+			t.Fatalf("luma psnr %.2f dB for %v", psnr, size)
+		}
+	}
+}
+
+func TestEncodeWebPAlpha(t *testing.T) {
+	src := gradient(64, 48, true)
+	var buf bytes.Buffer
+	if err := EncodeWebP(&buf, src, 80); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, a := decoded.At(2, 2).RGBA(); a != 0 {
+		t.Fatalf("alpha at (2,2) = %d, want 0", a)
+	}
+	if _, _, _, a := decoded.At(60, 40).RGBA(); a != 0xffff {
+		t.Fatalf("alpha at (60,40) = %d, want opaque", a)
+	}
+}
+
+func TestBlurhashAndDominantColor(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	for i := range img.Pix {
+		img.Pix[i] = []uint8{200, 30, 30, 255}[i%4]
+	}
+	hash, err := Blurhash(img, 4, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 期望值按 blurha.sh 参考算法对同一纯色图片独立计算得出。
+	if want := "L5M^z||wfQ|w|wo1fQo1fQfQfQfQ"; hash != want {
+		t.Fatalf("blurhash = %s, want %s", hash, want)
+	}
+	if color := DominantColor(img); color != "#c81e1e" {
+		t.Fatalf("dominant color %s", color)
+	}
+}
+
+func TestStripGPS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, gradient(16, 16, false), nil); err != nil {
+		t.Fatal(err)
+	}
+	original := withExif(buf.Bytes(), buildExif(6, true))
+
+	exif, err := ReadExif(original)
+	if err != nil || exif.Orientation != 6 {
+		t.Fatalf("read exif = %+v, %v", exif, err)
+	}
+
+	stripped, changed := StripGPS(original)
+	if !changed {
+		t.Fatal("expected gps to be stripped")
+	}
+	if bytes.Contains(stripped, []byte("N\x00\x00\x00")) || bytes.Contains(stripped, gpsMarker) {
+		t.Fatal("gps data still present")
+	}
+	exif, err = ReadExif(stripped)
+	if err != nil || exif.Orientation != 6 {
+		t.Fatalf("orientation lost after strip: %+v, %v", exif, err)
+	}
+	decoded, err := Decode(stripped, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Width() != 16 || decoded.Height() != 16 {
+		t.Fatalf("decoded size %dx%d", decoded.Width(), decoded.Height())
+	}
+
+	if _, changed := StripGPS(withExif(buf.Bytes(), buildExif(1, false))); changed {
+		t.Fatal("image without gps should be unchanged")
+	}
+}
+
+var gpsMarker = []byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}
+
+// buildExif 构造小端 TIFF：IFD0 含 Orientation 与（可选）GPS 指针，GPS IFD 含纬度参考与一个 8 字节有理数。
+func buildExif(orientation uint16, gps bool) []byte {
+	le := binary.LittleEndian
+	data := []byte("II*\x00\x08\x00\x00\x00")
+	count := uint16(1)
+	if gps {
+		count = 2
+	}
+	ifd := make([]byte, 2+12*int(count)+4)
+	le.PutUint16(ifd, count)
+	le.PutUint16(ifd[2:], tagOrientation)
+	le.PutUint16(ifd[4:], 3)
+	le.PutUint32(ifd[6:], 1)
+	le.PutUint16(ifd[10:], orientation)
+	if gps {
+		gpsOffset := uint32(8 + len(ifd))
+		le.PutUint16(ifd[14:], tagGPSIFD)
+		le.PutUint16(ifd[16:], 4)
+		le.PutUint32(ifd[18:], 1)
+		le.PutUint32(ifd[22:], gpsOffset)
+	}
+	data = append(data, ifd...)
+	if gps {
+		gpsIFD := make([]byte, 2+12*2+4)
+		le.PutUint16(gpsIFD, 2)
+		le.PutUint16(gpsIFD[2:], 1) // GPSLatitudeRef
+		le.PutUint16(gpsIFD[4:], 2)
+		le.PutUint32(gpsIFD[6:], 2)
+		copy(gpsIFD[10:], "N\x00\x00\x00")
+		le.PutUint16(gpsIFD[14:], 2) // GPSLatitude
+		le.PutUint16(gpsIFD[16:], 5)
+		le.PutUint32(gpsIFD[18:], 1)
+		le.PutUint32(gpsIFD[22:], uint32(len(data)+len(gpsIFD)))
+		data = append(data, gpsIFD...)
+		data = append(data, gpsMarker...)
+	}
+	return data
+}
+
+func withExif(jpegData []byte, tiffData []byte) []byte {
+	segment := append([]byte("Exif\x00\x00"), tiffData...)
+	out := []byte{0xff, 0xd8, 0xff, 0xe1, byte((len(segment) + 2) >> 8), byte(len(segment) + 2)}
+	out = append(out, segment...)
+	return append(out, jpegData[2:]...)
+}
+
+func TestReadExifDeviceAndGPS(t *testing.T) {
+	rational := func(values ...uint32) []byte {
+		out := make([]byte, 0, 8*len(values))
+		for _, v := range values {
+			out = binary.LittleEndian.AppendUint32(out, v)
+			out = binary.LittleEndian.AppendUint32(out, 1)
+		}
+		return out
+	}
+	data := buildTIFF(
+		[]testTIFFEntry{
+			{tag: tagMake, kind: 2, value: []byte("Canon\x00")},
+			{tag: tagModel, kind: 2, value: []byte("Canon EOS R5\x00")},
+		},
+		[]testTIFFEntry{
+			{tag: tagDateTimeOriginal, kind: 2, value: []byte("2024:05:01 08:30:00\x00")},
+		},
+		[]testTIFFEntry{
+			{tag: tagGPSLatitudeRef, kind: 2, value: []byte("N\x00")},
+			{tag: tagGPSLatitude, kind: 5, value: rational(31, 14, 24)},
+			{tag: tagGPSLongitudeRef, kind: 2, value: []byte("W\x00")},
+			{tag: tagGPSLongitude, kind: 5, value: rational(121, 30, 0)},
+		},
+	)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, gradient(8, 8, false), nil); err != nil {
+		t.Fatal(err)
+	}
+	exif, err := ReadExif(withExif(buf.Bytes(), data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exif.Device() != "Canon EOS R5" {
+		t.Fatalf("device = %q", exif.Device())
+	}
+	if exif.Location() != "31.240000, -121.500000" {
+		t.Fatalf("location = %q", exif.Location())
+	}
+	if !exif.TakenAt.Equal(time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)) {
+		t.Fatalf("taken at = %v", exif.TakenAt)
+	}
+}
+
+type testTIFFEntry struct {
+	tag   uint16
+	kind  uint16
+	value []byte
+}
+
+// buildTIFF 构造小端 TIFF：IFD0 之后依次放 Exif 子 IFD 与 GPS IFD，超过 4 字节的值追加在末尾。
+func buildTIFF(ifd0 []testTIFFEntry, exifIFD []testTIFFEntry, gpsIFD []testTIFFEntry) []byte {
+	le := binary.LittleEndian
+	ifdSize := func(n int) int { return 2 + 12*n + 4 }
+	ifd0 = append(ifd0,
+		testTIFFEntry{tag: tagExifIFD, kind: 4},
+		testTIFFEntry{tag: tagGPSIFD, kind: 4},
+	)
+	exifOffset := 8 + ifdSize(len(ifd0))
+	gpsOffset := exifOffset + ifdSize(len(exifIFD))
+	ifd0[len(ifd0)-2].value = le.AppendUint32(nil, uint32(exifOffset))
+	ifd0[len(ifd0)-1].value = le.AppendUint32(nil, uint32(gpsOffset))
+
+	data := []byte("II*\x00\x08\x00\x00\x00")
+	extra := gpsOffset + ifdSize(len(gpsIFD))
+	var tail []byte
+	for _, ifd := range [][]testTIFFEntry{ifd0, exifIFD, gpsIFD} {
+		data = le.AppendUint16(data, uint16(len(ifd)))
+		for _, entry := range ifd {
+			count := len(entry.value) / tiffTypeSize[entry.kind]
+			data = le.AppendUint16(data, entry.tag)
+			data = le.AppendUint16(data, entry.kind)
+			data = le.AppendUint32(data, uint32(count))
+			if len(entry.value) <= 4 {
+				raw := make([]byte, 4)
+				copy(raw, entry.value)
+				data = append(data, raw...)
+				continue
+			}
+			data = le.AppendUint32(data, uint32(extra+len(tail)))
+			tail = append(tail, entry.value...)
+		}
+		data = le.AppendUint32(data, 0)
+	}
+	return append(data, tail...)
+}
+
+func TestSanitizeSVG(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE svg [<!ENTITY x "boom">]>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" onload="alert(1)" viewBox="0 0 10 10">
+  <!-- comment -->
+  <script>alert(1)</script>
+  <foreignObject><div>html</div></foreignObject>
+  <a xlink:href="java&#x09;script:alert(1)"><rect width="10" height="10" style="fill:red"/></a>
+  <use href="#icon"/>
+  <image href="data:image/svg+xml;base64,PHN2Zz4="/>
+</svg>`
+	if !LooksLikeSVG([]byte(input)) {
+		t.Fatal("svg not detected")
+	}
+	out, err := SanitizeSVG([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(out)
+	for _, banned := range []string{"script", "onload", "foreignObject", "ENTITY", "comment", "data:image/svg"} {
+		if strings.Contains(got, banned) {
+			t.Errorf("sanitized svg still contains %q: %s", banned, got)
+		}
+	}
+	for _, kept := range []string{`viewBox="0 0 10 10"`, `style="fill:red"`, `href="#icon"`, `xmlns:xlink=`, "<rect"} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("sanitized svg lost %q: %s", kept, got)
+		}
+	}
+	if _, err := SanitizeSVG([]byte(`<html><svg></svg></html>`)); !errors.Is(err, ErrInvalidSVG) {
+		t.Errorf("non-svg root err = %v", err)
+	}
+	if LooksLikeSVG([]byte("hello <svg>")) {
+		t.Error("plain text detected as svg")
+	}
+}
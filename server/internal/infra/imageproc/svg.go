@@ -0,0 +1,198 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrInvalidSVG SVG 无法解析、标签不匹配或根元素不是 svg。
+var ErrInvalidSVG = errors.New("invalid svg document")
+
+// svgBlockedElements 会执行脚本或嵌入外部文档的元素，连同子节点一起移除。
+var svgBlockedElements = map[string]struct{}{
+	"script":        {},
+	"foreignobject": {},
+	"iframe":        {},
+	"embed":         {},
+	"object":        {},
+	"handler":       {},
+	"listener":      {},
+}
+
+// LooksLikeSVG 判断文件头（跳过 XML 声明、注释与 DOCTYPE 后）是否以 <svg 开头。
+func LooksLikeSVG(head []byte) bool {
+	rest := bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	for {
+		rest = bytes.TrimLeft(rest, " \t\r\n")
+		switch {
+		case bytes.HasPrefix(rest, []byte("<?")):
+			rest = skipPast(rest, "?>")
+		case bytes.HasPrefix(rest, []byte("<!--")):
+			rest = skipPast(rest, "-->")
+		case len(rest) >= 9 && strings.EqualFold(string(rest[:9]), "<!doctype"):
+			if open := bytes.IndexByte(rest, '['); open >= 0 && open < bytes.IndexByte(rest, '>') {
+				rest = skipPast(rest, "]")
+			}
+			rest = skipPast(rest, ">")
+		default:
+			return len(rest) >= 4 && strings.EqualFold(string(rest[:4]), "<svg")
+		}
+		if rest == nil {
+			return false
+		}
+	}
+}
+
+func skipPast(data []byte, marker string) []byte {
+	idx := bytes.Index(data, []byte(marker))
+	if idx < 0 {
+		return nil
+	}
+	return data[idx+len(marker):]
+}
+
+// SanitizeSVG 移除 SVG 中可执行或外链的内容后重新序列化：脚本类元素、on* 事件属性、
+// 非安全协议的链接、注释、DOCTYPE（防止实体展开）与 XML 声明以外的处理指令。
+func SanitizeSVG(data []byte) ([]byte, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = true
+
+	var out bytes.Buffer
+	var stack []string
+	skipDepth := 0
+	rootSeen := false
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidSVG
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := qualifiedName(t.Name)
+			stack = append(stack, name)
+			if !rootSeen {
+				if !strings.EqualFold(t.Name.Local, "svg") {
+					return nil, ErrInvalidSVG
+				}
+				rootSeen = true
+			}
+			if skipDepth > 0 {
+				skipDepth++
+				continue
+			}
+			if _, blocked := svgBlockedElements[strings.ToLower(t.Name.Local)]; blocked {
+				skipDepth = 1
+				continue
+			}
+			out.WriteByte('<')
+			out.WriteString(name)
+			for _, attr := range t.Attr {
+				if !safeSVGAttr(attr) {
+					continue
+				}
+				out.WriteByte(' ')
+				out.WriteString(qualifiedName(attr.Name))
+				out.WriteString(`="`)
+				xml.EscapeText(&out, []byte(attr.Value))
+				out.WriteByte('"')
+			}
+			out.WriteByte('>')
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1] != qualifiedName(t.Name) {
+				return nil, ErrInvalidSVG
+			}
+			stack = stack[:len(stack)-1]
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			out.WriteString("</")
+			out.WriteString(qualifiedName(t.Name))
+			out.WriteByte('>')
+		case xml.CharData:
+			if skipDepth > 0 || len(stack) == 0 {
+				continue
+			}
+			if strings.EqualFold(stack[len(stack)-1], "style") && unsafeCSS(string(t)) {
+				continue
+			}
+			xml.EscapeText(&out, t)
+		case xml.ProcInst:
+			if t.Target == "xml" && !rootSeen {
+				out.WriteString("<?xml ")
+				out.Write(t.Inst)
+				out.WriteString("?>\n")
+			}
+		}
+	}
+	if !rootSeen || len(stack) != 0 {
+		return nil, ErrInvalidSVG
+	}
+	return out.Bytes(), nil
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+func safeSVGAttr(attr xml.Attr) bool {
+	local := strings.ToLower(attr.Name.Local)
+	if strings.HasPrefix(local, "on") {
+		return false
+	}
+	value := normalizeAttrValue(attr.Value)
+	if strings.Contains(value, "javascript:") || strings.Contains(value, "vbscript:") {
+		return false
+	}
+	switch local {
+	case "href", "src":
+		return safeSVGLink(value)
+	case "style":
+		return !unsafeCSS(value)
+	}
+	return true
+}
+
+// safeSVGLink 只允许片段、相对路径、http(s)、mailto 与位图 data URI。
+func safeSVGLink(value string) bool {
+	if value == "" || strings.HasPrefix(value, "#") {
+		return true
+	}
+	for _, prefix := range []string{"http://", "https://", "mailto:", "data:image/png", "data:image/jpeg", "data:image/gif", "data:image/webp"} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	colon := strings.IndexByte(value, ':')
+	return colon < 0 || strings.ContainsAny(value[:colon], "/?#")
+}
+
+func unsafeCSS(value string) bool {
+	value = normalizeAttrValue(value)
+	return strings.Contains(value, "javascript:") ||
+		strings.Contains(value, "expression(") ||
+		strings.Contains(value, "@import") ||
+		strings.Contains(value, "-moz-binding")
+}
+
+// normalizeAttrValue 去掉空白与控制字符并转小写，避免 "java\tscript:" 之类的绕过。
+func normalizeAttrValue(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, value)
+}
@@ -0,0 +1,700 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+)
+
+// 纯 Go 的 WebP 有损编码器：只生成 VP8 关键帧，亮度统一使用 16x16 DC 预测，
+// 色度使用 8x8 DC 预测，系数概率使用默认表。压缩率不及 libwebp，
+// 但输出可被所有 WebP 解码器识别，足以满足缩略图与响应式图片。
+// 带透明通道的图片额外写入未压缩的 ALPH 块（VP8X 扩展格式）。
+
+const maxWebPDimension = 16383
+
+var ErrWebPTooLarge = errors.New("webp: image too large")
+
+// EncodeWebP 以有损 WebP 编码图片，quality 取值 1-100。
+func EncodeWebP(w io.Writer, img image.Image, quality int) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width <= 0 || height <= 0 {
+		return errors.New("webp: empty image")
+	}
+	if width > maxWebPDimension || height > maxWebPDimension {
+		return ErrWebPTooLarge
+	}
+
+	yuv, alpha := toYUV420(img)
+	frame, err := encodeVP8(yuv, width, height, qualityToQIndex(quality))
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	out.Write([]byte{0, 0, 0, 0})
+	out.WriteString("WEBP")
+	if alpha != nil {
+		var vp8x [10]byte
+		vp8x[0] = 0x10 // 含透明通道
+		putUint24(vp8x[4:], uint32(width-1))
+		putUint24(vp8x[7:], uint32(height-1))
+		writeChunk(&out, "VP8X", vp8x[:])
+		// 首字节 0：不做预处理、不做滤波、不压缩。
+		writeChunk(&out, "ALPH", append([]byte{0}, alpha...))
+	}
+	writeChunk(&out, "VP8 ", frame)
+
+	data := out.Bytes()
+	binary.LittleEndian.PutUint32(data[4:8], uint32(len(data)-8))
+	_, err = w.Write(data)
+	return err
+}
+
+func writeChunk(out *bytes.Buffer, fourCC string, payload []byte) {
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(payload)))
+	out.WriteString(fourCC)
+	out.Write(size[:])
+	out.Write(payload)
+	if len(payload)%2 == 1 {
+		out.WriteByte(0)
+	}
+}
+
+func putUint24(dst []byte, v uint32) {
+	dst[0] = byte(v)
+	dst[1] = byte(v >> 8)
+	dst[2] = byte(v >> 16)
+}
+
+// qualityToQIndex 把 1-100 的质量映射为 VP8 量化索引（0 最精细，127 最粗糙）。
+func qualityToQIndex(quality int) int {
+	if quality <= 0 {
+		quality = DefaultQuality
+	}
+	if quality > 100 {
+		quality = 100
+	}
+	return (100 - quality) * 127 / 100
+}
+
+// yuvPlanes 是按宏块对齐（宽高补齐到 16 的倍数）的 4:2:0 平面，边缘以最后一行/列填充。
+type yuvPlanes struct {
+	y, u, v          []uint8
+	yStride, cStride int
+}
+
+// toYUV420 按 BT.601 有限范围转换（与 libwebp 一致）；图片存在非不透明像素时同时返回 alpha 平面。
+func toYUV420(img image.Image) (*yuvPlanes, []byte) {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	mbw, mbh := (width+15)/16, (height+15)/16
+	p := &yuvPlanes{yStride: mbw * 16, cStride: mbw * 8}
+	p.y = make([]uint8, p.yStride*mbh*16)
+	p.u = make([]uint8, p.cStride*mbh*8)
+	p.v = make([]uint8, p.cStride*mbh*8)
+
+	rgb := make([]int32, 3*width*height)
+	alpha := make([]byte, width*height)
+	opaque := true
+	nrgba := toNRGBA(img)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := nrgba.NRGBAAt(nrgba.Rect.Min.X+x, nrgba.Rect.Min.Y+y)
+			i := y*width + x
+			rgb[3*i], rgb[3*i+1], rgb[3*i+2] = int32(c.R), int32(c.G), int32(c.B)
+			alpha[i] = c.A
+			if c.A != 0xff {
+				opaque = false
+			}
+		}
+	}
+	at := func(x, y int) (int32, int32, int32) {
+		if x >= width {
+			x = width - 1
+		}
+		if y >= height {
+			y = height - 1
+		}
+		i := 3 * (y*width + x)
+		return rgb[i], rgb[i+1], rgb[i+2]
+	}
+
+	for y := 0; y < mbh*16; y++ {
+		for x := 0; x < mbw*16; x++ {
+			r, g, bl := at(x, y)
+			p.y[y*p.yStride+x] = uint8((16839*r + 33059*g + 6420*bl + (16 << 16) + (1 << 15)) >> 16)
+		}
+	}
+	for y := 0; y < mbh*8; y++ {
+		for x := 0; x < mbw*8; x++ {
+			var r, g, bl int32
+			for dy := 0; dy < 2; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					pr, pg, pb := at(2*x+dx, 2*y+dy)
+					r, g, bl = r+pr, g+pg, bl+pb
+				}
+			}
+			p.u[y*p.cStride+x] = clipUV(-9719*r - 19081*g + 28800*bl)
+			p.v[y*p.cStride+x] = clipUV(28800*r - 24116*g - 4684*bl)
+		}
+	}
+	if opaque {
+		return p, nil
+	}
+	return p, alpha
+}
+
+func clipUV(v int32) uint8 {
+	v = (v + (1 << 17) + (128 << 18)) >> 18
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+// vp8Quant 是一组 [DC, AC] 量化步长。
+type vp8Quant [2]int32
+
+type vp8Encoder struct {
+	planes *yuvPlanes
+	mbw    int
+	mbh    int
+	y1     vp8Quant
+	y2     vp8Quant
+	uv     vp8Quant
+
+	// 重建后的平面，作为后续宏块的预测来源，必须与解码器的结果逐字节一致。
+	recon *yuvPlanes
+
+	// 相邻块是否含非零系数，用作系数概率的上下文：
+	// top 按列保存上方宏块的最后一行，left 保存左侧宏块的最后一列。
+	topY, topU, topV []uint8
+	topY2            []uint8
+	leftY            [4]uint8
+	leftU, leftV     [2]uint8
+	leftY2           uint8
+
+	tokens *boolEncoder
+}
+
+func encodeVP8(planes *yuvPlanes, width int, height int, qindex int) ([]byte, error) {
+	e := &vp8Encoder{
+		planes: planes,
+		mbw:    (width + 15) / 16,
+		mbh:    (height + 15) / 16,
+		tokens: newBoolEncoder(),
+	}
+	e.recon = &yuvPlanes{
+		y:       make([]uint8, len(planes.y)),
+		u:       make([]uint8, len(planes.u)),
+		v:       make([]uint8, len(planes.v)),
+		yStride: planes.yStride,
+		cStride: planes.cStride,
+	}
+	e.topY = make([]uint8, 4*e.mbw)
+	e.topU = make([]uint8, 2*e.mbw)
+	e.topV = make([]uint8, 2*e.mbw)
+	e.topY2 = make([]uint8, e.mbw)
+
+	e.y1 = vp8Quant{int32(vp8DCQuant[qindex]), int32(vp8ACQuant[qindex])}
+	e.y2 = vp8Quant{int32(vp8DCQuant[qindex]) * 2, int32(vp8ACQuant[qindex]) * 155 / 100}
+	if e.y2[1] < 8 {
+		e.y2[1] = 8
+	}
+	uvIndex := qindex
+	if uvIndex > 117 {
+		uvIndex = 117
+	}
+	e.uv = vp8Quant{int32(vp8DCQuant[uvIndex]), int32(vp8ACQuant[qindex])}
+
+	header := newBoolEncoder()
+	header.putLiteral(0, 1) // color space
+	header.putLiteral(0, 1) // clamping type
+	header.putLiteral(0, 1) // 不分段
+	header.putLiteral(0, 1) // 普通环路滤波
+	header.putLiteral(uint32(loopFilterLevel(qindex)), 6)
+	header.putLiteral(0, 3) // sharpness
+	header.putLiteral(0, 1) // 不使用 mode/ref 滤波增量
+	header.putLiteral(0, 2) // 单一系数分区
+	header.putLiteral(uint32(qindex), 7)
+	for i := 0; i < 5; i++ {
+		header.putLiteral(0, 1) // 各平面不调整量化增量
+	}
+	header.putLiteral(0, 1) // refresh_entropy_probs
+	for i := range vp8CoeffUpdateProbs {
+		for j := range vp8CoeffUpdateProbs[i] {
+			for k := range vp8CoeffUpdateProbs[i][j] {
+				for l := range vp8CoeffUpdateProbs[i][j][k] {
+					header.put(false, vp8CoeffUpdateProbs[i][j][k][l])
+				}
+			}
+		}
+	}
+	header.putLiteral(0, 1) // 不使用 skip 标志
+
+	for mby := 0; mby < e.mbh; mby++ {
+		e.leftY = [4]uint8{}
+		e.leftU, e.leftV = [2]uint8{}, [2]uint8{}
+		e.leftY2 = 0
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			// 关键帧模式树：Y 16x16 DC_PRED = 1,0,0；UV DC_PRED = 0。
+			header.put(true, 145)
+			header.put(false, 156)
+			header.put(false, 163)
+			header.put(false, 142)
+			e.encodeMacroblock(mbx, mby)
+		}
+	}
+
+	first := header.flush()
+	second := e.tokens.flush()
+	// 只使用一个系数分区，解码器要求分区长度可用 24 位表示；首分区长度字段为 19 位。
+	if len(first) >= 1<<19 || len(second) >= 1<<24 {
+		return nil, ErrWebPTooLarge
+	}
+
+	out := make([]byte, 0, 10+len(first)+len(second))
+	tag := uint32(len(first))<<5 | 1<<4 // 关键帧、version 0、show_frame
+	out = append(out, byte(tag), byte(tag>>8), byte(tag>>16))
+	out = append(out, 0x9d, 0x01, 0x2a)
+	out = append(out, byte(width), byte(width>>8), byte(height), byte(height>>8))
+	out = append(out, first...)
+	return append(out, second...), nil
+}
+
+// loopFilterLevel 量化越粗块效应越明显，滤波强度随之提高。
+func loopFilterLevel(qindex int) int {
+	level := qindex / 3
+	if level > 63 {
+		level = 63
+	}
+	return level
+}
+
+const (
+	vp8PlaneY1WithY2 = 0
+	vp8PlaneY2       = 1
+	vp8PlaneUV       = 2
+)
+
+func (e *vp8Encoder) encodeMacroblock(mbx, mby int) {
+	// 亮度：16 个 4x4 块的 DC 另行经 WHT 变换为 Y2 块。
+	predY := dcPrediction(e.recon.y, e.recon.yStride, mbx*16, mby*16, 16, mbx > 0, mby > 0)
+	var coeffs [16][16]int32
+	var dc [16]int32
+	for n := 0; n < 16; n++ {
+		bx, by := mbx*16+(n%4)*4, mby*16+(n/4)*4
+		forwardDCT(e.planes.y, e.planes.yStride, bx, by, predY, &coeffs[n])
+		dc[n] = coeffs[n][0]
+	}
+	var y2 [16]int32
+	forwardWHT(&dc, &y2)
+	var y2q [16]int32
+	quantizeBlock(&y2, &y2q, e.y2, 0)
+
+	ctx := e.leftY2 + e.topY2[mbx]
+	nz := e.writeCoefficients(&y2q, vp8PlaneY2, ctx, 0)
+	e.leftY2, e.topY2[mbx] = nz, nz
+
+	// 先按解码器的方式还原 Y2，得到每个 4x4 块实际使用的 DC。
+	var y2deq [16]int32
+	for i := range y2q {
+		y2deq[i] = y2q[i] * e.y2[btoi(i > 0)]
+	}
+	var dcRecon [16]int32
+	inverseWHT(&y2deq, &dcRecon)
+
+	for n := 0; n < 16; n++ {
+		var q [16]int32
+		quantizeBlock(&coeffs[n], &q, e.y1, 1)
+		col, row := n%4, n/4
+		ctx := e.leftY[row] + e.topY[mbx*4+col]
+		nz := e.writeCoefficients(&q, vp8PlaneY1WithY2, ctx, 1)
+		e.leftY[row], e.topY[mbx*4+col] = nz, nz
+
+		var deq [16]int32
+		deq[0] = dcRecon[n]
+		for i := 1; i < 16; i++ {
+			deq[i] = q[i] * e.y1[1]
+		}
+		inverseDCT(e.recon.y, e.recon.yStride, mbx*16+col*4, mby*16+row*4, predY, &deq)
+	}
+
+	e.encodeChroma(e.planes.u, e.recon.u, mbx, mby, e.topU, &e.leftU)
+	e.encodeChroma(e.planes.v, e.recon.v, mbx, mby, e.topV, &e.leftV)
+}
+
+func (e *vp8Encoder) encodeChroma(src []uint8, recon []uint8, mbx, mby int, top []uint8, left *[2]uint8) {
+	stride := e.planes.cStride
+	pred := dcPrediction(recon, stride, mbx*8, mby*8, 8, mbx > 0, mby > 0)
+	for n := 0; n < 4; n++ {
+		col, row := n%2, n/2
+		bx, by := mbx*8+col*4, mby*8+row*4
+		var coeffs, q, deq [16]int32
+		forwardDCT(src, stride, bx, by, pred, &coeffs)
+		quantizeBlock(&coeffs, &q, e.uv, 0)
+		ctx := left[row] + top[mbx*2+col]
+		nz := e.writeCoefficients(&q, vp8PlaneUV, ctx, 0)
+		left[row], top[mbx*2+col] = nz, nz
+		for i := range q {
+			deq[i] = q[i] * e.uv[btoi(i > 0)]
+		}
+		inverseDCT(recon, stride, bx, by, pred, &deq)
+	}
+}
+
+// dcPrediction 计算 DC 预测值：上方与左侧都不可用时为 128，否则取可用边的均值。
+func dcPrediction(plane []uint8, stride, x, y, size int, hasLeft, hasTop bool) int32 {
+	var sum, count int32
+	if hasTop {
+		for i := 0; i < size; i++ {
+			sum += int32(plane[(y-1)*stride+x+i])
+		}
+		count += int32(size)
+	}
+	if hasLeft {
+		for j := 0; j < size; j++ {
+			sum += int32(plane[(y+j)*stride+x-1])
+		}
+		count += int32(size)
+	}
+	if count == 0 {
+		return 128
+	}
+	return (sum + count/2) / count
+}
+
+// forwardDCT 对源像素与预测值之差做 4x4 整数 DCT（与 libwebp 的 FTransform 相同）。
+func forwardDCT(src []uint8, stride, x, y int, pred int32, out *[16]int32) {
+	var tmp [16]int32
+	for i := 0; i < 4; i++ {
+		row := src[(y+i)*stride+x:]
+		d0 := int32(row[0]) - pred
+		d1 := int32(row[1]) - pred
+		d2 := int32(row[2]) - pred
+		d3 := int32(row[3]) - pred
+		a0, a1, a2, a3 := d0+d3, d1+d2, d1-d2, d0-d3
+		tmp[0+i*4] = (a0 + a1) * 8
+		tmp[1+i*4] = (a2*2217 + a3*5352 + 1812) >> 9
+		tmp[2+i*4] = (a0 - a1) * 8
+		tmp[3+i*4] = (a3*2217 - a2*5352 + 937) >> 9
+	}
+	for i := 0; i < 4; i++ {
+		a0 := tmp[0+i] + tmp[12+i]
+		a1 := tmp[4+i] + tmp[8+i]
+		a2 := tmp[4+i] - tmp[8+i]
+		a3 := tmp[0+i] - tmp[12+i]
+		out[0+i] = (a0 + a1 + 7) >> 4
+		out[4+i] = (a2*2217+a3*5352+12000)>>16 + btoi(a3 != 0)
+		out[8+i] = (a0 - a1 + 7) >> 4
+		out[12+i] = (a3*2217 - a2*5352 + 51000) >> 16
+	}
+}
+
+// forwardWHT 对 16 个 DC 系数做 Walsh-Hadamard 变换。
+func forwardWHT(in *[16]int32, out *[16]int32) {
+	var tmp [16]int32
+	for i := 0; i < 4; i++ {
+		a0 := in[i*4+0] + in[i*4+2]
+		a1 := in[i*4+1] + in[i*4+3]
+		a2 := in[i*4+1] - in[i*4+3]
+		a3 := in[i*4+0] - in[i*4+2]
+		tmp[0+i*4] = a0 + a1
+		tmp[1+i*4] = a3 + a2
+		tmp[2+i*4] = a3 - a2
+		tmp[3+i*4] = a0 - a1
+	}
+	for i := 0; i < 4; i++ {
+		a0 := tmp[0+i] + tmp[8+i]
+		a1 := tmp[4+i] + tmp[12+i]
+		a2 := tmp[4+i] - tmp[12+i]
+		a3 := tmp[0+i] - tmp[8+i]
+		out[0+i] = (a0 + a1) >> 1
+		out[4+i] = (a3 + a2) >> 1
+		out[8+i] = (a3 - a2) >> 1
+		out[12+i] = (a0 - a1) >> 1
+	}
+}
+
+// inverseWHT 与解码器的逆变换逐位一致。
+func inverseWHT(in *[16]int32, out *[16]int32) {
+	var m [16]int32
+	for i := 0; i < 4; i++ {
+		a0 := in[0+i] + in[12+i]
+		a1 := in[4+i] + in[8+i]
+		a2 := in[4+i] - in[8+i]
+		a3 := in[0+i] - in[12+i]
+		m[0+i] = a0 + a1
+		m[8+i] = a0 - a1
+		m[4+i] = a3 + a2
+		m[12+i] = a3 - a2
+	}
+	for i := 0; i < 4; i++ {
+		dc := m[0+i*4] + 3
+		a0 := dc + m[3+i*4]
+		a1 := m[1+i*4] + m[2+i*4]
+		a2 := m[1+i*4] - m[2+i*4]
+		a3 := dc - m[3+i*4]
+		out[i*4+0] = (a0 + a1) >> 3
+		out[i*4+1] = (a3 + a2) >> 3
+		out[i*4+2] = (a0 - a1) >> 3
+		out[i*4+3] = (a3 - a2) >> 3
+	}
+}
+
+// inverseDCT 把反量化系数叠加到预测值上写入重建平面，算法与解码器逐位一致。
+func inverseDCT(dst []uint8, stride, x, y int, pred int32, in *[16]int32) {
+	const (
+		c1 = 85627
+		c2 = 35468
+	)
+	var m [4][4]int32
+	for i := 0; i < 4; i++ {
+		a := in[i] + in[8+i]
+		b := in[i] - in[8+i]
+		c := (in[4+i]*c2)>>16 - (in[12+i]*c1)>>16
+		d := (in[4+i]*c1)>>16 + (in[12+i]*c2)>>16
+		m[i][0] = a + d
+		m[i][1] = b + c
+		m[i][2] = b - c
+		m[i][3] = a - d
+	}
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+		d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+		row := dst[(y+j)*stride+x:]
+		row[0] = clip8(pred + (a+d)>>3)
+		row[1] = clip8(pred + (b+c)>>3)
+		row[2] = clip8(pred + (b-c)>>3)
+		row[3] = clip8(pred + (a-d)>>3)
+	}
+}
+
+// maxCoeffLevel 是 DCT_CAT6 能表示的最大量化值。
+const maxCoeffLevel = 2048
+
+// quantizeBlock 量化系数；AC 使用略小于 1/2 的舍入偏移，把接近 0 的系数压成 0 以节省码率。
+func quantizeBlock(in *[16]int32, out *[16]int32, q vp8Quant, first int) {
+	for i := first; i < 16; i++ {
+		step := q[btoi(i > 0)]
+		v := in[i]
+		sign := int32(1)
+		if v < 0 {
+			sign, v = -1, -v
+		}
+		bias := step / 2
+		if i > 0 {
+			bias = step * 3 / 8
+		}
+		level := (v + bias) / step
+		if level > maxCoeffLevel {
+			level = maxCoeffLevel
+		}
+		out[i] = sign * level
+	}
+}
+
+var (
+	vp8Bands   = [17]uint8{0, 1, 2, 3, 6, 4, 5, 6, 6, 6, 6, 6, 6, 6, 6, 7, 0}
+	vp8Zigzag  = [16]uint8{0, 1, 4, 8, 5, 2, 3, 6, 9, 12, 13, 10, 7, 11, 14, 15}
+	vp8Cat3456 = [4][]uint8{
+		{173, 148, 140},
+		{176, 155, 140, 135},
+		{180, 157, 141, 134, 130},
+		{254, 254, 243, 230, 196, 177, 153, 140, 133, 130, 129},
+	}
+)
+
+// writeCoefficients 按 token 树写入一个 4x4 块的量化系数（RFC 6386 第 13 章），
+// 返回该块是否含非零系数，供相邻块作上下文。
+func (e *vp8Encoder) writeCoefficients(q *[16]int32, plane int, ctx uint8, first int) uint8 {
+	probs := &vp8DefaultCoeffProbs[plane]
+	last := -1
+	for n := 15; n >= first; n-- {
+		if q[vp8Zigzag[n]] != 0 {
+			last = n
+			break
+		}
+	}
+
+	n := first
+	p := probs[vp8Bands[n]][ctx]
+	if last < 0 {
+		e.tokens.put(false, p[0])
+		return 0
+	}
+	e.tokens.put(true, p[0])
+	for n < 16 {
+		v := q[vp8Zigzag[n]]
+		n++
+		if v == 0 {
+			e.tokens.put(false, p[1])
+			p = probs[vp8Bands[n]][0]
+			continue
+		}
+		e.tokens.put(true, p[1])
+		sign := v < 0
+		if sign {
+			v = -v
+		}
+		if v == 1 {
+			e.tokens.put(false, p[2])
+			p = probs[vp8Bands[n]][1]
+		} else {
+			e.tokens.put(true, p[2])
+			e.writeLevel(uint32(v), p)
+			p = probs[vp8Bands[n]][2]
+		}
+		e.tokens.put(sign, 128)
+		if n == 16 {
+			break
+		}
+		more := n <= last
+		e.tokens.put(more, p[0])
+		if !more {
+			break
+		}
+	}
+	return 1
+}
+
+// writeLevel 写入大于 1 的系数绝对值。
+func (e *vp8Encoder) writeLevel(v uint32, p [vp8NumProbs]uint8) {
+	switch {
+	case v <= 4:
+		e.tokens.put(false, p[3])
+		if v == 2 {
+			e.tokens.put(false, p[4])
+		} else {
+			e.tokens.put(true, p[4])
+			e.tokens.put(v == 4, p[5])
+		}
+	case v <= 10:
+		e.tokens.put(true, p[3])
+		e.tokens.put(false, p[6])
+		if v <= 6 {
+			e.tokens.put(false, p[7])
+			e.tokens.put(v == 6, 159)
+		} else {
+			e.tokens.put(true, p[7])
+			extra := v - 7
+			e.tokens.put(extra&2 != 0, 165)
+			e.tokens.put(extra&1 != 0, 145)
+		}
+	default:
+		e.tokens.put(true, p[3])
+		e.tokens.put(true, p[6])
+		cat := 3
+		for c := 0; c < 3; c++ {
+			if v < 3+(8<<uint(c+1)) {
+				cat = c
+				break
+			}
+		}
+		e.tokens.put(cat >= 2, p[8])
+		e.tokens.put(cat&1 != 0, p[9+cat/2])
+		extra := v - (3 + 8<<uint(cat))
+		tab := vp8Cat3456[cat]
+		for i := range tab {
+			e.tokens.put(extra>>uint(len(tab)-1-i)&1 != 0, tab[i])
+		}
+	}
+}
+
+// boolEncoder 是 VP8 的布尔算术编码器（RFC 6386 第 7.3 节）。
+type boolEncoder struct {
+	buf      []byte
+	rng      uint32
+	bottom   uint32
+	bitCount int
+}
+
+func newBoolEncoder() *boolEncoder {
+	return &boolEncoder{rng: 255, bitCount: 24}
+}
+
+func (e *boolEncoder) put(bit bool, prob uint8) {
+	split := 1 + ((e.rng-1)*uint32(prob))>>8
+	if bit {
+		e.bottom += split
+		e.rng -= split
+	} else {
+		e.rng = split
+	}
+	for e.rng < 128 {
+		e.rng <<= 1
+		if e.bottom&(1<<31) != 0 {
+			e.carry()
+		}
+		e.bottom <<= 1
+		e.bitCount--
+		if e.bitCount == 0 {
+			e.buf = append(e.buf, byte(e.bottom>>24))
+			e.bottom &= (1 << 24) - 1
+			e.bitCount = 8
+		}
+	}
+}
+
+// putLiteral 以 1/2 概率从高位到低位写入 n 位无符号数。
+func (e *boolEncoder) putLiteral(v uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		e.put(v>>uint(i)&1 != 0, 128)
+	}
+}
+
+func (e *boolEncoder) carry() {
+	for i := len(e.buf) - 1; i >= 0; i-- {
+		if e.buf[i] != 0xff {
+			e.buf[i]++
+			return
+		}
+		e.buf[i] = 0
+	}
+}
+
+func (e *boolEncoder) flush() []byte {
+	c := e.bitCount
+	v := e.bottom
+	if v&(1<<uint(32-c)) != 0 {
+		e.carry()
+	}
+	v <<= uint(c & 7)
+	for c >>= 3; c > 0; c-- {
+		v <<= 8
+	}
+	for i := 0; i < 4; i++ {
+		e.buf = append(e.buf, byte(v>>24))
+		v <<= 8
+	}
+	return e.buf
+}
+
+func clip8(v int32) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+func btoi(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,146 @@
+package persistence
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+// ActivityPubFollowerRepository stores remote followers of the local actor.
+type ActivityPubFollowerRepository struct {
+	db   *gorm.DB
+	repo *GormRepository[model.ActivityPubFollower]
+}
+
+func NewActivityPubFollowerRepository(db *gorm.DB) *ActivityPubFollowerRepository {
+	return &ActivityPubFollowerRepository{
+		db:   db,
+		repo: NewGormRepository[model.ActivityPubFollower](db),
+	}
+}
+
+func (r *ActivityPubFollowerRepository) Upsert(ctx context.Context, follower *federation.ActivityPubFollower) error {
+	rec := model.ActivityPubFollower{
+		ActorID:          follower.ActorID,
+		Inbox:            follower.Inbox,
+		SharedInbox:      follower.SharedInbox,
+		Username:         follower.Username,
+		DisplayName:      follower.DisplayName,
+		Avatar:           follower.Avatar,
+		FollowActivityID: follower.FollowActivityID,
+	}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "actor_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"inbox", "shared_inbox", "username", "display_name", "avatar", "follow_activity_id", "updated_at"}),
+	}).Create(&rec).Error; err != nil {
+		return err
+	}
+	*follower = mapActivityPubFollowerToDomain(rec)
+	return nil
+}
+
+func (r *ActivityPubFollowerRepository) DeleteByActorID(ctx context.Context, actorID string) error {
+	affected, err := r.repo.DeleteWhere(ctx, "actor_id = ?", actorID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return federation.ErrFollowerNotFound
+	}
+	return nil
+}
+
+func (r *ActivityPubFollowerRepository) ListAll(ctx context.Context) ([]federation.ActivityPubFollower, error) {
+	recs, err := r.repo.List(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]federation.ActivityPubFollower, len(recs))
+	for i, rec := range recs {
+		result[i] = mapActivityPubFollowerToDomain(rec)
+	}
+	return result, nil
+}
+
+func (r *ActivityPubFollowerRepository) Count(ctx context.Context) (int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&model.ActivityPubFollower{}).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func mapActivityPubFollowerToDomain(rec model.ActivityPubFollower) federation.ActivityPubFollower {
+	return federation.ActivityPubFollower{
+		ID:               rec.ID,
+		ActorID:          rec.ActorID,
+		Inbox:            rec.Inbox,
+		SharedInbox:      rec.SharedInbox,
+		Username:         rec.Username,
+		DisplayName:      rec.DisplayName,
+		Avatar:           rec.Avatar,
+		FollowActivityID: rec.FollowActivityID,
+		CreatedAt:        rec.CreatedAt,
+		UpdatedAt:        rec.UpdatedAt,
+	}
+}
+
+// ActivityPubInteractionRepository stores likes and boosts of local content.
+type ActivityPubInteractionRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityPubInteractionRepository(db *gorm.DB) *ActivityPubInteractionRepository {
+	return &ActivityPubInteractionRepository{db: db}
+}
+
+func (r *ActivityPubInteractionRepository) Create(ctx context.Context, interaction *federation.ActivityPubInteraction) error {
+	rec := model.ActivityPubInteraction{
+		ActivityID: interaction.ActivityID,
+		Kind:       interaction.Kind,
+		ActorID:    interaction.ActorID,
+		ObjectType: interaction.ObjectType,
+		ObjectID:   interaction.ObjectID,
+	}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "activity_id"}},
+		DoNothing: true,
+	}).Create(&rec).Error; err != nil {
+		return err
+	}
+	interaction.ID = rec.ID
+	interaction.CreatedAt = rec.CreatedAt
+	return nil
+}
+
+func (r *ActivityPubInteractionRepository) DeleteByActivityID(ctx context.Context, activityID string, actorID string) error {
+	return r.db.WithContext(ctx).
+		Where("activity_id = ? AND actor_id = ?", activityID, actorID).
+		Delete(&model.ActivityPubInteraction{}).Error
+}
+
+func (r *ActivityPubInteractionRepository) CountByObject(ctx context.Context, objectType string, objectID int64) (map[string]int64, error) {
+	var rows []struct {
+		Kind  string
+		Total int64
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&model.ActivityPubInteraction{}).
+		Select("kind, COUNT(*) AS total").
+		Where("object_type = ? AND object_id = ?", objectType, objectID).
+		Group("kind").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		result[row.Kind] = row.Total
+	}
+	return result, nil
+}
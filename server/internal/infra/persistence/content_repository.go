@@ -774,6 +774,75 @@ func (r *ContentRepository) ListPublicArticlesForFederation(ctx context.Context,
 	return articles, total, nil
 }
 
+// ListPublicTimeline 合并公开文章与手记，按创建时间倒序统一分页。
+func (r *ContentRepository) ListPublicTimeline(ctx context.Context, page int, pageSize int) ([]content.TimelineEntry, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	const timeline = `SELECT 'article' AS kind, id, created_at FROM article WHERE is_published = TRUE AND deleted_at IS NULL
+UNION ALL
+SELECT 'moment' AS kind, id, created_at FROM moment WHERE is_published = TRUE AND deleted_at IS NULL`
+
+	db := r.db.WithContext(ctx)
+	var total int64
+	if err := db.Raw("SELECT COUNT(*) FROM (" + timeline + ") AS timeline").Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var refs []struct {
+		Kind string
+		ID   int64
+	}
+	if err := db.Raw("SELECT kind, id FROM ("+timeline+") AS timeline ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?",
+		pageSize, (page-1)*pageSize).Scan(&refs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var articleIDs, momentIDs []int64
+	for _, ref := range refs {
+		if ref.Kind == "article" {
+			articleIDs = append(articleIDs, ref.ID)
+		} else {
+			momentIDs = append(momentIDs, ref.ID)
+		}
+	}
+	articles := make(map[int64]*content.Article, len(articleIDs))
+	if len(articleIDs) > 0 {
+		var models []*model.Article
+		if err := db.Where("id IN ?", articleIDs).Find(&models).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, am := range models {
+			articles[am.ID] = r.modelToArticle(am)
+		}
+	}
+	moments := make(map[int64]*content.Moment, len(momentIDs))
+	if len(momentIDs) > 0 {
+		var models []*model.Moment
+		if err := db.Where("id IN ?", momentIDs).Find(&models).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, mm := range models {
+			moments[mm.ID] = r.modelToMoment(mm)
+		}
+	}
+
+	entries := make([]content.TimelineEntry, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Kind == "article" {
+			if article, ok := articles[ref.ID]; ok {
+				entries = append(entries, content.TimelineEntry{Article: article})
+			}
+		} else if moment, ok := moments[ref.ID]; ok {
+			entries = append(entries, content.TimelineEntry{Moment: moment})
+		}
+	}
+	return entries, total, nil
+}
+
 // CreateMoment 创建手记
 func (r *ContentRepository) CreateMoment(ctx context.Context, moment *content.Moment) error {
 	tocBytes, err := tocToBytes(moment.TOC)
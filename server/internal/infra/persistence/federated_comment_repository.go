@@ -0,0 +1,130 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+// FederatedCommentRepository stores comments delivered by remote instances.
+type FederatedCommentRepository struct {
+	db   *gorm.DB
+	repo *GormRepository[model.FederatedComment]
+}
+
+func NewFederatedCommentRepository(db *gorm.DB) *FederatedCommentRepository {
+	return &FederatedCommentRepository{
+		db:   db,
+		repo: NewGormRepository[model.FederatedComment](db),
+	}
+}
+
+func (r *FederatedCommentRepository) Create(ctx context.Context, item *federation.FederatedComment) error {
+	rec := mapFederatedCommentToModel(item)
+	if err := r.repo.Create(ctx, &rec); err != nil {
+		return err
+	}
+	*item = mapFederatedCommentToDomain(rec)
+	return nil
+}
+
+func (r *FederatedCommentRepository) GetByID(ctx context.Context, id int64) (*federation.FederatedComment, error) {
+	rec, err := r.repo.FirstByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, federation.ErrFederatedCommentNotFound
+		}
+		return nil, err
+	}
+	item := mapFederatedCommentToDomain(*rec)
+	return &item, nil
+}
+
+func (r *FederatedCommentRepository) FindByRemoteID(ctx context.Context, instanceID int64, remoteID string) (*federation.FederatedComment, error) {
+	rec, err := r.repo.First(ctx, "source_instance_id = ? AND remote_comment_id = ?", instanceID, remoteID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, federation.ErrFederatedCommentNotFound
+		}
+		return nil, err
+	}
+	item := mapFederatedCommentToDomain(*rec)
+	return &item, nil
+}
+
+func (r *FederatedCommentRepository) Update(ctx context.Context, item *federation.FederatedComment) error {
+	rec := mapFederatedCommentToModel(item)
+	return r.db.WithContext(ctx).Save(&rec).Error
+}
+
+func (r *FederatedCommentRepository) List(ctx context.Context, options federation.FederatedCommentListOptions) ([]federation.FederatedComment, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.FederatedComment{})
+	if options.Status != nil && *options.Status != "" {
+		query = query.Where("status = ?", *options.Status)
+	}
+	if options.TargetArticleID != nil {
+		query = query.Where("target_article_id = ?", *options.TargetArticleID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (options.Page - 1) * options.PageSize
+	var recs []model.FederatedComment
+	if err := query.Order("created_at DESC").
+		Order("id DESC").
+		Offset(offset).
+		Limit(options.PageSize).
+		Find(&recs).Error; err != nil {
+		return nil, 0, err
+	}
+	result := make([]federation.FederatedComment, len(recs))
+	for i, rec := range recs {
+		result[i] = mapFederatedCommentToDomain(rec)
+	}
+	return result, total, nil
+}
+
+func mapFederatedCommentToModel(item *federation.FederatedComment) model.FederatedComment {
+	return model.FederatedComment{
+		ID:               item.ID,
+		SourceInstanceID: item.SourceInstanceID,
+		RemoteCommentID:  item.RemoteCommentID,
+		TargetArticleID:  item.TargetArticleID,
+		AuthorName:       item.AuthorName,
+		AuthorURL:        item.AuthorURL,
+		AuthorAvatar:     item.AuthorAvatar,
+		Content:          item.Content,
+		Status:           item.Status,
+		CommentID:        item.CommentID,
+		RejectReason:     item.RejectReason,
+		ReviewedAt:       item.ReviewedAt,
+		CreatedAt:        item.CreatedAt,
+		UpdatedAt:        item.UpdatedAt,
+	}
+}
+
+func mapFederatedCommentToDomain(rec model.FederatedComment) federation.FederatedComment {
+	return federation.FederatedComment{
+		ID:               rec.ID,
+		SourceInstanceID: rec.SourceInstanceID,
+		RemoteCommentID:  rec.RemoteCommentID,
+		TargetArticleID:  rec.TargetArticleID,
+		AuthorName:       rec.AuthorName,
+		AuthorURL:        rec.AuthorURL,
+		AuthorAvatar:     rec.AuthorAvatar,
+		Content:          rec.Content,
+		Status:           rec.Status,
+		CommentID:        rec.CommentID,
+		RejectReason:     rec.RejectReason,
+		ReviewedAt:       rec.ReviewedAt,
+		CreatedAt:        rec.CreatedAt,
+		UpdatedAt:        rec.UpdatedAt,
+	}
+}
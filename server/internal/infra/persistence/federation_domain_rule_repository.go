@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+// FederationDomainRuleRepository stores domain-level block/allow rules.
+type FederationDomainRuleRepository struct {
+	db   *gorm.DB
+	repo *GormRepository[model.FederationDomainRule]
+}
+
+func NewFederationDomainRuleRepository(db *gorm.DB) *FederationDomainRuleRepository {
+	return &FederationDomainRuleRepository{
+		db:   db,
+		repo: NewGormRepository[model.FederationDomainRule](db),
+	}
+}
+
+func (r *FederationDomainRuleRepository) List(ctx context.Context) ([]federation.FederationDomainRule, error) {
+	recs, err := r.repo.List(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Order("action").Order("pattern")
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]federation.FederationDomainRule, len(recs))
+	for i, rec := range recs {
+		result[i] = mapFederationDomainRuleToDomain(rec)
+	}
+	return result, nil
+}
+
+func (r *FederationDomainRuleRepository) Create(ctx context.Context, rule *federation.FederationDomainRule) error {
+	rec := model.FederationDomainRule{
+		Pattern: rule.Pattern,
+		Action:  rule.Action,
+		Reason:  rule.Reason,
+	}
+	if err := r.repo.Create(ctx, &rec); err != nil {
+		return err
+	}
+	*rule = mapFederationDomainRuleToDomain(rec)
+	return nil
+}
+
+func (r *FederationDomainRuleRepository) Delete(ctx context.Context, id int64) error {
+	affected, err := r.repo.DeleteWhere(ctx, "id = ?", id)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return federation.ErrDomainRuleNotFound
+	}
+	return nil
+}
+
+func mapFederationDomainRuleToDomain(rec model.FederationDomainRule) federation.FederationDomainRule {
+	return federation.FederationDomainRule{
+		ID:        rec.ID,
+		Pattern:   rec.Pattern,
+		Action:    rec.Action,
+		Reason:    rec.Reason,
+		CreatedAt: rec.CreatedAt,
+		UpdatedAt: rec.UpdatedAt,
+	}
+}
@@ -0,0 +1,132 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+// OutboundFriendLinkRepository stores friend-link applications sent to remote instances.
+type OutboundFriendLinkRepository struct {
+	db   *gorm.DB
+	repo *GormRepository[model.FederationOutboundFriendLink]
+}
+
+func NewOutboundFriendLinkRepository(db *gorm.DB) *OutboundFriendLinkRepository {
+	return &OutboundFriendLinkRepository{
+		db:   db,
+		repo: NewGormRepository[model.FederationOutboundFriendLink](db),
+	}
+}
+
+func (r *OutboundFriendLinkRepository) Create(ctx context.Context, item *federation.OutboundFriendLinkRequest) error {
+	rec := mapOutboundFriendLinkToModel(item)
+	if err := r.repo.Create(ctx, &rec); err != nil {
+		return err
+	}
+	*item = mapOutboundFriendLinkToDomain(rec)
+	return nil
+}
+
+func (r *OutboundFriendLinkRepository) GetByID(ctx context.Context, id int64) (*federation.OutboundFriendLinkRequest, error) {
+	rec, err := r.repo.FirstByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, federation.ErrOutboundFriendLinkNotFound
+		}
+		return nil, err
+	}
+	item := mapOutboundFriendLinkToDomain(*rec)
+	return &item, nil
+}
+
+func (r *OutboundFriendLinkRepository) FindOpenByTargetURL(ctx context.Context, targetURL string) (*federation.OutboundFriendLinkRequest, error) {
+	var rec model.FederationOutboundFriendLink
+	err := r.db.WithContext(ctx).
+		Where("target_url = ? AND status IN ?", targetURL, []string{"pending", "sent"}).
+		Order("created_at DESC").
+		Order("id DESC").
+		First(&rec).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, federation.ErrOutboundFriendLinkNotFound
+		}
+		return nil, err
+	}
+	item := mapOutboundFriendLinkToDomain(rec)
+	return &item, nil
+}
+
+func (r *OutboundFriendLinkRepository) Update(ctx context.Context, item *federation.OutboundFriendLinkRequest) error {
+	rec := mapOutboundFriendLinkToModel(item)
+	return r.db.WithContext(ctx).Save(&rec).Error
+}
+
+func (r *OutboundFriendLinkRepository) List(ctx context.Context, options federation.OutboundFriendLinkListOptions) ([]federation.OutboundFriendLinkRequest, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.FederationOutboundFriendLink{})
+	if options.Status != nil && *options.Status != "" {
+		query = query.Where("status = ?", *options.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (options.Page - 1) * options.PageSize
+	var recs []model.FederationOutboundFriendLink
+	if err := query.Order("created_at DESC").
+		Order("id DESC").
+		Offset(offset).
+		Limit(options.PageSize).
+		Find(&recs).Error; err != nil {
+		return nil, 0, err
+	}
+	result := make([]federation.OutboundFriendLinkRequest, len(recs))
+	for i, rec := range recs {
+		result[i] = mapOutboundFriendLinkToDomain(rec)
+	}
+	return result, total, nil
+}
+
+func mapOutboundFriendLinkToModel(item *federation.OutboundFriendLinkRequest) model.FederationOutboundFriendLink {
+	return model.FederationOutboundFriendLink{
+		ID:                  item.ID,
+		TargetURL:           item.TargetURL,
+		InstanceID:          item.InstanceID,
+		Message:             item.Message,
+		RSSURL:              item.RSSURL,
+		Status:              item.Status,
+		ResponseStatus:      item.ResponseStatus,
+		ResponseBody:        item.ResponseBody,
+		RemoteApplicationID: item.RemoteApplicationID,
+		RejectReason:        item.RejectReason,
+		FriendLinkID:        item.FriendLinkID,
+		DecidedAt:           item.DecidedAt,
+		CreatedAt:           item.CreatedAt,
+		UpdatedAt:           item.UpdatedAt,
+	}
+}
+
+func mapOutboundFriendLinkToDomain(rec model.FederationOutboundFriendLink) federation.OutboundFriendLinkRequest {
+	return federation.OutboundFriendLinkRequest{
+		ID:                  rec.ID,
+		TargetURL:           rec.TargetURL,
+		InstanceID:          rec.InstanceID,
+		Message:             rec.Message,
+		RSSURL:              rec.RSSURL,
+		Status:              rec.Status,
+		ResponseStatus:      rec.ResponseStatus,
+		ResponseBody:        rec.ResponseBody,
+		RemoteApplicationID: rec.RemoteApplicationID,
+		RejectReason:        rec.RejectReason,
+		FriendLinkID:        rec.FriendLinkID,
+		DecidedAt:           rec.DecidedAt,
+		CreatedAt:           rec.CreatedAt,
+		UpdatedAt:           rec.UpdatedAt,
+	}
+}
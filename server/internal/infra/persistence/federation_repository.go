@@ -27,6 +27,18 @@ func NewFederationInstanceRepository(db *gorm.DB) *FederationInstanceRepository
 	}
 }
 
+func (r *FederationInstanceRepository) GetByID(ctx context.Context, id int64) (*federation.FederationInstance, error) {
+	rec, err := r.repo.FirstByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, federation.ErrFederationInstanceNotFound
+		}
+		return nil, err
+	}
+	instance := mapFederationInstanceToDomain(*rec)
+	return &instance, nil
+}
+
 func (r *FederationInstanceRepository) GetByBaseURL(ctx context.Context, baseURL string) (*federation.FederationInstance, error) {
 	rec, err := r.repo.First(ctx, "base_url = ?", baseURL)
 	if err != nil {
@@ -41,6 +53,9 @@ func (r *FederationInstanceRepository) GetByBaseURL(ctx context.Context, baseURL
 
 func (r *FederationInstanceRepository) Create(ctx context.Context, instance *federation.FederationInstance) error {
 	rec := mapFederationInstanceToModel(instance)
+	if len(rec.Manifest) == 0 {
+		rec.Manifest = datatypes.JSON("{}")
+	}
 	if err := r.repo.Create(ctx, &rec); err != nil {
 		return err
 	}
@@ -57,6 +72,62 @@ func (r *FederationInstanceRepository) Update(ctx context.Context, instance *fed
 		Updates(&rec).Error
 }
 
+// UpdateStatus 单独更新状态，便于清空屏蔽原因。
+func (r *FederationInstanceRepository) UpdateStatus(ctx context.Context, id int64, status string, blockReason *string) error {
+	result := r.db.WithContext(ctx).Model(&model.FederationInstance{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":       status,
+			"block_reason": blockReason,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return federation.ErrFederationInstanceNotFound
+	}
+	return nil
+}
+
+// RecordRateLimitViolation 累加实例的限流次数，实例尚未入库时忽略。
+func (r *FederationInstanceRepository) RecordRateLimitViolation(ctx context.Context, baseURL string, at time.Time) error {
+	return r.db.WithContext(ctx).Exec(
+		"UPDATE federation_instance SET rate_limit_violations = rate_limit_violations + 1, last_rate_limited_at = ? WHERE base_url = ?",
+		at, baseURL,
+	).Error
+}
+
+func (r *FederationInstanceRepository) List(ctx context.Context, options federation.InstanceListOptions) ([]federation.FederationInstance, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.FederationInstance{})
+	if options.Status != nil && *options.Status != "" {
+		query = query.Where("status = ?", *options.Status)
+	}
+	if options.Search != nil && *options.Search != "" {
+		keyword := "%" + *options.Search + "%"
+		query = query.Where("base_url ILIKE ? OR name ILIKE ?", keyword, keyword)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (options.Page - 1) * options.PageSize
+	var recs []model.FederationInstance
+	if err := query.Order("last_seen_at DESC NULLS LAST").
+		Order("id DESC").
+		Offset(offset).
+		Limit(options.PageSize).
+		Find(&recs).Error; err != nil {
+		return nil, 0, err
+	}
+	result := make([]federation.FederationInstance, len(recs))
+	for i, rec := range recs {
+		result[i] = mapFederationInstanceToDomain(rec)
+	}
+	return result, total, nil
+}
+
 func (r *FederationInstanceRepository) ListActive(ctx context.Context) ([]federation.FederationInstance, error) {
 	recs, err := r.repo.List(ctx, func(db *gorm.DB) *gorm.DB {
 		return db.Where("status = ?", "active").Order("updated_at DESC")
@@ -114,6 +185,38 @@ func (r *FederatedPostCacheRepository) ListByInstance(ctx context.Context, insta
 	return result, nil
 }
 
+func (r *FederatedPostCacheRepository) GetByRemoteID(ctx context.Context, instanceID int64, remotePostID string) (*federation.FederatedPostCache, error) {
+	var rec model.FederatedPostCache
+	if err := r.db.WithContext(ctx).
+		Where("instance_id = ? AND remote_post_id = ?", instanceID, remotePostID).
+		First(&rec).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, federation.ErrFederatedPostNotFound
+		}
+		return nil, err
+	}
+	post := mapFederatedPostCacheToDomain(rec)
+	return &post, nil
+}
+
+func (r *FederatedPostCacheRepository) ListStale(ctx context.Context, before time.Time, limit int) ([]federation.FederatedPostCache, error) {
+	query := r.db.WithContext(ctx).
+		Where("remote_post_id IS NOT NULL AND (cached_at IS NULL OR cached_at < ?)", before).
+		Order("cached_at ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var recs []model.FederatedPostCache
+	if err := query.Find(&recs).Error; err != nil {
+		return nil, err
+	}
+	result := make([]federation.FederatedPostCache, len(recs))
+	for i, rec := range recs {
+		result[i] = mapFederatedPostCacheToDomain(rec)
+	}
+	return result, nil
+}
+
 func (r *FederatedPostCacheRepository) ListRecent(ctx context.Context, limit int) ([]federation.FederatedPostCache, error) {
 	query := r.db.WithContext(ctx)
 	if limit > 0 {
@@ -230,20 +333,24 @@ func (r *FederatedMentionRepository) ListByUser(ctx context.Context, userID int6
 
 func mapFederationInstanceToDomain(rec model.FederationInstance) federation.FederationInstance {
 	return federation.FederationInstance{
-		ID:              rec.ID,
-		BaseURL:         rec.BaseURL,
-		Name:            rec.Name,
-		Description:     rec.Description,
-		ProtocolVersion: rec.ProtocolVersion,
-		PublicKey:       rec.PublicKey,
-		KeyID:           rec.KeyID,
-		Features:        json.RawMessage(rec.Features),
-		Policies:        json.RawMessage(rec.Policies),
-		Endpoints:       json.RawMessage(rec.Endpoints),
-		Status:          rec.Status,
-		LastSeenAt:      rec.LastSeenAt,
-		CreatedAt:       rec.CreatedAt,
-		UpdatedAt:       rec.UpdatedAt,
+		ID:                  rec.ID,
+		BaseURL:             rec.BaseURL,
+		Name:                rec.Name,
+		Description:         rec.Description,
+		ProtocolVersion:     rec.ProtocolVersion,
+		PublicKey:           rec.PublicKey,
+		KeyID:               rec.KeyID,
+		Features:            json.RawMessage(rec.Features),
+		Policies:            json.RawMessage(rec.Policies),
+		Endpoints:           json.RawMessage(rec.Endpoints),
+		Manifest:            json.RawMessage(rec.Manifest),
+		Status:              rec.Status,
+		BlockReason:         rec.BlockReason,
+		RateLimitViolations: rec.RateLimitViolations,
+		LastRateLimitedAt:   rec.LastRateLimitedAt,
+		LastSeenAt:          rec.LastSeenAt,
+		CreatedAt:           rec.CreatedAt,
+		UpdatedAt:           rec.UpdatedAt,
 	}
 }
 
@@ -259,7 +366,9 @@ func mapFederationInstanceToModel(instance *federation.FederationInstance) model
 		Features:        datatypes.JSON(instance.Features),
 		Policies:        datatypes.JSON(instance.Policies),
 		Endpoints:       datatypes.JSON(instance.Endpoints),
+		Manifest:        datatypes.JSON(instance.Manifest),
 		Status:          instance.Status,
+		BlockReason:     instance.BlockReason,
 		LastSeenAt:      instance.LastSeenAt,
 	}
 }
@@ -0,0 +1,104 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+// FederationSigningKeyRepository stores local signing keys across rotations.
+type FederationSigningKeyRepository struct {
+	db   *gorm.DB
+	repo *GormRepository[model.FederationSigningKey]
+}
+
+func NewFederationSigningKeyRepository(db *gorm.DB) *FederationSigningKeyRepository {
+	return &FederationSigningKeyRepository{
+		db:   db,
+		repo: NewGormRepository[model.FederationSigningKey](db),
+	}
+}
+
+func (r *FederationSigningKeyRepository) ListValid(ctx context.Context, at time.Time) ([]federation.FederationSigningKey, error) {
+	recs, err := r.repo.List(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("expires_at IS NULL OR expires_at > ?", at).
+			Order("created_at DESC").
+			Order("id DESC")
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]federation.FederationSigningKey, len(recs))
+	for i, rec := range recs {
+		result[i] = mapFederationSigningKeyToDomain(rec)
+	}
+	return result, nil
+}
+
+func (r *FederationSigningKeyRepository) FindByPublicKey(ctx context.Context, publicKey string) (*federation.FederationSigningKey, error) {
+	rec, err := r.repo.First(ctx, "public_key = ?", publicKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, federation.ErrSigningKeyNotFound
+		}
+		return nil, err
+	}
+	key := mapFederationSigningKeyToDomain(*rec)
+	return &key, nil
+}
+
+func (r *FederationSigningKeyRepository) Create(ctx context.Context, key *federation.FederationSigningKey) error {
+	rec := model.FederationSigningKey{
+		KeyRef:     key.KeyRef,
+		Algorithm:  key.Algorithm,
+		PublicKey:  key.PublicKey,
+		PrivateKey: key.PrivateKey,
+		ExpiresAt:  key.ExpiresAt,
+	}
+	if err := r.repo.Create(ctx, &rec); err != nil {
+		return err
+	}
+	*key = mapFederationSigningKeyToDomain(rec)
+	return nil
+}
+
+func (r *FederationSigningKeyRepository) Rotate(ctx context.Context, key *federation.FederationSigningKey, expiresAt time.Time) error {
+	rec := model.FederationSigningKey{
+		KeyRef:     key.KeyRef,
+		Algorithm:  key.Algorithm,
+		PublicKey:  key.PublicKey,
+		PrivateKey: key.PrivateKey,
+		ExpiresAt:  key.ExpiresAt,
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.FederationSigningKey{}).
+			Where("algorithm = ?", key.Algorithm).
+			Where("expires_at IS NULL OR expires_at > ?", expiresAt).
+			Update("expires_at", expiresAt).Error; err != nil {
+			return err
+		}
+		return tx.Create(&rec).Error
+	})
+	if err != nil {
+		return err
+	}
+	*key = mapFederationSigningKeyToDomain(rec)
+	return nil
+}
+
+func mapFederationSigningKeyToDomain(rec model.FederationSigningKey) federation.FederationSigningKey {
+	return federation.FederationSigningKey{
+		ID:         rec.ID,
+		KeyRef:     rec.KeyRef,
+		Algorithm:  rec.Algorithm,
+		PublicKey:  rec.PublicKey,
+		PrivateKey: rec.PrivateKey,
+		CreatedAt:  rec.CreatedAt,
+		ExpiresAt:  rec.ExpiresAt,
+	}
+}
@@ -0,0 +1,115 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+type FriendLinkHealthRepository struct {
+	db *gorm.DB
+}
+
+func NewFriendLinkHealthRepository(db *gorm.DB) *FriendLinkHealthRepository {
+	return &FriendLinkHealthRepository{db: db}
+}
+
+func (r *FriendLinkHealthRepository) GetByLinkID(ctx context.Context, friendLinkID int64) (*social.FriendLinkHealth, error) {
+	var rec model.FriendLinkHealth
+	if err := r.db.WithContext(ctx).Where("friend_link_id = ?", friendLinkID).First(&rec).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, social.ErrFriendLinkHealthNotFound
+		}
+		return nil, err
+	}
+	entity := mapFriendLinkHealthToDomain(rec)
+	return &entity, nil
+}
+
+func (r *FriendLinkHealthRepository) Upsert(ctx context.Context, health *social.FriendLinkHealth) error {
+	rec := mapFriendLinkHealthToModel(health)
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "friend_link_id"}},
+		UpdateAll: true,
+	}).Create(&rec).Error
+}
+
+func (r *FriendLinkHealthRepository) List(ctx context.Context, options social.FriendLinkHealthListOptions) ([]*social.FriendLinkHealth, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.FriendLinkHealth{}).
+		Joins("JOIN friend_link ON friend_link.id = friend_link_health.friend_link_id AND friend_link.deleted_at IS NULL")
+	if options.IsHealthy != nil {
+		query = query.Where("friend_link_health.is_healthy = ?", *options.IsHealthy)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (options.Page - 1) * options.PageSize
+	var recs []model.FriendLinkHealth
+	if err := query.Select("friend_link_health.*").
+		Order("friend_link_health.is_healthy ASC, friend_link_health.consecutive_failures DESC, friend_link_health.friend_link_id ASC").
+		Offset(offset).
+		Limit(options.PageSize).
+		Find(&recs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]*social.FriendLinkHealth, len(recs))
+	for i, rec := range recs {
+		entity := mapFriendLinkHealthToDomain(rec)
+		result[i] = &entity
+	}
+	return result, total, nil
+}
+
+func (r *FriendLinkHealthRepository) ListAutoDeactivatedLinkIDs(ctx context.Context) ([]int64, error) {
+	var ids []int64
+	if err := r.db.WithContext(ctx).Model(&model.FriendLinkHealth{}).
+		Where("auto_deactivated = ?", true).
+		Order("friend_link_id ASC").
+		Pluck("friend_link_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func mapFriendLinkHealthToDomain(rec model.FriendLinkHealth) social.FriendLinkHealth {
+	return social.FriendLinkHealth{
+		FriendLinkID:        rec.FriendLinkID,
+		StatusCode:          rec.StatusCode,
+		LatencyMs:           rec.LatencyMs,
+		TLSExpiresAt:        rec.TLSExpiresAt,
+		HasBacklink:         rec.HasBacklink,
+		RSSStatusCode:       rec.RSSStatusCode,
+		ErrorMessage:        rec.ErrorMessage,
+		IsHealthy:           rec.IsHealthy,
+		ConsecutiveFailures: rec.ConsecutiveFailures,
+		LastSuccessAt:       rec.LastSuccessAt,
+		AutoDeactivated:     rec.AutoDeactivated,
+		CheckedAt:           rec.CheckedAt,
+	}
+}
+
+func mapFriendLinkHealthToModel(health *social.FriendLinkHealth) model.FriendLinkHealth {
+	return model.FriendLinkHealth{
+		FriendLinkID:        health.FriendLinkID,
+		StatusCode:          health.StatusCode,
+		LatencyMs:           health.LatencyMs,
+		TLSExpiresAt:        health.TLSExpiresAt,
+		HasBacklink:         health.HasBacklink,
+		RSSStatusCode:       health.RSSStatusCode,
+		ErrorMessage:        health.ErrorMessage,
+		IsHealthy:           health.IsHealthy,
+		ConsecutiveFailures: health.ConsecutiveFailures,
+		LastSuccessAt:       health.LastSuccessAt,
+		AutoDeactivated:     health.AutoDeactivated,
+		CheckedAt:           health.CheckedAt,
+	}
+}
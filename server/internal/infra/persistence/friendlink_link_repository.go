@@ -22,6 +22,18 @@ func NewFriendLinkRepository(db *gorm.DB) *FriendLinkRepository {
 	}
 }
 
+func (r *FriendLinkRepository) FindByID(ctx context.Context, id int64) (*social.FriendLink, error) {
+	rec, err := r.repo.FirstByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, social.ErrFriendLinkNotFound
+		}
+		return nil, err
+	}
+	entity := mapFriendLinkToDomain(*rec)
+	return &entity, nil
+}
+
 func (r *FriendLinkRepository) FindByURL(ctx context.Context, url string) (*social.FriendLink, error) {
 	rec, err := r.repo.First(ctx, "url = ?", url)
 	if err != nil {
@@ -45,6 +57,149 @@ func (r *FriendLinkRepository) Create(ctx context.Context, link *social.FriendLi
 	return nil
 }
 
+func (r *FriendLinkRepository) FindByIDs(ctx context.Context, ids []int64) ([]*social.FriendLink, error) {
+	if len(ids) == 0 {
+		return []*social.FriendLink{}, nil
+	}
+	var recs []model.FriendLink
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&recs).Error; err != nil {
+		return nil, err
+	}
+	return mapFriendLinksToDomain(recs), nil
+}
+
+func (r *FriendLinkRepository) List(ctx context.Context, options social.FriendLinkListOptions) ([]*social.FriendLink, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.FriendLink{})
+	if options.Kind != nil && *options.Kind != "" {
+		query = query.Where("kind = ?", *options.Kind)
+	}
+	if options.IsActive != nil {
+		query = query.Where("is_active = ?", *options.IsActive)
+	}
+	if options.Search != nil && *options.Search != "" {
+		keyword := "%" + *options.Search + "%"
+		query = query.Where("name ILIKE ? OR url ILIKE ?", keyword, keyword)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (options.Page - 1) * options.PageSize
+	var recs []model.FriendLink
+	if err := query.Order("id DESC").
+		Offset(offset).
+		Limit(options.PageSize).
+		Find(&recs).Error; err != nil {
+		return nil, 0, err
+	}
+	return mapFriendLinksToDomain(recs), total, nil
+}
+
+func (r *FriendLinkRepository) Update(ctx context.Context, link *social.FriendLink) error {
+	rec := mapFriendLinkToModel(link)
+	result := r.db.WithContext(ctx).Model(&model.FriendLink{}).
+		Where("id = ?", link.ID).
+		Updates(map[string]any{
+			"name":               rec.Name,
+			"url":                rec.URL,
+			"logo":               rec.Logo,
+			"description":        rec.Description,
+			"rss_url":            rec.RSSURL,
+			"kind":               rec.Kind,
+			"sync_mode":          rec.SyncMode,
+			"instance_id":        rec.InstanceID,
+			"sync_interval":      rec.SyncInterval,
+			"feed_etag":          rec.FeedETag,
+			"feed_last_modified": rec.FeedLastModified,
+			"user_id":            rec.UserID,
+			"is_active":          rec.IsActive,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return social.ErrFriendLinkNotFound
+	}
+	return nil
+}
+
+func (r *FriendLinkRepository) Delete(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.FriendLink{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return social.ErrFriendLinkNotFound
+	}
+	return nil
+}
+
+func (r *FriendLinkRepository) ListActive(ctx context.Context) ([]*social.FriendLink, error) {
+	var recs []model.FriendLink
+	if err := r.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Order("id ASC").
+		Find(&recs).Error; err != nil {
+		return nil, err
+	}
+	return mapFriendLinksToDomain(recs), nil
+}
+
+func (r *FriendLinkRepository) ListSyncable(ctx context.Context) ([]*social.FriendLink, error) {
+	var recs []model.FriendLink
+	if err := r.db.WithContext(ctx).
+		Where("is_active = ? AND sync_mode = ? AND rss_url IS NOT NULL AND rss_url <> ''", true, "rss").
+		Order("id ASC").
+		Find(&recs).Error; err != nil {
+		return nil, err
+	}
+	return mapFriendLinksToDomain(recs), nil
+}
+
+func (r *FriendLinkRepository) UpdateSyncState(ctx context.Context, id int64, state social.FriendLinkSyncState) error {
+	result := r.db.WithContext(ctx).Model(&model.FriendLink{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"last_sync_at":       state.LastSyncAt,
+			"last_sync_status":   state.LastSyncStatus,
+			"last_sync_error":    state.LastSyncError,
+			"total_posts_cached": state.TotalPostsCached,
+			"feed_etag":          state.FeedETag,
+			"feed_last_modified": state.FeedLastModified,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return social.ErrFriendLinkNotFound
+	}
+	return nil
+}
+
+func (r *FriendLinkRepository) SetActive(ctx context.Context, id int64, active bool) error {
+	result := r.db.WithContext(ctx).Model(&model.FriendLink{}).
+		Where("id = ?", id).
+		Update("is_active", active)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return social.ErrFriendLinkNotFound
+	}
+	return nil
+}
+
+func mapFriendLinksToDomain(recs []model.FriendLink) []*social.FriendLink {
+	result := make([]*social.FriendLink, len(recs))
+	for i, rec := range recs {
+		entity := mapFriendLinkToDomain(rec)
+		result[i] = &entity
+	}
+	return result
+}
+
 func mapFriendLinkToDomain(rec model.FriendLink) social.FriendLink {
 	return social.FriendLink{
 		ID:               rec.ID,
@@ -58,8 +213,11 @@ func mapFriendLinkToDomain(rec model.FriendLink) social.FriendLink {
 		InstanceID:       rec.InstanceID,
 		LastSyncAt:       rec.LastSyncAt,
 		LastSyncStatus:   rec.LastSyncStatus,
+		LastSyncError:    rec.LastSyncError,
 		SyncInterval:     rec.SyncInterval,
 		TotalPostsCached: rec.TotalPostsCached,
+		FeedETag:         rec.FeedETag,
+		FeedLastModified: rec.FeedLastModified,
 		UserID:           rec.UserID,
 		IsActive:         rec.IsActive,
 		CreatedAt:        rec.CreatedAt,
@@ -81,8 +239,11 @@ func mapFriendLinkToModel(link *social.FriendLink) model.FriendLink {
 		InstanceID:       link.InstanceID,
 		LastSyncAt:       link.LastSyncAt,
 		LastSyncStatus:   link.LastSyncStatus,
+		LastSyncError:    link.LastSyncError,
 		SyncInterval:     link.SyncInterval,
 		TotalPostsCached: link.TotalPostsCached,
+		FeedETag:         link.FeedETag,
+		FeedLastModified: link.FeedLastModified,
 		UserID:           link.UserID,
 		IsActive:         link.IsActive,
 	}
@@ -0,0 +1,111 @@
+package persistence
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+type FriendLinkPostRepository struct {
+	db *gorm.DB
+}
+
+func NewFriendLinkPostRepository(db *gorm.DB) *FriendLinkPostRepository {
+	return &FriendLinkPostRepository{db: db}
+}
+
+func (r *FriendLinkPostRepository) UpsertBatch(ctx context.Context, posts []social.FriendLinkPost) error {
+	if len(posts) == 0 {
+		return nil
+	}
+	recs := make([]model.FriendLinkPost, len(posts))
+	for i := range posts {
+		recs[i] = mapFriendLinkPostToModel(&posts[i])
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "friend_link_id"}, {Name: "guid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"title", "url", "summary", "author", "published_at", "updated_at"}),
+	}).Create(&recs).Error
+}
+
+func (r *FriendLinkPostRepository) TrimByLink(ctx context.Context, friendLinkID int64, keep int) (int, error) {
+	db := r.db.WithContext(ctx)
+	if keep > 0 {
+		keepIDs := db.Model(&model.FriendLinkPost{}).
+			Select("id").
+			Where("friend_link_id = ?", friendLinkID).
+			Order("published_at DESC").
+			Limit(keep)
+		if err := db.Where("friend_link_id = ? AND id NOT IN (?)", friendLinkID, keepIDs).
+			Delete(&model.FriendLinkPost{}).Error; err != nil {
+			return 0, err
+		}
+	}
+	var total int64
+	if err := db.Model(&model.FriendLinkPost{}).
+		Where("friend_link_id = ?", friendLinkID).
+		Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return int(total), nil
+}
+
+func (r *FriendLinkPostRepository) ListRecentActive(ctx context.Context, page int, pageSize int) ([]*social.FriendLinkPost, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.FriendLinkPost{}).
+		Joins("JOIN friend_link ON friend_link.id = friend_link_post.friend_link_id").
+		Where("friend_link.is_active = ? AND friend_link.deleted_at IS NULL", true)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	var recs []model.FriendLinkPost
+	if err := query.Select("friend_link_post.*").
+		Order("friend_link_post.published_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&recs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]*social.FriendLinkPost, len(recs))
+	for i, rec := range recs {
+		entity := mapFriendLinkPostToDomain(rec)
+		result[i] = &entity
+	}
+	return result, total, nil
+}
+
+func mapFriendLinkPostToDomain(rec model.FriendLinkPost) social.FriendLinkPost {
+	return social.FriendLinkPost{
+		ID:           rec.ID,
+		FriendLinkID: rec.FriendLinkID,
+		GUID:         rec.GUID,
+		Title:        rec.Title,
+		URL:          rec.URL,
+		Summary:      rec.Summary,
+		Author:       rec.Author,
+		PublishedAt:  rec.PublishedAt,
+		CreatedAt:    rec.CreatedAt,
+		UpdatedAt:    rec.UpdatedAt,
+	}
+}
+
+func mapFriendLinkPostToModel(post *social.FriendLinkPost) model.FriendLinkPost {
+	return model.FriendLinkPost{
+		ID:           post.ID,
+		FriendLinkID: post.FriendLinkID,
+		GUID:         post.GUID,
+		Title:        post.Title,
+		URL:          post.URL,
+		Summary:      post.Summary,
+		Author:       post.Author,
+		PublishedAt:  post.PublishedAt,
+	}
+}
@@ -24,6 +24,49 @@ func NewFriendLinkApplicationRepository(db *gorm.DB) *FriendLinkApplicationRepos
 	}
 }
 
+func (r *FriendLinkApplicationRepository) FindByID(ctx context.Context, id int64) (*social.FriendLinkApplication, error) {
+	rec, err := r.repo.FirstByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, social.ErrFriendLinkApplicationNotFound
+		}
+		return nil, err
+	}
+	entity := mapFriendLinkApplicationToDomain(*rec)
+	return &entity, nil
+}
+
+func (r *FriendLinkApplicationRepository) List(ctx context.Context, options social.FriendLinkApplicationListOptions) ([]*social.FriendLinkApplication, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.FriendLinkApplication{})
+	if options.Status != nil && *options.Status != "" {
+		query = query.Where("status = ?", *options.Status)
+	}
+	if options.Channel != nil && *options.Channel != "" {
+		query = query.Where("apply_channel = ?", *options.Channel)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (options.Page - 1) * options.PageSize
+	var recs []model.FriendLinkApplication
+	if err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(options.PageSize).
+		Find(&recs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]*social.FriendLinkApplication, len(recs))
+	for i, rec := range recs {
+		entity := mapFriendLinkApplicationToDomain(rec)
+		result[i] = &entity
+	}
+	return result, total, nil
+}
+
 func (r *FriendLinkApplicationRepository) FindByURL(ctx context.Context, url string) (*social.FriendLinkApplication, error) {
 	rec, err := r.repo.First(ctx, "url = ?", url)
 	if err != nil {
@@ -65,6 +108,8 @@ func (r *FriendLinkApplicationRepository) Update(ctx context.Context, app *socia
 			"user_id":             rec.UserID,
 			"message":             rec.Message,
 			"status":              rec.Status,
+			"review_reason":       rec.ReviewReason,
+			"reviewed_at":         rec.ReviewedAt,
 		}).Error
 }
 
@@ -85,6 +130,8 @@ func mapFriendLinkApplicationToDomain(rec model.FriendLinkApplication) social.Fr
 		UserID:            rec.UserID,
 		Message:           rec.Message,
 		Status:            rec.Status,
+		ReviewReason:      rec.ReviewReason,
+		ReviewedAt:        rec.ReviewedAt,
 		CreatedAt:         rec.CreatedAt,
 		UpdatedAt:         rec.UpdatedAt,
 	}
@@ -107,5 +154,7 @@ func mapFriendLinkApplicationToModel(app *social.FriendLinkApplication) model.Fr
 		UserID:            app.UserID,
 		Message:           app.Message,
 		Status:            app.Status,
+		ReviewReason:      app.ReviewReason,
+		ReviewedAt:        app.ReviewedAt,
 	}
 }
@@ -0,0 +1,99 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/social"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+type FriendLinkWebSubRepository struct {
+	db *gorm.DB
+}
+
+func NewFriendLinkWebSubRepository(db *gorm.DB) *FriendLinkWebSubRepository {
+	return &FriendLinkWebSubRepository{db: db}
+}
+
+func (r *FriendLinkWebSubRepository) GetByLinkID(ctx context.Context, friendLinkID int64) (*social.FriendLinkWebSub, error) {
+	return r.first(ctx, "friend_link_id = ?", friendLinkID)
+}
+
+func (r *FriendLinkWebSubRepository) GetByToken(ctx context.Context, token string) (*social.FriendLinkWebSub, error) {
+	return r.first(ctx, "callback_token = ?", token)
+}
+
+func (r *FriendLinkWebSubRepository) first(ctx context.Context, query string, arg any) (*social.FriendLinkWebSub, error) {
+	var rec model.FriendLinkWebSub
+	if err := r.db.WithContext(ctx).Where(query, arg).First(&rec).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, social.ErrFriendLinkWebSubNotFound
+		}
+		return nil, err
+	}
+	entity := mapFriendLinkWebSubToDomain(rec)
+	return &entity, nil
+}
+
+func (r *FriendLinkWebSubRepository) Upsert(ctx context.Context, sub *social.FriendLinkWebSub) error {
+	rec := mapFriendLinkWebSubToModel(sub)
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "friend_link_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"hub", "topic", "callback_token", "secret", "status", "lease_seconds", "expires_at", "last_push_at", "error", "updated_at"}),
+	}).Create(&rec).Error
+}
+
+func (r *FriendLinkWebSubRepository) Delete(ctx context.Context, friendLinkID int64) error {
+	return r.db.WithContext(ctx).Where("friend_link_id = ?", friendLinkID).Delete(&model.FriendLinkWebSub{}).Error
+}
+
+func (r *FriendLinkWebSubRepository) ListAll(ctx context.Context) ([]*social.FriendLinkWebSub, error) {
+	var recs []model.FriendLinkWebSub
+	if err := r.db.WithContext(ctx).Order("friend_link_id ASC").Find(&recs).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*social.FriendLinkWebSub, len(recs))
+	for i, rec := range recs {
+		entity := mapFriendLinkWebSubToDomain(rec)
+		result[i] = &entity
+	}
+	return result, nil
+}
+
+func mapFriendLinkWebSubToModel(sub *social.FriendLinkWebSub) model.FriendLinkWebSub {
+	return model.FriendLinkWebSub{
+		FriendLinkID:  sub.FriendLinkID,
+		Hub:           sub.Hub,
+		Topic:         sub.Topic,
+		CallbackToken: sub.CallbackToken,
+		Secret:        sub.Secret,
+		Status:        sub.Status,
+		LeaseSeconds:  sub.LeaseSeconds,
+		ExpiresAt:     sub.ExpiresAt,
+		LastPushAt:    sub.LastPushAt,
+		Error:         sub.Error,
+		CreatedAt:     sub.CreatedAt,
+		UpdatedAt:     sub.UpdatedAt,
+	}
+}
+
+func mapFriendLinkWebSubToDomain(rec model.FriendLinkWebSub) social.FriendLinkWebSub {
+	return social.FriendLinkWebSub{
+		FriendLinkID:  rec.FriendLinkID,
+		Hub:           rec.Hub,
+		Topic:         rec.Topic,
+		CallbackToken: rec.CallbackToken,
+		Secret:        rec.Secret,
+		Status:        rec.Status,
+		LeaseSeconds:  rec.LeaseSeconds,
+		ExpiresAt:     rec.ExpiresAt,
+		LastPushAt:    rec.LastPushAt,
+		Error:         rec.Error,
+		CreatedAt:     rec.CreatedAt,
+		UpdatedAt:     rec.UpdatedAt,
+	}
+}
@@ -0,0 +1,425 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/app/contentutil"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/like"
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+type PhotoRepository struct {
+	db   *gorm.DB
+	repo *GormRepository[model.Photo]
+}
+
+func NewPhotoRepository(db *gorm.DB) *PhotoRepository {
+	return &PhotoRepository{
+		db:   db,
+		repo: NewGormRepository[model.Photo](db),
+	}
+}
+
+func (r *PhotoRepository) FindByID(ctx context.Context, id int64) (*media.Photo, error) {
+	rec, err := r.repo.FirstByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, media.ErrPhotoNotFound
+		}
+		return nil, err
+	}
+	photo := mapPhotoToDomain(*rec)
+	return &photo, nil
+}
+
+func (r *PhotoRepository) List(ctx context.Context, albumID *int64, offset int, limit int) ([]media.Photo, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.Photo{})
+	if albumID != nil {
+		query = query.Where("album_id = ?", *albumID).Order("sort_order ASC").Order("id ASC")
+	} else {
+		query = query.Order("created_at DESC").Order("id DESC")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var records []model.Photo
+	if err := query.Offset(offset).Limit(limit).Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	photos := make([]media.Photo, len(records))
+	for i, rec := range records {
+		photos[i] = mapPhotoToDomain(rec)
+	}
+	return photos, total, nil
+}
+
+func (r *PhotoRepository) Create(ctx context.Context, photo *media.Photo) error {
+	rec := mapPhotoToModel(photo)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rec).Error; err != nil {
+			return err
+		}
+		title := strconv.FormatInt(rec.ID, 10)
+		if photo.Description != nil && *photo.Description != "" {
+			title = *photo.Description
+		}
+		areaID, err := createCommentArea(tx, contentutil.CommentAreaTypePhoto, "照片", title, rec.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Photo{}).Where("id = ?", rec.ID).
+			Update("comment_id", areaID).Error; err != nil {
+			return err
+		}
+		photo.ID = rec.ID
+		photo.CommentID = areaID
+		photo.CreatedAt = rec.CreatedAt
+		photo.UpdatedAt = rec.UpdatedAt
+		return nil
+	})
+}
+
+func (r *PhotoRepository) Update(ctx context.Context, photo *media.Photo) error {
+	rec := mapPhotoToModel(photo)
+	return r.db.WithContext(ctx).Model(&model.Photo{}).
+		Where("id = ?", photo.ID).
+		Updates(map[string]any{
+			"album_id":    rec.AlbumID,
+			"location":    rec.Location,
+			"device":      rec.Device,
+			"shade":       rec.Shade,
+			"description": rec.Description,
+			"taken_at":    rec.TakenAt,
+			"sort_order":  rec.SortOrder,
+		}).Error
+}
+
+// Delete 删除照片及其评论区与点赞记录；作为相册封面时由外键置空。
+func (r *PhotoRepository) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rec model.Photo
+		if err := tx.Select("id", "comment_id").Where("id = ?", id).First(&rec).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return media.ErrPhotoNotFound
+			}
+			return err
+		}
+		if err := tx.Where("target_type = ? AND target_id = ?", string(like.TargetPhoto), id).
+			Delete(&model.ContentLike{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.Photo{}, id).Error; err != nil {
+			return err
+		}
+		if rec.CommentID != nil {
+			return deleteCommentArea(tx, *rec.CommentID)
+		}
+		return nil
+	})
+}
+
+func (r *PhotoRepository) UpdateOrder(ctx context.Context, albumID int64, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Photo{}).
+			Where("album_id = ? AND id IN ?", albumID, ids).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count != int64(len(ids)) {
+			return media.ErrPhotoNotInAlbum
+		}
+		for i, id := range ids {
+			if err := tx.Model(&model.Photo{}).Where("id = ?", id).
+				Update("sort_order", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PhotoRepository) NextSortOrder(ctx context.Context, albumID int64) (int, error) {
+	var next int
+	err := r.db.WithContext(ctx).Model(&model.Photo{}).
+		Where("album_id = ?", albumID).
+		Select("COALESCE(MAX(sort_order) + 1, 0)").
+		Scan(&next).Error
+	return next, err
+}
+
+func (r *PhotoRepository) IncLike(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Model(&model.Photo{}).
+		Where("id = ?", id).
+		UpdateColumn("likes", gorm.Expr("likes + ?", 1)).Error
+}
+
+func (r *PhotoRepository) DecLike(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Model(&model.Photo{}).
+		Where("id = ? AND likes > 0", id).
+		UpdateColumn("likes", gorm.Expr("likes - ?", 1)).Error
+}
+
+type AlbumRepository struct {
+	db   *gorm.DB
+	repo *GormRepository[model.PhotoAlbum]
+}
+
+func NewAlbumRepository(db *gorm.DB) *AlbumRepository {
+	return &AlbumRepository{
+		db:   db,
+		repo: NewGormRepository[model.PhotoAlbum](db),
+	}
+}
+
+func (r *AlbumRepository) FindByID(ctx context.Context, id int64) (*media.Album, error) {
+	rec, err := r.repo.FirstByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, media.ErrAlbumNotFound
+		}
+		return nil, err
+	}
+	albums, err := r.withPhotos(ctx, []model.PhotoAlbum{*rec})
+	if err != nil {
+		return nil, err
+	}
+	return &albums[0], nil
+}
+
+func (r *AlbumRepository) List(ctx context.Context, offset int, limit int) ([]media.Album, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&model.PhotoAlbum{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var records []model.PhotoAlbum
+	if err := r.db.WithContext(ctx).
+		Order("sort_order ASC").
+		Order("id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	albums, err := r.withPhotos(ctx, records)
+	if err != nil {
+		return nil, 0, err
+	}
+	return albums, total, nil
+}
+
+func (r *AlbumRepository) Create(ctx context.Context, album *media.Album) error {
+	rec := mapAlbumToModel(album)
+	if rec.SortOrder == 0 {
+		if err := r.db.WithContext(ctx).Model(&model.PhotoAlbum{}).
+			Select("COALESCE(MAX(sort_order) + 1, 0)").
+			Scan(&rec.SortOrder).Error; err != nil {
+			return err
+		}
+	}
+	if err := r.repo.Create(ctx, &rec); err != nil {
+		return err
+	}
+	album.ID = rec.ID
+	album.SortOrder = rec.SortOrder
+	album.CreatedAt = rec.CreatedAt
+	album.UpdatedAt = rec.UpdatedAt
+	return nil
+}
+
+func (r *AlbumRepository) Update(ctx context.Context, album *media.Album) error {
+	return r.db.WithContext(ctx).Model(&model.PhotoAlbum{}).
+		Where("id = ?", album.ID).
+		Updates(map[string]any{
+			"title":          album.Title,
+			"description":    album.Description,
+			"cover_photo_id": album.CoverPhotoID,
+		}).Error
+}
+
+// Delete 删除相册，相册内的照片保留并由外键移出相册。
+func (r *AlbumRepository) Delete(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Delete(&model.PhotoAlbum{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return media.ErrAlbumNotFound
+	}
+	return nil
+}
+
+func (r *AlbumRepository) UpdateOrder(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&model.PhotoAlbum{}).Where("id = ?", id).
+				Update("sort_order", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// withPhotos 批量填充照片数量与封面；未设置封面的相册取排序最靠前的照片。
+func (r *AlbumRepository) withPhotos(ctx context.Context, records []model.PhotoAlbum) ([]media.Album, error) {
+	albums := make([]media.Album, len(records))
+	if len(records) == 0 {
+		return albums, nil
+	}
+	ids := make([]int64, len(records))
+	var coverIDs []int64
+	for i, rec := range records {
+		ids[i] = rec.ID
+		if rec.CoverPhotoID != nil {
+			coverIDs = append(coverIDs, *rec.CoverPhotoID)
+		}
+	}
+
+	var counts []struct {
+		AlbumID int64
+		Count   int64
+	}
+	if err := r.db.WithContext(ctx).Model(&model.Photo{}).
+		Select("album_id, COUNT(*) AS count").
+		Where("album_id IN ?", ids).
+		Group("album_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	countByAlbum := make(map[int64]int64, len(counts))
+	for _, c := range counts {
+		countByAlbum[c.AlbumID] = c.Count
+	}
+
+	covers := make(map[int64]model.Photo)
+	if len(coverIDs) > 0 {
+		var photos []model.Photo
+		if err := r.db.WithContext(ctx).Where("id IN ?", coverIDs).Find(&photos).Error; err != nil {
+			return nil, err
+		}
+		for _, p := range photos {
+			covers[p.ID] = p
+		}
+	}
+	var firsts []model.Photo
+	if err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (album_id) * FROM photo WHERE album_id IN ? ORDER BY album_id, sort_order, id`, ids).
+		Scan(&firsts).Error; err != nil {
+		return nil, err
+	}
+	firstByAlbum := make(map[int64]model.Photo, len(firsts))
+	for _, p := range firsts {
+		firstByAlbum[*p.AlbumID] = p
+	}
+
+	for i, rec := range records {
+		albums[i] = mapAlbumToDomain(rec)
+		albums[i].PhotoCount = countByAlbum[rec.ID]
+		cover, ok := model.Photo{}, false
+		if rec.CoverPhotoID != nil {
+			cover, ok = covers[*rec.CoverPhotoID]
+		}
+		if !ok {
+			cover, ok = firstByAlbum[rec.ID]
+		}
+		if ok {
+			photo := mapPhotoToDomain(cover)
+			albums[i].Cover = &photo
+		}
+	}
+	return albums, nil
+}
+
+func mapPhotoToDomain(rec model.Photo) media.Photo {
+	photo := media.Photo{
+		ID:          rec.ID,
+		UploadID:    rec.UploadID,
+		AlbumID:     rec.AlbumID,
+		URL:         rec.URL,
+		Location:    stringToPtr(rec.Location),
+		Device:      stringToPtr(rec.Device),
+		Shade:       stringToPtr(rec.Shade),
+		Description: stringToPtr(rec.Description),
+		TakenAt:     rec.TakenAt,
+		SortOrder:   rec.SortOrder,
+		Likes:       rec.Likes,
+		CreatedAt:   rec.CreatedAt,
+		UpdatedAt:   rec.UpdatedAt,
+	}
+	if rec.CommentID != nil {
+		photo.CommentID = *rec.CommentID
+	}
+	if rec.Width != nil {
+		photo.Width = *rec.Width
+	}
+	if rec.Height != nil {
+		photo.Height = *rec.Height
+	}
+	if rec.Blurhash != nil {
+		photo.Blurhash = *rec.Blurhash
+	}
+	return photo
+}
+
+func mapPhotoToModel(photo *media.Photo) model.Photo {
+	rec := model.Photo{
+		ID:          photo.ID,
+		UploadID:    photo.UploadID,
+		AlbumID:     photo.AlbumID,
+		URL:         photo.URL,
+		Location:    optionalString(photo.Location),
+		Device:      optionalString(photo.Device),
+		Shade:       optionalString(photo.Shade),
+		Description: optionalString(photo.Description),
+		TakenAt:     photo.TakenAt,
+		SortOrder:   photo.SortOrder,
+		Likes:       photo.Likes,
+	}
+	if photo.CommentID != 0 {
+		rec.CommentID = &photo.CommentID
+	}
+	if photo.Width > 0 && photo.Height > 0 {
+		rec.Width = &photo.Width
+		rec.Height = &photo.Height
+	}
+	if photo.Blurhash != "" {
+		rec.Blurhash = &photo.Blurhash
+	}
+	return rec
+}
+
+func mapAlbumToDomain(rec model.PhotoAlbum) media.Album {
+	return media.Album{
+		ID:           rec.ID,
+		Title:        rec.Title,
+		Description:  rec.Description,
+		CoverPhotoID: rec.CoverPhotoID,
+		SortOrder:    rec.SortOrder,
+		CreatedAt:    rec.CreatedAt,
+		UpdatedAt:    rec.UpdatedAt,
+	}
+}
+
+func mapAlbumToModel(album *media.Album) model.PhotoAlbum {
+	return model.PhotoAlbum{
+		ID:           album.ID,
+		Title:        album.Title,
+		Description:  album.Description,
+		CoverPhotoID: album.CoverPhotoID,
+		SortOrder:    album.SortOrder,
+	}
+}
@@ -221,6 +221,18 @@ func (r *IdentityRepository) CountUsers(ctx context.Context) (int64, error) {
 	return total, nil
 }
 
+func (r *IdentityRepository) FindFirstAdmin(ctx context.Context) (*identity.User, error) {
+	var rec model.User
+	if err := r.db.WithContext(ctx).Where("is_admin = ?", true).Order("id ASC").First(&rec).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identity.ErrUserNotFound
+		}
+		return nil, err
+	}
+	user := mapUserToDomain(rec)
+	return &user, nil
+}
+
 func isUniqueConstraint(err error) bool {
 	if err == nil {
 		return false
@@ -0,0 +1,69 @@
+package persistence
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/like"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+type LikeRepository struct {
+	db *gorm.DB
+}
+
+func NewLikeRepository(db *gorm.DB) *LikeRepository {
+	return &LikeRepository{db: db}
+}
+
+func (r *LikeRepository) Create(ctx context.Context, item *like.ContentLike) (bool, error) {
+	rec := model.ContentLike{
+		TargetType: string(item.TargetType),
+		TargetID:   item.TargetID,
+		UserID:     item.UserID,
+	}
+	if item.UserID == nil && item.SessionID != nil {
+		rec.SessionID = *item.SessionID
+	}
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&rec)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	item.ID = rec.ID
+	item.CreatedAt = rec.CreatedAt
+	return true, nil
+}
+
+func (r *LikeRepository) Delete(ctx context.Context, item *like.ContentLike) (bool, error) {
+	result := r.ownerScope(ctx, item).Delete(&model.ContentLike{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *LikeRepository) Exists(ctx context.Context, item *like.ContentLike) (bool, error) {
+	var count int64
+	if err := r.ownerScope(ctx, item).Model(&model.ContentLike{}).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ownerScope 按点赞人过滤：登录用户按 user_id，访客按 session_id。
+func (r *LikeRepository) ownerScope(ctx context.Context, item *like.ContentLike) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Where("target_type = ? AND target_id = ?", string(item.TargetType), item.TargetID)
+	if item.UserID != nil {
+		return query.Where("user_id = ?", *item.UserID)
+	}
+	if item.SessionID != nil {
+		return query.Where("user_id IS NULL AND session_id = ?", *item.SessionID)
+	}
+	return query.Where("1 = 0")
+}
@@ -2,8 +2,10 @@ package persistence
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
 	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
@@ -70,14 +72,34 @@ func (r *UploadFileRepository) UpdateName(ctx context.Context, id int64, name st
 		Update("name", name).Error
 }
 
-func (r *UploadFileRepository) List(ctx context.Context, offset int, limit int) ([]media.UploadFile, int64, error) {
+func (r *UploadFileRepository) UpdateImageMeta(ctx context.Context, id int64, meta media.ImageMeta) error {
+	rec := model.UploadFile{}
+	applyImageMeta(&rec, meta)
+	return r.db.WithContext(ctx).
+		Model(&model.UploadFile{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"width":          rec.Width,
+			"height":         rec.Height,
+			"dominant_color": rec.DominantColor,
+			"blurhash":       rec.Blurhash,
+			"variants":       rec.Variants,
+		}).Error
+}
+
+func (r *UploadFileRepository) List(ctx context.Context, userID *int64, offset int, limit int) ([]media.UploadFile, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.UploadFile{})
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
 	var total int64
-	if err := r.db.WithContext(ctx).Model(&model.UploadFile{}).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	var records []model.UploadFile
-	if err := r.db.WithContext(ctx).
+	if err := query.
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(limit).
@@ -92,29 +114,97 @@ func (r *UploadFileRepository) List(ctx context.Context, offset int, limit int)
 	return files, total, nil
 }
 
+func (r *UploadFileRepository) SumSizeByUser(ctx context.Context, userID int64) (int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&model.UploadFile{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *UploadFileRepository) FindByStoredPaths(ctx context.Context, paths []string) ([]media.UploadFile, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	var records []model.UploadFile
+	if err := r.db.WithContext(ctx).
+		Where("path IN ?", paths).
+		Or("EXISTS (SELECT 1 FROM jsonb_array_elements(variants) AS v WHERE v->>'path' IN ?)", paths).
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	files := make([]media.UploadFile, len(records))
+	for i, rec := range records {
+		files[i] = mapUploadFileToDomain(rec)
+	}
+	return files, nil
+}
+
 func (r *UploadFileRepository) DeleteByID(ctx context.Context, id int64) error {
 	return r.db.WithContext(ctx).Delete(&model.UploadFile{}, id).Error
 }
 
 func mapUploadFileToDomain(rec model.UploadFile) media.UploadFile {
-	return media.UploadFile{
+	file := media.UploadFile{
 		ID:        rec.ID,
 		Name:      rec.Name,
 		Path:      rec.Path,
 		Type:      rec.Type,
 		Size:      rec.Size,
 		Hash:      rec.Hash,
+		UserID:    rec.UserID,
 		CreatedAt: rec.CreatedAt,
 	}
+	if rec.Width != nil {
+		file.Width = *rec.Width
+	}
+	if rec.Height != nil {
+		file.Height = *rec.Height
+	}
+	if rec.DominantColor != nil {
+		file.DominantColor = *rec.DominantColor
+	}
+	if rec.Blurhash != nil {
+		file.Blurhash = *rec.Blurhash
+	}
+	if len(rec.Variants) > 0 {
+		_ = json.Unmarshal(rec.Variants, &file.Variants)
+	}
+	return file
 }
 
 func mapUploadFileToModel(file *media.UploadFile) model.UploadFile {
-	return model.UploadFile{
-		ID:   file.ID,
-		Name: file.Name,
-		Path: file.Path,
-		Type: file.Type,
-		Size: file.Size,
-		Hash: file.Hash,
+	rec := model.UploadFile{
+		ID:     file.ID,
+		Name:   file.Name,
+		Path:   file.Path,
+		Type:   file.Type,
+		Size:   file.Size,
+		Hash:   file.Hash,
+		UserID: file.UserID,
+	}
+	applyImageMeta(&rec, file.ImageMeta)
+	return rec
+}
+
+func applyImageMeta(rec *model.UploadFile, meta media.ImageMeta) {
+	if meta.Width > 0 && meta.Height > 0 {
+		rec.Width = &meta.Width
+		rec.Height = &meta.Height
+	}
+	if meta.DominantColor != "" {
+		rec.DominantColor = &meta.DominantColor
+	}
+	if meta.Blurhash != "" {
+		rec.Blurhash = &meta.Blurhash
+	}
+	rec.Variants = datatypes.JSON("[]")
+	if len(meta.Variants) > 0 {
+		if payload, err := json.Marshal(meta.Variants); err == nil {
+			rec.Variants = payload
+		}
 	}
 }
@@ -17,14 +17,122 @@ type FederationInstance struct {
 	Features        datatypes.JSON `gorm:"column:features;type:jsonb;not null"`
 	Policies        datatypes.JSON `gorm:"column:policies;type:jsonb;not null"`
 	Endpoints       datatypes.JSON `gorm:"column:endpoints;type:jsonb;not null"`
+	Manifest        datatypes.JSON `gorm:"column:manifest;type:jsonb;not null"`
 	Status          string         `gorm:"column:status;size:20;not null"`
-	LastSeenAt      *time.Time     `gorm:"column:last_seen_at"`
-	CreatedAt       time.Time      `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt       time.Time      `gorm:"column:updated_at;autoUpdateTime"`
+	BlockReason     *string        `gorm:"column:block_reason;type:text"`
+	// 限流计数只通过 RecordRateLimitViolation 累加，Update 时忽略。
+	RateLimitViolations int        `gorm:"column:rate_limit_violations;->"`
+	LastRateLimitedAt   *time.Time `gorm:"column:last_rate_limited_at;->"`
+	LastSeenAt          *time.Time `gorm:"column:last_seen_at"`
+	CreatedAt           time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt           time.Time  `gorm:"column:updated_at;autoUpdateTime"`
 }
 
 func (FederationInstance) TableName() string { return "federation_instance" }
 
+type FederationDomainRule struct {
+	ID        int64     `gorm:"column:id;primaryKey"`
+	Pattern   string    `gorm:"column:pattern;size:255;not null"`
+	Action    string    `gorm:"column:action;size:10;not null"`
+	Reason    *string   `gorm:"column:reason;type:text"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (FederationDomainRule) TableName() string { return "federation_domain_rule" }
+
+type FederationSigningKey struct {
+	ID         int64      `gorm:"column:id;primaryKey"`
+	KeyRef     string     `gorm:"column:key_ref;size:64;not null"`
+	Algorithm  string     `gorm:"column:algorithm;size:32;not null"`
+	PublicKey  string     `gorm:"column:public_key;type:text;not null"`
+	PrivateKey string     `gorm:"column:private_key;type:text;not null"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at"`
+}
+
+func (FederationSigningKey) TableName() string { return "federation_signing_key" }
+
+type ActivityPubFollower struct {
+	ID               int64     `gorm:"column:id;primaryKey"`
+	ActorID          string    `gorm:"column:actor_id;size:500;not null"`
+	Inbox            string    `gorm:"column:inbox;size:500;not null"`
+	SharedInbox      *string   `gorm:"column:shared_inbox;size:500"`
+	Username         *string   `gorm:"column:username;size:255"`
+	DisplayName      *string   `gorm:"column:display_name;size:255"`
+	Avatar           *string   `gorm:"column:avatar;size:500"`
+	FollowActivityID *string   `gorm:"column:follow_activity_id;size:500"`
+	CreatedAt        time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (ActivityPubFollower) TableName() string { return "activitypub_follower" }
+
+type ActivityPubInteraction struct {
+	ID         int64     `gorm:"column:id;primaryKey"`
+	ActivityID string    `gorm:"column:activity_id;size:500;not null"`
+	Kind       string    `gorm:"column:kind;size:20;not null"`
+	ActorID    string    `gorm:"column:actor_id;size:500;not null"`
+	ObjectType string    `gorm:"column:object_type;size:20;not null"`
+	ObjectID   int64     `gorm:"column:object_id;not null"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (ActivityPubInteraction) TableName() string { return "activitypub_interaction" }
+
+type Webmention struct {
+	ID         int64      `gorm:"column:id;primaryKey"`
+	Source     string     `gorm:"column:source;size:1000;not null"`
+	Target     string     `gorm:"column:target;size:1000;not null"`
+	TargetType string     `gorm:"column:target_type;size:20;not null"`
+	TargetID   int64      `gorm:"column:target_id;not null"`
+	Status     string     `gorm:"column:status;size:20;not null"`
+	AuthorName *string    `gorm:"column:author_name;size:255"`
+	AuthorURL  *string    `gorm:"column:author_url;size:1000"`
+	Title      *string    `gorm:"column:title;size:500"`
+	Content    *string    `gorm:"column:content;type:text"`
+	CommentID  *int64     `gorm:"column:comment_id"`
+	Error      *string    `gorm:"column:error;type:text"`
+	VerifiedAt *time.Time `gorm:"column:verified_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (Webmention) TableName() string { return "webmention" }
+
+type WebmentionSend struct {
+	ID          int64      `gorm:"column:id;primaryKey"`
+	ArticleID   int64      `gorm:"column:article_id;not null"`
+	Source      string     `gorm:"column:source;size:1000;not null"`
+	Target      string     `gorm:"column:target;size:1000;not null"`
+	Endpoint    *string    `gorm:"column:endpoint;size:1000"`
+	Status      string     `gorm:"column:status;size:20;not null"`
+	StatusCode  *int       `gorm:"column:status_code"`
+	Error       *string    `gorm:"column:error;type:text"`
+	ContentHash *string    `gorm:"column:content_hash;size:64"`
+	SentAt      *time.Time `gorm:"column:sent_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (WebmentionSend) TableName() string { return "webmention_send" }
+
+type WebSubSubscription struct {
+	ID              int64      `gorm:"column:id;primaryKey"`
+	Topic           string     `gorm:"column:topic;size:500;not null"`
+	Callback        string     `gorm:"column:callback;size:1000;not null"`
+	Secret          *string    `gorm:"column:secret;size:200"`
+	LeaseSeconds    int        `gorm:"column:lease_seconds;not null"`
+	ExpiresAt       time.Time  `gorm:"column:expires_at;not null"`
+	LastDeliveredAt *time.Time `gorm:"column:last_delivered_at"`
+	LastError       *string    `gorm:"column:last_error;type:text"`
+	FailureCount    int        `gorm:"column:failure_count;not null"`
+	CreatedAt       time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (WebSubSubscription) TableName() string { return "websub_subscription" }
+
 type FederatedPostCache struct {
 	ID             int64          `gorm:"column:id;primaryKey"`
 	InstanceID     int64          `gorm:"column:instance_id;not null"`
@@ -66,6 +174,25 @@ type FederatedCitation struct {
 
 func (FederatedCitation) TableName() string { return "federated_citation" }
 
+type FederatedComment struct {
+	ID               int64      `gorm:"column:id;primaryKey"`
+	SourceInstanceID int64      `gorm:"column:source_instance_id;not null"`
+	RemoteCommentID  string     `gorm:"column:remote_comment_id;size:255;not null"`
+	TargetArticleID  int64      `gorm:"column:target_article_id;not null"`
+	AuthorName       string     `gorm:"column:author_name;size:255;not null"`
+	AuthorURL        *string    `gorm:"column:author_url;size:1000"`
+	AuthorAvatar     *string    `gorm:"column:author_avatar;size:1000"`
+	Content          string     `gorm:"column:content;type:text;not null"`
+	Status           string     `gorm:"column:status;size:20;not null"`
+	CommentID        *int64     `gorm:"column:comment_id"`
+	RejectReason     *string    `gorm:"column:reject_reason;type:text"`
+	ReviewedAt       *time.Time `gorm:"column:reviewed_at"`
+	CreatedAt        time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (FederatedComment) TableName() string { return "federated_comment" }
+
 type FederatedMention struct {
 	ID               int64      `gorm:"column:id;primaryKey"`
 	SourceInstanceID int64      `gorm:"column:source_instance_id;not null"`
@@ -80,3 +207,22 @@ type FederatedMention struct {
 }
 
 func (FederatedMention) TableName() string { return "federated_mention" }
+
+type FederationOutboundFriendLink struct {
+	ID                  int64      `gorm:"column:id;primaryKey"`
+	TargetURL           string     `gorm:"column:target_url;size:500;not null"`
+	InstanceID          *int64     `gorm:"column:instance_id"`
+	Message             *string    `gorm:"column:message;type:text"`
+	RSSURL              *string    `gorm:"column:rss_url;size:1000"`
+	Status              string     `gorm:"column:status;size:20;not null"`
+	ResponseStatus      *int       `gorm:"column:response_status"`
+	ResponseBody        *string    `gorm:"column:response_body;type:text"`
+	RemoteApplicationID *int64     `gorm:"column:remote_application_id"`
+	RejectReason        *string    `gorm:"column:reject_reason;type:text"`
+	FriendLinkID        *int64     `gorm:"column:friend_link_id"`
+	DecidedAt           *time.Time `gorm:"column:decided_at"`
+	CreatedAt           time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt           time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (FederationOutboundFriendLink) TableName() string { return "federation_outbound_friendlink" }
@@ -3,6 +3,7 @@ package model
 import (
 	"time"
 
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -69,3 +70,64 @@ type UserOAuth struct {
 }
 
 func (UserOAuth) TableName() string { return "user_oauth" }
+
+type UserTwoFactor struct {
+	UserID       int64      `gorm:"column:user_id;primaryKey"`
+	Secret       string     `gorm:"column:secret;size:64;not null"`
+	LastUsedStep int64      `gorm:"column:last_used_step;not null;default:0"`
+	Enabled      bool       `gorm:"column:enabled;not null;default:false"`
+	EnabledAt    *time.Time `gorm:"column:enabled_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (UserTwoFactor) TableName() string { return "user_two_factor" }
+
+type UserRecoveryCode struct {
+	ID        int64      `gorm:"column:id;primaryKey"`
+	UserID    int64      `gorm:"column:user_id;not null"`
+	CodeHash  string     `gorm:"column:code_hash;size:64;not null"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (UserRecoveryCode) TableName() string { return "user_recovery_code" }
+
+type UserPasskey struct {
+	ID             int64          `gorm:"column:id;primaryKey"`
+	UserID         int64          `gorm:"column:user_id;not null"`
+	Name           string         `gorm:"column:name;size:64;not null"`
+	CredentialID   []byte         `gorm:"column:credential_id;not null"`
+	PublicKey      []byte         `gorm:"column:public_key;not null"`
+	Algorithm      int64          `gorm:"column:algorithm;not null"`
+	SignCount      int64          `gorm:"column:sign_count;not null;default:0"`
+	AAGUID         []byte         `gorm:"column:aaguid"`
+	Transports     datatypes.JSON `gorm:"column:transports;type:jsonb"`
+	BackupEligible bool           `gorm:"column:backup_eligible;not null;default:false"`
+	BackupState    bool           `gorm:"column:backup_state;not null;default:false"`
+	LastUsedAt     *time.Time     `gorm:"column:last_used_at"`
+	CreatedAt      time.Time      `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time      `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (UserPasskey) TableName() string { return "user_passkey" }
+
+type UserSession struct {
+	ID                int64      `gorm:"column:id;primaryKey"`
+	SessionKey        string     `gorm:"column:session_key;size:64;not null"`
+	UserID            int64      `gorm:"column:user_id;not null"`
+	RefreshTokenHash  string     `gorm:"column:refresh_token_hash;size:64;not null"`
+	PreviousTokenHash string     `gorm:"column:previous_token_hash;size:64;not null"`
+	UserAgent         string     `gorm:"column:user_agent;size:512;not null"`
+	Browser           string     `gorm:"column:browser;size:64;not null"`
+	Platform          string     `gorm:"column:platform;size:64;not null"`
+	IP                string     `gorm:"column:ip;size:64;not null"`
+	Location          string     `gorm:"column:location;size:128;not null"`
+	CreatedAt         time.Time  `gorm:"column:created_at;autoCreateTime"`
+	LastSeenAt        time.Time  `gorm:"column:last_seen_at"`
+	ExpiresAt         time.Time  `gorm:"column:expires_at;not null"`
+	RevokedAt         *time.Time `gorm:"column:revoked_at"`
+	RevokedReason     string     `gorm:"column:revoked_reason;size:32;not null"`
+}
+
+func (UserSession) TableName() string { return "user_session" }
@@ -1,27 +1,94 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
 
 type UploadFile struct {
-	ID        int64     `gorm:"column:id;primaryKey"`
-	Name      string    `gorm:"column:name;size:255;not null"`
-	Path      string    `gorm:"column:path;size:255;not null"`
-	Type      string    `gorm:"column:type;size:45;not null"`
-	Size      int64     `gorm:"column:size;not null"`
-	Hash      string    `gorm:"column:hash;size:64"`
-	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	ID            int64          `gorm:"column:id;primaryKey"`
+	Name          string         `gorm:"column:name;size:255;not null"`
+	Path          string         `gorm:"column:path;size:255;not null"`
+	Type          string         `gorm:"column:type;size:45;not null"`
+	Size          int64          `gorm:"column:size;not null"`
+	Hash          string         `gorm:"column:hash;size:64"`
+	UserID        *int64         `gorm:"column:user_id"`
+	Width         *int           `gorm:"column:width"`
+	Height        *int           `gorm:"column:height"`
+	DominantColor *string        `gorm:"column:dominant_color;size:9"`
+	Blurhash      *string        `gorm:"column:blurhash;size:64"`
+	Variants      datatypes.JSON `gorm:"column:variants;type:jsonb;not null;default:'[]'"`
+	CreatedAt     time.Time      `gorm:"column:created_at;autoCreateTime"`
 }
 
 func (UploadFile) TableName() string { return "upload_file" }
 
 type Photo struct {
-	ID          int64     `gorm:"column:id;primaryKey"`
-	URL         string    `gorm:"column:url;size:255;not null"`
-	Location    string    `gorm:"column:location;size:255"`
-	Device      string    `gorm:"column:device;size:255"`
-	Shade       string    `gorm:"column:shade;size:255"`
-	Description string    `gorm:"column:description"`
-	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+	ID          int64      `gorm:"column:id;primaryKey"`
+	UploadID    *int64     `gorm:"column:upload_id"`
+	AlbumID     *int64     `gorm:"column:album_id"`
+	CommentID   *int64     `gorm:"column:comment_id"`
+	URL         string     `gorm:"column:url;size:255;not null"`
+	Location    string     `gorm:"column:location;size:255"`
+	Device      string     `gorm:"column:device;size:255"`
+	Shade       string     `gorm:"column:shade;size:255"`
+	Description string     `gorm:"column:description"`
+	Width       *int       `gorm:"column:width"`
+	Height      *int       `gorm:"column:height"`
+	Blurhash    *string    `gorm:"column:blurhash;size:64"`
+	TakenAt     *time.Time `gorm:"column:taken_at"`
+	SortOrder   int        `gorm:"column:sort_order;not null;default:0"`
+	Likes       int        `gorm:"column:likes;not null;default:0"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;autoUpdateTime"`
 }
 
 func (Photo) TableName() string { return "photo" }
+
+type PhotoAlbum struct {
+	ID           int64     `gorm:"column:id;primaryKey"`
+	Title        string    `gorm:"column:title;size:255;not null"`
+	Description  *string   `gorm:"column:description"`
+	CoverPhotoID *int64    `gorm:"column:cover_photo_id"`
+	SortOrder    int       `gorm:"column:sort_order;not null;default:0"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (PhotoAlbum) TableName() string { return "photo_album" }
+
+type UploadFileUsage struct {
+	UploadID  int64     `gorm:"column:upload_id;primaryKey"`
+	OwnerType string    `gorm:"column:owner_type;primaryKey;size:20"`
+	OwnerID   int64     `gorm:"column:owner_id;primaryKey"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (UploadFileUsage) TableName() string { return "upload_file_usage" }
+
+type UploadSession struct {
+	ID          string    `gorm:"column:id;primaryKey;size:36"`
+	UserID      int64     `gorm:"column:user_id;not null"`
+	Name        string    `gorm:"column:name;size:255;not null"`
+	Type        string    `gorm:"column:type;size:20;not null"`
+	Size        int64     `gorm:"column:size;not null"`
+	ChunkSize   int64     `gorm:"column:chunk_size;not null"`
+	TotalChunks int       `gorm:"column:total_chunks;not null"`
+	Hash        string    `gorm:"column:hash;size:64;not null"`
+	UploadID    *int64    `gorm:"column:upload_id"`
+	ExpiresAt   time.Time `gorm:"column:expires_at;not null"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (UploadSession) TableName() string { return "upload_session" }
+
+type UploadFileSource struct {
+	ID        int64     `gorm:"column:id;primaryKey"`
+	UploadID  int64     `gorm:"column:upload_id;not null"`
+	SourceURL string    `gorm:"column:source_url;not null"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (UploadFileSource) TableName() string { return "upload_file_source" }
@@ -19,8 +19,11 @@ type FriendLink struct {
 	InstanceID       *int64         `gorm:"column:instance_id"`
 	LastSyncAt       *time.Time     `gorm:"column:last_sync_at"`
 	LastSyncStatus   *string        `gorm:"column:last_sync_status;size:20"`
+	LastSyncError    *string        `gorm:"column:last_sync_error"`
 	SyncInterval     *int           `gorm:"column:sync_interval"`
 	TotalPostsCached int            `gorm:"column:total_posts_cached;not null"`
+	FeedETag         *string        `gorm:"column:feed_etag;size:255"`
+	FeedLastModified *string        `gorm:"column:feed_last_modified;size:64"`
 	UserID           *int64         `gorm:"column:user_id"`
 	IsActive         bool           `gorm:"column:is_active"`
 	CreatedAt        time.Time      `gorm:"column:created_at;autoCreateTime"`
@@ -30,6 +33,55 @@ type FriendLink struct {
 
 func (FriendLink) TableName() string { return "friend_link" }
 
+type FriendLinkPost struct {
+	ID           int64     `gorm:"column:id;primaryKey"`
+	FriendLinkID int64     `gorm:"column:friend_link_id;not null"`
+	GUID         string    `gorm:"column:guid;size:512;not null"`
+	Title        string    `gorm:"column:title;size:512;not null"`
+	URL          string    `gorm:"column:url;size:512;not null"`
+	Summary      *string   `gorm:"column:summary"`
+	Author       *string   `gorm:"column:author;size:255"`
+	PublishedAt  time.Time `gorm:"column:published_at;not null"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (FriendLinkPost) TableName() string { return "friend_link_post" }
+
+type FriendLinkHealth struct {
+	FriendLinkID        int64      `gorm:"column:friend_link_id;primaryKey"`
+	StatusCode          *int       `gorm:"column:status_code"`
+	LatencyMs           *int       `gorm:"column:latency_ms"`
+	TLSExpiresAt        *time.Time `gorm:"column:tls_expires_at"`
+	HasBacklink         bool       `gorm:"column:has_backlink;not null"`
+	RSSStatusCode       *int       `gorm:"column:rss_status_code"`
+	ErrorMessage        *string    `gorm:"column:error_message"`
+	IsHealthy           bool       `gorm:"column:is_healthy;not null"`
+	ConsecutiveFailures int        `gorm:"column:consecutive_failures;not null"`
+	LastSuccessAt       *time.Time `gorm:"column:last_success_at"`
+	AutoDeactivated     bool       `gorm:"column:auto_deactivated;not null"`
+	CheckedAt           time.Time  `gorm:"column:checked_at;not null"`
+}
+
+func (FriendLinkHealth) TableName() string { return "friend_link_health" }
+
+type FriendLinkWebSub struct {
+	FriendLinkID  int64      `gorm:"column:friend_link_id;primaryKey"`
+	Hub           string     `gorm:"column:hub;size:1000;not null"`
+	Topic         string     `gorm:"column:topic;size:1000;not null"`
+	CallbackToken string     `gorm:"column:callback_token;size:64;not null"`
+	Secret        string     `gorm:"column:secret;size:128;not null"`
+	Status        string     `gorm:"column:status;size:20;not null"`
+	LeaseSeconds  *int       `gorm:"column:lease_seconds"`
+	ExpiresAt     *time.Time `gorm:"column:expires_at"`
+	LastPushAt    *time.Time `gorm:"column:last_push_at"`
+	Error         *string    `gorm:"column:error;type:text"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (FriendLinkWebSub) TableName() string { return "friend_link_websub" }
+
 type FriendLinkApplication struct {
 	ID                int64          `gorm:"column:id;primaryKey"`
 	Name              *string        `gorm:"column:name;size:255"`
@@ -46,6 +98,8 @@ type FriendLinkApplication struct {
 	UserID            *int64         `gorm:"column:user_id"`
 	Message           *string        `gorm:"column:message"`
 	Status            string         `gorm:"column:status;size:20"`
+	ReviewReason      *string        `gorm:"column:review_reason"`
+	ReviewedAt        *time.Time     `gorm:"column:reviewed_at"`
 	CreatedAt         time.Time      `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt         time.Time      `gorm:"column:updated_at;autoUpdateTime"`
 }
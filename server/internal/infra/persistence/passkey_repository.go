@@ -0,0 +1,139 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+type PasskeyRepository struct {
+	db *gorm.DB
+}
+
+func NewPasskeyRepository(db *gorm.DB) *PasskeyRepository {
+	return &PasskeyRepository{db: db}
+}
+
+func (r *PasskeyRepository) ListByUserID(ctx context.Context, userID int64) ([]identity.Passkey, error) {
+	var recs []model.UserPasskey
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id ASC").Find(&recs).Error; err != nil {
+		return nil, err
+	}
+	passkeys := make([]identity.Passkey, len(recs))
+	for i := range recs {
+		passkeys[i] = mapPasskeyToDomain(&recs[i])
+	}
+	return passkeys, nil
+}
+
+func (r *PasskeyRepository) FindByCredentialID(ctx context.Context, credentialID []byte) (*identity.Passkey, error) {
+	var rec model.UserPasskey
+	if err := r.db.WithContext(ctx).Where("credential_id = ?", credentialID).First(&rec).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identity.ErrPasskeyNotFound
+		}
+		return nil, err
+	}
+	passkey := mapPasskeyToDomain(&rec)
+	return &passkey, nil
+}
+
+func (r *PasskeyRepository) Create(ctx context.Context, passkey *identity.Passkey) error {
+	transports, err := json.Marshal(passkey.Transports)
+	if err != nil {
+		return err
+	}
+	if passkey.Transports == nil {
+		transports = []byte("[]")
+	}
+	rec := model.UserPasskey{
+		UserID:         passkey.UserID,
+		Name:           passkey.Name,
+		CredentialID:   passkey.CredentialID,
+		PublicKey:      passkey.PublicKey,
+		Algorithm:      passkey.Algorithm,
+		SignCount:      int64(passkey.SignCount),
+		AAGUID:         passkey.AAGUID,
+		Transports:     datatypes.JSON(transports),
+		BackupEligible: passkey.BackupEligible,
+		BackupState:    passkey.BackupState,
+	}
+	if err := r.db.WithContext(ctx).Create(&rec).Error; err != nil {
+		return err
+	}
+	passkey.ID = rec.ID
+	passkey.CreatedAt = rec.CreatedAt
+	passkey.UpdatedAt = rec.UpdatedAt
+	return nil
+}
+
+func (r *PasskeyRepository) UpdateSignCount(ctx context.Context, id int64, signCount uint32, backupState bool, usedAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserPasskey{}).
+		Where("id = ? AND (sign_count < ? OR (sign_count = 0 AND ? = 0))", id, int64(signCount), int64(signCount)).
+		Updates(map[string]any{
+			"sign_count":   int64(signCount),
+			"backup_state": backupState,
+			"last_used_at": usedAt,
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *PasskeyRepository) Rename(ctx context.Context, userID, id int64, name string) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserPasskey{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Updates(map[string]any{"name": name, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return identity.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func (r *PasskeyRepository) Delete(ctx context.Context, userID, id int64) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&model.UserPasskey{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return identity.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func mapPasskeyToDomain(rec *model.UserPasskey) identity.Passkey {
+	var transports []string
+	if len(rec.Transports) > 0 {
+		_ = json.Unmarshal(rec.Transports, &transports)
+	}
+	return identity.Passkey{
+		ID:             rec.ID,
+		UserID:         rec.UserID,
+		Name:           rec.Name,
+		CredentialID:   rec.CredentialID,
+		PublicKey:      rec.PublicKey,
+		Algorithm:      rec.Algorithm,
+		SignCount:      uint32(rec.SignCount),
+		AAGUID:         rec.AAGUID,
+		Transports:     transports,
+		BackupEligible: rec.BackupEligible,
+		BackupState:    rec.BackupState,
+		LastUsedAt:     rec.LastUsedAt,
+		CreatedAt:      rec.CreatedAt,
+		UpdatedAt:      rec.UpdatedAt,
+	}
+}
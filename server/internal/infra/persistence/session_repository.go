@@ -0,0 +1,155 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *identity.Session) error {
+	rec := model.UserSession{
+		SessionKey:       session.SessionKey,
+		UserID:           session.UserID,
+		RefreshTokenHash: session.RefreshTokenHash,
+		UserAgent:        session.UserAgent,
+		Browser:          session.Browser,
+		Platform:         session.Platform,
+		IP:               session.IP,
+		Location:         session.Location,
+		LastSeenAt:       session.LastSeenAt,
+		ExpiresAt:        session.ExpiresAt,
+	}
+	if err := r.db.WithContext(ctx).Create(&rec).Error; err != nil {
+		return err
+	}
+	session.ID = rec.ID
+	session.CreatedAt = rec.CreatedAt
+	return nil
+}
+
+func (r *SessionRepository) FindByKey(ctx context.Context, sessionKey string) (*identity.Session, error) {
+	var rec model.UserSession
+	if err := r.db.WithContext(ctx).Where("session_key = ?", sessionKey).First(&rec).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identity.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	session := mapSessionToDomain(&rec)
+	return &session, nil
+}
+
+func (r *SessionRepository) ListActive(ctx context.Context, userID int64, now time.Time) ([]identity.Session, error) {
+	var recs []model.UserSession
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").
+		Find(&recs).Error; err != nil {
+		return nil, err
+	}
+	sessions := make([]identity.Session, len(recs))
+	for i := range recs {
+		sessions[i] = mapSessionToDomain(&recs[i])
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) Rotate(ctx context.Context, id int64, oldHash, newHash string, update identity.SessionTouch) (bool, error) {
+	values := map[string]any{
+		"refresh_token_hash":  newHash,
+		"previous_token_hash": oldHash,
+		"last_seen_at":        update.LastSeenAt,
+		"expires_at":          update.ExpiresAt,
+	}
+	if update.IP != "" {
+		values["ip"] = update.IP
+		values["location"] = update.Location
+	}
+	result := r.db.WithContext(ctx).
+		Model(&model.UserSession{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", id, oldHash).
+		Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, userID, id int64, reason string) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return identity.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeByKey(ctx context.Context, sessionKey, reason string) error {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserSession{}).
+		Where("session_key = ? AND revoked_at IS NULL", sessionKey).
+		Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return identity.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeAll(ctx context.Context, userID int64, exceptKey, reason string) (int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&model.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptKey != "" {
+		query = query.Where("session_key <> ?", exceptKey)
+	}
+	result := query.Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason})
+	return result.RowsAffected, result.Error
+}
+
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ? OR revoked_at < ?", before, before).
+		Delete(&model.UserSession{})
+	return result.RowsAffected, result.Error
+}
+
+func mapSessionToDomain(rec *model.UserSession) identity.Session {
+	return identity.Session{
+		ID:                rec.ID,
+		SessionKey:        rec.SessionKey,
+		UserID:            rec.UserID,
+		RefreshTokenHash:  rec.RefreshTokenHash,
+		PreviousTokenHash: rec.PreviousTokenHash,
+		UserAgent:         rec.UserAgent,
+		Browser:           rec.Browser,
+		Platform:          rec.Platform,
+		IP:                rec.IP,
+		Location:          rec.Location,
+		CreatedAt:         rec.CreatedAt,
+		LastSeenAt:        rec.LastSeenAt,
+		ExpiresAt:         rec.ExpiresAt,
+		RevokedAt:         rec.RevokedAt,
+		RevokedReason:     rec.RevokedReason,
+	}
+}
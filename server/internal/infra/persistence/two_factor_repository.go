@@ -0,0 +1,110 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/identity"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+type TwoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+func (r *TwoFactorRepository) FindByUserID(ctx context.Context, userID int64) (*identity.TwoFactor, error) {
+	var rec model.UserTwoFactor
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&rec).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, identity.ErrTwoFactorNotSetup
+		}
+		return nil, err
+	}
+	return &identity.TwoFactor{
+		UserID:       rec.UserID,
+		Secret:       rec.Secret,
+		LastUsedStep: rec.LastUsedStep,
+		Enabled:      rec.Enabled,
+		EnabledAt:    rec.EnabledAt,
+		CreatedAt:    rec.CreatedAt,
+		UpdatedAt:    rec.UpdatedAt,
+	}, nil
+}
+
+func (r *TwoFactorRepository) Save(ctx context.Context, tf *identity.TwoFactor) error {
+	rec := model.UserTwoFactor{
+		UserID:       tf.UserID,
+		Secret:       tf.Secret,
+		LastUsedStep: tf.LastUsedStep,
+		Enabled:      tf.Enabled,
+		EnabledAt:    tf.EnabledAt,
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "last_used_step", "enabled", "enabled_at", "updated_at"}),
+	}).Create(&rec).Error
+}
+
+func (r *TwoFactorRepository) Delete(ctx context.Context, userID int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.UserTwoFactor{}).Error
+	})
+}
+
+func (r *TwoFactorRepository) MarkStepUsed(ctx context.Context, userID int64, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserTwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Updates(map[string]any{"last_used_step": step, "updated_at": time.Now()})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(hashes) == 0 {
+			return nil
+		}
+		records := make([]model.UserRecoveryCode, len(hashes))
+		for i, hash := range hashes {
+			records[i] = model.UserRecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&records).Error
+	})
+}
+
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *TwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,112 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+type UploadSessionRepository struct {
+	db   *gorm.DB
+	repo *GormRepository[model.UploadSession]
+}
+
+func NewUploadSessionRepository(db *gorm.DB) *UploadSessionRepository {
+	return &UploadSessionRepository{
+		db:   db,
+		repo: NewGormRepository[model.UploadSession](db),
+	}
+}
+
+func (r *UploadSessionRepository) Create(ctx context.Context, session *media.UploadSession) error {
+	rec := mapUploadSessionToModel(session)
+	if err := r.repo.Create(ctx, &rec); err != nil {
+		return err
+	}
+	session.CreatedAt = rec.CreatedAt
+	session.UpdatedAt = rec.UpdatedAt
+	return nil
+}
+
+func (r *UploadSessionRepository) FindByID(ctx context.Context, id string) (*media.UploadSession, error) {
+	rec, err := r.repo.First(ctx, "id = ?", id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, media.ErrUploadSessionNotFound
+		}
+		return nil, err
+	}
+	entity := mapUploadSessionToDomain(*rec)
+	return &entity, nil
+}
+
+func (r *UploadSessionRepository) MarkCompleted(ctx context.Context, id string, uploadID int64) error {
+	result := r.db.WithContext(ctx).Model(&model.UploadSession{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"upload_id": uploadID, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return media.ErrUploadSessionNotFound
+	}
+	return nil
+}
+
+func (r *UploadSessionRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.repo.DeleteWhere(ctx, "id = ?", id)
+	return err
+}
+
+func (r *UploadSessionRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]media.UploadSession, error) {
+	var records []model.UploadSession
+	if err := r.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	sessions := make([]media.UploadSession, len(records))
+	for i, rec := range records {
+		sessions[i] = mapUploadSessionToDomain(rec)
+	}
+	return sessions, nil
+}
+
+func mapUploadSessionToDomain(rec model.UploadSession) media.UploadSession {
+	return media.UploadSession{
+		ID:          rec.ID,
+		UserID:      rec.UserID,
+		Name:        rec.Name,
+		Type:        rec.Type,
+		Size:        rec.Size,
+		ChunkSize:   rec.ChunkSize,
+		TotalChunks: rec.TotalChunks,
+		Hash:        rec.Hash,
+		UploadID:    rec.UploadID,
+		ExpiresAt:   rec.ExpiresAt,
+		CreatedAt:   rec.CreatedAt,
+		UpdatedAt:   rec.UpdatedAt,
+	}
+}
+
+func mapUploadSessionToModel(session *media.UploadSession) model.UploadSession {
+	return model.UploadSession{
+		ID:          session.ID,
+		UserID:      session.UserID,
+		Name:        session.Name,
+		Type:        session.Type,
+		Size:        session.Size,
+		ChunkSize:   session.ChunkSize,
+		TotalChunks: session.TotalChunks,
+		Hash:        session.Hash,
+		UploadID:    session.UploadID,
+		ExpiresAt:   session.ExpiresAt,
+	}
+}
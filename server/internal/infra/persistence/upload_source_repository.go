@@ -0,0 +1,44 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+type UploadFileSourceRepository struct {
+	db *gorm.DB
+}
+
+func NewUploadFileSourceRepository(db *gorm.DB) *UploadFileSourceRepository {
+	return &UploadFileSourceRepository{db: db}
+}
+
+func (r *UploadFileSourceRepository) FindByURL(ctx context.Context, sourceURL string) (*media.UploadFile, error) {
+	var rec model.UploadFile
+	err := r.db.WithContext(ctx).
+		Joins("JOIN upload_file_source s ON s.upload_id = upload_file.id").
+		Where("s.source_url = ?", sourceURL).
+		First(&rec).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, media.ErrUploadFileNotFound
+		}
+		return nil, err
+	}
+	entity := mapUploadFileToDomain(rec)
+	return &entity, nil
+}
+
+func (r *UploadFileSourceRepository) Record(ctx context.Context, uploadID int64, sourceURL string) error {
+	rec := model.UploadFileSource{UploadID: uploadID, SourceURL: sourceURL}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source_url"}},
+		DoUpdates: clause.AssignmentColumns([]string{"upload_id"}),
+	}).Create(&rec).Error
+}
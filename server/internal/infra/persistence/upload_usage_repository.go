@@ -0,0 +1,155 @@
+package persistence
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/media"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+type UploadFileUsageRepository struct {
+	db *gorm.DB
+}
+
+func NewUploadFileUsageRepository(db *gorm.DB) *UploadFileUsageRepository {
+	return &UploadFileUsageRepository{db: db}
+}
+
+func (r *UploadFileUsageRepository) Replace(ctx context.Context, ownerType string, ownerID int64, uploadIDs []int64) error {
+	ids := slices.Clone(uploadIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+			Delete(&model.UploadFileUsage{}).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		records := make([]model.UploadFileUsage, len(ids))
+		for i, id := range ids {
+			records[i] = model.UploadFileUsage{UploadID: id, OwnerType: ownerType, OwnerID: ownerID}
+		}
+		return tx.Create(&records).Error
+	})
+}
+
+func (r *UploadFileUsageRepository) ReplaceOwnerType(ctx context.Context, ownerType string, refs map[int64][]int64) error {
+	var records []model.UploadFileUsage
+	for ownerID, uploadIDs := range refs {
+		ids := slices.Clone(uploadIDs)
+		slices.Sort(ids)
+		for _, id := range slices.Compact(ids) {
+			records = append(records, model.UploadFileUsage{UploadID: id, OwnerType: ownerType, OwnerID: ownerID})
+		}
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("owner_type = ?", ownerType).Delete(&model.UploadFileUsage{}).Error; err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		return tx.Create(&records).Error
+	})
+}
+
+func (r *UploadFileUsageRepository) ListReferenceSources(ctx context.Context) ([]media.UsageSource, error) {
+	const pattern = "%/uploads/%"
+	var users []model.User
+	if err := r.db.WithContext(ctx).Select("id", "avatar").
+		Where("avatar LIKE ?", pattern).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	var links []model.FriendLink
+	if err := r.db.WithContext(ctx).Select("id", "logo").
+		Where("logo LIKE ?", pattern).
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+	var infos []model.WebsiteInfo
+	if err := r.db.WithContext(ctx).Select("id", "value").
+		Where("value LIKE ?", pattern).
+		Find(&infos).Error; err != nil {
+		return nil, err
+	}
+
+	sources := make([]media.UsageSource, 0, len(users)+len(links)+len(infos))
+	for _, user := range users {
+		sources = append(sources, media.UsageSource{OwnerType: media.UsageOwnerUser, OwnerID: user.ID, Text: user.Avatar})
+	}
+	for _, link := range links {
+		sources = append(sources, media.UsageSource{OwnerType: media.UsageOwnerFriendLink, OwnerID: link.ID, Text: link.Logo})
+	}
+	for _, info := range infos {
+		sources = append(sources, media.UsageSource{OwnerType: media.UsageOwnerWebsiteInfo, OwnerID: info.ID, Text: info.Value})
+	}
+	return sources, nil
+}
+
+func (r *UploadFileUsageRepository) ListByUpload(ctx context.Context, uploadID int64) ([]media.Usage, error) {
+	var records []model.UploadFileUsage
+	if err := r.db.WithContext(ctx).
+		Where("upload_id = ?", uploadID).
+		Order("owner_type ASC").
+		Order("owner_id ASC").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	var photos []model.Photo
+	if err := r.db.WithContext(ctx).
+		Select("id", "created_at").
+		Where("upload_id = ?", uploadID).
+		Order("id ASC").
+		Find(&photos).Error; err != nil {
+		return nil, err
+	}
+	usages := make([]media.Usage, 0, len(records)+len(photos))
+	for _, rec := range records {
+		usages = append(usages, media.Usage{
+			UploadID:  rec.UploadID,
+			OwnerType: rec.OwnerType,
+			OwnerID:   rec.OwnerID,
+			CreatedAt: rec.CreatedAt,
+		})
+	}
+	for _, photo := range photos {
+		usages = append(usages, media.Usage{
+			UploadID:  uploadID,
+			OwnerType: media.UsageOwnerPhoto,
+			OwnerID:   photo.ID,
+			CreatedAt: photo.CreatedAt,
+		})
+	}
+	return usages, nil
+}
+
+func (r *UploadFileUsageRepository) ListOrphans(ctx context.Context, before time.Time, offset int, limit int) ([]media.UploadFile, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.UploadFile{}).
+		Where("created_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM upload_file_usage u WHERE u.upload_id = upload_file.id)").
+		Where("NOT EXISTS (SELECT 1 FROM photo p WHERE p.upload_id = upload_file.id)")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var records []model.UploadFile
+	if err := query.Order("created_at ASC").Order("id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+	files := make([]media.UploadFile, len(records))
+	for i, rec := range records {
+		files[i] = mapUploadFileToDomain(rec)
+	}
+	return files, total, nil
+}
@@ -0,0 +1,211 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+// WebmentionRepository stores received Webmentions.
+type WebmentionRepository struct {
+	db   *gorm.DB
+	repo *GormRepository[model.Webmention]
+}
+
+func NewWebmentionRepository(db *gorm.DB) *WebmentionRepository {
+	return &WebmentionRepository{
+		db:   db,
+		repo: NewGormRepository[model.Webmention](db),
+	}
+}
+
+func (r *WebmentionRepository) Upsert(ctx context.Context, mention *federation.Webmention) error {
+	rec := mapWebmentionToModel(mention)
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source"}, {Name: "target"}},
+		DoUpdates: clause.AssignmentColumns([]string{"target_type", "target_id", "status", "error", "updated_at"}),
+	}).Create(&rec).Error; err != nil {
+		return err
+	}
+	// ON CONFLICT 更新时 RETURNING 只带回部分列，重新读取完整记录。
+	stored, err := r.repo.First(ctx, "source = ? AND target = ?", mention.Source, mention.Target)
+	if err != nil {
+		return err
+	}
+	*mention = mapWebmentionToDomain(*stored)
+	return nil
+}
+
+func (r *WebmentionRepository) GetByID(ctx context.Context, id int64) (*federation.Webmention, error) {
+	rec, err := r.repo.FirstByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, federation.ErrWebmentionNotFound
+		}
+		return nil, err
+	}
+	mention := mapWebmentionToDomain(*rec)
+	return &mention, nil
+}
+
+func (r *WebmentionRepository) Update(ctx context.Context, mention *federation.Webmention) error {
+	rec := mapWebmentionToModel(mention)
+	return r.db.WithContext(ctx).Save(&rec).Error
+}
+
+func (r *WebmentionRepository) Delete(ctx context.Context, id int64) error {
+	affected, err := r.repo.DeleteWhere(ctx, "id = ?", id)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return federation.ErrWebmentionNotFound
+	}
+	return nil
+}
+
+func (r *WebmentionRepository) List(ctx context.Context, options federation.WebmentionListOptions) ([]federation.Webmention, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.Webmention{})
+	if options.Status != nil && *options.Status != "" {
+		query = query.Where("status = ?", *options.Status)
+	}
+	if options.TargetType != nil && *options.TargetType != "" {
+		query = query.Where("target_type = ?", *options.TargetType)
+	}
+	if options.TargetID != nil {
+		query = query.Where("target_id = ?", *options.TargetID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (options.Page - 1) * options.PageSize
+	var recs []model.Webmention
+	if err := query.Order("created_at DESC").
+		Order("id DESC").
+		Offset(offset).
+		Limit(options.PageSize).
+		Find(&recs).Error; err != nil {
+		return nil, 0, err
+	}
+	result := make([]federation.Webmention, len(recs))
+	for i, rec := range recs {
+		result[i] = mapWebmentionToDomain(rec)
+	}
+	return result, total, nil
+}
+
+func mapWebmentionToModel(mention *federation.Webmention) model.Webmention {
+	return model.Webmention{
+		ID:         mention.ID,
+		Source:     mention.Source,
+		Target:     mention.Target,
+		TargetType: mention.TargetType,
+		TargetID:   mention.TargetID,
+		Status:     mention.Status,
+		AuthorName: mention.AuthorName,
+		AuthorURL:  mention.AuthorURL,
+		Title:      mention.Title,
+		Content:    mention.Content,
+		CommentID:  mention.CommentID,
+		Error:      mention.Error,
+		VerifiedAt: mention.VerifiedAt,
+		CreatedAt:  mention.CreatedAt,
+		UpdatedAt:  mention.UpdatedAt,
+	}
+}
+
+func mapWebmentionToDomain(rec model.Webmention) federation.Webmention {
+	return federation.Webmention{
+		ID:         rec.ID,
+		Source:     rec.Source,
+		Target:     rec.Target,
+		TargetType: rec.TargetType,
+		TargetID:   rec.TargetID,
+		Status:     rec.Status,
+		AuthorName: rec.AuthorName,
+		AuthorURL:  rec.AuthorURL,
+		Title:      rec.Title,
+		Content:    rec.Content,
+		CommentID:  rec.CommentID,
+		Error:      rec.Error,
+		VerifiedAt: rec.VerifiedAt,
+		CreatedAt:  rec.CreatedAt,
+		UpdatedAt:  rec.UpdatedAt,
+	}
+}
+
+// WebmentionSendRepository stores per-link outbound Webmention status.
+type WebmentionSendRepository struct {
+	db   *gorm.DB
+	repo *GormRepository[model.WebmentionSend]
+}
+
+func NewWebmentionSendRepository(db *gorm.DB) *WebmentionSendRepository {
+	return &WebmentionSendRepository{
+		db:   db,
+		repo: NewGormRepository[model.WebmentionSend](db),
+	}
+}
+
+func (r *WebmentionSendRepository) Upsert(ctx context.Context, send *federation.WebmentionSend) error {
+	rec := model.WebmentionSend{
+		ArticleID:   send.ArticleID,
+		Source:      send.Source,
+		Target:      send.Target,
+		Endpoint:    send.Endpoint,
+		Status:      send.Status,
+		StatusCode:  send.StatusCode,
+		Error:       send.Error,
+		ContentHash: send.ContentHash,
+		SentAt:      send.SentAt,
+	}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "article_id"}, {Name: "target"}},
+		DoUpdates: clause.AssignmentColumns([]string{"source", "endpoint", "status", "status_code", "error", "content_hash", "sent_at", "updated_at"}),
+	}).Create(&rec).Error; err != nil {
+		return err
+	}
+	send.ID = rec.ID
+	send.UpdatedAt = rec.UpdatedAt
+	return nil
+}
+
+func (r *WebmentionSendRepository) ListByArticle(ctx context.Context, articleID int64) ([]federation.WebmentionSend, error) {
+	recs, err := r.repo.List(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("article_id = ?", articleID).Order("id ASC")
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]federation.WebmentionSend, len(recs))
+	for i, rec := range recs {
+		result[i] = federation.WebmentionSend{
+			ID:          rec.ID,
+			ArticleID:   rec.ArticleID,
+			Source:      rec.Source,
+			Target:      rec.Target,
+			Endpoint:    rec.Endpoint,
+			Status:      rec.Status,
+			StatusCode:  rec.StatusCode,
+			Error:       rec.Error,
+			ContentHash: rec.ContentHash,
+			SentAt:      rec.SentAt,
+			CreatedAt:   rec.CreatedAt,
+			UpdatedAt:   rec.UpdatedAt,
+		}
+	}
+	return result, nil
+}
+
+func (r *WebmentionSendRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.repo.DeleteWhere(ctx, "id = ?", id)
+	return err
+}
@@ -0,0 +1,138 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/domain/federation"
+	"github.com/grtsinry43/grtblog-v2/server/internal/infra/persistence/model"
+)
+
+// WebSubSubscriptionRepository stores subscriptions of the built-in WebSub hub.
+type WebSubSubscriptionRepository struct {
+	db   *gorm.DB
+	repo *GormRepository[model.WebSubSubscription]
+}
+
+func NewWebSubSubscriptionRepository(db *gorm.DB) *WebSubSubscriptionRepository {
+	return &WebSubSubscriptionRepository{
+		db:   db,
+		repo: NewGormRepository[model.WebSubSubscription](db),
+	}
+}
+
+func (r *WebSubSubscriptionRepository) Upsert(ctx context.Context, sub *federation.WebSubSubscription) error {
+	rec := model.WebSubSubscription{
+		Topic:        sub.Topic,
+		Callback:     sub.Callback,
+		Secret:       sub.Secret,
+		LeaseSeconds: sub.LeaseSeconds,
+		ExpiresAt:    sub.ExpiresAt,
+	}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "topic"}, {Name: "callback"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "lease_seconds", "expires_at", "updated_at"}),
+	}).Create(&rec).Error; err != nil {
+		return err
+	}
+	stored, err := r.repo.First(ctx, "topic = ? AND callback = ?", sub.Topic, sub.Callback)
+	if err != nil {
+		return err
+	}
+	*sub = mapWebSubSubscriptionToDomain(*stored)
+	return nil
+}
+
+func (r *WebSubSubscriptionRepository) DeleteByTopicCallback(ctx context.Context, topic string, callback string) error {
+	_, err := r.repo.DeleteWhere(ctx, "topic = ? AND callback = ?", topic, callback)
+	return err
+}
+
+func (r *WebSubSubscriptionRepository) Delete(ctx context.Context, id int64) error {
+	affected, err := r.repo.DeleteWhere(ctx, "id = ?", id)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return federation.ErrWebSubNotFound
+	}
+	return nil
+}
+
+func (r *WebSubSubscriptionRepository) ListActiveByTopic(ctx context.Context, topic string, now time.Time) ([]federation.WebSubSubscription, error) {
+	recs, err := r.repo.List(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("topic = ? AND expires_at > ?", topic, now).Order("id ASC")
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]federation.WebSubSubscription, len(recs))
+	for i, rec := range recs {
+		result[i] = mapWebSubSubscriptionToDomain(rec)
+	}
+	return result, nil
+}
+
+func (r *WebSubSubscriptionRepository) List(ctx context.Context, options federation.WebSubSubscriptionListOptions) ([]federation.WebSubSubscription, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.WebSubSubscription{})
+	if options.Topic != nil && *options.Topic != "" {
+		query = query.Where("topic = ?", *options.Topic)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (options.Page - 1) * options.PageSize
+	var recs []model.WebSubSubscription
+	if err := query.Order("created_at DESC").
+		Order("id DESC").
+		Offset(offset).
+		Limit(options.PageSize).
+		Find(&recs).Error; err != nil {
+		return nil, 0, err
+	}
+	result := make([]federation.WebSubSubscription, len(recs))
+	for i, rec := range recs {
+		result[i] = mapWebSubSubscriptionToDomain(rec)
+	}
+	return result, total, nil
+}
+
+func (r *WebSubSubscriptionRepository) RecordDelivery(ctx context.Context, id int64, at time.Time, deliveryErr *string) error {
+	updates := map[string]any{
+		"last_error": deliveryErr,
+		"updated_at": at,
+	}
+	if deliveryErr == nil {
+		updates["last_delivered_at"] = at
+		updates["failure_count"] = 0
+	} else {
+		updates["failure_count"] = gorm.Expr("failure_count + 1")
+	}
+	return r.db.WithContext(ctx).Model(&model.WebSubSubscription{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *WebSubSubscriptionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return r.repo.DeleteWhere(ctx, "expires_at <= ?", before)
+}
+
+func mapWebSubSubscriptionToDomain(rec model.WebSubSubscription) federation.WebSubSubscription {
+	return federation.WebSubSubscription{
+		ID:              rec.ID,
+		Topic:           rec.Topic,
+		Callback:        rec.Callback,
+		Secret:          rec.Secret,
+		LeaseSeconds:    rec.LeaseSeconds,
+		ExpiresAt:       rec.ExpiresAt,
+		LastDeliveredAt: rec.LastDeliveredAt,
+		LastError:       rec.LastError,
+		FailureCount:    rec.FailureCount,
+		CreatedAt:       rec.CreatedAt,
+		UpdatedAt:       rec.UpdatedAt,
+	}
+}
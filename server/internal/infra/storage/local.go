@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LocalDriver 将对象保存在本地目录，仅适用于单实例部署。
+type LocalDriver struct {
+	root   string
+	signer *URLSigner
+}
+
+func NewLocalDriver(root string, signer *URLSigner) *LocalDriver {
+	if root == "" {
+		root = filepath.Join("storage", "uploads")
+	}
+	return &LocalDriver{root: filepath.Clean(root), signer: signer}
+}
+
+func (d *LocalDriver) Name() string { return DriverLocal }
+
+// Root 返回存储根目录。
+func (d *LocalDriver) Root() string { return d.root }
+
+// Signer 返回本地签名地址的签名器。
+func (d *LocalDriver) Signer() *URLSigner { return d.signer }
+
+// DiskPath 返回对象在磁盘上的路径。
+func (d *LocalDriver) DiskPath(key string) (string, error) {
+	clean, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	// 兼容早期记录中带存储根目录前缀的路径，例如 "/storage/uploads/pictures/a.jpg"。
+	clean = strings.TrimPrefix(clean, filepath.ToSlash(d.root)+"/")
+	return filepath.Join(d.root, filepath.FromSlash(clean)), nil
+}
+
+func (d *LocalDriver) Put(_ context.Context, key string, body io.Reader, _ int64, _ string) error {
+	path, err := d.DiskPath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// 先写临时文件再改名，避免并发读取到写了一半的文件。
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (d *LocalDriver) Open(_ context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	path, err := d.DiskPath(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrObjectNotFound
+		}
+		return nil, nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, localInfo(key, stat), nil
+}
+
+func (d *LocalDriver) Stat(_ context.Context, key string) (*ObjectInfo, error) {
+	path, err := d.DiskPath(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	if stat.IsDir() {
+		return nil, ErrObjectNotFound
+	}
+	return localInfo(key, stat), nil
+}
+
+func (d *LocalDriver) Delete(_ context.Context, key string) error {
+	path, err := d.DiskPath(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List 遍历存储目录下的文件，跳过写入中的临时文件与隐藏文件。
+func (d *LocalDriver) List(ctx context.Context, fn func(ObjectInfo) error) error {
+	err := filepath.WalkDir(d.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() && path != d.root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		stat, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(d.root, path)
+		if err != nil {
+			return err
+		}
+		return fn(*localInfo(filepath.ToSlash(rel), stat))
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (d *LocalDriver) SignedURL(_ context.Context, key string, ttl time.Duration) (string, error) {
+	clean, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return d.signer.URL(clean, time.Now().Add(clampTTL(ttl))), nil
+}
+
+func localInfo(key string, stat os.FileInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Key:         key,
+		Size:        stat.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+		ModTime:     stat.ModTime(),
+	}
+}
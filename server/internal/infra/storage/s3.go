@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/config"
+)
+
+// S3Driver 通过 S3 REST 接口读写对象，多副本部署共享同一存储桶。
+type S3Driver struct {
+	endpoint      *url.URL
+	bucket        string
+	pathStyle     bool
+	publicBaseURL string
+	signer        sigV4
+	client        *http.Client
+	now           func() time.Time
+}
+
+func NewS3Driver(cfg config.S3Config, client *http.Client) (*S3Driver, error) {
+	if strings.TrimSpace(cfg.Bucket) == "" {
+		return nil, errors.New("s3 bucket is required")
+	}
+	if strings.TrimSpace(cfg.AccessKey) == "" || strings.TrimSpace(cfg.SecretKey) == "" {
+		return nil, errors.New("s3 credentials are required")
+	}
+	raw := strings.TrimSpace(cfg.Endpoint)
+	if raw == "" {
+		raw = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(strings.TrimRight(raw, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid s3 endpoint: %s", raw)
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	return &S3Driver{
+		endpoint:      endpoint,
+		bucket:        cfg.Bucket,
+		pathStyle:     cfg.PathStyle,
+		publicBaseURL: strings.TrimRight(cfg.PublicBaseURL, "/"),
+		signer: sigV4{
+			accessKey: cfg.AccessKey,
+			secretKey: cfg.SecretKey,
+			region:    region,
+			service:   "s3",
+		},
+		client: client,
+		now:    time.Now,
+	}, nil
+}
+
+func (d *S3Driver) Name() string { return DriverS3 }
+
+// PublicURL 返回配置的公开访问地址（CDN 或公开读存储桶），未配置时为空。
+func (d *S3Driver) PublicURL(key string) string {
+	if d.publicBaseURL == "" {
+		return ""
+	}
+	return d.publicBaseURL + "/" + uriEncode(key, false)
+}
+
+func (d *S3Driver) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		return errors.New("s3 put requires object size")
+	}
+	req, err := d.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := d.do(req, unsignedPayload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+func (d *S3Driver) Open(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	req, err := d.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := d.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, nil, s3Error(resp)
+	}
+	return resp.Body, s3Info(key, resp), nil
+}
+
+func (d *S3Driver) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	req, err := d.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp)
+	}
+	return s3Info(key, resp), nil
+}
+
+func (d *S3Driver) Delete(ctx context.Context, key string) error {
+	req, err := d.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := d.do(req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return s3Error(resp)
+	}
+}
+
+func (d *S3Driver) SignedURL(_ context.Context, key string, ttl time.Duration) (string, error) {
+	target, err := d.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	return d.signer.presign(http.MethodGet, target, d.now(), clampTTL(ttl)).String(), nil
+}
+
+// objectURL 按 path-style（endpoint/bucket/key，MinIO 默认）或 virtual-hosted（bucket.endpoint/key）拼接对象地址。
+func (d *S3Driver) objectURL(key string) (*url.URL, error) {
+	clean, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	target := *d.endpoint
+	basePath := strings.TrimRight(target.Path, "/")
+	if d.pathStyle {
+		target.Path = basePath + "/" + d.bucket + "/" + clean
+	} else {
+		target.Host = d.bucket + "." + target.Host
+		target.Path = basePath + "/" + clean
+	}
+	target.RawPath = uriEncode(target.Path, false)
+	return &target, nil
+}
+
+func (d *S3Driver) newRequest(ctx context.Context, method string, key string, body io.Reader) (*http.Request, error) {
+	target, err := d.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, method, target.String(), body)
+}
+
+func (d *S3Driver) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	d.signer.sign(req, payloadHash, d.now())
+	return d.client.Do(req)
+}
+
+func s3Info(key string, resp *http.Response) *ObjectInfo {
+	info := &ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        resp.Header.Get("ETag"),
+	}
+	if raw := resp.Header.Get("Content-Length"); info.Size < 0 && raw != "" {
+		info.Size, _ = strconv.ParseInt(raw, 10, 64)
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = modified
+	}
+	return info
+}
+
+func s3Error(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrObjectNotFound
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("s3 request failed: %s %s", resp.Status, strings.TrimSpace(string(raw)))
+}
@@ -0,0 +1,89 @@
+// Package storage 提供上传文件的存储驱动：本地磁盘与 S3 兼容对象存储（AWS S3、MinIO、R2 等）。
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/config"
+)
+
+// 驱动名称，同时作为 UploadFile.Path 的前缀。
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// MaxSignedURLTTL 签名地址最长有效期（S3 预签名上限为 7 天）。
+const MaxSignedURLTTL = 7 * 24 * time.Hour
+
+var (
+	ErrObjectNotFound = errors.New("storage object not found")
+	ErrInvalidKey     = errors.New("invalid storage key")
+)
+
+// ObjectInfo 对象元数据。
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ETag        string
+	ModTime     time.Time
+}
+
+// Driver 存储驱动，key 为不带前导斜杠的相对路径，例如 "pictures/2024-01-01-12:00:00-ab12.jpg"。
+type Driver interface {
+	Name() string
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL 返回限时访问地址，私有存储下也可直接访问。
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Lister 可选接口：遍历驱动上的全部对象，用于找出没有数据库记录的文件。
+type Lister interface {
+	List(ctx context.Context, fn func(ObjectInfo) error) error
+}
+
+// New 按配置创建驱动；local 驱动的签名地址由 /uploads 路由校验。
+func New(cfg config.StorageConfig, driver string) (Driver, error) {
+	switch strings.ToLower(strings.TrimSpace(driver)) {
+	case "", DriverLocal:
+		return NewLocalDriver(cfg.LocalDir, NewURLSigner(cfg.SignSecret, "/uploads")), nil
+	case DriverS3:
+		return NewS3Driver(cfg.S3, &http.Client{Timeout: 60 * time.Second})
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", driver)
+	}
+}
+
+// CleanKey 规范化对象键并拒绝越出存储根目录的路径。
+func CleanKey(key string) (string, error) {
+	trimmed := strings.Trim(strings.TrimSpace(key), "/")
+	if trimmed == "" {
+		return "", ErrInvalidKey
+	}
+	for _, part := range strings.Split(trimmed, "/") {
+		if part == "" || part == "." || part == ".." || strings.Contains(part, "\\") {
+			return "", ErrInvalidKey
+		}
+	}
+	return trimmed, nil
+}
+
+func clampTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return 15 * time.Minute
+	}
+	if ttl > MaxSignedURLTTL {
+		return MaxSignedURLTTL
+	}
+	return ttl
+}
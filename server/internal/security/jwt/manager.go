@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
@@ -18,10 +19,14 @@ var (
 	ErrInvalidToken = errors.New("invalid token")
 	// ErrExpiredToken 表示 token 已超出过期时间。
 	ErrExpiredToken = errors.New("token expired")
+	// ErrRevokedToken 表示 token 对应的会话已被吊销或不存在。
+	ErrRevokedToken = errors.New("token revoked")
 )
 
 // Claims 表示 JWT 的载荷内容。
 type Claims struct {
+	// ID 为登录会话的标识（jti），用于吊销尚未过期的 token。
+	ID        string `json:"jti,omitempty"`
 	Subject   string `json:"sub,omitempty"`
 	UserID    int64  `json:"uid"`
 	IsAdmin   bool   `json:"isAdmin"`
@@ -30,11 +35,17 @@ type Claims struct {
 	ExpiresAt int64  `json:"exp"`
 }
 
+// SessionChecker 校验 token 所属会话仍然有效，会话失效时返回 ErrRevokedToken。
+type SessionChecker interface {
+	CheckSession(ctx context.Context, claims *Claims) error
+}
+
 // Manager 负责签发、解析与校验 JWT。
 type Manager struct {
-	secret []byte
-	issuer string
-	ttl    time.Duration
+	secret   []byte
+	issuer   string
+	ttl      time.Duration
+	sessions SessionChecker
 }
 
 func NewManager(cfg config.AuthConfig) *Manager {
@@ -45,10 +56,22 @@ func NewManager(cfg config.AuthConfig) *Manager {
 	}
 }
 
-// Generate 针对指定用户签发 token。
-func (m *Manager) Generate(userID int64, isAdmin bool) (string, *Claims, error) {
+// WithSessionChecker 设置会话校验，设置后不含 jti 的 token 视为无效。
+func (m *Manager) WithSessionChecker(checker SessionChecker) *Manager {
+	m.sessions = checker
+	return m
+}
+
+// TTL 返回 access token 的有效期。
+func (m *Manager) TTL() time.Duration {
+	return m.ttl
+}
+
+// Generate 针对指定用户签发 token，sessionID 写入 jti。
+func (m *Manager) Generate(userID int64, isAdmin bool, sessionID string) (string, *Claims, error) {
 	now := time.Now()
 	claims := &Claims{
+		ID:        sessionID,
 		UserID:    userID,
 		IsAdmin:   isAdmin,
 		Issuer:    m.issuer,
@@ -94,6 +117,17 @@ func (m *Manager) Parse(token string) (*Claims, error) {
 	return &claims, nil
 }
 
+// CheckSession 校验 token 所属会话，未设置 SessionChecker 时直接通过。
+func (m *Manager) CheckSession(ctx context.Context, claims *Claims) error {
+	if m.sessions == nil {
+		return nil
+	}
+	if claims.ID == "" {
+		return ErrRevokedToken
+	}
+	return m.sessions.CheckSession(ctx, claims)
+}
+
 func (m *Manager) sign(claims *Claims) (string, error) {
 	headerJSON := `{"alg":"HS256","typ":"JWT"}`
 	header := base64.RawURLEncoding.EncodeToString([]byte(headerJSON))
@@ -0,0 +1,74 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grtsinry43/grtblog-v2/server/internal/config"
+)
+
+type stubChecker struct {
+	revoked map[string]bool
+}
+
+func (s stubChecker) CheckSession(_ context.Context, claims *Claims) error {
+	if s.revoked[claims.ID] {
+		return ErrRevokedToken
+	}
+	return nil
+}
+
+func newTestManager() *Manager {
+	return NewManager(config.AuthConfig{Secret: "test-secret", Issuer: "test", AccessTTL: time.Minute})
+}
+
+func TestGenerateCarriesSessionID(t *testing.T) {
+	m := newTestManager()
+	token, _, err := m.Generate(1, true, "session-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := m.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if claims.ID != "session-a" || claims.UserID != 1 || !claims.IsAdmin {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestCheckSession(t *testing.T) {
+	m := newTestManager()
+	if err := m.CheckSession(context.Background(), &Claims{UserID: 1}); err != nil {
+		t.Fatalf("without checker: got %v", err)
+	}
+
+	m.WithSessionChecker(stubChecker{revoked: map[string]bool{"session-b": true}})
+	cases := []struct {
+		id   string
+		want error
+	}{
+		{"session-a", nil},
+		{"session-b", ErrRevokedToken},
+		{"", ErrRevokedToken},
+	}
+	for _, tc := range cases {
+		if err := m.CheckSession(context.Background(), &Claims{ID: tc.id, UserID: 1}); !errors.Is(err, tc.want) {
+			t.Errorf("CheckSession(%q) = %v, want %v", tc.id, err, tc.want)
+		}
+	}
+}
+
+func TestParseRejectsTamperedToken(t *testing.T) {
+	m := newTestManager()
+	token, _, err := m.Generate(1, false, "session-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewManager(config.AuthConfig{Secret: "other-secret", Issuer: "test", AccessTTL: time.Minute})
+	if _, err := other.Parse(token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("got %v, want ErrInvalidToken", err)
+	}
+}
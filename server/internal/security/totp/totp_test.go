@@ -0,0 +1,71 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录 B 的 SHA1 测试向量，密钥为 ASCII "12345678901234567890"，取后 6 位。
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tc := range cases {
+		got, err := Code(rfcSecret, Step(time.Unix(tc.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d): %v", tc.unix, err)
+		}
+		if got != tc.want {
+			t.Errorf("Code(%d) = %s, want %s", tc.unix, got, tc.want)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	prev, _ := Code(rfcSecret, Step(now)-1)
+	if step, ok := Validate(rfcSecret, prev, now); !ok || step != Step(now)-1 {
+		t.Fatalf("previous step code rejected: step=%d ok=%v", step, ok)
+	}
+	old, _ := Code(rfcSecret, Step(now)-2)
+	if _, ok := Validate(rfcSecret, old, now); ok {
+		t.Fatal("code two steps old should be rejected")
+	}
+	if _, ok := Validate(rfcSecret, "12345", now); ok {
+		t.Fatal("short code should be rejected")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("My Blog", "admin", rfcSecret)
+	if !strings.HasPrefix(uri, "otpauth://totp/My%20Blog:admin?") {
+		t.Fatalf("unexpected uri prefix: %s", uri)
+	}
+	for _, part := range []string{"secret=" + rfcSecret, "issuer=My+Blog", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("uri %s missing %s", uri, part)
+		}
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 {
+		t.Fatalf("secret length = %d, want 32", len(secret))
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Fatalf("generated secret not decodable: %v", err)
+	}
+}
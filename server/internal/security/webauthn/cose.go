@@ -0,0 +1,112 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+)
+
+// COSE 算法标识（RFC 9053），按偏好排列。
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// SupportedAlgorithms 是注册时声明接受的算法。
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE_Key 参数标签。
+const (
+	coseKty    int64 = 1
+	coseAlg    int64 = 3
+	coseCrv    int64 = -1
+	coseX      int64 = -2
+	coseY      int64 = -3
+	coseRSAN   int64 = -1
+	coseRSAE   int64 = -2
+	ktyOKP     int64 = 1
+	ktyEC2     int64 = 2
+	ktyRSA     int64 = 3
+	crvP256    int64 = 1
+	crvEd25519 int64 = 6
+)
+
+// parsePublicKey 解析 COSE 编码的公钥，返回公钥与算法。
+func parsePublicKey(coseKey []byte) (crypto.PublicKey, int64, error) {
+	item, _, err := decodeCBOR(coseKey)
+	if err != nil {
+		return nil, 0, ErrMalformed
+	}
+	params, ok := item.(map[any]any)
+	if !ok {
+		return nil, 0, ErrMalformed
+	}
+	kty, _ := params[coseKty].(int64)
+	alg, _ := params[coseAlg].(int64)
+
+	switch {
+	case kty == ktyEC2 && alg == AlgES256:
+		crv, _ := params[coseCrv].(int64)
+		x, _ := params[coseX].([]byte)
+		y, _ := params[coseY].([]byte)
+		if crv != crvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, ErrMalformed
+		}
+		// 借助 ecdh 校验点在曲线上。
+		point := append(append([]byte{0x04}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, 0, ErrMalformed
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, alg, nil
+	case kty == ktyOKP && alg == AlgEdDSA:
+		crv, _ := params[coseCrv].(int64)
+		x, _ := params[coseX].([]byte)
+		if crv != crvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, ErrMalformed
+		}
+		return ed25519.PublicKey(x), alg, nil
+	case kty == ktyRSA && alg == AlgRS256:
+		n, _ := params[coseRSAN].([]byte)
+		e, _ := params[coseRSAE].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, 0, ErrMalformed
+		}
+		exponent := int(new(big.Int).SetBytes(e).Int64())
+		if exponent < 3 {
+			return nil, 0, ErrMalformed
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}, alg, nil
+	}
+	return nil, 0, ErrUnsupportedAlgorithm
+}
+
+// verifySignature 使用 COSE 公钥校验签名。
+func verifySignature(coseKey, data, signature []byte) error {
+	key, _, err := parsePublicKey(coseKey)
+	if err != nil {
+		return err
+	}
+	switch pub := key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		if ecdsa.VerifyASN1(pub, digest[:], signature) {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(pub, data, signature) {
+			return nil
+		}
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) == nil {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
@@ -0,0 +1,265 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// 实现 WebAuthn Level 2 中依赖方需要的注册与断言校验。
+// 注册时声明 attestation 为 none，不校验证明声明（attStmt），只信任认证器数据中的公钥。
+
+var (
+	ErrMalformed            = errors.New("malformed webauthn data")
+	ErrCeremonyType         = errors.New("unexpected client data type")
+	ErrChallengeMismatch    = errors.New("challenge mismatch")
+	ErrOriginMismatch       = errors.New("origin not allowed")
+	ErrRPIDMismatch         = errors.New("rp id hash mismatch")
+	ErrUserNotPresent       = errors.New("user presence flag not set")
+	ErrUserNotVerified      = errors.New("user verification flag not set")
+	ErrUnsupportedAlgorithm = errors.New("unsupported public key algorithm")
+	ErrInvalidSignature     = errors.New("invalid signature")
+)
+
+// 认证器数据标志位。
+const (
+	FlagUserPresent    byte = 0x01
+	FlagUserVerified   byte = 0x04
+	FlagBackupEligible byte = 0x08
+	FlagBackupState    byte = 0x10
+	FlagAttestedData   byte = 0x40
+	FlagExtensionData  byte = 0x80
+)
+
+// ChallengeSize 是随机挑战的字节数，规范要求至少 16 字节。
+const ChallengeSize = 32
+
+// maxCredentialIDLength 是规范允许的凭据 ID 最大长度。
+const maxCredentialIDLength = 1023
+
+// RelyingParty 描述依赖方：ID 为注册域名（不含协议与端口），Origins 为允许发起仪式的页面来源。
+type RelyingParty struct {
+	ID      string
+	Name    string
+	Origins []string
+}
+
+// ClientData 是浏览器生成的 clientDataJSON。
+type ClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin,omitempty"`
+}
+
+// AuthenticatorData 是解析后的认证器数据；仅注册时包含凭据信息。
+type AuthenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+// UserVerified 表示认证器完成了用户验证（PIN、生物识别等）。
+func (d *AuthenticatorData) UserVerified() bool { return d.Flags&FlagUserVerified != 0 }
+
+// BackupEligible 表示凭据可在设备间同步。
+func (d *AuthenticatorData) BackupEligible() bool { return d.Flags&FlagBackupEligible != 0 }
+
+// BackupState 表示凭据当前已同步备份。
+func (d *AuthenticatorData) BackupState() bool { return d.Flags&FlagBackupState != 0 }
+
+// Credential 是注册成功后需要保存的凭据。
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte
+	Algorithm      int64
+	SignCount      uint32
+	AAGUID         []byte
+	UserVerified   bool
+	BackupEligible bool
+	BackupState    bool
+}
+
+// NewChallenge 生成随机挑战。
+func NewChallenge() ([]byte, error) {
+	b := make([]byte, ChallengeSize)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// DecodeBase64URL 解码浏览器传回的 base64url 字段，兼容带填充与标准 base64 的写法。
+func DecodeBase64URL(value string) ([]byte, error) {
+	value = strings.TrimRight(strings.TrimSpace(value), "=")
+	value = strings.NewReplacer("+", "-", "/", "_").Replace(value)
+	return base64.RawURLEncoding.DecodeString(value)
+}
+
+// SignCountValid 判断签名计数是否合法：计数必须严格递增；双方都为 0 表示认证器不支持计数（如同步通行密钥）。
+// 计数回退通常意味着凭据被复制。
+func SignCountValid(stored, received uint32) bool {
+	if stored == 0 && received == 0 {
+		return true
+	}
+	return received > stored
+}
+
+// VerifyRegistration 校验 navigator.credentials.create 的结果并返回新凭据。
+func (rp RelyingParty) VerifyRegistration(challenge, clientDataJSON, attestationObject []byte, requireUserVerification bool) (*Credential, error) {
+	if err := rp.verifyClientData(clientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+	item, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	attestation, ok := item.(map[any]any)
+	if !ok {
+		return nil, ErrMalformed
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, ErrMalformed
+	}
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData, requireUserVerification); err != nil {
+		return nil, err
+	}
+	if authData.Flags&FlagAttestedData == 0 || len(authData.CredentialID) == 0 {
+		return nil, ErrMalformed
+	}
+	_, alg, err := parsePublicKey(authData.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Credential{
+		ID:             authData.CredentialID,
+		PublicKey:      authData.PublicKey,
+		Algorithm:      alg,
+		SignCount:      authData.SignCount,
+		AAGUID:         authData.AAGUID,
+		UserVerified:   authData.UserVerified(),
+		BackupEligible: authData.BackupEligible(),
+		BackupState:    authData.BackupState(),
+	}, nil
+}
+
+// VerifyAssertion 校验 navigator.credentials.get 的结果；publicKey 为注册时保存的 COSE 公钥。
+// 签名计数由调用方结合已保存的值用 SignCountValid 判断。
+func (rp RelyingParty) VerifyAssertion(challenge, clientDataJSON, rawAuthData, signature, publicKey []byte, requireUserVerification bool) (*AuthenticatorData, error) {
+	if err := rp.verifyClientData(clientDataJSON, "webauthn.get", challenge); err != nil {
+		return nil, err
+	}
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData, requireUserVerification); err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := make([]byte, 0, len(rawAuthData)+len(clientDataHash))
+	signed = append(append(signed, rawAuthData...), clientDataHash[:]...)
+	if err := verifySignature(publicKey, signed, signature); err != nil {
+		return nil, err
+	}
+	return authData, nil
+}
+
+// ParseAuthenticatorData 解析认证器数据的二进制结构。
+func ParseAuthenticatorData(data []byte) (*AuthenticatorData, error) {
+	if len(data) < 37 {
+		return nil, ErrMalformed
+	}
+	authData := &AuthenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[37:]
+	if authData.Flags&FlagAttestedData != 0 {
+		if len(rest) < 18 {
+			return nil, ErrMalformed
+		}
+		authData.AAGUID = rest[:16]
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLen == 0 || idLen > maxCredentialIDLength || len(rest) < idLen {
+			return nil, ErrMalformed
+		}
+		authData.CredentialID = rest[:idLen]
+		rest = rest[idLen:]
+		_, remaining, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		authData.PublicKey = rest[:len(rest)-len(remaining)]
+		rest = remaining
+	}
+	if authData.Flags&FlagExtensionData != 0 {
+		_, remaining, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrMalformed
+		}
+		rest = remaining
+	}
+	if len(rest) != 0 {
+		return nil, ErrMalformed
+	}
+	return authData, nil
+}
+
+func (rp RelyingParty) verifyClientData(clientDataJSON []byte, ceremony string, challenge []byte) error {
+	var clientData ClientData
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return ErrMalformed
+	}
+	if clientData.Type != ceremony {
+		return ErrCeremonyType
+	}
+	received, err := DecodeBase64URL(clientData.Challenge)
+	if err != nil || len(challenge) == 0 || subtle.ConstantTimeCompare(received, challenge) != 1 {
+		return ErrChallengeMismatch
+	}
+	if clientData.CrossOrigin || !rp.originAllowed(clientData.Origin) {
+		return ErrOriginMismatch
+	}
+	return nil
+}
+
+func (rp RelyingParty) verifyAuthenticatorData(authData *AuthenticatorData, requireUserVerification bool) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(authData.RPIDHash, rpIDHash[:]) {
+		return ErrRPIDMismatch
+	}
+	if authData.Flags&FlagUserPresent == 0 {
+		return ErrUserNotPresent
+	}
+	if requireUserVerification && !authData.UserVerified() {
+		return ErrUserNotVerified
+	}
+	return nil
+}
+
+func (rp RelyingParty) originAllowed(origin string) bool {
+	origin = strings.TrimSuffix(origin, "/")
+	for _, allowed := range rp.Origins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+)
+
+var testRP = RelyingParty{ID: "example.com", Name: "Example", Origins: []string{"https://example.com"}}
+
+// encodeCBOR 是测试用的最小编码器，只覆盖构造认证器数据所需的类型。
+func encodeCBOR(v any) []byte {
+	head := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n <= 0xff:
+			return []byte{major<<5 | 24, byte(n)}
+		case n <= 0xffff:
+			return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+		default:
+			return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+		}
+	}
+	switch val := v.(type) {
+	case int:
+		if val >= 0 {
+			return head(0, uint64(val))
+		}
+		return head(1, uint64(-1-val))
+	case int64:
+		return encodeCBOR(int(val))
+	case []byte:
+		return append(head(2, uint64(len(val))), val...)
+	case string:
+		return append(head(3, uint64(len(val))), val...)
+	case map[any]any:
+		keys := make([]any, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return string(encodeCBOR(keys[i])) < string(encodeCBOR(keys[j])) })
+		out := head(5, uint64(len(val)))
+		for _, k := range keys {
+			out = append(out, encodeCBOR(k)...)
+			out = append(out, encodeCBOR(val[k])...)
+		}
+		return out
+	}
+	panic("unsupported type")
+}
+
+type testAuthenticator struct {
+	credentialID []byte
+	signer       crypto.Signer
+	coseKey      []byte
+}
+
+func newES256Authenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := key.X.FillBytes(make([]byte, 32))
+	y := key.Y.FillBytes(make([]byte, 32))
+	coseKey := encodeCBOR(map[any]any{
+		coseKty: ktyEC2, coseAlg: AlgES256, coseCrv: crvP256, coseX: x, coseY: y,
+	})
+	return &testAuthenticator{credentialID: []byte("credential-es256"), signer: key, coseKey: coseKey}
+}
+
+func newEd25519Authenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coseKey := encodeCBOR(map[any]any{
+		coseKty: ktyOKP, coseAlg: AlgEdDSA, coseCrv: crvEd25519, coseX: []byte(pub),
+	})
+	return &testAuthenticator{credentialID: []byte("credential-ed25519"), signer: priv, coseKey: coseKey}
+}
+
+func clientDataJSON(ceremony string, challenge []byte, origin string) []byte {
+	b, _ := json.Marshal(ClientData{
+		Type:      ceremony,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    origin,
+	})
+	return b
+}
+
+func (a *testAuthenticator) authData(flags byte, signCount uint32, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRP.ID))
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey...)
+	}
+	return data
+}
+
+func (a *testAuthenticator) sign(t *testing.T, authData, clientData []byte) []byte {
+	t.Helper()
+	hash := sha256.Sum256(clientData)
+	message := append(append([]byte{}, authData...), hash[:]...)
+	var (
+		sig []byte
+		err error
+	)
+	if _, ok := a.signer.(ed25519.PrivateKey); ok {
+		sig, err = a.signer.Sign(rand.Reader, message, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(message)
+		sig, err = a.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	auth := newES256Authenticator(t)
+	challenge, _ := NewChallenge()
+	attestation := encodeCBOR(map[any]any{
+		"fmt":      "none",
+		"attStmt":  map[any]any{},
+		"authData": auth.authData(FlagUserPresent|FlagUserVerified|FlagAttestedData|FlagBackupEligible, 0, true),
+	})
+
+	cred, err := testRP.VerifyRegistration(challenge, clientDataJSON("webauthn.create", challenge, "https://example.com"), attestation, true)
+	if err != nil {
+		t.Fatalf("VerifyRegistration: %v", err)
+	}
+	if string(cred.ID) != string(auth.credentialID) || cred.Algorithm != AlgES256 || !cred.BackupEligible || !cred.UserVerified {
+		t.Fatalf("unexpected credential: %+v", cred)
+	}
+
+	other, _ := NewChallenge()
+	if _, err := testRP.VerifyRegistration(challenge, clientDataJSON("webauthn.create", other, "https://example.com"), attestation, true); !errors.Is(err, ErrChallengeMismatch) {
+		t.Fatalf("challenge mismatch: got %v", err)
+	}
+	if _, err := testRP.VerifyRegistration(challenge, clientDataJSON("webauthn.create", challenge, "https://evil.example"), attestation, true); !errors.Is(err, ErrOriginMismatch) {
+		t.Fatalf("origin mismatch: got %v", err)
+	}
+	if _, err := testRP.VerifyRegistration(challenge, clientDataJSON("webauthn.get", challenge, "https://example.com"), attestation, true); !errors.Is(err, ErrCeremonyType) {
+		t.Fatalf("ceremony type: got %v", err)
+	}
+}
+
+func TestVerifyRegistrationRequiresUserVerification(t *testing.T) {
+	auth := newES256Authenticator(t)
+	challenge, _ := NewChallenge()
+	attestation := encodeCBOR(map[any]any{
+		"fmt":      "none",
+		"attStmt":  map[any]any{},
+		"authData": auth.authData(FlagUserPresent|FlagAttestedData, 0, true),
+	})
+	if _, err := testRP.VerifyRegistration(challenge, clientDataJSON("webauthn.create", challenge, "https://example.com"), attestation, true); !errors.Is(err, ErrUserNotVerified) {
+		t.Fatalf("got %v, want ErrUserNotVerified", err)
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	for _, auth := range []*testAuthenticator{newES256Authenticator(t), newEd25519Authenticator(t)} {
+		challenge, _ := NewChallenge()
+		clientData := clientDataJSON("webauthn.get", challenge, "https://example.com")
+		authData := auth.authData(FlagUserPresent|FlagUserVerified, 7, false)
+		sig := auth.sign(t, authData, clientData)
+
+		parsed, err := testRP.VerifyAssertion(challenge, clientData, authData, sig, auth.coseKey, true)
+		if err != nil {
+			t.Fatalf("%s: VerifyAssertion: %v", auth.credentialID, err)
+		}
+		if parsed.SignCount != 7 {
+			t.Fatalf("sign count = %d, want 7", parsed.SignCount)
+		}
+
+		tampered := append([]byte{}, authData...)
+		tampered[len(tampered)-1]++
+		if _, err := testRP.VerifyAssertion(challenge, clientData, tampered, sig, auth.coseKey, true); !errors.Is(err, ErrInvalidSignature) {
+			t.Fatalf("%s: tampered data: got %v", auth.credentialID, err)
+		}
+	}
+}
+
+func TestVerifyAssertionRPID(t *testing.T) {
+	auth := newES256Authenticator(t)
+	challenge, _ := NewChallenge()
+	clientData := clientDataJSON("webauthn.get", challenge, "https://example.com")
+	authData := auth.authData(FlagUserPresent|FlagUserVerified, 1, false)
+	sig := auth.sign(t, authData, clientData)
+
+	rp := RelyingParty{ID: "other.example", Origins: testRP.Origins}
+	if _, err := rp.VerifyAssertion(challenge, clientData, authData, sig, auth.coseKey, true); !errors.Is(err, ErrRPIDMismatch) {
+		t.Fatalf("got %v, want ErrRPIDMismatch", err)
+	}
+}
+
+func TestSignCountValid(t *testing.T) {
+	cases := []struct {
+		stored, received uint32
+		want             bool
+	}{
+		{0, 0, true},
+		{0, 1, true},
+		{5, 6, true},
+		{5, 5, false},
+		{5, 4, false},
+		{5, 0, false},
+	}
+	for _, tc := range cases {
+		if got := SignCountValid(tc.stored, tc.received); got != tc.want {
+			t.Errorf("SignCountValid(%d, %d) = %v, want %v", tc.stored, tc.received, got, tc.want)
+		}
+	}
+}
+
+func TestDecodeCBORRejectsTruncated(t *testing.T) {
+	data := encodeCBOR(map[any]any{"fmt": "none", "authData": []byte{1, 2, 3}})
+	if _, _, err := decodeCBOR(data[:len(data)-1]); err == nil {
+		t.Fatal("truncated input accepted")
+	}
+}
@@ -0,0 +1,44 @@
+-- +goose Up
+ALTER TABLE friend_link
+    ADD COLUMN IF NOT EXISTS feed_etag          VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS feed_last_modified VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS last_sync_error    TEXT;
+
+CREATE TABLE IF NOT EXISTS friend_link_post
+(
+    id             BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    friend_link_id BIGINT       NOT NULL,
+    guid           VARCHAR(512) NOT NULL,
+    title          VARCHAR(512) NOT NULL,
+    url            VARCHAR(512) NOT NULL,
+    summary        TEXT,
+    author         VARCHAR(255),
+    published_at   TIMESTAMPTZ  NOT NULL,
+    created_at     TIMESTAMPTZ  DEFAULT now(),
+    updated_at     TIMESTAMPTZ  DEFAULT now(),
+
+    FOREIGN KEY (friend_link_id) REFERENCES friend_link (id) ON DELETE CASCADE,
+    CONSTRAINT uq_friend_link_post_guid UNIQUE (friend_link_id, guid)
+);
+
+CREATE INDEX idx_friend_link_post_published_at ON friend_link_post (published_at DESC);
+
+INSERT INTO sys_config (config_key, value, group_path, label, value_type, sort, meta)
+VALUES ('friendlink.syncIntervalMinutes', '60', 'friendlink/sync', '默认同步间隔(分钟)', 'number', 10, '{"unit":"min","min":5}'::jsonb),
+       ('friendlink.syncMaxItems', '20', 'friendlink/sync', '每个友链保留文章数', 'number', 20, '{"min":1,"max":100}'::jsonb),
+       ('friendlink.syncTimeoutSeconds', '15', 'friendlink/sync', '抓取超时(秒)', 'number', 30, '{"unit":"s"}'::jsonb)
+ON CONFLICT (config_key) DO NOTHING;
+
+-- +goose Down
+DELETE FROM sys_config WHERE config_key IN (
+    'friendlink.syncIntervalMinutes',
+    'friendlink.syncMaxItems',
+    'friendlink.syncTimeoutSeconds'
+);
+
+DROP TABLE IF EXISTS friend_link_post;
+
+ALTER TABLE friend_link
+    DROP COLUMN IF EXISTS last_sync_error,
+    DROP COLUMN IF EXISTS feed_last_modified,
+    DROP COLUMN IF EXISTS feed_etag;
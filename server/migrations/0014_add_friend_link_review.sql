@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE friend_link_applications
+    ADD COLUMN IF NOT EXISTS review_reason TEXT,
+    ADD COLUMN IF NOT EXISTS reviewed_at   TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_friend_link_app_status_channel
+    ON friend_link_applications (status, apply_channel, created_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_friend_link_app_status_channel;
+
+ALTER TABLE friend_link_applications
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS review_reason;
@@ -0,0 +1,39 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS friend_link_health
+(
+    friend_link_id       BIGINT PRIMARY KEY,
+    status_code          INT,
+    latency_ms           INT,
+    tls_expires_at       TIMESTAMPTZ,
+    has_backlink         BOOLEAN     NOT NULL DEFAULT FALSE,
+    rss_status_code      INT,
+    error_message        TEXT,
+    is_healthy           BOOLEAN     NOT NULL DEFAULT TRUE,
+    consecutive_failures INT         NOT NULL DEFAULT 0,
+    last_success_at      TIMESTAMPTZ,
+    auto_deactivated     BOOLEAN     NOT NULL DEFAULT FALSE,
+    checked_at           TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    FOREIGN KEY (friend_link_id) REFERENCES friend_link (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_friend_link_health_healthy ON friend_link_health (is_healthy);
+
+INSERT INTO sys_config (config_key, value, group_path, label, value_type, sort, meta)
+VALUES ('friendlink.healthIntervalHours', '24', 'friendlink/health', '检查间隔(小时)', 'number', 10, '{"unit":"h","min":1}'::jsonb),
+       ('friendlink.healthTimeoutSeconds', '10', 'friendlink/health', '请求超时(秒)', 'number', 20, '{"unit":"s"}'::jsonb),
+       ('friendlink.healthFailureThreshold', '3', 'friendlink/health', '连续失败阈值', 'number', 30, '{"min":1}'::jsonb),
+       ('friendlink.healthAutoDeactivate', 'false', 'friendlink/health', '自动下线失效友链', 'bool', 40, '{"inputType":"switch"}'::jsonb),
+       ('friendlink.backlinkURL', '', 'friendlink/health', '本站地址(反链检测)', 'string', 50, '{}'::jsonb)
+ON CONFLICT (config_key) DO NOTHING;
+
+-- +goose Down
+DELETE FROM sys_config WHERE config_key IN (
+    'friendlink.healthIntervalHours',
+    'friendlink.healthTimeoutSeconds',
+    'friendlink.healthFailureThreshold',
+    'friendlink.healthAutoDeactivate',
+    'friendlink.backlinkURL'
+);
+
+DROP TABLE IF EXISTS friend_link_health;
@@ -0,0 +1,28 @@
+-- +goose Up
+ALTER TABLE federation_instance
+    ADD COLUMN IF NOT EXISTS manifest     JSONB NOT NULL DEFAULT '{}'::jsonb,
+    ADD COLUMN IF NOT EXISTS block_reason TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_federation_instance_status ON federation_instance (status);
+
+CREATE TABLE IF NOT EXISTS federation_domain_rule
+(
+    id         BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    pattern    VARCHAR(255) NOT NULL,
+    action     VARCHAR(10)  NOT NULL,
+    reason     TEXT,
+    created_at TIMESTAMPTZ  DEFAULT now(),
+    updated_at TIMESTAMPTZ  DEFAULT now(),
+
+    CONSTRAINT uq_federation_domain_rule_pattern UNIQUE (pattern),
+    CONSTRAINT chk_federation_domain_rule_action CHECK (action IN ('block', 'allow'))
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS federation_domain_rule;
+
+DROP INDEX IF EXISTS idx_federation_instance_status;
+
+ALTER TABLE federation_instance
+    DROP COLUMN IF EXISTS block_reason,
+    DROP COLUMN IF EXISTS manifest;
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE federation_instance
+    ADD COLUMN IF NOT EXISTS rate_limit_violations INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_rate_limited_at  TIMESTAMPTZ;
+
+UPDATE federation_config
+SET description = '令牌桶限流 JSON，如 {"instance":{"limit":600,"window_seconds":3600},"endpoints":{"citation_request":{"limit":30,"window_seconds":3600,"burst":5}}}，未配置的端点使用内置默认值'
+WHERE config_key = 'federation.rateLimits';
+
+-- +goose Down
+UPDATE federation_config
+SET description = '速率限制 JSON'
+WHERE config_key = 'federation.rateLimits';
+
+ALTER TABLE federation_instance
+    DROP COLUMN IF EXISTS last_rate_limited_at,
+    DROP COLUMN IF EXISTS rate_limit_violations;
@@ -0,0 +1,21 @@
+-- +goose Up
+UPDATE federation_config
+SET enum_options = '["rsa-sha256","ed25519"]'::jsonb,
+    description  = 'HTTP Signatures 首选算法；选择 ed25519 时仍保留 RSA 密钥用于与仅支持 rsa-sha256 的实例通信'
+WHERE config_key = 'federation.signatureAlg';
+
+INSERT INTO federation_config (config_key, value, is_sensitive, group_path, label, description, value_type, enum_options, default_value, visible_when, sort, meta)
+VALUES
+    ('federation.ed25519PublicKey', '', FALSE, 'federation/security', 'Ed25519 公钥', '对外发布的 Ed25519 公钥', 'string', '[]'::jsonb, NULL, '[]'::jsonb, 32, '{}'::jsonb),
+    ('federation.ed25519PrivateKey', '', TRUE, 'federation/security', 'Ed25519 私钥', 'Ed25519 签名使用的私钥', 'string', '[]'::jsonb, NULL, '[]'::jsonb, 34, '{"inputType":"password"}'::jsonb)
+ON CONFLICT (config_key) DO NOTHING;
+
+-- +goose Down
+DELETE FROM federation_config
+WHERE config_key IN ('federation.ed25519PublicKey', 'federation.ed25519PrivateKey');
+
+UPDATE federation_config
+SET enum_options = '["rsa-sha256"]'::jsonb,
+    value        = 'rsa-sha256',
+    description  = 'HTTP Signatures 使用的算法'
+WHERE config_key = 'federation.signatureAlg';
@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS federation_signing_key
+(
+    id          BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    key_ref     VARCHAR(64) NOT NULL,
+    algorithm   VARCHAR(32) NOT NULL,
+    public_key  TEXT        NOT NULL,
+    private_key TEXT        NOT NULL,
+    created_at  TIMESTAMPTZ DEFAULT now(),
+    expires_at  TIMESTAMPTZ,
+
+    CONSTRAINT uq_federation_signing_key_ref UNIQUE (key_ref)
+);
+
+CREATE INDEX IF NOT EXISTS idx_federation_signing_key_algorithm ON federation_signing_key (algorithm, created_at DESC);
+
+-- 已有密钥沿用原 keyId：RSA 为公钥文档地址本身，Ed25519 为 #ed25519。
+INSERT INTO federation_signing_key (key_ref, algorithm, public_key, private_key)
+SELECT '', 'rsa-sha256', pub.value, priv.value
+FROM federation_config pub
+         JOIN federation_config priv ON priv.config_key = 'federation.privateKey'
+WHERE pub.config_key = 'federation.publicKey'
+  AND pub.value <> ''
+  AND priv.value <> ''
+ON CONFLICT (key_ref) DO NOTHING;
+
+INSERT INTO federation_signing_key (key_ref, algorithm, public_key, private_key)
+SELECT 'ed25519', 'ed25519', pub.value, priv.value
+FROM federation_config pub
+         JOIN federation_config priv ON priv.config_key = 'federation.ed25519PrivateKey'
+WHERE pub.config_key = 'federation.ed25519PublicKey'
+  AND pub.value <> ''
+  AND priv.value <> ''
+ON CONFLICT (key_ref) DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS federation_signing_key;
@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS activitypub_follower
+(
+    id                 BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    actor_id           VARCHAR(500) NOT NULL,
+    inbox              VARCHAR(500) NOT NULL,
+    shared_inbox       VARCHAR(500),
+    username           VARCHAR(255),
+    display_name       VARCHAR(255),
+    avatar             VARCHAR(500),
+    follow_activity_id VARCHAR(500),
+    created_at         TIMESTAMPTZ DEFAULT now(),
+    updated_at         TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT uq_activitypub_follower_actor UNIQUE (actor_id)
+);
+
+CREATE TABLE IF NOT EXISTS activitypub_interaction
+(
+    id          BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    activity_id VARCHAR(500) NOT NULL,
+    kind        VARCHAR(20)  NOT NULL,
+    actor_id    VARCHAR(500) NOT NULL,
+    object_type VARCHAR(20)  NOT NULL,
+    object_id   BIGINT       NOT NULL,
+    created_at  TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT uq_activitypub_interaction_activity UNIQUE (activity_id),
+    CONSTRAINT chk_activitypub_interaction_kind CHECK (kind IN ('like', 'announce'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_activitypub_interaction_object ON activitypub_interaction (object_type, object_id);
+
+INSERT INTO federation_config (config_key, value, is_sensitive, group_path, label, description, value_type, enum_options, default_value, visible_when, sort, meta)
+VALUES
+    ('federation.activityPub', 'false', FALSE, 'federation/activitypub', '启用 ActivityPub', '允许 Mastodon/Misskey 等实例通过 WebFinger 关注站长账号并接收文章推送', 'bool', '[]'::jsonb, 'false', '[]'::jsonb, 10, '{"inputType":"switch"}'::jsonb),
+    ('federation.activityPubReplies', 'false', FALSE, 'federation/activitypub', '接收回复', '将来自 ActivityPub 的回复写入对应文章的评论区（默认关闭，写入前会校验实例黑名单）', 'bool', '[]'::jsonb, 'false', '[]'::jsonb, 20, '{"inputType":"switch"}'::jsonb)
+ON CONFLICT (config_key) DO NOTHING;
+
+-- +goose Down
+DELETE FROM federation_config
+WHERE config_key IN ('federation.activityPub', 'federation.activityPubReplies');
+
+DROP TABLE IF EXISTS activitypub_interaction;
+DROP TABLE IF EXISTS activitypub_follower;
@@ -0,0 +1,59 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS webmention
+(
+    id           BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    source       VARCHAR(1000) NOT NULL,
+    target       VARCHAR(1000) NOT NULL,
+    target_type  VARCHAR(20)   NOT NULL,
+    target_id    BIGINT        NOT NULL,
+    status       VARCHAR(20)   NOT NULL DEFAULT 'pending',
+    author_name  VARCHAR(255),
+    author_url   VARCHAR(1000),
+    title        VARCHAR(500),
+    content      TEXT,
+    comment_id   BIGINT,
+    error        TEXT,
+    verified_at  TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ DEFAULT now(),
+    updated_at   TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT uq_webmention_source_target UNIQUE (source, target),
+    CONSTRAINT chk_webmention_status CHECK (status IN ('pending', 'verified', 'rejected', 'deleted'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_webmention_target ON webmention (target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_webmention_status ON webmention (status);
+
+CREATE TABLE IF NOT EXISTS webmention_send
+(
+    id           BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    article_id   BIGINT        NOT NULL,
+    source       VARCHAR(1000) NOT NULL,
+    target       VARCHAR(1000) NOT NULL,
+    endpoint     VARCHAR(1000),
+    status       VARCHAR(20)   NOT NULL DEFAULT 'pending',
+    status_code  INT,
+    error        TEXT,
+    content_hash VARCHAR(64),
+    sent_at      TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ DEFAULT now(),
+    updated_at   TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT uq_webmention_send_article_target UNIQUE (article_id, target),
+    CONSTRAINT chk_webmention_send_status CHECK (status IN ('pending', 'sent', 'no_endpoint', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_webmention_send_article ON webmention_send (article_id);
+
+INSERT INTO federation_config (config_key, value, is_sensitive, group_path, label, description, value_type, enum_options, default_value, visible_when, sort, meta)
+VALUES
+    ('federation.webmention', 'false', FALSE, 'federation/webmention', '启用 Webmention', '接收其他博客的 Webmention，并在文章发布/更新时通知文中外链', 'bool', '[]'::jsonb, 'false', '[]'::jsonb, 10, '{"inputType":"switch"}'::jsonb),
+    ('federation.webmentionComments', 'false', FALSE, 'federation/webmention', '转为评论', '校验通过的 Webmention 写入对应内容的评论区（默认未读）', 'bool', '[]'::jsonb, 'false', '[]'::jsonb, 20, '{"inputType":"switch"}'::jsonb)
+ON CONFLICT (config_key) DO NOTHING;
+
+-- +goose Down
+DELETE FROM federation_config
+WHERE config_key IN ('federation.webmention', 'federation.webmentionComments');
+
+DROP TABLE IF EXISTS webmention_send;
+DROP TABLE IF EXISTS webmention;
@@ -0,0 +1,55 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS websub_subscription
+(
+    id                BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    topic             VARCHAR(500)  NOT NULL,
+    callback          VARCHAR(1000) NOT NULL,
+    secret            VARCHAR(200),
+    lease_seconds     INT           NOT NULL,
+    expires_at        TIMESTAMPTZ   NOT NULL,
+    last_delivered_at TIMESTAMPTZ,
+    last_error        TEXT,
+    failure_count     INT           NOT NULL DEFAULT 0,
+    created_at        TIMESTAMPTZ DEFAULT now(),
+    updated_at        TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT uq_websub_subscription_topic_callback UNIQUE (topic, callback)
+);
+
+CREATE INDEX IF NOT EXISTS idx_websub_subscription_expires ON websub_subscription (expires_at);
+
+CREATE TABLE IF NOT EXISTS friend_link_websub
+(
+    friend_link_id BIGINT PRIMARY KEY,
+    hub            VARCHAR(1000) NOT NULL,
+    topic          VARCHAR(1000) NOT NULL,
+    callback_token VARCHAR(64)   NOT NULL,
+    secret         VARCHAR(128)  NOT NULL,
+    status         VARCHAR(20)   NOT NULL DEFAULT 'pending',
+    lease_seconds  INT,
+    expires_at     TIMESTAMPTZ,
+    last_push_at   TIMESTAMPTZ,
+    error          TEXT,
+    created_at     TIMESTAMPTZ DEFAULT now(),
+    updated_at     TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT uq_friend_link_websub_token UNIQUE (callback_token),
+    CONSTRAINT chk_friend_link_websub_status CHECK (status IN ('pending', 'active', 'denied')),
+    FOREIGN KEY (friend_link_id) REFERENCES friend_link (id) ON DELETE CASCADE
+);
+
+INSERT INTO federation_config (config_key, value, is_sensitive, group_path, label, description, value_type, enum_options, default_value, visible_when, sort, meta)
+VALUES
+    ('federation.websub', 'false', FALSE, 'federation/websub', '启用 WebSub Hub', '在 Feed 中声明内置 Hub，内容发布时主动推送给订阅方', 'bool', '[]'::jsonb, 'false', '[]'::jsonb, 10, '{"inputType":"switch"}'::jsonb)
+ON CONFLICT (config_key) DO NOTHING;
+
+INSERT INTO sys_config (config_key, value, group_path, label, value_type, sort, meta)
+VALUES ('friendlink.syncWebSub', 'true', 'friendlink/sync', '优先使用 WebSub 推送', 'bool', 40, '{"inputType":"switch"}'::jsonb)
+ON CONFLICT (config_key) DO NOTHING;
+
+-- +goose Down
+DELETE FROM sys_config WHERE config_key = 'friendlink.syncWebSub';
+DELETE FROM federation_config WHERE config_key = 'federation.websub';
+
+DROP TABLE IF EXISTS friend_link_websub;
+DROP TABLE IF EXISTS websub_subscription;
@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS federated_comment
+(
+    id                 BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    source_instance_id BIGINT        NOT NULL,
+    remote_comment_id  VARCHAR(255)  NOT NULL,
+    target_article_id  BIGINT        NOT NULL,
+    author_name        VARCHAR(255)  NOT NULL,
+    author_url         VARCHAR(1000),
+    author_avatar      VARCHAR(1000),
+    content            TEXT          NOT NULL,
+    status             VARCHAR(20)   NOT NULL DEFAULT 'pending',
+    comment_id         BIGINT,
+    reject_reason      TEXT,
+    reviewed_at        TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ DEFAULT now(),
+    updated_at         TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT fk_federated_comment_instance FOREIGN KEY (source_instance_id) REFERENCES federation_instance (id),
+    CONSTRAINT fk_federated_comment_article FOREIGN KEY (target_article_id) REFERENCES article (id) ON DELETE CASCADE,
+    CONSTRAINT uq_federated_comment_remote UNIQUE (source_instance_id, remote_comment_id),
+    CONSTRAINT chk_federated_comment_status CHECK (status IN ('pending', 'approved', 'rejected'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_federated_comment_status_created
+    ON federated_comment (status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_federated_comment_target
+    ON federated_comment (target_article_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS federated_comment;
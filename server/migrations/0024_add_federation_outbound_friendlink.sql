@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS federation_outbound_friendlink
+(
+    id                    BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    target_url            VARCHAR(500) NOT NULL,
+    instance_id           BIGINT,
+    message               TEXT,
+    rss_url               VARCHAR(1000),
+    status                VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    response_status       INT,
+    response_body         TEXT,
+    remote_application_id BIGINT,
+    reject_reason         TEXT,
+    friend_link_id        BIGINT,
+    decided_at            TIMESTAMPTZ,
+    created_at            TIMESTAMPTZ DEFAULT now(),
+    updated_at            TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT fk_federation_outbound_friendlink_instance FOREIGN KEY (instance_id) REFERENCES federation_instance (id) ON DELETE SET NULL,
+    CONSTRAINT chk_federation_outbound_friendlink_status CHECK (status IN ('pending', 'sent', 'failed', 'approved', 'rejected'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_federation_outbound_friendlink_target
+    ON federation_outbound_friendlink (target_url, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_federation_outbound_friendlink_status
+    ON federation_outbound_friendlink (status, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS federation_outbound_friendlink;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE upload_file
+    ADD COLUMN IF NOT EXISTS width          INT,
+    ADD COLUMN IF NOT EXISTS height         INT,
+    ADD COLUMN IF NOT EXISTS dominant_color VARCHAR(9),
+    ADD COLUMN IF NOT EXISTS blurhash       VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS variants       JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE upload_file
+    DROP COLUMN IF EXISTS variants,
+    DROP COLUMN IF EXISTS blurhash,
+    DROP COLUMN IF EXISTS dominant_color,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS width;
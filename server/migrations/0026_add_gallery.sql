@@ -0,0 +1,64 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS photo_album
+(
+    id             BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    title          VARCHAR(255) NOT NULL,
+    description    TEXT,
+    cover_photo_id BIGINT,
+    sort_order     INT          NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ DEFAULT now(),
+    updated_at     TIMESTAMPTZ DEFAULT now()
+);
+
+ALTER TABLE photo
+    ADD COLUMN IF NOT EXISTS upload_id  BIGINT,
+    ADD COLUMN IF NOT EXISTS album_id   BIGINT,
+    ADD COLUMN IF NOT EXISTS comment_id BIGINT,
+    ADD COLUMN IF NOT EXISTS width      INT,
+    ADD COLUMN IF NOT EXISTS height     INT,
+    ADD COLUMN IF NOT EXISTS blurhash   VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS taken_at   TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS sort_order INT     NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS likes      INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ DEFAULT now(),
+    ADD CONSTRAINT fk_photo_upload FOREIGN KEY (upload_id) REFERENCES upload_file (id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_photo_album FOREIGN KEY (album_id) REFERENCES photo_album (id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_photo_comment_area FOREIGN KEY (comment_id) REFERENCES comment_area (id);
+
+ALTER TABLE photo_album
+    ADD CONSTRAINT fk_photo_album_cover FOREIGN KEY (cover_photo_id) REFERENCES photo (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_photo_album_sort ON photo (album_id, sort_order, id);
+CREATE INDEX IF NOT EXISTS idx_photo_created_at ON photo (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_photo_album_order ON photo_album (sort_order, id);
+
+-- 新增的枚举值在本事务提交前不可使用，迁移中也没有用到。
+ALTER TYPE like_target_type ADD VALUE IF NOT EXISTS 'photo';
+
+-- +goose Down
+-- PostgreSQL 不支持删除枚举值，like_target_type 中的 'photo' 保留。
+DELETE FROM content_like WHERE target_type::text = 'photo';
+
+DROP INDEX IF EXISTS idx_photo_album_order;
+DROP INDEX IF EXISTS idx_photo_created_at;
+DROP INDEX IF EXISTS idx_photo_album_sort;
+
+ALTER TABLE photo_album
+    DROP CONSTRAINT IF EXISTS fk_photo_album_cover;
+
+ALTER TABLE photo
+    DROP CONSTRAINT IF EXISTS fk_photo_comment_area,
+    DROP CONSTRAINT IF EXISTS fk_photo_album,
+    DROP CONSTRAINT IF EXISTS fk_photo_upload,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS likes,
+    DROP COLUMN IF EXISTS sort_order,
+    DROP COLUMN IF EXISTS taken_at,
+    DROP COLUMN IF EXISTS blurhash,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS width,
+    DROP COLUMN IF EXISTS comment_id,
+    DROP COLUMN IF EXISTS album_id,
+    DROP COLUMN IF EXISTS upload_id;
+
+DROP TABLE IF EXISTS photo_album;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS upload_file_usage
+(
+    upload_id  BIGINT      NOT NULL,
+    owner_type VARCHAR(20) NOT NULL,
+    owner_id   BIGINT      NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+
+    PRIMARY KEY (upload_id, owner_type, owner_id),
+    CONSTRAINT fk_upload_file_usage_upload FOREIGN KEY (upload_id) REFERENCES upload_file (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_file_usage_owner
+    ON upload_file_usage (owner_type, owner_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS upload_file_usage;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS upload_session
+(
+    id           VARCHAR(36) PRIMARY KEY,
+    user_id      BIGINT       NOT NULL,
+    name         VARCHAR(255) NOT NULL,
+    type         VARCHAR(20)  NOT NULL,
+    size         BIGINT       NOT NULL,
+    chunk_size   BIGINT       NOT NULL,
+    total_chunks INT          NOT NULL,
+    hash         VARCHAR(64)  NOT NULL DEFAULT '',
+    upload_id    BIGINT,
+    expires_at   TIMESTAMPTZ  NOT NULL,
+    created_at   TIMESTAMPTZ DEFAULT now(),
+    updated_at   TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT fk_upload_session_upload FOREIGN KEY (upload_id) REFERENCES upload_file (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_session_expires_at
+    ON upload_session (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS upload_session;
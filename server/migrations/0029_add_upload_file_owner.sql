@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE upload_file
+    ADD COLUMN IF NOT EXISTS user_id BIGINT,
+    ADD CONSTRAINT fk_upload_file_user FOREIGN KEY (user_id) REFERENCES app_user (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_upload_file_user_id ON upload_file (user_id);
+
+INSERT INTO sys_config (config_key, value, group_path, label, value_type, enum_options, sort, meta)
+VALUES ('upload.userQuotaMB', '1024', 'storage/upload', '单用户配额(MB)', 'number', '[]'::jsonb, 20, '{"unit":"MB","min":0}'::jsonb),
+       ('upload.ratePerMinute', '20', 'storage/upload', '每分钟上传次数', 'number', '[]'::jsonb, 30, '{"min":1}'::jsonb),
+       ('upload.svgPolicy', 'sanitize', 'storage/upload', 'SVG 处理', 'enum', '[{"label":"清洗后保存","value":"sanitize"},{"label":"拒绝","value":"reject"}]'::jsonb, 40, '{}'::jsonb)
+ON CONFLICT (config_key) DO NOTHING;
+
+-- +goose Down
+DELETE FROM sys_config WHERE config_key IN (
+    'upload.userQuotaMB',
+    'upload.ratePerMinute',
+    'upload.svgPolicy'
+);
+
+DROP INDEX IF EXISTS idx_upload_file_user_id;
+
+ALTER TABLE upload_file
+    DROP CONSTRAINT IF EXISTS fk_upload_file_user,
+    DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS upload_file_source
+(
+    id         BIGSERIAL PRIMARY KEY,
+    upload_id  BIGINT NOT NULL,
+    source_url TEXT   NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT fk_upload_file_source_upload FOREIGN KEY (upload_id) REFERENCES upload_file (id) ON DELETE CASCADE,
+    CONSTRAINT uq_upload_file_source_url UNIQUE (source_url)
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_file_source_upload_id
+    ON upload_file_source (upload_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS upload_file_source;
@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS user_two_factor
+(
+    user_id        BIGINT PRIMARY KEY,
+    secret         VARCHAR(64) NOT NULL,
+    last_used_step BIGINT      NOT NULL DEFAULT 0,
+    enabled        BOOLEAN     NOT NULL DEFAULT FALSE,
+    enabled_at     TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ DEFAULT now(),
+    updated_at     TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT fk_user_two_factor_user FOREIGN KEY (user_id) REFERENCES app_user (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS user_recovery_code
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL,
+    code_hash  VARCHAR(64) NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT fk_user_recovery_code_user FOREIGN KEY (user_id) REFERENCES app_user (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_recovery_code_user_id
+    ON user_recovery_code (user_id);
+
+INSERT INTO sys_config (config_key, value, group_path, label, value_type, enum_options, sort, meta)
+VALUES ('auth.adminTwoFactor', 'optional', 'security/twoFactor', '管理员两步验证', 'enum', '[{"label":"可选","value":"optional"},{"label":"强制","value":"required"}]'::jsonb, 10, '{}'::jsonb)
+ON CONFLICT (config_key) DO NOTHING;
+
+-- +goose Down
+DELETE FROM sys_config WHERE config_key = 'auth.adminTwoFactor';
+
+DROP TABLE IF EXISTS user_recovery_code;
+DROP TABLE IF EXISTS user_two_factor;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS user_passkey
+(
+    id              BIGSERIAL PRIMARY KEY,
+    user_id         BIGINT       NOT NULL,
+    name            VARCHAR(64)  NOT NULL DEFAULT '',
+    credential_id   BYTEA        NOT NULL,
+    public_key      BYTEA        NOT NULL,
+    algorithm       INTEGER      NOT NULL,
+    sign_count      BIGINT       NOT NULL DEFAULT 0,
+    aaguid          BYTEA,
+    transports      JSONB        NOT NULL DEFAULT '[]'::jsonb,
+    backup_eligible BOOLEAN      NOT NULL DEFAULT FALSE,
+    backup_state    BOOLEAN      NOT NULL DEFAULT FALSE,
+    last_used_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ DEFAULT now(),
+    updated_at      TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT uq_user_passkey_credential_id UNIQUE (credential_id),
+    CONSTRAINT fk_user_passkey_user FOREIGN KEY (user_id) REFERENCES app_user (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_passkey_user_id
+    ON user_passkey (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_passkey;
@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS user_session
+(
+    id                  BIGSERIAL PRIMARY KEY,
+    session_key         VARCHAR(64)  NOT NULL,
+    user_id             BIGINT       NOT NULL,
+    refresh_token_hash  VARCHAR(64)  NOT NULL,
+    previous_token_hash VARCHAR(64)  NOT NULL DEFAULT '',
+    user_agent          VARCHAR(512) NOT NULL DEFAULT '',
+    browser             VARCHAR(64)  NOT NULL DEFAULT '',
+    platform            VARCHAR(64)  NOT NULL DEFAULT '',
+    ip                  VARCHAR(64)  NOT NULL DEFAULT '',
+    location            VARCHAR(128) NOT NULL DEFAULT '',
+    created_at          TIMESTAMPTZ DEFAULT now(),
+    last_seen_at        TIMESTAMPTZ DEFAULT now(),
+    expires_at          TIMESTAMPTZ  NOT NULL,
+    revoked_at          TIMESTAMPTZ,
+    revoked_reason      VARCHAR(32)  NOT NULL DEFAULT '',
+
+    CONSTRAINT uq_user_session_key UNIQUE (session_key),
+    CONSTRAINT fk_user_session_user FOREIGN KEY (user_id) REFERENCES app_user (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_session_user_id
+    ON user_session (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_user_session_expires_at
+    ON user_session (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_session;